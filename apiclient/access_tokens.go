@@ -0,0 +1,76 @@
+package apiclient
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// AccessTokenScope is a permission a personal access token can be given.
+type AccessTokenScope string
+
+const (
+	ScopeProfileRead        AccessTokenScope = "profile:read"
+	ScopeProfileWrite       AccessTokenScope = "profile:write"
+	ScopeUsersRead          AccessTokenScope = "users:read"
+	ScopeOrganizationsRead  AccessTokenScope = "organizations:read"
+	ScopeOrganizationsWrite AccessTokenScope = "organizations:write"
+)
+
+// AccessTokenRequest creates a personal access token. A nil ExpiresAt makes
+// a token that does not expire.
+type AccessTokenRequest struct {
+	Name      string             `json:"name"`
+	Scopes    []AccessTokenScope `json:"scopes"`
+	ExpiresAt *time.Time         `json:"expires_at,omitempty"`
+}
+
+// AccessToken describes a personal access token. Token is only set in the
+// answer to CreateAccessToken; pass it to WithBearerToken to use it.
+type AccessToken struct {
+	Response   `json:"-"`
+	ID         int64              `json:"id"`
+	Token      string             `json:"token,omitempty"`
+	Name       string             `json:"name"`
+	Scopes     []AccessTokenScope `json:"scopes"`
+	CreatedAt  time.Time          `json:"created_at"`
+	ExpiresAt  *time.Time         `json:"expires_at"`
+	LastUsedAt *time.Time         `json:"last_used_at"`
+}
+
+type AccessTokens struct {
+	Response `json:"-"`
+	Tokens   []AccessToken `json:"tokens"`
+}
+
+// ListAccessTokens lists the caller's personal access tokens, newest first.
+func (c *Client) ListAccessTokens(ctx context.Context) (*AccessTokens, error) {
+	var out AccessTokens
+	if err := c.call(ctx, &request{method: http.MethodGet, path: "/api/profile/tokens"}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) CreateAccessToken(ctx context.Context, body AccessTokenRequest) (*AccessToken, error) {
+	req, err := jsonRequest(http.MethodPost, "/api/profile/tokens", body)
+	if err != nil {
+		return nil, err
+	}
+	var out AccessToken
+	if err := c.call(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteAccessToken revokes a token; it stops working at once.
+func (c *Client) DeleteAccessToken(ctx context.Context, id int64) (*Response, error) {
+	var out Response
+	path := "/api/profile/tokens/" + strconv.FormatInt(id, 10)
+	if err := c.call(ctx, &request{method: http.MethodDelete, path: path}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,132 @@
+package apiclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type GoogleAuthPurpose string
+
+const (
+	GoogleAuthPurposeLogin  GoogleAuthPurpose = "login"
+	GoogleAuthPurposeSignUp GoogleAuthPurpose = "signup"
+)
+
+// Message is the body of calls that only confirm what they did.
+type Message struct {
+	Response `json:"-"`
+	Message  string `json:"message"`
+}
+
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// Grant types for IssueToken and IssueJWT. GrantTypeRefreshToken is only
+// accepted by IssueJWT.
+const (
+	GrantTypePassword     = "password"
+	GrantTypeGoogle       = "google"
+	GrantTypeRefreshToken = "refresh_token"
+)
+
+// TokenRequest asks for a bearer token with an email and password, or with a
+// Google authorization code from the login consent page.
+type TokenRequest struct {
+	GrantType    string `json:"grant_type"`
+	Email        string `json:"email,omitempty"`
+	Password     string `json:"password,omitempty"`
+	Code         string `json:"code,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+type Token struct {
+	Response    `json:"-"`
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresIn   int64     `json:"expires_in"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type SignUpRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	// InviteCode is required while sign-up is invite-only.
+	InviteCode string `json:"invite_code,omitempty"`
+}
+
+type SignUpPolicy struct {
+	Response       `json:"-"`
+	Mode           string   `json:"mode"`
+	AllowedDomains []string `json:"allowed_domains"`
+}
+
+type GoogleAuthURL struct {
+	Response `json:"-"`
+	URL      string `json:"url"`
+}
+
+// Login starts a session; the session cookie is in the result's Cookies.
+func (c *Client) Login(ctx context.Context, in LoginRequest) (*Message, error) {
+	req, err := jsonRequest(http.MethodPost, "/api/auth/login", in)
+	if err != nil {
+		return nil, err
+	}
+	var out Message
+	if err := c.call(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// IssueToken logs in an API client. Pass the token to WithBearerToken, and
+// revoke it with Logout.
+func (c *Client) IssueToken(ctx context.Context, in TokenRequest) (*Token, error) {
+	req, err := jsonRequest(http.MethodPost, "/api/auth/token", in)
+	if err != nil {
+		return nil, err
+	}
+	var out Token
+	if err := c.call(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) SignUp(ctx context.Context, in SignUpRequest) (*Message, error) {
+	req, err := jsonRequest(http.MethodPost, "/api/auth/signup", in)
+	if err != nil {
+		return nil, err
+	}
+	var out Message
+	if err := c.call(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) GetSignUpPolicy(ctx context.Context) (*SignUpPolicy, error) {
+	var out SignUpPolicy
+	if err := c.call(ctx, &request{method: http.MethodGet, path: "/api/auth/signup/policy"}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetGoogleAuthURL returns the Google consent page for a login or sign-up;
+// inviteCode is remembered for the sign-up callback.
+func (c *Client) GetGoogleAuthURL(ctx context.Context, purpose GoogleAuthPurpose, inviteCode string) (*GoogleAuthURL, error) {
+	query := url.Values{"purpose": {string(purpose)}}
+	if inviteCode != "" {
+		query.Set("invite_code", inviteCode)
+	}
+
+	var out GoogleAuthURL
+	if err := c.call(ctx, &request{method: http.MethodGet, path: "/api/auth/google/url", query: query}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
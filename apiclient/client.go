@@ -0,0 +1,303 @@
+// Package apiclient is a Go client for the server API. Its types follow the
+// OpenAPI document in server/internal/pkg/openapi/openapi.yml; keep the two
+// in step when an endpoint changes.
+//
+// Every method returns the typed body of a 2xx response, or an *Error built
+// from the problem the server answered with. Both carry the status code,
+// headers and cookies of the response, so callers that sit between a browser
+// and the API can pass Set-Cookie on.
+package apiclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTimeout    = 10 * time.Second
+	defaultRetries    = 2
+	defaultRetryDelay = 200 * time.Millisecond
+	maxRetryDelay     = 5 * time.Second
+
+	jsonContentType       = "application/json"
+	mergePatchContentType = "application/merge-patch+json"
+
+	// CSRFCookieName and CSRFHeaderName are the double-submit pair the API
+	// checks on state-changing requests.
+	CSRFCookieName = "csrf_token"
+	CSRFHeaderName = "X-CSRF-Token"
+)
+
+// Client calls the API at a base URL. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	retries    int
+	retryDelay time.Duration
+	userAgent  string
+	token      string
+}
+
+type Option func(*Client)
+
+// WithHTTPClient sets the client requests are sent with, for example one whose
+// transport adds tracing or the caller's cookies.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetries sets how many times an idempotent request is retried after a
+// network error or a 429, 502, 503 or 504 answer. Zero turns retries off.
+func WithRetries(retries int) Option {
+	return func(c *Client) {
+		c.retries = retries
+	}
+}
+
+// WithRetryDelay sets the delay before the first retry; it doubles on each
+// further attempt unless the server sends Retry-After.
+func WithRetryDelay(delay time.Duration) Option {
+	return func(c *Client) {
+		c.retryDelay = delay
+	}
+}
+
+// WithBearerToken authenticates every request with a token from IssueToken
+// instead of the session cookie; such requests need no CSRF token.
+func WithBearerToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		retries:    defaultRetries,
+		retryDelay: defaultRetryDelay,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Response describes the HTTP response a result or an error was read from.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Cookies    []*http.Cookie
+}
+
+func newResponse(resp *http.Response) Response {
+	return Response{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Cookies:    resp.Cookies(),
+	}
+}
+
+// request is a call to make; the body is kept as bytes so retries can send it
+// again.
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	header      http.Header
+	body        []byte
+	contentType string
+}
+
+func jsonRequest(method, path string, body any) (*request, error) {
+	return encodedRequest(method, path, jsonContentType, body)
+}
+
+func encodedRequest(method, path, contentType string, body any) (*request, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	return &request{method: method, path: path, body: data, contentType: contentType}, nil
+}
+
+// call sends req and decodes a successful response into out, which must
+// embed Response. A non-2xx answer is returned as an *Error.
+func (c *Client) call(ctx context.Context, req *request, out responseTarget) error {
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newError(resp)
+	}
+
+	out.setResponse(newResponse(resp))
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// do sends req, retrying idempotent methods on transient failures.
+func (c *Client) do(ctx context.Context, req *request) (*http.Response, error) {
+	retries := 0
+	if isIdempotent(req.method) {
+		retries = c.retries
+	}
+
+	delay := c.retryDelay
+	for attempt := 0; ; attempt++ {
+		httpReq, err := c.newHTTPRequest(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.httpClient.Do(httpReq)
+		if attempt >= retries || !shouldRetry(ctx, resp, err) {
+			if err != nil {
+				return nil, fmt.Errorf("failed to make request: %w", err)
+			}
+			return resp, nil
+		}
+
+		wait := delay
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				wait = retryAfter
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		wait = min(wait, maxRetryDelay)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+func (c *Client) newHTTPRequest(ctx context.Context, req *request) (*http.Request, error) {
+	target := c.baseURL + req.path
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+
+	var body io.Reader
+	if req.body != nil {
+		body = bytes.NewReader(req.body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	for key, values := range req.header {
+		httpReq.Header[key] = values
+	}
+	if req.contentType != "" {
+		httpReq.Header.Set("Content-Type", req.contentType)
+	}
+	httpReq.Header.Set("Accept", jsonContentType)
+	if c.userAgent != "" {
+		httpReq.Header.Set("User-Agent", c.userAgent)
+	}
+	if c.token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	for _, cookie := range CookiesFromContext(ctx) {
+		httpReq.AddCookie(cookie)
+	}
+	setCSRFHeader(httpReq)
+
+	return httpReq, nil
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as a date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+// Page selects a page of a list that is read newest first. Zero values use
+// the server's defaults.
+type Page struct {
+	Limit  int
+	Before int64
+}
+
+func (p Page) query() url.Values {
+	query := url.Values{}
+	if p.Limit > 0 {
+		query.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Before > 0 {
+		query.Set("before", strconv.FormatInt(p.Before, 10))
+	}
+	return query
+}
+
+// responseTarget is implemented by every result type through its embedded
+// Response.
+type responseTarget interface {
+	setResponse(Response)
+}
+
+func (r *Response) setResponse(resp Response) {
+	*r = resp
+}
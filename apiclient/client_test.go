@@ -0,0 +1,291 @@
+package apiclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyServer answers status for the first failures requests and 200 with
+// body afterwards.
+func flakyServer(t *testing.T, failures int32, status int, header http.Header, body string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) <= failures {
+			for key, values := range header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("Content-Type", jsonContentType)
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &attempts
+}
+
+func TestClient_Retries(t *testing.T) {
+	tests := []struct {
+		name             string
+		status           int
+		failures         int32
+		call             func(c *Client) error
+		expectedAttempts int32
+		expectedStatus   int
+	}{
+		{
+			name:             "get recovers after unavailable",
+			status:           http.StatusServiceUnavailable,
+			failures:         2,
+			call:             func(c *Client) error { _, err := c.GetAuthStatus(context.Background()); return err },
+			expectedAttempts: 3,
+		},
+		{
+			name:             "get gives up after the configured retries",
+			status:           http.StatusBadGateway,
+			failures:         5,
+			call:             func(c *Client) error { _, err := c.GetAuthStatus(context.Background()); return err },
+			expectedAttempts: 3,
+			expectedStatus:   http.StatusBadGateway,
+		},
+		{
+			name:             "post is not retried",
+			status:           http.StatusServiceUnavailable,
+			failures:         1,
+			call:             func(c *Client) error { _, err := c.Logout(context.Background()); return err },
+			expectedAttempts: 1,
+			expectedStatus:   http.StatusServiceUnavailable,
+		},
+		{
+			name:             "client errors are not retried",
+			status:           http.StatusBadRequest,
+			failures:         1,
+			call:             func(c *Client) error { _, err := c.GetAuthStatus(context.Background()); return err },
+			expectedAttempts: 1,
+			expectedStatus:   http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, attempts := flakyServer(t, tt.failures, tt.status, nil, `{"authenticated":true,"message":"ok"}`)
+			c := New(srv.URL, WithRetries(2), WithRetryDelay(time.Millisecond))
+
+			err := tt.call(c)
+
+			if got := attempts.Load(); got != tt.expectedAttempts {
+				t.Errorf("expected %d attempts, got %d", tt.expectedAttempts, got)
+			}
+			if tt.expectedStatus == 0 {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			} else if !IsStatus(err, tt.expectedStatus) {
+				t.Errorf("expected status %d, got %v", tt.expectedStatus, err)
+			}
+		})
+	}
+}
+
+func TestClient_RetryResendsBody(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(data))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusGatewayTimeout)
+			return
+		}
+		io.WriteString(w, `{}`)
+	}))
+	defer srv.Close()
+
+	req, err := jsonRequest(http.MethodPut, "/resource", map[string]string{"name": "Ada"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var out Response
+	c := New(srv.URL, WithRetryDelay(time.Millisecond))
+	if err := c.call(context.Background(), req, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(bodies) != 2 || bodies[0] != `{"name":"Ada"}` || bodies[1] != bodies[0] {
+		t.Errorf("expected the body to be sent twice, got %q", bodies)
+	}
+}
+
+func TestClient_RetryAfterOverridesBackoff(t *testing.T) {
+	srv, attempts := flakyServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"0"}}, `{"authenticated":true}`)
+	c := New(srv.URL, WithRetryDelay(time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	status, err := c.GetAuthStatus(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !status.Authenticated || attempts.Load() != 2 {
+		t.Errorf("expected a second, successful attempt; got %+v after %d attempts", status, attempts.Load())
+	}
+}
+
+func TestClient_RetryWaitHonoursContext(t *testing.T) {
+	srv, attempts := flakyServer(t, 5, http.StatusServiceUnavailable, nil, `{}`)
+	c := New(srv.URL, WithRetryDelay(time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := c.GetAuthStatus(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline to end the wait, got %v", err)
+	}
+	if attempts.Load() != 1 {
+		t.Errorf("expected 1 attempt, got %d", attempts.Load())
+	}
+}
+
+func TestClient_RetriesNetworkErrors(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+			return
+		}
+		io.WriteString(w, `{"authenticated":true}`)
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, WithRetryDelay(time.Millisecond))
+	if _, err := c.GetAuthStatus(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if attempts.Load() != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts.Load())
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      string
+		expectOK   bool
+		expectWait func(time.Duration) bool
+	}{
+		{name: "empty", value: ""},
+		{name: "seconds", value: "3", expectOK: true, expectWait: func(d time.Duration) bool { return d == 3*time.Second }},
+		{name: "negative seconds", value: "-1"},
+		{name: "garbage", value: "soon"},
+		{
+			name:       "future date",
+			value:      time.Now().Add(time.Minute).UTC().Format(http.TimeFormat),
+			expectOK:   true,
+			expectWait: func(d time.Duration) bool { return d > 50*time.Second && d <= time.Minute },
+		},
+		{
+			name:       "past date",
+			value:      time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat),
+			expectOK:   true,
+			expectWait: func(d time.Duration) bool { return d == 0 },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wait, ok := parseRetryAfter(tt.value)
+			if ok != tt.expectOK {
+				t.Fatalf("expected ok %v, got %v", tt.expectOK, ok)
+			}
+			if ok && !tt.expectWait(wait) {
+				t.Errorf("unexpected wait %v", wait)
+			}
+		})
+	}
+}
+
+func TestClient_RequestHeaders(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         []Option
+		cookies      []*http.Cookie
+		expectCookie string
+		expectCSRF   string
+		expectAuth   string
+	}{
+		{
+			name:         "browser cookies with csrf token",
+			cookies:      []*http.Cookie{{Name: "session_id", Value: "s1"}, {Name: CSRFCookieName, Value: "c1"}},
+			expectCookie: "session_id=s1; csrf_token=c1",
+			expectCSRF:   "c1",
+		},
+		{
+			name:         "cookies without csrf token",
+			cookies:      []*http.Cookie{{Name: "session_id", Value: "s1"}},
+			expectCookie: "session_id=s1",
+		},
+		{
+			name:       "bearer token",
+			opts:       []Option{WithBearerToken("tok")},
+			expectAuth: "Bearer tok",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *http.Request
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r
+				io.WriteString(w, `{"message":"ok"}`)
+			}))
+			defer srv.Close()
+
+			c := New(srv.URL, append(tt.opts, WithUserAgent("test-agent"))...)
+			ctx := WithCookies(context.Background(), tt.cookies)
+			if _, err := c.Logout(ctx); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if cookie := got.Header.Get("Cookie"); cookie != tt.expectCookie {
+				t.Errorf("expected cookie %q, got %q", tt.expectCookie, cookie)
+			}
+			if csrf := got.Header.Get(CSRFHeaderName); csrf != tt.expectCSRF {
+				t.Errorf("expected csrf header %q, got %q", tt.expectCSRF, csrf)
+			}
+			if auth := got.Header.Get("Authorization"); auth != tt.expectAuth {
+				t.Errorf("expected authorization %q, got %q", tt.expectAuth, auth)
+			}
+			if ua := got.Header.Get("User-Agent"); ua != "test-agent" {
+				t.Errorf("expected user agent test-agent, got %q", ua)
+			}
+		})
+	}
+}
+
+func TestClient_ResponseMetadata(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session_id", Value: "", MaxAge: -1})
+		w.Header().Set("ETag", `"v2"`)
+		io.WriteString(w, `{"id":1}`)
+	}))
+	defer srv.Close()
+
+	profile, err := New(srv.URL).GetProfile(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if profile.StatusCode != http.StatusOK || profile.ETag != `"v2"` {
+		t.Errorf("unexpected response %+v", profile.Response)
+	}
+	if len(profile.Cookies) != 1 || profile.Cookies[0].Name != "session_id" {
+		t.Errorf("expected the session cookie to be passed on, got %v", profile.Cookies)
+	}
+}
@@ -0,0 +1,31 @@
+package apiclient
+
+import (
+	"context"
+	"net/http"
+)
+
+type cookiesKey struct{}
+
+// WithCookies returns a context whose requests carry cookies, such as the
+// session and CSRF cookies a browser sent to a service calling the API on
+// its behalf.
+func WithCookies(ctx context.Context, cookies []*http.Cookie) context.Context {
+	return context.WithValue(ctx, cookiesKey{}, cookies)
+}
+
+func CookiesFromContext(ctx context.Context) []*http.Cookie {
+	cookies, _ := ctx.Value(cookiesKey{}).([]*http.Cookie)
+	return cookies
+}
+
+// setCSRFHeader copies the CSRF cookie into the header the API compares it
+// with, unless the header is already set.
+func setCSRFHeader(req *http.Request) {
+	if req.Header.Get(CSRFHeaderName) != "" {
+		return
+	}
+	if cookie, err := req.Cookie(CSRFCookieName); err == nil && cookie.Value != "" {
+		req.Header.Set(CSRFHeaderName, cookie.Value)
+	}
+}
@@ -0,0 +1,100 @@
+package apiclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Codes the API answers with that callers commonly branch on. The full list
+// is kept in server/internal/pkg/httptools/errors.go.
+const (
+	CodeUnauthorized             = "unauthorized"
+	CodeSessionNotFound          = "session_not_found"
+	CodeInvalidCSRFToken         = "invalid_csrf_token"
+	CodeValidationFailed         = "validation_failed"
+	CodeInvalidRequest           = "invalid_request"
+	CodeProfileVersionMismatch   = "profile_version_mismatch"
+	CodeReauthenticationRequired = "reauthentication_required"
+	CodeTooManyRequests          = "too_many_requests"
+	CodeInsufficientScope        = "insufficient_scope"
+	CodeInvalidRefreshToken      = "invalid_refresh_token"
+	CodeRefreshTokenReused       = "refresh_token_reused"
+)
+
+// maxErrorBodySize bounds how much of an error body is read.
+const maxErrorBodySize = 64 << 10
+
+// Error is a non-2xx answer from the API, read from its RFC 7807 problem
+// body. Code is stable and meant to be matched; Detail is English text for
+// developers.
+type Error struct {
+	Response
+	Type      string
+	Title     string
+	Code      string
+	Detail    string
+	RequestID string
+	Fields    map[string]string
+}
+
+type problemBody struct {
+	Type      string            `json:"type"`
+	Title     string            `json:"title"`
+	Detail    string            `json:"detail"`
+	Code      string            `json:"code"`
+	RequestID string            `json:"request_id"`
+	Fields    map[string]string `json:"fields"`
+	Error     string            `json:"error"`
+}
+
+func newError(resp *http.Response) *Error {
+	apiErr := &Error{Response: newResponse(resp)}
+
+	var problem problemBody
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxErrorBodySize)).Decode(&problem); err == nil {
+		apiErr.Type = problem.Type
+		apiErr.Title = problem.Title
+		apiErr.Code = problem.Code
+		apiErr.Detail = problem.Detail
+		apiErr.RequestID = problem.RequestID
+		apiErr.Fields = problem.Fields
+		if apiErr.Detail == "" {
+			apiErr.Detail = problem.Error
+		}
+	}
+	if apiErr.Detail == "" {
+		apiErr.Detail = fmt.Sprintf("unexpected status %d", resp.StatusCode)
+	}
+	return apiErr
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("api: status %d: %s", e.StatusCode, e.Detail)
+	}
+	return fmt.Sprintf("api: status %d: %s: %s", e.StatusCode, e.Code, e.Detail)
+}
+
+// AsError returns the *Error in err's chain, if there is one.
+func AsError(err error) (*Error, bool) {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	return nil, false
+}
+
+// IsCode reports whether err is an API error with the given code.
+func IsCode(err error, code string) bool {
+	apiErr, ok := AsError(err)
+	return ok && apiErr.Code == code
+}
+
+// IsStatus reports whether err is an API error with the given status code.
+func IsStatus(err error, statusCode int) bool {
+	apiErr, ok := AsError(err)
+	return ok && apiErr.StatusCode == statusCode
+}
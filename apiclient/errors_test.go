@@ -0,0 +1,97 @@
+package apiclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_ProblemDecoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		expected Error
+	}{
+		{
+			name:   "problem details",
+			status: http.StatusUnprocessableEntity,
+			body: `{"type":"https://example.com/problems/validation_failed","title":"Unprocessable Entity",` +
+				`"detail":"validation failed","code":"validation_failed","request_id":"req-1","fields":{"handle":"is taken"}}`,
+			expected: Error{
+				Type:      "https://example.com/problems/validation_failed",
+				Title:     "Unprocessable Entity",
+				Code:      CodeValidationFailed,
+				Detail:    "validation failed",
+				RequestID: "req-1",
+				Fields:    map[string]string{"handle": "is taken"},
+			},
+		},
+		{
+			name:     "legacy error body",
+			status:   http.StatusBadRequest,
+			body:     `{"error":"bad input data"}`,
+			expected: Error{Detail: "bad input data"},
+		},
+		{
+			name:     "not json",
+			status:   http.StatusBadGateway,
+			body:     `<html>bad gateway</html>`,
+			expected: Error{Detail: "unexpected status 502"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/problem+json")
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			}))
+			defer srv.Close()
+
+			_, err := New(srv.URL, WithRetries(0)).GetAuthStatus(context.Background())
+
+			apiErr, ok := AsError(fmt.Errorf("wrapped: %w", err))
+			if !ok {
+				t.Fatalf("expected an *Error, got %v", err)
+			}
+			if apiErr.StatusCode != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, apiErr.StatusCode)
+			}
+			if apiErr.Header.Get("Content-Type") != "application/problem+json" {
+				t.Errorf("expected the response headers, got %v", apiErr.Header)
+			}
+			if apiErr.Type != tt.expected.Type || apiErr.Title != tt.expected.Title || apiErr.Code != tt.expected.Code ||
+				apiErr.Detail != tt.expected.Detail || apiErr.RequestID != tt.expected.RequestID {
+				t.Errorf("unexpected error %+v", apiErr)
+			}
+			if len(apiErr.Fields) != len(tt.expected.Fields) || apiErr.Fields["handle"] != tt.expected.Fields["handle"] {
+				t.Errorf("expected fields %v, got %v", tt.expected.Fields, apiErr.Fields)
+			}
+			if !IsStatus(err, tt.status) || IsCode(err, CodeUnauthorized) {
+				t.Error("unexpected IsStatus or IsCode result")
+			}
+			if tt.expected.Code != "" && !IsCode(err, tt.expected.Code) {
+				t.Errorf("expected IsCode(%q) to hold", tt.expected.Code)
+			}
+		})
+	}
+}
+
+func TestError_Error(t *testing.T) {
+	withCode := &Error{Response: Response{StatusCode: 401}, Code: CodeSessionNotFound, Detail: "session not found"}
+	if got := withCode.Error(); got != "api: status 401: session_not_found: session not found" {
+		t.Errorf("unexpected message %q", got)
+	}
+	withoutCode := &Error{Response: Response{StatusCode: 502}, Detail: "unexpected status 502"}
+	if got := withoutCode.Error(); got != "api: status 502: unexpected status 502" {
+		t.Errorf("unexpected message %q", got)
+	}
+	if _, ok := AsError(fmt.Errorf("plain")); ok {
+		t.Error("expected a plain error not to be an *Error")
+	}
+}
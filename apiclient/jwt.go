@@ -0,0 +1,84 @@
+package apiclient
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// JWTTokenPair is a signed access token for services that verify it with
+// the keys from GetJWKS, and the refresh token to get the next pair with.
+type JWTTokenPair struct {
+	Response              `json:"-"`
+	AccessToken           string    `json:"access_token"`
+	TokenType             string    `json:"token_type"`
+	ExpiresIn             int64     `json:"expires_in"`
+	ExpiresAt             time.Time `json:"expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+}
+
+// JWK is a public key from the JWK set. Ed25519 keys set Curve and X, RSA
+// keys N and E.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+type JWKS struct {
+	Response `json:"-"`
+	Keys     []JWK `json:"keys"`
+}
+
+type refreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// IssueJWT logs in for a JWT access token, or refreshes one with
+// GrantTypeRefreshToken. The access token is not accepted by this API.
+func (c *Client) IssueJWT(ctx context.Context, in TokenRequest) (*JWTTokenPair, error) {
+	req, err := jsonRequest(http.MethodPost, "/api/auth/jwt", in)
+	if err != nil {
+		return nil, err
+	}
+	var out JWTTokenPair
+	if err := c.call(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RefreshJWT exchanges a refresh token for a new pair. A refresh token can
+// only be used once: keep the one returned, since using an old one again
+// fails with CodeRefreshTokenReused and ends the login.
+func (c *Client) RefreshJWT(ctx context.Context, refreshToken string) (*JWTTokenPair, error) {
+	return c.IssueJWT(ctx, TokenRequest{GrantType: GrantTypeRefreshToken, RefreshToken: refreshToken})
+}
+
+// RevokeJWT ends the login a refresh token belongs to.
+func (c *Client) RevokeJWT(ctx context.Context, refreshToken string) (*Response, error) {
+	req, err := jsonRequest(http.MethodPost, "/api/auth/jwt/revoke", refreshTokenRequest{RefreshToken: refreshToken})
+	if err != nil {
+		return nil, err
+	}
+	var out Response
+	if err := c.call(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetJWKS returns the public keys JWT access tokens are signed with.
+func (c *Client) GetJWKS(ctx context.Context) (*JWKS, error) {
+	var out JWKS
+	if err := c.call(ctx, &request{method: http.MethodGet, path: "/.well-known/jwks.json"}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,54 @@
+package apiclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type Organization struct {
+	Response  `json:"-"`
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	JoinedAt  time.Time `json:"joined_at"`
+}
+
+type Invitation struct {
+	Response         `json:"-"`
+	ID               int64     `json:"id"`
+	OrganizationID   int64     `json:"organization_id"`
+	OrganizationName string    `json:"organization_name"`
+	Email            string    `json:"email"`
+	Role             string    `json:"role"`
+	CreatedAt        time.Time `json:"created_at"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+// GetInvitation looks up the invitation an emailed token belongs to.
+func (c *Client) GetInvitation(ctx context.Context, token string) (*Invitation, error) {
+	query := url.Values{"token": {token}}
+
+	var out Invitation
+	if err := c.call(ctx, &request{method: http.MethodGet, path: "/api/organizations/invitation", query: query}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AcceptInvitation joins the organization the token invites to.
+func (c *Client) AcceptInvitation(ctx context.Context, token string) (*Organization, error) {
+	req, err := jsonRequest(http.MethodPost, "/api/organizations/invitation/accept", struct {
+		Token string `json:"token"`
+	}{Token: token})
+	if err != nil {
+		return nil, err
+	}
+	var out Organization
+	if err := c.call(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,316 @@
+package apiclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type ProfileVisibility struct {
+	FullName string `json:"full_name,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Phone    string `json:"phone,omitempty"`
+	Avatar   string `json:"avatar,omitempty"`
+}
+
+type Profile struct {
+	Response        `json:"-"`
+	FullName        string            `json:"full_name"`
+	Phone           string            `json:"phone"`
+	PhoneVerifiedAt *time.Time        `json:"phone_verified_at"`
+	Email           string            `json:"email"`
+	PendingEmail    string            `json:"pending_email"`
+	AvatarURL       string            `json:"avatar_url"`
+	CustomFields    map[string]string `json:"custom_fields"`
+	Handle          string            `json:"handle"`
+	Public          bool              `json:"public"`
+	Visibility      ProfileVisibility `json:"visibility"`
+	// ETag is the profile version to send back with PatchProfile.
+	ETag string `json:"-"`
+}
+
+// ProfilePatch is a JSON merge patch of the profile; nil members are left
+// out and so keep their value. CustomFields, when set, is merged key by key.
+type ProfilePatch struct {
+	FullName     *string            `json:"full_name,omitempty"`
+	Phone        *string            `json:"phone,omitempty"`
+	Email        *string            `json:"email,omitempty"`
+	CustomFields map[string]string  `json:"custom_fields,omitempty"`
+	Handle       *string            `json:"handle,omitempty"`
+	Public       *bool              `json:"public,omitempty"`
+	Visibility   *ProfileVisibility `json:"visibility,omitempty"`
+}
+
+type ProfileFieldRules struct {
+	MinLength int      `json:"min_length"`
+	MaxLength int      `json:"max_length"`
+	Pattern   string   `json:"pattern"`
+	Min       *float64 `json:"min"`
+	Max       *float64 `json:"max"`
+	Options   []string `json:"options"`
+}
+
+type ProfileField struct {
+	Key        string            `json:"key"`
+	Label      string            `json:"label"`
+	Type       string            `json:"type"`
+	Rules      ProfileFieldRules `json:"rules"`
+	Required   bool              `json:"required"`
+	Visibility string            `json:"visibility"`
+	Position   int               `json:"position"`
+}
+
+type ProfileFields struct {
+	Response `json:"-"`
+	Fields   []ProfileField `json:"fields"`
+}
+
+type ProfileChange struct {
+	ID        int64     `json:"id"`
+	Field     string    `json:"field"`
+	OldValue  *string   `json:"old_value"`
+	NewValue  *string   `json:"new_value"`
+	SessionID string    `json:"session_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ProfileHistory struct {
+	Response   `json:"-"`
+	Changes    []ProfileChange `json:"changes"`
+	NextBefore int64           `json:"next_before"`
+}
+
+type AccountDeletion struct {
+	Response            `json:"-"`
+	Message             string    `json:"message"`
+	DeletionScheduledAt time.Time `json:"deletion_scheduled_at"`
+}
+
+type EmailChange struct {
+	Response  `json:"-"`
+	Message   string    `json:"message"`
+	NewEmail  string    `json:"new_email"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type PhoneVerification struct {
+	Response  `json:"-"`
+	Message   string    `json:"message"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type Avatar struct {
+	Response  `json:"-"`
+	Message   string `json:"message"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+type DataExport struct {
+	Response    `json:"-"`
+	ID          string     `json:"id"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+type PublicProfileField struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type PublicProfile struct {
+	Response     `json:"-"`
+	Handle       string               `json:"handle"`
+	FullName     string               `json:"full_name"`
+	Email        string               `json:"email"`
+	Phone        string               `json:"phone"`
+	AvatarURL    string               `json:"avatar_url"`
+	CustomFields []PublicProfileField `json:"custom_fields"`
+}
+
+type DirectoryUser struct {
+	Handle    string `json:"handle"`
+	FullName  string `json:"full_name"`
+	Email     string `json:"email"`
+	Phone     string `json:"phone"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+type Directory struct {
+	Response `json:"-"`
+	Users    []DirectoryUser `json:"users"`
+	// NextCursor is set when a full page was returned.
+	NextCursor string `json:"next_cursor"`
+}
+
+func (c *Client) GetProfile(ctx context.Context) (*Profile, error) {
+	var out Profile
+	if err := c.call(ctx, &request{method: http.MethodGet, path: "/api/profile"}, &out); err != nil {
+		return nil, err
+	}
+	out.ETag = out.Header.Get("ETag")
+	return &out, nil
+}
+
+// PatchProfile applies patch if the profile still has version etag; the API
+// answers 412 with CodeProfileVersionMismatch when it has changed since.
+func (c *Client) PatchProfile(ctx context.Context, patch ProfilePatch, etag string) (*Profile, error) {
+	req, err := encodedRequest(http.MethodPatch, "/api/profile", mergePatchContentType, patch)
+	if err != nil {
+		return nil, err
+	}
+	req.header = http.Header{"If-Match": {etag}}
+
+	var out Profile
+	if err := c.call(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	out.ETag = out.Header.Get("ETag")
+	return &out, nil
+}
+
+func (c *Client) GetProfileFields(ctx context.Context) (*ProfileFields, error) {
+	var out ProfileFields
+	if err := c.call(ctx, &request{method: http.MethodGet, path: "/api/profile/fields"}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) GetProfileHistory(ctx context.Context, page Page) (*ProfileHistory, error) {
+	var out ProfileHistory
+	if err := c.call(ctx, &request{method: http.MethodGet, path: "/api/profile/history", query: page.query()}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteAccount schedules the account for deletion and ends the session.
+// password is required for accounts that have one.
+func (c *Client) DeleteAccount(ctx context.Context, password string) (*AccountDeletion, error) {
+	req, err := jsonRequest(http.MethodDelete, "/api/profile", struct {
+		Password string `json:"password"`
+	}{Password: password})
+	if err != nil {
+		return nil, err
+	}
+	var out AccountDeletion
+	if err := c.call(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) RequestEmailChange(ctx context.Context, email string) (*EmailChange, error) {
+	req, err := jsonRequest(http.MethodPost, "/api/profile/email", struct {
+		Email string `json:"email"`
+	}{Email: email})
+	if err != nil {
+		return nil, err
+	}
+	var out EmailChange
+	if err := c.call(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) RequestPhoneVerification(ctx context.Context) (*PhoneVerification, error) {
+	var out PhoneVerification
+	if err := c.call(ctx, &request{method: http.MethodPost, path: "/api/profile/phone/verification"}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) ConfirmPhoneVerification(ctx context.Context, code string) (*Message, error) {
+	req, err := jsonRequest(http.MethodPost, "/api/profile/phone/verification/confirm", struct {
+		Code string `json:"code"`
+	}{Code: code})
+	if err != nil {
+		return nil, err
+	}
+	var out Message
+	if err := c.call(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UploadAvatar replaces the avatar with the image in data; the API checks the
+// format and size.
+func (c *Client) UploadAvatar(ctx context.Context, filename string, data []byte) (*Avatar, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("avatar", filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create multipart body: %w", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to write multipart body: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close multipart body: %w", err)
+	}
+
+	req := &request{
+		method:      http.MethodPut,
+		path:        "/api/profile/avatar",
+		body:        body.Bytes(),
+		contentType: writer.FormDataContentType(),
+	}
+	var out Avatar
+	if err := c.call(ctx, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RequestDataExport queues an export of everything stored about the user.
+func (c *Client) RequestDataExport(ctx context.Context) (*DataExport, error) {
+	var out DataExport
+	if err := c.call(ctx, &request{method: http.MethodPost, path: "/api/profile/export"}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) GetLatestDataExport(ctx context.Context) (*DataExport, error) {
+	var out DataExport
+	if err := c.call(ctx, &request{method: http.MethodGet, path: "/api/profile/export"}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetPublicProfile returns the profile published under handle as the caller,
+// signed in or not, may see it.
+func (c *Client) GetPublicProfile(ctx context.Context, handle string) (*PublicProfile, error) {
+	var out PublicProfile
+	if err := c.call(ctx, &request{method: http.MethodGet, path: "/api/users/" + url.PathEscape(handle)}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SearchDirectory looks users up by name or email; cursor continues from the
+// NextCursor of a previous page.
+func (c *Client) SearchDirectory(ctx context.Context, query, cursor string) (*Directory, error) {
+	params := url.Values{"q": {query}}
+	if cursor != "" {
+		params.Set("cursor", cursor)
+	}
+
+	var out Directory
+	if err := c.call(ctx, &request{method: http.MethodGet, path: "/api/users", query: params}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
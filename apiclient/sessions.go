@@ -0,0 +1,58 @@
+package apiclient
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+type AuthStatus struct {
+	Response      `json:"-"`
+	Authenticated bool `json:"authenticated"`
+}
+
+type AuditEvent struct {
+	ID            int64             `json:"id"`
+	ActorUserID   *int64            `json:"actor_user_id"`
+	SubjectUserID *int64            `json:"subject_user_id"`
+	Type          string            `json:"type"`
+	IP            string            `json:"ip"`
+	UserAgent     string            `json:"user_agent"`
+	Outcome       string            `json:"outcome"`
+	Details       map[string]string `json:"details"`
+	CreatedAt     time.Time         `json:"created_at"`
+}
+
+type AuditEvents struct {
+	Response   `json:"-"`
+	Events     []AuditEvent `json:"events"`
+	NextBefore int64        `json:"next_before"`
+}
+
+// GetAuthStatus reports whether the session cookie is valid. The answer also
+// sets the CSRF cookie later state-changing calls need.
+func (c *Client) GetAuthStatus(ctx context.Context) (*AuthStatus, error) {
+	var out AuthStatus
+	if err := c.call(ctx, &request{method: http.MethodGet, path: "/api/auth/status"}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Logout ends the session; the result's Cookies clear the session cookie.
+func (c *Client) Logout(ctx context.Context) (*Message, error) {
+	var out Message
+	if err := c.call(ctx, &request{method: http.MethodPost, path: "/api/auth/logout"}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetActivity lists the security events of the signed-in user, newest first.
+func (c *Client) GetActivity(ctx context.Context, page Page) (*AuditEvents, error) {
+	var out AuditEvents
+	if err := c.call(ctx, &request{method: http.MethodGet, path: "/api/auth/activity", query: page.query()}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
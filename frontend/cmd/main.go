@@ -11,23 +11,27 @@ import (
 	"syscall"
 	"time"
 
+	"apiclient"
 	"frontend/internal/config"
 	authDelivery "frontend/internal/delivery/auth"
 	profileDelivery "frontend/internal/delivery/profile"
 	authGateway "frontend/internal/gateway/auth"
 	profileGateway "frontend/internal/gateway/profile"
+	"frontend/internal/pkg/health"
+	"frontend/internal/pkg/httpclient"
 	"frontend/internal/pkg/logging"
 	"frontend/internal/pkg/ping"
 	"frontend/internal/pkg/proxy"
+	"frontend/internal/pkg/tracing"
 )
 
 func main() {
 	configPath := flag.String("config", "config.yml", "path to config file")
 	flag.Parse()
 
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+	logger := slog.New(logging.NewContextHandler(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
 		Level: slog.LevelDebug,
-	}))
+	})))
 
 	cfg, err := config.Load(*configPath)
 	if err != nil {
@@ -35,25 +39,49 @@ func main() {
 		os.Exit(1)
 	}
 
+	shutdownTracing := func(context.Context) error { return nil }
+	if cfg.Tracing.Enabled {
+		shutdownTracing, err = tracing.Setup(context.Background(), tracing.Config{
+			ServiceName:  cfg.Tracing.ServiceName,
+			OTLPEndpoint: cfg.Tracing.OTLPEndpoint,
+			File:         cfg.Tracing.File,
+			SampleRatio:  cfg.Tracing.SampleRatio,
+		})
+		if err != nil {
+			logger.Error("failed to set up tracing", "error", err)
+			os.Exit(1)
+		}
+		logger.Info("tracing enabled", "otlp_endpoint", cfg.Tracing.OTLPEndpoint, "file", cfg.Tracing.File)
+	}
+
 	templates, err := template.ParseGlob("templates/*.html")
 	if err != nil {
 		logger.Error("failed to parse templates", "error", err)
 		os.Exit(1)
 	}
 
-	authGW := authGateway.NewGateway(cfg.API.BaseURL)
-	profileGW := profileGateway.NewGateway(cfg.API.BaseURL)
+	// Both gateways share one API client. It forwards the browser's cookies
+	// from the request context; its transport adds the request ID and the
+	// trace context.
+	apiClient := apiclient.New(cfg.API.BaseURL,
+		apiclient.WithHTTPClient(httpclient.New(&http.Client{Timeout: 10 * time.Second})))
+	authGW := authGateway.NewGateway(apiClient)
+	profileGW := profileGateway.NewGateway(apiClient)
 
+	// An unreachable backend only fails readiness; the frontend keeps running
+	// and recovers once the API server is up.
 	pingClient := ping.NewClient(cfg.API.BaseURL)
 	pingCtx, pingCancel := context.WithTimeout(context.Background(), 5*time.Second)
 	if err := pingClient.Ping(pingCtx); err != nil {
-		logger.Error("backend is not available", "error", err, "api", cfg.API.BaseURL)
-		os.Exit(1)
+		logger.Warn("backend is not available yet", "error", err, "api", cfg.API.BaseURL)
 	} else {
 		logger.Info("backend is available", "api", cfg.API.BaseURL)
 	}
 	pingCancel()
 
+	healthChecker := health.NewChecker(cfg.Health.CheckTimeout)
+	healthChecker.Add("backend", pingClient.Ping)
+
 	authHandler := authDelivery.NewHandler(logger, templates, authGW)
 	profileHandler := profileDelivery.NewHandler(logger, templates, profileGW)
 
@@ -66,6 +94,7 @@ func main() {
 		Templates:         templates,
 		LoggingMiddleware: loggingMiddleware,
 		ProxyHandler:      proxyHandler,
+		HealthChecker:     healthChecker,
 	})
 
 	server := &http.Server{
@@ -89,6 +118,11 @@ func main() {
 
 	<-quit
 	logger.Info("shutting down server...")
+	healthChecker.ShutDown()
+	if cfg.Health.ShutdownDelay > 0 {
+		logger.Info("waiting before closing the listener", "delay", cfg.Health.ShutdownDelay)
+		time.Sleep(cfg.Health.ShutdownDelay)
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -98,5 +132,9 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Error("failed to flush traces", "error", err)
+	}
+
 	logger.Info("server exited gracefully")
 }
@@ -8,8 +8,11 @@ import (
 	profileDelivery "frontend/internal/delivery/profile"
 	"frontend/internal/pkg/cache"
 	"frontend/internal/pkg/cookies"
+	"frontend/internal/pkg/health"
 	"frontend/internal/pkg/logging"
 	"frontend/internal/pkg/proxy"
+	"frontend/internal/pkg/requestid"
+	"frontend/internal/pkg/tracing"
 )
 
 type RoutesConfig struct {
@@ -18,6 +21,7 @@ type RoutesConfig struct {
 	Templates         *template.Template
 	LoggingMiddleware *logging.LoggingMiddleware
 	ProxyHandler      *proxy.ProxyHandler
+	HealthChecker     *health.Checker
 }
 
 func SetupRoutes(config RoutesConfig) http.Handler {
@@ -30,6 +34,9 @@ func SetupRoutes(config RoutesConfig) http.Handler {
 		mux.Handle("/api/", config.ProxyHandler)
 	}
 
+	mux.HandleFunc("/healthz", config.HealthChecker.Liveness)
+	mux.HandleFunc("/readyz", config.HealthChecker.Readiness)
+
 	mux.HandleFunc("/login", config.AuthHandler.LoginPage)
 	mux.HandleFunc("/login/google", config.AuthHandler.GoogleLogin)
 
@@ -40,6 +47,17 @@ func SetupRoutes(config RoutesConfig) http.Handler {
 
 	mux.HandleFunc("/profile", config.ProfileHandler.ViewProfile)
 	mux.HandleFunc("/profile/edit", config.ProfileHandler.EditProfile)
+	mux.HandleFunc("/profile/delete", config.ProfileHandler.DeleteAccount)
+	mux.HandleFunc("/profile/phone/send", config.ProfileHandler.SendPhoneCode)
+	mux.HandleFunc("/profile/phone/verify", config.ProfileHandler.VerifyPhone)
+	mux.HandleFunc("/profile/email", config.ProfileHandler.RequestEmailChange)
+	mux.HandleFunc("/profile/avatar", config.ProfileHandler.UploadAvatar)
+	mux.HandleFunc("/profile/export", config.ProfileHandler.RequestDataExport)
+	mux.HandleFunc("/profile/tokens", config.ProfileHandler.AccessTokens)
+	mux.HandleFunc("/profile/tokens/delete", config.ProfileHandler.DeleteAccessToken)
+	mux.HandleFunc("/u/{handle}", config.ProfileHandler.PublicProfile)
+	mux.HandleFunc("/directory", config.ProfileHandler.Directory)
+	mux.HandleFunc("/invitations/accept", config.ProfileHandler.AcceptInvitation)
 
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/" {
@@ -53,9 +71,10 @@ func SetupRoutes(config RoutesConfig) http.Handler {
 		http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
 	})
 
-	handler := config.LoggingMiddleware.AccessLog(mux)
+	handler := config.LoggingMiddleware.AccessLog(tracing.Middleware(mux))
 	handler = cookies.Middleware(handler)
 	handler = cache.NoCacheMiddleware(handler)
+	handler = requestid.Middleware(handler)
 	return handler
 }
 
@@ -3,13 +3,16 @@ package config
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	Server ServerConfig `yaml:"server"`
-	API    APIConfig    `yaml:"api"`
+	Server  ServerConfig  `yaml:"server"`
+	API     APIConfig     `yaml:"api"`
+	Tracing TracingConfig `yaml:"tracing"`
+	Health  HealthConfig  `yaml:"health"`
 }
 
 type ServerConfig struct {
@@ -20,6 +23,25 @@ type APIConfig struct {
 	BaseURL string `yaml:"base_url"`
 }
 
+// TracingConfig exports OpenTelemetry traces over OTLP/HTTP to OTLPEndpoint.
+// Without an endpoint spans are written as JSON to File, or to stdout when
+// File is empty.
+type TracingConfig struct {
+	Enabled      bool    `yaml:"enabled"`
+	ServiceName  string  `yaml:"service_name"`
+	OTLPEndpoint string  `yaml:"otlp_endpoint"`
+	File         string  `yaml:"file"`
+	SampleRatio  float64 `yaml:"sample_ratio"`
+}
+
+// HealthConfig bounds the /readyz checks. ShutdownDelay keeps serving with
+// readiness failing after a shutdown signal, so load balancers can take the
+// frontend out of rotation first.
+type HealthConfig struct {
+	CheckTimeout  time.Duration `yaml:"check_timeout"`
+	ShutdownDelay time.Duration `yaml:"shutdown_delay"`
+}
+
 func Load(configPath string) (*Config, error) {
 	data, err := os.ReadFile(configPath)
 	if err != nil {
@@ -32,6 +54,7 @@ func Load(configPath string) (*Config, error) {
 	}
 
 	applyEnvOverrides(&config)
+	applyDefaults(&config)
 
 	return &config, nil
 }
@@ -47,4 +70,29 @@ func applyEnvOverrides(config *Config) {
 	if val := os.Getenv("API_BASE_URL"); val != "" {
 		config.API.BaseURL = val
 	}
+
+	if val := os.Getenv("TRACING_ENABLED"); val != "" {
+		config.Tracing.Enabled = val == "true" || val == "1" || val == "yes"
+	}
+	if val := os.Getenv("OTEL_SERVICE_NAME"); val != "" {
+		config.Tracing.ServiceName = val
+	}
+	if val := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); val != "" {
+		config.Tracing.OTLPEndpoint = val
+	}
+	if val := os.Getenv("TRACING_FILE"); val != "" {
+		config.Tracing.File = val
+	}
+}
+
+func applyDefaults(config *Config) {
+	if config.Health.CheckTimeout <= 0 {
+		config.Health.CheckTimeout = 2 * time.Second
+	}
+	if config.Tracing.ServiceName == "" {
+		config.Tracing.ServiceName = "frontend"
+	}
+	if config.Tracing.SampleRatio <= 0 || config.Tracing.SampleRatio > 1 {
+		config.Tracing.SampleRatio = 1
+	}
 }
@@ -7,8 +7,9 @@ import (
 
 type AuthGateway interface {
 	Login(ctx context.Context, email, password string) (*domain.LoginResult, error)
-	SignUp(ctx context.Context, email, password string) (*domain.SignUpResult, error)
-	GetGoogleAuthURL(ctx context.Context, purpose domain.GoogleAuthPurpose) (*domain.GoogleAuthResult, error)
+	SignUp(ctx context.Context, email, password, inviteCode string) (*domain.SignUpResult, error)
+	GetSignUpPolicy(ctx context.Context) (*domain.SignUpPolicyResult, error)
+	GetGoogleAuthURL(ctx context.Context, purpose domain.GoogleAuthPurpose, inviteCode string) (*domain.GoogleAuthResult, error)
 	Logout(ctx context.Context) (*domain.LogoutResult, error)
 	CheckAuthStatus(ctx context.Context) (*domain.AuthStatusResult, error)
 }
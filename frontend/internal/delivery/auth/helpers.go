@@ -17,12 +17,17 @@ type pageData struct {
 	FooterText           string
 	FooterLink           string
 	FooterLinkText       string
+	// InviteRequired and AllowedDomains describe the sign-up policy.
+	InviteRequired bool
+	InviteCode     string
+	AllowedDomains []string
 }
 
 type pageDataOptions struct {
-	Email   string
-	Error   string
-	Message string
+	Email      string
+	Error      string
+	Message    string
+	InviteCode string
 }
 
 func newLoginPageData(opts pageDataOptions) pageData {
@@ -66,6 +71,7 @@ func newSignUpPageData(opts pageDataOptions) pageData {
 		FooterText:           "Already have an account?",
 		FooterLink:           "/login",
 		FooterLinkText:       "Sign In",
+		InviteCode:           opts.InviteCode,
 	}
 
 	if opts.Email != "" {
@@ -6,6 +6,7 @@ import (
 	"net/url"
 
 	"frontend/internal/domain"
+	"frontend/internal/pkg/i18n"
 )
 
 func (h *Handler) LoginPage(w http.ResponseWriter, r *http.Request) {
@@ -13,7 +14,7 @@ func (h *Handler) LoginPage(w http.ResponseWriter, r *http.Request) {
 	case http.MethodGet:
 		opts := pageDataOptions{}
 		if errorMsg := r.URL.Query().Get("error"); errorMsg != "" {
-			opts.Error = errorMsg
+			opts.Error = i18n.Message(r, r.URL.Query().Get("error_code"), errorMsg)
 		} else if successMsg := r.URL.Query().Get("success"); successMsg != "" {
 			opts.Message = successMsg
 		}
@@ -28,11 +29,11 @@ func (h *Handler) LoginPage(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) showLoginForm(w http.ResponseWriter, r *http.Request, data pageData) {
 	result, err := h.authGateway.CheckAuthStatus(r.Context())
 	if err != nil || result.Status == domain.ResponseStatusError {
-		h.logger.Error("failed to check auth status", "error", err)
+		h.logger.ErrorContext(r.Context(), "failed to check auth status", "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
-	h.logger.Info("auth status", "is_authenticated", result.IsAuthenticated)
+	h.logger.InfoContext(r.Context(), "auth status", "is_authenticated", result.IsAuthenticated)
 	if result.IsAuthenticated {
 		http.Redirect(w, r, "/profile", http.StatusSeeOther)
 		return
@@ -40,7 +41,7 @@ func (h *Handler) showLoginForm(w http.ResponseWriter, r *http.Request, data pag
 	setCookies(w, result.Cookies)
 	err = h.templates.ExecuteTemplate(w, "auth.html", data)
 	if err != nil {
-		h.logger.Error("failed to render login page", "error", err)
+		h.logger.ErrorContext(r.Context(), "failed to render login page", "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
 }
@@ -62,13 +63,13 @@ func (h *Handler) handleLogin(w http.ResponseWriter, r *http.Request) {
 
 	result, err := h.authGateway.Login(r.Context(), email, password)
 	if err != nil {
-		h.logger.Error("failed to login", "error", err)
+		h.logger.ErrorContext(r.Context(), "failed to login", "error", err)
 		http.Redirect(w, r, fmt.Sprintf("/login?error=%s", url.QueryEscape("Failed to connect to server")), http.StatusSeeOther)
 		return
 	}
 
 	if result.Status == domain.ResponseStatusError {
-		http.Redirect(w, r, fmt.Sprintf("/login?error=%s", url.QueryEscape(result.Error)), http.StatusSeeOther)
+		http.Redirect(w, r, fmt.Sprintf("/login?error=%s", url.QueryEscape(i18n.Message(r, result.ErrorCode, result.Error))), http.StatusSeeOther)
 		return
 	}
 
@@ -78,9 +79,9 @@ func (h *Handler) handleLogin(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) GoogleLogin(w http.ResponseWriter, r *http.Request) {
-	result, err := h.authGateway.GetGoogleAuthURL(r.Context(), domain.GoogleAuthPurposeLogin)
+	result, err := h.authGateway.GetGoogleAuthURL(r.Context(), domain.GoogleAuthPurposeLogin, "")
 	if err != nil {
-		h.logger.Error("failed to get google auth URL", "error", err)
+		h.logger.ErrorContext(r.Context(), "failed to get google auth URL", "error", err)
 		http.Redirect(w, r, fmt.Sprintf("/login?error=%s", url.QueryEscape("Failed to get Google sign-in link")), http.StatusSeeOther)
 		return
 	}
@@ -16,7 +16,7 @@ func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
 			http.Redirect(w, r, "/login", http.StatusSeeOther)
 			return
 		}
-		h.logger.Error("failed to logout", "error", err)
+		h.logger.ErrorContext(r.Context(), "failed to logout", "error", err)
 	}
 
 	http.SetCookie(w, &http.Cookie{
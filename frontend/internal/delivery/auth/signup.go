@@ -6,14 +6,15 @@ import (
 	"net/url"
 
 	"frontend/internal/domain"
+	"frontend/internal/pkg/i18n"
 )
 
 func (h *Handler) SignUpPage(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
-		opts := pageDataOptions{}
+		opts := pageDataOptions{InviteCode: r.URL.Query().Get("invite")}
 		if errorMsg := r.URL.Query().Get("error"); errorMsg != "" {
-			opts.Error = errorMsg
+			opts.Error = i18n.Message(r, r.URL.Query().Get("error_code"), errorMsg)
 		} else if successMsg := r.URL.Query().Get("success"); successMsg != "" {
 			opts.Message = successMsg
 		}
@@ -28,7 +29,7 @@ func (h *Handler) SignUpPage(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) showSignUpForm(w http.ResponseWriter, r *http.Request, data pageData) {
 	result, err := h.authGateway.CheckAuthStatus(r.Context())
 	if err != nil || result.Status == domain.ResponseStatusError {
-		h.logger.Error("failed to check auth status", "error", err)
+		h.logger.ErrorContext(r.Context(), "failed to check auth status", "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
@@ -36,9 +37,22 @@ func (h *Handler) showSignUpForm(w http.ResponseWriter, r *http.Request, data pa
 		http.Redirect(w, r, "/profile", http.StatusSeeOther)
 		return
 	}
+
+	// Without the policy the page falls back to the open form; the API
+	// still enforces the policy on submit.
+	policy, err := h.authGateway.GetSignUpPolicy(r.Context())
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "failed to get sign-up policy", "error", err)
+	} else {
+		data.InviteRequired = policy.Mode == domain.SignUpModeInviteOnly
+		if policy.Mode == domain.SignUpModeAllowedDomains {
+			data.AllowedDomains = policy.AllowedDomains
+		}
+	}
+
 	err = h.templates.ExecuteTemplate(w, "auth.html", data)
 	if err != nil {
-		h.logger.Error("failed to render signup page", "error", err)
+		h.logger.ErrorContext(r.Context(), "failed to render signup page", "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
 }
@@ -52,21 +66,22 @@ func (h *Handler) handleSignUp(w http.ResponseWriter, r *http.Request) {
 
 	email := r.FormValue("email")
 	password := r.FormValue("password")
+	inviteCode := r.FormValue("invite_code")
 
 	if email == "" || password == "" {
-		http.Redirect(w, r, fmt.Sprintf("/signup?error=%s", url.QueryEscape("Please fill in all fields")), http.StatusSeeOther)
+		http.Redirect(w, r, signUpErrorURL("Please fill in all fields", inviteCode), http.StatusSeeOther)
 		return
 	}
 
-	result, err := h.authGateway.SignUp(r.Context(), email, password)
+	result, err := h.authGateway.SignUp(r.Context(), email, password, inviteCode)
 	if err != nil {
-		h.logger.Error("failed to signup", "error", err)
-		http.Redirect(w, r, fmt.Sprintf("/signup?error=%s", url.QueryEscape("Failed to connect to server")), http.StatusSeeOther)
+		h.logger.ErrorContext(r.Context(), "failed to signup", "error", err)
+		http.Redirect(w, r, signUpErrorURL("Failed to connect to server", inviteCode), http.StatusSeeOther)
 		return
 	}
 
 	if result.Status == domain.ResponseStatusError {
-		http.Redirect(w, r, fmt.Sprintf("/signup?error=%s", url.QueryEscape(result.Error)), http.StatusSeeOther)
+		http.Redirect(w, r, signUpErrorURL(i18n.Message(r, result.ErrorCode, result.Error), inviteCode), http.StatusSeeOther)
 		return
 	}
 
@@ -75,10 +90,12 @@ func (h *Handler) handleSignUp(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, fmt.Sprintf("/login?success=%s", url.QueryEscape("User created successfully")), http.StatusSeeOther)
 }
 
+// GoogleSignUp starts a Google sign-up. While sign-up is invite-only the
+// sign-up form posts here with the invite code.
 func (h *Handler) GoogleSignUp(w http.ResponseWriter, r *http.Request) {
-	result, err := h.authGateway.GetGoogleAuthURL(r.Context(), domain.GoogleAuthPurposeSignUp)
+	result, err := h.authGateway.GetGoogleAuthURL(r.Context(), domain.GoogleAuthPurposeSignUp, r.FormValue("invite_code"))
 	if err != nil {
-		h.logger.Error("failed to get google auth URL", "error", err)
+		h.logger.ErrorContext(r.Context(), "failed to get google auth URL", "error", err)
 		h.showSignUpForm(w, r, newSignUpPageData(pageDataOptions{
 			Error: "Failed to get Google sign-up link",
 		}))
@@ -89,3 +106,13 @@ func (h *Handler) GoogleSignUp(w http.ResponseWriter, r *http.Request) {
 
 	http.Redirect(w, r, result.URL, http.StatusTemporaryRedirect)
 }
+
+// signUpErrorURL sends the user back to the sign-up form, keeping the invite
+// code they entered.
+func signUpErrorURL(message, inviteCode string) string {
+	params := url.Values{"error": {message}}
+	if inviteCode != "" {
+		params.Set("invite", inviteCode)
+	}
+	return "/signup?" + params.Encode()
+}
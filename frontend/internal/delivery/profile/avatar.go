@@ -0,0 +1,62 @@
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"frontend/internal/domain"
+	"frontend/internal/pkg/i18n"
+)
+
+// maxAvatarUploadSize only protects the frontend; the API applies the real,
+// configured limit.
+const maxAvatarUploadSize = 10 << 20
+
+func (h *Handler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxAvatarUploadSize)
+	file, header, err := r.FormFile("avatar")
+	if err != nil {
+		message := "Choose an image to upload"
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			message = "The image is too large"
+		}
+		http.Redirect(w, r, fmt.Sprintf("/profile/edit?error=%s", url.QueryEscape(message)), http.StatusSeeOther)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		http.Redirect(w, r, fmt.Sprintf("/profile/edit?error=%s", url.QueryEscape("Failed to process form")), http.StatusSeeOther)
+		return
+	}
+
+	result, err := h.profileGateway.UploadAvatar(r.Context(), header.Filename, data)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to upload avatar", "error", err)
+		http.Redirect(w, r, fmt.Sprintf("/profile/edit?error=%s", url.QueryEscape("Failed to connect to server")), http.StatusSeeOther)
+		return
+	}
+
+	setCookies(w, result.Cookies)
+
+	if result.Status == domain.ResponseStatusError {
+		if result.StatusCode == http.StatusUnauthorized {
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		}
+		http.Redirect(w, r, fmt.Sprintf("/profile/edit?error=%s", url.QueryEscape(i18n.Message(r, result.ErrorCode, result.Error))), http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/profile/edit?success=%s", url.QueryEscape("Picture updated")), http.StatusSeeOther)
+}
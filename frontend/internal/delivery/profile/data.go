@@ -1,17 +1,114 @@
 package profile
 
 type profileViewData struct {
-	FullName string
-	Phone    string
-	Email    string
-	Error    string
-	Success  string
+	AvatarURL     string
+	FullName      string
+	Phone         string
+	PhoneVerified bool
+	Email         string
+	PendingEmail  string
+	Error         string
+	Success       string
+	CustomFields  []customFieldValueView
+	// PublicURL is set while the profile is published.
+	PublicURL string
+	Export    *dataExportView
+}
+
+type dataExportView struct {
+	Status      string
+	RequestedAt string
+	ExpiresAt   string
+	DownloadURL string
 }
 
 type profileEditData struct {
-	FullName string
-	Phone    string
-	Email    string
+	AvatarURL     string
+	FullName      string
+	FullNameError string
+	Phone         string
+	PhoneError    string
+	Email         string
+	PendingEmail  string
+	ETag          string
+	CustomFields  []customFieldView
+	Handle        string
+	HandleError   string
+	Public        bool
+	Visibility    []visibilityView
+	Error         string
+	Success       string
+}
+
+type publicProfileData struct {
+	Handle       string
+	FullName     string
+	Email        string
+	Phone        string
+	AvatarURL    string
+	CustomFields []customFieldValueView
+	Error        string
+}
+
+type directoryData struct {
+	Query   string
+	Users   []directoryUserView
+	NextURL string
+	// Searched tells an empty result apart from the page before a search.
+	Searched bool
 	Error    string
-	Success  string
+}
+
+type directoryUserView struct {
+	ProfileURL string
+	Handle     string
+	FullName   string
+	Email      string
+	Phone      string
+	AvatarURL  string
+}
+
+type invitationData struct {
+	Token            string
+	OrganizationName string
+	Email            string
+	Role             string
+	ExpiresAt        string
+	Error            string
+}
+
+type accessTokensData struct {
+	Tokens      []accessTokenView
+	Scopes      []scopeOption
+	Expirations []expirationOption
+	Name        string
+	NameError   string
+	ScopesError string
+	// NewToken is the token just created; it is only ever shown here.
+	NewToken     string
+	NewTokenName string
+	Error        string
+	Success      string
+}
+
+type accessTokenView struct {
+	ID         int64
+	Name       string
+	Scopes     string
+	CreatedAt  string
+	ExpiresAt  string
+	LastUsedAt string
+	Expired    bool
+}
+
+type scopeOption struct {
+	Value       string
+	Description string
+	Checked     bool
+}
+
+type expirationOption struct {
+	Days     string
+	Label    string
+	Selected bool
 }
@@ -0,0 +1,45 @@
+package profile
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"frontend/internal/domain"
+	"frontend/internal/pkg/i18n"
+)
+
+func (h *Handler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		http.Redirect(w, r, fmt.Sprintf("/profile?error=%s", url.QueryEscape("Failed to process form")), http.StatusSeeOther)
+		return
+	}
+
+	result, err := h.profileGateway.DeleteAccount(r.Context(), r.FormValue("password"))
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to delete account", "error", err)
+		http.Redirect(w, r, fmt.Sprintf("/profile?error=%s", url.QueryEscape("Failed to connect to server")), http.StatusSeeOther)
+		return
+	}
+
+	setCookies(w, result.Cookies)
+
+	if result.Status == domain.ResponseStatusError {
+		if result.ErrorCode == domain.ErrorCodeUnauthorized || result.ErrorCode == domain.ErrorCodeSessionNotFound {
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		}
+		http.Redirect(w, r, fmt.Sprintf("/profile?error=%s", url.QueryEscape(i18n.Message(r, result.ErrorCode, result.Error))), http.StatusSeeOther)
+		return
+	}
+
+	message := fmt.Sprintf("Your account will be deleted on %s. Log in before then to cancel.",
+		result.DeletionScheduledAt.Format("January 2, 2006"))
+	http.Redirect(w, r, fmt.Sprintf("/login?success=%s", url.QueryEscape(message)), http.StatusSeeOther)
+}
@@ -0,0 +1,74 @@
+package profile
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"frontend/internal/domain"
+	"frontend/internal/pkg/i18n"
+)
+
+// Directory lets signed-in users look each other up by name or email.
+func (h *Handler) Directory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	data := directoryData{Query: query}
+	if query == "" {
+		h.showDirectory(w, r, data)
+		return
+	}
+
+	result, err := h.profileGateway.SearchDirectory(r.Context(), query, r.URL.Query().Get("cursor"))
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to search directory", "error", err)
+		data.Error = "Failed to connect to server"
+		h.showDirectory(w, r, data)
+		return
+	}
+
+	if result.Status == domain.ResponseStatusError {
+		switch {
+		case result.StatusCode == http.StatusUnauthorized:
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		case result.FieldErrors["q"] != "":
+			data.Error = "Search " + result.FieldErrors["q"]
+		default:
+			data.Error = i18n.Message(r, result.ErrorCode, result.Error)
+		}
+		h.showDirectory(w, r, data)
+		return
+	}
+
+	setCookies(w, result.Cookies)
+
+	data.Searched = true
+	for _, user := range result.Users {
+		data.Users = append(data.Users, directoryUserView{
+			ProfileURL: "/u/" + url.PathEscape(user.Handle),
+			Handle:     user.Handle,
+			FullName:   user.FullName,
+			Email:      user.Email,
+			Phone:      user.Phone,
+			AvatarURL:  user.AvatarURL,
+		})
+	}
+	if result.NextCursor != "" {
+		data.NextURL = "/directory?" + url.Values{"q": {query}, "cursor": {result.NextCursor}}.Encode()
+	}
+
+	h.showDirectory(w, r, data)
+}
+
+func (h *Handler) showDirectory(w http.ResponseWriter, r *http.Request, data directoryData) {
+	err := h.templates.ExecuteTemplate(w, "directory.html", data)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to render directory page", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,44 @@
+package profile
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"frontend/internal/domain"
+	"frontend/internal/pkg/i18n"
+)
+
+func (h *Handler) RequestEmailChange(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		http.Redirect(w, r, fmt.Sprintf("/profile/edit?error=%s", url.QueryEscape("Failed to process form")), http.StatusSeeOther)
+		return
+	}
+
+	result, err := h.profileGateway.RequestEmailChange(r.Context(), r.FormValue("email"))
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to request email change", "error", err)
+		http.Redirect(w, r, fmt.Sprintf("/profile/edit?error=%s", url.QueryEscape("Failed to connect to server")), http.StatusSeeOther)
+		return
+	}
+
+	setCookies(w, result.Cookies)
+
+	if result.Status == domain.ResponseStatusError {
+		if result.StatusCode == http.StatusUnauthorized {
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		}
+		http.Redirect(w, r, fmt.Sprintf("/profile/edit?error=%s", url.QueryEscape(i18n.Message(r, result.ErrorCode, result.Error))), http.StatusSeeOther)
+		return
+	}
+
+	message := fmt.Sprintf("We sent a confirmation link to %s. Your email changes once you open it.", result.NewEmail)
+	http.Redirect(w, r, fmt.Sprintf("/profile?success=%s", url.QueryEscape(message)), http.StatusSeeOther)
+}
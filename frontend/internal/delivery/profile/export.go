@@ -0,0 +1,66 @@
+package profile
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"frontend/internal/domain"
+	"frontend/internal/pkg/i18n"
+)
+
+const exportTimeLayout = "January 2, 2006 15:04 MST"
+
+func (h *Handler) RequestDataExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	result, err := h.profileGateway.RequestDataExport(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to request data export", "error", err)
+		http.Redirect(w, r, fmt.Sprintf("/profile?error=%s", url.QueryEscape("Failed to connect to server")), http.StatusSeeOther)
+		return
+	}
+
+	setCookies(w, result.Cookies)
+
+	if result.Status == domain.ResponseStatusError {
+		if result.StatusCode == http.StatusUnauthorized {
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		}
+		http.Redirect(w, r, fmt.Sprintf("/profile?error=%s", url.QueryEscape(i18n.Message(r, result.ErrorCode, result.Error))), http.StatusSeeOther)
+		return
+	}
+
+	message := "Your data export has been requested. Refresh this page in a minute to download it."
+	http.Redirect(w, r, fmt.Sprintf("/profile?success=%s", url.QueryEscape(message)), http.StatusSeeOther)
+}
+
+// loadDataExport fetches the status of the latest export for the profile page.
+// A missing export or a failed lookup just hides the status block.
+func (h *Handler) loadDataExport(r *http.Request) *dataExportView {
+	result, err := h.profileGateway.GetLatestDataExport(r.Context())
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "failed to get data export status", "error", err)
+		return nil
+	}
+	if result.Status == domain.ResponseStatusError {
+		return nil
+	}
+
+	export := result.Export
+	view := &dataExportView{
+		Status:      export.Status,
+		RequestedAt: export.CreatedAt.Local().Format(exportTimeLayout),
+	}
+	if export.ExpiresAt != nil {
+		view.ExpiresAt = export.ExpiresAt.Local().Format(exportTimeLayout)
+	}
+	if export.Status == "ready" {
+		view.DownloadURL = "/api/profile/export/" + url.PathEscape(export.ID)
+	}
+	return view
+}
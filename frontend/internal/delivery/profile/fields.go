@@ -0,0 +1,159 @@
+package profile
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"frontend/internal/domain"
+)
+
+// Form names of custom field inputs and their echoed errors.
+const (
+	customFieldPrefix      = "custom."
+	customFieldErrorPrefix = "custom_error."
+)
+
+type customFieldOption struct {
+	Value string
+	Label string
+}
+
+// customFieldView is one administrator-defined input on the edit page.
+type customFieldView struct {
+	ID          string
+	Name        string
+	Label       string
+	Value       string
+	Error       string
+	Input       string
+	Required    bool
+	Options     []customFieldOption
+	MinLength   int
+	MaxLength   int
+	Min         string
+	Max         string
+	Placeholder string
+}
+
+// customFieldValueView is one filled-in custom field on the profile page.
+type customFieldValueView struct {
+	Label string
+	Value string
+}
+
+// loadProfileFields fetches the custom field definitions. A failed lookup
+// only hides the custom fields, the rest of the profile still works.
+func (h *Handler) loadProfileFields(r *http.Request) []domain.ProfileField {
+	result, err := h.profileGateway.GetProfileFields(r.Context())
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "failed to get profile fields", "error", err)
+		return nil
+	}
+	if result.Status == domain.ResponseStatusError {
+		h.logger.WarnContext(r.Context(), "failed to get profile fields", "error", result.Error)
+		return nil
+	}
+	return result.Fields
+}
+
+// customFieldViews builds the inputs for the edit page. errors is keyed by
+// field key and may be nil.
+func customFieldViews(fields []domain.ProfileField, values, errors map[string]string) []customFieldView {
+	views := make([]customFieldView, 0, len(fields))
+	for _, field := range fields {
+		view := customFieldView{
+			ID:        "custom_" + field.Key,
+			Name:      customFieldPrefix + field.Key,
+			Label:     field.Label,
+			Value:     values[field.Key],
+			Error:     errors[field.Key],
+			Input:     field.Type,
+			Required:  field.Required,
+			MinLength: field.MinLength,
+			MaxLength: field.MaxLength,
+		}
+
+		switch field.Type {
+		case "number":
+			if field.Min != nil {
+				view.Min = strconv.FormatFloat(*field.Min, 'f', -1, 64)
+			}
+			if field.Max != nil {
+				view.Max = strconv.FormatFloat(*field.Max, 'f', -1, 64)
+			}
+		case "boolean":
+			view.Input = "select"
+			view.Options = []customFieldOption{{Value: "true", Label: "Yes"}, {Value: "false", Label: "No"}}
+		case "select":
+			for _, option := range field.Options {
+				view.Options = append(view.Options, customFieldOption{Value: option, Label: option})
+			}
+		case "timezone":
+			view.Input = "text"
+			view.Placeholder = "Europe/Berlin"
+		case "locale":
+			view.Input = "text"
+			view.Placeholder = "en-US"
+		case "textarea", "url":
+		default:
+			view.Input = "text"
+		}
+		views = append(views, view)
+	}
+	return views
+}
+
+// customFieldValues lists the filled-in fields for the profile page in the
+// order the administrators defined.
+func customFieldValues(fields []domain.ProfileField, values map[string]string) []customFieldValueView {
+	var views []customFieldValueView
+	for _, field := range fields {
+		value := values[field.Key]
+		if value == "" {
+			continue
+		}
+		views = append(views, customFieldValueView{Label: field.Label, Value: displayCustomFieldValue(field.Type, value)})
+	}
+	return views
+}
+
+func displayCustomFieldValue(fieldType, value string) string {
+	if fieldType != "boolean" {
+		return value
+	}
+	if value == "true" {
+		return "Yes"
+	}
+	return "No"
+}
+
+// formCustomFields collects the submitted custom field inputs. It returns nil
+// when the form had none, so the stored values are left untouched.
+func formCustomFields(form url.Values) map[string]string {
+	var values map[string]string
+	for name := range form {
+		key, ok := strings.CutPrefix(name, customFieldPrefix)
+		if !ok || key == "" {
+			continue
+		}
+		if values == nil {
+			values = make(map[string]string)
+		}
+		values[key] = form.Get(name)
+	}
+	return values
+}
+
+// queryCustomFields reads echoed custom field values or errors back from the
+// edit page query.
+func queryCustomFields(query url.Values, prefix string) map[string]string {
+	values := make(map[string]string)
+	for name := range query {
+		if key, ok := strings.CutPrefix(name, prefix); ok {
+			values[key] = query.Get(name)
+		}
+	}
+	return values
+}
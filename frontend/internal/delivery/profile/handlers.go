@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 
 	"frontend/internal/domain"
+	"frontend/internal/pkg/i18n"
 )
 
 func (h *Handler) ViewProfile(w http.ResponseWriter, r *http.Request) {
@@ -16,7 +18,7 @@ func (h *Handler) ViewProfile(w http.ResponseWriter, r *http.Request) {
 
 	result, err := h.profileGateway.GetProfile(r.Context())
 	if err != nil {
-		h.logger.Error("failed to get profile", "error", err)
+		h.logger.ErrorContext(r.Context(), "failed to get profile", "error", err)
 		h.showProfileView(w, r, profileViewData{
 			Error: "Failed to connect to server",
 		})
@@ -29,7 +31,7 @@ func (h *Handler) ViewProfile(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		h.showProfileView(w, r, profileViewData{
-			Error: result.Error,
+			Error: i18n.Message(r, result.ErrorCode, result.Error),
 		})
 		return
 	}
@@ -42,9 +44,17 @@ func (h *Handler) ViewProfile(w http.ResponseWriter, r *http.Request) {
 	setCookies(w, result.Cookies)
 
 	data := profileViewData{
-		FullName: result.Profile.FullName,
-		Phone:    result.Profile.Phone,
-		Email:    result.Profile.Email,
+		AvatarURL:     result.Profile.AvatarURL,
+		FullName:      result.Profile.FullName,
+		Phone:         result.Profile.Phone,
+		PhoneVerified: result.Profile.PhoneVerifiedAt != nil,
+		Email:         result.Profile.Email,
+		PendingEmail:  result.Profile.PendingEmail,
+		CustomFields:  customFieldValues(h.loadProfileFields(r), result.Profile.CustomFields),
+		Export:        h.loadDataExport(r),
+	}
+	if result.Profile.Public && result.Profile.Handle != "" {
+		data.PublicURL = "/u/" + url.PathEscape(result.Profile.Handle)
 	}
 
 	if errorMsg := r.URL.Query().Get("error"); errorMsg != "" {
@@ -62,7 +72,7 @@ func (h *Handler) EditProfile(w http.ResponseWriter, r *http.Request) {
 	case http.MethodGet:
 		result, err := h.profileGateway.GetProfile(r.Context())
 		if err != nil {
-			h.logger.Error("failed to get profile", "error", err)
+			h.logger.ErrorContext(r.Context(), "failed to get profile", "error", err)
 			h.showProfileEdit(w, r, profileEditData{
 				Error: "Failed to connect to server",
 			})
@@ -75,7 +85,7 @@ func (h *Handler) EditProfile(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 			h.showProfileEdit(w, r, profileEditData{
-				Error: result.Error,
+				Error: i18n.Message(r, result.ErrorCode, result.Error),
 			})
 			return
 		}
@@ -83,17 +93,44 @@ func (h *Handler) EditProfile(w http.ResponseWriter, r *http.Request) {
 		setCookies(w, result.Cookies)
 
 		data := profileEditData{
-			FullName: result.Profile.FullName,
-			Phone:    result.Profile.Phone,
-			Email:    result.Profile.Email,
+			AvatarURL:    result.Profile.AvatarURL,
+			FullName:     result.Profile.FullName,
+			Phone:        result.Profile.Phone,
+			Email:        result.Profile.Email,
+			PendingEmail: result.Profile.PendingEmail,
+			ETag:         result.Profile.ETag,
+			Handle:       result.Profile.Handle,
+			Public:       result.Profile.Public,
 		}
+		visibility := result.Profile.Visibility
+		fields := h.loadProfileFields(r)
+		customValues, customErrors := result.Profile.CustomFields, map[string]string(nil)
 
 		if errorMsg := r.URL.Query().Get("error"); errorMsg != "" {
 			data.Error = errorMsg
 		}
+		if query := r.URL.Query(); query.Has("phone_error") || query.Has("full_name_error") {
+			data.FullName = query.Get("full_name")
+			data.FullNameError = query.Get("full_name_error")
+			data.Phone = query.Get("phone")
+			data.PhoneError = query.Get("phone_error")
+			data.Handle = query.Get("handle")
+			data.HandleError = query.Get("handle_error")
+			data.Public = query.Get("public") == "true"
+			visibility = formVisibility(query.Get)
+			customValues = queryCustomFields(query, customFieldPrefix)
+			customErrors = queryCustomFields(query, customFieldErrorPrefix)
+			// Keep the version the user started from: if the profile has
+			// changed in the meantime, saving the echoed values must fail.
+			if etag := query.Get("etag"); etag != "" {
+				data.ETag = etag
+			}
+		}
 		if successMsg := r.URL.Query().Get("success"); successMsg != "" {
 			data.Success = successMsg
 		}
+		data.CustomFields = customFieldViews(fields, customValues, customErrors)
+		data.Visibility = visibilityViews(visibility)
 
 		h.showProfileEdit(w, r, data)
 	case http.MethodPost:
@@ -113,12 +150,19 @@ func (h *Handler) handleProfileUpdate(w http.ResponseWriter, r *http.Request) {
 	profile := &domain.Profile{
 		FullName: r.FormValue("full_name"),
 		Phone:    r.FormValue("phone"),
-		Email:    r.FormValue("email"),
+		ETag:     r.FormValue("etag"),
+		Handle:   r.FormValue("handle"),
+		// An unchecked box is not submitted at all.
+		Public:     r.FormValue("public") == "on",
+		Visibility: formVisibility(r.FormValue),
+		// Inputs are only rendered when the field definitions loaded; without
+		// them the map stays nil and the stored values are kept.
+		CustomFields: formCustomFields(r.PostForm),
 	}
 
 	result, err := h.profileGateway.UpdateProfile(r.Context(), profile)
 	if err != nil {
-		h.logger.Error("failed to update profile", "error", err)
+		h.logger.ErrorContext(r.Context(), "failed to update profile", "error", err)
 		http.Redirect(w, r, fmt.Sprintf("/profile/edit?error=%s", url.QueryEscape("Failed to connect to server")), http.StatusSeeOther)
 		return
 	}
@@ -126,7 +170,38 @@ func (h *Handler) handleProfileUpdate(w http.ResponseWriter, r *http.Request) {
 	setCookies(w, result.Cookies)
 
 	if result.Status == domain.ResponseStatusError {
-		http.Redirect(w, r, fmt.Sprintf("/profile/edit?error=%s", url.QueryEscape(result.Error)), http.StatusSeeOther)
+		if result.StatusCode == http.StatusPreconditionFailed {
+			// The edit page reloads the profile, so the user sees the fresh
+			// data together with the message.
+			message := "Your profile was changed elsewhere. Review the current details and apply your changes again."
+			http.Redirect(w, r, fmt.Sprintf("/profile/edit?error=%s", url.QueryEscape(message)), http.StatusSeeOther)
+			return
+		}
+		if len(result.FieldErrors) > 0 {
+			// Echo the submitted values back so the user can fix them in place.
+			query := url.Values{
+				"full_name":       {profile.FullName},
+				"full_name_error": {result.FieldErrors["full_name"]},
+				"phone":           {profile.Phone},
+				"phone_error":     {result.FieldErrors["phone"]},
+				"etag":            {profile.ETag},
+				"handle":          {profile.Handle},
+				"handle_error":    {result.FieldErrors["handle"]},
+				"public":          {strconv.FormatBool(profile.Public)},
+			}
+			for _, key := range []string{"full_name", "email", "phone", "avatar"} {
+				query.Set("visibility."+key, r.FormValue("visibility."+key))
+			}
+			for key, value := range profile.CustomFields {
+				query.Set(customFieldPrefix+key, value)
+				if message := result.FieldErrors["custom_fields."+key]; message != "" {
+					query.Set(customFieldErrorPrefix+key, message)
+				}
+			}
+			http.Redirect(w, r, "/profile/edit?"+query.Encode(), http.StatusSeeOther)
+			return
+		}
+		http.Redirect(w, r, fmt.Sprintf("/profile/edit?error=%s", url.QueryEscape(i18n.Message(r, result.ErrorCode, result.Error))), http.StatusSeeOther)
 		return
 	}
 
@@ -139,18 +214,18 @@ func setCookies(w http.ResponseWriter, cookies []*http.Cookie) {
 	}
 }
 
-func (h *Handler) showProfileView(w http.ResponseWriter, _ *http.Request, data profileViewData) {
+func (h *Handler) showProfileView(w http.ResponseWriter, r *http.Request, data profileViewData) {
 	err := h.templates.ExecuteTemplate(w, "profile-view.html", data)
 	if err != nil {
-		h.logger.Error("failed to render profile view page", "error", err)
+		h.logger.ErrorContext(r.Context(), "failed to render profile view page", "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
 }
 
-func (h *Handler) showProfileEdit(w http.ResponseWriter, _ *http.Request, data profileEditData) {
+func (h *Handler) showProfileEdit(w http.ResponseWriter, r *http.Request, data profileEditData) {
 	err := h.templates.ExecuteTemplate(w, "profile-edit.html", data)
 	if err != nil {
-		h.logger.Error("failed to render profile edit page", "error", err)
+		h.logger.ErrorContext(r.Context(), "failed to render profile edit page", "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
 }
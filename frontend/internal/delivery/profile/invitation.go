@@ -0,0 +1,91 @@
+package profile
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"frontend/internal/domain"
+	"frontend/internal/pkg/i18n"
+)
+
+// AcceptInvitation is the target of organization invitation emails: GET
+// shows the invitation for `?token=`, POST joins the organization.
+func (h *Handler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.showInvitation(w, r)
+	case http.MethodPost:
+		h.acceptInvitation(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Handler) showInvitation(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	data := invitationData{Token: token}
+
+	result, err := h.profileGateway.GetInvitation(r.Context(), token)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to get invitation", "error", err)
+		data.Error = "Failed to connect to server"
+		h.renderInvitation(w, r, data)
+		return
+	}
+
+	setCookies(w, result.Cookies)
+
+	if result.Status == domain.ResponseStatusError {
+		if result.StatusCode == http.StatusUnauthorized {
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		}
+		data.Error = i18n.Message(r, result.ErrorCode, result.Error)
+		h.renderInvitation(w, r, data)
+		return
+	}
+
+	data.OrganizationName = result.OrganizationName
+	data.Email = result.Email
+	data.Role = result.Role
+	data.ExpiresAt = result.ExpiresAt.Format("January 2, 2006")
+	h.renderInvitation(w, r, data)
+}
+
+func (h *Handler) acceptInvitation(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		http.Redirect(w, r, fmt.Sprintf("/profile?error=%s", url.QueryEscape("Failed to process form")), http.StatusSeeOther)
+		return
+	}
+
+	result, err := h.profileGateway.AcceptInvitation(r.Context(), r.FormValue("token"))
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to accept invitation", "error", err)
+		http.Redirect(w, r, fmt.Sprintf("/profile?error=%s", url.QueryEscape("Failed to connect to server")), http.StatusSeeOther)
+		return
+	}
+
+	setCookies(w, result.Cookies)
+
+	if result.Status == domain.ResponseStatusError {
+		if result.StatusCode == http.StatusUnauthorized {
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		}
+		http.Redirect(w, r, fmt.Sprintf("/profile?error=%s", url.QueryEscape(i18n.Message(r, result.ErrorCode, result.Error))), http.StatusSeeOther)
+		return
+	}
+
+	message := fmt.Sprintf("You joined %s as %s.", result.OrganizationName, result.Role)
+	http.Redirect(w, r, fmt.Sprintf("/profile?success=%s", url.QueryEscape(message)), http.StatusSeeOther)
+}
+
+func (h *Handler) renderInvitation(w http.ResponseWriter, r *http.Request, data invitationData) {
+	err := h.templates.ExecuteTemplate(w, "invitation.html", data)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to render invitation page", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
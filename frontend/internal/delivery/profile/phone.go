@@ -0,0 +1,63 @@
+package profile
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"frontend/internal/domain"
+	"frontend/internal/pkg/i18n"
+)
+
+func (h *Handler) SendPhoneCode(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	result, err := h.profileGateway.RequestPhoneVerification(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to request phone verification", "error", err)
+		http.Redirect(w, r, fmt.Sprintf("/profile?error=%s", url.QueryEscape("Failed to connect to server")), http.StatusSeeOther)
+		return
+	}
+
+	h.redirectAfterPhoneVerification(w, r, result, "We sent a verification code to your phone.")
+}
+
+func (h *Handler) VerifyPhone(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		http.Redirect(w, r, fmt.Sprintf("/profile?error=%s", url.QueryEscape("Failed to process form")), http.StatusSeeOther)
+		return
+	}
+
+	result, err := h.profileGateway.ConfirmPhoneVerification(r.Context(), r.FormValue("code"))
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to confirm phone verification", "error", err)
+		http.Redirect(w, r, fmt.Sprintf("/profile?error=%s", url.QueryEscape("Failed to connect to server")), http.StatusSeeOther)
+		return
+	}
+
+	h.redirectAfterPhoneVerification(w, r, result, "Your phone number is verified.")
+}
+
+func (h *Handler) redirectAfterPhoneVerification(w http.ResponseWriter, r *http.Request, result *domain.PhoneVerificationResult, success string) {
+	setCookies(w, result.Cookies)
+
+	if result.Status == domain.ResponseStatusError {
+		if result.StatusCode == http.StatusUnauthorized {
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		}
+		http.Redirect(w, r, fmt.Sprintf("/profile?error=%s", url.QueryEscape(i18n.Message(r, result.ErrorCode, result.Error))), http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/profile?success=%s", url.QueryEscape(success)), http.StatusSeeOther)
+}
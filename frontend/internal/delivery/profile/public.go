@@ -0,0 +1,113 @@
+package profile
+
+import (
+	"net/http"
+
+	"frontend/internal/domain"
+	"frontend/internal/pkg/i18n"
+)
+
+// visibilityOptions are the choices offered for every visibility setting.
+var visibilityOptions = []customFieldOption{
+	{Value: "public", Label: "Everyone"},
+	{Value: "signed_in", Label: "Signed-in users"},
+	{Value: "private", Label: "Only me"},
+}
+
+// visibilityView is one "who can see this" select on the edit page.
+type visibilityView struct {
+	ID      string
+	Name    string
+	Label   string
+	Value   string
+	Options []customFieldOption
+}
+
+func visibilityViews(visibility domain.ProfileVisibility) []visibilityView {
+	settings := []struct {
+		key   string
+		label string
+		value string
+	}{
+		{"full_name", "Name", visibility.FullName},
+		{"email", "Email", visibility.Email},
+		{"phone", "Telephone", visibility.Phone},
+		{"avatar", "Picture", visibility.Avatar},
+	}
+
+	views := make([]visibilityView, 0, len(settings))
+	for _, setting := range settings {
+		views = append(views, visibilityView{
+			ID:      "visibility_" + setting.key,
+			Name:    "visibility." + setting.key,
+			Label:   setting.label,
+			Value:   setting.value,
+			Options: visibilityOptions,
+		})
+	}
+	return views
+}
+
+func formVisibility(get func(string) string) domain.ProfileVisibility {
+	return domain.ProfileVisibility{
+		FullName: get("visibility.full_name"),
+		Email:    get("visibility.email"),
+		Phone:    get("visibility.phone"),
+		Avatar:   get("visibility.avatar"),
+	}
+}
+
+// PublicProfile renders /u/{handle}. It works signed in or not; the API
+// decides what the visitor may see.
+func (h *Handler) PublicProfile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	result, err := h.profileGateway.GetPublicProfile(r.Context(), r.PathValue("handle"))
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to get public profile", "error", err)
+		h.showPublicProfile(w, r, publicProfileData{Error: "Failed to connect to server"})
+		return
+	}
+
+	if result.Status == domain.ResponseStatusError {
+		if result.StatusCode == http.StatusNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			if err := h.templates.ExecuteTemplate(w, "404.html", nil); err != nil {
+				h.logger.ErrorContext(r.Context(), "failed to render not found page", "error", err)
+			}
+			return
+		}
+		h.showPublicProfile(w, r, publicProfileData{Error: i18n.Message(r, result.ErrorCode, result.Error)})
+		return
+	}
+
+	setCookies(w, result.Cookies)
+
+	profile := result.Profile
+	data := publicProfileData{
+		Handle:    profile.Handle,
+		FullName:  profile.FullName,
+		Email:     profile.Email,
+		Phone:     profile.Phone,
+		AvatarURL: profile.AvatarURL,
+	}
+	for _, field := range profile.CustomFields {
+		data.CustomFields = append(data.CustomFields, customFieldValueView{
+			Label: field.Label,
+			Value: displayCustomFieldValue(field.Type, field.Value),
+		})
+	}
+
+	h.showPublicProfile(w, r, data)
+}
+
+func (h *Handler) showPublicProfile(w http.ResponseWriter, r *http.Request, data publicProfileData) {
+	err := h.templates.ExecuteTemplate(w, "public-profile.html", data)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to render public profile page", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
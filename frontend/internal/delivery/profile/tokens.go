@@ -0,0 +1,217 @@
+package profile
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"frontend/internal/domain"
+	"frontend/internal/pkg/i18n"
+)
+
+const tokenTimeLayout = "January 2, 2006"
+
+// accessTokenScopes are the scopes offered when creating a token, in the
+// order they are shown.
+var accessTokenScopes = []struct {
+	value       string
+	description string
+}{
+	{"profile:read", "Read your profile, history and data exports"},
+	{"profile:write", "Change your profile"},
+	{"users:read", "Look up other users"},
+	{"organizations:read", "List your organizations and their members"},
+	{"organizations:write", "Create organizations and manage their members"},
+}
+
+// accessTokenExpirations are the lifetimes offered for a token; an empty
+// value makes a token that does not expire.
+var accessTokenExpirations = []struct {
+	days  string
+	label string
+}{
+	{"30", "30 days"},
+	{"90", "90 days"},
+	{"365", "1 year"},
+	{"", "Never"},
+}
+
+// AccessTokens lists the personal access tokens of the user on GET and
+// creates one on POST. The new token is rendered straight into the answer
+// rather than passed through a redirect, so it never ends up in a URL.
+func (h *Handler) AccessTokens(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		data := newAccessTokensData(nil, "30")
+		data.Error = r.URL.Query().Get("error")
+		data.Success = r.URL.Query().Get("success")
+		h.showAccessTokens(w, r, data)
+	case http.MethodPost:
+		h.createAccessToken(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Handler) createAccessToken(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		http.Redirect(w, r, fmt.Sprintf("/profile/tokens?error=%s", url.QueryEscape("Failed to process form")), http.StatusSeeOther)
+		return
+	}
+
+	scopes := r.Form["scopes"]
+	days := r.FormValue("expires_in")
+	data := newAccessTokensData(scopes, days)
+	data.Name = strings.TrimSpace(r.FormValue("name"))
+
+	var expiresAt *time.Time
+	if days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			data.Error = "Please choose when the token expires."
+			h.showAccessTokens(w, r, data)
+			return
+		}
+		at := time.Now().AddDate(0, 0, n)
+		expiresAt = &at
+	}
+
+	result, err := h.profileGateway.CreateAccessToken(r.Context(), data.Name, scopes, expiresAt)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to create access token", "error", err)
+		data.Error = "Failed to connect to server"
+		h.showAccessTokens(w, r, data)
+		return
+	}
+
+	setCookies(w, result.Cookies)
+
+	if result.Status == domain.ResponseStatusError {
+		if result.StatusCode == http.StatusUnauthorized {
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		}
+		if len(result.FieldErrors) > 0 {
+			data.NameError = result.FieldErrors["name"]
+			data.ScopesError = result.FieldErrors["scopes"]
+			if message := result.FieldErrors["expires_at"]; message != "" {
+				data.Error = "Expiry " + message
+			}
+		} else {
+			data.Error = i18n.Message(r, result.ErrorCode, result.Error)
+		}
+		h.showAccessTokens(w, r, data)
+		return
+	}
+
+	data = newAccessTokensData(nil, "30")
+	data.NewToken = result.Value
+	data.NewTokenName = result.Token.Name
+	h.showAccessTokens(w, r, data)
+}
+
+func (h *Handler) DeleteAccessToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.FormValue("id"), 10, 64)
+	if err != nil {
+		http.Redirect(w, r, fmt.Sprintf("/profile/tokens?error=%s", url.QueryEscape("Failed to process form")), http.StatusSeeOther)
+		return
+	}
+
+	result, err := h.profileGateway.DeleteAccessToken(r.Context(), id)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to delete access token", "error", err)
+		http.Redirect(w, r, fmt.Sprintf("/profile/tokens?error=%s", url.QueryEscape("Failed to connect to server")), http.StatusSeeOther)
+		return
+	}
+
+	setCookies(w, result.Cookies)
+
+	if result.Status == domain.ResponseStatusError {
+		if result.StatusCode == http.StatusUnauthorized {
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		}
+		http.Redirect(w, r, fmt.Sprintf("/profile/tokens?error=%s", url.QueryEscape(i18n.Message(r, result.ErrorCode, result.Error))), http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/profile/tokens?success=%s", url.QueryEscape("The token was revoked.")), http.StatusSeeOther)
+}
+
+// showAccessTokens loads the token list and renders the page. Pages that
+// show a new token are never cached.
+func (h *Handler) showAccessTokens(w http.ResponseWriter, r *http.Request, data accessTokensData) {
+	result, err := h.profileGateway.ListAccessTokens(r.Context())
+	switch {
+	case err != nil:
+		h.logger.ErrorContext(r.Context(), "failed to list access tokens", "error", err)
+		data.Error = "Failed to connect to server"
+	case result.Status == domain.ResponseStatusError:
+		if result.StatusCode == http.StatusUnauthorized {
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		}
+		data.Error = i18n.Message(r, result.ErrorCode, result.Error)
+	default:
+		setCookies(w, result.Cookies)
+		now := time.Now()
+		for _, token := range result.Tokens {
+			data.Tokens = append(data.Tokens, accessTokenViewFromDomain(token, now))
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	err = h.templates.ExecuteTemplate(w, "tokens.html", data)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to render access tokens page", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+func newAccessTokensData(checked []string, days string) accessTokensData {
+	data := accessTokensData{}
+	for _, scope := range accessTokenScopes {
+		data.Scopes = append(data.Scopes, scopeOption{
+			Value:       scope.value,
+			Description: scope.description,
+			Checked:     slices.Contains(checked, scope.value),
+		})
+	}
+	for _, expiration := range accessTokenExpirations {
+		data.Expirations = append(data.Expirations, expirationOption{
+			Days:     expiration.days,
+			Label:    expiration.label,
+			Selected: expiration.days == days,
+		})
+	}
+	return data
+}
+
+func accessTokenViewFromDomain(token domain.AccessToken, now time.Time) accessTokenView {
+	view := accessTokenView{
+		ID:         token.ID,
+		Name:       token.Name,
+		Scopes:     strings.Join(token.Scopes, ", "),
+		CreatedAt:  token.CreatedAt.Local().Format(tokenTimeLayout),
+		ExpiresAt:  "never",
+		LastUsedAt: "never",
+	}
+	if token.ExpiresAt != nil {
+		view.ExpiresAt = token.ExpiresAt.Local().Format(tokenTimeLayout)
+		view.Expired = !now.Before(*token.ExpiresAt)
+	}
+	if token.LastUsedAt != nil {
+		view.LastUsedAt = token.LastUsedAt.Local().Format(tokenTimeLayout)
+	}
+	return view
+}
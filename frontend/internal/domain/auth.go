@@ -16,10 +16,32 @@ const (
 	GoogleAuthPurposeSignUp GoogleAuthPurpose = "signup"
 )
 
+// Sign-up modes reported by the API.
+const (
+	SignUpModeOpen           = "open"
+	SignUpModeInviteOnly     = "invite_only"
+	SignUpModeAllowedDomains = "allowed_domains"
+)
+
+// Error codes reported by the API that the frontend acts on; the rest are
+// only used to look up messages.
+const (
+	ErrorCodeUnauthorized    = "unauthorized"
+	ErrorCodeSessionNotFound = "session_not_found"
+)
+
+type SignUpPolicyResult struct {
+	Status         ResponseStatus
+	Mode           string
+	AllowedDomains []string
+	StatusCode     int
+}
+
 type LoginResult struct {
 	Status     ResponseStatus
 	Message    string
 	Error      string
+	ErrorCode  string
 	Cookies    []*http.Cookie
 	StatusCode int
 }
@@ -28,6 +50,7 @@ type GoogleAuthResult struct {
 	Status     ResponseStatus
 	URL        string
 	Error      string
+	ErrorCode  string
 	Cookies    []*http.Cookie
 	StatusCode int
 }
@@ -36,6 +59,7 @@ type SignUpResult struct {
 	Status     ResponseStatus
 	Message    string
 	Error      string
+	ErrorCode  string
 	Cookies    []*http.Cookie
 	StatusCode int
 }
@@ -44,6 +68,7 @@ type LogoutResult struct {
 	Status     ResponseStatus
 	Message    string
 	Error      string
+	ErrorCode  string
 	Cookies    []*http.Cookie
 	StatusCode int
 }
@@ -1,17 +1,214 @@
 package domain
 
-import "net/http"
+import (
+	"net/http"
+	"time"
+)
 
 type Profile struct {
+	FullName        string
+	Phone           string
+	PhoneVerifiedAt *time.Time
+	Email           string
+	PendingEmail    string
+	// AvatarURL is a path under /api that serves the avatar; empty if the
+	// user has none.
+	AvatarURL string
+	// ETag identifies the profile version; updates send it back so the API
+	// can reject edits made on stale data.
+	ETag string
+	// CustomFields holds the values of administrator-defined fields by key.
+	// Updates send it only when the form contained those fields.
+	CustomFields map[string]string
+	// Handle names the public profile at /u/{handle}; Public opts in to it.
+	Handle     string
+	Public     bool
+	Visibility ProfileVisibility
+}
+
+// ProfileVisibility holds who may see each attribute on the public profile:
+// "public", "signed_in" or "private".
+type ProfileVisibility struct {
 	FullName string
-	Phone    string
 	Email    string
+	Phone    string
+	Avatar   string
+}
+
+// PublicProfile is someone's profile as the current visitor may see it;
+// hidden attributes are empty.
+type PublicProfile struct {
+	Handle       string
+	FullName     string
+	Email        string
+	Phone        string
+	AvatarURL    string
+	CustomFields []PublicProfileField
+}
+
+type PublicProfileField struct {
+	Label string
+	Type  string
+	Value string
+}
+
+type PublicProfileResult struct {
+	Status     ResponseStatus
+	Profile    *PublicProfile
+	Error      string
+	ErrorCode  string
+	Cookies    []*http.Cookie
+	StatusCode int
+}
+
+// DirectoryResult is one page of a user directory search; NextCursor is
+// empty on the last page.
+type DirectoryResult struct {
+	Status      ResponseStatus
+	Users       []PublicProfile
+	NextCursor  string
+	Error       string
+	ErrorCode   string
+	FieldErrors map[string]string
+	Cookies     []*http.Cookie
+	StatusCode  int
+}
+
+// InvitationResult describes an organization invitation and, once
+// accepted, the role the user joined with.
+type InvitationResult struct {
+	Status           ResponseStatus
+	OrganizationName string
+	Email            string
+	Role             string
+	ExpiresAt        time.Time
+	Error            string
+	ErrorCode        string
+	Cookies          []*http.Cookie
+	StatusCode       int
+}
+
+// ProfileField describes a custom profile field defined by administrators.
+type ProfileField struct {
+	Key        string
+	Label      string
+	Type       string
+	Required   bool
+	Visibility string
+	MinLength  int
+	MaxLength  int
+	Pattern    string
+	Min        *float64
+	Max        *float64
+	Options    []string
+}
+
+type ProfileFieldsResult struct {
+	Status     ResponseStatus
+	Fields     []ProfileField
+	Error      string
+	ErrorCode  string
+	Cookies    []*http.Cookie
+	StatusCode int
 }
 
 type ProfileResult struct {
+	Status    ResponseStatus
+	Profile   *Profile
+	Error     string
+	ErrorCode string
+	// FieldErrors holds per-field validation messages keyed by the API field
+	// name, e.g. "phone".
+	FieldErrors map[string]string
+	Cookies     []*http.Cookie
+	StatusCode  int
+}
+
+type DeleteAccountResult struct {
+	Status              ResponseStatus
+	Message             string
+	Error               string
+	ErrorCode           string
+	DeletionScheduledAt time.Time
+	Cookies             []*http.Cookie
+	StatusCode          int
+}
+
+type EmailChangeResult struct {
 	Status     ResponseStatus
-	Profile    *Profile
+	Message    string
+	NewEmail   string
 	Error      string
+	ErrorCode  string
 	Cookies    []*http.Cookie
 	StatusCode int
 }
+
+type PhoneVerificationResult struct {
+	Status     ResponseStatus
+	Message    string
+	Error      string
+	ErrorCode  string
+	Cookies    []*http.Cookie
+	StatusCode int
+}
+
+type DataExport struct {
+	ID          string
+	Status      string
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+	ExpiresAt   *time.Time
+}
+
+type DataExportResult struct {
+	Status     ResponseStatus
+	Export     *DataExport
+	Error      string
+	ErrorCode  string
+	Cookies    []*http.Cookie
+	StatusCode int
+}
+
+type AvatarResult struct {
+	Status     ResponseStatus
+	Message    string
+	AvatarURL  string
+	Error      string
+	ErrorCode  string
+	Cookies    []*http.Cookie
+	StatusCode int
+}
+
+// AccessToken describes a personal access token. The token itself is only
+// known in the result of creating it.
+type AccessToken struct {
+	ID         int64
+	Name       string
+	Scopes     []string
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+}
+
+type AccessTokensResult struct {
+	Status     ResponseStatus
+	Tokens     []AccessToken
+	Error      string
+	ErrorCode  string
+	Cookies    []*http.Cookie
+	StatusCode int
+}
+
+// AccessTokenResult is the answer to creating or revoking a token; Value
+// holds the new token and is shown to the user once.
+type AccessTokenResult struct {
+	Status      ResponseStatus
+	Token       *AccessToken
+	Value       string
+	Error       string
+	ErrorCode   string
+	FieldErrors map[string]string
+	Cookies     []*http.Cookie
+	StatusCode  int
+}
@@ -1,221 +1,135 @@
 package auth
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"net/http"
-	"time"
 
+	"apiclient"
 	"frontend/internal/domain"
-	"frontend/internal/pkg/httpclient"
 )
 
-const (
-	defaultTimeout     = 10 * time.Second
-	loginURI           = "/api/auth/login"
-	signupURI          = "/api/auth/signup"
-	logoutURI          = "/api/auth/logout"
-	googleAuthURI      = "/api/auth/google/url"
-	jsonContentType    = "application/json"
-	checkAuthStatusURI = "/api/auth/status"
-)
-
-var googleAuthPurposeMap = map[domain.GoogleAuthPurpose]string{
-	domain.GoogleAuthPurposeLogin:  "login",
-	domain.GoogleAuthPurposeSignUp: "signup",
+var googleAuthPurposeMap = map[domain.GoogleAuthPurpose]apiclient.GoogleAuthPurpose{
+	domain.GoogleAuthPurposeLogin:  apiclient.GoogleAuthPurposeLogin,
+	domain.GoogleAuthPurposeSignUp: apiclient.GoogleAuthPurposeSignUp,
 }
 
 type Gateway struct {
-	client     *http.Client
-	apiBaseURL string
+	client *apiclient.Client
 }
 
-func NewGateway(apiBaseURL string) *Gateway {
+func NewGateway(client *apiclient.Client) *Gateway {
 	return &Gateway{
-		client:     httpclient.New(&http.Client{Timeout: defaultTimeout}),
-		apiBaseURL: apiBaseURL,
-	}
-}
-
-func (g *Gateway) makeRequestWithBody(ctx context.Context, method, url string, data interface{}) (*http.Response, error) {
-	reqBody, err := json.Marshal(data)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		client: client,
 	}
-
-	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(reqBody))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("Content-Type", jsonContentType)
-
-	return g.client.Do(req)
-}
-
-func (g *Gateway) makeRequestWithoutBody(ctx context.Context, method, url string) (*http.Response, error) {
-	req, err := http.NewRequestWithContext(ctx, method, url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	return g.client.Do(req)
 }
 
 func (g *Gateway) Login(ctx context.Context, email, password string) (*domain.LoginResult, error) {
-	resp, err := g.makeRequestWithBody(ctx, http.MethodPost, g.apiBaseURL+loginURI, loginRequest{
+	resp, err := g.client.Login(ctx, apiclient.LoginRequest{
 		Email:    email,
 		Password: password,
 	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to call API: %w", err)
+	if apiErr, ok := apiclient.AsError(err); ok {
+		return &domain.LoginResult{
+			Status:     domain.ResponseStatusError,
+			Error:      apiErr.Detail,
+			ErrorCode:  apiErr.Code,
+			Cookies:    apiErr.Cookies,
+			StatusCode: apiErr.StatusCode,
+		}, nil
 	}
-	defer resp.Body.Close()
-
-	var respDTO loginResponse
-	err = json.NewDecoder(resp.Body).Decode(&respDTO)
 	if err != nil {
-		return nil, fmt.Errorf("failed to login: %w", err)
+		return nil, fmt.Errorf("failed to call API: %w", err)
 	}
 
-	var status domain.ResponseStatus
-	if resp.StatusCode == http.StatusOK {
-		status = domain.ResponseStatusSuccess
-	} else {
-		status = domain.ResponseStatusError
-	}
 	return &domain.LoginResult{
-		Status:     status,
-		Message:    respDTO.Message,
-		Error:      respDTO.Error,
-		Cookies:    resp.Cookies(),
+		Status:     domain.ResponseStatusSuccess,
+		Message:    resp.Message,
+		Cookies:    resp.Cookies,
 		StatusCode: resp.StatusCode,
 	}, nil
 }
 
-func (g *Gateway) GetGoogleAuthURL(ctx context.Context, purpose domain.GoogleAuthPurpose) (*domain.GoogleAuthResult, error) {
-	resp, err := g.makeRequestWithoutBody(ctx, http.MethodGet, g.apiBaseURL+googleAuthURI+"?purpose="+googleAuthPurposeMap[purpose])
+// GetGoogleAuthURL starts a Google login or sign-up; inviteCode is passed on
+// for invite-only sign-up.
+func (g *Gateway) GetGoogleAuthURL(ctx context.Context, purpose domain.GoogleAuthPurpose, inviteCode string) (*domain.GoogleAuthResult, error) {
+	resp, err := g.client.GetGoogleAuthURL(ctx, googleAuthPurposeMap[purpose], inviteCode)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call API: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
-
-	var respDTO googleAuthResponse
-	if err := json.NewDecoder(resp.Body).Decode(&respDTO); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	var status domain.ResponseStatus
-	if resp.StatusCode == http.StatusOK {
-		status = domain.ResponseStatusSuccess
-	} else {
-		status = domain.ResponseStatusError
-	}
 
 	return &domain.GoogleAuthResult{
-		Status:     status,
-		URL:        respDTO.URL,
-		Error:      respDTO.Error,
-		Cookies:    resp.Cookies(),
+		Status:     domain.ResponseStatusSuccess,
+		URL:        resp.URL,
+		Cookies:    resp.Cookies,
 		StatusCode: resp.StatusCode,
 	}, nil
 }
 
-func (g *Gateway) SignUp(ctx context.Context, email, password string) (*domain.SignUpResult, error) {
-	resp, err := g.makeRequestWithBody(ctx, http.MethodPost, g.apiBaseURL+signupURI, signUpRequest{
-		Email:    email,
-		Password: password,
+func (g *Gateway) SignUp(ctx context.Context, email, password, inviteCode string) (*domain.SignUpResult, error) {
+	resp, err := g.client.SignUp(ctx, apiclient.SignUpRequest{
+		Email:      email,
+		Password:   password,
+		InviteCode: inviteCode,
 	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to call API: %w", err)
+	if apiErr, ok := apiclient.AsError(err); ok {
+		return &domain.SignUpResult{
+			Status:     domain.ResponseStatusError,
+			Error:      apiErr.Detail,
+			ErrorCode:  apiErr.Code,
+			Cookies:    apiErr.Cookies,
+			StatusCode: apiErr.StatusCode,
+		}, nil
 	}
-	defer resp.Body.Close()
-
-	var respDTO signUpResponse
-	err = json.NewDecoder(resp.Body).Decode(&respDTO)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create account: %w", err)
+		return nil, fmt.Errorf("failed to call API: %w", err)
 	}
 
-	var status domain.ResponseStatus
-	if resp.StatusCode == http.StatusCreated {
-		status = domain.ResponseStatusSuccess
-	} else {
-		status = domain.ResponseStatusError
-	}
 	return &domain.SignUpResult{
-		Status:     status,
-		Message:    respDTO.Message,
-		Error:      respDTO.Error,
-		Cookies:    resp.Cookies(),
+		Status:     domain.ResponseStatusSuccess,
+		Message:    resp.Message,
+		Cookies:    resp.Cookies,
 		StatusCode: resp.StatusCode,
 	}, nil
 }
 
-func (g *Gateway) Logout(ctx context.Context) (*domain.LogoutResult, error) {
-	resp, err := g.makeRequestWithoutBody(ctx, http.MethodPost, g.apiBaseURL+logoutURI)
+func (g *Gateway) GetSignUpPolicy(ctx context.Context) (*domain.SignUpPolicyResult, error) {
+	resp, err := g.client.GetSignUpPolicy(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call API: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+	return &domain.SignUpPolicyResult{
+		Status:         domain.ResponseStatusSuccess,
+		Mode:           resp.Mode,
+		AllowedDomains: resp.AllowedDomains,
+		StatusCode:     resp.StatusCode,
+	}, nil
+}
 
-	var respDTO logoutResponse
-	err = json.NewDecoder(resp.Body).Decode(&respDTO)
+func (g *Gateway) Logout(ctx context.Context) (*domain.LogoutResult, error) {
+	resp, err := g.client.Logout(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to logout: %w", err)
+		return nil, fmt.Errorf("failed to call API: %w", err)
 	}
 
-	var status domain.ResponseStatus
-	if resp.StatusCode == http.StatusOK {
-		status = domain.ResponseStatusSuccess
-	} else {
-		status = domain.ResponseStatusError
-	}
 	return &domain.LogoutResult{
-		Status:     status,
-		Message:    respDTO.Message,
-		Error:      respDTO.Error,
-		Cookies:    resp.Cookies(),
+		Status:     domain.ResponseStatusSuccess,
+		Message:    resp.Message,
+		Cookies:    resp.Cookies,
 		StatusCode: resp.StatusCode,
 	}, nil
 }
 
 func (g *Gateway) CheckAuthStatus(ctx context.Context) (*domain.AuthStatusResult, error) {
-	resp, err := g.makeRequestWithoutBody(ctx, http.MethodGet, g.apiBaseURL+checkAuthStatusURI)
+	resp, err := g.client.GetAuthStatus(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call API: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
-
-	var respDTO authStatusResponse
-	err = json.NewDecoder(resp.Body).Decode(&respDTO)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check auth status: %w", err)
-	}
-
-	var status domain.ResponseStatus
-	if resp.StatusCode == http.StatusOK {
-		status = domain.ResponseStatusSuccess
-	} else {
-		status = domain.ResponseStatusError
-	}
 	return &domain.AuthStatusResult{
-		Status:          status,
-		IsAuthenticated: respDTO.IsAuthenticated,
+		Status:          domain.ResponseStatusSuccess,
+		IsAuthenticated: resp.Authenticated,
 		StatusCode:      resp.StatusCode,
-		Cookies:         resp.Cookies(),
+		Cookies:         resp.Cookies,
 	}, nil
 }
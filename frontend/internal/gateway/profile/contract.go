@@ -3,9 +3,25 @@ package profile
 import (
 	"context"
 	"frontend/internal/domain"
+	"time"
 )
 
 type Gateway interface {
 	GetProfile(ctx context.Context) (*domain.ProfileResult, error)
 	UpdateProfile(ctx context.Context, profile *domain.Profile) (*domain.ProfileResult, error)
+	GetProfileFields(ctx context.Context) (*domain.ProfileFieldsResult, error)
+	GetPublicProfile(ctx context.Context, handle string) (*domain.PublicProfileResult, error)
+	SearchDirectory(ctx context.Context, query, cursor string) (*domain.DirectoryResult, error)
+	DeleteAccount(ctx context.Context, password string) (*domain.DeleteAccountResult, error)
+	RequestEmailChange(ctx context.Context, email string) (*domain.EmailChangeResult, error)
+	RequestPhoneVerification(ctx context.Context) (*domain.PhoneVerificationResult, error)
+	ConfirmPhoneVerification(ctx context.Context, code string) (*domain.PhoneVerificationResult, error)
+	UploadAvatar(ctx context.Context, filename string, data []byte) (*domain.AvatarResult, error)
+	RequestDataExport(ctx context.Context) (*domain.DataExportResult, error)
+	GetLatestDataExport(ctx context.Context) (*domain.DataExportResult, error)
+	GetInvitation(ctx context.Context, token string) (*domain.InvitationResult, error)
+	AcceptInvitation(ctx context.Context, token string) (*domain.InvitationResult, error)
+	ListAccessTokens(ctx context.Context) (*domain.AccessTokensResult, error)
+	CreateAccessToken(ctx context.Context, name string, scopes []string, expiresAt *time.Time) (*domain.AccessTokenResult, error)
+	DeleteAccessToken(ctx context.Context, id int64) (*domain.AccessTokenResult, error)
 }
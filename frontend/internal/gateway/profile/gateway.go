@@ -1,133 +1,518 @@
 package profile
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
-	"net/http"
 	"time"
 
+	"apiclient"
 	"frontend/internal/domain"
-	"frontend/internal/pkg/httpclient"
-)
-
-const (
-	defaultTimeout   = 10 * time.Second
-	getProfileURI    = "/api/profile"
-	updateProfileURI = "/api/profile"
-	jsonContentType  = "application/json"
 )
 
 type gateway struct {
-	client     *http.Client
-	apiBaseURL string
+	client *apiclient.Client
 }
 
-func NewGateway(apiBaseURL string) Gateway {
+func NewGateway(client *apiclient.Client) Gateway {
 	return &gateway{
-		client:     httpclient.New(&http.Client{Timeout: defaultTimeout}),
-		apiBaseURL: apiBaseURL,
+		client: client,
 	}
 }
 
-func (g *gateway) makeRequestWithBody(ctx context.Context, method, url string, data interface{}) (*http.Response, error) {
-	reqBody, err := json.Marshal(data)
+func (g *gateway) GetProfile(ctx context.Context) (*domain.ProfileResult, error) {
+	resp, err := g.client.GetProfile(ctx)
+	if apiErr, ok := apiclient.AsError(err); ok {
+		return &domain.ProfileResult{
+			Status:     domain.ResponseStatusError,
+			Error:      apiErr.Detail,
+			ErrorCode:  apiErr.Code,
+			Cookies:    apiErr.Cookies,
+			StatusCode: apiErr.StatusCode,
+		}, nil
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(reqBody))
+	return &domain.ProfileResult{
+		Status:     domain.ResponseStatusSuccess,
+		Profile:    profileFromAPI(resp),
+		Cookies:    resp.Cookies,
+		StatusCode: resp.StatusCode,
+	}, nil
+}
+
+// UpdateProfile sends the edited fields as a merge patch, so the email is left
+// alone when it is not part of the form. The update only applies if the
+// profile still matches profile.ETag.
+func (g *gateway) UpdateProfile(ctx context.Context, profile *domain.Profile) (*domain.ProfileResult, error) {
+	patch := apiclient.ProfilePatch{
+		FullName:     &profile.FullName,
+		Phone:        &profile.Phone,
+		CustomFields: profile.CustomFields,
+		Handle:       &profile.Handle,
+		Public:       &profile.Public,
+		Visibility: &apiclient.ProfileVisibility{
+			FullName: profile.Visibility.FullName,
+			Email:    profile.Visibility.Email,
+			Phone:    profile.Visibility.Phone,
+			Avatar:   profile.Visibility.Avatar,
+		},
+	}
+	if profile.Email != "" {
+		patch.Email = &profile.Email
+	}
+
+	resp, err := g.client.PatchProfile(ctx, patch, profile.ETag)
+	if apiErr, ok := apiclient.AsError(err); ok {
+		return &domain.ProfileResult{
+			Status:      domain.ResponseStatusError,
+			Error:       apiErr.Detail,
+			ErrorCode:   apiErr.Code,
+			FieldErrors: apiErr.Fields,
+			Cookies:     apiErr.Cookies,
+			StatusCode:  apiErr.StatusCode,
+		}, nil
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
-	req.Header.Set("Content-Type", jsonContentType)
 
-	return g.client.Do(req)
+	return &domain.ProfileResult{
+		Status:     domain.ResponseStatusSuccess,
+		Profile:    profileFromAPI(resp),
+		Cookies:    resp.Cookies,
+		StatusCode: resp.StatusCode,
+	}, nil
 }
 
-func (g *gateway) makeRequestWithoutBody(ctx context.Context, method, url string) (*http.Response, error) {
-	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+// GetProfileFields returns the custom field definitions the edit form is
+// built from.
+func (g *gateway) GetProfileFields(ctx context.Context) (*domain.ProfileFieldsResult, error) {
+	resp, err := g.client.GetProfileFields(ctx)
+	if apiErr, ok := apiclient.AsError(err); ok {
+		return &domain.ProfileFieldsResult{
+			Status:     domain.ResponseStatusError,
+			Error:      apiErr.Detail,
+			ErrorCode:  apiErr.Code,
+			Cookies:    apiErr.Cookies,
+			StatusCode: apiErr.StatusCode,
+		}, nil
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
 
-	return g.client.Do(req)
+	result := &domain.ProfileFieldsResult{
+		Status:     domain.ResponseStatusSuccess,
+		Fields:     make([]domain.ProfileField, 0, len(resp.Fields)),
+		Cookies:    resp.Cookies,
+		StatusCode: resp.StatusCode,
+	}
+	for _, field := range resp.Fields {
+		result.Fields = append(result.Fields, domain.ProfileField{
+			Key:        field.Key,
+			Label:      field.Label,
+			Type:       field.Type,
+			Required:   field.Required,
+			Visibility: field.Visibility,
+			MinLength:  field.Rules.MinLength,
+			MaxLength:  field.Rules.MaxLength,
+			Pattern:    field.Rules.Pattern,
+			Min:        field.Rules.Min,
+			Max:        field.Rules.Max,
+			Options:    field.Rules.Options,
+		})
+	}
+	return result, nil
 }
 
-func (g *gateway) GetProfile(ctx context.Context) (*domain.ProfileResult, error) {
-	resp, err := g.makeRequestWithoutBody(ctx, http.MethodGet, g.apiBaseURL+getProfileURI)
+func (g *gateway) DeleteAccount(ctx context.Context, password string) (*domain.DeleteAccountResult, error) {
+	resp, err := g.client.DeleteAccount(ctx, password)
+	if apiErr, ok := apiclient.AsError(err); ok {
+		return &domain.DeleteAccountResult{
+			Status:     domain.ResponseStatusError,
+			Error:      apiErr.Detail,
+			ErrorCode:  apiErr.Code,
+			Cookies:    apiErr.Cookies,
+			StatusCode: apiErr.StatusCode,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.DeleteAccountResult{
+		Status:              domain.ResponseStatusSuccess,
+		Message:             resp.Message,
+		DeletionScheduledAt: resp.DeletionScheduledAt,
+		Cookies:             resp.Cookies,
+		StatusCode:          resp.StatusCode,
+	}, nil
+}
+
+func (g *gateway) RequestEmailChange(ctx context.Context, email string) (*domain.EmailChangeResult, error) {
+	resp, err := g.client.RequestEmailChange(ctx, email)
+	if apiErr, ok := apiclient.AsError(err); ok {
+		return &domain.EmailChangeResult{
+			Status:     domain.ResponseStatusError,
+			Error:      apiErr.Detail,
+			ErrorCode:  apiErr.Code,
+			Cookies:    apiErr.Cookies,
+			StatusCode: apiErr.StatusCode,
+		}, nil
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	result := &domain.ProfileResult{
+	return &domain.EmailChangeResult{
 		Status:     domain.ResponseStatusSuccess,
-		Cookies:    resp.Cookies(),
+		Message:    resp.Message,
+		NewEmail:   resp.NewEmail,
+		Cookies:    resp.Cookies,
 		StatusCode: resp.StatusCode,
+	}, nil
+}
+
+// UploadAvatar forwards the image; the API does all validation and
+// processing.
+func (g *gateway) UploadAvatar(ctx context.Context, filename string, data []byte) (*domain.AvatarResult, error) {
+	resp, err := g.client.UploadAvatar(ctx, filename, data)
+	if apiErr, ok := apiclient.AsError(err); ok {
+		return &domain.AvatarResult{
+			Status:     domain.ResponseStatusError,
+			Error:      apiErr.Detail,
+			ErrorCode:  apiErr.Code,
+			Cookies:    apiErr.Cookies,
+			StatusCode: apiErr.StatusCode,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.AvatarResult{
+		Status:     domain.ResponseStatusSuccess,
+		Message:    resp.Message,
+		AvatarURL:  resp.AvatarURL,
+		Cookies:    resp.Cookies,
+		StatusCode: resp.StatusCode,
+	}, nil
+}
+
+func (g *gateway) RequestPhoneVerification(ctx context.Context) (*domain.PhoneVerificationResult, error) {
+	resp, err := g.client.RequestPhoneVerification(ctx)
+	if err != nil {
+		return phoneVerificationError(err)
+	}
+
+	return &domain.PhoneVerificationResult{
+		Status:     domain.ResponseStatusSuccess,
+		Message:    resp.Message,
+		Cookies:    resp.Cookies,
+		StatusCode: resp.StatusCode,
+	}, nil
+}
+
+func (g *gateway) ConfirmPhoneVerification(ctx context.Context, code string) (*domain.PhoneVerificationResult, error) {
+	resp, err := g.client.ConfirmPhoneVerification(ctx, code)
+	if err != nil {
+		return phoneVerificationError(err)
+	}
+
+	return &domain.PhoneVerificationResult{
+		Status:     domain.ResponseStatusSuccess,
+		Message:    resp.Message,
+		Cookies:    resp.Cookies,
+		StatusCode: resp.StatusCode,
+	}, nil
+}
+
+func phoneVerificationError(err error) (*domain.PhoneVerificationResult, error) {
+	apiErr, ok := apiclient.AsError(err)
+	if !ok {
+		return nil, err
+	}
+	return &domain.PhoneVerificationResult{
+		Status:     domain.ResponseStatusError,
+		Error:      apiErr.Detail,
+		ErrorCode:  apiErr.Code,
+		Cookies:    apiErr.Cookies,
+		StatusCode: apiErr.StatusCode,
+	}, nil
+}
+
+func (g *gateway) RequestDataExport(ctx context.Context) (*domain.DataExportResult, error) {
+	resp, err := g.client.RequestDataExport(ctx)
+	return dataExportResult(resp, err)
+}
+
+func (g *gateway) GetLatestDataExport(ctx context.Context) (*domain.DataExportResult, error) {
+	resp, err := g.client.GetLatestDataExport(ctx)
+	return dataExportResult(resp, err)
+}
+
+func dataExportResult(resp *apiclient.DataExport, err error) (*domain.DataExportResult, error) {
+	if apiErr, ok := apiclient.AsError(err); ok {
+		return &domain.DataExportResult{
+			Status:     domain.ResponseStatusError,
+			Error:      apiErr.Detail,
+			ErrorCode:  apiErr.Code,
+			Cookies:    apiErr.Cookies,
+			StatusCode: apiErr.StatusCode,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		result.Status = domain.ResponseStatusError
-		var errorResp struct {
-			Error string `json:"error"`
-		}
-		if err := json.NewDecoder(resp.Body).Decode(&errorResp); err == nil {
-			result.Error = errorResp.Error
-		} else {
-			result.Error = fmt.Sprintf("failed to get profile: status %d", resp.StatusCode)
-		}
-		return result, nil
+	return &domain.DataExportResult{
+		Status: domain.ResponseStatusSuccess,
+		Export: &domain.DataExport{
+			ID:          resp.ID,
+			Status:      resp.Status,
+			CreatedAt:   resp.CreatedAt,
+			CompletedAt: resp.CompletedAt,
+			ExpiresAt:   resp.ExpiresAt,
+		},
+		Cookies:    resp.Cookies,
+		StatusCode: resp.StatusCode,
+	}, nil
+}
+
+// GetPublicProfile fetches the profile published under handle as the current
+// visitor, signed in or not, may see it.
+func (g *gateway) GetPublicProfile(ctx context.Context, handle string) (*domain.PublicProfileResult, error) {
+	resp, err := g.client.GetPublicProfile(ctx, handle)
+	if apiErr, ok := apiclient.AsError(err); ok {
+		return &domain.PublicProfileResult{
+			Status:     domain.ResponseStatusError,
+			Error:      apiErr.Detail,
+			ErrorCode:  apiErr.Code,
+			Cookies:    apiErr.Cookies,
+			StatusCode: apiErr.StatusCode,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	var profileResp profileResponse
-	if err := json.NewDecoder(resp.Body).Decode(&profileResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	result := &domain.PublicProfileResult{
+		Status: domain.ResponseStatusSuccess,
+		Profile: &domain.PublicProfile{
+			Handle:    resp.Handle,
+			FullName:  resp.FullName,
+			Email:     resp.Email,
+			Phone:     resp.Phone,
+			AvatarURL: resp.AvatarURL,
+		},
+		Cookies:    resp.Cookies,
+		StatusCode: resp.StatusCode,
+	}
+	for _, field := range resp.CustomFields {
+		result.Profile.CustomFields = append(result.Profile.CustomFields, domain.PublicProfileField{
+			Label: field.Label,
+			Type:  field.Type,
+			Value: field.Value,
+		})
 	}
+	return result, nil
+}
 
-	result.Profile = &domain.Profile{
-		FullName: profileResp.FullName,
-		Phone:    profileResp.Phone,
-		Email:    profileResp.Email,
+// SearchDirectory looks other users up by name or email; cursor continues
+// a previous page.
+func (g *gateway) SearchDirectory(ctx context.Context, query, cursor string) (*domain.DirectoryResult, error) {
+	resp, err := g.client.SearchDirectory(ctx, query, cursor)
+	if apiErr, ok := apiclient.AsError(err); ok {
+		return &domain.DirectoryResult{
+			Status:      domain.ResponseStatusError,
+			Error:       apiErr.Detail,
+			ErrorCode:   apiErr.Code,
+			FieldErrors: apiErr.Fields,
+			Cookies:     apiErr.Cookies,
+			StatusCode:  apiErr.StatusCode,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
 	}
 
+	result := &domain.DirectoryResult{
+		Status:     domain.ResponseStatusSuccess,
+		NextCursor: resp.NextCursor,
+		Cookies:    resp.Cookies,
+		StatusCode: resp.StatusCode,
+	}
+	for _, user := range resp.Users {
+		result.Users = append(result.Users, domain.PublicProfile{
+			Handle:    user.Handle,
+			FullName:  user.FullName,
+			Email:     user.Email,
+			Phone:     user.Phone,
+			AvatarURL: user.AvatarURL,
+		})
+	}
 	return result, nil
 }
 
-func (g *gateway) UpdateProfile(ctx context.Context, profile *domain.Profile) (*domain.ProfileResult, error) {
-	req := profileRequest{
-		FullName: profile.FullName,
-		Phone:    profile.Phone,
-		Email:    profile.Email,
+func (g *gateway) GetInvitation(ctx context.Context, token string) (*domain.InvitationResult, error) {
+	resp, err := g.client.GetInvitation(ctx, token)
+	if err != nil {
+		return invitationError(err)
 	}
 
-	resp, err := g.makeRequestWithBody(ctx, http.MethodPut, g.apiBaseURL+updateProfileURI, req)
+	return &domain.InvitationResult{
+		Status:           domain.ResponseStatusSuccess,
+		OrganizationName: resp.OrganizationName,
+		Email:            resp.Email,
+		Role:             resp.Role,
+		ExpiresAt:        resp.ExpiresAt,
+		Cookies:          resp.Cookies,
+		StatusCode:       resp.StatusCode,
+	}, nil
+}
+
+func (g *gateway) AcceptInvitation(ctx context.Context, token string) (*domain.InvitationResult, error) {
+	resp, err := g.client.AcceptInvitation(ctx, token)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
+		return invitationError(err)
 	}
-	defer resp.Body.Close()
 
-	result := &domain.ProfileResult{
+	return &domain.InvitationResult{
+		Status:           domain.ResponseStatusSuccess,
+		OrganizationName: resp.Name,
+		Role:             resp.Role,
+		Cookies:          resp.Cookies,
+		StatusCode:       resp.StatusCode,
+	}, nil
+}
+
+func invitationError(err error) (*domain.InvitationResult, error) {
+	apiErr, ok := apiclient.AsError(err)
+	if !ok {
+		return nil, err
+	}
+	return &domain.InvitationResult{
+		Status:     domain.ResponseStatusError,
+		Error:      apiErr.Detail,
+		ErrorCode:  apiErr.Code,
+		Cookies:    apiErr.Cookies,
+		StatusCode: apiErr.StatusCode,
+	}, nil
+}
+
+func (g *gateway) ListAccessTokens(ctx context.Context) (*domain.AccessTokensResult, error) {
+	resp, err := g.client.ListAccessTokens(ctx)
+	if apiErr, ok := apiclient.AsError(err); ok {
+		return &domain.AccessTokensResult{
+			Status:     domain.ResponseStatusError,
+			Error:      apiErr.Detail,
+			ErrorCode:  apiErr.Code,
+			Cookies:    apiErr.Cookies,
+			StatusCode: apiErr.StatusCode,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]domain.AccessToken, 0, len(resp.Tokens))
+	for _, token := range resp.Tokens {
+		tokens = append(tokens, accessTokenFromAPI(&token))
+	}
+	return &domain.AccessTokensResult{
 		Status:     domain.ResponseStatusSuccess,
-		Cookies:    resp.Cookies(),
+		Tokens:     tokens,
+		Cookies:    resp.Cookies,
 		StatusCode: resp.StatusCode,
+	}, nil
+}
+
+func (g *gateway) CreateAccessToken(ctx context.Context, name string, scopes []string, expiresAt *time.Time) (*domain.AccessTokenResult, error) {
+	request := apiclient.AccessTokenRequest{
+		Name:      name,
+		Scopes:    make([]apiclient.AccessTokenScope, 0, len(scopes)),
+		ExpiresAt: expiresAt,
+	}
+	for _, scope := range scopes {
+		request.Scopes = append(request.Scopes, apiclient.AccessTokenScope(scope))
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		result.Status = domain.ResponseStatusError
-		var errorResp struct {
-			Error string `json:"error"`
-		}
-		if err := json.NewDecoder(resp.Body).Decode(&errorResp); err == nil {
-			result.Error = errorResp.Error
-		} else {
-			result.Error = fmt.Sprintf("failed to update profile: status %d", resp.StatusCode)
-		}
-		return result, nil
+	resp, err := g.client.CreateAccessToken(ctx, request)
+	if err != nil {
+		return accessTokenError(err)
 	}
 
-	result.Profile = profile
+	token := accessTokenFromAPI(resp)
+	return &domain.AccessTokenResult{
+		Status:     domain.ResponseStatusSuccess,
+		Token:      &token,
+		Value:      resp.Token,
+		Cookies:    resp.Cookies,
+		StatusCode: resp.StatusCode,
+	}, nil
+}
 
-	return result, nil
+func (g *gateway) DeleteAccessToken(ctx context.Context, id int64) (*domain.AccessTokenResult, error) {
+	resp, err := g.client.DeleteAccessToken(ctx, id)
+	if err != nil {
+		return accessTokenError(err)
+	}
+
+	return &domain.AccessTokenResult{
+		Status:     domain.ResponseStatusSuccess,
+		Cookies:    resp.Cookies,
+		StatusCode: resp.StatusCode,
+	}, nil
+}
+
+func accessTokenError(err error) (*domain.AccessTokenResult, error) {
+	apiErr, ok := apiclient.AsError(err)
+	if !ok {
+		return nil, err
+	}
+	return &domain.AccessTokenResult{
+		Status:      domain.ResponseStatusError,
+		Error:       apiErr.Detail,
+		ErrorCode:   apiErr.Code,
+		FieldErrors: apiErr.Fields,
+		Cookies:     apiErr.Cookies,
+		StatusCode:  apiErr.StatusCode,
+	}, nil
+}
+
+func accessTokenFromAPI(resp *apiclient.AccessToken) domain.AccessToken {
+	scopes := make([]string, 0, len(resp.Scopes))
+	for _, scope := range resp.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+	return domain.AccessToken{
+		ID:         resp.ID,
+		Name:       resp.Name,
+		Scopes:     scopes,
+		CreatedAt:  resp.CreatedAt,
+		ExpiresAt:  resp.ExpiresAt,
+		LastUsedAt: resp.LastUsedAt,
+	}
+}
+
+func profileFromAPI(resp *apiclient.Profile) *domain.Profile {
+	return &domain.Profile{
+		FullName:        resp.FullName,
+		Phone:           resp.Phone,
+		PhoneVerifiedAt: resp.PhoneVerifiedAt,
+		Email:           resp.Email,
+		PendingEmail:    resp.PendingEmail,
+		AvatarURL:       resp.AvatarURL,
+		CustomFields:    resp.CustomFields,
+		Handle:          resp.Handle,
+		Public:          resp.Public,
+		Visibility: domain.ProfileVisibility{
+			FullName: resp.Visibility.FullName,
+			Email:    resp.Visibility.Email,
+			Phone:    resp.Visibility.Phone,
+			Avatar:   resp.Visibility.Avatar,
+		},
+		ETag: resp.ETag,
+	}
 }
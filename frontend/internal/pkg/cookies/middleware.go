@@ -1,10 +1,16 @@
 package cookies
 
-import "net/http"
+import (
+	"net/http"
 
+	"apiclient"
+)
+
+// Middleware keeps the browser's cookies in the request context, where
+// apiclient picks them up for the calls made on the browser's behalf.
 func Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx := WithCookies(r.Context(), r.Cookies())
+		ctx := apiclient.WithCookies(r.Context(), r.Cookies())
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
@@ -0,0 +1,129 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOK           = "ok"
+	StatusFail         = "fail"
+	StatusShuttingDown = "shutting_down"
+)
+
+// Check reports whether a dependency is usable. It must return once ctx is
+// done.
+type Check func(ctx context.Context) error
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker serves /healthz and /readyz. Liveness only says the process is up;
+// readiness runs every check and fails once shutdown has begun, so a load
+// balancer stops sending traffic before the listener closes.
+type Checker struct {
+	timeout      time.Duration
+	checks       []namedCheck
+	shuttingDown atomic.Bool
+}
+
+type checkResult struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+type report struct {
+	Status string                 `json:"status"`
+	Checks map[string]checkResult `json:"checks,omitempty"`
+}
+
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+// Add registers a readiness check. Checks must be added before serving.
+func (c *Checker) Add(name string, check Check) {
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// ShutDown makes readiness fail from now on.
+func (c *Checker) ShutDown() {
+	c.shuttingDown.Store(true)
+}
+
+func (c *Checker) Liveness(w http.ResponseWriter, _ *http.Request) {
+	writeReport(w, http.StatusOK, report{Status: StatusOK})
+}
+
+func (c *Checker) Readiness(w http.ResponseWriter, r *http.Request) {
+	result := c.run(r.Context())
+	statusCode := http.StatusOK
+	if c.shuttingDown.Load() {
+		result.Status = StatusShuttingDown
+	}
+	if result.Status != StatusOK {
+		statusCode = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeReport(w, statusCode, result)
+}
+
+// run executes the checks concurrently, each bounded by the checker timeout.
+func (c *Checker) run(ctx context.Context) report {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	result := report{Status: StatusOK, Checks: make(map[string]checkResult, len(c.checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, nc := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			err := runCheck(ctx, nc.check)
+
+			checkRes := checkResult{Status: StatusOK, DurationMS: time.Since(start).Milliseconds()}
+			if err != nil {
+				checkRes.Status = StatusFail
+				checkRes.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			result.Checks[nc.name] = checkRes
+			if err != nil {
+				result.Status = StatusFail
+			}
+		}()
+	}
+	wg.Wait()
+	return result
+}
+
+// runCheck stops waiting for a check that ignores its context.
+func runCheck(ctx context.Context, check Check) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- check(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func writeReport(w http.ResponseWriter, statusCode int, result report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(result)
+}
@@ -3,47 +3,61 @@ package httpclient
 import (
 	"net/http"
 
-	"frontend/internal/pkg/cookies"
-)
+	"frontend/internal/pkg/requestid"
+	"frontend/internal/pkg/tracing"
 
-const (
-	csrfTokenCookieName = "csrf_token"
-	csrfTokenHeaderName = "X-CSRF-Token"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
-type CookieTransport struct {
+// Transport passes the request ID and trace context on to the API. The
+// browser's cookies and the CSRF header are added by apiclient, from the
+// cookies the cookies middleware stored in the request context.
+type Transport struct {
 	Base http.RoundTripper
 }
 
-func (t *CookieTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	for _, cookie := range cookies.FromContext(req.Context()) {
-		req.AddCookie(cookie)
-		if cookie.Name == csrfTokenCookieName && cookie.Value != "" {
-			req.Header.Set(csrfTokenHeaderName, cookie.Value)
-		}
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if id := requestid.FromContext(req.Context()); id != "" {
+		req.Header.Set(requestid.Header, id)
 	}
 
-	if req.Header.Get(csrfTokenHeaderName) == "" {
-		for _, cookie := range req.Cookies() {
-			if cookie.Name == csrfTokenCookieName && cookie.Value != "" {
-				req.Header.Set(csrfTokenHeaderName, cookie.Value)
-				break
-			}
-		}
-	}
+	// Each gateway call is a client span, and the API server joins the trace
+	// through the traceparent header.
+	ctx, span := tracing.Start(req.Context(), req.Method+" "+req.URL.Path,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("server.address", req.URL.Host),
+			attribute.String("url.path", req.URL.Path),
+		),
+	)
+	defer span.End()
+	tracing.Inject(ctx, req.Header)
 
 	base := t.Base
 	if base == nil {
 		base = http.DefaultTransport
 	}
-	return base.RoundTrip(req)
+	resp, err := base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	return resp, nil
 }
 
 func New(client *http.Client) *http.Client {
 	if client.Transport == nil {
-		client.Transport = &CookieTransport{}
+		client.Transport = &Transport{}
 	} else {
-		client.Transport = &CookieTransport{Base: client.Transport}
+		client.Transport = &Transport{Base: client.Transport}
 	}
 	return client
 }
@@ -0,0 +1,140 @@
+package i18n
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const defaultLanguage = "en"
+
+// catalog maps a language to the messages shown for the API error codes.
+// Codes missing from a language fall back to the text sent by the server.
+var catalog = map[string]map[string]string{
+	"en": {
+		"bad_request":            "The request could not be processed.",
+		"unauthorized":           "Please log in to continue.",
+		"forbidden":              "You are not allowed to do this.",
+		"not_found":              "The page you were looking for was not found.",
+		"payload_too_large":      "The upload is too large.",
+		"unsupported_media_type": "This file type is not supported.",
+		"validation_failed":      "Please correct the highlighted fields.",
+		"invalid_request":        "Some of the values sent were not valid.",
+		"too_many_requests":      "Too many requests, please wait a moment.",
+		"internal_error":         "Something went wrong on our side. Please try again.",
+		"unavailable":            "The service is temporarily unavailable. Please try again later.",
+
+		"user_already_exists":       "An account with this email already exists.",
+		"invalid_email":             "Please enter a valid email address.",
+		"invalid_password":          "The password is incorrect.",
+		"user_not_found":            "No account exists for this email.",
+		"invalid_google_code":       "Google sign-in failed. Please try again.",
+		"reauthentication_required": "Please enter your password again to continue.",
+		"session_not_found":         "Your session has expired. Please log in again.",
+		"session_active":            "You are already logged in.",
+		"invalid_csrf_token":        "Your form has expired. Please reload the page and try again.",
+
+		"access_token_not_found": "The access token was not found.",
+		"too_many_access_tokens": "You have too many access tokens. Revoke one you no longer use first.",
+		"insufficient_scope":     "The access token does not allow this.",
+
+		"invite_code_required":     "An invite code is required to sign up.",
+		"invalid_invite_code":      "The invite code is invalid, expired or already used.",
+		"invite_code_not_found":    "The invite code was not found.",
+		"email_domain_not_allowed": "Sign-up is not open to this email domain.",
+		"email_not_verified":       "Your Google account's email address is not verified.",
+
+		"export_not_found": "The data export was not found.",
+		"export_not_ready": "The data export is not ready yet.",
+		"export_expired":   "The data export has expired. Please request a new one.",
+
+		"profile_version_mismatch": "Your profile was changed elsewhere. Please reload and try again.",
+		"handle_taken":             "This handle is already taken.",
+
+		"unsupported_image":     "The avatar must be a PNG, JPEG or WebP image.",
+		"image_too_large":       "The avatar image is too large.",
+		"avatar_not_found":      "The avatar was not found.",
+		"invalid_avatar_size":   "The avatar size is not valid.",
+		"invalid_avatar_format": "The avatar format must be PNG or SVG.",
+
+		"email_change_requires_confirmation": "Your email can only be changed with confirmation.",
+		"email_unchanged":                    "This is already your email address.",
+		"email_change_not_found":             "The email change request was not found.",
+		"email_change_expired":               "The email change request has expired.",
+
+		"invalid_phone":                "Please enter a valid phone number.",
+		"phone_not_set":                "Add a phone number to your profile first.",
+		"phone_already_verified":       "Your phone number is already verified.",
+		"phone_verification_not_found": "No verification code was requested for this phone number.",
+		"phone_verification_expired":   "The verification code has expired. Please request a new one.",
+		"phone_verification_too_soon":  "A code was sent recently. Please wait before requesting another one.",
+		"too_many_phone_code_attempts": "Too many wrong codes. Please request a new one.",
+		"invalid_phone_code":           "The verification code is incorrect.",
+
+		"organization_not_found":         "The organization was not found.",
+		"not_organization_member":        "The organization or member was not found.",
+		"already_organization_member":    "You are already a member of this organization.",
+		"organization_permission_denied": "Your role in this organization does not allow this.",
+		"last_organization_owner":        "An organization must keep at least one owner.",
+		"invitation_not_found":           "The invitation was not found.",
+		"invitation_expired":             "The invitation has expired.",
+		"invitation_for_another_email":   "This invitation was sent to another email address.",
+
+		"rate_limited": "Too many requests, please wait a moment.",
+	},
+}
+
+// Message returns the message for an API error code in the language the
+// browser prefers, or fallback when the code is empty or not translated.
+func Message(r *http.Request, code, fallback string) string {
+	if code == "" {
+		return fallback
+	}
+	for _, lang := range preferredLanguages(r.Header.Get("Accept-Language")) {
+		if messages, ok := catalog[lang]; ok {
+			if message, ok := messages[code]; ok {
+				return message
+			}
+			return fallback
+		}
+	}
+	if message, ok := catalog[defaultLanguage][code]; ok {
+		return message
+	}
+	return fallback
+}
+
+// preferredLanguages lists the primary subtags of an Accept-Language header,
+// most preferred first.
+func preferredLanguages(header string) []string {
+	type weighted struct {
+		lang string
+		q    float64
+	}
+
+	var langs []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil || parsed <= 0 {
+				continue
+			}
+			q = parsed
+		}
+		lang, _, _ := strings.Cut(tag, "-")
+		langs = append(langs, weighted{lang: strings.ToLower(lang), q: q})
+	}
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].q > langs[j].q })
+
+	result := make([]string, 0, len(langs))
+	for _, l := range langs {
+		result = append(result, l.lang)
+	}
+	return result
+}
@@ -52,7 +52,7 @@ func (m *LoggingMiddleware) AccessLog(next http.Handler) http.Handler {
 		next.ServeHTTP(rw, r)
 
 		duration := time.Since(start)
-		m.logger.Info("access log",
+		m.logger.InfoContext(r.Context(), "access log",
 			"method", r.Method,
 			"path", r.URL.Path,
 			"query", r.URL.RawQuery,
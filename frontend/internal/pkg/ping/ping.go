@@ -11,7 +11,7 @@ import (
 
 const (
 	defaultTimeout = 5 * time.Second
-	pingURI        = "/ping"
+	pingURI        = "/healthz"
 )
 
 type Client struct {
@@ -7,6 +7,13 @@ import (
 	"net/url"
 	"strings"
 	"time"
+
+	"frontend/internal/pkg/requestid"
+	"frontend/internal/pkg/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -41,7 +48,7 @@ func (p *ProxyHandler) proxyRequest(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 
 	if r.ContentLength > maxRequestBodySize {
-		p.logger.Warn("request body too large", "size", r.ContentLength, "max", maxRequestBodySize)
+		p.logger.WarnContext(r.Context(), "request body too large", "size", r.ContentLength, "max", maxRequestBodySize)
 		http.Error(w, "Request Entity Too Large", http.StatusRequestEntityTooLarge)
 		return
 	}
@@ -53,53 +60,82 @@ func (p *ProxyHandler) proxyRequest(w http.ResponseWriter, r *http.Request) {
 
 	backendURL, err := url.Parse(p.backendURL + r.URL.Path)
 	if err != nil {
-		p.logger.Error("failed to parse backend URL", "error", err)
+		p.logger.ErrorContext(r.Context(), "failed to parse backend URL", "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 
 	backendURL.RawQuery = r.URL.RawQuery
 
-	req, err := http.NewRequestWithContext(r.Context(), r.Method, backendURL.String(), body)
+	ctx, span := tracing.Start(r.Context(), "proxy "+r.Method+" "+r.URL.Path,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", r.Method),
+			attribute.String("server.address", backendURL.Host),
+			attribute.String("url.path", r.URL.Path),
+		),
+	)
+	defer span.End()
+
+	req, err := http.NewRequestWithContext(ctx, r.Method, backendURL.String(), body)
 	if err != nil {
-		p.logger.Error("failed to create proxy request", "error", err)
+		p.logger.ErrorContext(r.Context(), "failed to create proxy request", "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 
 	for key, values := range r.Header {
-		if strings.ToLower(key) == "host" {
+		// A trace context sent by the browser is replaced by the proxy span's.
+		switch strings.ToLower(key) {
+		case "host", "traceparent", "tracestate":
 			continue
 		}
 		for _, value := range values {
 			req.Header.Add(key, value)
 		}
 	}
+	tracing.Inject(ctx, req.Header)
+	if id := requestid.FromContext(ctx); id != "" {
+		req.Header.Set(requestid.Header, id)
+	}
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		p.logger.Error("failed to proxy request", "error", err, "path", r.URL.Path)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		p.logger.ErrorContext(r.Context(), "failed to proxy request", "error", err, "path", r.URL.Path)
 		http.Error(w, "Bad Gateway", http.StatusBadGateway)
 		return
 	}
 	defer resp.Body.Close()
 
+	// The backend knows best how its responses may be cached, so its headers
+	// replace the no-cache defaults set for pages.
+	if resp.Header.Get("Cache-Control") != "" {
+		w.Header().Del("Pragma")
+		w.Header().Del("Expires")
+	}
 	for key, values := range resp.Header {
+		w.Header().Del(key)
 		for _, value := range values {
 			w.Header().Add(key, value)
 		}
 	}
 
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, resp.Status)
+	}
 	w.WriteHeader(resp.StatusCode)
 
 	size, err := io.Copy(w, resp.Body)
 	if err != nil {
-		p.logger.Error("failed to copy response body", "error", err, "bytes_copied", size)
+		p.logger.ErrorContext(r.Context(), "failed to copy response body", "error", err, "bytes_copied", size)
 		return
 	}
 
 	duration := time.Since(start)
-	p.logger.Info("proxy response",
+	p.logger.InfoContext(r.Context(), "proxy response",
 		"method", r.Method,
 		"path", r.URL.Path,
 		"query", r.URL.RawQuery,
@@ -2,28 +2,59 @@ package main
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"flag"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/XSAM/otelsql"
 	_ "github.com/go-sql-driver/mysql"
+	"go.opentelemetry.io/otel/attribute"
 
 	"server/internal/config"
+	accessTokenDelivery "server/internal/delivery/accesstoken"
+	auditDelivery "server/internal/delivery/audit"
 	authDelivery "server/internal/delivery/auth"
 	csrfDelivery "server/internal/delivery/csrf"
+	organizationDelivery "server/internal/delivery/organization"
 	profileDelivery "server/internal/delivery/profile"
+	profileFieldDelivery "server/internal/delivery/profilefield"
+	"server/internal/domain"
+	blobGateway "server/internal/gateway/blob"
 	authGateway "server/internal/gateway/google"
+	mailGateway "server/internal/gateway/mail"
+	smsGateway "server/internal/gateway/sms"
+	"server/internal/pkg/health"
+	"server/internal/pkg/httptools"
+	"server/internal/pkg/job"
+	"server/internal/pkg/jwt"
+	"server/internal/pkg/logging"
+	"server/internal/pkg/metrics"
 	middleware "server/internal/pkg/middleware"
+	"server/internal/pkg/openapi"
+	"server/internal/pkg/tracing"
+	accessTokenRepo "server/internal/repository/accesstoken"
+	auditRepo "server/internal/repository/audit"
+	exportRepo "server/internal/repository/export"
+	inviteRepo "server/internal/repository/invite"
+	organizationRepo "server/internal/repository/organization"
+	profileFieldRepo "server/internal/repository/profilefield"
+	refreshTokenRepo "server/internal/repository/refreshtoken"
 	sessionRepo "server/internal/repository/session"
 	userRepo "server/internal/repository/user"
+	accessTokenUC "server/internal/usecase/accesstoken"
+	auditUC "server/internal/usecase/audit"
 	authUC "server/internal/usecase/auth"
 	csrfUC "server/internal/usecase/csrf"
+	organizationUC "server/internal/usecase/organization"
 	profileUC "server/internal/usecase/profile"
+	profileFieldUC "server/internal/usecase/profilefield"
 
 	"github.com/rs/cors"
 )
@@ -33,9 +64,9 @@ func main() {
 	envPath := flag.String("env", ".env", "path to .env file")
 	flag.Parse()
 
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+	logger := slog.New(logging.NewContextHandler(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
 		Level: slog.LevelInfo,
-	}))
+	})))
 
 	cfg, err := config.Load(*configPath, *envPath)
 	if err != nil {
@@ -43,7 +74,30 @@ func main() {
 		os.Exit(1)
 	}
 
-	db, err := sql.Open("mysql", cfg.Database.DSN())
+	shutdownTracing := func(context.Context) error { return nil }
+	if cfg.Tracing.Enabled {
+		shutdownTracing, err = tracing.Setup(context.Background(), tracing.Config{
+			ServiceName:  cfg.Tracing.ServiceName,
+			OTLPEndpoint: cfg.Tracing.OTLPEndpoint,
+			File:         cfg.Tracing.File,
+			SampleRatio:  cfg.Tracing.SampleRatio,
+		})
+		if err != nil {
+			logger.Error("failed to set up tracing", "error", err)
+			os.Exit(1)
+		}
+		logger.Info("tracing enabled", "otlp_endpoint", cfg.Tracing.OTLPEndpoint, "file", cfg.Tracing.File)
+	}
+
+	// Every query gets a span of its own below the calling use case's span.
+	db, err := otelsql.Open("mysql", cfg.Database.DSN(),
+		otelsql.WithAttributes(attribute.String("db.system", "mysql")),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			DisableErrSkip:       true,
+			OmitConnResetSession: true,
+			OmitRows:             true,
+		}),
+	)
 	if err != nil {
 		logger.Error("failed to open database", "error", err)
 		os.Exit(1)
@@ -57,6 +111,28 @@ func main() {
 
 	userRepository := userRepo.NewRepository(logger, db)
 	sessionRepository := sessionRepo.NewRepository()
+	auditRepository := auditRepo.NewRepository(logger, db)
+	exportRepository := exportRepo.NewRepository(logger, db)
+	profileFieldRepository := profileFieldRepo.NewRepository(logger, db)
+	organizationRepository := organizationRepo.NewRepository(logger, db)
+	inviteRepository := inviteRepo.NewRepository(logger, db)
+	accessTokenRepository := accessTokenRepo.NewRepository(logger, db)
+	refreshTokenRepository := refreshTokenRepo.NewRepository(logger, db)
+
+	healthChecker := health.NewChecker(cfg.Health.CheckTimeout)
+	healthChecker.Add("mysql", db.PingContext)
+	healthChecker.Add("session_store", sessionRepository.Ping)
+	healthChecker.Add("oauth", func(context.Context) error {
+		return checkGoogleOAuthConfig(cfg.OAuth.Google)
+	})
+
+	metricsRegistry := metrics.NewRegistry()
+	metricsRegistry.RegisterGoRuntime()
+	metricsRegistry.RegisterDBStats(db)
+	metricsRegistry.NewGaugeFunc("sessions_stored", "Number of sessions in the session store.", func() float64 {
+		return float64(sessionRepository.Count())
+	})
+	authMetrics := metrics.NewAuthMetrics(metricsRegistry)
 
 	googleOAuthGateway := authGateway.NewOAuthGateway(authGateway.GoogleOAuthConfig{
 		ClientID:     cfg.OAuth.Google.ClientID,
@@ -64,24 +140,124 @@ func main() {
 		RedirectURL:  cfg.OAuth.Google.RedirectURL,
 	})
 
+	mailer := mailGateway.NewLogMailer(logger)
+	smsSender, err := newSMSSender(cfg.Phone.SMSProvider, logger)
+	if err != nil {
+		logger.Error("failed to create sms sender", "error", err)
+		os.Exit(1)
+	}
+
+	blobStore, err := blobGateway.NewLocalStore(cfg.Storage.BlobDir)
+	if err != nil {
+		logger.Error("failed to create blob store", "error", err)
+		os.Exit(1)
+	}
+
+	signUpPolicy, err := newSignUpPolicy(cfg.SignUp)
+	if err != nil {
+		logger.Error("invalid sign-up policy", "error", err)
+		os.Exit(1)
+	}
+
+	// tokenSigner stays a nil interface, not a nil *jwt.Signer, when no key
+	// is configured, so the auth use case sees the issuer as off.
+	var tokenSigner authUC.TokenSigner
+	jwtSigner, err := newJWTSigner(cfg.JWT)
+	if err != nil {
+		logger.Error("invalid jwt keys", "error", err)
+		os.Exit(1)
+	}
+	if jwtSigner != nil {
+		tokenSigner = jwtSigner
+	}
+
 	csrfUseCase := csrfUC.NewUseCase(logger)
-	profileUseCase := profileUC.NewUseCase(logger, userRepository)
-	authUseCase := authUC.NewUseCase(logger, userRepository, sessionRepository, googleOAuthGateway, csrfUseCase)
+	auditUseCase := auditUC.NewUseCase(logger, auditRepository)
+	profileUseCase := profileUC.NewUseCase(logger, userRepository, sessionRepository, refreshTokenRepository, accessTokenRepository, organizationRepository, exportRepository, profileFieldRepository, auditUseCase, mailer, smsSender, blobStore, profileUC.Config{
+		DeletionGracePeriod: cfg.Account.DeletionGracePeriod,
+		ExportTTL:           cfg.Account.ExportTTL,
+		EmailChangeTTL:      cfg.Account.EmailChangeTTL,
+		LinkBaseURL:         cfg.Server.FrontendURL,
+
+		DefaultPhoneRegion:      cfg.Phone.DefaultRegion,
+		PhoneCodeTTL:            cfg.Phone.CodeTTL,
+		PhoneCodeResendInterval: cfg.Phone.CodeResendInterval,
+		PhoneCodeMaxAttempts:    cfg.Phone.CodeMaxAttempts,
+
+		AvatarMaxBytes: cfg.Avatar.MaxUploadSize,
+
+		DirectorySearchLimit:  cfg.Directory.SearchLimit,
+		DirectorySearchPeriod: cfg.Directory.SearchPeriod,
+	})
+	profileFieldUseCase := profileFieldUC.NewUseCase(logger, profileFieldRepository, auditUseCase)
+	accessTokenUseCase := accessTokenUC.NewUseCase(logger, accessTokenRepository, auditUseCase)
+	authUseCase := authUC.NewUseCase(logger, userRepository, sessionRepository, inviteRepository, refreshTokenRepository, googleOAuthGateway, csrfUseCase, tokenSigner, auditUseCase, authMetrics, authUC.Config{
+		SignUpPolicy:   signUpPolicy,
+		InviteCodeTTL:  cfg.SignUp.InviteCodeTTL,
+		BearerTokenTTL: cfg.Auth.BearerTokenTTL,
+		JWT: authUC.JWTConfig{
+			Issuer:          cfg.JWT.Issuer,
+			Audience:        cfg.JWT.Audience,
+			AccessTokenTTL:  cfg.JWT.AccessTokenTTL,
+			RefreshTokenTTL: cfg.JWT.RefreshTokenTTL,
+			IncludeEmail:    cfg.JWT.IncludeEmail,
+			Claims:          cfg.JWT.Claims,
+		},
+	})
+	organizationUseCase := organizationUC.NewUseCase(logger, organizationRepository, userRepository, sessionRepository, auditUseCase, mailer, organizationUC.Config{
+		InvitationTTL: cfg.Organization.InvitationTTL,
+		LinkBaseURL:   cfg.Server.FrontendURL,
+	})
 
 	authHandler := authDelivery.NewHandler(authUseCase, sessionRepository, logger, cfg.Server.FrontendURL, cfg)
-	profileHandler := profileDelivery.NewHandler(logger, profileUseCase)
+	profileHandler := profileDelivery.NewHandler(logger, profileUseCase, cfg.Server.FrontendURL, cfg.Avatar.MaxUploadSize)
+	auditHandler := auditDelivery.NewHandler(logger, auditUseCase)
+	profileFieldHandler := profileFieldDelivery.NewHandler(logger, profileFieldUseCase)
+	organizationHandler := organizationDelivery.NewHandler(logger, organizationUseCase)
+	accessTokenHandler := accessTokenDelivery.NewHandler(logger, accessTokenUseCase)
 
-	authMiddleware := authDelivery.NewAuthMiddleware(logger, sessionRepository)
+	authMiddleware := authDelivery.NewAuthMiddleware(logger, sessionRepository, accessTokenUseCase)
+	scopeMiddleware := authDelivery.NewScopeMiddleware(accessTokenScopes)
+	adminMiddleware := authDelivery.NewAdminMiddleware(logger, authUseCase)
 	csrfMiddleware := csrfDelivery.NewCSRFMiddleware(logger, csrfUseCase)
+	organizationMiddleware := organizationDelivery.NewOrganizationMiddleware(logger, organizationUseCase)
 	panicMiddleware := middleware.NewPanicMiddleware(logger)
 	loggingMiddleware := middleware.NewLoggingMiddleware(logger)
+	clientIPResolver, err := middleware.NewClientIPResolver(cfg.Server.TrustedProxies)
+	if err != nil {
+		logger.Error("invalid trusted proxies", "error", err)
+		os.Exit(1)
+	}
+	metricsMiddleware := middleware.NewMetricsMiddleware(metricsRegistry)
+
+	apiDoc, err := openapi.Load(context.Background())
+	if err != nil {
+		logger.Error("failed to load openapi document", "error", err)
+		os.Exit(1)
+	}
+	openAPIHandler, err := openapi.Handler(apiDoc)
+	if err != nil {
+		logger.Error("failed to serve openapi document", "error", err)
+		os.Exit(1)
+	}
+
+	var openAPIMiddleware *middleware.OpenAPIMiddleware
+	if cfg.OpenAPI.ValidateRequests || cfg.OpenAPI.ValidateResponses {
+		openAPIMiddleware, err = middleware.NewOpenAPIMiddleware(logger, apiDoc, cfg.OpenAPI.ValidateResponses)
+		if err != nil {
+			logger.Error("failed to create openapi validator", "error", err)
+			os.Exit(1)
+		}
+		logger.Info("OpenAPI validation enabled", "responses", cfg.OpenAPI.ValidateResponses)
+	}
 
 	var corsMiddleware *cors.Cors
 	if cfg.Server.CORSEnabled {
 		corsMiddleware = cors.New(cors.Options{
 			AllowedOrigins:   []string{cfg.Server.FrontendURL},
-			AllowedMethods:   []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions},
+			AllowedMethods:   []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions},
 			AllowCredentials: true,
+			ExposedHeaders:   []string{httptools.RequestIDHeader},
 		})
 		logger.Info("CORS enabled", "frontend_url", cfg.Server.FrontendURL)
 	} else {
@@ -89,15 +265,26 @@ func main() {
 	}
 
 	router := SetupRoutes(RoutesConfig{
-		AuthHandler:     authHandler,
-		ProfileHandler:  profileHandler,
-		AuthMiddleware:  authMiddleware,
-		CSRFMiddleware:  csrfMiddleware,
-		PanicMiddleware: panicMiddleware,
-		CORSMiddleware:  corsMiddleware,
+		AuthHandler:            authHandler,
+		ProfileHandler:         profileHandler,
+		AuditHandler:           auditHandler,
+		ProfileFieldHandler:    profileFieldHandler,
+		OrganizationHandler:    organizationHandler,
+		AccessTokenHandler:     accessTokenHandler,
+		AuthMiddleware:         authMiddleware,
+		ScopeMiddleware:        scopeMiddleware,
+		AdminMiddleware:        adminMiddleware,
+		CSRFMiddleware:         csrfMiddleware,
+		OrganizationMiddleware: organizationMiddleware,
+		PanicMiddleware:        panicMiddleware,
+		MetricsMiddleware:      metricsMiddleware,
+		CORSMiddleware:         corsMiddleware,
+		HealthChecker:          healthChecker,
+		OpenAPIHandler:         openAPIHandler,
+		OpenAPIMiddleware:      openAPIMiddleware,
 	})
 
-	handler := loggingMiddleware.AccessLog(router)
+	handler := middleware.RequestIDMiddleware(clientIPResolver.ClientInfoMiddleware(loggingMiddleware.AccessLog(router)))
 
 	server := &http.Server{
 		Addr:         cfg.Server.Addr,
@@ -107,6 +294,24 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	var metricsServer *http.Server
+	if cfg.Metrics.Enabled {
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle("/metrics", metricsRegistry.Handler())
+		metricsServer = &http.Server{
+			Addr:         cfg.Metrics.Addr,
+			Handler:      metricsMux,
+			ReadTimeout:  5 * time.Second,
+			WriteTimeout: 10 * time.Second,
+		}
+	}
+
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go job.RunPeriodically(jobsCtx, logger, "purge_deleted_accounts", cfg.Account.DeletionPurgeInterval, profileUseCase.PurgeScheduledDeletions)
+	go job.RunPeriodically(jobsCtx, logger, "process_data_exports", cfg.Account.ExportProcessInterval, profileUseCase.ProcessPendingExports)
+	go job.RunPeriodically(jobsCtx, logger, "purge_refresh_tokens", cfg.JWT.PurgeInterval, authUseCase.PurgeExpiredRefreshTokens)
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 	go func() {
@@ -116,9 +321,24 @@ func main() {
 			os.Exit(1)
 		}
 	}()
+	if metricsServer != nil {
+		go func() {
+			logger.Info("starting metrics server", "addr", cfg.Metrics.Addr)
+			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Error("metrics server failed", "error", err)
+				os.Exit(1)
+			}
+		}()
+	}
 
 	<-quit
 	logger.Info("shutting down server...")
+	healthChecker.ShutDown()
+	stopJobs()
+	if cfg.Health.ShutdownDelay > 0 {
+		logger.Info("waiting before closing the listener", "delay", cfg.Health.ShutdownDelay)
+		time.Sleep(cfg.Health.ShutdownDelay)
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -127,10 +347,80 @@ func main() {
 		logger.Error("server forced to shutdown", "error", err)
 		os.Exit(1)
 	}
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(ctx); err != nil {
+			logger.Error("metrics server forced to shutdown", "error", err)
+		}
+	}
 
 	if err := db.Close(); err != nil {
 		logger.Error("failed to close database", "error", err)
 	}
 
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Error("failed to flush traces", "error", err)
+	}
+
 	logger.Info("server exited gracefully")
 }
+
+func newSMSSender(provider string, logger *slog.Logger) (profileUC.SMSSender, error) {
+	switch provider {
+	case "log":
+		return smsGateway.NewLogSender(logger), nil
+	case "fake":
+		return smsGateway.NewFakeSender(), nil
+	default:
+		return nil, fmt.Errorf("unknown sms provider %q", provider)
+	}
+}
+
+func newSignUpPolicy(cfg config.SignUpConfig) (domain.SignUpPolicy, error) {
+	policy := domain.SignUpPolicy{Mode: domain.SignUpMode(cfg.Mode)}
+	if !policy.Mode.IsValid() {
+		return policy, fmt.Errorf("unknown sign-up mode %q", cfg.Mode)
+	}
+	for _, d := range cfg.AllowedDomains {
+		d = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "@"))
+		if d != "" {
+			policy.AllowedDomains = append(policy.AllowedDomains, d)
+		}
+	}
+	if policy.Mode == domain.SignUpModeAllowedDomains && len(policy.AllowedDomains) == 0 {
+		return policy, fmt.Errorf("sign-up mode %q needs at least one allowed domain", cfg.Mode)
+	}
+	return policy, nil
+}
+
+// newJWTSigner loads the configured JWT keys. It returns nil when there are
+// none, which leaves the JWT issuer off.
+func newJWTSigner(cfg config.JWTConfig) (*jwt.Signer, error) {
+	if len(cfg.Keys) == 0 {
+		return nil, nil
+	}
+	keys := make([]*jwt.Key, 0, len(cfg.Keys))
+	for _, keyCfg := range cfg.Keys {
+		key, err := jwt.LoadKey(keyCfg.ID, keyCfg.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	signingKey := cfg.SigningKey
+	if signingKey == "" && len(keys) == 1 {
+		signingKey = keys[0].ID
+	}
+	return jwt.NewSigner(keys, signingKey)
+}
+
+func checkGoogleOAuthConfig(cfg config.GoogleOAuthConfig) error {
+	switch {
+	case cfg.ClientID == "":
+		return errors.New("google oauth client id is not configured")
+	case cfg.ClientSecret == "":
+		return errors.New("google oauth client secret is not configured")
+	case cfg.RedirectURL == "":
+		return errors.New("google oauth redirect url is not configured")
+	}
+	return nil
+}
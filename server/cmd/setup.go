@@ -4,44 +4,104 @@ import (
 	"io"
 	"net/http"
 
+	accessTokenDelivery "server/internal/delivery/accesstoken"
+	auditDelivery "server/internal/delivery/audit"
 	authDelivery "server/internal/delivery/auth"
 	csrfDelivery "server/internal/delivery/csrf"
+	organizationDelivery "server/internal/delivery/organization"
 	profileDelivery "server/internal/delivery/profile"
+	profileFieldDelivery "server/internal/delivery/profilefield"
+	"server/internal/domain"
+	"server/internal/pkg/health"
+	"server/internal/pkg/httptools"
 	middleware "server/internal/pkg/middleware"
+	"server/internal/pkg/openapi"
 
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
 )
 
 type RoutesConfig struct {
-	AuthHandler     *authDelivery.Handler
-	ProfileHandler  *profileDelivery.Handler
-	AuthMiddleware  *authDelivery.AuthMiddleware
-	CSRFMiddleware  *csrfDelivery.CSRFMiddleware
-	PanicMiddleware *middleware.PanicMiddleware
-	CORSMiddleware  *cors.Cors
+	AuthHandler            *authDelivery.Handler
+	ProfileHandler         *profileDelivery.Handler
+	AuditHandler           *auditDelivery.Handler
+	ProfileFieldHandler    *profileFieldDelivery.Handler
+	OrganizationHandler    *organizationDelivery.Handler
+	AccessTokenHandler     *accessTokenDelivery.Handler
+	AuthMiddleware         *authDelivery.AuthMiddleware
+	ScopeMiddleware        *authDelivery.ScopeMiddleware
+	AdminMiddleware        *authDelivery.AdminMiddleware
+	CSRFMiddleware         *csrfDelivery.CSRFMiddleware
+	OrganizationMiddleware *organizationDelivery.OrganizationMiddleware
+	PanicMiddleware        *middleware.PanicMiddleware
+	MetricsMiddleware      *middleware.MetricsMiddleware
+	CORSMiddleware         *cors.Cors
+	HealthChecker          *health.Checker
+	OpenAPIHandler         http.HandlerFunc
+	// OpenAPIMiddleware is nil when validation is turned off.
+	OpenAPIMiddleware *middleware.OpenAPIMiddleware
+}
+
+// accessTokenScopes lists the routes a personal access token may call, keyed
+// by method and path template, with the scope each needs. Tokens are turned
+// away from every other route, including the token management ones and the
+// email change, so a leaked token cannot be used to mint more, to move the
+// account to another address or to delete it.
+var accessTokenScopes = map[string]domain.TokenScope{
+	"GET /api/profile":             domain.ScopeProfileRead,
+	"GET /api/profile/fields":      domain.ScopeProfileRead,
+	"GET /api/profile/history":     domain.ScopeProfileRead,
+	"GET /api/profile/export":      domain.ScopeProfileRead,
+	"GET /api/profile/export/{id}": domain.ScopeProfileRead,
+	"GET /api/auth/activity":       domain.ScopeProfileRead,
+
+	"PUT /api/profile":                             domain.ScopeProfileWrite,
+	"PATCH /api/profile":                           domain.ScopeProfileWrite,
+	"POST /api/profile/phone/verification":         domain.ScopeProfileWrite,
+	"POST /api/profile/phone/verification/confirm": domain.ScopeProfileWrite,
+	"POST /api/profile/export":                     domain.ScopeProfileWrite,
+	"PUT /api/profile/avatar":                      domain.ScopeProfileWrite,
+
+	"GET /api/users":             domain.ScopeUsersRead,
+	"GET /api/users/{handle}":    domain.ScopeUsersRead,
+	"GET /api/users/{id}/avatar": domain.ScopeUsersRead,
+
+	"GET /api/organizations":                         domain.ScopeOrganizationsRead,
+	"GET /api/organizations/{id:[0-9]+}/members":     domain.ScopeOrganizationsRead,
+	"GET /api/organizations/{id:[0-9]+}/invitations": domain.ScopeOrganizationsRead,
+
+	"POST /api/organizations":                                       domain.ScopeOrganizationsWrite,
+	"PUT /api/organizations/{id:[0-9]+}/members/{userID:[0-9]+}":    domain.ScopeOrganizationsWrite,
+	"DELETE /api/organizations/{id:[0-9]+}/members/{userID:[0-9]+}": domain.ScopeOrganizationsWrite,
+	"POST /api/organizations/{id:[0-9]+}/invitations":               domain.ScopeOrganizationsWrite,
 }
 
 func SetupRoutes(config RoutesConfig) *mux.Router {
 	router := mux.NewRouter()
-	router.NotFoundHandler = http.HandlerFunc(NotFound)
-	router.Use(config.PanicMiddleware.PanicMiddleware)
+	router.NotFoundHandler = middleware.TracingMiddleware(config.MetricsMiddleware.Instrument(http.HandlerFunc(NotFound)))
+	router.Use(middleware.TracingMiddleware, config.MetricsMiddleware.Instrument, config.PanicMiddleware.PanicMiddleware)
+	if config.OpenAPIMiddleware != nil {
+		router.Use(config.OpenAPIMiddleware.Validate)
+	}
 
 	var corsRouter *mux.Router
 	if config.CORSMiddleware != nil {
 		corsRouter = router.Methods(http.MethodGet, http.MethodPost,
-			http.MethodPut, http.MethodDelete, http.MethodOptions).Subrouter()
+			http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions).Subrouter()
 		corsRouter.Use(config.CORSMiddleware.Handler)
 	} else {
 		corsRouter = router
 	}
 
 	authRouter := corsRouter.Methods(http.MethodGet, http.MethodPost,
-		http.MethodPut, http.MethodDelete, http.MethodOptions).Subrouter()
-	authRouter.Use(config.AuthMiddleware.RequireAuth)
+		http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions).Subrouter()
+	authRouter.Use(config.AuthMiddleware.RequireAuth, config.ScopeMiddleware.RequireScope, config.OrganizationMiddleware.LoadOrganization)
+
+	optionalAuthRouter := corsRouter.Methods(http.MethodGet).Subrouter()
+	optionalAuthRouter.Use(config.AuthMiddleware.OptionalAuth, config.ScopeMiddleware.RequireScope)
 
 	unAuthRouter := corsRouter.Methods(http.MethodGet, http.MethodPost,
-		http.MethodPut, http.MethodDelete, http.MethodOptions).Subrouter()
+		http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions).Subrouter()
 	unAuthRouter.Use(config.AuthMiddleware.RequireUnAuth, config.CSRFMiddleware.RequireCSRFToken, config.CSRFMiddleware.SetCSRFToken)
 
 	unAuthRouter.HandleFunc("/api/auth/signup", config.AuthHandler.SignUpWithEmail).Methods(http.MethodPost)
@@ -55,10 +115,63 @@ func SetupRoutes(config RoutesConfig) *mux.Router {
 	}
 
 	router.HandleFunc("/ping", Ping).Methods(http.MethodGet)
+	router.HandleFunc("/healthz", config.HealthChecker.Liveness).Methods(http.MethodGet)
+	router.HandleFunc("/readyz", config.HealthChecker.Readiness).Methods(http.MethodGet)
+	router.HandleFunc(openapi.Path, config.OpenAPIHandler).Methods(http.MethodGet)
+	router.HandleFunc("/api/auth/signup/policy", config.AuthHandler.GetSignUpPolicy).Methods(http.MethodGet)
+	corsRouter.HandleFunc("/api/auth/token", config.AuthHandler.IssueToken).Methods(http.MethodPost)
+	corsRouter.HandleFunc("/api/auth/jwt", config.AuthHandler.IssueJWT).Methods(http.MethodPost)
+	corsRouter.HandleFunc("/api/auth/jwt/revoke", config.AuthHandler.RevokeJWT).Methods(http.MethodPost)
+	router.HandleFunc("/.well-known/jwks.json", config.AuthHandler.JWKS).Methods(http.MethodGet)
+	optionalAuthRouter.HandleFunc("/api/users/{handle}", config.ProfileHandler.GetPublicProfile).Methods(http.MethodGet)
+	optionalAuthRouter.HandleFunc("/api/users/{id}/avatar", config.ProfileHandler.GetUserAvatar).Methods(http.MethodGet)
+	router.HandleFunc("/api/profile/email/confirm", config.ProfileHandler.ConfirmEmailChange).Methods(http.MethodGet)
+	router.HandleFunc("/api/profile/email/cancel", config.ProfileHandler.CancelEmailChange).Methods(http.MethodGet)
 
 	authRouter.Handle("/api/auth/logout", config.CSRFMiddleware.SetCSRFToken(http.HandlerFunc(config.AuthHandler.LogOut))).Methods(http.MethodPost)
 	authRouter.HandleFunc("/api/profile", config.ProfileHandler.GetProfile).Methods(http.MethodGet)
 	authRouter.Handle("/api/profile", config.CSRFMiddleware.RequireCSRFToken(http.HandlerFunc(config.ProfileHandler.UpdateProfile))).Methods(http.MethodPut)
+	authRouter.Handle("/api/profile", config.CSRFMiddleware.RequireCSRFToken(http.HandlerFunc(config.ProfileHandler.PatchProfile))).Methods(http.MethodPatch)
+	authRouter.Handle("/api/profile", config.CSRFMiddleware.RequireCSRFToken(http.HandlerFunc(config.ProfileHandler.DeleteAccount))).Methods(http.MethodDelete)
+	authRouter.Handle("/api/profile/email", config.CSRFMiddleware.RequireCSRFToken(http.HandlerFunc(config.ProfileHandler.RequestEmailChange))).Methods(http.MethodPost)
+	authRouter.Handle("/api/profile/phone/verification", config.CSRFMiddleware.RequireCSRFToken(http.HandlerFunc(config.ProfileHandler.RequestPhoneVerification))).Methods(http.MethodPost)
+	authRouter.Handle("/api/profile/phone/verification/confirm", config.CSRFMiddleware.RequireCSRFToken(http.HandlerFunc(config.ProfileHandler.ConfirmPhoneVerification))).Methods(http.MethodPost)
+	authRouter.Handle("/api/profile/export", config.CSRFMiddleware.RequireCSRFToken(http.HandlerFunc(config.ProfileHandler.RequestDataExport))).Methods(http.MethodPost)
+	authRouter.HandleFunc("/api/profile/fields", config.ProfileFieldHandler.GetFields).Methods(http.MethodGet)
+	authRouter.HandleFunc("/api/profile/history", config.ProfileHandler.GetProfileHistory).Methods(http.MethodGet)
+	authRouter.HandleFunc("/api/profile/export", config.ProfileHandler.GetLatestDataExport).Methods(http.MethodGet)
+	authRouter.HandleFunc("/api/profile/export/{id}", config.ProfileHandler.DownloadDataExport).Methods(http.MethodGet)
+	authRouter.Handle("/api/profile/avatar", config.CSRFMiddleware.RequireCSRFToken(http.HandlerFunc(config.ProfileHandler.UploadAvatar))).Methods(http.MethodPut)
+	authRouter.HandleFunc("/api/profile/tokens", config.AccessTokenHandler.ListTokens).Methods(http.MethodGet)
+	authRouter.Handle("/api/profile/tokens", config.CSRFMiddleware.RequireCSRFToken(http.HandlerFunc(config.AccessTokenHandler.CreateToken))).Methods(http.MethodPost)
+	authRouter.Handle("/api/profile/tokens/{id:[0-9]+}", config.CSRFMiddleware.RequireCSRFToken(http.HandlerFunc(config.AccessTokenHandler.DeleteToken))).Methods(http.MethodDelete)
+	authRouter.HandleFunc("/api/auth/activity", config.AuditHandler.GetActivity).Methods(http.MethodGet)
+	authRouter.HandleFunc("/api/users", config.ProfileHandler.SearchDirectory).Methods(http.MethodGet)
+	authRouter.HandleFunc("/api/organizations", config.OrganizationHandler.ListOrganizations).Methods(http.MethodGet)
+	authRouter.Handle("/api/organizations", config.CSRFMiddleware.RequireCSRFToken(http.HandlerFunc(config.OrganizationHandler.CreateOrganization))).Methods(http.MethodPost)
+	authRouter.HandleFunc("/api/organizations/current", config.OrganizationHandler.GetCurrentOrganization).Methods(http.MethodGet)
+	authRouter.Handle("/api/organizations/current", config.CSRFMiddleware.RequireCSRFToken(http.HandlerFunc(config.OrganizationHandler.SelectOrganization))).Methods(http.MethodPut)
+	authRouter.HandleFunc("/api/organizations/invitation", config.OrganizationHandler.GetInvitation).Methods(http.MethodGet)
+	authRouter.Handle("/api/organizations/invitation/accept", config.CSRFMiddleware.RequireCSRFToken(http.HandlerFunc(config.OrganizationHandler.AcceptInvitation))).Methods(http.MethodPost)
+	authRouter.HandleFunc("/api/organizations/{id:[0-9]+}/members", config.OrganizationHandler.ListMembers).Methods(http.MethodGet)
+	authRouter.Handle("/api/organizations/{id:[0-9]+}/members/{userID:[0-9]+}", config.CSRFMiddleware.RequireCSRFToken(http.HandlerFunc(config.OrganizationHandler.ChangeMemberRole))).Methods(http.MethodPut)
+	authRouter.Handle("/api/organizations/{id:[0-9]+}/members/{userID:[0-9]+}", config.CSRFMiddleware.RequireCSRFToken(http.HandlerFunc(config.OrganizationHandler.RemoveMember))).Methods(http.MethodDelete)
+	authRouter.HandleFunc("/api/organizations/{id:[0-9]+}/invitations", config.OrganizationHandler.ListInvitations).Methods(http.MethodGet)
+	authRouter.Handle("/api/organizations/{id:[0-9]+}/invitations", config.CSRFMiddleware.RequireCSRFToken(http.HandlerFunc(config.OrganizationHandler.InviteMember))).Methods(http.MethodPost)
+
+	adminRouter := authRouter.PathPrefix("/api/admin").Subrouter()
+	adminRouter.Use(config.AdminMiddleware.RequireAdmin)
+
+	adminRouter.HandleFunc("/audit", config.AuditHandler.QueryEvents).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/audit/verify", config.AuditHandler.VerifyChain).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/users/{id}/profile/history", config.ProfileHandler.GetUserProfileHistory).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/invite-codes", config.AuthHandler.ListInviteCodes).Methods(http.MethodGet)
+	adminRouter.Handle("/invite-codes", config.CSRFMiddleware.RequireCSRFToken(http.HandlerFunc(config.AuthHandler.CreateInviteCode))).Methods(http.MethodPost)
+	adminRouter.Handle("/invite-codes/{id:[0-9]+}", config.CSRFMiddleware.RequireCSRFToken(http.HandlerFunc(config.AuthHandler.DeleteInviteCode))).Methods(http.MethodDelete)
+	adminRouter.HandleFunc("/profile-fields", config.ProfileFieldHandler.ListFields).Methods(http.MethodGet)
+	adminRouter.Handle("/profile-fields", config.CSRFMiddleware.RequireCSRFToken(http.HandlerFunc(config.ProfileFieldHandler.CreateField))).Methods(http.MethodPost)
+	adminRouter.Handle("/profile-fields/{key}", config.CSRFMiddleware.RequireCSRFToken(http.HandlerFunc(config.ProfileFieldHandler.UpdateField))).Methods(http.MethodPut)
+	adminRouter.Handle("/profile-fields/{key}", config.CSRFMiddleware.RequireCSRFToken(http.HandlerFunc(config.ProfileFieldHandler.DeleteField))).Methods(http.MethodDelete)
 
 	unCorsedUnAuthRouter := router.Methods(http.MethodGet, http.MethodPost).Subrouter()
 	unCorsedUnAuthRouter.Use(config.CSRFMiddleware.SetCSRFToken, config.AuthMiddleware.RequireUnAuth)
@@ -70,9 +183,7 @@ func SetupRoutes(config RoutesConfig) *mux.Router {
 }
 
 func NotFound(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusNotFound)
-	w.Header().Set("Content-Type", "application/json")
-	io.WriteString(w, `{"message": "not found"}`)
+	httptools.WriteJSONError(w, http.StatusNotFound, "not found")
 }
 
 func Ping(w http.ResponseWriter, _ *http.Request) {
@@ -1,5 +1,19 @@
-drop table if exists user;
+drop table if exists refresh_token;
+drop table if exists personal_access_token;
+drop table if exists signup_invite;
+drop table if exists organization_invitation;
+drop table if exists organization_member;
+drop table if exists organization;
+drop table if exists profile_change;
+drop table if exists profile_field_value;
+drop table if exists profile_field;
+drop table if exists phone_verification;
+drop table if exists email_change_request;
+drop table if exists data_export;
+drop table if exists audit_event;
+drop table if exists audit_chain_head;
 drop table if exists oauth_account;
+drop table if exists user;
 
 create table user (
     id bigint AUTO_INCREMENT PRIMARY KEY,
@@ -7,8 +21,28 @@ create table user (
     password_hash varchar(255) DEFAULT NULL,
     full_name varchar(255) DEFAULT NULL,
     phone varchar(255) DEFAULT NULL,
+    phone_verified_at datetime DEFAULT NULL,
+    avatar_id char(36) DEFAULT NULL,
+    is_admin boolean not null default false,
+    deletion_scheduled_at datetime DEFAULT NULL,
+    -- handle names the public profile at /u/{handle}; the visibility
+    -- columns hold 'public', 'signed_in' or 'private'.
+    handle varchar(32) DEFAULT NULL UNIQUE,
+    public_profile boolean not null default false,
+    full_name_visibility varchar(16) not null default 'public',
+    email_visibility varchar(16) not null default 'private',
+    phone_visibility varchar(16) not null default 'private',
+    avatar_visibility varchar(16) not null default 'public',
+    -- version is bumped on every profile change and exposed as the ETag.
+    version int unsigned NOT NULL DEFAULT 1,
     created_at timestamp not null default current_timestamp,
-    updated_at timestamp not null default current_timestamp on update current_timestamp
+    updated_at timestamp not null default current_timestamp on update current_timestamp,
+    key (deletion_scheduled_at),
+    -- The directory search matches substrings, which the ngram parser
+    -- indexes. Name and email have their own index so each match can
+    -- respect that field's visibility.
+    fulltext key directory_full_name (full_name) with parser ngram,
+    fulltext key directory_email (email) with parser ngram
 );
 
 create table oauth_account (
@@ -21,3 +55,186 @@ create table oauth_account (
     foreign key (user_id) references user(id) on delete cascade,
     unique key (provider_name, sub)
 );
+
+-- profile_field holds the custom profile attributes defined by
+-- administrators; rules is the JSON-encoded validation rules.
+create table profile_field (
+    id bigint AUTO_INCREMENT PRIMARY KEY,
+    field_key varchar(64) NOT NULL UNIQUE,
+    label varchar(255) NOT NULL,
+    field_type varchar(16) NOT NULL,
+    rules text NOT NULL,
+    required boolean NOT NULL DEFAULT false,
+    visibility varchar(16) NOT NULL,
+    position int NOT NULL DEFAULT 0,
+    created_at timestamp not null default current_timestamp,
+    updated_at timestamp not null default current_timestamp on update current_timestamp
+);
+
+create table profile_field_value (
+    user_id bigint NOT NULL,
+    field_id bigint NOT NULL,
+    value text NOT NULL,
+    PRIMARY KEY (user_id, field_id),
+    foreign key (user_id) references user(id) on delete cascade,
+    foreign key (field_id) references profile_field(id) on delete cascade
+);
+
+-- profile_change keeps the history of profile updates, one row per changed
+-- field; session_id is derived from the session token, never the token.
+create table profile_change (
+    id bigint AUTO_INCREMENT PRIMARY KEY,
+    user_id bigint NOT NULL,
+    field varchar(128) NOT NULL,
+    old_value text DEFAULT NULL,
+    new_value text DEFAULT NULL,
+    session_id char(16) DEFAULT NULL,
+    created_at datetime(6) NOT NULL,
+    foreign key (user_id) references user(id) on delete cascade,
+    key (user_id, id)
+);
+
+create table phone_verification (
+    user_id bigint PRIMARY KEY,
+    phone varchar(16) NOT NULL,
+    code_hash char(64) NOT NULL,
+    attempts int NOT NULL DEFAULT 0,
+    created_at datetime NOT NULL,
+    expires_at datetime NOT NULL,
+    foreign key (user_id) references user(id) on delete cascade
+);
+
+create table email_change_request (
+    id bigint AUTO_INCREMENT PRIMARY KEY,
+    user_id bigint NOT NULL,
+    old_email varchar(255) NOT NULL,
+    new_email varchar(255) NOT NULL,
+    confirm_token_hash char(64) NOT NULL,
+    cancel_token_hash char(64) NOT NULL,
+    created_at datetime NOT NULL,
+    expires_at datetime NOT NULL,
+    foreign key (user_id) references user(id) on delete cascade,
+    unique key (user_id),
+    unique key (confirm_token_hash),
+    unique key (cancel_token_hash)
+);
+
+create table data_export (
+    id char(36) PRIMARY KEY,
+    user_id bigint NOT NULL,
+    status varchar(16) NOT NULL,
+    archive longblob DEFAULT NULL,
+    created_at datetime NOT NULL,
+    completed_at datetime DEFAULT NULL,
+    expires_at datetime DEFAULT NULL,
+    foreign key (user_id) references user(id) on delete cascade,
+    key (user_id, created_at),
+    key (status, created_at),
+    key (expires_at)
+);
+
+create table audit_event (
+    id bigint AUTO_INCREMENT PRIMARY KEY,
+    actor_user_id bigint DEFAULT NULL,
+    subject_user_id bigint DEFAULT NULL,
+    event_type varchar(64) NOT NULL,
+    ip varchar(255) NOT NULL,
+    user_agent varchar(512) NOT NULL,
+    outcome varchar(16) NOT NULL,
+    details text NOT NULL,
+    created_at datetime(6) NOT NULL,
+    prev_hash char(64) NOT NULL,
+    hash char(64) NOT NULL,
+    key (actor_user_id),
+    key (subject_user_id),
+    key (event_type, created_at)
+);
+
+create table audit_chain_head (
+    id tinyint PRIMARY KEY,
+    last_hash char(64) NOT NULL
+);
+
+insert into audit_chain_head (id, last_hash) values (1, '');
+
+create table organization (
+    id bigint AUTO_INCREMENT PRIMARY KEY,
+    name varchar(255) NOT NULL,
+    created_by bigint DEFAULT NULL,
+    created_at datetime NOT NULL,
+    foreign key (created_by) references user(id) on delete set null
+);
+
+-- role is 'owner', 'admin' or 'member'; every organization keeps at least
+-- one owner.
+create table organization_member (
+    organization_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    role varchar(16) NOT NULL,
+    created_at datetime NOT NULL,
+    PRIMARY KEY (organization_id, user_id),
+    foreign key (organization_id) references organization(id) on delete cascade,
+    foreign key (user_id) references user(id) on delete cascade,
+    key (user_id)
+);
+
+-- Inviting an address again replaces its pending invitation.
+create table organization_invitation (
+    id bigint AUTO_INCREMENT PRIMARY KEY,
+    organization_id bigint NOT NULL,
+    email varchar(255) NOT NULL,
+    role varchar(16) NOT NULL,
+    token_hash char(64) NOT NULL UNIQUE,
+    invited_by bigint DEFAULT NULL,
+    created_at datetime NOT NULL,
+    expires_at datetime NOT NULL,
+    foreign key (organization_id) references organization(id) on delete cascade,
+    foreign key (invited_by) references user(id) on delete set null,
+    unique key (organization_id, email)
+);
+
+-- Invite codes for invite-only sign-up. A code is redeemed by setting
+-- used_at, so it cannot be used twice.
+create table signup_invite (
+    id bigint AUTO_INCREMENT PRIMARY KEY,
+    code_hash char(64) NOT NULL UNIQUE,
+    note varchar(255) NOT NULL DEFAULT '',
+    created_by bigint DEFAULT NULL,
+    created_at datetime NOT NULL,
+    expires_at datetime NOT NULL,
+    used_email varchar(255) DEFAULT NULL,
+    used_at datetime DEFAULT NULL,
+    foreign key (created_by) references user(id) on delete set null
+);
+
+-- Personal access tokens for scripts. Scopes are stored space-separated;
+-- expires_at is NULL for tokens that do not expire.
+create table personal_access_token (
+    id bigint AUTO_INCREMENT PRIMARY KEY,
+    user_id bigint NOT NULL,
+    name varchar(100) NOT NULL,
+    scopes varchar(255) NOT NULL,
+    token_hash char(64) NOT NULL UNIQUE,
+    created_at datetime NOT NULL,
+    expires_at datetime DEFAULT NULL,
+    last_used_at datetime DEFAULT NULL,
+    foreign key (user_id) references user(id) on delete cascade
+);
+
+-- Refresh tokens for JWT access tokens. Each refresh marks the token used
+-- and issues the next one with the same family_id; a used token presented
+-- again revokes the family.
+create table refresh_token (
+    id bigint AUTO_INCREMENT PRIMARY KEY,
+    family_id char(36) NOT NULL,
+    user_id bigint NOT NULL,
+    token_hash char(64) NOT NULL UNIQUE,
+    auth_method varchar(32) NOT NULL,
+    created_at datetime NOT NULL,
+    expires_at datetime NOT NULL,
+    used_at datetime DEFAULT NULL,
+    revoked_at datetime DEFAULT NULL,
+    foreign key (user_id) references user(id) on delete cascade,
+    key (family_id),
+    key (expires_at)
+);
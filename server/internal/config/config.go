@@ -3,15 +3,30 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	Server   ServerConfig   `yaml:"server"`
-	Database DatabaseConfig `yaml:"database"`
-	OAuth    OAuthConfig    `yaml:"oauth"`
+	Server       ServerConfig       `yaml:"server"`
+	Database     DatabaseConfig     `yaml:"database"`
+	OAuth        OAuthConfig        `yaml:"oauth"`
+	Auth         AuthConfig         `yaml:"auth"`
+	JWT          JWTConfig          `yaml:"jwt"`
+	Account      AccountConfig      `yaml:"account"`
+	Phone        PhoneConfig        `yaml:"phone"`
+	Avatar       AvatarConfig       `yaml:"avatar"`
+	Storage      StorageConfig      `yaml:"storage"`
+	Directory    DirectoryConfig    `yaml:"directory"`
+	Organization OrganizationConfig `yaml:"organization"`
+	SignUp       SignUpConfig       `yaml:"signup"`
+	Metrics      MetricsConfig      `yaml:"metrics"`
+	Tracing      TracingConfig      `yaml:"tracing"`
+	Health       HealthConfig       `yaml:"health"`
+	OpenAPI      OpenAPIConfig      `yaml:"openapi"`
 }
 
 type ServerConfig struct {
@@ -19,6 +34,9 @@ type ServerConfig struct {
 	FrontendURL string `yaml:"frontend_url"`
 	FullAddress string `yaml:"full_address"`
 	CORSEnabled bool   `yaml:"cors_enabled"`
+	// TrustedProxies are the addresses or CIDR ranges of reverse proxies
+	// whose X-Forwarded-For and X-Real-IP headers are believed.
+	TrustedProxies []string `yaml:"trusted_proxies"`
 }
 
 type DatabaseConfig struct {
@@ -39,6 +57,117 @@ type GoogleOAuthConfig struct {
 	ClientSecret string `yaml:"client_secret"`
 }
 
+// AuthConfig controls the tokens issued to API clients at /api/auth/token.
+type AuthConfig struct {
+	BearerTokenTTL time.Duration `yaml:"bearer_token_ttl"`
+}
+
+// JWTConfig controls the signed access tokens issued at /api/auth/jwt; the
+// issuer stays off until Keys lists a key. To rotate keys, add the new one,
+// point SigningKey at it, and remove the old one once the access tokens it
+// signed have expired.
+type JWTConfig struct {
+	// Issuer is the iss claim; it defaults to Server.FullAddress.
+	Issuer          string        `yaml:"issuer"`
+	Audience        []string      `yaml:"audience"`
+	AccessTokenTTL  time.Duration `yaml:"access_token_ttl"`
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl"`
+	// SigningKey is the id of the key new tokens are signed with. It may be
+	// left empty while there is a single key.
+	SigningKey string         `yaml:"signing_key"`
+	Keys       []JWTKeyConfig `yaml:"keys"`
+	// IncludeEmail adds the email claim; Claims are added to every token.
+	IncludeEmail  bool           `yaml:"include_email"`
+	Claims        map[string]any `yaml:"claims"`
+	PurgeInterval time.Duration  `yaml:"purge_interval"`
+}
+
+// JWTKeyConfig is a PEM encoded Ed25519 or RSA private key. Its ID is
+// published as the kid of the public key.
+type JWTKeyConfig struct {
+	ID             string `yaml:"id"`
+	PrivateKeyFile string `yaml:"private_key_file"`
+}
+
+type AccountConfig struct {
+	DeletionGracePeriod   time.Duration `yaml:"deletion_grace_period"`
+	DeletionPurgeInterval time.Duration `yaml:"deletion_purge_interval"`
+	ExportTTL             time.Duration `yaml:"export_ttl"`
+	ExportProcessInterval time.Duration `yaml:"export_process_interval"`
+	EmailChangeTTL        time.Duration `yaml:"email_change_ttl"`
+}
+
+type PhoneConfig struct {
+	DefaultRegion      string        `yaml:"default_region"`
+	CodeTTL            time.Duration `yaml:"code_ttl"`
+	CodeResendInterval time.Duration `yaml:"code_resend_interval"`
+	CodeMaxAttempts    int           `yaml:"code_max_attempts"`
+	// SMSProvider selects the SMSSender: "log" or "fake".
+	SMSProvider string `yaml:"sms_provider"`
+}
+
+type AvatarConfig struct {
+	MaxUploadSize int64 `yaml:"max_upload_size"`
+}
+
+type StorageConfig struct {
+	// BlobDir is where the local blob store keeps uploaded files.
+	BlobDir string `yaml:"blob_dir"`
+}
+
+// DirectoryConfig rate limits the user directory search per user.
+type DirectoryConfig struct {
+	SearchLimit  int           `yaml:"search_limit"`
+	SearchPeriod time.Duration `yaml:"search_period"`
+}
+
+// OrganizationConfig controls organization invitations.
+type OrganizationConfig struct {
+	InvitationTTL time.Duration `yaml:"invitation_ttl"`
+}
+
+// SignUpConfig decides who may register. Mode is "open", "invite_only" or
+// "allowed_domains"; AllowedDomains is only used by the last.
+type SignUpConfig struct {
+	Mode           string        `yaml:"mode"`
+	AllowedDomains []string      `yaml:"allowed_domains"`
+	InviteCodeTTL  time.Duration `yaml:"invite_code_ttl"`
+}
+
+// MetricsConfig serves Prometheus metrics at /metrics on a listener of its
+// own, so they are not reachable through the public API address.
+type MetricsConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Addr    string `yaml:"addr"`
+}
+
+// TracingConfig exports OpenTelemetry traces over OTLP/HTTP to OTLPEndpoint.
+// Without an endpoint spans are written as JSON to File, or to stdout when
+// File is empty, so tracing also works without a collector.
+type TracingConfig struct {
+	Enabled      bool    `yaml:"enabled"`
+	ServiceName  string  `yaml:"service_name"`
+	OTLPEndpoint string  `yaml:"otlp_endpoint"`
+	File         string  `yaml:"file"`
+	SampleRatio  float64 `yaml:"sample_ratio"`
+}
+
+// HealthConfig bounds the /readyz dependency checks. ShutdownDelay keeps the
+// server serving with readiness failing for a while after a shutdown signal,
+// so load balancers can take it out of rotation first.
+type HealthConfig struct {
+	CheckTimeout  time.Duration `yaml:"check_timeout"`
+	ShutdownDelay time.Duration `yaml:"shutdown_delay"`
+}
+
+// OpenAPIConfig checks API traffic against the OpenAPI document.
+// ValidateResponses buffers every response and also turns on request
+// validation; it is meant for development.
+type OpenAPIConfig struct {
+	ValidateRequests  bool `yaml:"validate_requests"`
+	ValidateResponses bool `yaml:"validate_responses"`
+}
+
 func Load(configPath string, envPath string) (*Config, error) {
 	if envPath != "" {
 		if err := godotenv.Load(envPath); err != nil {
@@ -61,6 +190,7 @@ func Load(configPath string, envPath string) (*Config, error) {
 	}
 
 	applyEnvOverrides(&config)
+	applyDefaults(&config)
 
 	return &config, nil
 }
@@ -80,6 +210,10 @@ func applyEnvOverrides(config *Config) {
 		config.Server.FullAddress = val
 	}
 
+	if val := getEnvFirst("TRUSTED_PROXIES"); val != "" {
+		config.Server.TrustedProxies = strings.Split(val, ",")
+	}
+
 	if val := getEnvFirst("MYSQLHOST", "DATABASE_HOST"); val != "" {
 		config.Database.Host = val
 	}
@@ -115,6 +249,156 @@ func applyEnvOverrides(config *Config) {
 	if val := getEnvFirst("CORS_ENABLED", "ENABLE_CORS"); val != "" {
 		config.Server.CORSEnabled = val == "true" || val == "1" || val == "yes"
 	}
+
+	if val := getEnvDuration("ACCOUNT_DELETION_GRACE_PERIOD"); val > 0 {
+		config.Account.DeletionGracePeriod = val
+	}
+
+	if val := getEnvDuration("ACCOUNT_DELETION_PURGE_INTERVAL"); val > 0 {
+		config.Account.DeletionPurgeInterval = val
+	}
+
+	if val := getEnvDuration("ACCOUNT_EXPORT_TTL"); val > 0 {
+		config.Account.ExportTTL = val
+	}
+
+	if val := getEnvDuration("ACCOUNT_EXPORT_PROCESS_INTERVAL"); val > 0 {
+		config.Account.ExportProcessInterval = val
+	}
+
+	if val := getEnvDuration("ACCOUNT_EMAIL_CHANGE_TTL"); val > 0 {
+		config.Account.EmailChangeTTL = val
+	}
+
+	if val := os.Getenv("PHONE_DEFAULT_REGION"); val != "" {
+		config.Phone.DefaultRegion = val
+	}
+
+	if val := os.Getenv("SMS_PROVIDER"); val != "" {
+		config.Phone.SMSProvider = val
+	}
+
+	if val := getEnvFirst("STORAGE_BLOB_DIR"); val != "" {
+		config.Storage.BlobDir = val
+	}
+
+	if val := getEnvFirst("SIGNUP_MODE"); val != "" {
+		config.SignUp.Mode = val
+	}
+
+	if val := getEnvFirst("SIGNUP_ALLOWED_DOMAINS"); val != "" {
+		config.SignUp.AllowedDomains = strings.Split(val, ",")
+	}
+
+	if val := getEnvFirst("METRICS_ENABLED"); val != "" {
+		config.Metrics.Enabled = val == "true" || val == "1" || val == "yes"
+	}
+
+	if val := getEnvFirst("METRICS_ADDR"); val != "" {
+		config.Metrics.Addr = val
+	}
+
+	if val := getEnvFirst("TRACING_ENABLED"); val != "" {
+		config.Tracing.Enabled = val == "true" || val == "1" || val == "yes"
+	}
+
+	if val := getEnvFirst("OTEL_SERVICE_NAME"); val != "" {
+		config.Tracing.ServiceName = val
+	}
+
+	if val := getEnvFirst("OTEL_EXPORTER_OTLP_ENDPOINT", "TRACING_OTLP_ENDPOINT"); val != "" {
+		config.Tracing.OTLPEndpoint = val
+	}
+
+	if val := getEnvFirst("TRACING_FILE"); val != "" {
+		config.Tracing.File = val
+	}
+
+	if val := getEnvFirst("OPENAPI_VALIDATE_REQUESTS"); val != "" {
+		config.OpenAPI.ValidateRequests = val == "true" || val == "1" || val == "yes"
+	}
+
+	if val := getEnvFirst("OPENAPI_VALIDATE_RESPONSES"); val != "" {
+		config.OpenAPI.ValidateResponses = val == "true" || val == "1" || val == "yes"
+	}
+}
+
+func applyDefaults(config *Config) {
+	if config.Account.DeletionGracePeriod <= 0 {
+		config.Account.DeletionGracePeriod = 30 * 24 * time.Hour
+	}
+	if config.Account.DeletionPurgeInterval <= 0 {
+		config.Account.DeletionPurgeInterval = time.Hour
+	}
+	if config.Account.ExportTTL <= 0 {
+		config.Account.ExportTTL = 7 * 24 * time.Hour
+	}
+	if config.Account.ExportProcessInterval <= 0 {
+		config.Account.ExportProcessInterval = time.Minute
+	}
+	if config.Account.EmailChangeTTL <= 0 {
+		config.Account.EmailChangeTTL = 24 * time.Hour
+	}
+	if config.Auth.BearerTokenTTL <= 0 {
+		config.Auth.BearerTokenTTL = 30 * 24 * time.Hour
+	}
+	if config.JWT.Issuer == "" {
+		config.JWT.Issuer = config.Server.FullAddress
+	}
+	if config.JWT.AccessTokenTTL <= 0 {
+		config.JWT.AccessTokenTTL = 15 * time.Minute
+	}
+	if config.JWT.RefreshTokenTTL <= 0 {
+		config.JWT.RefreshTokenTTL = 30 * 24 * time.Hour
+	}
+	if config.JWT.PurgeInterval <= 0 {
+		config.JWT.PurgeInterval = time.Hour
+	}
+	if config.Phone.CodeTTL <= 0 {
+		config.Phone.CodeTTL = 10 * time.Minute
+	}
+	if config.Phone.CodeResendInterval <= 0 {
+		config.Phone.CodeResendInterval = time.Minute
+	}
+	if config.Phone.CodeMaxAttempts <= 0 {
+		config.Phone.CodeMaxAttempts = 5
+	}
+	if config.Phone.SMSProvider == "" {
+		config.Phone.SMSProvider = "log"
+	}
+	if config.Avatar.MaxUploadSize <= 0 {
+		config.Avatar.MaxUploadSize = 5 << 20
+	}
+	if config.Storage.BlobDir == "" {
+		config.Storage.BlobDir = "data/blobs"
+	}
+	if config.Directory.SearchLimit <= 0 {
+		config.Directory.SearchLimit = 30
+	}
+	if config.Directory.SearchPeriod <= 0 {
+		config.Directory.SearchPeriod = time.Minute
+	}
+	if config.Organization.InvitationTTL <= 0 {
+		config.Organization.InvitationTTL = 7 * 24 * time.Hour
+	}
+	if config.SignUp.Mode == "" {
+		config.SignUp.Mode = "open"
+	}
+	if config.SignUp.InviteCodeTTL <= 0 {
+		config.SignUp.InviteCodeTTL = 30 * 24 * time.Hour
+	}
+	if config.Metrics.Addr == "" {
+		config.Metrics.Addr = ":9090"
+	}
+	if config.Health.CheckTimeout <= 0 {
+		config.Health.CheckTimeout = 2 * time.Second
+	}
+	if config.Tracing.ServiceName == "" {
+		config.Tracing.ServiceName = "server"
+	}
+	if config.Tracing.SampleRatio <= 0 || config.Tracing.SampleRatio > 1 {
+		config.Tracing.SampleRatio = 1
+	}
 }
 
 func getEnvFirst(keys ...string) string {
@@ -138,6 +422,17 @@ func getEnvInt(keys ...string) int {
 	return 0
 }
 
+func getEnvDuration(keys ...string) time.Duration {
+	for _, key := range keys {
+		if val := os.Getenv(key); val != "" {
+			if duration, err := time.ParseDuration(val); err == nil {
+				return duration
+			}
+		}
+	}
+	return 0
+}
+
 func (c *DatabaseConfig) DSN() string {
 	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
 		c.Username,
@@ -0,0 +1,13 @@
+package accesstoken
+
+import (
+	"context"
+	"server/internal/domain"
+	"time"
+)
+
+type AccessTokenUC interface {
+	CreateToken(ctx context.Context, userID int64, name string, scopes []domain.TokenScope, expiresAt *time.Time) (string, *domain.PersonalAccessToken, error)
+	ListTokens(ctx context.Context, userID int64) ([]*domain.PersonalAccessToken, error)
+	DeleteToken(ctx context.Context, userID, id int64) error
+}
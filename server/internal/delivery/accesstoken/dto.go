@@ -0,0 +1,50 @@
+package accesstoken
+
+import (
+	"server/internal/domain"
+	"time"
+)
+
+type createTokenRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (req createTokenRequest) scopes() []domain.TokenScope {
+	scopes := make([]domain.TokenScope, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		scopes = append(scopes, domain.TokenScope(scope))
+	}
+	return scopes
+}
+
+type tokenDTO struct {
+	ID int64 `json:"id"`
+	// Token is only returned when the token is created.
+	Token      string     `json:"token,omitempty"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+func tokenFromDomain(token *domain.PersonalAccessToken) tokenDTO {
+	scopes := make([]string, 0, len(token.Scopes))
+	for _, scope := range token.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+	return tokenDTO{
+		ID:         token.ID,
+		Name:       token.Name,
+		Scopes:     scopes,
+		CreatedAt:  token.CreatedAt,
+		ExpiresAt:  token.ExpiresAt,
+		LastUsedAt: token.LastUsedAt,
+	}
+}
+
+type tokensDTO struct {
+	Tokens []tokenDTO `json:"tokens"`
+}
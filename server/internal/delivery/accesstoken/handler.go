@@ -0,0 +1,17 @@
+package accesstoken
+
+import (
+	"log/slog"
+)
+
+type Handler struct {
+	logger *slog.Logger
+	uc     AccessTokenUC
+}
+
+func NewHandler(logger *slog.Logger, uc AccessTokenUC) *Handler {
+	return &Handler{
+		logger: logger,
+		uc:     uc,
+	}
+}
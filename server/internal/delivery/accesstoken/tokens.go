@@ -0,0 +1,80 @@
+package accesstoken
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"server/internal/domain"
+	"server/internal/pkg/context"
+	"server/internal/pkg/httptools"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) ListTokens(w http.ResponseWriter, r *http.Request) {
+	session := context.MustSessionFromContext(r.Context())
+
+	tokens, err := h.uc.ListTokens(r.Context(), session.UserID)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to list access tokens", "error", err)
+		httptools.WriteJSONError(w, http.StatusInternalServerError, "failed to list access tokens")
+		return
+	}
+
+	dto := tokensDTO{Tokens: make([]tokenDTO, 0, len(tokens))}
+	for _, token := range tokens {
+		dto.Tokens = append(dto.Tokens, tokenFromDomain(token))
+	}
+	httptools.WriteJSONResponse(w, http.StatusOK, dto)
+}
+
+// CreateToken returns the new token once; it cannot be looked up later.
+func (h *Handler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	session := context.MustSessionFromContext(r.Context())
+
+	var req createTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httptools.WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	value, token, err := h.uc.CreateToken(r.Context(), session.UserID, req.Name, req.scopes(), req.ExpiresAt)
+	if err != nil {
+		if httptools.IsKnownError(err) {
+			httptools.WriteError(w, err)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "failed to create access token", "error", err)
+		httptools.WriteJSONError(w, http.StatusInternalServerError, "failed to create access token")
+		return
+	}
+
+	dto := tokenFromDomain(token)
+	dto.Token = value
+	w.Header().Set("Cache-Control", "no-store")
+	httptools.WriteJSONResponse(w, http.StatusCreated, dto)
+}
+
+func (h *Handler) DeleteToken(w http.ResponseWriter, r *http.Request) {
+	session := context.MustSessionFromContext(r.Context())
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		httptools.WriteJSONError(w, http.StatusBadRequest, "invalid access token id")
+		return
+	}
+
+	err = h.uc.DeleteToken(r.Context(), session.UserID, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrAccessTokenNotFound) {
+			httptools.WriteError(w, err)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "failed to delete access token", "error", err)
+		httptools.WriteJSONError(w, http.StatusInternalServerError, "failed to delete access token")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
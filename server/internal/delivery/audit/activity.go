@@ -0,0 +1,56 @@
+package audit
+
+import (
+	"net/http"
+	"server/internal/pkg/context"
+	"server/internal/pkg/httptools"
+	"strconv"
+)
+
+const (
+	defaultActivityLimit = 50
+	// maxActivityLimit matches the repository's cap, so a full page always
+	// comes with a cursor to the next one.
+	maxActivityLimit = 500
+)
+
+func (h *Handler) GetActivity(w http.ResponseWriter, r *http.Request) {
+	session := context.MustSessionFromContext(r.Context())
+
+	limit, beforeID, ok := parsePaging(r)
+	if !ok {
+		httptools.WriteJSONError(w, http.StatusBadRequest, "invalid paging parameters")
+		return
+	}
+
+	events, err := h.uc.ListUserActivity(r.Context(), session.UserID, beforeID, limit)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to list user activity", "error", err)
+		httptools.WriteJSONError(w, http.StatusInternalServerError, "failed to list activity")
+		return
+	}
+
+	httptools.WriteJSONResponse(w, http.StatusOK, eventsFromDomain(events, limit, false))
+}
+
+func parsePaging(r *http.Request) (int, int64, bool) {
+	limit := defaultActivityLimit
+	if val := r.URL.Query().Get("limit"); val != "" {
+		parsed, err := strconv.Atoi(val)
+		if err != nil || parsed <= 0 {
+			return 0, 0, false
+		}
+		limit = min(parsed, maxActivityLimit)
+	}
+
+	var beforeID int64
+	if val := r.URL.Query().Get("before"); val != "" {
+		parsed, err := strconv.ParseInt(val, 10, 64)
+		if err != nil || parsed <= 0 {
+			return 0, 0, false
+		}
+		beforeID = parsed
+	}
+
+	return limit, beforeID, true
+}
@@ -0,0 +1,60 @@
+package audit
+
+import (
+	"net/http"
+	"server/internal/domain"
+	"server/internal/pkg/httptools"
+	"strconv"
+)
+
+func (h *Handler) QueryEvents(w http.ResponseWriter, r *http.Request) {
+	limit, beforeID, ok := parsePaging(r)
+	if !ok {
+		httptools.WriteJSONError(w, http.StatusBadRequest, "invalid paging parameters")
+		return
+	}
+
+	query := domain.AuditQuery{
+		Type:     domain.AuditEventType(r.URL.Query().Get("type")),
+		Outcome:  domain.AuditOutcome(r.URL.Query().Get("outcome")),
+		BeforeID: beforeID,
+		Limit:    limit,
+	}
+	if val := r.URL.Query().Get("user_id"); val != "" {
+		userID, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			httptools.WriteJSONError(w, http.StatusBadRequest, "invalid user_id")
+			return
+		}
+		query.UserID = &userID
+	}
+
+	events, err := h.uc.QueryEvents(r.Context(), query)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to query audit events", "error", err)
+		httptools.WriteJSONError(w, http.StatusInternalServerError, "failed to query audit events")
+		return
+	}
+
+	httptools.WriteJSONResponse(w, http.StatusOK, eventsFromDomain(events, limit, true))
+}
+
+func (h *Handler) VerifyChain(w http.ResponseWriter, r *http.Request) {
+	report, err := h.uc.VerifyChain(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to verify audit chain", "error", err)
+		httptools.WriteJSONError(w, http.StatusInternalServerError, "failed to verify audit chain")
+		return
+	}
+
+	if !report.Valid {
+		h.logger.WarnContext(r.Context(), "audit chain is broken", "broken_at_id", report.BrokenAtID, "head_missing", report.HeadMissing)
+	}
+
+	httptools.WriteJSONResponse(w, http.StatusOK, chainReportDTO{
+		Checked:     report.Checked,
+		Valid:       report.Valid,
+		BrokenAtID:  report.BrokenAtID,
+		HeadMissing: report.HeadMissing,
+	})
+}
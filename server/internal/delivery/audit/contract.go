@@ -0,0 +1,12 @@
+package audit
+
+import (
+	"context"
+	"server/internal/domain"
+)
+
+type AuditUC interface {
+	ListUserActivity(ctx context.Context, userID int64, beforeID int64, limit int) ([]*domain.AuditEvent, error)
+	QueryEvents(ctx context.Context, query domain.AuditQuery) ([]*domain.AuditEvent, error)
+	VerifyChain(ctx context.Context) (*domain.AuditChainReport, error)
+}
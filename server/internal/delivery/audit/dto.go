@@ -0,0 +1,56 @@
+package audit
+
+import (
+	"server/internal/domain"
+	"time"
+)
+
+type auditEventDTO struct {
+	ID            int64             `json:"id"`
+	ActorUserID   *int64            `json:"actor_user_id"`
+	SubjectUserID *int64            `json:"subject_user_id"`
+	Type          string            `json:"type"`
+	IP            string            `json:"ip"`
+	UserAgent     string            `json:"user_agent"`
+	Outcome       string            `json:"outcome"`
+	Details       map[string]string `json:"details"`
+	CreatedAt     time.Time         `json:"created_at"`
+	Hash          string            `json:"hash,omitempty"`
+}
+
+type auditEventsDTO struct {
+	Events     []auditEventDTO `json:"events"`
+	NextBefore int64           `json:"next_before,omitempty"`
+}
+
+type chainReportDTO struct {
+	Checked     int   `json:"checked"`
+	Valid       bool  `json:"valid"`
+	BrokenAtID  int64 `json:"broken_at_id,omitempty"`
+	HeadMissing bool  `json:"head_missing,omitempty"`
+}
+
+func eventsFromDomain(events []*domain.AuditEvent, limit int, withHash bool) auditEventsDTO {
+	dto := auditEventsDTO{Events: make([]auditEventDTO, 0, len(events))}
+	for _, event := range events {
+		item := auditEventDTO{
+			ID:            event.ID,
+			ActorUserID:   event.ActorUserID,
+			SubjectUserID: event.SubjectUserID,
+			Type:          string(event.Type),
+			IP:            event.IP,
+			UserAgent:     event.UserAgent,
+			Outcome:       string(event.Outcome),
+			Details:       event.Details,
+			CreatedAt:     event.CreatedAt,
+		}
+		if withHash {
+			item.Hash = event.Hash
+		}
+		dto.Events = append(dto.Events, item)
+	}
+	if limit > 0 && len(events) == limit {
+		dto.NextBefore = events[len(events)-1].ID
+	}
+	return dto
+}
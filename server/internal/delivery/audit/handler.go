@@ -0,0 +1,17 @@
+package audit
+
+import (
+	"log/slog"
+)
+
+type Handler struct {
+	logger *slog.Logger
+	uc     AuditUC
+}
+
+func NewHandler(logger *slog.Logger, uc AuditUC) *Handler {
+	return &Handler{
+		logger: logger,
+		uc:     uc,
+	}
+}
@@ -0,0 +1,38 @@
+package delivery
+
+import (
+	"log/slog"
+	"net/http"
+	"server/internal/pkg/context"
+	"server/internal/pkg/httptools"
+)
+
+type AdminMiddleware struct {
+	logger *slog.Logger
+	uc     AdminUC
+}
+
+func NewAdminMiddleware(logger *slog.Logger, uc AdminUC) *AdminMiddleware {
+	return &AdminMiddleware{
+		logger: logger,
+		uc:     uc,
+	}
+}
+
+// RequireAdmin must run after RequireAuth, it relies on the session in the context.
+func (m *AdminMiddleware) RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session := context.MustSessionFromContext(r.Context())
+		isAdmin, err := m.uc.IsAdmin(r.Context(), session.UserID)
+		if err != nil {
+			m.logger.ErrorContext(r.Context(), "failed to check admin role", "error", err)
+			httptools.WriteJSONError(w, http.StatusInternalServerError, "failed to check admin role")
+			return
+		}
+		if !isAdmin {
+			httptools.WriteJSONError(w, http.StatusForbidden, "forbidden")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -3,17 +3,34 @@ package delivery
 import (
 	"context"
 	"server/internal/domain"
+	"server/internal/pkg/jwt"
 )
 
 type SessionUC interface {
 	GetSessionByToken(ctx context.Context, token string) (*domain.Session, error)
 }
 
+type AccessTokenUC interface {
+	Authenticate(ctx context.Context, token string) (*domain.Session, error)
+}
+
 type AuthUC interface {
-	SignUpWithEmail(ctx context.Context, email, password string) error
+	SignUpWithEmail(ctx context.Context, email, password, inviteCode string) error
 	LogInWithEmail(ctx context.Context, email, password string) (*domain.Session, error)
 	LogOut(ctx context.Context, session *domain.Session) error
 	LogInWithGoogle(ctx context.Context, code string) (*domain.Session, error)
-	SignUpWithGoogle(ctx context.Context, code string) error
+	IssueToken(ctx context.Context, grant domain.TokenGrant) (*domain.Session, error)
+	IssueJWT(ctx context.Context, grant domain.TokenGrant) (*domain.TokenPair, error)
+	RevokeRefreshToken(ctx context.Context, refreshToken string) error
+	JWKS() jwt.JWKS
+	SignUpWithGoogle(ctx context.Context, code, inviteCode string) error
 	GetGoogleAuthURL(ctx context.Context, purpose string) (string, string, error)
+	SignUpPolicy() domain.SignUpPolicy
+	CreateInviteCode(ctx context.Context, adminID int64, note string) (string, *domain.InviteCode, error)
+	ListInviteCodes(ctx context.Context) ([]*domain.InviteCode, error)
+	DeleteInviteCode(ctx context.Context, adminID, id int64) error
+}
+
+type AdminUC interface {
+	IsAdmin(ctx context.Context, userID int64) (bool, error)
 }
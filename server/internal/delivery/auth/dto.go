@@ -1,6 +1,84 @@
 package delivery
 
+import (
+	"server/internal/domain"
+	"time"
+)
+
 type authDTO struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 }
+
+type signUpDTO struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	// InviteCode is required while sign-up is invite-only.
+	InviteCode string `json:"invite_code"`
+}
+
+// tokenRequest follows the OAuth 2.0 token request: grant_type "password"
+// takes email and password, "google" a Google authorization code.
+type tokenRequest struct {
+	GrantType string `json:"grant_type"`
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+	Code      string `json:"code"`
+	// RefreshToken is only accepted by /api/auth/jwt, with grant_type
+	// "refresh_token".
+	RefreshToken string `json:"refresh_token"`
+}
+
+type tokenResponse struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresIn   int64     `json:"expires_in"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// jwtResponse extends tokenResponse with the refresh token to get the next
+// access token with.
+type jwtResponse struct {
+	tokenResponse
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+}
+
+type revokeRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type signUpPolicyDTO struct {
+	Mode           string   `json:"mode"`
+	AllowedDomains []string `json:"allowed_domains,omitempty"`
+}
+
+type createInviteCodeRequest struct {
+	Note string `json:"note"`
+}
+
+type inviteCodeDTO struct {
+	ID int64 `json:"id"`
+	// Code is only returned when the invite code is created.
+	Code      string     `json:"code,omitempty"`
+	Note      string     `json:"note"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedEmail string     `json:"used_email,omitempty"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+}
+
+func inviteCodeFromDomain(invite *domain.InviteCode) inviteCodeDTO {
+	return inviteCodeDTO{
+		ID:        invite.ID,
+		Note:      invite.Note,
+		CreatedAt: invite.CreatedAt,
+		ExpiresAt: invite.ExpiresAt,
+		UsedEmail: invite.UsedEmail,
+		UsedAt:    invite.UsedAt,
+	}
+}
+
+type inviteCodesDTO struct {
+	InviteCodes []inviteCodeDTO `json:"invite_codes"`
+}
@@ -11,31 +11,26 @@ import (
 
 func (h *Handler) SignUpWithEmail(w http.ResponseWriter, r *http.Request) {
 	decoder := json.NewDecoder(r.Body)
-	userCreate := authDTO{}
+	userCreate := signUpDTO{}
 	err := decoder.Decode(&userCreate)
 	if err != nil {
-		h.logger.Warn("failed to decode request body", "error", err)
+		h.logger.WarnContext(r.Context(), "failed to decode request body", "error", err)
 		httptools.WriteJSONError(w, http.StatusBadRequest, "bad input data")
 		return
 	}
 
-	err = h.uc.SignUpWithEmail(r.Context(), userCreate.Email, userCreate.Password)
+	err = h.uc.SignUpWithEmail(r.Context(), userCreate.Email, userCreate.Password, userCreate.InviteCode)
 	if err != nil {
-		switch {
-		case errors.Is(err, domain.ErrUserAlreadyExists):
-			h.logger.Info("user already exists", "email", userCreate.Email)
-			httptools.WriteJSONError(w, http.StatusBadRequest, "user already exists")
-		case errors.Is(err, domain.ErrNotValidEmail):
-			h.logger.Warn("invalid email", "email", userCreate.Email)
-			httptools.WriteJSONError(w, http.StatusBadRequest, "not valid email")
-		default:
-			h.logger.Error("internal error during sign up", "error", err)
-			httptools.WriteJSONError(w, http.StatusInternalServerError, "internal server error")
+		if httptools.IsKnownError(err) {
+			h.logger.WarnContext(r.Context(), "sign up rejected", "error", err, "email", userCreate.Email)
+		} else {
+			h.logger.ErrorContext(r.Context(), "internal error during sign up", "error", err)
 		}
+		httptools.WriteError(w, err)
 		return
 	}
 
-	h.logger.Info("user created successfully", "email", userCreate.Email)
+	h.logger.InfoContext(r.Context(), "user created successfully", "email", userCreate.Email)
 	httptools.WriteJSONResponse(w, http.StatusCreated, map[string]string{"message": "user created successfully"})
 }
 
@@ -44,7 +39,7 @@ func (h *Handler) LogInWithEmail(w http.ResponseWriter, r *http.Request) {
 	userLogin := authDTO{}
 	err := decoder.Decode(&userLogin)
 	if err != nil {
-		h.logger.Warn("failed to decode request body", "error", err)
+		h.logger.WarnContext(r.Context(), "failed to decode request body", "error", err)
 		httptools.WriteJSONError(w, http.StatusBadRequest, "bad input data")
 		return
 	}
@@ -52,18 +47,16 @@ func (h *Handler) LogInWithEmail(w http.ResponseWriter, r *http.Request) {
 	session, err := h.uc.LogInWithEmail(r.Context(), userLogin.Email, userLogin.Password)
 	if err != nil {
 		switch {
-		case errors.Is(err, domain.ErrInvalidPassword):
-			h.logger.Warn("invalid password", "email", userLogin.Email)
-			httptools.WriteJSONError(w, http.StatusUnauthorized, "password is incorrect")
-		case errors.Is(err, domain.ErrNotValidEmail):
-			h.logger.Warn("invalid email", "email", userLogin.Email)
-			httptools.WriteJSONError(w, http.StatusBadRequest, "not valid email")
 		case errors.Is(err, domain.ErrUserNotExists):
-			h.logger.Warn("user not exists", "email", userLogin.Email)
-			httptools.WriteJSONError(w, http.StatusUnauthorized, "username entered does not exist")
+			// An unknown account is a failed login here, not a missing resource.
+			h.logger.WarnContext(r.Context(), "user not exists", "email", userLogin.Email)
+			httptools.WriteProblem(w, http.StatusUnauthorized, httptools.CodeUserNotFound, "username entered does not exist")
+		case httptools.IsKnownError(err):
+			h.logger.WarnContext(r.Context(), "login rejected", "error", err, "email", userLogin.Email)
+			httptools.WriteError(w, err)
 		default:
-			h.logger.Error("internal error during login", "error", err)
-			httptools.WriteJSONError(w, http.StatusInternalServerError, "internal server error")
+			h.logger.ErrorContext(r.Context(), "internal error during login", "error", err)
+			httptools.WriteError(w, err)
 		}
 		return
 	}
@@ -80,6 +73,6 @@ func (h *Handler) LogInWithEmail(w http.ResponseWriter, r *http.Request) {
 		MaxAge:   int(time.Until(session.ExpiresAt).Seconds()),
 	})
 
-	h.logger.Info("user logged in successfully", "email", userLogin.Email)
+	h.logger.InfoContext(r.Context(), "user logged in successfully", "email", userLogin.Email)
 	httptools.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "logged in successfully"})
 }
@@ -13,6 +13,10 @@ import (
 const stateCookieName = "state"
 const stateCookieMaxAge = time.Minute * 10
 
+// inviteCookieName carries the invite code of an invite-only Google sign-up
+// through the OAuth round trip.
+const inviteCookieName = "signup_invite"
+
 func (h *Handler) GetGoogleAuthURL(w http.ResponseWriter, r *http.Request) {
 	purpose := r.URL.Query().Get("purpose")
 	if purpose != "login" && purpose != "signup" {
@@ -21,7 +25,7 @@ func (h *Handler) GetGoogleAuthURL(w http.ResponseWriter, r *http.Request) {
 	}
 	url, state, err := h.uc.GetGoogleAuthURL(r.Context(), purpose)
 	if err != nil {
-		h.logger.Error("failed to get google auth url", "error", err)
+		h.logger.ErrorContext(r.Context(), "failed to get google auth url", "error", err)
 		httptools.WriteJSONError(w, http.StatusInternalServerError, "failed to get google auth url")
 		return
 	}
@@ -36,6 +40,18 @@ func (h *Handler) GetGoogleAuthURL(w http.ResponseWriter, r *http.Request) {
 		MaxAge:   int(stateCookieMaxAge.Seconds()),
 		Expires:  time.Now().Add(stateCookieMaxAge),
 	})
+	if inviteCode := r.URL.Query().Get("invite_code"); purpose == "signup" && inviteCode != "" {
+		http.SetCookie(w, &http.Cookie{
+			Name:     inviteCookieName,
+			Value:    inviteCode,
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   secure,
+			SameSite: http.SameSiteLaxMode,
+			MaxAge:   int(stateCookieMaxAge.Seconds()),
+			Expires:  time.Now().Add(stateCookieMaxAge),
+		})
+	}
 	httptools.WriteJSONResponse(w, http.StatusOK, map[string]string{"url": url})
 }
 
@@ -73,7 +89,7 @@ func (h *Handler) validateStateAndExtractCode(w http.ResponseWriter, r *http.Req
 func (h *Handler) SignUpWithGoogle(w http.ResponseWriter, r *http.Request) {
 	redirectURL, err := url.Parse(h.frontendURL + "/signup")
 	if err != nil {
-		h.logger.Error("internal server error", "error", err)
+		h.logger.ErrorContext(r.Context(), "internal server error", "error", err)
 		redirectURL.RawQuery = url.Values{"error": {"internal server error"}}.Encode()
 		http.Redirect(w, r, redirectURL.String(), http.StatusSeeOther)
 		return
@@ -81,22 +97,45 @@ func (h *Handler) SignUpWithGoogle(w http.ResponseWriter, r *http.Request) {
 
 	code, err := h.validateStateAndExtractCode(w, r, redirectURL)
 	if err != nil {
-		h.logger.Warn("failed to validate state and extract code", "error", err)
+		h.logger.WarnContext(r.Context(), "failed to validate state and extract code", "error", err)
 		return
 	}
 
-	err = h.uc.SignUpWithGoogle(r.Context(), code)
+	var inviteCode string
+	if inviteCookie, err := r.Cookie(inviteCookieName); err == nil {
+		inviteCode = inviteCookie.Value
+		http.SetCookie(w, &http.Cookie{
+			Name:     inviteCookieName,
+			Value:    "",
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   r.TLS != nil,
+			SameSite: http.SameSiteLaxMode,
+			MaxAge:   -1,
+			Expires:  time.Unix(0, 0),
+		})
+	}
+
+	err = h.uc.SignUpWithGoogle(r.Context(), code, inviteCode)
 	if err != nil {
 		var errorMessage string
 		if errors.Is(err, domain.ErrInvalidGoogleCode) {
 			errorMessage = "invalid google code"
 		} else if errors.Is(err, domain.ErrUserAlreadyExists) {
 			errorMessage = "user already exists"
+		} else if errors.Is(err, domain.ErrInviteCodeRequired) {
+			errorMessage = "invite code required"
+		} else if errors.Is(err, domain.ErrInvalidInviteCode) {
+			errorMessage = "invite code is invalid, expired or already used"
+		} else if errors.Is(err, domain.ErrEmailDomainNotAllowed) {
+			errorMessage = "sign-up is not open to this email domain"
+		} else if errors.Is(err, domain.ErrEmailNotVerified) {
+			errorMessage = "the google account's email address is not verified"
 		} else {
 			errorMessage = "failed to sign up with google"
 		}
-		h.logger.Error("failed to sign up with google", "error", err)
-		redirectURL.RawQuery = url.Values{"error": {errorMessage}}.Encode()
+		h.logger.ErrorContext(r.Context(), "failed to sign up with google", "error", err)
+		redirectURL.RawQuery = url.Values{"error": {errorMessage}, "error_code": {httptools.ErrorCode(err)}}.Encode()
 		http.Redirect(w, r, redirectURL.String(), http.StatusSeeOther)
 		return
 	}
@@ -108,7 +147,7 @@ func (h *Handler) SignUpWithGoogle(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) LogInWithGoogle(w http.ResponseWriter, r *http.Request) {
 	redirectURL, err := url.Parse(h.frontendURL + "/login")
 	if err != nil {
-		h.logger.Error("internal server error", "error", err)
+		h.logger.ErrorContext(r.Context(), "internal server error", "error", err)
 		redirectURL.RawQuery = url.Values{"error": {"internal server error"}}.Encode()
 		http.Redirect(w, r, redirectURL.String(), http.StatusSeeOther)
 		return
@@ -116,7 +155,7 @@ func (h *Handler) LogInWithGoogle(w http.ResponseWriter, r *http.Request) {
 
 	code, err := h.validateStateAndExtractCode(w, r, redirectURL)
 	if err != nil {
-		h.logger.Warn("failed to validate state and extract code", "error", err)
+		h.logger.WarnContext(r.Context(), "failed to validate state and extract code", "error", err)
 		return
 	}
 
@@ -130,8 +169,8 @@ func (h *Handler) LogInWithGoogle(w http.ResponseWriter, r *http.Request) {
 		} else {
 			errorMessage = "failed to log in with google"
 		}
-		h.logger.Error("failed to log in with google", "error", err)
-		redirectURL.RawQuery = url.Values{"error": {errorMessage}}.Encode()
+		h.logger.ErrorContext(r.Context(), "failed to log in with google", "error", err)
+		redirectURL.RawQuery = url.Values{"error": {errorMessage}, "error_code": {httptools.ErrorCode(err)}}.Encode()
 		http.Redirect(w, r, redirectURL.String(), http.StatusSeeOther)
 		return
 	}
@@ -160,7 +199,7 @@ func (h *Handler) LogInWithGoogle(w http.ResponseWriter, r *http.Request) {
 
 	redirectURL, err = url.Parse(h.frontendURL + "/profile")
 	if err != nil {
-		h.logger.Error("failed to parse redirect URL", "error", err)
+		h.logger.ErrorContext(r.Context(), "failed to parse redirect URL", "error", err)
 		redirectURL.RawQuery = url.Values{"error": {"internal server error"}}.Encode()
 		http.Redirect(w, r, redirectURL.String(), http.StatusSeeOther)
 		return
@@ -0,0 +1,89 @@
+package delivery
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"server/internal/domain"
+	"server/internal/pkg/context"
+	"server/internal/pkg/httptools"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// GetSignUpPolicy tells clients whether sign-up needs an invite code or is
+// limited to some email domains.
+func (h *Handler) GetSignUpPolicy(w http.ResponseWriter, r *http.Request) {
+	policy := h.uc.SignUpPolicy()
+	dto := signUpPolicyDTO{Mode: string(policy.Mode)}
+	if policy.Mode == domain.SignUpModeAllowedDomains {
+		dto.AllowedDomains = policy.AllowedDomains
+	}
+	httptools.WriteJSONResponse(w, http.StatusOK, dto)
+}
+
+func (h *Handler) ListInviteCodes(w http.ResponseWriter, r *http.Request) {
+	codes, err := h.uc.ListInviteCodes(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to list invite codes", "error", err)
+		httptools.WriteJSONError(w, http.StatusInternalServerError, "failed to list invite codes")
+		return
+	}
+
+	dto := inviteCodesDTO{InviteCodes: make([]inviteCodeDTO, 0, len(codes))}
+	for _, code := range codes {
+		dto.InviteCodes = append(dto.InviteCodes, inviteCodeFromDomain(code))
+	}
+	httptools.WriteJSONResponse(w, http.StatusOK, dto)
+}
+
+// CreateInviteCode returns the new code once; it cannot be looked up later.
+func (h *Handler) CreateInviteCode(w http.ResponseWriter, r *http.Request) {
+	session := context.MustSessionFromContext(r.Context())
+
+	var req createInviteCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httptools.WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	code, invite, err := h.uc.CreateInviteCode(r.Context(), session.UserID, req.Note)
+	if err != nil {
+		var fieldErrors domain.FieldErrors
+		if errors.As(err, &fieldErrors) {
+			httptools.WriteValidationProblem(w, fieldErrors)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "failed to create invite code", "error", err)
+		httptools.WriteJSONError(w, http.StatusInternalServerError, "failed to create invite code")
+		return
+	}
+
+	dto := inviteCodeFromDomain(invite)
+	dto.Code = code
+	httptools.WriteJSONResponse(w, http.StatusCreated, dto)
+}
+
+func (h *Handler) DeleteInviteCode(w http.ResponseWriter, r *http.Request) {
+	session := context.MustSessionFromContext(r.Context())
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		httptools.WriteJSONError(w, http.StatusBadRequest, "invalid invite code id")
+		return
+	}
+
+	err = h.uc.DeleteInviteCode(r.Context(), session.UserID, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrInviteCodeNotFound) {
+			httptools.WriteError(w, err)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "failed to delete invite code", "error", err)
+		httptools.WriteJSONError(w, http.StatusInternalServerError, "failed to delete invite code")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,87 @@
+package delivery
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"server/internal/domain"
+	"server/internal/pkg/httptools"
+	"time"
+)
+
+// IssueJWT answers with a signed access token for services that check it
+// against /.well-known/jwks.json instead of calling back, and a refresh
+// token to rotate it with. It takes the grants of IssueToken plus
+// grant_type "refresh_token".
+func (h *Handler) IssueJWT(w http.ResponseWriter, r *http.Request) {
+	var req tokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WarnContext(r.Context(), "failed to decode request body", "error", err)
+		httptools.WriteJSONError(w, http.StatusBadRequest, "bad input data")
+		return
+	}
+
+	pair, err := h.uc.IssueJWT(r.Context(), domain.TokenGrant{
+		Type:         req.GrantType,
+		Email:        req.Email,
+		Password:     req.Password,
+		Code:         req.Code,
+		RefreshToken: req.RefreshToken,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrUserNotExists):
+			h.logger.WarnContext(r.Context(), "user not exists", "grant_type", req.GrantType)
+			httptools.WriteProblem(w, http.StatusUnauthorized, httptools.CodeUserNotFound, "username entered does not exist")
+		case httptools.IsKnownError(err):
+			h.logger.WarnContext(r.Context(), "jwt request rejected", "error", err, "grant_type", req.GrantType)
+			httptools.WriteError(w, err)
+		default:
+			h.logger.ErrorContext(r.Context(), "internal error during jwt request", "error", err)
+			httptools.WriteError(w, err)
+		}
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "jwt issued", "user_id", pair.UserID, "grant_type", req.GrantType)
+	w.Header().Set("Cache-Control", "no-store")
+	httptools.WriteJSONResponse(w, http.StatusOK, jwtResponse{
+		tokenResponse: tokenResponse{
+			AccessToken: pair.AccessToken,
+			TokenType:   "Bearer",
+			ExpiresIn:   int64(time.Until(pair.AccessTokenExpiresAt).Seconds()),
+			ExpiresAt:   pair.AccessTokenExpiresAt,
+		},
+		RefreshToken:          pair.RefreshToken,
+		RefreshTokenExpiresAt: pair.RefreshTokenExpiresAt,
+	})
+}
+
+// RevokeJWT logs a JWT client out by revoking its refresh token and every
+// token rotated from the same login.
+func (h *Handler) RevokeJWT(w http.ResponseWriter, r *http.Request) {
+	var req revokeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WarnContext(r.Context(), "failed to decode request body", "error", err)
+		httptools.WriteJSONError(w, http.StatusBadRequest, "bad input data")
+		return
+	}
+
+	if err := h.uc.RevokeRefreshToken(r.Context(), req.RefreshToken); err != nil {
+		if httptools.IsKnownError(err) {
+			h.logger.WarnContext(r.Context(), "refresh token revocation rejected", "error", err)
+		} else {
+			h.logger.ErrorContext(r.Context(), "failed to revoke refresh token", "error", err)
+		}
+		httptools.WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// JWKS publishes the keys access tokens are signed with. Services may cache
+// the set briefly and should fetch it again when they see an unknown kid.
+func (h *Handler) JWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	httptools.WriteJSONResponse(w, http.StatusOK, h.uc.JWKS())
+}
@@ -11,7 +11,7 @@ func (h *Handler) LogOut(w http.ResponseWriter, r *http.Request) {
 	session := context.MustSessionFromContext(r.Context())
 	err := h.uc.LogOut(r.Context(), session)
 	if err != nil {
-		h.logger.Error("failed to log out", "error", err)
+		h.logger.ErrorContext(r.Context(), "failed to log out", "error", err)
 		httptools.WriteJSONError(w, http.StatusInternalServerError, "failed to log out")
 		return
 	}
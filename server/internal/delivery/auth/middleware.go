@@ -7,17 +7,20 @@ import (
 	"server/internal/domain"
 	"server/internal/pkg/context"
 	"server/internal/pkg/httptools"
+	"strings"
 )
 
 type AuthMiddleware struct {
-	logger *slog.Logger
-	uc     SessionUC
+	logger        *slog.Logger
+	uc            SessionUC
+	accessTokenUC AccessTokenUC
 }
 
-func NewAuthMiddleware(logger *slog.Logger, uc SessionUC) *AuthMiddleware {
+func NewAuthMiddleware(logger *slog.Logger, uc SessionUC, accessTokenUC AccessTokenUC) *AuthMiddleware {
 	return &AuthMiddleware{
-		logger: logger,
-		uc:     uc,
+		logger:        logger,
+		uc:            uc,
+		accessTokenUC: accessTokenUC,
 	}
 }
 
@@ -29,7 +32,7 @@ func (m *AuthMiddleware) RequireUnAuth(next http.Handler) http.Handler {
 			return
 		}
 		if err != nil {
-			m.logger.Error("failed to get auth token", "error", err)
+			m.logger.ErrorContext(r.Context(), "failed to get auth token", "error", err)
 			httptools.WriteJSONError(w, http.StatusInternalServerError, "failed to get auth token")
 			return
 		}
@@ -40,34 +43,51 @@ func (m *AuthMiddleware) RequireUnAuth(next http.Handler) http.Handler {
 			return
 		}
 		if err != nil {
-			m.logger.Error("failed to check if session is active", "error", err)
+			m.logger.ErrorContext(r.Context(), "failed to check if session is active", "error", err)
 			httptools.WriteJSONError(w, http.StatusInternalServerError, "failed to check if session is active")
 			return
 		}
 
-		httptools.WriteJSONError(w, http.StatusForbidden, "session is active")
+		httptools.WriteProblem(w, http.StatusForbidden, httptools.CodeSessionActive, "session is active")
 	})
 }
 
 func (m *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		token, err := r.Cookie("auth_token")
-		if errors.Is(err, http.ErrNoCookie) {
+		session, err := m.authenticate(r)
+		if errors.Is(err, errNoCredentials) {
 			httptools.WriteJSONError(w, http.StatusUnauthorized, "unauthorized")
 			return
 		}
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			if _, ok := bearerToken(r); ok {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			}
+			httptools.WriteError(w, err)
+			return
+		}
 		if err != nil {
-			m.logger.Error("failed to get auth token", "error", err)
-			httptools.WriteJSONError(w, http.StatusInternalServerError, "failed to get auth token")
+			m.logger.ErrorContext(r.Context(), "failed to check if session is active", "error", err)
+			httptools.WriteJSONError(w, http.StatusInternalServerError, "failed to check if session is active")
 			return
 		}
-		session, err := m.uc.GetSessionByToken(r.Context(), token.Value)
-		if errors.Is(err, domain.ErrSessionNotFound) {
-			httptools.WriteJSONError(w, http.StatusUnauthorized, "unauthorized")
+		r = r.WithContext(context.WithSession(r.Context(), session))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// OptionalAuth attaches the session when the request carries a valid one and
+// lets anonymous requests through otherwise, for pages that show more to
+// signed-in users.
+func (m *AuthMiddleware) OptionalAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, err := m.authenticate(r)
+		if errors.Is(err, errNoCredentials) || errors.Is(err, domain.ErrSessionNotFound) {
+			next.ServeHTTP(w, r)
 			return
 		}
 		if err != nil {
-			m.logger.Error("failed to check if session is active", "error", err)
+			m.logger.ErrorContext(r.Context(), "failed to check if session is active", "error", err)
 			httptools.WriteJSONError(w, http.StatusInternalServerError, "failed to check if session is active")
 			return
 		}
@@ -75,3 +95,42 @@ func (m *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+var errNoCredentials = errors.New("no credentials")
+
+// authenticate finds the session of a request from its bearer token or,
+// without an Authorization header, from the auth_token cookie. Each kind of
+// session is only accepted the way it was issued, so a bearer token cannot
+// stand in for a cookie and skip the CSRF check, or the reverse. Personal
+// access tokens are recognised by their prefix and only work as bearer
+// tokens.
+func (m *AuthMiddleware) authenticate(r *http.Request) (*domain.Session, error) {
+	token, bearer := bearerToken(r)
+	if !bearer {
+		cookie, err := r.Cookie("auth_token")
+		if err != nil {
+			return nil, errNoCredentials
+		}
+		token = cookie.Value
+	} else if strings.HasPrefix(token, domain.AccessTokenPrefix) {
+		return m.accessTokenUC.Authenticate(r.Context(), token)
+	}
+
+	session, err := m.uc.GetSessionByToken(r.Context(), token)
+	if err != nil {
+		return nil, err
+	}
+	if session.Bearer != bearer {
+		return nil, domain.ErrSessionNotFound
+	}
+	return session, nil
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
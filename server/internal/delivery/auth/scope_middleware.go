@@ -0,0 +1,63 @@
+package delivery
+
+import (
+	"fmt"
+	"net/http"
+	"server/internal/domain"
+	"server/internal/pkg/context"
+	"server/internal/pkg/httptools"
+
+	"github.com/gorilla/mux"
+)
+
+// ScopeMiddleware limits what personal access tokens can do. Routes are
+// looked up as "METHOD /path/template"; a token may only call routes listed
+// in the table, and only with the scope given there. Other sessions are not
+// affected.
+type ScopeMiddleware struct {
+	routeScopes map[string]domain.TokenScope
+}
+
+func NewScopeMiddleware(routeScopes map[string]domain.TokenScope) *ScopeMiddleware {
+	return &ScopeMiddleware{
+		routeScopes: routeScopes,
+	}
+}
+
+// RequireScope must run after RequireAuth or OptionalAuth; requests without
+// a session pass through.
+func (m *ScopeMiddleware) RequireScope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, ok := context.SessionFromContext(r.Context())
+		if !ok || session.AuthMethod != domain.AuthMethodAccessToken {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		scope, ok := m.routeScope(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope"`)
+			httptools.WriteError(w, domain.ErrInsufficientScope)
+			return
+		}
+		if !session.HasScope(scope) {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer error="insufficient_scope", scope="%s"`, scope))
+			httptools.WriteError(w, domain.ErrInsufficientScope)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (m *ScopeMiddleware) routeScope(r *http.Request) (domain.TokenScope, bool) {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return "", false
+	}
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return "", false
+	}
+	scope, ok := m.routeScopes[r.Method+" "+template]
+	return scope, ok
+}
@@ -15,7 +15,7 @@ func (h *Handler) CheckAuthStatus(w http.ResponseWriter, r *http.Request) {
 			httptools.WriteJSONResponse(w, http.StatusOK, map[string]bool{"authenticated": false})
 			return
 		}
-		h.logger.Error("failed to get auth token", "error", err)
+		h.logger.ErrorContext(r.Context(), "failed to get auth token", "error", err)
 		httptools.WriteJSONError(w, http.StatusInternalServerError, "failed to get auth token")
 		return
 	}
@@ -26,7 +26,7 @@ func (h *Handler) CheckAuthStatus(w http.ResponseWriter, r *http.Request) {
 			httptools.WriteJSONResponse(w, http.StatusOK, map[string]bool{"authenticated": false})
 			return
 		}
-		h.logger.Error("failed to check session", "error", err)
+		h.logger.ErrorContext(r.Context(), "failed to check session", "error", err)
 		httptools.WriteJSONError(w, http.StatusInternalServerError, "failed to check session")
 		return
 	}
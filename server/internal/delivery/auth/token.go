@@ -0,0 +1,52 @@
+package delivery
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"server/internal/domain"
+	"server/internal/pkg/httptools"
+	"time"
+)
+
+// IssueToken logs an API client in and answers with a bearer token for the
+// Authorization header. No cookies are read or set, so clients that cannot
+// keep cookies or echo the CSRF token can use the API.
+func (h *Handler) IssueToken(w http.ResponseWriter, r *http.Request) {
+	var req tokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WarnContext(r.Context(), "failed to decode request body", "error", err)
+		httptools.WriteJSONError(w, http.StatusBadRequest, "bad input data")
+		return
+	}
+
+	session, err := h.uc.IssueToken(r.Context(), domain.TokenGrant{
+		Type:     req.GrantType,
+		Email:    req.Email,
+		Password: req.Password,
+		Code:     req.Code,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrUserNotExists):
+			h.logger.WarnContext(r.Context(), "user not exists", "grant_type", req.GrantType)
+			httptools.WriteProblem(w, http.StatusUnauthorized, httptools.CodeUserNotFound, "username entered does not exist")
+		case httptools.IsKnownError(err):
+			h.logger.WarnContext(r.Context(), "token request rejected", "error", err, "grant_type", req.GrantType)
+			httptools.WriteError(w, err)
+		default:
+			h.logger.ErrorContext(r.Context(), "internal error during token request", "error", err)
+			httptools.WriteError(w, err)
+		}
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "bearer token issued", "user_id", session.UserID, "grant_type", req.GrantType)
+	w.Header().Set("Cache-Control", "no-store")
+	httptools.WriteJSONResponse(w, http.StatusOK, tokenResponse{
+		AccessToken: session.Token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(time.Until(session.ExpiresAt).Seconds()),
+		ExpiresAt:   session.ExpiresAt,
+	})
+}
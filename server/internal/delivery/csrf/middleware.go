@@ -4,6 +4,7 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"server/internal/pkg/context"
 	"server/internal/pkg/httptools"
 	"time"
 )
@@ -28,7 +29,7 @@ func (m *CSRFMiddleware) SetCSRFToken(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		token, err := m.uc.GetCSRFToken(r.Context())
 		if err != nil {
-			m.logger.Error("failed to get CSRF token", "error", err)
+			m.logger.ErrorContext(r.Context(), "failed to get CSRF token", "error", err)
 			httptools.WriteJSONError(w, http.StatusInternalServerError, "failed to get CSRF token")
 			return
 		}
@@ -48,27 +49,35 @@ func (m *CSRFMiddleware) SetCSRFToken(next http.Handler) http.Handler {
 	})
 }
 
+// RequireCSRFToken checks the double-submitted token. Requests authenticated
+// with a bearer token are exempt: a browser never attaches the Authorization
+// header on its own, so they cannot be forged cross-site.
 func (m *CSRFMiddleware) RequireCSRFToken(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if session, ok := context.SessionFromContext(r.Context()); ok && session.Bearer {
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		tokenCookie, err := r.Cookie(csrfTokenCookieName)
 		if errors.Is(err, http.ErrNoCookie) {
-			httptools.WriteJSONError(w, http.StatusBadRequest, "csrf token is required")
+			httptools.WriteProblem(w, http.StatusBadRequest, httptools.CodeInvalidCSRFToken, "csrf token is required")
 			return
 		}
 		if err != nil {
-			m.logger.Error("failed to get CSRF token", "error", err)
+			m.logger.ErrorContext(r.Context(), "failed to get CSRF token", "error", err)
 			httptools.WriteJSONError(w, http.StatusInternalServerError, "failed to get CSRF token")
 			return
 		}
 		tokenHeader := r.Header.Get(csrfTokenHeaderName)
 		ok, err := m.uc.ValidateCSRFToken(r.Context(), tokenCookie.Value, tokenHeader)
 		if err != nil {
-			m.logger.Error("failed to validate CSRF token", "error", err)
+			m.logger.ErrorContext(r.Context(), "failed to validate CSRF token", "error", err)
 			httptools.WriteJSONError(w, http.StatusInternalServerError, "failed to validate CSRF token")
 			return
 		}
 		if !ok {
-			httptools.WriteJSONError(w, http.StatusBadRequest, "invalid CSRF token")
+			httptools.WriteProblem(w, http.StatusBadRequest, httptools.CodeInvalidCSRFToken, "invalid CSRF token")
 			return
 		}
 		next.ServeHTTP(w, r)
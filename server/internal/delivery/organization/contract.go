@@ -0,0 +1,20 @@
+package organization
+
+import (
+	"context"
+	"server/internal/domain"
+)
+
+type OrganizationUC interface {
+	CreateOrganization(ctx context.Context, userID int64, name string) (*domain.OrganizationMembership, error)
+	ListOrganizations(ctx context.Context, userID int64) ([]*domain.OrganizationMembership, error)
+	ListMembers(ctx context.Context, userID, organizationID int64) ([]*domain.OrganizationMember, error)
+	ChangeMemberRole(ctx context.Context, actorID, organizationID, userID int64, role domain.OrganizationRole) error
+	RemoveMember(ctx context.Context, actorID, organizationID, userID int64) error
+	SelectOrganization(ctx context.Context, session *domain.Session, organizationID int64) (*domain.OrganizationMembership, error)
+	CurrentOrganization(ctx context.Context, session *domain.Session) (*domain.OrganizationMembership, error)
+	InviteMember(ctx context.Context, actorID, organizationID int64, email string, role domain.OrganizationRole) (*domain.OrganizationInvitation, error)
+	ListInvitations(ctx context.Context, actorID, organizationID int64) ([]*domain.OrganizationInvitation, error)
+	GetInvitation(ctx context.Context, token string) (*domain.OrganizationInvitation, error)
+	AcceptInvitation(ctx context.Context, userID int64, token string) (*domain.OrganizationMembership, error)
+}
@@ -0,0 +1,115 @@
+package organization
+
+import (
+	"server/internal/domain"
+	"time"
+)
+
+type organizationDTO struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	JoinedAt  time.Time `json:"joined_at"`
+}
+
+func organizationFromDomain(membership *domain.OrganizationMembership) organizationDTO {
+	return organizationDTO{
+		ID:        membership.Organization.ID,
+		Name:      membership.Organization.Name,
+		Role:      string(membership.Role),
+		CreatedAt: membership.Organization.CreatedAt,
+		JoinedAt:  membership.JoinedAt,
+	}
+}
+
+type organizationsDTO struct {
+	Organizations []organizationDTO `json:"organizations"`
+}
+
+// currentOrganizationDTO has a null organization when none is selected.
+type currentOrganizationDTO struct {
+	Organization *organizationDTO `json:"organization"`
+}
+
+func currentFromDomain(membership *domain.OrganizationMembership) currentOrganizationDTO {
+	if membership == nil {
+		return currentOrganizationDTO{}
+	}
+	dto := organizationFromDomain(membership)
+	return currentOrganizationDTO{Organization: &dto}
+}
+
+type memberDTO struct {
+	UserID   int64     `json:"user_id"`
+	Email    string    `json:"email"`
+	FullName string    `json:"full_name"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+type membersDTO struct {
+	Members []memberDTO `json:"members"`
+}
+
+func membersFromDomain(members []*domain.OrganizationMember) membersDTO {
+	dto := membersDTO{Members: make([]memberDTO, 0, len(members))}
+	for _, member := range members {
+		dto.Members = append(dto.Members, memberDTO{
+			UserID:   member.UserID,
+			Email:    member.Email,
+			FullName: member.FullName,
+			Role:     string(member.Role),
+			JoinedAt: member.JoinedAt,
+		})
+	}
+	return dto
+}
+
+type invitationDTO struct {
+	ID               int64     `json:"id"`
+	OrganizationID   int64     `json:"organization_id"`
+	OrganizationName string    `json:"organization_name"`
+	Email            string    `json:"email"`
+	Role             string    `json:"role"`
+	CreatedAt        time.Time `json:"created_at"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+func invitationFromDomain(invitation *domain.OrganizationInvitation) invitationDTO {
+	return invitationDTO{
+		ID:               invitation.ID,
+		OrganizationID:   invitation.OrganizationID,
+		OrganizationName: invitation.OrganizationName,
+		Email:            invitation.Email,
+		Role:             string(invitation.Role),
+		CreatedAt:        invitation.CreatedAt,
+		ExpiresAt:        invitation.ExpiresAt,
+	}
+}
+
+type invitationsDTO struct {
+	Invitations []invitationDTO `json:"invitations"`
+}
+
+type createOrganizationRequest struct {
+	Name string `json:"name"`
+}
+
+// selectOrganizationRequest clears the selection with a null or zero id.
+type selectOrganizationRequest struct {
+	OrganizationID *int64 `json:"organization_id"`
+}
+
+type roleRequest struct {
+	Role string `json:"role"`
+}
+
+type inviteRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+type acceptInvitationRequest struct {
+	Token string `json:"token"`
+}
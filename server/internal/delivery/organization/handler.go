@@ -0,0 +1,17 @@
+package organization
+
+import (
+	"log/slog"
+)
+
+type Handler struct {
+	logger *slog.Logger
+	uc     OrganizationUC
+}
+
+func NewHandler(logger *slog.Logger, uc OrganizationUC) *Handler {
+	return &Handler{
+		logger: logger,
+		uc:     uc,
+	}
+}
@@ -0,0 +1,73 @@
+package organization
+
+import (
+	"encoding/json"
+	"net/http"
+	"server/internal/domain"
+	"server/internal/pkg/context"
+	"server/internal/pkg/httptools"
+)
+
+func (h *Handler) InviteMember(w http.ResponseWriter, r *http.Request) {
+	session := context.MustSessionFromContext(r.Context())
+
+	var req inviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httptools.WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	invitation, err := h.uc.InviteMember(r.Context(), session.UserID, pathID(r, "id"), req.Email, domain.OrganizationRole(req.Role))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	httptools.WriteJSONResponse(w, http.StatusCreated, invitationFromDomain(invitation))
+}
+
+func (h *Handler) ListInvitations(w http.ResponseWriter, r *http.Request) {
+	session := context.MustSessionFromContext(r.Context())
+
+	invitations, err := h.uc.ListInvitations(r.Context(), session.UserID, pathID(r, "id"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	dto := invitationsDTO{Invitations: make([]invitationDTO, 0, len(invitations))}
+	for _, invitation := range invitations {
+		dto.Invitations = append(dto.Invitations, invitationFromDomain(invitation))
+	}
+	httptools.WriteJSONResponse(w, http.StatusOK, dto)
+}
+
+// GetInvitation shows the invitation behind the `?token=` from an
+// invitation email before it is accepted.
+func (h *Handler) GetInvitation(w http.ResponseWriter, r *http.Request) {
+	invitation, err := h.uc.GetInvitation(r.Context(), r.URL.Query().Get("token"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	httptools.WriteJSONResponse(w, http.StatusOK, invitationFromDomain(invitation))
+}
+
+func (h *Handler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	session := context.MustSessionFromContext(r.Context())
+
+	var req acceptInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httptools.WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	membership, err := h.uc.AcceptInvitation(r.Context(), session.UserID, req.Token)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	httptools.WriteJSONResponse(w, http.StatusOK, organizationFromDomain(membership))
+}
@@ -0,0 +1,43 @@
+package organization
+
+import (
+	"log/slog"
+	"net/http"
+	"server/internal/pkg/context"
+	"server/internal/pkg/httptools"
+)
+
+type OrganizationMiddleware struct {
+	logger *slog.Logger
+	uc     OrganizationUC
+}
+
+func NewOrganizationMiddleware(logger *slog.Logger, uc OrganizationUC) *OrganizationMiddleware {
+	return &OrganizationMiddleware{
+		logger: logger,
+		uc:     uc,
+	}
+}
+
+// LoadOrganization puts the organization selected for the session into the
+// request context next to the session. It must run after RequireAuth.
+func (m *OrganizationMiddleware) LoadOrganization(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, ok := context.SessionFromContext(r.Context())
+		if !ok || session.OrganizationID == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		membership, err := m.uc.CurrentOrganization(r.Context(), session)
+		if err != nil {
+			m.logger.ErrorContext(r.Context(), "failed to load current organization", "error", err)
+			httptools.WriteJSONError(w, http.StatusInternalServerError, "failed to load current organization")
+			return
+		}
+		if membership != nil {
+			r = r.WithContext(context.WithOrganization(r.Context(), membership))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
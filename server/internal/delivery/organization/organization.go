@@ -0,0 +1,131 @@
+package organization
+
+import (
+	"encoding/json"
+	"net/http"
+	"server/internal/domain"
+	"server/internal/pkg/context"
+	"server/internal/pkg/httptools"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) ListOrganizations(w http.ResponseWriter, r *http.Request) {
+	session := context.MustSessionFromContext(r.Context())
+
+	memberships, err := h.uc.ListOrganizations(r.Context(), session.UserID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	dto := organizationsDTO{Organizations: make([]organizationDTO, 0, len(memberships))}
+	for _, membership := range memberships {
+		dto.Organizations = append(dto.Organizations, organizationFromDomain(membership))
+	}
+	httptools.WriteJSONResponse(w, http.StatusOK, dto)
+}
+
+func (h *Handler) CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	session := context.MustSessionFromContext(r.Context())
+
+	var req createOrganizationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httptools.WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	membership, err := h.uc.CreateOrganization(r.Context(), session.UserID, req.Name)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	httptools.WriteJSONResponse(w, http.StatusCreated, organizationFromDomain(membership))
+}
+
+// GetCurrentOrganization returns the organization selected for the session,
+// as loaded by the organization middleware.
+func (h *Handler) GetCurrentOrganization(w http.ResponseWriter, r *http.Request) {
+	membership, _ := context.OrganizationFromContext(r.Context())
+	httptools.WriteJSONResponse(w, http.StatusOK, currentFromDomain(membership))
+}
+
+func (h *Handler) SelectOrganization(w http.ResponseWriter, r *http.Request) {
+	session := context.MustSessionFromContext(r.Context())
+
+	var req selectOrganizationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httptools.WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	var organizationID int64
+	if req.OrganizationID != nil {
+		organizationID = *req.OrganizationID
+	}
+
+	membership, err := h.uc.SelectOrganization(r.Context(), session, organizationID)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	httptools.WriteJSONResponse(w, http.StatusOK, currentFromDomain(membership))
+}
+
+func (h *Handler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	session := context.MustSessionFromContext(r.Context())
+
+	members, err := h.uc.ListMembers(r.Context(), session.UserID, pathID(r, "id"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	httptools.WriteJSONResponse(w, http.StatusOK, membersFromDomain(members))
+}
+
+func (h *Handler) ChangeMemberRole(w http.ResponseWriter, r *http.Request) {
+	session := context.MustSessionFromContext(r.Context())
+
+	var req roleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httptools.WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	err := h.uc.ChangeMemberRole(r.Context(), session.UserID, pathID(r, "id"), pathID(r, "userID"), domain.OrganizationRole(req.Role))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveMember removes a member; members remove themselves to leave.
+func (h *Handler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	session := context.MustSessionFromContext(r.Context())
+
+	err := h.uc.RemoveMember(r.Context(), session.UserID, pathID(r, "id"), pathID(r, "userID"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	if !httptools.IsKnownError(err) {
+		h.logger.ErrorContext(r.Context(), "organization request failed", "error", err)
+	}
+	httptools.WriteError(w, err)
+}
+
+// pathID reads a numeric path variable; the routes only match digits.
+func pathID(r *http.Request, name string) int64 {
+	id, _ := strconv.ParseInt(mux.Vars(r)[name], 10, 64)
+	return id
+}
@@ -0,0 +1,141 @@
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"server/internal/domain"
+	"server/internal/pkg/context"
+	"server/internal/pkg/httptools"
+	"server/internal/pkg/identicon"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	avatarFormField = "avatar"
+	// multipartOverhead leaves room for boundaries and part headers on top
+	// of the file itself.
+	multipartOverhead = 64 << 10
+	// versionedAvatarMaxAge applies when the URL names the avatar version:
+	// a new upload gets a new URL, so the response never goes stale.
+	versionedAvatarMaxAge = 365 * 24 * 60 * 60
+)
+
+// UploadAvatar accepts a multipart/form-data body with the image in the
+// "avatar" field.
+func (h *Handler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	session := context.MustSessionFromContext(r.Context())
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.avatarMaxBytes+multipartOverhead)
+	file, _, err := r.FormFile(avatarFormField)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			httptools.WriteJSONError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("avatar must be at most %d bytes", h.avatarMaxBytes))
+			return
+		}
+		httptools.WriteJSONError(w, http.StatusBadRequest, "multipart field \"avatar\" with the image is required")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, h.avatarMaxBytes+1))
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to read avatar upload", "error", err)
+		httptools.WriteJSONError(w, http.StatusBadRequest, "failed to read avatar")
+		return
+	}
+
+	avatarID, err := h.uc.UploadAvatar(r.Context(), session.UserID, data)
+	if err != nil {
+		if !httptools.IsKnownError(err) {
+			h.logger.ErrorContext(r.Context(), "failed to upload avatar", "error", err)
+		}
+		httptools.WriteError(w, err)
+		return
+	}
+
+	httptools.WriteJSONResponse(w, http.StatusOK, avatarResponseDTO{
+		Message:   "avatar updated",
+		AvatarURL: avatarURL(session.UserID, avatarID),
+	})
+}
+
+// GetUserAvatar serves a thumbnail, `?size=` picks one of the generated
+// sizes. Users without a picture get a generated one, `?format=svg` asks for
+// it as SVG instead of PNG. URLs carrying `?v=<avatar id>` are cached for
+// good, anything else is revalidated through the ETag. The route works
+// without a session; pictures the viewer may not see are reported as not
+// found.
+func (h *Handler) GetUserAvatar(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		httptools.WriteJSONError(w, http.StatusNotFound, "avatar not found")
+		return
+	}
+
+	size := 0
+	if raw := r.URL.Query().Get("size"); raw != "" {
+		size, err = strconv.Atoi(raw)
+		if err != nil {
+			httptools.WriteJSONError(w, http.StatusBadRequest, "invalid avatar size")
+			return
+		}
+	}
+
+	var viewerID int64
+	if session, ok := context.SessionFromContext(r.Context()); ok {
+		viewerID = session.UserID
+	}
+
+	avatar, err := h.uc.GetAvatar(r.Context(), viewerID, userID, size, r.URL.Query().Get("format"))
+	if err != nil {
+		if !httptools.IsKnownError(err) {
+			h.logger.ErrorContext(r.Context(), "failed to get avatar", "error", err)
+		}
+		httptools.WriteError(w, err)
+		return
+	}
+
+	etag := avatarETag(avatar)
+	w.Header().Set("ETag", etag)
+	if r.URL.Query().Get("v") == avatar.AvatarID {
+		w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d, immutable", versionedAvatarMaxAge))
+	} else {
+		w.Header().Set("Cache-Control", "private, no-cache")
+	}
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", avatar.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(avatar.Data)))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	// Generated avatars may be SVG; opened directly they must not run
+	// anything.
+	w.Header().Set("Content-Security-Policy", "default-src 'none'")
+	w.WriteHeader(http.StatusOK)
+	w.Write(avatar.Data)
+}
+
+// avatarETag is strong: the bytes are fixed by the avatar ID, the size and
+// the content type, which tells a generated SVG apart from its PNG.
+func avatarETag(avatar *domain.AvatarImage) string {
+	subtype := strings.TrimPrefix(avatar.ContentType, "image/")
+	return fmt.Sprintf(`"%s-%d-%s"`, avatar.AvatarID, avatar.Size, subtype)
+}
+
+func avatarURL(userID int64, avatarID string) string {
+	return fmt.Sprintf("/api/users/%d/avatar?v=%s", userID, avatarID)
+}
+
+// generatedAvatarURL points at the SVG rendering, which stays sharp at any
+// display size; the version changes along with the initials.
+func generatedAvatarURL(userID int64, fullName string) string {
+	return fmt.Sprintf("/api/users/%d/avatar?format=%s&v=%s", userID, domain.AvatarFormatSVG, identicon.New(userID, fullName).Version())
+}
@@ -3,9 +3,25 @@ package profile
 import (
 	"context"
 	"server/internal/domain"
+	"time"
 )
 
 type ProfileUC interface {
 	GetProfile(ctx context.Context, userID int64) (*domain.Profile, error)
-	UpdateProfile(ctx context.Context, userID int64, profile *domain.Profile) error
+	UpdateProfile(ctx context.Context, userID, version int64, profile *domain.Profile) (*domain.Profile, error)
+	PatchProfile(ctx context.Context, userID, version int64, patch *domain.ProfilePatch) (*domain.Profile, error)
+	RequestAccountDeletion(ctx context.Context, session *domain.Session, password string) (time.Time, error)
+	RequestDataExport(ctx context.Context, userID int64) (*domain.DataExport, error)
+	GetLatestDataExport(ctx context.Context, userID int64) (*domain.DataExport, error)
+	GetDataExport(ctx context.Context, userID int64, exportID string) (*domain.DataExport, error)
+	RequestEmailChange(ctx context.Context, userID int64, newEmail string) (*domain.EmailChangeRequest, error)
+	ConfirmEmailChange(ctx context.Context, token string) error
+	CancelEmailChange(ctx context.Context, token string) error
+	RequestPhoneVerification(ctx context.Context, userID int64) (time.Time, error)
+	ConfirmPhoneVerification(ctx context.Context, userID int64, code string) error
+	UploadAvatar(ctx context.Context, userID int64, data []byte) (string, error)
+	GetAvatar(ctx context.Context, viewerID, userID int64, size int, format string) (*domain.AvatarImage, error)
+	GetPublicProfile(ctx context.Context, handle string, viewerID int64) (*domain.PublicProfile, error)
+	ListProfileHistory(ctx context.Context, userID, beforeID int64, limit int) ([]*domain.ProfileChange, error)
+	SearchDirectory(ctx context.Context, viewerID int64, query string, afterID int64, limit int) ([]*domain.PublicProfile, error)
 }
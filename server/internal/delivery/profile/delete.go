@@ -0,0 +1,49 @@
+package profile
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"server/internal/pkg/context"
+	"server/internal/pkg/httptools"
+	"time"
+)
+
+func (h *Handler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	session := context.MustSessionFromContext(r.Context())
+	dto := deleteAccountDTO{}
+	err := json.NewDecoder(r.Body).Decode(&dto)
+	if err != nil && !errors.Is(err, io.EOF) {
+		h.logger.WarnContext(r.Context(), "failed to decode request body", "error", err)
+		httptools.WriteJSONError(w, http.StatusBadRequest, "bad input data")
+		return
+	}
+
+	deleteAt, err := h.uc.RequestAccountDeletion(r.Context(), session, dto.Password)
+	if err != nil {
+		if !httptools.IsKnownError(err) {
+			h.logger.ErrorContext(r.Context(), "failed to request account deletion", "error", err)
+		}
+		httptools.WriteError(w, err)
+		return
+	}
+
+	secure := r.TLS != nil
+	http.SetCookie(w, &http.Cookie{
+		Name:     "auth_token",
+		Value:    "",
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   -1,
+		Expires:  time.Unix(0, 0),
+	})
+
+	h.logger.InfoContext(r.Context(), "account deletion scheduled", "user_id", session.UserID, "delete_at", deleteAt)
+	httptools.WriteJSONResponse(w, http.StatusAccepted, deleteAccountResponseDTO{
+		Message:             "account scheduled for deletion",
+		DeletionScheduledAt: deleteAt,
+	})
+}
@@ -0,0 +1,59 @@
+package profile
+
+import (
+	"net/http"
+	"server/internal/pkg/context"
+	"server/internal/pkg/httptools"
+	"strconv"
+)
+
+const (
+	defaultDirectoryLimit = 20
+	maxDirectoryLimit     = 100
+)
+
+// SearchDirectory looks up other users by name or email for signed-in
+// users: `?q=` is the search text, `cursor` continues a previous page.
+func (h *Handler) SearchDirectory(w http.ResponseWriter, r *http.Request) {
+	session := context.MustSessionFromContext(r.Context())
+
+	limit, afterID, ok := parseDirectoryPaging(r)
+	if !ok {
+		httptools.WriteJSONError(w, http.StatusBadRequest, "invalid paging parameters")
+		return
+	}
+
+	profiles, err := h.uc.SearchDirectory(r.Context(), session.UserID, r.URL.Query().Get("q"), afterID, limit)
+	if err != nil {
+		if !httptools.IsKnownError(err) {
+			h.logger.ErrorContext(r.Context(), "failed to search directory", "error", err)
+		}
+		httptools.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "private, no-cache")
+	httptools.WriteJSONResponse(w, http.StatusOK, directoryFromDomain(profiles, limit))
+}
+
+func parseDirectoryPaging(r *http.Request) (int, int64, bool) {
+	limit := defaultDirectoryLimit
+	if val := r.URL.Query().Get("limit"); val != "" {
+		parsed, err := strconv.Atoi(val)
+		if err != nil || parsed <= 0 {
+			return 0, 0, false
+		}
+		limit = min(parsed, maxDirectoryLimit)
+	}
+
+	var afterID int64
+	if val := r.URL.Query().Get("cursor"); val != "" {
+		parsed, err := strconv.ParseInt(val, 10, 64)
+		if err != nil || parsed <= 0 {
+			return 0, 0, false
+		}
+		afterID = parsed
+	}
+
+	return limit, afterID, true
+}
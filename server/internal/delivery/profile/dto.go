@@ -1,18 +1,62 @@
 package profile
 
-import "server/internal/domain"
+import (
+	"encoding/json"
+	"server/internal/domain"
+	"strconv"
+	"time"
+)
 
 type profileDTO struct {
-	FullName string `json:"full_name"`
-	Phone    string `json:"phone"`
-	Email    string `json:"email"`
+	FullName        string     `json:"full_name"`
+	Phone           string     `json:"phone"`
+	PhoneVerifiedAt *time.Time `json:"phone_verified_at,omitempty"`
+	Email           string     `json:"email"`
+	PendingEmail    string     `json:"pending_email,omitempty"`
+	AvatarURL       string     `json:"avatar_url,omitempty"`
+	// CustomFields is omitted from a PUT body to leave the values as they
+	// are; when present it replaces all of them.
+	CustomFields map[string]string `json:"custom_fields"`
+	Handle       string            `json:"handle"`
+	Public       bool              `json:"public"`
+	// Visibility members left out of a PUT body are kept.
+	Visibility visibilityDTO `json:"visibility"`
+}
+
+type visibilityDTO struct {
+	FullName string `json:"full_name,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Phone    string `json:"phone,omitempty"`
+	Avatar   string `json:"avatar,omitempty"`
+}
+
+func (dto visibilityDTO) ToDomain() domain.ProfilePrivacy {
+	return domain.ProfilePrivacy{
+		FullName: domain.Visibility(dto.FullName),
+		Email:    domain.Visibility(dto.Email),
+		Phone:    domain.Visibility(dto.Phone),
+		Avatar:   domain.Visibility(dto.Avatar),
+	}
+}
+
+func visibilityFromDomain(privacy domain.ProfilePrivacy) visibilityDTO {
+	return visibilityDTO{
+		FullName: string(privacy.FullName),
+		Email:    string(privacy.Email),
+		Phone:    string(privacy.Phone),
+		Avatar:   string(privacy.Avatar),
+	}
 }
 
 func (dto *profileDTO) ToDomain() *domain.Profile {
 	return &domain.Profile{
-		FullName: dto.FullName,
-		Phone:    dto.Phone,
-		Email:    dto.Email,
+		FullName:     dto.FullName,
+		Phone:        dto.Phone,
+		Email:        dto.Email,
+		CustomFields: dto.CustomFields,
+		Handle:       dto.Handle,
+		Public:       dto.Public,
+		Privacy:      dto.Visibility.ToDomain(),
 	}
 }
 
@@ -20,4 +64,289 @@ func (dto *profileDTO) FromDomain(profile *domain.Profile) {
 	dto.FullName = profile.FullName
 	dto.Phone = profile.Phone
 	dto.Email = profile.Email
+	dto.PhoneVerifiedAt = profile.PhoneVerifiedAt
+	dto.PendingEmail = profile.PendingEmail
+	if profile.AvatarID != "" {
+		dto.AvatarURL = avatarURL(profile.UserID, profile.AvatarID)
+	} else {
+		dto.AvatarURL = generatedAvatarURL(profile.UserID, profile.FullName)
+	}
+	dto.CustomFields = profile.CustomFields
+	if dto.CustomFields == nil {
+		dto.CustomFields = map[string]string{}
+	}
+	dto.Handle = profile.Handle
+	dto.Public = profile.Public
+	dto.Visibility = visibilityFromDomain(profile.Privacy)
+}
+
+// profilePutDTO is the body of a full update. Every field is replaced, so
+// handle and public must be sent: a client written before they existed
+// would otherwise clear them without noticing.
+type profilePutDTO struct {
+	profileDTO
+	missing []string
+}
+
+func (dto *profilePutDTO) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &dto.profileDTO); err != nil {
+		return err
+	}
+	var present struct {
+		Handle *json.RawMessage `json:"handle"`
+		Public *json.RawMessage `json:"public"`
+	}
+	if err := json.Unmarshal(data, &present); err != nil {
+		return err
+	}
+	if present.Handle == nil {
+		dto.missing = append(dto.missing, "handle")
+	}
+	if present.Public == nil {
+		dto.missing = append(dto.missing, "public")
+	}
+	return nil
+}
+
+// Validate reports the required fields left out of the body.
+func (dto *profilePutDTO) Validate() error {
+	if len(dto.missing) == 0 {
+		return nil
+	}
+	fieldErrors := domain.FieldErrors{}
+	for _, field := range dto.missing {
+		fieldErrors[field] = "is required; send the current value or use PATCH"
+	}
+	return fieldErrors
+}
+
+// optionalString tells a field missing from the body (Set is false) from one
+// set to null or a value.
+type optionalString struct {
+	Set   bool
+	Value string
+}
+
+func (o *optionalString) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if string(data) == "null" {
+		o.Value = ""
+		return nil
+	}
+	return json.Unmarshal(data, &o.Value)
+}
+
+func (o optionalString) ptr() *string {
+	if !o.Set {
+		return nil
+	}
+	return &o.Value
+}
+
+type profilePatchDTO struct {
+	FullName optionalString `json:"full_name"`
+	Phone    optionalString `json:"phone"`
+	Email    optionalString `json:"email"`
+	// CustomFields is merged member by member: a null member clears that
+	// field, members left out are kept.
+	CustomFields map[string]optionalString `json:"custom_fields"`
+	Handle       optionalString            `json:"handle"`
+	Public       *bool                     `json:"public"`
+	// Visibility is merged member by member as well; visibilities cannot
+	// be cleared, so null members are ignored.
+	Visibility *visibilityDTO `json:"visibility"`
+}
+
+func (dto *profilePatchDTO) ToDomain() *domain.ProfilePatch {
+	patch := &domain.ProfilePatch{
+		FullName: dto.FullName.ptr(),
+		Phone:    dto.Phone.ptr(),
+		Email:    dto.Email.ptr(),
+		Handle:   dto.Handle.ptr(),
+		Public:   dto.Public,
+	}
+	if dto.Visibility != nil {
+		patch.Privacy = dto.Visibility.ToDomain()
+	}
+	if len(dto.CustomFields) > 0 {
+		patch.CustomFields = make(map[string]string, len(dto.CustomFields))
+		for key, value := range dto.CustomFields {
+			patch.CustomFields[key] = value.Value
+		}
+	}
+	return patch
+}
+
+type deleteAccountDTO struct {
+	Password string `json:"password"`
+}
+
+type deleteAccountResponseDTO struct {
+	Message             string    `json:"message"`
+	DeletionScheduledAt time.Time `json:"deletion_scheduled_at"`
+}
+
+type emailChangeDTO struct {
+	Email string `json:"email"`
+}
+
+type emailChangeResponseDTO struct {
+	Message   string    `json:"message"`
+	NewEmail  string    `json:"new_email"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type phoneVerificationResponseDTO struct {
+	Message   string    `json:"message"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type avatarResponseDTO struct {
+	Message   string `json:"message"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+type phoneCodeDTO struct {
+	Code string `json:"code"`
+}
+
+type dataExportDTO struct {
+	ID          string     `json:"id"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+// FromDomain reports exports past their download window as "expired" even
+// before the cleanup job has removed them.
+func (dto *dataExportDTO) FromDomain(export *domain.DataExport, now time.Time) {
+	dto.ID = export.ID
+	dto.Status = string(export.Status)
+	if export.IsExpired(now) {
+		dto.Status = "expired"
+	}
+	dto.CreatedAt = export.CreatedAt
+	dto.CompletedAt = export.CompletedAt
+	dto.ExpiresAt = export.ExpiresAt
+}
+
+type profileChangeDTO struct {
+	ID        int64     `json:"id"`
+	Field     string    `json:"field"`
+	OldValue  *string   `json:"old_value"`
+	NewValue  *string   `json:"new_value"`
+	SessionID string    `json:"session_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type profileHistoryDTO struct {
+	Changes    []profileChangeDTO `json:"changes"`
+	NextBefore int64              `json:"next_before,omitempty"`
+}
+
+// historyFromDomain reports unset values as null and sets NextBefore when a
+// full page came back, so clients know to ask for older changes.
+func historyFromDomain(changes []*domain.ProfileChange, limit int) profileHistoryDTO {
+	dto := profileHistoryDTO{Changes: make([]profileChangeDTO, 0, len(changes))}
+	for _, change := range changes {
+		dto.Changes = append(dto.Changes, profileChangeDTO{
+			ID:        change.ID,
+			Field:     change.Field,
+			OldValue:  nullableString(change.OldValue),
+			NewValue:  nullableString(change.NewValue),
+			SessionID: change.SessionID,
+			CreatedAt: change.CreatedAt,
+		})
+	}
+	if limit > 0 && len(changes) == limit {
+		dto.NextBefore = changes[len(changes)-1].ID
+	}
+	return dto
+}
+
+func nullableString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+type publicProfileDTO struct {
+	Handle       string                  `json:"handle"`
+	FullName     string                  `json:"full_name,omitempty"`
+	Email        string                  `json:"email,omitempty"`
+	Phone        string                  `json:"phone,omitempty"`
+	AvatarURL    string                  `json:"avatar_url,omitempty"`
+	CustomFields []publicProfileFieldDTO `json:"custom_fields"`
+}
+
+type publicProfileFieldDTO struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// FromDomain leaves out what the viewer may not see; the generated avatar
+// is drawn from the visible name only.
+func (dto *publicProfileDTO) FromDomain(profile *domain.PublicProfile) {
+	dto.Handle = profile.Handle
+	dto.FullName = profile.FullName
+	dto.Email = profile.Email
+	dto.Phone = profile.Phone
+	dto.AvatarURL = visibleAvatarURL(profile)
+	dto.CustomFields = make([]publicProfileFieldDTO, 0, len(profile.CustomFields))
+	for _, field := range profile.CustomFields {
+		dto.CustomFields = append(dto.CustomFields, publicProfileFieldDTO{
+			Key:   field.Key,
+			Label: field.Label,
+			Type:  string(field.Type),
+			Value: field.Value,
+		})
+	}
+}
+
+// visibleAvatarURL links the picture if the viewer may see it, the generated
+// one being drawn from the visible name only.
+func visibleAvatarURL(profile *domain.PublicProfile) string {
+	if !profile.AvatarVisible {
+		return ""
+	}
+	if profile.AvatarID != "" {
+		return avatarURL(profile.UserID, profile.AvatarID)
+	}
+	return generatedAvatarURL(profile.UserID, profile.FullName)
+}
+
+type directoryEntryDTO struct {
+	Handle    string `json:"handle"`
+	FullName  string `json:"full_name,omitempty"`
+	Email     string `json:"email,omitempty"`
+	Phone     string `json:"phone,omitempty"`
+	AvatarURL string `json:"avatar_url,omitempty"`
+}
+
+type directoryDTO struct {
+	Users []directoryEntryDTO `json:"users"`
+	// NextCursor is set when a full page was returned; pass it back as
+	// `cursor` for the next one.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+func directoryFromDomain(profiles []*domain.PublicProfile, limit int) directoryDTO {
+	dto := directoryDTO{Users: make([]directoryEntryDTO, 0, len(profiles))}
+	for _, profile := range profiles {
+		dto.Users = append(dto.Users, directoryEntryDTO{
+			Handle:    profile.Handle,
+			FullName:  profile.FullName,
+			Email:     profile.Email,
+			Phone:     profile.Phone,
+			AvatarURL: visibleAvatarURL(profile),
+		})
+	}
+	if limit > 0 && len(profiles) == limit {
+		dto.NextCursor = strconv.FormatInt(profiles[len(profiles)-1].UserID, 10)
+	}
+	return dto
 }
@@ -0,0 +1,85 @@
+package profile
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"server/internal/domain"
+	"server/internal/pkg/context"
+	"server/internal/pkg/httptools"
+)
+
+func (h *Handler) RequestEmailChange(w http.ResponseWriter, r *http.Request) {
+	session := context.MustSessionFromContext(r.Context())
+	dto := emailChangeDTO{}
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		h.logger.WarnContext(r.Context(), "failed to decode request body", "error", err)
+		httptools.WriteJSONError(w, http.StatusBadRequest, "bad input data")
+		return
+	}
+
+	request, err := h.uc.RequestEmailChange(r.Context(), session.UserID, dto.Email)
+	if err != nil {
+		if !httptools.IsKnownError(err) {
+			h.logger.ErrorContext(r.Context(), "failed to request email change", "error", err)
+		}
+		httptools.WriteError(w, err)
+		return
+	}
+
+	httptools.WriteJSONResponse(w, http.StatusAccepted, emailChangeResponseDTO{
+		Message:   "confirmation link sent to the new email address",
+		NewEmail:  request.NewEmail,
+		ExpiresAt: request.ExpiresAt,
+	})
+}
+
+// ConfirmEmailChange is opened from the link in the confirmation email, so it
+// answers with a redirect to the frontend rather than JSON.
+func (h *Handler) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	err := h.uc.ConfirmEmailChange(r.Context(), r.URL.Query().Get("token"))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrEmailChangeNotFound):
+			h.redirectToFrontend(w, r, "/profile", "error", "This confirmation link is invalid or was already used.")
+		case errors.Is(err, domain.ErrEmailChangeExpired):
+			h.redirectToFrontend(w, r, "/profile", "error", "This confirmation link has expired. Please request the change again.")
+		case errors.Is(err, domain.ErrUserAlreadyExists):
+			h.redirectToFrontend(w, r, "/profile", "error", "This email address is already in use.")
+		default:
+			h.logger.ErrorContext(r.Context(), "failed to confirm email change", "error", err)
+			h.redirectToFrontend(w, r, "/profile", "error", "Failed to confirm email change.")
+		}
+		return
+	}
+
+	h.redirectToFrontend(w, r, "/profile", "success", "Your email address has been updated.")
+}
+
+func (h *Handler) CancelEmailChange(w http.ResponseWriter, r *http.Request) {
+	err := h.uc.CancelEmailChange(r.Context(), r.URL.Query().Get("token"))
+	if err != nil {
+		if errors.Is(err, domain.ErrEmailChangeNotFound) {
+			h.redirectToFrontend(w, r, "/login", "error", "This link is invalid or the change was already completed.")
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "failed to cancel email change", "error", err)
+		h.redirectToFrontend(w, r, "/login", "error", "Failed to cancel email change.")
+		return
+	}
+
+	h.redirectToFrontend(w, r, "/login", "success", "The email change was cancelled and all sessions were signed out. Please log in again.")
+}
+
+func (h *Handler) redirectToFrontend(w http.ResponseWriter, r *http.Request, path, key, message string) {
+	redirectURL, err := url.Parse(h.frontendURL)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to parse frontend url", "error", err)
+		httptools.WriteJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	redirectURL.Path = path
+	redirectURL.RawQuery = url.Values{key: {message}}.Encode()
+	http.Redirect(w, r, redirectURL.String(), http.StatusSeeOther)
+}
@@ -0,0 +1,38 @@
+package profile
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+func profileETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ifMatchVersion extracts the profile version from the If-Match header. ok is
+// false when the header is missing or holds no ETag issued by profileETag;
+// weak tags never match since If-Match uses strong comparison (RFC 9110
+// section 13.1.1). wildcard is true for "If-Match: *", which matches whatever
+// version is current.
+func ifMatchVersion(r *http.Request) (version int64, wildcard, present, ok bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		return 0, false, false, false
+	}
+	if header == "*" {
+		return 0, true, true, true
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+		if err == nil {
+			return version, false, true, true
+		}
+	}
+	return 0, false, true, false
+}
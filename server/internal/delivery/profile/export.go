@@ -0,0 +1,71 @@
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"server/internal/domain"
+	"server/internal/pkg/context"
+	"server/internal/pkg/httptools"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) RequestDataExport(w http.ResponseWriter, r *http.Request) {
+	session := context.MustSessionFromContext(r.Context())
+
+	export, err := h.uc.RequestDataExport(r.Context(), session.UserID)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to request data export", "error", err)
+		httptools.WriteJSONError(w, http.StatusInternalServerError, "failed to request data export")
+		return
+	}
+
+	dto := dataExportDTO{}
+	dto.FromDomain(export, time.Now())
+	httptools.WriteJSONResponse(w, http.StatusAccepted, dto)
+}
+
+func (h *Handler) GetLatestDataExport(w http.ResponseWriter, r *http.Request) {
+	session := context.MustSessionFromContext(r.Context())
+
+	export, err := h.uc.GetLatestDataExport(r.Context(), session.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrExportNotFound) {
+			httptools.WriteError(w, err)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "failed to get data export", "error", err)
+		httptools.WriteJSONError(w, http.StatusInternalServerError, "failed to get data export")
+		return
+	}
+
+	dto := dataExportDTO{}
+	dto.FromDomain(export, time.Now())
+	httptools.WriteJSONResponse(w, http.StatusOK, dto)
+}
+
+func (h *Handler) DownloadDataExport(w http.ResponseWriter, r *http.Request) {
+	session := context.MustSessionFromContext(r.Context())
+	exportID := mux.Vars(r)["id"]
+
+	export, err := h.uc.GetDataExport(r.Context(), session.UserID, exportID)
+	if err != nil {
+		if !httptools.IsKnownError(err) {
+			h.logger.ErrorContext(r.Context(), "failed to get data export", "error", err)
+		}
+		httptools.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="data-export-%s.json"`, export.ID))
+	w.Header().Set("Content-Length", strconv.Itoa(len(export.Archive)))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(export.Archive); err != nil {
+		h.logger.WarnContext(r.Context(), "failed to write data export", "error", err)
+	}
+}
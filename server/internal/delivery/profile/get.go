@@ -11,7 +11,7 @@ func (h *Handler) GetProfile(w http.ResponseWriter, r *http.Request) {
 	profile, err := h.uc.GetProfile(r.Context(), session.UserID)
 
 	if err != nil {
-		h.logger.Error("failed to get profile", "error", err)
+		h.logger.ErrorContext(r.Context(), "failed to get profile", "error", err)
 		httptools.WriteJSONError(w, http.StatusInternalServerError, "failed to get profile")
 		return
 	}
@@ -19,5 +19,6 @@ func (h *Handler) GetProfile(w http.ResponseWriter, r *http.Request) {
 	dto := profileDTO{}
 	dto.FromDomain(profile)
 
+	w.Header().Set("ETag", profileETag(profile.Version))
 	httptools.WriteJSONResponse(w, http.StatusOK, dto)
 }
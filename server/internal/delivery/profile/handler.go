@@ -5,13 +5,17 @@ import (
 )
 
 type Handler struct {
-	logger *slog.Logger
-	uc     ProfileUC
+	logger         *slog.Logger
+	uc             ProfileUC
+	frontendURL    string
+	avatarMaxBytes int64
 }
 
-func NewHandler(logger *slog.Logger, uc ProfileUC) *Handler {
+func NewHandler(logger *slog.Logger, uc ProfileUC, frontendURL string, avatarMaxBytes int64) *Handler {
 	return &Handler{
-		logger: logger,
-		uc:     uc,
+		logger:         logger,
+		uc:             uc,
+		frontendURL:    frontendURL,
+		avatarMaxBytes: avatarMaxBytes,
 	}
 }
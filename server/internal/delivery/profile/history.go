@@ -0,0 +1,71 @@
+package profile
+
+import (
+	"net/http"
+	"server/internal/pkg/context"
+	"server/internal/pkg/httptools"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultHistoryLimit = 50
+	// maxHistoryLimit matches the repository's cap, so a full page always
+	// comes with a cursor to the next one.
+	maxHistoryLimit = 500
+)
+
+func (h *Handler) GetProfileHistory(w http.ResponseWriter, r *http.Request) {
+	session := context.MustSessionFromContext(r.Context())
+	h.writeProfileHistory(w, r, session.UserID)
+}
+
+// GetUserProfileHistory is the administrator view of any user's history.
+func (h *Handler) GetUserProfileHistory(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		httptools.WriteJSONError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+	h.writeProfileHistory(w, r, userID)
+}
+
+func (h *Handler) writeProfileHistory(w http.ResponseWriter, r *http.Request, userID int64) {
+	limit, beforeID, ok := parseHistoryPaging(r)
+	if !ok {
+		httptools.WriteJSONError(w, http.StatusBadRequest, "invalid paging parameters")
+		return
+	}
+
+	changes, err := h.uc.ListProfileHistory(r.Context(), userID, beforeID, limit)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to list profile history", "error", err)
+		httptools.WriteJSONError(w, http.StatusInternalServerError, "failed to list profile history")
+		return
+	}
+
+	httptools.WriteJSONResponse(w, http.StatusOK, historyFromDomain(changes, limit))
+}
+
+func parseHistoryPaging(r *http.Request) (int, int64, bool) {
+	limit := defaultHistoryLimit
+	if val := r.URL.Query().Get("limit"); val != "" {
+		parsed, err := strconv.Atoi(val)
+		if err != nil || parsed <= 0 {
+			return 0, 0, false
+		}
+		limit = min(parsed, maxHistoryLimit)
+	}
+
+	var beforeID int64
+	if val := r.URL.Query().Get("before"); val != "" {
+		parsed, err := strconv.ParseInt(val, 10, 64)
+		if err != nil || parsed <= 0 {
+			return 0, 0, false
+		}
+		beforeID = parsed
+	}
+
+	return limit, beforeID, true
+}
@@ -0,0 +1,47 @@
+package profile
+
+import (
+	"encoding/json"
+	"net/http"
+	"server/internal/pkg/context"
+	"server/internal/pkg/httptools"
+)
+
+func (h *Handler) RequestPhoneVerification(w http.ResponseWriter, r *http.Request) {
+	session := context.MustSessionFromContext(r.Context())
+
+	expiresAt, err := h.uc.RequestPhoneVerification(r.Context(), session.UserID)
+	if err != nil {
+		if !httptools.IsKnownError(err) {
+			h.logger.ErrorContext(r.Context(), "failed to request phone verification", "error", err)
+		}
+		httptools.WriteError(w, err)
+		return
+	}
+
+	httptools.WriteJSONResponse(w, http.StatusAccepted, phoneVerificationResponseDTO{
+		Message:   "verification code sent",
+		ExpiresAt: expiresAt,
+	})
+}
+
+func (h *Handler) ConfirmPhoneVerification(w http.ResponseWriter, r *http.Request) {
+	session := context.MustSessionFromContext(r.Context())
+	dto := phoneCodeDTO{}
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		h.logger.WarnContext(r.Context(), "failed to decode request body", "error", err)
+		httptools.WriteJSONError(w, http.StatusBadRequest, "bad input data")
+		return
+	}
+
+	err := h.uc.ConfirmPhoneVerification(r.Context(), session.UserID, dto.Code)
+	if err != nil {
+		if !httptools.IsKnownError(err) {
+			h.logger.ErrorContext(r.Context(), "failed to confirm phone verification", "error", err)
+		}
+		httptools.WriteError(w, err)
+		return
+	}
+
+	httptools.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "phone number verified"})
+}
@@ -0,0 +1,41 @@
+package profile
+
+import (
+	"errors"
+	"net/http"
+	"server/internal/domain"
+	"server/internal/pkg/context"
+	"server/internal/pkg/httptools"
+
+	"github.com/gorilla/mux"
+)
+
+// GetPublicProfile shows the profile published under a handle. It works
+// without a session; signed-in viewers may see more, depending on the
+// owner's visibility settings.
+func (h *Handler) GetPublicProfile(w http.ResponseWriter, r *http.Request) {
+	var viewerID int64
+	if session, ok := context.SessionFromContext(r.Context()); ok {
+		viewerID = session.UserID
+	}
+
+	profile, err := h.uc.GetPublicProfile(r.Context(), mux.Vars(r)["handle"], viewerID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotExists) {
+			httptools.WriteError(w, err)
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "failed to get public profile", "error", err)
+		httptools.WriteJSONError(w, http.StatusInternalServerError, "failed to get profile")
+		return
+	}
+
+	dto := publicProfileDTO{}
+	dto.FromDomain(profile)
+
+	// What is shown depends on who is asking, by session cookie or by
+	// bearer token, so shared caches must not keep it.
+	w.Header().Set("Cache-Control", "private, no-cache")
+	w.Header().Set("Vary", "Cookie, Authorization")
+	httptools.WriteJSONResponse(w, http.StatusOK, dto)
+}
@@ -2,25 +2,103 @@ package profile
 
 import (
 	"encoding/json"
+	"mime"
 	"net/http"
 	"server/internal/pkg/context"
 	"server/internal/pkg/httptools"
 )
 
+const mergePatchContentType = "application/merge-patch+json"
+
 func (h *Handler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 	session := context.MustSessionFromContext(r.Context())
-	profileDTO := profileDTO{}
-	err := json.NewDecoder(r.Body).Decode(&profileDTO)
+	version, ok := h.requireIfMatch(w, r, session.UserID)
+	if !ok {
+		return
+	}
+
+	putDTO := profilePutDTO{}
+	err := json.NewDecoder(r.Body).Decode(&putDTO)
 	if err != nil {
-		h.logger.Error("failed to decode request body", "error", err)
+		h.logger.ErrorContext(r.Context(), "failed to decode request body", "error", err)
 		httptools.WriteJSONError(w, http.StatusBadRequest, "bad input data")
 		return
 	}
-	err = h.uc.UpdateProfile(r.Context(), session.UserID, profileDTO.ToDomain())
+	if err := putDTO.Validate(); err != nil {
+		httptools.WriteError(w, err)
+		return
+	}
+	profile, err := h.uc.UpdateProfile(r.Context(), session.UserID, version, putDTO.ToDomain())
 	if err != nil {
-		h.logger.Error("failed to update profile", "error", err)
-		httptools.WriteJSONError(w, http.StatusInternalServerError, "failed to update profile")
+		h.writeProfileUpdateError(w, r, err)
 		return
 	}
+	w.Header().Set("ETag", profileETag(profile.Version))
 	httptools.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "profile updated successfully"})
 }
+
+// PatchProfile applies a JSON merge patch (RFC 7396): fields left out of the
+// body are not touched, null clears a field.
+func (h *Handler) PatchProfile(w http.ResponseWriter, r *http.Request) {
+	session := context.MustSessionFromContext(r.Context())
+	version, ok := h.requireIfMatch(w, r, session.UserID)
+	if !ok {
+		return
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || (mediaType != mergePatchContentType && mediaType != "application/json") {
+		httptools.WriteJSONError(w, http.StatusUnsupportedMediaType, "content type must be "+mergePatchContentType)
+		return
+	}
+
+	patchDTO := profilePatchDTO{}
+	err = json.NewDecoder(r.Body).Decode(&patchDTO)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to decode request body", "error", err)
+		httptools.WriteJSONError(w, http.StatusBadRequest, "bad input data")
+		return
+	}
+
+	profile, err := h.uc.PatchProfile(r.Context(), session.UserID, version, patchDTO.ToDomain())
+	if err != nil {
+		h.writeProfileUpdateError(w, r, err)
+		return
+	}
+
+	responseDTO := profileDTO{}
+	responseDTO.FromDomain(profile)
+	w.Header().Set("ETag", profileETag(profile.Version))
+	httptools.WriteJSONResponse(w, http.StatusOK, responseDTO)
+}
+
+// requireIfMatch makes updates conditional so that two tabs editing the same
+// profile cannot silently overwrite each other. "If-Match: *" asks only that
+// the profile exists, so it is given the current version.
+func (h *Handler) requireIfMatch(w http.ResponseWriter, r *http.Request, userID int64) (int64, bool) {
+	version, wildcard, present, ok := ifMatchVersion(r)
+	if !present {
+		httptools.WriteJSONError(w, http.StatusPreconditionRequired, "If-Match header with the profile ETag is required")
+		return 0, false
+	}
+	if !ok {
+		httptools.WriteJSONError(w, http.StatusPreconditionFailed, "profile was changed elsewhere")
+		return 0, false
+	}
+	if wildcard {
+		profile, err := h.uc.GetProfile(r.Context(), userID)
+		if err != nil {
+			h.writeProfileUpdateError(w, r, err)
+			return 0, false
+		}
+		return profile.Version, true
+	}
+	return version, true
+}
+
+func (h *Handler) writeProfileUpdateError(w http.ResponseWriter, r *http.Request, err error) {
+	if !httptools.IsKnownError(err) {
+		h.logger.ErrorContext(r.Context(), "failed to update profile", "error", err)
+	}
+	httptools.WriteError(w, err)
+}
@@ -0,0 +1,79 @@
+package profilefield
+
+import (
+	"encoding/json"
+	"net/http"
+	"server/internal/pkg/context"
+	"server/internal/pkg/httptools"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) ListFields(w http.ResponseWriter, r *http.Request) {
+	fields, err := h.uc.ListFields(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to list profile fields", "error", err)
+		httptools.WriteJSONError(w, http.StatusInternalServerError, "failed to list profile fields")
+		return
+	}
+
+	httptools.WriteJSONResponse(w, http.StatusOK, fieldsFromDomain(fields, true))
+}
+
+func (h *Handler) CreateField(w http.ResponseWriter, r *http.Request) {
+	session := context.MustSessionFromContext(r.Context())
+
+	var dto profileFieldDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		httptools.WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	field, err := h.uc.CreateField(r.Context(), session.UserID, dto.ToDomain())
+	if err != nil {
+		h.writeFieldError(w, r, err)
+		return
+	}
+
+	httptools.WriteJSONResponse(w, http.StatusCreated, fieldFromDomain(field, true))
+}
+
+// UpdateField replaces the definition named in the path; the key in the body
+// is ignored and the type may be left out.
+func (h *Handler) UpdateField(w http.ResponseWriter, r *http.Request) {
+	session := context.MustSessionFromContext(r.Context())
+
+	var dto profileFieldDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		httptools.WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	dto.Key = mux.Vars(r)["key"]
+
+	field, err := h.uc.UpdateField(r.Context(), session.UserID, dto.ToDomain())
+	if err != nil {
+		h.writeFieldError(w, r, err)
+		return
+	}
+
+	httptools.WriteJSONResponse(w, http.StatusOK, fieldFromDomain(field, true))
+}
+
+func (h *Handler) DeleteField(w http.ResponseWriter, r *http.Request) {
+	session := context.MustSessionFromContext(r.Context())
+
+	err := h.uc.DeleteField(r.Context(), session.UserID, mux.Vars(r)["key"])
+	if err != nil {
+		h.writeFieldError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) writeFieldError(w http.ResponseWriter, r *http.Request, err error) {
+	if !httptools.IsKnownError(err) {
+		h.logger.ErrorContext(r.Context(), "failed to change profile field", "error", err)
+	}
+	httptools.WriteError(w, err)
+}
@@ -0,0 +1,13 @@
+package profilefield
+
+import (
+	"context"
+	"server/internal/domain"
+)
+
+type ProfileFieldUC interface {
+	ListFields(ctx context.Context) ([]*domain.ProfileField, error)
+	CreateField(ctx context.Context, actorID int64, field *domain.ProfileField) (*domain.ProfileField, error)
+	UpdateField(ctx context.Context, actorID int64, field *domain.ProfileField) (*domain.ProfileField, error)
+	DeleteField(ctx context.Context, actorID int64, key string) error
+}
@@ -0,0 +1,84 @@
+package profilefield
+
+import (
+	"server/internal/domain"
+	"time"
+)
+
+type rulesDTO struct {
+	MinLength int      `json:"min_length,omitempty"`
+	MaxLength int      `json:"max_length,omitempty"`
+	Pattern   string   `json:"pattern,omitempty"`
+	Min       *float64 `json:"min,omitempty"`
+	Max       *float64 `json:"max,omitempty"`
+	Options   []string `json:"options,omitempty"`
+}
+
+type profileFieldDTO struct {
+	Key        string     `json:"key"`
+	Label      string     `json:"label"`
+	Type       string     `json:"type"`
+	Rules      rulesDTO   `json:"rules"`
+	Required   bool       `json:"required"`
+	Visibility string     `json:"visibility"`
+	Position   int        `json:"position"`
+	CreatedAt  *time.Time `json:"created_at,omitempty"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+}
+
+func (dto *profileFieldDTO) ToDomain() *domain.ProfileField {
+	return &domain.ProfileField{
+		Key:   dto.Key,
+		Label: dto.Label,
+		Type:  domain.ProfileFieldType(dto.Type),
+		Rules: domain.ProfileFieldRules{
+			MinLength: dto.Rules.MinLength,
+			MaxLength: dto.Rules.MaxLength,
+			Pattern:   dto.Rules.Pattern,
+			Min:       dto.Rules.Min,
+			Max:       dto.Rules.Max,
+			Options:   dto.Rules.Options,
+		},
+		Required:   dto.Required,
+		Visibility: domain.ProfileFieldVisibility(dto.Visibility),
+		Position:   dto.Position,
+	}
+}
+
+// fieldFromDomain leaves the timestamps out unless withTimestamps is set;
+// they only matter to administrators.
+func fieldFromDomain(field *domain.ProfileField, withTimestamps bool) profileFieldDTO {
+	dto := profileFieldDTO{
+		Key:   field.Key,
+		Label: field.Label,
+		Type:  string(field.Type),
+		Rules: rulesDTO{
+			MinLength: field.Rules.MinLength,
+			MaxLength: field.Rules.MaxLength,
+			Pattern:   field.Rules.Pattern,
+			Min:       field.Rules.Min,
+			Max:       field.Rules.Max,
+			Options:   field.Rules.Options,
+		},
+		Required:   field.Required,
+		Visibility: string(field.Visibility),
+		Position:   field.Position,
+	}
+	if withTimestamps {
+		dto.CreatedAt = &field.CreatedAt
+		dto.UpdatedAt = &field.UpdatedAt
+	}
+	return dto
+}
+
+type profileFieldsDTO struct {
+	Fields []profileFieldDTO `json:"fields"`
+}
+
+func fieldsFromDomain(fields []*domain.ProfileField, withTimestamps bool) profileFieldsDTO {
+	dto := profileFieldsDTO{Fields: make([]profileFieldDTO, 0, len(fields))}
+	for _, field := range fields {
+		dto.Fields = append(dto.Fields, fieldFromDomain(field, withTimestamps))
+	}
+	return dto
+}
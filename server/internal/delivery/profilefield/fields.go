@@ -0,0 +1,19 @@
+package profilefield
+
+import (
+	"net/http"
+	"server/internal/pkg/httptools"
+)
+
+// GetFields lists the custom field definitions so clients can render and
+// validate the inputs for them.
+func (h *Handler) GetFields(w http.ResponseWriter, r *http.Request) {
+	fields, err := h.uc.ListFields(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to list profile fields", "error", err)
+		httptools.WriteJSONError(w, http.StatusInternalServerError, "failed to list profile fields")
+		return
+	}
+
+	httptools.WriteJSONResponse(w, http.StatusOK, fieldsFromDomain(fields, false))
+}
@@ -0,0 +1,17 @@
+package profilefield
+
+import (
+	"log/slog"
+)
+
+type Handler struct {
+	logger *slog.Logger
+	uc     ProfileFieldUC
+}
+
+func NewHandler(logger *slog.Logger, uc ProfileFieldUC) *Handler {
+	return &Handler{
+		logger: logger,
+		uc:     uc,
+	}
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// AccessTokenPrefix starts every personal access token, so a bearer token
+// can be told apart from a session token without a lookup.
+const AccessTokenPrefix = "pat_"
+
+// TokenScope is a permission a personal access token can be given.
+type TokenScope string
+
+const (
+	ScopeProfileRead        TokenScope = "profile:read"
+	ScopeProfileWrite       TokenScope = "profile:write"
+	ScopeUsersRead          TokenScope = "users:read"
+	ScopeOrganizationsRead  TokenScope = "organizations:read"
+	ScopeOrganizationsWrite TokenScope = "organizations:write"
+)
+
+// TokenScopes lists every scope in the order they are shown to users.
+var TokenScopes = []TokenScope{
+	ScopeProfileRead,
+	ScopeProfileWrite,
+	ScopeUsersRead,
+	ScopeOrganizationsRead,
+	ScopeOrganizationsWrite,
+}
+
+func (s TokenScope) IsValid() bool {
+	return slices.Contains(TokenScopes, s)
+}
+
+// PersonalAccessToken lets scripts call the API on behalf of a user. Only
+// the SHA-256 hash of the token is stored; the token itself is shown once,
+// when it is created. A nil ExpiresAt means the token does not expire.
+type PersonalAccessToken struct {
+	ID         int64
+	UserID     int64
+	Name       string
+	Scopes     []TokenScope
+	TokenHash  string
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+}
+
+func (t *PersonalAccessToken) IsExpired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+func (t *PersonalAccessToken) HasScope(scope TokenScope) bool {
+	return slices.Contains(t.Scopes, scope)
+}
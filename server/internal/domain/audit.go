@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type AuditEventType string
+
+const (
+	AuditEventLogInEmail   AuditEventType = "login.email"
+	AuditEventLogInGoogle  AuditEventType = "login.google"
+	AuditEventSignUpEmail  AuditEventType = "signup.email"
+	AuditEventSignUpGoogle AuditEventType = "signup.google"
+	AuditEventLogOut       AuditEventType = "logout"
+	AuditEventProfileEdit  AuditEventType = "profile.update"
+	AuditEventEmailChange  AuditEventType = "email.change"
+
+	AuditEventAccountDeletionRequest   AuditEventType = "account.deletion_request"
+	AuditEventAccountDeletionCancel    AuditEventType = "account.deletion_cancel"
+	AuditEventAccountDelete            AuditEventType = "account.delete"
+	AuditEventDataExportRequest        AuditEventType = "data_export.request"
+	AuditEventDataExportDownload       AuditEventType = "data_export.download"
+	AuditEventEmailChangeRequest       AuditEventType = "email.change_request"
+	AuditEventEmailChangeCancel        AuditEventType = "email.change_cancel"
+	AuditEventPhoneVerificationRequest AuditEventType = "phone.verification_request"
+	AuditEventPhoneVerify              AuditEventType = "phone.verify"
+	AuditEventAvatarUpdate             AuditEventType = "profile.avatar_update"
+	AuditEventProfileFieldCreate       AuditEventType = "profile_field.create"
+	AuditEventProfileFieldUpdate       AuditEventType = "profile_field.update"
+	AuditEventProfileFieldDelete       AuditEventType = "profile_field.delete"
+	AuditEventOrganizationCreate       AuditEventType = "organization.create"
+	AuditEventOrganizationInvite       AuditEventType = "organization.invite"
+	AuditEventOrganizationJoin         AuditEventType = "organization.join"
+	AuditEventOrganizationRoleChange   AuditEventType = "organization.role_change"
+	AuditEventOrganizationMemberRemove AuditEventType = "organization.member_remove"
+	AuditEventInviteCodeCreate         AuditEventType = "invite_code.create"
+	AuditEventInviteCodeDelete         AuditEventType = "invite_code.delete"
+	AuditEventAccessTokenCreate        AuditEventType = "access_token.create"
+	AuditEventAccessTokenDelete        AuditEventType = "access_token.delete"
+	AuditEventRefreshTokenReuse        AuditEventType = "refresh_token.reuse"
+	AuditEventRefreshTokenRevoke       AuditEventType = "refresh_token.revoke"
+)
+
+type AuditOutcome string
+
+const (
+	AuditOutcomeSuccess AuditOutcome = "success"
+	AuditOutcomeFailure AuditOutcome = "failure"
+)
+
+type AuditEvent struct {
+	ID            int64
+	ActorUserID   *int64
+	SubjectUserID *int64
+	Type          AuditEventType
+	IP            string
+	UserAgent     string
+	Outcome       AuditOutcome
+	Details       map[string]string
+	CreatedAt     time.Time
+	PrevHash      string
+	Hash          string
+}
+
+type AuditQuery struct {
+	UserID   *int64
+	Type     AuditEventType
+	Outcome  AuditOutcome
+	BeforeID int64
+	Limit    int
+}
+
+type AuditChainReport struct {
+	Checked    int
+	Valid      bool
+	BrokenAtID int64
+	// HeadMissing is set when no row carries the hash recorded as the chain
+	// head, which is what deleting rows from the end of the log looks like.
+	HeadMissing bool
+}
+
+// ComputeHash returns the chain hash of the event: a SHA-256 over the previous
+// row's hash and every recorded field, so editing or removing any row breaks
+// every hash after it.
+func (e *AuditEvent) ComputeHash() (string, error) {
+	details, err := e.DetailsJSON()
+	if err != nil {
+		return "", err
+	}
+	parts := []string{
+		e.PrevHash,
+		formatOptionalID(e.ActorUserID),
+		formatOptionalID(e.SubjectUserID),
+		string(e.Type),
+		e.IP,
+		e.UserAgent,
+		string(e.Outcome),
+		details,
+		strconv.FormatInt(e.CreatedAt.UnixMicro(), 10),
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x1f")))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (e *AuditEvent) DetailsJSON() (string, error) {
+	if len(e.Details) == 0 {
+		return "{}", nil
+	}
+	data, err := json.Marshal(e.Details)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func formatOptionalID(id *int64) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatInt(*id, 10)
+}
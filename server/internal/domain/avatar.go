@@ -0,0 +1,16 @@
+package domain
+
+// AvatarImage is one stored thumbnail of a user's avatar.
+type AvatarImage struct {
+	AvatarID    string
+	Size        int
+	ContentType string
+	Data        []byte
+}
+
+// Formats a generated avatar can be rendered in. Uploaded pictures are
+// always served in the format they were stored in.
+const (
+	AvatarFormatPNG = "png"
+	AvatarFormatSVG = "svg"
+)
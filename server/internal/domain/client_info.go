@@ -0,0 +1,6 @@
+package domain
+
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
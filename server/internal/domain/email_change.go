@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+type EmailChangeRequest struct {
+	ID               int64
+	UserID           int64
+	OldEmail         string
+	NewEmail         string
+	ConfirmTokenHash string
+	CancelTokenHash  string
+	CreatedAt        time.Time
+	ExpiresAt        time.Time
+}
+
+func (r *EmailChangeRequest) IsExpired(now time.Time) bool {
+	return now.After(r.ExpiresAt)
+}
+
+type EmailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
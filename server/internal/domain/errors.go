@@ -1,6 +1,10 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"sort"
+	"strings"
+)
 
 var (
 	ErrUserAlreadyExists = errors.New("user already exists")
@@ -8,8 +12,109 @@ var (
 	ErrInvalidPassword   = errors.New("invalid password")
 	ErrUserNotExists     = errors.New("user not exists")
 	ErrInvalidGoogleCode = errors.New("invalid Google code")
+
+	ErrReauthenticationRequired = errors.New("re-authentication required")
+)
+
+var (
+	ErrInviteCodeRequired    = errors.New("invite code required")
+	ErrInvalidInviteCode     = errors.New("invite code invalid, expired or already used")
+	ErrInviteCodeNotFound    = errors.New("invite code not found")
+	ErrEmailDomainNotAllowed = errors.New("email domain not allowed")
+	ErrEmailNotVerified      = errors.New("email address not verified by the identity provider")
+)
+
+var (
+	ErrSessionNotFound      = errors.New("session not found")
+	ErrUnsupportedGrantType = errors.New("unsupported grant type")
+)
+
+var (
+	ErrAccessTokenNotFound = errors.New("access token not found")
+	ErrTooManyAccessTokens = errors.New("too many access tokens")
+	ErrInsufficientScope   = errors.New("insufficient scope")
+)
+
+var (
+	ErrJWTDisabled          = errors.New("jwt issuing disabled")
+	ErrInvalidRefreshToken  = errors.New("refresh token invalid or expired")
+	ErrRefreshTokenReused   = errors.New("refresh token reused")
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+)
+
+var (
+	ErrExportNotFound = errors.New("export not found")
+	ErrExportNotReady = errors.New("export not ready")
+	ErrExportExpired  = errors.New("export expired")
+)
+
+var (
+	ErrProfileVersionMismatch = errors.New("profile version mismatch")
 )
 
 var (
-	ErrSessionNotFound = errors.New("session not found")
+	ErrUnsupportedImage    = errors.New("unsupported image")
+	ErrImageTooLarge       = errors.New("image too large")
+	ErrAvatarNotFound      = errors.New("avatar not found")
+	ErrInvalidAvatarSize   = errors.New("invalid avatar size")
+	ErrInvalidAvatarFormat = errors.New("invalid avatar format")
+	ErrBlobNotFound        = errors.New("blob not found")
 )
+
+var (
+	ErrEmailChangeRequiresConfirmation = errors.New("email change requires confirmation")
+	ErrEmailUnchanged                  = errors.New("email unchanged")
+	ErrEmailChangeNotFound             = errors.New("email change request not found")
+	ErrEmailChangeExpired              = errors.New("email change request expired")
+)
+
+var (
+	ErrInvalidPhone              = errors.New("invalid phone number")
+	ErrPhoneNotSet               = errors.New("phone number not set")
+	ErrPhoneAlreadyVerified      = errors.New("phone number already verified")
+	ErrPhoneVerificationNotFound = errors.New("phone verification not found")
+	ErrPhoneVerificationExpired  = errors.New("phone verification expired")
+	ErrPhoneVerificationTooSoon  = errors.New("phone verification requested too soon")
+	ErrTooManyPhoneCodeAttempts  = errors.New("too many phone verification attempts")
+	ErrInvalidPhoneCode          = errors.New("invalid phone verification code")
+)
+
+var (
+	ErrOrganizationNotFound         = errors.New("organization not found")
+	ErrNotOrganizationMember        = errors.New("not an organization member")
+	ErrAlreadyOrganizationMember    = errors.New("already an organization member")
+	ErrOrganizationPermissionDenied = errors.New("organization permission denied")
+	ErrLastOrganizationOwner        = errors.New("organization must keep an owner")
+	ErrInvitationNotFound           = errors.New("invitation not found")
+	ErrInvitationExpired            = errors.New("invitation expired")
+	ErrInvitationForAnotherEmail    = errors.New("invitation is for another email address")
+)
+
+var (
+	ErrRateLimited = errors.New("rate limited")
+)
+
+// FieldErrors reports invalid input per field, keyed by the API field name.
+type FieldErrors map[string]string
+
+func (e FieldErrors) Error() string {
+	fields := make([]string, 0, len(e))
+	for field := range e {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	messages := make([]string, 0, len(fields))
+	for _, field := range fields {
+		messages = append(messages, field+": "+e[field])
+	}
+	return "invalid fields: " + strings.Join(messages, "; ")
+}
+
+var (
+	ErrProfileFieldNotFound    = errors.New("profile field not found")
+	ErrProfileFieldExists      = errors.New("profile field already exists")
+	ErrProfileFieldTypeChanged = errors.New("profile field type cannot be changed")
+)
+
+var ErrHandleTaken = errors.New("handle already taken")
@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+type DataExportStatus string
+
+const (
+	DataExportStatusPending DataExportStatus = "pending"
+	DataExportStatusReady   DataExportStatus = "ready"
+	DataExportStatusFailed  DataExportStatus = "failed"
+)
+
+type DataExport struct {
+	ID          string
+	UserID      int64
+	Status      DataExportStatus
+	Archive     []byte
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+	ExpiresAt   *time.Time
+}
+
+func (e *DataExport) IsExpired(now time.Time) bool {
+	return e.ExpiresAt != nil && now.After(*e.ExpiresAt)
+}
+
+type OAuthAccount struct {
+	ProviderName string
+	Sub          string
+	CreatedAt    time.Time
+}
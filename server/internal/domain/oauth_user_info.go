@@ -1,8 +1,9 @@
 package domain
 
 type OAuthUserInfo struct {
-	Email        string
-	FullName     string
-	ProviderName string
-	Sub          string
+	Email         string
+	EmailVerified bool
+	FullName      string
+	ProviderName  string
+	Sub           string
 }
@@ -0,0 +1,68 @@
+package domain
+
+import "time"
+
+// OrganizationRole is a member's role within one organization.
+type OrganizationRole string
+
+const (
+	OrganizationRoleOwner  OrganizationRole = "owner"
+	OrganizationRoleAdmin  OrganizationRole = "admin"
+	OrganizationRoleMember OrganizationRole = "member"
+)
+
+func (r OrganizationRole) IsValid() bool {
+	switch r {
+	case OrganizationRoleOwner, OrganizationRoleAdmin, OrganizationRoleMember:
+		return true
+	}
+	return false
+}
+
+// CanManageMembers reports whether the role may invite, remove and change
+// the roles of members. Only owners may touch other owners.
+func (r OrganizationRole) CanManageMembers() bool {
+	return r == OrganizationRoleOwner || r == OrganizationRoleAdmin
+}
+
+type Organization struct {
+	ID        int64
+	Name      string
+	CreatedBy int64
+	CreatedAt time.Time
+}
+
+// OrganizationMembership is an organization as seen by one of its members.
+type OrganizationMembership struct {
+	Organization Organization
+	Role         OrganizationRole
+	JoinedAt     time.Time
+}
+
+// OrganizationMember is an entry of an organization's member list.
+type OrganizationMember struct {
+	OrganizationID int64
+	UserID         int64
+	Email          string
+	FullName       string
+	Role           OrganizationRole
+	JoinedAt       time.Time
+}
+
+// OrganizationInvitation lets whoever owns Email join with Role. Only the
+// hash of the token sent by email is stored.
+type OrganizationInvitation struct {
+	ID               int64
+	OrganizationID   int64
+	OrganizationName string
+	Email            string
+	Role             OrganizationRole
+	TokenHash        string
+	InvitedBy        int64
+	CreatedAt        time.Time
+	ExpiresAt        time.Time
+}
+
+func (i *OrganizationInvitation) IsExpired(now time.Time) bool {
+	return now.After(i.ExpiresAt)
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+type PhoneVerification struct {
+	UserID    int64
+	Phone     string
+	CodeHash  string
+	Attempts  int
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+func (v *PhoneVerification) IsExpired(now time.Time) bool {
+	return now.After(v.ExpiresAt)
+}
@@ -1,8 +1,49 @@
 package domain
 
+import "time"
+
 type Profile struct {
-	UserID   int64
-	Email    string
-	FullName string
-	Phone    string
+	UserID          int64
+	Email           string
+	FullName        string
+	Phone           string
+	PhoneVerifiedAt *time.Time
+	// Version grows with every change to the profile and guards against
+	// concurrent edits overwriting each other.
+	Version int64
+	// PendingEmail is the unconfirmed address of an ongoing email change.
+	PendingEmail string
+	// AvatarID names the current set of avatar thumbnails; empty if the
+	// user has not uploaded one.
+	AvatarID string
+	// CustomFields holds the values of administrator-defined fields by
+	// field key; fields without a value are absent.
+	CustomFields map[string]string
+	// Handle is the unique name in the public profile URL, empty until the
+	// user picks one.
+	Handle string
+	// Public opts the profile in to being shown at /u/{handle}.
+	Public  bool
+	Privacy ProfilePrivacy
+}
+
+// ProfilePatch is a partial profile update: nil fields are left as they are,
+// an empty string clears the field.
+type ProfilePatch struct {
+	FullName *string
+	Phone    *string
+	Email    *string
+	// CustomFields sets custom field values by field key; keys that are
+	// absent are left as they are.
+	CustomFields map[string]string
+	Handle       *string
+	Public       *bool
+	// Privacy changes the visibilities that are set; empty ones are left
+	// as they are.
+	Privacy ProfilePrivacy
+}
+
+func (p *ProfilePatch) IsEmpty() bool {
+	return p.FullName == nil && p.Phone == nil && p.Email == nil && len(p.CustomFields) == 0 &&
+		p.Handle == nil && p.Public == nil && p.Privacy == (ProfilePrivacy{})
 }
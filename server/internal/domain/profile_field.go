@@ -0,0 +1,68 @@
+package domain
+
+import "time"
+
+type ProfileFieldType string
+
+const (
+	ProfileFieldTypeText     ProfileFieldType = "text"
+	ProfileFieldTypeTextArea ProfileFieldType = "textarea"
+	ProfileFieldTypeNumber   ProfileFieldType = "number"
+	ProfileFieldTypeBoolean  ProfileFieldType = "boolean"
+	ProfileFieldTypeSelect   ProfileFieldType = "select"
+	ProfileFieldTypeURL      ProfileFieldType = "url"
+	ProfileFieldTypeTimezone ProfileFieldType = "timezone"
+	ProfileFieldTypeLocale   ProfileFieldType = "locale"
+)
+
+var ProfileFieldTypes = []ProfileFieldType{
+	ProfileFieldTypeText,
+	ProfileFieldTypeTextArea,
+	ProfileFieldTypeNumber,
+	ProfileFieldTypeBoolean,
+	ProfileFieldTypeSelect,
+	ProfileFieldTypeURL,
+	ProfileFieldTypeTimezone,
+	ProfileFieldTypeLocale,
+}
+
+type ProfileFieldVisibility string
+
+const (
+	// ProfileFieldVisibilityPrivate fields are seen by the user and
+	// administrators only.
+	ProfileFieldVisibilityPrivate ProfileFieldVisibility = "private"
+	// ProfileFieldVisibilityPublic fields may be shown to other users.
+	ProfileFieldVisibilityPublic ProfileFieldVisibility = "public"
+)
+
+// ProfileFieldRules constrain the values of a custom field. Zero values mean
+// "no constraint"; which rules apply depends on the field type.
+type ProfileFieldRules struct {
+	// MinLength and MaxLength count characters of text values.
+	MinLength int
+	MaxLength int
+	// Pattern is a regular expression the whole text value must match.
+	Pattern string
+	// Min and Max bound number values.
+	Min *float64
+	Max *float64
+	// Options lists the allowed values of a select field.
+	Options []string
+}
+
+// ProfileField is an administrator-defined profile attribute. Values are
+// stored per user as strings, keyed by Key in Profile.CustomFields.
+type ProfileField struct {
+	ID         int64
+	Key        string
+	Label      string
+	Type       ProfileFieldType
+	Rules      ProfileFieldRules
+	Required   bool
+	Visibility ProfileFieldVisibility
+	// Position orders the fields on the profile page.
+	Position  int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
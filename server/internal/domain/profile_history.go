@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// ProfileChange records one field of a profile update: the value before and
+// after it and the session that made it. Field uses the API names, e.g.
+// "phone" or "custom_fields.department"; an empty value means "not set".
+type ProfileChange struct {
+	ID        int64
+	UserID    int64
+	Field     string
+	OldValue  string
+	NewValue  string
+	SessionID string
+	CreatedAt time.Time
+}
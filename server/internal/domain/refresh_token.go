@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+// RefreshToken is one link of a chain of opaque refresh tokens handed out
+// with JWT access tokens. Every refresh uses up the token and issues the
+// next one in the same family; presenting a used token again means it
+// leaked, and the whole family is revoked. Only the SHA-256 hash of the
+// token is stored.
+type RefreshToken struct {
+	ID         int64
+	FamilyID   string
+	UserID     int64
+	TokenHash  string
+	AuthMethod string
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	UsedAt     *time.Time
+	RevokedAt  *time.Time
+}
+
+func (t *RefreshToken) IsExpired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+// TokenPair is what the JWT issuer returns: a signed access token that
+// services verify against the published keys, and the refresh token to get
+// the next pair with.
+type TokenPair struct {
+	UserID                int64
+	AccessToken           string
+	AccessTokenExpiresAt  time.Time
+	RefreshToken          string
+	RefreshTokenExpiresAt time.Time
+}
@@ -1,9 +1,70 @@
 package domain
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"time"
+)
+
+const (
+	AuthMethodPassword = "password"
+	AuthMethodGoogle   = "google"
+	// AuthMethodAccessToken marks sessions made up for a request carrying a
+	// personal access token; they are never stored.
+	AuthMethodAccessToken = "access_token"
+)
+
+// Grant types accepted when an API client asks for a bearer token.
+const (
+	GrantTypePassword = "password"
+	GrantTypeGoogle   = "google"
+	// GrantTypeRefreshToken exchanges a refresh token for a new JWT pair; it
+	// is only accepted by the JWT issuer.
+	GrantTypeRefreshToken = "refresh_token"
+)
+
+// TokenGrant is what an API client exchanges for a bearer token: an email
+// and password, or a Google authorization code obtained through the login
+// consent URL. RefreshToken is set for refresh token grants.
+type TokenGrant struct {
+	Type         string
+	Email        string
+	Password     string
+	Code         string
+	RefreshToken string
+}
 
 type Session struct {
-	Token     string
-	UserID    int64
-	ExpiresAt time.Time
+	Token      string
+	UserID     int64
+	AuthMethod string
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	// OrganizationID is the organization selected for this session, zero
+	// when none is.
+	OrganizationID int64
+	// Bearer marks sessions issued to API clients. Their token is only
+	// accepted in the Authorization header, never as the auth_token cookie,
+	// so requests made with it are not subject to CSRF checks.
+	Bearer bool
+	// Scopes is what a personal access token was granted. It only applies
+	// when AuthMethod is AuthMethodAccessToken; other sessions may call
+	// every route.
+	Scopes []TokenScope
+}
+
+// HasScope reports whether the session may use a route that needs scope.
+func (s *Session) HasScope(scope TokenScope) bool {
+	if s.AuthMethod != AuthMethodAccessToken {
+		return true
+	}
+	return slices.Contains(s.Scopes, scope)
+}
+
+// ID identifies the session in records that outlive it. It is derived from
+// the token, which is a credential and must not be stored or shown.
+func (s *Session) ID() string {
+	sum := sha256.Sum256([]byte(s.Token))
+	return hex.EncodeToString(sum[:8])
 }
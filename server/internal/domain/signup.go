@@ -0,0 +1,48 @@
+package domain
+
+import "time"
+
+// SignUpMode decides who may create an account.
+type SignUpMode string
+
+const (
+	// SignUpModeOpen lets anyone sign up.
+	SignUpModeOpen SignUpMode = "open"
+	// SignUpModeInviteOnly requires an unused invite code created by an
+	// administrator.
+	SignUpModeInviteOnly SignUpMode = "invite_only"
+	// SignUpModeAllowedDomains only accepts emails from the listed domains.
+	SignUpModeAllowedDomains SignUpMode = "allowed_domains"
+)
+
+func (m SignUpMode) IsValid() bool {
+	switch m {
+	case SignUpModeOpen, SignUpModeInviteOnly, SignUpModeAllowedDomains:
+		return true
+	}
+	return false
+}
+
+// SignUpPolicy is the configured sign-up mode. AllowedDomains is only used
+// in SignUpModeAllowedDomains and holds lower-case domains.
+type SignUpPolicy struct {
+	Mode           SignUpMode
+	AllowedDomains []string
+}
+
+// InviteCode lets one person sign up while sign-up is invite-only. Only the
+// SHA-256 hash of the code is stored; UsedAt is set once it was redeemed.
+type InviteCode struct {
+	ID        int64
+	CodeHash  string
+	Note      string
+	CreatedBy int64
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedEmail string
+	UsedAt    *time.Time
+}
+
+func (c *InviteCode) IsExpired(now time.Time) bool {
+	return !now.Before(c.ExpiresAt)
+}
@@ -0,0 +1,72 @@
+package domain
+
+// Visibility controls who may see a part of a public profile. The owner
+// always sees everything.
+type Visibility string
+
+const (
+	VisibilityPublic   Visibility = "public"
+	VisibilitySignedIn Visibility = "signed_in"
+	VisibilityPrivate  Visibility = "private"
+)
+
+func (v Visibility) IsValid() bool {
+	switch v {
+	case VisibilityPublic, VisibilitySignedIn, VisibilityPrivate:
+		return true
+	}
+	return false
+}
+
+// AllowsViewer reports whether someone other than the owner may see the
+// value; signedIn tells whether the viewer is logged in.
+func (v Visibility) AllowsViewer(signedIn bool) bool {
+	switch v {
+	case VisibilityPublic:
+		return true
+	case VisibilitySignedIn:
+		return signedIn
+	default:
+		return false
+	}
+}
+
+// ProfilePrivacy holds the visibility of each standard profile attribute.
+type ProfilePrivacy struct {
+	FullName Visibility
+	Email    Visibility
+	Phone    Visibility
+	Avatar   Visibility
+}
+
+// DefaultProfilePrivacy matches the column defaults: name and picture are
+// shown on a public profile, contact details are not.
+var DefaultProfilePrivacy = ProfilePrivacy{
+	FullName: VisibilityPublic,
+	Email:    VisibilityPrivate,
+	Phone:    VisibilityPrivate,
+	Avatar:   VisibilityPublic,
+}
+
+// PublicProfile is what a viewer gets to see of someone's profile: the
+// attributes the viewer may not see are left empty.
+type PublicProfile struct {
+	UserID   int64
+	Handle   string
+	FullName string
+	Email    string
+	Phone    string
+	// AvatarVisible tells whether the picture may be shown; AvatarID is
+	// empty when the user has not uploaded one.
+	AvatarVisible bool
+	AvatarID      string
+	// CustomFields lists the values of public custom fields in field order.
+	CustomFields []PublicProfileField
+}
+
+type PublicProfileField struct {
+	Key   string
+	Label string
+	Type  ProfileFieldType
+	Value string
+}
@@ -77,10 +77,11 @@ func (g *OAuthGateway) GetOAuthUserInfo(ctx context.Context, code, purpose strin
 	}
 
 	return &domain.OAuthUserInfo{
-		Sub:          googleUser.Sub,
-		Email:        googleUser.Email,
-		FullName:     googleUser.Name,
-		ProviderName: googleProviderName,
+		Sub:           googleUser.Sub,
+		Email:         googleUser.Email,
+		EmailVerified: googleUser.EmailVerified,
+		FullName:      googleUser.Name,
+		ProviderName:  googleProviderName,
 	}, nil
 }
 
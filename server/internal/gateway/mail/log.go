@@ -0,0 +1,22 @@
+package mail
+
+import (
+	"context"
+	"log/slog"
+	"server/internal/domain"
+)
+
+// LogMailer writes outgoing mail to the log instead of delivering it. It is
+// the default until an SMTP provider is configured.
+type LogMailer struct {
+	logger *slog.Logger
+}
+
+func NewLogMailer(logger *slog.Logger) *LogMailer {
+	return &LogMailer{logger: logger}
+}
+
+func (m *LogMailer) Send(ctx context.Context, message domain.EmailMessage) error {
+	m.logger.InfoContext(ctx, "email sent", "to", message.To, "subject", message.Subject, "body", message.Body)
+	return nil
+}
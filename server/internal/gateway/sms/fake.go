@@ -0,0 +1,45 @@
+package sms
+
+import (
+	"context"
+	"sync"
+)
+
+type Message struct {
+	Phone string
+	Text  string
+}
+
+// FakeSender keeps sent messages in memory so tests can read the codes back.
+type FakeSender struct {
+	mu       sync.Mutex
+	messages []Message
+	err      error
+}
+
+func NewFakeSender() *FakeSender {
+	return &FakeSender{}
+}
+
+// FailWith makes every following Send return err.
+func (s *FakeSender) FailWith(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+func (s *FakeSender) Send(_ context.Context, phone, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.messages = append(s.messages, Message{Phone: phone, Text: message})
+	return nil
+}
+
+func (s *FakeSender) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
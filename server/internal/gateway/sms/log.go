@@ -0,0 +1,21 @@
+package sms
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogSender writes text messages to the log instead of sending them. It is
+// meant for development, where no SMS provider is configured.
+type LogSender struct {
+	logger *slog.Logger
+}
+
+func NewLogSender(logger *slog.Logger) *LogSender {
+	return &LogSender{logger: logger}
+}
+
+func (s *LogSender) Send(ctx context.Context, phone, message string) error {
+	s.logger.InfoContext(ctx, "sms sent", "phone", phone, "message", message)
+	return nil
+}
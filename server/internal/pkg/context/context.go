@@ -7,6 +7,12 @@ import (
 
 type contextKey struct{}
 
+type clientInfoKey struct{}
+
+type organizationKey struct{}
+
+type requestIDKey struct{}
+
 func WithSession(ctx context.Context, session *domain.Session) context.Context {
 	return context.WithValue(ctx, contextKey{}, session)
 }
@@ -23,3 +29,34 @@ func MustSessionFromContext(ctx context.Context) *domain.Session {
 	}
 	return session
 }
+
+// WithOrganization attaches the organization selected for the session.
+func WithOrganization(ctx context.Context, membership *domain.OrganizationMembership) context.Context {
+	return context.WithValue(ctx, organizationKey{}, membership)
+}
+
+// OrganizationFromContext returns the current organization, if the session
+// has selected one.
+func OrganizationFromContext(ctx context.Context) (*domain.OrganizationMembership, bool) {
+	membership, ok := ctx.Value(organizationKey{}).(*domain.OrganizationMembership)
+	return membership, ok
+}
+
+func WithClientInfo(ctx context.Context, info domain.ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoKey{}, info)
+}
+
+func ClientInfoFromContext(ctx context.Context) domain.ClientInfo {
+	info, _ := ctx.Value(clientInfoKey{}).(domain.ClientInfo)
+	return info
+}
+
+// WithRequestID attaches the ID that ties together the log lines of a request.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
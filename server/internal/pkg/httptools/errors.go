@@ -0,0 +1,224 @@
+package httptools
+
+import (
+	"errors"
+	"net/http"
+	"server/internal/domain"
+)
+
+// Error codes are part of the API contract: clients branch on them and the
+// frontend keys its messages by them, so existing codes must not change.
+const (
+	CodeBadRequest           = "bad_request"
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "forbidden"
+	CodeNotFound             = "not_found"
+	CodeMethodNotAllowed     = "method_not_allowed"
+	CodeConflict             = "conflict"
+	CodeGone                 = "gone"
+	CodePreconditionFailed   = "precondition_failed"
+	CodePayloadTooLarge      = "payload_too_large"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeValidationFailed     = "validation_failed"
+	CodeInvalidRequest       = "invalid_request"
+	CodeInvalidResponse      = "invalid_response"
+	CodeTooManyRequests      = "too_many_requests"
+	CodeInternalError        = "internal_error"
+	CodeUnavailable          = "unavailable"
+
+	CodeUserAlreadyExists        = "user_already_exists"
+	CodeInvalidEmail             = "invalid_email"
+	CodeInvalidPassword          = "invalid_password"
+	CodeUserNotFound             = "user_not_found"
+	CodeInvalidGoogleCode        = "invalid_google_code"
+	CodeReauthenticationRequired = "reauthentication_required"
+	CodeSessionNotFound          = "session_not_found"
+	CodeSessionActive            = "session_active"
+	CodeInvalidCSRFToken         = "invalid_csrf_token"
+	CodeUnsupportedGrantType     = "unsupported_grant_type"
+
+	CodeAccessTokenNotFound = "access_token_not_found"
+	CodeTooManyAccessTokens = "too_many_access_tokens"
+	CodeInsufficientScope   = "insufficient_scope"
+
+	CodeJWTDisabled         = "jwt_disabled"
+	CodeInvalidRefreshToken = "invalid_refresh_token"
+	CodeRefreshTokenReused  = "refresh_token_reused"
+
+	CodeInviteCodeRequired    = "invite_code_required"
+	CodeInvalidInviteCode     = "invalid_invite_code"
+	CodeInviteCodeNotFound    = "invite_code_not_found"
+	CodeEmailDomainNotAllowed = "email_domain_not_allowed"
+	CodeEmailNotVerified      = "email_not_verified"
+
+	CodeExportNotFound = "export_not_found"
+	CodeExportNotReady = "export_not_ready"
+	CodeExportExpired  = "export_expired"
+
+	CodeProfileVersionMismatch = "profile_version_mismatch"
+	CodeHandleTaken            = "handle_taken"
+
+	CodeUnsupportedImage    = "unsupported_image"
+	CodeImageTooLarge       = "image_too_large"
+	CodeAvatarNotFound      = "avatar_not_found"
+	CodeInvalidAvatarSize   = "invalid_avatar_size"
+	CodeInvalidAvatarFormat = "invalid_avatar_format"
+
+	CodeEmailChangeRequiresConfirmation = "email_change_requires_confirmation"
+	CodeEmailUnchanged                  = "email_unchanged"
+	CodeEmailChangeNotFound             = "email_change_not_found"
+	CodeEmailChangeExpired              = "email_change_expired"
+
+	CodeInvalidPhone              = "invalid_phone"
+	CodePhoneNotSet               = "phone_not_set"
+	CodePhoneAlreadyVerified      = "phone_already_verified"
+	CodePhoneVerificationNotFound = "phone_verification_not_found"
+	CodePhoneVerificationExpired  = "phone_verification_expired"
+	CodePhoneVerificationTooSoon  = "phone_verification_too_soon"
+	CodeTooManyPhoneCodeAttempts  = "too_many_phone_code_attempts"
+	CodeInvalidPhoneCode          = "invalid_phone_code"
+
+	CodeOrganizationNotFound         = "organization_not_found"
+	CodeNotOrganizationMember        = "not_organization_member"
+	CodeAlreadyOrganizationMember    = "already_organization_member"
+	CodeOrganizationPermissionDenied = "organization_permission_denied"
+	CodeLastOrganizationOwner        = "last_organization_owner"
+	CodeInvitationNotFound           = "invitation_not_found"
+	CodeInvitationExpired            = "invitation_expired"
+	CodeInvitationForAnotherEmail    = "invitation_for_another_email"
+
+	CodeProfileFieldNotFound    = "profile_field_not_found"
+	CodeProfileFieldExists      = "profile_field_exists"
+	CodeProfileFieldTypeChanged = "profile_field_type_changed"
+
+	CodeRateLimited = "rate_limited"
+)
+
+type errorMapping struct {
+	err    error
+	status int
+	code   string
+	detail string
+}
+
+// errorMappings is the single place where domain errors get their HTTP
+// status, code and default message.
+var errorMappings = []errorMapping{
+	{domain.ErrUserAlreadyExists, http.StatusConflict, CodeUserAlreadyExists, "an account with this email already exists"},
+	{domain.ErrNotValidEmail, http.StatusBadRequest, CodeInvalidEmail, "email is not valid"},
+	{domain.ErrInvalidPassword, http.StatusUnauthorized, CodeInvalidPassword, "password is incorrect"},
+	{domain.ErrUserNotExists, http.StatusNotFound, CodeUserNotFound, "user not found"},
+	{domain.ErrInvalidGoogleCode, http.StatusBadRequest, CodeInvalidGoogleCode, "invalid google code"},
+	{domain.ErrReauthenticationRequired, http.StatusForbidden, CodeReauthenticationRequired, "re-authentication required"},
+	{domain.ErrSessionNotFound, http.StatusUnauthorized, CodeSessionNotFound, "unauthorized"},
+	{domain.ErrUnsupportedGrantType, http.StatusBadRequest, CodeUnsupportedGrantType, "unsupported grant type"},
+
+	{domain.ErrAccessTokenNotFound, http.StatusNotFound, CodeAccessTokenNotFound, "access token not found"},
+	{domain.ErrTooManyAccessTokens, http.StatusConflict, CodeTooManyAccessTokens, "too many access tokens"},
+	{domain.ErrInsufficientScope, http.StatusForbidden, CodeInsufficientScope, "access token lacks the required scope"},
+
+	{domain.ErrJWTDisabled, http.StatusNotFound, CodeJWTDisabled, "jwt issuing is not configured"},
+	{domain.ErrInvalidRefreshToken, http.StatusUnauthorized, CodeInvalidRefreshToken, "refresh token is invalid or expired"},
+	{domain.ErrRefreshTokenReused, http.StatusUnauthorized, CodeRefreshTokenReused, "refresh token was already used, log in again"},
+
+	{domain.ErrInviteCodeRequired, http.StatusForbidden, CodeInviteCodeRequired, "invite code required"},
+	{domain.ErrInvalidInviteCode, http.StatusForbidden, CodeInvalidInviteCode, "invite code is invalid, expired or already used"},
+	{domain.ErrInviteCodeNotFound, http.StatusNotFound, CodeInviteCodeNotFound, "invite code not found"},
+	{domain.ErrEmailDomainNotAllowed, http.StatusForbidden, CodeEmailDomainNotAllowed, "sign-up is not open to this email domain"},
+	{domain.ErrEmailNotVerified, http.StatusForbidden, CodeEmailNotVerified, "the google account's email address is not verified"},
+
+	{domain.ErrExportNotFound, http.StatusNotFound, CodeExportNotFound, "data export not found"},
+	{domain.ErrExportNotReady, http.StatusConflict, CodeExportNotReady, "data export is not ready"},
+	{domain.ErrExportExpired, http.StatusGone, CodeExportExpired, "data export has expired"},
+
+	{domain.ErrProfileVersionMismatch, http.StatusPreconditionFailed, CodeProfileVersionMismatch, "profile was changed elsewhere"},
+	{domain.ErrHandleTaken, http.StatusConflict, CodeHandleTaken, "handle is already taken"},
+
+	{domain.ErrUnsupportedImage, http.StatusUnsupportedMediaType, CodeUnsupportedImage, "avatar must be a PNG, JPEG or WebP image"},
+	{domain.ErrImageTooLarge, http.StatusRequestEntityTooLarge, CodeImageTooLarge, "avatar image is too large"},
+	{domain.ErrAvatarNotFound, http.StatusNotFound, CodeAvatarNotFound, "avatar not found"},
+	{domain.ErrBlobNotFound, http.StatusNotFound, CodeAvatarNotFound, "avatar not found"},
+	{domain.ErrInvalidAvatarSize, http.StatusBadRequest, CodeInvalidAvatarSize, "invalid avatar size"},
+	{domain.ErrInvalidAvatarFormat, http.StatusBadRequest, CodeInvalidAvatarFormat, "avatar format must be png or svg"},
+
+	{domain.ErrEmailChangeRequiresConfirmation, http.StatusBadRequest, CodeEmailChangeRequiresConfirmation, "email can only be changed with confirmation"},
+	{domain.ErrEmailUnchanged, http.StatusBadRequest, CodeEmailUnchanged, "email is the same as the current one"},
+	{domain.ErrEmailChangeNotFound, http.StatusNotFound, CodeEmailChangeNotFound, "email change request not found"},
+	{domain.ErrEmailChangeExpired, http.StatusGone, CodeEmailChangeExpired, "email change request has expired"},
+
+	{domain.ErrInvalidPhone, http.StatusBadRequest, CodeInvalidPhone, "phone number is not valid"},
+	{domain.ErrPhoneNotSet, http.StatusBadRequest, CodePhoneNotSet, "add a phone number to your profile first"},
+	{domain.ErrPhoneAlreadyVerified, http.StatusConflict, CodePhoneAlreadyVerified, "phone number is already verified"},
+	{domain.ErrPhoneVerificationNotFound, http.StatusNotFound, CodePhoneVerificationNotFound, "no verification code was requested for this phone number"},
+	{domain.ErrPhoneVerificationExpired, http.StatusGone, CodePhoneVerificationExpired, "verification code has expired, request a new one"},
+	{domain.ErrPhoneVerificationTooSoon, http.StatusTooManyRequests, CodePhoneVerificationTooSoon, "a code was sent recently, please wait before requesting another one"},
+	{domain.ErrTooManyPhoneCodeAttempts, http.StatusTooManyRequests, CodeTooManyPhoneCodeAttempts, "too many wrong codes, request a new one"},
+	{domain.ErrInvalidPhoneCode, http.StatusBadRequest, CodeInvalidPhoneCode, "verification code is incorrect"},
+
+	{domain.ErrOrganizationNotFound, http.StatusNotFound, CodeOrganizationNotFound, "organization not found"},
+	// Outsiders cannot tell an organization they are not in from one that
+	// does not exist.
+	{domain.ErrNotOrganizationMember, http.StatusNotFound, CodeNotOrganizationMember, "organization or member not found"},
+	{domain.ErrAlreadyOrganizationMember, http.StatusConflict, CodeAlreadyOrganizationMember, "you are already a member of this organization"},
+	{domain.ErrOrganizationPermissionDenied, http.StatusForbidden, CodeOrganizationPermissionDenied, "your role in this organization does not allow this"},
+	{domain.ErrLastOrganizationOwner, http.StatusConflict, CodeLastOrganizationOwner, "an organization must keep at least one owner"},
+	{domain.ErrInvitationNotFound, http.StatusNotFound, CodeInvitationNotFound, "invitation not found"},
+	{domain.ErrInvitationExpired, http.StatusGone, CodeInvitationExpired, "invitation expired"},
+	{domain.ErrInvitationForAnotherEmail, http.StatusForbidden, CodeInvitationForAnotherEmail, "this invitation was sent to another email address"},
+
+	{domain.ErrProfileFieldNotFound, http.StatusNotFound, CodeProfileFieldNotFound, "profile field not found"},
+	{domain.ErrProfileFieldExists, http.StatusConflict, CodeProfileFieldExists, "a profile field with this key already exists"},
+	{domain.ErrProfileFieldTypeChanged, http.StatusConflict, CodeProfileFieldTypeChanged, "the type of a profile field cannot be changed"},
+
+	{domain.ErrRateLimited, http.StatusTooManyRequests, CodeRateLimited, "too many requests, please wait a moment"},
+}
+
+func lookupError(err error) (errorMapping, bool) {
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			return m, true
+		}
+	}
+	return errorMapping{}, false
+}
+
+func asFieldErrors(err error) (domain.FieldErrors, bool) {
+	var fields domain.FieldErrors
+	ok := errors.As(err, &fields)
+	return fields, ok
+}
+
+func codeForStatus(statusCode int) string {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusGone:
+		return CodeGone
+	case http.StatusPreconditionFailed:
+		return CodePreconditionFailed
+	case http.StatusRequestEntityTooLarge:
+		return CodePayloadTooLarge
+	case http.StatusUnsupportedMediaType:
+		return CodeUnsupportedMediaType
+	case http.StatusUnprocessableEntity:
+		return CodeValidationFailed
+	case http.StatusTooManyRequests:
+		return CodeTooManyRequests
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	}
+	if statusCode >= http.StatusInternalServerError {
+		return CodeInternalError
+	}
+	return CodeBadRequest
+}
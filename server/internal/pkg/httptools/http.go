@@ -5,8 +5,16 @@ import (
 	"net/http"
 )
 
+// RequestIDHeader carries the request ID in both directions. The request ID
+// middleware sets it on the response before any handler runs, which is how
+// error bodies pick it up.
+const RequestIDHeader = "X-Request-ID"
+
+// WriteJSONError writes an RFC 7807 problem with a generic code for the
+// status. Errors that come from the domain should go through WriteError to
+// get their specific code.
 func WriteJSONError(w http.ResponseWriter, statusCode int, message string) {
-	WriteJSONResponse(w, statusCode, map[string]string{"error": message})
+	WriteProblem(w, statusCode, codeForStatus(statusCode), message)
 }
 
 func WriteJSONResponse(w http.ResponseWriter, statusCode int, data interface{}) {
@@ -0,0 +1,108 @@
+package httptools
+
+import (
+	"encoding/json"
+	"net/http"
+	"server/internal/domain"
+)
+
+const (
+	problemContentType = "application/problem+json"
+	problemTypePrefix  = "urn:problem-type:"
+)
+
+// Problem is an RFC 7807 problem details body. Code is the stable,
+// machine-readable identifier clients branch on; Detail is English text for
+// developers and must not be matched against.
+type Problem struct {
+	Type      string            `json:"type"`
+	Title     string            `json:"title"`
+	Status    int               `json:"status"`
+	Detail    string            `json:"detail,omitempty"`
+	Code      string            `json:"code"`
+	RequestID string            `json:"request_id,omitempty"`
+	Fields    map[string]string `json:"fields,omitempty"`
+	// Error repeats Detail for clients written against the old
+	// {"error": "..."} body.
+	Error string `json:"error,omitempty"`
+}
+
+func WriteProblem(w http.ResponseWriter, statusCode int, code, detail string) {
+	writeProblem(w, Problem{
+		Type:   problemTypePrefix + code,
+		Title:  http.StatusText(statusCode),
+		Status: statusCode,
+		Detail: detail,
+		Code:   code,
+	})
+}
+
+// WriteValidationProblem reports invalid input per field.
+func WriteValidationProblem(w http.ResponseWriter, fields domain.FieldErrors) {
+	writeProblem(w, Problem{
+		Type:   problemTypePrefix + CodeValidationFailed,
+		Title:  http.StatusText(http.StatusUnprocessableEntity),
+		Status: http.StatusUnprocessableEntity,
+		Detail: "validation failed",
+		Code:   CodeValidationFailed,
+		Fields: fields,
+	})
+}
+
+// WriteInvalidRequest reports a request that does not match the API
+// specification; fields names the offending parameters and body members.
+func WriteInvalidRequest(w http.ResponseWriter, detail string, fields map[string]string) {
+	writeProblem(w, Problem{
+		Type:   problemTypePrefix + CodeInvalidRequest,
+		Title:  http.StatusText(http.StatusBadRequest),
+		Status: http.StatusBadRequest,
+		Detail: detail,
+		Code:   CodeInvalidRequest,
+		Fields: fields,
+	})
+}
+
+// WriteError maps err to a problem using the central table of domain errors.
+// Errors that are not in the table are reported as internal errors without
+// leaking their text; the caller is expected to have logged them.
+func WriteError(w http.ResponseWriter, err error) {
+	if fields, ok := asFieldErrors(err); ok {
+		WriteValidationProblem(w, fields)
+		return
+	}
+	if m, ok := lookupError(err); ok {
+		WriteProblem(w, m.status, m.code, m.detail)
+		return
+	}
+	WriteProblem(w, http.StatusInternalServerError, CodeInternalError, "internal server error")
+}
+
+// IsKnownError reports whether WriteError has a specific mapping for err, so
+// handlers can log everything else as unexpected.
+func IsKnownError(err error) bool {
+	if _, ok := asFieldErrors(err); ok {
+		return true
+	}
+	_, ok := lookupError(err)
+	return ok
+}
+
+// ErrorCode returns the stable code for err, for places that report errors
+// without a JSON body, such as the OAuth callback redirects.
+func ErrorCode(err error) string {
+	if _, ok := asFieldErrors(err); ok {
+		return CodeValidationFailed
+	}
+	if m, ok := lookupError(err); ok {
+		return m.code
+	}
+	return CodeInternalError
+}
+
+func writeProblem(w http.ResponseWriter, problem Problem) {
+	problem.RequestID = w.Header().Get(RequestIDHeader)
+	problem.Error = problem.Detail
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
@@ -0,0 +1,272 @@
+// Package identicon renders the default avatar shown until a user uploads a
+// picture: their initials on a coloured background, or a mirrored pixel
+// pattern when the name yields no initials the bundled font can draw. The
+// output depends only on the inputs, so nothing is stored and the same bytes
+// come back on every request.
+package identicon
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// ContentTypeSVG is the media type of SVG output.
+const ContentTypeSVG = "image/svg+xml"
+
+const (
+	// rendererVersion is part of every hash; bump it whenever the drawing
+	// changes so cached images are not served for the new look.
+	rendererVersion = "1"
+
+	gridCells      = 5
+	fontSizeFactor = 0.42
+)
+
+var (
+	patternBackground = color.RGBA{R: 0xf0, G: 0xf0, B: 0xf0, A: 0xff}
+	initialsColor     = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+
+	parseFont = sync.OnceValues(func() (*opentype.Font, error) {
+		return opentype.Parse(gobold.TTF)
+	})
+)
+
+// Identicon is the generated avatar of one user.
+type Identicon struct {
+	hash     [sha256.Size]byte
+	initials string
+}
+
+// New derives the avatar from the user ID and full name. Only the initials
+// of the name are used, so unrelated name edits keep the same image.
+func New(userID int64, fullName string) Identicon {
+	initials := initialsOf(fullName)
+	if !drawable(initials) {
+		initials = ""
+	}
+
+	h := sha256.New()
+	h.Write([]byte(rendererVersion))
+	binary.Write(h, binary.BigEndian, userID)
+	h.Write([]byte(initials))
+
+	var i Identicon
+	copy(i.hash[:], h.Sum(nil))
+	i.initials = initials
+	return i
+}
+
+// Version identifies the rendered image; it changes whenever the output
+// would.
+func (i Identicon) Version() string {
+	return hex.EncodeToString(i.hash[:8])
+}
+
+// PNG renders the avatar as a size x size PNG.
+func (i Identicon) PNG(size int) ([]byte, error) {
+	var img *image.RGBA
+	var err error
+	if i.initials != "" {
+		img, err = i.drawInitials(size)
+	} else {
+		img = i.drawPattern(size)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode identicon: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// SVG renders the same avatar as a scalable image.
+func (i Identicon) SVG(size int) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, size, size, size, size)
+
+	if i.initials != "" {
+		fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="%s"/>`, size, size, hexColor(i.color()))
+		fmt.Fprintf(&b, `<text x="50%%" y="50%%" dy="0.35em" text-anchor="middle" font-family="Go, Helvetica, Arial, sans-serif" font-weight="bold" font-size="%s" fill="%s">%s</text>`,
+			strconv.FormatFloat(float64(size)*fontSizeFactor, 'f', -1, 64), hexColor(initialsColor), html.EscapeString(i.initials))
+	} else {
+		fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="%s"/>`, size, size, hexColor(patternBackground))
+		cell, margin := gridGeometry(size)
+		fg := hexColor(i.color())
+		i.eachCell(func(row, col int) {
+			fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`, margin+col*cell, margin+row*cell, cell, cell, fg)
+		})
+	}
+
+	b.WriteString(`</svg>`)
+	return []byte(b.String())
+}
+
+func (i Identicon) drawInitials(size int) (*image.RGBA, error) {
+	f, err := parseFont()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse identicon font: %w", err)
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{
+		Size:    float64(size) * fontSizeFactor,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create identicon font face: %w", err)
+	}
+	defer face.Close()
+
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), image.NewUniform(i.color()), image.Point{}, draw.Src)
+
+	drawer := &font.Drawer{Dst: img, Src: image.NewUniform(initialsColor), Face: face}
+	width := drawer.MeasureString(i.initials)
+	capHeight := face.Metrics().CapHeight
+	drawer.Dot = fixed.Point26_6{
+		X: (fixed.I(size) - width) / 2,
+		Y: (fixed.I(size) + capHeight) / 2,
+	}
+	drawer.DrawString(i.initials)
+	return img, nil
+}
+
+func (i Identicon) drawPattern(size int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), image.NewUniform(patternBackground), image.Point{}, draw.Src)
+
+	cell, margin := gridGeometry(size)
+	fg := image.NewUniform(i.color())
+	i.eachCell(func(row, col int) {
+		r := image.Rect(0, 0, cell, cell).Add(image.Pt(margin+col*cell, margin+row*cell))
+		draw.Draw(img, r, fg, image.Point{}, draw.Src)
+	})
+	return img
+}
+
+// eachCell calls fn for every filled cell of the grid. The left half and the
+// middle column come from the hash and the right half mirrors them.
+func (i Identicon) eachCell(fn func(row, col int)) {
+	half := (gridCells + 1) / 2
+	for row := range gridCells {
+		for col := range half {
+			bit := row*half + col
+			if i.hash[2+bit/8]&(1<<(bit%8)) == 0 {
+				continue
+			}
+			fn(row, col)
+			if mirror := gridCells - 1 - col; mirror != col {
+				fn(row, mirror)
+			}
+		}
+	}
+}
+
+// color picks a hue from the hash with fixed saturation and lightness, so
+// white initials stay readable on every background.
+func (i Identicon) color() color.RGBA {
+	hue := float64(binary.BigEndian.Uint16(i.hash[:2])%360) / 360
+	return hslToRGB(hue, 0.55, 0.45)
+}
+
+// gridGeometry sizes the cells so the pattern has half a cell of margin on
+// every side.
+func gridGeometry(size int) (cell, margin int) {
+	cell = size / (gridCells + 1)
+	margin = (size - cell*gridCells) / 2
+	return cell, margin
+}
+
+// initialsOf takes the first letter of the first and last words.
+func initialsOf(fullName string) string {
+	var letters []rune
+	for _, word := range strings.Fields(fullName) {
+		for _, r := range word {
+			if unicode.IsLetter(r) {
+				letters = append(letters, unicode.ToUpper(r))
+				break
+			}
+		}
+	}
+	switch len(letters) {
+	case 0:
+		return ""
+	case 1:
+		return string(letters[0])
+	default:
+		return string([]rune{letters[0], letters[len(letters)-1]})
+	}
+}
+
+// drawable reports whether the bundled font has a glyph for every rune;
+// scripts it does not cover fall back to the pattern instead of boxes.
+func drawable(initials string) bool {
+	if initials == "" {
+		return false
+	}
+	f, err := parseFont()
+	if err != nil {
+		return false
+	}
+	var buf sfnt.Buffer
+	for _, r := range initials {
+		idx, err := f.GlyphIndex(&buf, r)
+		if err != nil || idx == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func hslToRGB(h, s, l float64) color.RGBA {
+	q := l + s - l*s
+	if l < 0.5 {
+		q = l * (1 + s)
+	}
+	p := 2*l - q
+	channel := func(t float64) uint8 {
+		switch {
+		case t < 0:
+			t++
+		case t > 1:
+			t--
+		}
+		var v float64
+		switch {
+		case t < 1.0/6:
+			v = p + (q-p)*6*t
+		case t < 1.0/2:
+			v = q
+		case t < 2.0/3:
+			v = p + (q-p)*(2.0/3-t)*6
+		default:
+			v = p
+		}
+		return uint8(v*255 + 0.5)
+	}
+	return color.RGBA{R: channel(h + 1.0/3), G: channel(h), B: channel(h - 1.0/3), A: 0xff}
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
@@ -0,0 +1,205 @@
+package identicon
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image"
+	"image/png"
+	"strconv"
+	"testing"
+)
+
+func TestNew_Deterministic(t *testing.T) {
+	tests := []struct {
+		name     string
+		userID   int64
+		fullName string
+	}{
+		{name: "initials", userID: 42, fullName: "Ada Lovelace"},
+		{name: "pattern", userID: 42, fullName: ""},
+		{name: "undrawable name", userID: 7, fullName: "山田 太郎"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first, second := New(tt.userID, tt.fullName), New(tt.userID, tt.fullName)
+			if first.Version() != second.Version() {
+				t.Errorf("expected the same version, got %s and %s", first.Version(), second.Version())
+			}
+
+			firstPNG, err := first.PNG(64)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			secondPNG, err := second.PNG(64)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(firstPNG, secondPNG) {
+				t.Error("expected identical png output")
+			}
+			if !bytes.Equal(first.SVG(64), second.SVG(64)) {
+				t.Error("expected identical svg output")
+			}
+		})
+	}
+}
+
+func TestNew_Version(t *testing.T) {
+	base := New(42, "Ada Lovelace")
+
+	tests := []struct {
+		name         string
+		icon         Identicon
+		expectedSame bool
+	}{
+		{name: "other user", icon: New(43, "Ada Lovelace"), expectedSame: false},
+		{name: "other initials", icon: New(42, "Grace Hopper"), expectedSame: false},
+		{name: "no name", icon: New(42, ""), expectedSame: false},
+		{name: "same initials", icon: New(42, "ada King lovelace"), expectedSame: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if same := tt.icon.Version() == base.Version(); same != tt.expectedSame {
+				t.Errorf("expected same version %v, got %s and %s", tt.expectedSame, base.Version(), tt.icon.Version())
+			}
+		})
+	}
+}
+
+// The version ends up in avatar URLs cached by clients, so it must not
+// change between processes or releases unless rendererVersion is bumped.
+func TestNew_VersionIsStable(t *testing.T) {
+	tests := []struct {
+		fullName string
+		expected string
+	}{
+		{fullName: "Ada Lovelace", expected: "ac96bf0c010e09fc"},
+		{fullName: "", expected: "f1b7079fc98c697f"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fullName, func(t *testing.T) {
+			if got := New(42, tt.fullName).Version(); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNew_UndrawableNameFallsBackToPattern(t *testing.T) {
+	if got, expected := New(7, "山田 太郎").Version(), New(7, "").Version(); got != expected {
+		t.Errorf("expected the pattern version %s, got %s", expected, got)
+	}
+}
+
+func TestIdenticon_PNG(t *testing.T) {
+	tests := []struct {
+		name     string
+		fullName string
+		size     int
+	}{
+		{name: "initials small", fullName: "Ada Lovelace", size: 32},
+		{name: "initials large", fullName: "Ada Lovelace", size: 256},
+		{name: "pattern small", fullName: "", size: 32},
+		{name: "pattern large", fullName: "", size: 256},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := New(42, tt.fullName).PNG(tt.size)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			img, format, err := image.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("output does not decode: %v", err)
+			}
+			if format != "png" {
+				t.Errorf("expected png, got %s", format)
+			}
+			if img.Bounds() != image.Rect(0, 0, tt.size, tt.size) {
+				t.Errorf("expected %dx%d, got %v", tt.size, tt.size, img.Bounds())
+			}
+		})
+	}
+}
+
+func TestIdenticon_PatternIsMirrored(t *testing.T) {
+	data, err := New(42, "").PNG(60)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("output does not decode: %v", err)
+	}
+
+	for y := range 60 {
+		for x := range 30 {
+			if img.At(x, y) != img.At(59-x, y) {
+				t.Fatalf("expected pixel (%d, %d) to mirror (%d, %d)", x, y, 59-x, y)
+			}
+		}
+	}
+}
+
+func TestIdenticon_SVG(t *testing.T) {
+	tests := []struct {
+		name         string
+		fullName     string
+		size         int
+		expectedText string
+	}{
+		{name: "initials", fullName: "Ada Lovelace", size: 48, expectedText: "AL"},
+		{name: "accented initials", fullName: "Émile Zola", size: 48, expectedText: "ÉZ"},
+		{name: "pattern", fullName: "", size: 128},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var svg struct {
+				XMLName xml.Name   `xml:"svg"`
+				Width   string     `xml:"width,attr"`
+				Height  string     `xml:"height,attr"`
+				Rects   []struct{} `xml:"rect"`
+				Text    string     `xml:"text"`
+			}
+			if err := xml.Unmarshal(New(42, tt.fullName).SVG(tt.size), &svg); err != nil {
+				t.Fatalf("output is not valid xml: %v", err)
+			}
+			expectedSize := strconv.Itoa(tt.size)
+			if svg.Width != expectedSize || svg.Height != expectedSize {
+				t.Errorf("expected %sx%s, got %sx%s", expectedSize, expectedSize, svg.Width, svg.Height)
+			}
+			if svg.Text != tt.expectedText {
+				t.Errorf("expected text %q, got %q", tt.expectedText, svg.Text)
+			}
+			if len(svg.Rects) == 0 {
+				t.Error("expected a background")
+			}
+		})
+	}
+}
+
+func TestInitialsOf(t *testing.T) {
+	tests := []struct {
+		fullName string
+		expected string
+	}{
+		{fullName: "Ada Lovelace", expected: "AL"},
+		{fullName: "ada king lovelace", expected: "AL"},
+		{fullName: "Cher", expected: "C"},
+		{fullName: "  ", expected: ""},
+		{fullName: "123 \"Bob\"", expected: "B"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fullName, func(t *testing.T) {
+			if got := initialsOf(tt.fullName); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+// Package imageproc validates user-uploaded images and turns them into
+// square thumbnails. Images are always decoded and re-encoded, so metadata
+// such as EXIF location data never survives an upload.
+package imageproc
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"server/internal/domain"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+const (
+	ContentTypePNG  = "image/png"
+	ContentTypeJPEG = "image/jpeg"
+	ContentTypeWebP = "image/webp"
+
+	// maxPixels bounds the decoded size so a small, highly compressed file
+	// cannot make us allocate gigabytes.
+	maxPixels   = 40_000_000
+	jpegQuality = 85
+)
+
+// Sniff returns the content type detected from the data itself, ignoring
+// whatever the client claimed. Only PNG, JPEG and WebP are accepted.
+func Sniff(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	switch contentType {
+	case ContentTypePNG, ContentTypeJPEG, ContentTypeWebP:
+		return contentType, nil
+	default:
+		return "", fmt.Errorf("%w: %s", domain.ErrUnsupportedImage, contentType)
+	}
+}
+
+// Decode sniffs and decodes the image with the decoder of the detected
+// format only. JPEGs are turned upright by their EXIF orientation, since
+// the metadata saying how to show them is dropped when re-encoding.
+func Decode(data []byte) (image.Image, string, error) {
+	contentType, err := Sniff(data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var decodeConfig func([]byte) (image.Config, error)
+	var decode func([]byte) (image.Image, error)
+	switch contentType {
+	case ContentTypePNG:
+		decodeConfig = func(b []byte) (image.Config, error) { return png.DecodeConfig(bytes.NewReader(b)) }
+		decode = func(b []byte) (image.Image, error) { return png.Decode(bytes.NewReader(b)) }
+	case ContentTypeJPEG:
+		decodeConfig = func(b []byte) (image.Config, error) { return jpeg.DecodeConfig(bytes.NewReader(b)) }
+		decode = func(b []byte) (image.Image, error) { return jpeg.Decode(bytes.NewReader(b)) }
+	case ContentTypeWebP:
+		decodeConfig = func(b []byte) (image.Config, error) { return webp.DecodeConfig(bytes.NewReader(b)) }
+		decode = func(b []byte) (image.Image, error) { return webp.Decode(bytes.NewReader(b)) }
+	}
+
+	config, err := decodeConfig(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", domain.ErrUnsupportedImage, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, "", fmt.Errorf("%w: empty image", domain.ErrUnsupportedImage)
+	}
+	if config.Width*config.Height > maxPixels {
+		return nil, "", fmt.Errorf("%w: %dx%d pixels", domain.ErrImageTooLarge, config.Width, config.Height)
+	}
+
+	img, err := decode(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", domain.ErrUnsupportedImage, err)
+	}
+	if contentType == ContentTypeJPEG {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+	return img, contentType, nil
+}
+
+// Thumbnail crops the centre square of src and scales it to size x size.
+func Thumbnail(src image.Image, size int) image.Image {
+	bounds := src.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	crop := image.Rect(0, 0, side, side).Add(image.Point{
+		X: bounds.Min.X + (bounds.Dx()-side)/2,
+		Y: bounds.Min.Y + (bounds.Dy()-side)/2,
+	})
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Src, nil)
+	return dst
+}
+
+// EncodedContentType is the format Encode writes for a source format: JPEG
+// stays JPEG and everything else becomes PNG, which keeps transparency.
+// WebP uploads are stored as PNG on purpose, as neither the standard
+// library nor golang.org/x/image can encode WebP.
+func EncodedContentType(sourceContentType string) string {
+	if sourceContentType == ContentTypeJPEG {
+		return ContentTypeJPEG
+	}
+	return ContentTypePNG
+}
+
+// Encode writes img in the format given by EncodedContentType.
+func Encode(img image.Image, sourceContentType string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	if EncodedContentType(sourceContentType) == ContentTypeJPEG {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,334 @@
+package imageproc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"server/internal/domain"
+	"testing"
+)
+
+var (
+	red   = color.RGBA{R: 255, A: 255}
+	green = color.RGBA{G: 255, A: 255}
+	blue  = color.RGBA{B: 255, A: 255}
+	white = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+)
+
+// quadrants returns a w x h image with red, green, blue and white quarters
+// in reading order, so every turn and mirror of it looks different.
+func quadrants(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			switch {
+			case x < w/2 && y < h/2:
+				img.Set(x, y, red)
+			case y < h/2:
+				img.Set(x, y, green)
+			case x < w/2:
+				img.Set(x, y, blue)
+			default:
+				img.Set(x, y, white)
+			}
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("failed to encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatalf("failed to encode jpeg: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	return data
+}
+
+// withOrientation inserts an EXIF segment holding only the orientation tag
+// right after the start of a JPEG, the way cameras store it.
+func withOrientation(data []byte, order binary.ByteOrder, orientation uint16) []byte {
+	tiff := make([]byte, 26)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 1)
+	order.PutUint16(tiff[10:], exifOrientationTag)
+	order.PutUint16(tiff[12:], 3)
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], orientation)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(2+len(segment)))
+	app1 = append(app1, segment...)
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, app1...)
+	return append(out, data[2:]...)
+}
+
+// nearest names the quadrant colour closest to c, which absorbs JPEG loss.
+func nearest(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	names := []string{"red", "green", "blue", "white"}
+	best, bestDistance := "", int64(-1)
+	for i, candidate := range []color.RGBA{red, green, blue, white} {
+		cr, cg, cb, _ := candidate.RGBA()
+		distance := sq(int64(r)-int64(cr)) + sq(int64(g)-int64(cg)) + sq(int64(b)-int64(cb))
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = names[i], distance
+		}
+	}
+	return best
+}
+
+func sq(v int64) int64 { return v * v }
+
+// corners names the colours in the middle of each quarter of img, top left
+// first in reading order.
+func corners(img image.Image) [4]string {
+	b := img.Bounds()
+	x0, x1 := b.Min.X+b.Dx()/4, b.Min.X+b.Dx()*3/4
+	y0, y1 := b.Min.Y+b.Dy()/4, b.Min.Y+b.Dy()*3/4
+	return [4]string{nearest(img.At(x0, y0)), nearest(img.At(x1, y0)), nearest(img.At(x0, y1)), nearest(img.At(x1, y1))}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name                string
+		data                []byte
+		expectedContentType string
+		expectedBounds      image.Rectangle
+	}{
+		{name: "png", data: encodePNG(t, quadrants(6, 4)), expectedContentType: ContentTypePNG, expectedBounds: image.Rect(0, 0, 6, 4)},
+		{name: "jpeg", data: encodeJPEG(t, quadrants(6, 4)), expectedContentType: ContentTypeJPEG, expectedBounds: image.Rect(0, 0, 6, 4)},
+		{name: "lossy webp", data: readFixture(t, "lossy.webp"), expectedContentType: ContentTypeWebP, expectedBounds: image.Rect(0, 0, 1, 1)},
+		{name: "lossless webp", data: readFixture(t, "lossless.webp"), expectedContentType: ContentTypeWebP, expectedBounds: image.Rect(0, 0, 1, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, contentType, err := Decode(tt.data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if contentType != tt.expectedContentType {
+				t.Errorf("expected %s, got %s", tt.expectedContentType, contentType)
+			}
+			if img.Bounds() != tt.expectedBounds {
+				t.Errorf("expected bounds %v, got %v", tt.expectedBounds, img.Bounds())
+			}
+		})
+	}
+}
+
+// hugePNG is a valid PNG header announcing width x height pixels; the size
+// check has to reject it before any pixel data is needed.
+func hugePNG(t *testing.T, width, height uint32) []byte {
+	data := encodePNG(t, image.NewRGBA(image.Rect(0, 0, 1, 1)))
+	// Signature (8), length (4), "IHDR" (4), then width and height.
+	binary.BigEndian.PutUint32(data[16:], width)
+	binary.BigEndian.PutUint32(data[20:], height)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+	return data
+}
+
+func TestDecode_Errors(t *testing.T) {
+	pngData := encodePNG(t, quadrants(6, 4))
+
+	tests := []struct {
+		name          string
+		data          []byte
+		expectedError error
+	}{
+		{name: "gif", data: []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00;"), expectedError: domain.ErrUnsupportedImage},
+		{name: "svg", data: []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`), expectedError: domain.ErrUnsupportedImage},
+		{name: "truncated png", data: pngData[:len(pngData)/2], expectedError: domain.ErrUnsupportedImage},
+		{name: "too many pixels", data: hugePNG(t, 10000, 10000), expectedError: domain.ErrImageTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := Decode(tt.data); !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestDecode_EXIFOrientation(t *testing.T) {
+	// The stored picture is 32x16: red, green / blue, white.
+	stored := encodeJPEG(t, quadrants(32, 16))
+
+	tests := []struct {
+		orientation     uint16
+		order           binary.ByteOrder
+		expectedBounds  image.Rectangle
+		expectedCorners [4]string
+	}{
+		{orientation: 1, order: binary.LittleEndian, expectedBounds: image.Rect(0, 0, 32, 16), expectedCorners: [4]string{"red", "green", "blue", "white"}},
+		{orientation: 2, order: binary.LittleEndian, expectedBounds: image.Rect(0, 0, 32, 16), expectedCorners: [4]string{"green", "red", "white", "blue"}},
+		{orientation: 3, order: binary.BigEndian, expectedBounds: image.Rect(0, 0, 32, 16), expectedCorners: [4]string{"white", "blue", "green", "red"}},
+		{orientation: 4, order: binary.LittleEndian, expectedBounds: image.Rect(0, 0, 32, 16), expectedCorners: [4]string{"blue", "white", "red", "green"}},
+		{orientation: 5, order: binary.LittleEndian, expectedBounds: image.Rect(0, 0, 16, 32), expectedCorners: [4]string{"red", "blue", "green", "white"}},
+		{orientation: 6, order: binary.BigEndian, expectedBounds: image.Rect(0, 0, 16, 32), expectedCorners: [4]string{"blue", "red", "white", "green"}},
+		{orientation: 7, order: binary.LittleEndian, expectedBounds: image.Rect(0, 0, 16, 32), expectedCorners: [4]string{"white", "green", "blue", "red"}},
+		{orientation: 8, order: binary.LittleEndian, expectedBounds: image.Rect(0, 0, 16, 32), expectedCorners: [4]string{"green", "white", "red", "blue"}},
+		{orientation: 9, order: binary.LittleEndian, expectedBounds: image.Rect(0, 0, 32, 16), expectedCorners: [4]string{"red", "green", "blue", "white"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(rune('0'+tt.orientation)), func(t *testing.T) {
+			img, _, err := Decode(withOrientation(stored, tt.order, tt.orientation))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if img.Bounds() != tt.expectedBounds {
+				t.Errorf("expected bounds %v, got %v", tt.expectedBounds, img.Bounds())
+			}
+			if got := corners(img); got != tt.expectedCorners {
+				t.Errorf("expected %v, got %v", tt.expectedCorners, got)
+			}
+		})
+	}
+}
+
+func TestJPEGOrientation_Malformed(t *testing.T) {
+	stored := encodeJPEG(t, quadrants(8, 8))
+	rotated := withOrientation(stored, binary.LittleEndian, 6)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "no exif", data: stored},
+		{name: "not a jpeg", data: encodePNG(t, quadrants(8, 8))},
+		{name: "cut inside the exif segment", data: rotated[:20]},
+		{name: "bad tiff header", data: bytes.Replace(rotated, []byte("Exif\x00\x00II"), []byte("Exif\x00\x00XX"), 1)},
+		{name: "empty", data: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jpegOrientation(tt.data); got != 1 {
+				t.Errorf("expected orientation 1, got %d", got)
+			}
+		})
+	}
+	if got := jpegOrientation(rotated); got != 6 {
+		t.Errorf("expected orientation 6, got %d", got)
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	// A 30x10 strip of red, green and blue squares; the centre square is
+	// the green one.
+	src := image.NewRGBA(image.Rect(0, 0, 30, 10))
+	for y := range 10 {
+		for x := range 30 {
+			src.Set(x, y, []color.RGBA{red, green, blue}[x/10])
+		}
+	}
+
+	tests := []struct {
+		name string
+		src  image.Image
+		size int
+	}{
+		{name: "wide source", src: src, size: 4},
+		{name: "tall source", src: src.SubImage(image.Rect(10, 0, 20, 10)), size: 16},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thumb := Thumbnail(tt.src, tt.size)
+			if thumb.Bounds() != image.Rect(0, 0, tt.size, tt.size) {
+				t.Fatalf("expected %dx%d, got %v", tt.size, tt.size, thumb.Bounds())
+			}
+			for _, p := range []image.Point{{0, 0}, {tt.size - 1, 0}, {tt.size / 2, tt.size / 2}, {tt.size - 1, tt.size - 1}} {
+				if got := nearest(thumb.At(p.X, p.Y)); got != "green" {
+					t.Errorf("expected the centre square at %v, got %s", p, got)
+				}
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	img := quadrants(8, 8)
+
+	tests := []struct {
+		name                string
+		sourceContentType   string
+		expectedContentType string
+	}{
+		{name: "jpeg stays jpeg", sourceContentType: ContentTypeJPEG, expectedContentType: ContentTypeJPEG},
+		{name: "png stays png", sourceContentType: ContentTypePNG, expectedContentType: ContentTypePNG},
+		{name: "webp becomes png", sourceContentType: ContentTypeWebP, expectedContentType: ContentTypePNG},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EncodedContentType(tt.sourceContentType); got != tt.expectedContentType {
+				t.Errorf("expected %s, got %s", tt.expectedContentType, got)
+			}
+			data, err := Encode(img, tt.sourceContentType)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			decoded, contentType, err := Decode(data)
+			if err != nil {
+				t.Fatalf("encoded image does not decode: %v", err)
+			}
+			if contentType != tt.expectedContentType {
+				t.Errorf("expected %s output, got %s", tt.expectedContentType, contentType)
+			}
+			if got := corners(decoded); got != [4]string{"red", "green", "blue", "white"} {
+				t.Errorf("expected the picture to survive re-encoding, got %v", got)
+			}
+		})
+	}
+}
+
+func TestEncode_DropsEXIF(t *testing.T) {
+	img, contentType, err := Decode(withOrientation(encodeJPEG(t, quadrants(8, 8)), binary.LittleEndian, 6))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := Encode(img, contentType)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if bytes.Contains(data, []byte("Exif")) {
+		t.Error("expected metadata not to survive re-encoding")
+	}
+}
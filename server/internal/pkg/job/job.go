@@ -0,0 +1,26 @@
+package job
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// RunPeriodically calls fn every interval until ctx is cancelled. Errors are
+// logged and the job keeps running on the next tick.
+func RunPeriodically(ctx context.Context, logger *slog.Logger, name string, interval time.Duration, fn func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("background job stopped", "job", name)
+			return
+		case <-ticker.C:
+			if err := fn(ctx); err != nil {
+				logger.Error("background job failed", "job", name, "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// Signing algorithms, chosen by the type of the key.
+const (
+	AlgEdDSA = "EdDSA"
+	AlgRS256 = "RS256"
+)
+
+// minRSABits is the smallest RSA key accepted for RS256.
+const minRSABits = 2048
+
+// Key is a private signing key with the id published as the kid header.
+type Key struct {
+	ID         string
+	Algorithm  string
+	privateKey crypto.Signer
+}
+
+// LoadKey reads a PEM encoded Ed25519 or RSA private key. PKCS#8 is
+// accepted for both, PKCS#1 for RSA.
+func LoadKey(id, path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key %s: %w", id, err)
+	}
+	return ParseKey(id, data)
+}
+
+func ParseKey(id string, data []byte) (*Key, error) {
+	if id == "" {
+		return nil, errors.New("key id is empty")
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key %s is not PEM encoded", id)
+	}
+
+	var parsed any
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("key %s: unsupported PEM block %q", id, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key %s: %w", id, err)
+	}
+
+	switch key := parsed.(type) {
+	case ed25519.PrivateKey:
+		return &Key{ID: id, Algorithm: AlgEdDSA, privateKey: key}, nil
+	case *rsa.PrivateKey:
+		if key.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("key %s: RSA keys need at least %d bits", id, minRSABits)
+		}
+		return &Key{ID: id, Algorithm: AlgRS256, privateKey: key}, nil
+	default:
+		return nil, fmt.Errorf("key %s: only Ed25519 and RSA keys are supported", id)
+	}
+}
+
+func (k *Key) sign(data []byte) ([]byte, error) {
+	if k.Algorithm == AlgRS256 {
+		digest := sha256.Sum256(data)
+		return k.privateKey.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	return k.privateKey.Sign(rand.Reader, data, crypto.Hash(0))
+}
+
+// JWK is the public half of a key as published in a JWK set (RFC 7517).
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+func (k *Key) jwk() JWK {
+	jwk := JWK{KeyID: k.ID, Algorithm: k.Algorithm, Use: "sig"}
+	switch public := k.privateKey.Public().(type) {
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = encodeSegment(public)
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = encodeSegment(public.N.Bytes())
+		jwk.E = encodeSegment(big.NewInt(int64(public.E)).Bytes())
+	}
+	return jwk
+}
+
+// Signer signs tokens with its active key and publishes every key it holds.
+// Keeping the previous key configured after switching the active one lets
+// services verify tokens signed before the rotation until they expire.
+type Signer struct {
+	active *Key
+	keys   []*Key
+}
+
+func NewSigner(keys []*Key, activeID string) (*Signer, error) {
+	signer := &Signer{keys: keys}
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if seen[key.ID] {
+			return nil, fmt.Errorf("duplicate key id %s", key.ID)
+		}
+		seen[key.ID] = true
+		if key.ID == activeID {
+			signer.active = key
+		}
+	}
+	if signer.active == nil {
+		return nil, fmt.Errorf("signing key %q is not configured", activeID)
+	}
+	return signer, nil
+}
+
+// Sign returns a compact JWS of claims with the alg and kid of the active key.
+func (s *Signer) Sign(claims map[string]any) (string, error) {
+	header, err := json.Marshal(map[string]string{
+		"alg": s.active.Algorithm,
+		"kid": s.active.ID,
+		"typ": "JWT",
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode header: %w", err)
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode claims: %w", err)
+	}
+
+	signingInput := encodeSegment(header) + "." + encodeSegment(payload)
+	signature, err := s.active.sign([]byte(signingInput))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return signingInput + "." + encodeSegment(signature), nil
+}
+
+func (s *Signer) JWKS() JWKS {
+	set := JWKS{Keys: make([]JWK, 0, len(s.keys))}
+	for _, key := range s.keys {
+		set.Keys = append(set.Keys, key.jwk())
+	}
+	return set
+}
+
+func encodeSegment(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
@@ -0,0 +1,304 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func pkcs8PEM(t *testing.T, key any) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func pkcs1PEM(key *rsa.PrivateKey) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+func newEd25519PEM(t *testing.T) []byte {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return pkcs8PEM(t, key)
+}
+
+func newRSAKey(t *testing.T, bits int) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return key
+}
+
+func mustParseKey(t *testing.T, id string, data []byte) *Key {
+	t.Helper()
+	key, err := ParseKey(id, data)
+	if err != nil {
+		t.Fatalf("failed to parse key: %v", err)
+	}
+	return key
+}
+
+func decodeSegment(t *testing.T, segment string) []byte {
+	t.Helper()
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		t.Fatalf("segment %q is not base64url: %v", segment, err)
+	}
+	return data
+}
+
+// verify checks token the way a relying service would: it picks the key
+// named by the kid header from the published set and checks the signature
+// with the algorithm that key is published for.
+func verify(t *testing.T, token string, set JWKS) (map[string]string, map[string]any) {
+	t.Helper()
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("expected three segments, got %d", len(parts))
+	}
+
+	var header map[string]string
+	if err := json.Unmarshal(decodeSegment(t, parts[0]), &header); err != nil {
+		t.Fatalf("failed to decode header: %v", err)
+	}
+	var claims map[string]any
+	if err := json.Unmarshal(decodeSegment(t, parts[1]), &claims); err != nil {
+		t.Fatalf("failed to decode claims: %v", err)
+	}
+
+	var jwk *JWK
+	for i := range set.Keys {
+		if set.Keys[i].KeyID == header["kid"] {
+			jwk = &set.Keys[i]
+		}
+	}
+	if jwk == nil {
+		t.Fatalf("kid %q is not published", header["kid"])
+	}
+	if jwk.Algorithm != header["alg"] || jwk.Use != "sig" {
+		t.Fatalf("header alg %q does not match published key %+v", header["alg"], jwk)
+	}
+
+	signingInput := []byte(parts[0] + "." + parts[1])
+	signature := decodeSegment(t, parts[2])
+	switch jwk.KeyType {
+	case "OKP":
+		if jwk.Curve != "Ed25519" || header["alg"] != AlgEdDSA {
+			t.Fatalf("unexpected OKP key %+v", jwk)
+		}
+		if !ed25519.Verify(ed25519.PublicKey(decodeSegment(t, jwk.X)), signingInput, signature) {
+			t.Fatal("Ed25519 signature does not verify against the JWKS")
+		}
+	case "RSA":
+		if header["alg"] != AlgRS256 {
+			t.Fatalf("unexpected RSA alg %q", header["alg"])
+		}
+		public := &rsa.PublicKey{
+			N: new(big.Int).SetBytes(decodeSegment(t, jwk.N)),
+			E: int(new(big.Int).SetBytes(decodeSegment(t, jwk.E)).Int64()),
+		}
+		digest := sha256.Sum256(signingInput)
+		if err := rsa.VerifyPKCS1v15(public, crypto.SHA256, digest[:], signature); err != nil {
+			t.Fatalf("RS256 signature does not verify against the JWKS: %v", err)
+		}
+	default:
+		t.Fatalf("unexpected key type %q", jwk.KeyType)
+	}
+	return header, claims
+}
+
+func TestSigner_SignVerifiesAgainstJWKS(t *testing.T) {
+	rsaKey := newRSAKey(t, 2048)
+
+	tests := []struct {
+		name        string
+		pem         []byte
+		expectedAlg string
+		expectedKty string
+	}{
+		{name: "Ed25519 PKCS#8", pem: newEd25519PEM(t), expectedAlg: AlgEdDSA, expectedKty: "OKP"},
+		{name: "RSA PKCS#1", pem: pkcs1PEM(rsaKey), expectedAlg: AlgRS256, expectedKty: "RSA"},
+		{name: "RSA PKCS#8", pem: pkcs8PEM(t, rsaKey), expectedAlg: AlgRS256, expectedKty: "RSA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := mustParseKey(t, "key-1", tt.pem)
+			if key.Algorithm != tt.expectedAlg {
+				t.Fatalf("expected algorithm %s, got %s", tt.expectedAlg, key.Algorithm)
+			}
+			signer, err := NewSigner([]*Key{key}, "key-1")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			token, err := signer.Sign(map[string]any{"sub": "42", "exp": 1700000000})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			set := signer.JWKS()
+			if len(set.Keys) != 1 || set.Keys[0].KeyType != tt.expectedKty {
+				t.Fatalf("unexpected JWKS %+v", set)
+			}
+
+			header, claims := verify(t, token, set)
+			if header["alg"] != tt.expectedAlg || header["kid"] != "key-1" || header["typ"] != "JWT" {
+				t.Errorf("unexpected header %v", header)
+			}
+			if claims["sub"] != "42" || claims["exp"] != float64(1700000000) {
+				t.Errorf("unexpected claims %v", claims)
+			}
+		})
+	}
+}
+
+func TestSigner_SignatureCoversToken(t *testing.T) {
+	signer, err := NewSigner([]*Key{mustParseKey(t, "key-1", newEd25519PEM(t))}, "key-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	token, err := signer.Sign(map[string]any{"sub": "42"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	parts := strings.Split(token, ".")
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"1"}`))
+	public := ed25519.PublicKey(decodeSegment(t, signer.JWKS().Keys[0].X))
+	if ed25519.Verify(public, []byte(parts[0]+"."+forged), decodeSegment(t, parts[2])) {
+		t.Error("expected a changed payload not to verify")
+	}
+}
+
+func TestNewSigner_Rotation(t *testing.T) {
+	oldKey := mustParseKey(t, "2026-01", pkcs1PEM(newRSAKey(t, 2048)))
+	newKey := mustParseKey(t, "2026-07", newEd25519PEM(t))
+
+	signer, err := NewSigner([]*Key{oldKey, newKey}, "2026-07")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	set := signer.JWKS()
+	if len(set.Keys) != 2 || set.Keys[0].KeyID != "2026-01" || set.Keys[1].KeyID != "2026-07" {
+		t.Fatalf("expected both keys to be published in order, got %+v", set)
+	}
+	if set.Keys[0].Algorithm != AlgRS256 || set.Keys[0].N == "" || set.Keys[0].E != "AQAB" || set.Keys[0].X != "" {
+		t.Errorf("unexpected RSA JWK %+v", set.Keys[0])
+	}
+	if set.Keys[1].Algorithm != AlgEdDSA || set.Keys[1].X == "" || set.Keys[1].N != "" {
+		t.Errorf("unexpected Ed25519 JWK %+v", set.Keys[1])
+	}
+
+	token, err := signer.Sign(map[string]any{"sub": "42"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if header, _ := verify(t, token, set); header["kid"] != "2026-07" {
+		t.Errorf("expected the active key to sign, got kid %q", header["kid"])
+	}
+
+	// A token signed before the rotation still verifies against the set.
+	previous, err := NewSigner([]*Key{oldKey}, "2026-01")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	oldToken, err := previous.Sign(map[string]any{"sub": "42"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	verify(t, oldToken, set)
+}
+
+func TestNewSigner_Errors(t *testing.T) {
+	first := mustParseKey(t, "a", newEd25519PEM(t))
+	second := mustParseKey(t, "a", newEd25519PEM(t))
+
+	tests := []struct {
+		name     string
+		keys     []*Key
+		activeID string
+	}{
+		{name: "duplicate key id", keys: []*Key{first, second}, activeID: "a"},
+		{name: "active key not configured", keys: []*Key{first}, activeID: "b"},
+		{name: "no keys", keys: nil, activeID: "a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewSigner(tt.keys, tt.activeID); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestParseKey_Errors(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		id       string
+		data     []byte
+		contains string
+	}{
+		{name: "empty id", id: "", data: newEd25519PEM(t), contains: "empty"},
+		{name: "not PEM", id: "k", data: []byte("not a key"), contains: "not PEM"},
+		{name: "unsupported block", id: "k", data: pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte{1}}), contains: "unsupported PEM block"},
+		{name: "corrupt key", id: "k", data: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte{1, 2, 3}}), contains: "failed to parse"},
+		{name: "short RSA key", id: "k", data: pkcs1PEM(newRSAKey(t, 1024)), contains: "at least 2048 bits"},
+		{name: "ECDSA key", id: "k", data: pkcs8PEM(t, ecKey), contains: "only Ed25519 and RSA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseKey(tt.id, tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("expected error containing %q, got %v", tt.contains, err)
+			}
+		})
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signing.pem")
+	if err := os.WriteFile(path, newEd25519PEM(t), 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+
+	key, err := LoadKey("file", path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key.ID != "file" || key.Algorithm != AlgEdDSA {
+		t.Errorf("unexpected key %+v", key)
+	}
+
+	if _, err := LoadKey("missing", filepath.Join(t.TempDir(), "missing.pem")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
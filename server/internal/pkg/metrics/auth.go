@@ -0,0 +1,26 @@
+package metrics
+
+// AuthMetrics counts login and sign-up outcomes.
+type AuthMetrics struct {
+	logins  *CounterVec
+	signups *CounterVec
+}
+
+func NewAuthMetrics(r *Registry) *AuthMetrics {
+	return &AuthMetrics{
+		logins: r.NewCounterVec("auth_logins_total",
+			"Login attempts by method, outcome and failure reason.", "method", "outcome", "reason"),
+		signups: r.NewCounterVec("auth_signups_total",
+			"Sign-up attempts by provider, outcome and failure reason.", "provider", "outcome", "reason"),
+	}
+}
+
+// ObserveLogin counts a login; reason is empty for successful logins.
+func (m *AuthMetrics) ObserveLogin(method, outcome, reason string) {
+	m.logins.Inc(method, outcome, reason)
+}
+
+// ObserveSignUp counts a sign-up; reason is empty for successful sign-ups.
+func (m *AuthMetrics) ObserveSignUp(provider, outcome, reason string) {
+	m.signups.Inc(provider, outcome, reason)
+}
@@ -0,0 +1,168 @@
+package metrics
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func scrape(t *testing.T, r *Registry) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/plain; version=0.0.4; charset=utf-8" {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	return rec.Body.String()
+}
+
+func TestRegistry_Exposition(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounterVec("requests_total", "Requests by path.", "path")
+	duration := r.NewHistogramVec("duration_seconds", "Request latency.", []float64{1, 0.5}, "path")
+	r.NewGaugeFunc("queue_length", "Jobs waiting.", func() float64 { return 3 })
+	r.NewInfo("build_info", "Build information.", map[string]string{"version": "1.0", "commit": "abc"})
+
+	requests.Inc("/b")
+	requests.Add(2.5, "/a")
+	duration.Observe(0.25, "/a")
+	duration.Observe(0.75, "/a")
+	duration.Observe(2, "/a")
+
+	expected := `# HELP build_info Build information.
+# TYPE build_info gauge
+build_info{commit="abc",version="1.0"} 1
+# HELP duration_seconds Request latency.
+# TYPE duration_seconds histogram
+duration_seconds_bucket{path="/a",le="0.5"} 1
+duration_seconds_bucket{path="/a",le="1"} 2
+duration_seconds_bucket{path="/a",le="+Inf"} 3
+duration_seconds_sum{path="/a"} 3
+duration_seconds_count{path="/a"} 3
+# HELP queue_length Jobs waiting.
+# TYPE queue_length gauge
+queue_length 3
+# HELP requests_total Requests by path.
+# TYPE requests_total counter
+requests_total{path="/a"} 2.5
+requests_total{path="/b"} 1
+`
+	if got := scrape(t, r); got != expected {
+		t.Errorf("unexpected exposition:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestRegistry_Escaping(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("escaped_total", "Help with \\ backslash\nand newline.", "value")
+	c.Inc("quote \" backslash \\ newline \n end")
+
+	expected := `# HELP escaped_total Help with \\ backslash\nand newline.
+# TYPE escaped_total counter
+escaped_total{value="quote \" backslash \\ newline \n end"} 1
+`
+	if got := scrape(t, r); got != expected {
+		t.Errorf("unexpected exposition:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestHistogramVec_BucketBoundaries(t *testing.T) {
+	r := NewRegistry()
+	h := r.NewHistogramVec("size", "Sizes.", []float64{1, 2})
+	for _, v := range []float64{1, 2, 2.0001} {
+		h.Observe(v)
+	}
+
+	out := scrape(t, r)
+	for _, line := range []string{`size_bucket{le="1"} 1`, `size_bucket{le="2"} 2`, `size_bucket{le="+Inf"} 3`, `size_count 3`} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("expected line %q in:\n%s", line, out)
+		}
+	}
+}
+
+var (
+	commentLine = regexp.MustCompile(`^# (HELP|TYPE) ([a-zA-Z_:][a-zA-Z0-9_:]*) (.*)$`)
+	sampleLine  = regexp.MustCompile(`^([a-zA-Z_:][a-zA-Z0-9_:]*)(\{[a-zA-Z_][a-zA-Z0-9_]*="(?:[^"\\]|\\.)*"(?:,[a-zA-Z_][a-zA-Z0-9_]*="(?:[^"\\]|\\.)*")*\})? (\S+)$`)
+)
+
+// TestRegistry_RuntimeMetricsParse checks every line of a scrape with the
+// runtime and pool metrics against the text format grammar, and that every
+// sample belongs to a family declared before it.
+func TestRegistry_RuntimeMetricsParse(t *testing.T) {
+	r := NewRegistry()
+	r.RegisterGoRuntime()
+	NewAuthMetrics(r).ObserveLogin("password", "failure", "invalid_password")
+
+	declared := map[string]string{}
+	scanner := bufio.NewScanner(strings.NewReader(scrape(t, r)))
+	samples := 0
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := commentLine.FindStringSubmatch(line); m != nil {
+			if m[1] == "TYPE" {
+				declared[m[2]] = m[3]
+			}
+			continue
+		}
+		m := sampleLine.FindStringSubmatch(line)
+		if m == nil {
+			t.Errorf("line does not parse: %q", line)
+			continue
+		}
+		samples++
+		name := m[1]
+		for _, suffix := range []string{"_bucket", "_sum", "_count"} {
+			if base, ok := strings.CutSuffix(name, suffix); ok && declared[base] == "histogram" {
+				name = base
+			}
+		}
+		if _, ok := declared[name]; !ok {
+			t.Errorf("sample %q has no TYPE line before it", line)
+		}
+	}
+	if samples == 0 {
+		t.Error("expected samples in the scrape")
+	}
+	if declared["auth_logins_total"] != "counter" || declared["go_goroutines"] != "gauge" {
+		t.Errorf("unexpected families %v", declared)
+	}
+}
+
+func TestRegistry_Panics(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(r *Registry)
+	}{
+		{
+			name: "duplicate metric",
+			fn: func(r *Registry) {
+				r.NewCounterVec("dup_total", "First.")
+				r.NewGaugeFunc("dup_total", "Second.", func() float64 { return 0 })
+			},
+		},
+		{
+			name: "wrong number of label values",
+			fn: func(r *Registry) {
+				r.NewCounterVec("labels_total", "Labels.", "a", "b").Inc("only-one")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected a panic")
+				}
+			}()
+			tt.fn(NewRegistry())
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"database/sql"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// RegisterGoRuntime adds goroutine, memory and GC metrics under the names
+// used by the official Go client, plus process_start_time_seconds.
+func (r *Registry) RegisterGoRuntime() {
+	stats := &memStatsCache{}
+
+	r.NewInfo("go_info", "Information about the Go environment.", map[string]string{"version": runtime.Version()})
+	r.NewGaugeFunc("go_goroutines", "Number of goroutines that currently exist.", func() float64 {
+		return float64(runtime.NumGoroutine())
+	})
+	r.NewGaugeFunc("go_memstats_alloc_bytes", "Number of bytes allocated and still in use.", func() float64 {
+		return float64(stats.get().Alloc)
+	})
+	r.NewCounterFunc("go_memstats_alloc_bytes_total", "Total number of bytes allocated, even if freed.", func() float64 {
+		return float64(stats.get().TotalAlloc)
+	})
+	r.NewGaugeFunc("go_memstats_sys_bytes", "Number of bytes obtained from system.", func() float64 {
+		return float64(stats.get().Sys)
+	})
+	r.NewGaugeFunc("go_memstats_heap_inuse_bytes", "Number of heap bytes that are in use.", func() float64 {
+		return float64(stats.get().HeapInuse)
+	})
+	r.NewGaugeFunc("go_memstats_heap_objects", "Number of allocated objects.", func() float64 {
+		return float64(stats.get().HeapObjects)
+	})
+	r.NewGaugeFunc("go_memstats_last_gc_time_seconds", "Number of seconds since 1970 of last garbage collection.", func() float64 {
+		return float64(stats.get().LastGC) / 1e9
+	})
+	r.NewCounterFunc("go_gc_cycles_total", "Number of completed GC cycles.", func() float64 {
+		return float64(stats.get().NumGC)
+	})
+	r.NewCounterFunc("go_gc_pause_seconds_total", "Total time the program was stopped for GC.", func() float64 {
+		return float64(stats.get().PauseTotalNs) / 1e9
+	})
+
+	start := float64(time.Now().Unix())
+	r.NewGaugeFunc("process_start_time_seconds", "Start time of the process since unix epoch in seconds.", func() float64 {
+		return start
+	})
+}
+
+// memStatsCache shares one runtime.ReadMemStats, which stops the world,
+// between the metrics of a scrape.
+type memStatsCache struct {
+	mu     sync.Mutex
+	stats  runtime.MemStats
+	readAt time.Time
+}
+
+func (c *memStatsCache) get() *runtime.MemStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Since(c.readAt) > time.Second {
+		runtime.ReadMemStats(&c.stats)
+		c.readAt = time.Now()
+	}
+	return &c.stats
+}
+
+// RegisterDBStats exposes the connection pool statistics of db.
+func (r *Registry) RegisterDBStats(db *sql.DB) {
+	r.NewGaugeFunc("db_max_open_connections", "Maximum number of open connections to the database.", func() float64 {
+		return float64(db.Stats().MaxOpenConnections)
+	})
+	r.NewGaugeFunc("db_open_connections", "Number of established connections, both in use and idle.", func() float64 {
+		return float64(db.Stats().OpenConnections)
+	})
+	r.NewGaugeFunc("db_in_use_connections", "Number of connections currently in use.", func() float64 {
+		return float64(db.Stats().InUse)
+	})
+	r.NewGaugeFunc("db_idle_connections", "Number of idle connections.", func() float64 {
+		return float64(db.Stats().Idle)
+	})
+	r.NewCounterFunc("db_wait_count_total", "Total number of connections waited for.", func() float64 {
+		return float64(db.Stats().WaitCount)
+	})
+	r.NewCounterFunc("db_wait_duration_seconds_total", "Total time blocked waiting for a new connection.", func() float64 {
+		return db.Stats().WaitDuration.Seconds()
+	})
+	r.NewCounterFunc("db_max_idle_closed_total", "Total number of connections closed due to SetMaxIdleConns.", func() float64 {
+		return float64(db.Stats().MaxIdleClosed)
+	})
+	r.NewCounterFunc("db_max_lifetime_closed_total", "Total number of connections closed due to SetConnMaxLifetime.", func() float64 {
+		return float64(db.Stats().MaxLifetimeClosed)
+	})
+}
@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"server/internal/domain"
+	"server/internal/pkg/context"
+	"strings"
+)
+
+// ClientIPResolver finds the address a request came from. X-Forwarded-For
+// and X-Real-IP can be sent by any client, so they are only believed on
+// connections from a trusted proxy.
+type ClientIPResolver struct {
+	trustedProxies []netip.Prefix
+}
+
+// NewClientIPResolver takes the trusted proxies as IP addresses or CIDR
+// ranges. Without any, forwarding headers are ignored.
+func NewClientIPResolver(trustedProxies []string) (*ClientIPResolver, error) {
+	resolver := &ClientIPResolver{}
+	for _, proxy := range trustedProxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if !strings.Contains(proxy, "/") {
+			addr, ok := parseIP(proxy)
+			if !ok {
+				return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+			}
+			resolver.trustedProxies = append(resolver.trustedProxies, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		resolver.trustedProxies = append(resolver.trustedProxies, prefix.Masked())
+	}
+	return resolver, nil
+}
+
+// ClientIP returns the connection's address, or behind trusted proxies the
+// right-most X-Forwarded-For hop that is not one of them. Hops left of that
+// were written by the client and are ignored.
+func (c *ClientIPResolver) ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, ok := parseIP(host)
+	if !ok {
+		return host
+	}
+	if !c.isTrusted(addr) {
+		return addr.String()
+	}
+
+	hops := forwardedFor(r)
+	if len(hops) == 0 {
+		if realIP, ok := parseIP(r.Header.Get("X-Real-IP")); ok {
+			return realIP.String()
+		}
+		return addr.String()
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, ok := parseIP(hops[i])
+		if !ok {
+			break
+		}
+		addr = hop
+		if !c.isTrusted(hop) {
+			break
+		}
+	}
+	return addr.String()
+}
+
+// ClientInfoMiddleware stores the client's address and user agent in the
+// request context, where the access log and the audit log read them.
+func (c *ClientIPResolver) ClientInfoMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithClientInfo(r.Context(), domain.ClientInfo{
+			IP:        c.ClientIP(r),
+			UserAgent: r.Header.Get("User-Agent"),
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func (c *ClientIPResolver) isTrusted(addr netip.Addr) bool {
+	for _, prefix := range c.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedFor lists the X-Forwarded-For hops of every such header, in order.
+func forwardedFor(r *http.Request) []string {
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(header, ",") {
+			hops = append(hops, strings.TrimSpace(hop))
+		}
+	}
+	return hops
+}
+
+func parseIP(value string) (netip.Addr, bool) {
+	ip := net.ParseIP(strings.TrimSpace(value))
+	if ip == nil {
+		return netip.Addr{}, false
+	}
+	addr, ok := netip.AddrFromSlice(ip)
+	return addr.Unmap(), ok
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"log/slog"
 	"net/http"
+	"server/internal/pkg/context"
 	"time"
 )
 
@@ -37,13 +38,6 @@ func (m *LoggingMiddleware) AccessLog(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
-		ip := r.RemoteAddr
-		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
-			ip = forwarded
-		} else if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
-			ip = realIP
-		}
-
 		rw := &responseWriter{
 			ResponseWriter: w,
 			statusCode:     http.StatusOK,
@@ -52,11 +46,11 @@ func (m *LoggingMiddleware) AccessLog(next http.Handler) http.Handler {
 		next.ServeHTTP(rw, r)
 
 		duration := time.Since(start)
-		m.logger.Info("access log",
+		m.logger.InfoContext(r.Context(), "access log",
 			"method", r.Method,
 			"path", r.URL.Path,
 			"query", r.URL.RawQuery,
-			"ip", ip,
+			"ip", context.ClientInfoFromContext(r.Context()).IP,
 			"user_agent", r.Header.Get("User-Agent"),
 			"status", rw.statusCode,
 			"size", rw.size,
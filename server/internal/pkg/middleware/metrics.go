@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"server/internal/pkg/metrics"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// unmatchedRoute labels requests that did not match any route, so scanners
+// probing random paths cannot create unbounded label values.
+const unmatchedRoute = "unmatched"
+
+// otherMethod labels requests with a method outside the standard set, which
+// clients may otherwise make up to the same effect.
+const otherMethod = "OTHER"
+
+type MetricsMiddleware struct {
+	requests *metrics.CounterVec
+	duration *metrics.HistogramVec
+}
+
+func NewMetricsMiddleware(registry *metrics.Registry) *MetricsMiddleware {
+	return &MetricsMiddleware{
+		requests: registry.NewCounterVec("http_requests_total",
+			"HTTP requests by method, route template and status code.", "method", "route", "code"),
+		duration: registry.NewHistogramVec("http_request_duration_seconds",
+			"HTTP request latency by method and route template.", metrics.DefBuckets, "method", "route"),
+	}
+}
+
+// Instrument records the request under its gorilla/mux route template. It
+// has to run inside the router (router.Use or the NotFoundHandler) for the
+// matched route to be known.
+func (m *MetricsMiddleware) Instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		rw := &responseWriter{
+			ResponseWriter: w,
+			statusCode:     http.StatusOK,
+		}
+
+		next.ServeHTTP(rw, r)
+
+		method := methodLabel(r.Method)
+		route := routeTemplate(r)
+		m.requests.Inc(method, route, strconv.Itoa(rw.statusCode))
+		m.duration.Observe(time.Since(start).Seconds(), method, route)
+	})
+}
+
+func routeTemplate(r *http.Request) string {
+	if current := mux.CurrentRoute(r); current != nil {
+		if template, err := current.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+	return unmatchedRoute
+}
+
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+	return otherMethod
+}
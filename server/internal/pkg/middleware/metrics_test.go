@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"server/internal/pkg/metrics"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestMetricsMiddleware_BoundedLabels(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetricsMiddleware(registry)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{id}", func(w http.ResponseWriter, r *http.Request) {}).Methods(http.MethodGet)
+	router.NotFoundHandler = m.Instrument(http.NotFoundHandler())
+	router.Use(m.Instrument)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/users/1", nil),
+		httptest.NewRequest(http.MethodGet, "/users/2", nil),
+		httptest.NewRequest("MADEUP1", "/random/path", nil),
+		httptest.NewRequest("MADEUP2", "/other/path", nil),
+	} {
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	rec := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	out := rec.Body.String()
+
+	for _, line := range []string{
+		`http_requests_total{method="GET",route="/users/{id}",code="200"} 2`,
+		`http_requests_total{method="OTHER",route="unmatched",code="404"} 2`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("expected line %q in:\n%s", line, out)
+		}
+	}
+	if strings.Contains(out, "MADEUP") || strings.Contains(out, "/random/path") {
+		t.Errorf("expected client-chosen values to stay out of the labels:\n%s", out)
+	}
+}
@@ -0,0 +1,204 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"server/internal/pkg/httptools"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+// maxValidatedBodySize keeps the validator from buffering large uploads;
+// bigger bodies are left to the handlers, which limit them themselves.
+const maxValidatedBodySize = 1 << 20
+
+// OpenAPIMiddleware checks requests, and optionally responses, against the
+// OpenAPI document. Requests for paths the document does not describe are
+// passed through so the router can answer them.
+type OpenAPIMiddleware struct {
+	logger            *slog.Logger
+	router            routers.Router
+	validateResponses bool
+}
+
+func NewOpenAPIMiddleware(logger *slog.Logger, doc *openapi3.T, validateResponses bool) (*OpenAPIMiddleware, error) {
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &OpenAPIMiddleware{
+		logger:            logger,
+		router:            router,
+		validateResponses: validateResponses,
+	}, nil
+}
+
+// Validate answers requests that do not match the document with a 400
+// problem naming the offending parameters and body members. With response
+// validation on, a response that does not match is logged and replaced by a
+// 500; that buffers every response, so it is meant for development.
+func (m *OpenAPIMiddleware) Validate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, pathParams, err := m.router.FindRoute(r)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: pathParams,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				ExcludeRequestBody: r.ContentLength < 0 || r.ContentLength > maxValidatedBodySize,
+				MultiError:         true,
+				// Authentication is left to the auth and CSRF middleware.
+				AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+			},
+		}
+		if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+			m.logger.WarnContext(r.Context(), "request does not match the openapi document", "error", err)
+			httptools.WriteInvalidRequest(w, "request does not match the API specification", requestErrorFields(err))
+			return
+		}
+
+		if !m.validateResponses {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rw := newBufferedResponseWriter(w)
+		next.ServeHTTP(rw, r)
+
+		err = openapi3filter.ValidateResponse(r.Context(), &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 rw.statusCode,
+			Header:                 rw.header,
+			Body:                   io.NopCloser(bytes.NewReader(rw.body.Bytes())),
+			Options: &openapi3filter.Options{
+				ExcludeResponseBody:   !isJSON(rw.header.Get("Content-Type")),
+				IncludeResponseStatus: true,
+				MultiError:            true,
+			},
+		})
+		if err != nil {
+			m.logger.ErrorContext(r.Context(), "response does not match the openapi document",
+				"route", route.Path, "method", r.Method, "status", rw.statusCode, "error", err)
+			httptools.WriteProblem(w, http.StatusInternalServerError, httptools.CodeInvalidResponse,
+				"response does not match the API specification")
+			return
+		}
+
+		rw.flush()
+	})
+}
+
+// requestErrorFields keys validation messages by parameter name, or by the
+// dotted path of the offending body member.
+func requestErrorFields(err error) map[string]string {
+	fields := map[string]string{}
+	var walk func(err error)
+	walk = func(err error) {
+		switch err := err.(type) {
+		case openapi3.MultiError:
+			for _, e := range err {
+				walk(e)
+			}
+		case *openapi3filter.RequestError:
+			if err.Parameter == nil {
+				addSchemaErrors(fields, err.Err)
+				return
+			}
+			var schemaErr *openapi3.SchemaError
+			switch {
+			case errors.As(err.Err, &schemaErr):
+				fields[err.Parameter.Name] = schemaErr.Reason
+			case err.Err != nil:
+				fields[err.Parameter.Name] = err.Err.Error()
+			default:
+				fields[err.Parameter.Name] = err.Reason
+			}
+		}
+	}
+	walk(err)
+
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
+
+func addSchemaErrors(fields map[string]string, err error) {
+	switch err := err.(type) {
+	case openapi3.MultiError:
+		for _, e := range err {
+			addSchemaErrors(fields, e)
+		}
+	case *openapi3.SchemaError:
+		key := strings.Join(err.JSONPointer(), ".")
+		if key == "" {
+			key = "body"
+		}
+		fields[key] = err.Reason
+	}
+}
+
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// bufferedResponseWriter holds the response back until it has been
+// validated. It starts from the headers already set on the real writer, such
+// as the request ID, so problems written by the handler keep them.
+type bufferedResponseWriter struct {
+	w          http.ResponseWriter
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+	wroteHead  bool
+}
+
+func newBufferedResponseWriter(w http.ResponseWriter) *bufferedResponseWriter {
+	return &bufferedResponseWriter{
+		w:          w,
+		header:     w.Header().Clone(),
+		statusCode: http.StatusOK,
+	}
+}
+
+func (rw *bufferedResponseWriter) Header() http.Header {
+	return rw.header
+}
+
+func (rw *bufferedResponseWriter) WriteHeader(code int) {
+	if rw.wroteHead {
+		return
+	}
+	rw.statusCode = code
+	rw.wroteHead = true
+}
+
+func (rw *bufferedResponseWriter) Write(b []byte) (int, error) {
+	rw.wroteHead = true
+	return rw.body.Write(b)
+}
+
+func (rw *bufferedResponseWriter) flush() {
+	for key, values := range rw.header {
+		rw.w.Header()[key] = values
+	}
+	rw.w.WriteHeader(rw.statusCode)
+	rw.w.Write(rw.body.Bytes())
+}
@@ -19,8 +19,8 @@ func NewPanicMiddleware(logger *slog.Logger) *PanicMiddleware {
 func (m *PanicMiddleware) PanicMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
-			if r := recover(); r != nil {
-				m.logger.Error("panic middleware", "error", r)
+			if rec := recover(); rec != nil {
+				m.logger.ErrorContext(r.Context(), "panic middleware", "error", rec)
 				httptools.WriteJSONError(w, http.StatusInternalServerError, "internal server error")
 			}
 		}()
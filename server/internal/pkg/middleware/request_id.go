@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"server/internal/pkg/context"
+	"server/internal/pkg/httptools"
+)
+
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware keeps the caller's X-Request-ID, so the frontend's ID
+// follows the request here, or generates one. The ID is stored in the context
+// for the logger and echoed in the response.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(httptools.RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+
+		w.Header().Set(httptools.RequestIDHeader, requestID)
+		next.ServeHTTP(w, r.WithContext(context.WithRequestID(r.Context(), requestID)))
+	})
+}
+
+// validRequestID limits IDs taken from clients to a length and alphabet that
+// are safe to write into logs and headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,133 @@
+// Package phone parses user-entered phone numbers and normalizes them to
+// E.164.
+//
+// Numbers in international format are only checked for E.164 shape: a
+// country code cannot be told apart from the national number, nor the
+// national number checked, without the full libphonenumber metadata, and
+// a partial table rejects valid numbers. Whether a number really exists is
+// settled by the verification code sent to it.
+package phone
+
+import (
+	"fmt"
+	"server/internal/domain"
+	"strings"
+)
+
+const (
+	maxE164Digits = 15
+	// minE164Digits is the shortest number in service anywhere, a country
+	// code and a four digit subscriber number such as +683 4002 in Niue.
+	minE164Digits = 7
+)
+
+// region describes how numbers are dialled inside a country, which is all
+// that is needed to turn national input into international format.
+type region struct {
+	callingCode string
+	// trunkPrefix is dialled before the national number inside the country
+	// and dropped in international format, e.g. the leading 0 in the UK.
+	trunkPrefix string
+}
+
+// regions are the values accepted for the default region. Countries that
+// share a calling code, such as the NANP members or Russia and Kazakhstan,
+// are listed separately so either can be configured.
+var regions = map[string]region{
+	"AE": {callingCode: "971", trunkPrefix: "0"},
+	"AT": {callingCode: "43", trunkPrefix: "0"},
+	"AU": {callingCode: "61", trunkPrefix: "0"},
+	"BE": {callingCode: "32", trunkPrefix: "0"},
+	"BR": {callingCode: "55", trunkPrefix: "0"},
+	"BY": {callingCode: "375", trunkPrefix: "8"},
+	"CA": {callingCode: "1", trunkPrefix: "1"},
+	"CH": {callingCode: "41", trunkPrefix: "0"},
+	"CN": {callingCode: "86", trunkPrefix: "0"},
+	"CZ": {callingCode: "420"},
+	"DE": {callingCode: "49", trunkPrefix: "0"},
+	"DK": {callingCode: "45"},
+	"ES": {callingCode: "34"},
+	"FI": {callingCode: "358", trunkPrefix: "0"},
+	"FR": {callingCode: "33", trunkPrefix: "0"},
+	"GB": {callingCode: "44", trunkPrefix: "0"},
+	"IE": {callingCode: "353", trunkPrefix: "0"},
+	"IL": {callingCode: "972", trunkPrefix: "0"},
+	"IN": {callingCode: "91", trunkPrefix: "0"},
+	"IT": {callingCode: "39"},
+	"JP": {callingCode: "81", trunkPrefix: "0"},
+	"KZ": {callingCode: "7", trunkPrefix: "8"},
+	"MX": {callingCode: "52"},
+	"NL": {callingCode: "31", trunkPrefix: "0"},
+	"NO": {callingCode: "47"},
+	"NZ": {callingCode: "64", trunkPrefix: "0"},
+	"PL": {callingCode: "48"},
+	"PT": {callingCode: "351"},
+	"RU": {callingCode: "7", trunkPrefix: "8"},
+	"SE": {callingCode: "46", trunkPrefix: "0"},
+	"TR": {callingCode: "90", trunkPrefix: "0"},
+	"UA": {callingCode: "380", trunkPrefix: "0"},
+	"US": {callingCode: "1", trunkPrefix: "1"},
+}
+
+// Normalize turns raw input such as "+1 (415) 555-0132" or, with a default
+// region, "8 912 345-67-89" into E.164. National input is read as dialled
+// inside the country, with the trunk prefix where one is used. Errors wrap
+// domain.ErrInvalidPhone and say exactly what is wrong so they can be shown
+// to the user as is.
+func Normalize(raw, defaultRegion string) (string, error) {
+	digits, international, err := stripFormatting(raw)
+	if err != nil {
+		return "", err
+	}
+	if digits == "" {
+		return "", fmt.Errorf("%w: no digits found", domain.ErrInvalidPhone)
+	}
+
+	if !international {
+		r, ok := regions[strings.ToUpper(defaultRegion)]
+		if !ok {
+			return "", fmt.Errorf("%w: include the country code, e.g. +1 for the US", domain.ErrInvalidPhone)
+		}
+		if r.trunkPrefix != "" {
+			digits = strings.TrimPrefix(digits, r.trunkPrefix)
+		}
+		digits = r.callingCode + digits
+	}
+
+	if digits[0] == '0' {
+		return "", fmt.Errorf("%w: country codes do not start with 0", domain.ErrInvalidPhone)
+	}
+	if len(digits) < minE164Digits {
+		return "", fmt.Errorf("%w: number is shorter than %d digits", domain.ErrInvalidPhone, minE164Digits)
+	}
+	if len(digits) > maxE164Digits {
+		return "", fmt.Errorf("%w: number is longer than %d digits", domain.ErrInvalidPhone, maxE164Digits)
+	}
+
+	return "+" + digits, nil
+}
+
+func stripFormatting(raw string) (string, bool, error) {
+	raw = strings.TrimSpace(raw)
+	international := false
+	switch {
+	case strings.HasPrefix(raw, "+"):
+		international = true
+		raw = raw[1:]
+	case strings.HasPrefix(raw, "00"):
+		international = true
+		raw = raw[2:]
+	}
+
+	var b strings.Builder
+	for _, r := range raw {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", false, fmt.Errorf("%w: unexpected character %q", domain.ErrInvalidPhone, r)
+		}
+	}
+	return b.String(), international, nil
+}
@@ -0,0 +1,75 @@
+package phone
+
+import (
+	"errors"
+	"server/internal/domain"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		defaultRegion string
+		expected      string
+	}{
+		{name: "us", input: "+1 (415) 555-0132", expected: "+14155550132"},
+		{name: "nanp territory", input: "+1 876 555 0134", expected: "+18765550134"},
+		{name: "switzerland", input: "+41 44 668 18 00", expected: "+41446681800"},
+		{name: "sweden", input: "+46 8 123 456 78", expected: "+46812345678"},
+		{name: "ireland", input: "+353 1 234 5678", expected: "+35312345678"},
+		{name: "kazakhstan shares +7", input: "+7 701 123 4567", expected: "+77011234567"},
+		{name: "short numbering plan", input: "+683 4002", expected: "+6834002"},
+		{name: "double zero prefix", input: "0044 20 7946 0018", expected: "+442079460018"},
+		{name: "dots and dashes", input: "+380.50-123-4567", expected: "+380501234567"},
+		{name: "us national", input: "(415) 555-0132", defaultRegion: "US", expected: "+14155550132"},
+		{name: "us national with trunk", input: "1 415 555 0132", defaultRegion: "us", expected: "+14155550132"},
+		{name: "russian trunk prefix", input: "8 912 345-67-89", defaultRegion: "RU", expected: "+79123456789"},
+		{name: "kazakh trunk prefix", input: "8 701 123 4567", defaultRegion: "KZ", expected: "+77011234567"},
+		{name: "uk national", input: "07911 123456", defaultRegion: "GB", expected: "+447911123456"},
+		{name: "swiss national", input: "044 668 18 00", defaultRegion: "CH", expected: "+41446681800"},
+		{name: "international ignores default region", input: "+46 8 123 456 78", defaultRegion: "GB", expected: "+46812345678"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.input, tt.defaultRegion)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNormalize_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		defaultRegion string
+		contains      string
+	}{
+		{name: "empty", input: "  ", contains: "no digits found"},
+		{name: "letters", input: "+1 415 CALL NOW", contains: "unexpected character 'C'"},
+		{name: "missing country code", input: "912 345 67 89", contains: "include the country code"},
+		{name: "unknown default region", input: "912 345 67 89", defaultRegion: "XX", contains: "include the country code"},
+		{name: "country code starting with 0", input: "+0 123 456 789", contains: "do not start with 0"},
+		{name: "too short", input: "+7 912 3", contains: "shorter than 7 digits"},
+		{name: "too long", input: "+1 415 555 0132 12345", contains: "longer than 15 digits"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Normalize(tt.input, tt.defaultRegion)
+			if !errors.Is(err, domain.ErrInvalidPhone) {
+				t.Fatalf("expected domain.ErrInvalidPhone, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("expected error containing %q, got %v", tt.contains, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter is an in-memory token bucket per key. A key may spend up to limit
+// requests at once and earns them back evenly over period.
+type Limiter struct {
+	mu        sync.Mutex
+	limit     float64
+	period    time.Duration
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// New returns a limiter allowing limit requests per period and key. A
+// limit of zero or less allows everything.
+func New(limit int, period time.Duration) *Limiter {
+	return &Limiter{
+		limit:   float64(limit),
+		period:  period,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow spends a token of key and reports whether one was left.
+func (l *Limiter) Allow(key string) bool {
+	if l.limit <= 0 || l.period <= 0 {
+		return true
+	}
+
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.limit, updated: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = min(l.limit, b.tokens+l.limit*float64(now.Sub(b.updated))/float64(l.period))
+	b.updated = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// sweep drops the buckets that have refilled completely once per period,
+// they are no different from a new one.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.period {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.updated) >= l.period {
+			delete(l.buckets, key)
+		}
+	}
+}
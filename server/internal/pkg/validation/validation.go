@@ -0,0 +1,10 @@
+// Package validation holds input checks shared by several use cases.
+package validation
+
+import "regexp"
+
+var emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+
+func IsValidEmail(email string) bool {
+	return emailRegex.MatchString(email)
+}
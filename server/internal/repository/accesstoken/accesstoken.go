@@ -0,0 +1,188 @@
+package accesstoken
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"server/internal/domain"
+	"time"
+)
+
+const tokenColumns = "t.id, t.user_id, t.name, t.scopes, t.token_hash, t.created_at, t.expires_at, t.last_used_at"
+
+// CreateToken stores a token unless the user already has maxTokens. The
+// user row is locked while counting so concurrent creates cannot go past
+// the limit.
+func (r *Repository) CreateToken(ctx context.Context, token *domain.PersonalAccessToken, maxTokens int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				r.logger.ErrorContext(ctx, "failed to rollback transaction", "error", rollbackErr)
+			}
+		}
+	}()
+
+	var userID int64
+	err = tx.QueryRowContext(ctx, "SELECT id FROM user WHERE id = ? FOR UPDATE", token.UserID).Scan(&userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.ErrUserNotExists
+		}
+		r.logger.ErrorContext(ctx, "failed to lock user", "error", err)
+		return fmt.Errorf("failed to lock user: %w", err)
+	}
+
+	var count int
+	err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM personal_access_token WHERE user_id = ?", token.UserID).Scan(&count)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to count access tokens", "error", err)
+		return fmt.Errorf("failed to count access tokens: %w", err)
+	}
+	if count >= maxTokens {
+		return domain.ErrTooManyAccessTokens
+	}
+
+	result, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO personal_access_token (user_id, name, scopes, token_hash, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		token.UserID, token.Name, joinScopes(token.Scopes), token.TokenHash, token.CreatedAt, nullableTime(token.ExpiresAt),
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to create access token", "error", err)
+		return fmt.Errorf("failed to create access token: %w", err)
+	}
+
+	token.ID, err = result.LastInsertId()
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get access token id", "error", err)
+		return fmt.Errorf("failed to get access token id: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.ErrorContext(ctx, "failed to commit transaction", "error", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	committed = true
+	return nil
+}
+
+// ListTokens returns the tokens of a user, newest first.
+func (r *Repository) ListTokens(ctx context.Context, userID int64) ([]*domain.PersonalAccessToken, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT "+tokenColumns+" FROM personal_access_token t WHERE t.user_id = ? ORDER BY t.id DESC",
+		userID,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to list access tokens", "error", err)
+		return nil, fmt.Errorf("failed to list access tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := make([]*domain.PersonalAccessToken, 0)
+	for rows.Next() {
+		token, err := scanToken(rows)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "failed to scan access token", "error", err)
+			return nil, fmt.Errorf("failed to scan access token: %w", err)
+		}
+		tokens = append(tokens, token)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.ErrorContext(ctx, "failed to iterate access tokens", "error", err)
+		return nil, fmt.Errorf("failed to iterate access tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// GetTokenByHash finds a token by the hash of its value. Tokens of accounts
+// scheduled for deletion are not found, so they stop working together with
+// the account's sessions.
+func (r *Repository) GetTokenByHash(ctx context.Context, tokenHash string) (*domain.PersonalAccessToken, error) {
+	row := r.db.QueryRowContext(
+		ctx,
+		`SELECT `+tokenColumns+` FROM personal_access_token t
+		JOIN user u ON u.id = t.user_id
+		WHERE t.token_hash = ? AND u.deletion_scheduled_at IS NULL`,
+		tokenHash,
+	)
+	token, err := scanToken(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrAccessTokenNotFound
+	}
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get access token", "error", err)
+		return nil, fmt.Errorf("failed to get access token: %w", err)
+	}
+	return token, nil
+}
+
+// DeleteToken revokes a token. The user id is part of the condition so one
+// user cannot revoke another's token by guessing ids.
+func (r *Repository) DeleteToken(ctx context.Context, userID, id int64) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM personal_access_token WHERE id = ? AND user_id = ?", id, userID)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to delete access token", "error", err)
+		return fmt.Errorf("failed to delete access token: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get rows affected", "error", err)
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrAccessTokenNotFound
+	}
+	return nil
+}
+
+// DeleteUserTokens revokes every token of a user, for when the account may
+// be in someone else's hands.
+func (r *Repository) DeleteUserTokens(ctx context.Context, userID int64) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM personal_access_token WHERE user_id = ?", userID)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to delete user access tokens", "error", err)
+		return fmt.Errorf("failed to delete user access tokens: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) TouchToken(ctx context.Context, id int64, usedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE personal_access_token SET last_used_at = ? WHERE id = ?", usedAt, id)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to update access token last use", "error", err)
+		return fmt.Errorf("failed to update access token last use: %w", err)
+	}
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanToken(row scanner) (*domain.PersonalAccessToken, error) {
+	var token domain.PersonalAccessToken
+	var scopes string
+	var expiresAt, lastUsedAt sql.NullTime
+	err := row.Scan(&token.ID, &token.UserID, &token.Name, &scopes, &token.TokenHash, &token.CreatedAt, &expiresAt, &lastUsedAt)
+	if err != nil {
+		return nil, err
+	}
+	token.Scopes = splitScopes(scopes)
+	if expiresAt.Valid {
+		token.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		token.LastUsedAt = &lastUsedAt.Time
+	}
+	return &token, nil
+}
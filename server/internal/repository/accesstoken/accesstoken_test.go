@@ -0,0 +1,283 @@
+package accesstoken
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"os"
+	"server/internal/domain"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func setupTestDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+var tokenColumnNames = []string{"id", "user_id", "name", "scopes", "token_hash", "created_at", "expires_at", "last_used_at"}
+
+func TestRepository_CreateToken(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(30 * 24 * time.Hour)
+
+	expectInsert := func(m sqlmock.Sqlmock, arg sql.NullTime) {
+		m.ExpectExec("INSERT INTO personal_access_token").
+			WithArgs(int64(1), "deploy", "profile:read profile:write", "hash", now, arg).
+			WillReturnResult(sqlmock.NewResult(4, 1))
+	}
+
+	tests := []struct {
+		name          string
+		expiresAt     *time.Time
+		setupMock     func(sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name:      "with expiry",
+			expiresAt: &expiresAt,
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT id FROM user WHERE id = \\? FOR UPDATE").WithArgs(int64(1)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				m.ExpectQuery("SELECT COUNT\\(\\*\\) FROM personal_access_token WHERE user_id = \\?").WithArgs(int64(1)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				expectInsert(m, sql.NullTime{Time: expiresAt, Valid: true})
+				m.ExpectCommit()
+			},
+		},
+		{
+			name: "without expiry",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT id FROM user").WithArgs(int64(1)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				m.ExpectQuery("SELECT COUNT").WithArgs(int64(1)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				expectInsert(m, sql.NullTime{})
+				m.ExpectCommit()
+			},
+		},
+		{
+			name: "limit reached",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT id FROM user").WithArgs(int64(1)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				m.ExpectQuery("SELECT COUNT").WithArgs(int64(1)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+				m.ExpectRollback()
+			},
+			expectedError: domain.ErrTooManyAccessTokens,
+		},
+		{
+			name: "unknown user",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT id FROM user").WithArgs(int64(1)).WillReturnError(sql.ErrNoRows)
+				m.ExpectRollback()
+			},
+			expectedError: domain.ErrUserNotExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			tt.setupMock(mock)
+
+			repo := NewRepository(logger, db)
+			token := &domain.PersonalAccessToken{
+				UserID:    1,
+				Name:      "deploy",
+				Scopes:    []domain.TokenScope{domain.ScopeProfileRead, domain.ScopeProfileWrite},
+				TokenHash: "hash",
+				CreatedAt: now,
+				ExpiresAt: tt.expiresAt,
+			}
+			err := repo.CreateToken(context.Background(), token, 3)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if tt.expectedError == nil && token.ID != 4 {
+				t.Errorf("expected id 4, got %d", token.ID)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestRepository_ListTokens(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	rows := sqlmock.NewRows(tokenColumnNames).
+		AddRow(2, 1, "ci", "organizations:read", "hash2", now, now.Add(time.Hour), now).
+		AddRow(1, 1, "deploy", "profile:read profile:write", "hash1", now, nil, nil)
+	mock.ExpectQuery("SELECT (.+) FROM personal_access_token t WHERE t.user_id = \\? ORDER BY t.id DESC").
+		WithArgs(int64(1)).
+		WillReturnRows(rows)
+
+	repo := NewRepository(logger, db)
+	tokens, err := repo.ListTokens(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(tokens) != 2 {
+		t.Fatalf("expected 2 tokens, got %d", len(tokens))
+	}
+	if tokens[0].ExpiresAt == nil || tokens[0].LastUsedAt == nil {
+		t.Error("expected expiry and last use of the first token")
+	}
+	if tokens[1].ExpiresAt != nil || tokens[1].LastUsedAt != nil {
+		t.Error("expected no expiry and no last use of the second token")
+	}
+	if !slices.Equal(tokens[1].Scopes, []domain.TokenScope{domain.ScopeProfileRead, domain.ScopeProfileWrite}) {
+		t.Errorf("unexpected scopes %v", tokens[1].Scopes)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_GetTokenByHash(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		setupMock     func(sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name: "found",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("SELECT (.+) FROM personal_access_token t\\s+JOIN user u (.+) AND u.deletion_scheduled_at IS NULL").
+					WithArgs("hash").
+					WillReturnRows(sqlmock.NewRows(tokenColumnNames).
+						AddRow(1, 1, "deploy", "profile:read", "hash", now, nil, nil))
+			},
+		},
+		{
+			name: "unknown or account being deleted",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("SELECT (.+) FROM personal_access_token t").
+					WithArgs("hash").
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: domain.ErrAccessTokenNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			tt.setupMock(mock)
+
+			repo := NewRepository(logger, db)
+			token, err := repo.GetTokenByHash(context.Background(), "hash")
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if tt.expectedError == nil && (token == nil || token.UserID != 1) {
+				t.Errorf("unexpected token %+v", token)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestRepository_DeleteToken(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	tests := []struct {
+		name          string
+		rowsAffected  int64
+		expectedError error
+	}{
+		{name: "deleted", rowsAffected: 1},
+		{name: "unknown or another user's token", rowsAffected: 0, expectedError: domain.ErrAccessTokenNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			mock.ExpectExec("DELETE FROM personal_access_token WHERE id = \\? AND user_id = \\?").
+				WithArgs(int64(3), int64(1)).
+				WillReturnResult(sqlmock.NewResult(0, tt.rowsAffected))
+
+			repo := NewRepository(logger, db)
+			err := repo.DeleteToken(context.Background(), 1, 3)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestRepository_DeleteUserTokens(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectExec("DELETE FROM personal_access_token WHERE user_id = \\?").
+		WithArgs(int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	repo := NewRepository(logger, db)
+	if err := repo.DeleteUserTokens(context.Background(), 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_TouchToken(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE personal_access_token SET last_used_at = \\? WHERE id = \\?").
+		WithArgs(now, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	repo := NewRepository(logger, db)
+	if err := repo.TouchToken(context.Background(), 3, now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package accesstoken
+
+import (
+	"database/sql"
+	"log/slog"
+	"server/internal/domain"
+	"strings"
+	"time"
+)
+
+type Repository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func NewRepository(logger *slog.Logger, db *sql.DB) *Repository {
+	return &Repository{logger: logger, db: db}
+}
+
+// joinScopes and splitScopes convert to and from personal_access_token.scopes.
+func joinScopes(scopes []domain.TokenScope) string {
+	names := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		names = append(names, string(scope))
+	}
+	return strings.Join(names, " ")
+}
+
+func splitScopes(value string) []domain.TokenScope {
+	fields := strings.Fields(value)
+	scopes := make([]domain.TokenScope, 0, len(fields))
+	for _, field := range fields {
+		scopes = append(scopes, domain.TokenScope(field))
+	}
+	return scopes
+}
+
+func nullableTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
@@ -0,0 +1,206 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"os"
+	"server/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func setupTestDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+func TestRepository_AppendEvent(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+	userID := int64(7)
+
+	tests := []struct {
+		name          string
+		setupMock     func(sqlmock.Sqlmock)
+		expectedError bool
+		expectedPrev  string
+	}{
+		{
+			name: "successful append",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT last_hash FROM audit_chain_head WHERE id = 1 FOR UPDATE").
+					WillReturnRows(sqlmock.NewRows([]string{"last_hash"}).AddRow("prev"))
+				m.ExpectExec("INSERT INTO audit_event").
+					WillReturnResult(sqlmock.NewResult(11, 1))
+				m.ExpectExec("UPDATE audit_chain_head SET last_hash = \\? WHERE id = 1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			},
+			expectedError: false,
+			expectedPrev:  "prev",
+		},
+		{
+			name: "insert error",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT last_hash FROM audit_chain_head WHERE id = 1 FOR UPDATE").
+					WillReturnRows(sqlmock.NewRows([]string{"last_hash"}).AddRow("prev"))
+				m.ExpectExec("INSERT INTO audit_event").
+					WillReturnError(sql.ErrConnDone)
+				m.ExpectRollback()
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			tt.setupMock(mock)
+
+			repo := NewRepository(logger, db)
+			event := &domain.AuditEvent{
+				ActorUserID:   &userID,
+				SubjectUserID: &userID,
+				Type:          domain.AuditEventLogInEmail,
+				Outcome:       domain.AuditOutcomeSuccess,
+				CreatedAt:     time.Now(),
+			}
+			err := repo.AppendEvent(ctx, event)
+
+			if tt.expectedError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+			} else {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				if event.ID != 11 {
+					t.Errorf("expected ID 11, got %d", event.ID)
+				}
+				if event.PrevHash != tt.expectedPrev {
+					t.Errorf("expected prev hash %s, got %s", tt.expectedPrev, event.PrevHash)
+				}
+				if event.Hash == "" {
+					t.Error("expected non-empty hash")
+				}
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("mock expectations were not met: %v", err)
+			}
+		})
+	}
+}
+
+func TestRepository_ListEvents(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+	userID := int64(7)
+	columns := []string{"id", "actor_user_id", "subject_user_id", "event_type", "ip", "user_agent",
+		"outcome", "details", "created_at", "prev_hash", "hash"}
+
+	tests := []struct {
+		name          string
+		query         domain.AuditQuery
+		setupMock     func(sqlmock.Sqlmock)
+		expectedError bool
+		expectedCount int
+	}{
+		{
+			name:  "filter by user",
+			query: domain.AuditQuery{UserID: &userID, Limit: 10},
+			setupMock: func(m sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows(columns).
+					AddRow(2, nil, 7, "login.email", "127.0.0.1", "curl", "failure", `{"reason":"invalid_password"}`, time.Now(), "a", "b").
+					AddRow(1, 7, 7, "signup.email", "127.0.0.1", "curl", "success", `{}`, time.Now(), "", "a")
+				m.ExpectQuery("WHERE \\(actor_user_id = \\? OR subject_user_id = \\?\\) ORDER BY id DESC LIMIT \\?").
+					WithArgs(userID, userID, 10).
+					WillReturnRows(rows)
+			},
+			expectedError: false,
+			expectedCount: 2,
+		},
+		{
+			name:  "default limit",
+			query: domain.AuditQuery{Type: domain.AuditEventLogOut},
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("WHERE event_type = \\? ORDER BY id DESC LIMIT \\?").
+					WithArgs("logout", defaultListLimit).
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+			expectedError: false,
+			expectedCount: 0,
+		},
+		{
+			name:  "database error",
+			query: domain.AuditQuery{},
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("FROM audit_event ORDER BY id DESC LIMIT \\?").
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			tt.setupMock(mock)
+
+			repo := NewRepository(logger, db)
+			events, err := repo.ListEvents(ctx, tt.query)
+
+			if tt.expectedError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+			} else {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				if len(events) != tt.expectedCount {
+					t.Errorf("expected %d events, got %d", tt.expectedCount, len(events))
+				}
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("mock expectations were not met: %v", err)
+			}
+		})
+	}
+}
+
+func TestRepository_GetChainHead(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT last_hash FROM audit_chain_head WHERE id = 1").
+		WillReturnRows(sqlmock.NewRows([]string{"last_hash"}).AddRow("abc"))
+
+	repo := NewRepository(logger, db)
+	head, err := repo.GetChainHead(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if head != "abc" {
+		t.Errorf("expected head abc, got %s", head)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("mock expectations were not met: %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"server/internal/domain"
+)
+
+// AppendEvent links the event to the current chain head and inserts it. The
+// single-row audit_chain_head table is locked for the duration of the
+// transaction so concurrent writers cannot fork the chain.
+func (r *Repository) AppendEvent(ctx context.Context, event *domain.AuditEvent) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				r.logger.ErrorContext(ctx, "failed to rollback transaction", "error", rollbackErr)
+			}
+		}
+	}()
+
+	var prevHash string
+	err = tx.QueryRowContext(ctx, "SELECT last_hash FROM audit_chain_head WHERE id = 1 FOR UPDATE").Scan(&prevHash)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to lock audit chain head", "error", err)
+		return fmt.Errorf("failed to lock audit chain head: %w", err)
+	}
+
+	event.PrevHash = prevHash
+	event.Hash, err = event.ComputeHash()
+	if err != nil {
+		return fmt.Errorf("failed to compute audit event hash: %w", err)
+	}
+	details, err := event.DetailsJSON()
+	if err != nil {
+		return fmt.Errorf("failed to encode audit event details: %w", err)
+	}
+
+	result, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO audit_event
+		(actor_user_id, subject_user_id, event_type, ip, user_agent, outcome, details, created_at, prev_hash, hash)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		nullableID(event.ActorUserID), nullableID(event.SubjectUserID), string(event.Type),
+		event.IP, event.UserAgent, string(event.Outcome), details, event.CreatedAt, event.PrevHash, event.Hash,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to insert audit event", "error", err)
+		return fmt.Errorf("failed to insert audit event: %w", err)
+	}
+
+	event.ID, err = result.LastInsertId()
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get last insert id", "error", err)
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE audit_chain_head SET last_hash = ? WHERE id = 1", event.Hash)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to move audit chain head", "error", err)
+		return fmt.Errorf("failed to move audit chain head: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.ErrorContext(ctx, "failed to commit transaction", "error", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	committed = true
+	return nil
+}
+
+func nullableID(id *int64) sql.NullInt64 {
+	if id == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: *id, Valid: true}
+}
@@ -0,0 +1,122 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"server/internal/domain"
+	"strings"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+)
+
+const selectEventColumns = `SELECT id, actor_user_id, subject_user_id, event_type, ip, user_agent,
+	outcome, details, created_at, prev_hash, hash FROM audit_event`
+
+func (r *Repository) ListEvents(ctx context.Context, query domain.AuditQuery) ([]*domain.AuditEvent, error) {
+	conditions := make([]string, 0, 4)
+	args := make([]interface{}, 0, 6)
+	if query.UserID != nil {
+		conditions = append(conditions, "(actor_user_id = ? OR subject_user_id = ?)")
+		args = append(args, *query.UserID, *query.UserID)
+	}
+	if query.Type != "" {
+		conditions = append(conditions, "event_type = ?")
+		args = append(args, string(query.Type))
+	}
+	if query.Outcome != "" {
+		conditions = append(conditions, "outcome = ?")
+		args = append(args, string(query.Outcome))
+	}
+	if query.BeforeID > 0 {
+		conditions = append(conditions, "id < ?")
+		args = append(args, query.BeforeID)
+	}
+
+	sqlQuery := selectEventColumns
+	if len(conditions) > 0 {
+		sqlQuery += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	sqlQuery += " ORDER BY id DESC LIMIT ?"
+	args = append(args, clampLimit(query.Limit))
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to list audit events", "error", err)
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanEvents(ctx, rows)
+}
+
+// ListEventsAfterID returns events in chain order, used to verify the chain.
+func (r *Repository) ListEventsAfterID(ctx context.Context, afterID int64, limit int) ([]*domain.AuditEvent, error) {
+	rows, err := r.db.QueryContext(ctx, selectEventColumns+" WHERE id > ? ORDER BY id ASC LIMIT ?", afterID, limit)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to list audit events", "error", err)
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanEvents(ctx, rows)
+}
+
+// GetChainHead returns the hash of the newest event as recorded by
+// AppendEvent, empty while the log is.
+func (r *Repository) GetChainHead(ctx context.Context) (string, error) {
+	var lastHash string
+	err := r.db.QueryRowContext(ctx, "SELECT last_hash FROM audit_chain_head WHERE id = 1").Scan(&lastHash)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get audit chain head", "error", err)
+		return "", fmt.Errorf("failed to get audit chain head: %w", err)
+	}
+	return lastHash, nil
+}
+
+func (r *Repository) scanEvents(ctx context.Context, rows *sql.Rows) ([]*domain.AuditEvent, error) {
+	events := make([]*domain.AuditEvent, 0)
+	for rows.Next() {
+		var event domain.AuditEvent
+		var actorID, subjectID sql.NullInt64
+		var eventType, outcome, details string
+		err := rows.Scan(&event.ID, &actorID, &subjectID, &eventType, &event.IP, &event.UserAgent,
+			&outcome, &details, &event.CreatedAt, &event.PrevHash, &event.Hash)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "failed to scan audit event", "error", err)
+			return nil, fmt.Errorf("failed to scan audit event: %w", err)
+		}
+		if actorID.Valid {
+			event.ActorUserID = &actorID.Int64
+		}
+		if subjectID.Valid {
+			event.SubjectUserID = &subjectID.Int64
+		}
+		event.Type = domain.AuditEventType(eventType)
+		event.Outcome = domain.AuditOutcome(outcome)
+		if err := json.Unmarshal([]byte(details), &event.Details); err != nil {
+			r.logger.ErrorContext(ctx, "failed to decode audit event details", "error", err, "id", event.ID)
+			return nil, fmt.Errorf("failed to decode audit event details: %w", err)
+		}
+		events = append(events, &event)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.ErrorContext(ctx, "failed to iterate audit events", "error", err)
+		return nil, fmt.Errorf("failed to iterate audit events: %w", err)
+	}
+	return events, nil
+}
+
+func clampLimit(limit int) int {
+	if limit <= 0 {
+		return defaultListLimit
+	}
+	if limit > maxListLimit {
+		return maxListLimit
+	}
+	return limit
+}
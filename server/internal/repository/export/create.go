@@ -0,0 +1,20 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"server/internal/domain"
+)
+
+func (r *Repository) CreateExport(ctx context.Context, export *domain.DataExport) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		"INSERT INTO data_export (id, user_id, status, created_at) VALUES (?, ?, ?, ?)",
+		export.ID, export.UserID, string(export.Status), export.CreatedAt,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to create data export", "error", err)
+		return fmt.Errorf("failed to create data export: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package export
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"os"
+	"server/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func setupTestDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+var exportColumns = []string{"id", "user_id", "status", "archive", "created_at", "completed_at", "expires_at"}
+
+func TestRepository_CreateExport(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+	now := time.Now()
+
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectExec("INSERT INTO data_export").
+		WithArgs("export-1", int64(1), "pending", now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	repo := NewRepository(logger, db)
+	err := repo.CreateExport(ctx, &domain.DataExport{
+		ID:        "export-1",
+		UserID:    1,
+		Status:    domain.DataExportStatusPending,
+		CreatedAt: now,
+	})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("mock expectations were not met: %v", err)
+	}
+}
+
+func TestRepository_GetExportByID(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+	now := time.Now()
+
+	tests := []struct {
+		name           string
+		setupMock      func(sqlmock.Sqlmock)
+		expectedError  error
+		expectedStatus domain.DataExportStatus
+	}{
+		{
+			name: "ready export",
+			setupMock: func(m sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows(exportColumns).
+					AddRow("export-1", 1, "ready", []byte(`{}`), now, now, now.Add(time.Hour))
+				m.ExpectQuery("FROM data_export WHERE id = \\?").
+					WithArgs("export-1").
+					WillReturnRows(rows)
+			},
+			expectedError:  nil,
+			expectedStatus: domain.DataExportStatusReady,
+		},
+		{
+			name: "export not found",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("FROM data_export WHERE id = \\?").
+					WithArgs("export-1").
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: domain.ErrExportNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			tt.setupMock(mock)
+
+			repo := NewRepository(logger, db)
+			export, err := repo.GetExportByID(ctx, "export-1")
+
+			if err != tt.expectedError {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if tt.expectedError == nil {
+				if export.Status != tt.expectedStatus {
+					t.Errorf("expected status %s, got %s", tt.expectedStatus, export.Status)
+				}
+				if export.ExpiresAt == nil {
+					t.Error("expected expires at to be set")
+				}
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("mock expectations were not met: %v", err)
+			}
+		})
+	}
+}
+
+func TestRepository_ListPendingExports(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+	now := time.Now()
+
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	rows := sqlmock.NewRows(exportColumns).
+		AddRow("export-1", 1, "pending", nil, now, nil, nil).
+		AddRow("export-2", 2, "pending", nil, now, nil, nil)
+	mock.ExpectQuery("FROM data_export\\s+WHERE status = \\? ORDER BY created_at ASC LIMIT \\?").
+		WithArgs("pending", 10).
+		WillReturnRows(rows)
+
+	repo := NewRepository(logger, db)
+	exports, err := repo.ListPendingExports(ctx, 10)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(exports) != 2 {
+		t.Errorf("expected 2 exports, got %d", len(exports))
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("mock expectations were not met: %v", err)
+	}
+}
+
+func TestRepository_DeleteExpiredExports(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+	now := time.Now()
+
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectExec("DELETE FROM data_export WHERE expires_at IS NOT NULL AND expires_at <= \\?").
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+
+	repo := NewRepository(logger, db)
+	deleted, err := repo.DeleteExpiredExports(ctx, now)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if deleted != 4 {
+		t.Errorf("expected 4 deleted exports, got %d", deleted)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("mock expectations were not met: %v", err)
+	}
+}
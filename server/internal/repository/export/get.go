@@ -0,0 +1,96 @@
+package export
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"server/internal/domain"
+)
+
+const selectExportColumns = "SELECT id, user_id, status, archive, created_at, completed_at, expires_at FROM data_export"
+
+func (r *Repository) GetExportByID(ctx context.Context, exportID string) (*domain.DataExport, error) {
+	row := r.db.QueryRowContext(ctx, selectExportColumns+" WHERE id = ?", exportID)
+	export, err := scanExport(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrExportNotFound
+		}
+		r.logger.ErrorContext(ctx, "failed to get data export", "error", err)
+		return nil, fmt.Errorf("failed to get data export: %w", err)
+	}
+	return export, nil
+}
+
+// GetLatestExportByUserID returns the newest export metadata without the
+// archive body, for showing the status on the profile page.
+func (r *Repository) GetLatestExportByUserID(ctx context.Context, userID int64) (*domain.DataExport, error) {
+	row := r.db.QueryRowContext(
+		ctx,
+		`SELECT id, user_id, status, NULL, created_at, completed_at, expires_at FROM data_export
+		WHERE user_id = ? ORDER BY created_at DESC LIMIT 1`,
+		userID,
+	)
+	export, err := scanExport(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrExportNotFound
+		}
+		r.logger.ErrorContext(ctx, "failed to get latest data export", "error", err)
+		return nil, fmt.Errorf("failed to get latest data export: %w", err)
+	}
+	return export, nil
+}
+
+func (r *Repository) ListPendingExports(ctx context.Context, limit int) ([]*domain.DataExport, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT id, user_id, status, NULL, created_at, completed_at, expires_at FROM data_export
+		WHERE status = ? ORDER BY created_at ASC LIMIT ?`,
+		string(domain.DataExportStatusPending), limit,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to list pending data exports", "error", err)
+		return nil, fmt.Errorf("failed to list pending data exports: %w", err)
+	}
+	defer rows.Close()
+
+	exports := make([]*domain.DataExport, 0)
+	for rows.Next() {
+		export, err := scanExport(rows)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "failed to scan data export", "error", err)
+			return nil, fmt.Errorf("failed to scan data export: %w", err)
+		}
+		exports = append(exports, export)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.ErrorContext(ctx, "failed to iterate data exports", "error", err)
+		return nil, fmt.Errorf("failed to iterate data exports: %w", err)
+	}
+	return exports, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanExport(row scanner) (*domain.DataExport, error) {
+	var export domain.DataExport
+	var status string
+	var archive []byte
+	var completedAt, expiresAt sql.NullTime
+	err := row.Scan(&export.ID, &export.UserID, &status, &archive, &export.CreatedAt, &completedAt, &expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	export.Status = domain.DataExportStatus(status)
+	export.Archive = archive
+	if completedAt.Valid {
+		export.CompletedAt = &completedAt.Time
+	}
+	if expiresAt.Valid {
+		export.ExpiresAt = &expiresAt.Time
+	}
+	return &export, nil
+}
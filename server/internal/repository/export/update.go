@@ -0,0 +1,50 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"server/internal/domain"
+	"time"
+)
+
+func (r *Repository) CompleteExport(ctx context.Context, exportID string, archive []byte, completedAt, expiresAt time.Time) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		"UPDATE data_export SET status = ?, archive = ?, completed_at = ?, expires_at = ? WHERE id = ?",
+		string(domain.DataExportStatusReady), archive, completedAt, expiresAt, exportID,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to complete data export", "error", err)
+		return fmt.Errorf("failed to complete data export: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) FailExport(ctx context.Context, exportID string, completedAt, expiresAt time.Time) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		"UPDATE data_export SET status = ?, completed_at = ?, expires_at = ? WHERE id = ?",
+		string(domain.DataExportStatusFailed), completedAt, expiresAt, exportID,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to mark data export as failed", "error", err)
+		return fmt.Errorf("failed to mark data export as failed: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpiredExports drops archives past their expiry so personal data is
+// not kept around longer than the download window.
+func (r *Repository) DeleteExpiredExports(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM data_export WHERE expires_at IS NOT NULL AND expires_at <= ?", now)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to delete expired data exports", "error", err)
+		return 0, fmt.Errorf("failed to delete expired data exports: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get rows affected", "error", err)
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return affected, nil
+}
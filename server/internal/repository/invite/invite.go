@@ -0,0 +1,123 @@
+package invite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"server/internal/domain"
+	"time"
+)
+
+func (r *Repository) CreateInviteCode(ctx context.Context, code *domain.InviteCode) error {
+	result, err := r.db.ExecContext(
+		ctx,
+		"INSERT INTO signup_invite (code_hash, note, created_by, created_at, expires_at) VALUES (?, ?, ?, ?, ?)",
+		code.CodeHash, code.Note, code.CreatedBy, code.CreatedAt, code.ExpiresAt,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to create invite code", "error", err)
+		return fmt.Errorf("failed to create invite code: %w", err)
+	}
+
+	code.ID, err = result.LastInsertId()
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get invite code id", "error", err)
+		return fmt.Errorf("failed to get invite code id: %w", err)
+	}
+	return nil
+}
+
+// ListInviteCodes returns all invite codes, newest first.
+func (r *Repository) ListInviteCodes(ctx context.Context) ([]*domain.InviteCode, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT id, code_hash, note, created_by, created_at, expires_at, used_email, used_at
+		FROM signup_invite ORDER BY id DESC`,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to list invite codes", "error", err)
+		return nil, fmt.Errorf("failed to list invite codes: %w", err)
+	}
+	defer rows.Close()
+
+	codes := make([]*domain.InviteCode, 0)
+	for rows.Next() {
+		var code domain.InviteCode
+		var createdBy sql.NullInt64
+		var usedEmail sql.NullString
+		var usedAt sql.NullTime
+		err := rows.Scan(&code.ID, &code.CodeHash, &code.Note, &createdBy, &code.CreatedAt, &code.ExpiresAt, &usedEmail, &usedAt)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "failed to scan invite code", "error", err)
+			return nil, fmt.Errorf("failed to scan invite code: %w", err)
+		}
+		code.CreatedBy = createdBy.Int64
+		code.UsedEmail = usedEmail.String
+		if usedAt.Valid {
+			code.UsedAt = &usedAt.Time
+		}
+		codes = append(codes, &code)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.ErrorContext(ctx, "failed to iterate invite codes", "error", err)
+		return nil, fmt.Errorf("failed to iterate invite codes: %w", err)
+	}
+	return codes, nil
+}
+
+func (r *Repository) DeleteInviteCode(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM signup_invite WHERE id = ?", id)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to delete invite code", "error", err)
+		return fmt.Errorf("failed to delete invite code: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get rows affected", "error", err)
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrInviteCodeNotFound
+	}
+	return nil
+}
+
+// RedeemInviteCode marks the code as used by email. The conditional update
+// makes sure that two sign-ups racing for the same code cannot both win; the
+// loser gets domain.ErrInvalidInviteCode, as for unknown or expired codes.
+func (r *Repository) RedeemInviteCode(ctx context.Context, codeHash, email string, now time.Time) error {
+	result, err := r.db.ExecContext(
+		ctx,
+		`UPDATE signup_invite SET used_email = ?, used_at = ?
+		WHERE code_hash = ? AND used_at IS NULL AND expires_at > ?`,
+		email, now, codeHash, now,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to redeem invite code", "error", err)
+		return fmt.Errorf("failed to redeem invite code: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get rows affected", "error", err)
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrInvalidInviteCode
+	}
+	return nil
+}
+
+// ReleaseInviteCode makes a redeemed code usable again, for when creating
+// the account failed after the code was redeemed.
+func (r *Repository) ReleaseInviteCode(ctx context.Context, codeHash string) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		"UPDATE signup_invite SET used_email = NULL, used_at = NULL WHERE code_hash = ?",
+		codeHash,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to release invite code", "error", err)
+		return fmt.Errorf("failed to release invite code: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package invite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"os"
+	"server/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func setupTestDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+func TestRepository_CreateInviteCode(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectExec("INSERT INTO signup_invite").
+		WithArgs("hash", "for Ada", int64(1), now, now.Add(time.Hour)).
+		WillReturnResult(sqlmock.NewResult(7, 1))
+
+	repo := NewRepository(logger, db)
+	code := &domain.InviteCode{CodeHash: "hash", Note: "for Ada", CreatedBy: 1, CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	if err := repo.CreateInviteCode(context.Background(), code); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if code.ID != 7 {
+		t.Errorf("expected id 7, got %d", code.ID)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_ListInviteCodes(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery("FROM signup_invite ORDER BY id DESC").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "code_hash", "note", "created_by", "created_at", "expires_at", "used_email", "used_at"}).
+			AddRow(2, "unused", "", 1, now, now.Add(time.Hour), nil, nil).
+			AddRow(1, "used", "for Ada", nil, now, now.Add(time.Hour), "ada@example.com", now))
+
+	repo := NewRepository(logger, db)
+	codes, err := repo.ListInviteCodes(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(codes) != 2 {
+		t.Fatalf("expected 2 codes, got %d", len(codes))
+	}
+	if codes[0].UsedAt != nil || codes[0].CreatedBy != 1 {
+		t.Errorf("unexpected unused code: %+v", codes[0])
+	}
+	if codes[1].UsedAt == nil || codes[1].UsedEmail != "ada@example.com" || codes[1].CreatedBy != 0 {
+		t.Errorf("unexpected used code: %+v", codes[1])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_DeleteInviteCode(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	tests := []struct {
+		name          string
+		rowsAffected  int64
+		expectedError error
+	}{
+		{name: "deleted", rowsAffected: 1},
+		{name: "unknown code", rowsAffected: 0, expectedError: domain.ErrInviteCodeNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			mock.ExpectExec("DELETE FROM signup_invite WHERE id = \\?").
+				WithArgs(int64(3)).
+				WillReturnResult(sqlmock.NewResult(0, tt.rowsAffected))
+
+			repo := NewRepository(logger, db)
+			err := repo.DeleteInviteCode(context.Background(), 3)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestRepository_RedeemInviteCode(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		setupMock     func(sqlmock.Sqlmock)
+		expectedError error
+		expectError   bool
+	}{
+		{
+			name: "unused code is redeemed",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectExec("UPDATE signup_invite SET used_email = \\?, used_at = \\?").
+					WithArgs("ada@example.com", now, "hash", now).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "used, expired or unknown code",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectExec("UPDATE signup_invite SET used_email = \\?, used_at = \\?").
+					WithArgs("ada@example.com", now, "hash", now).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedError: domain.ErrInvalidInviteCode,
+			expectError:   true,
+		},
+		{
+			name: "database error",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectExec("UPDATE signup_invite").
+					WillReturnError(errors.New("db error"))
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			tt.setupMock(mock)
+
+			repo := NewRepository(logger, db)
+			err := repo.RedeemInviteCode(context.Background(), "hash", "ada@example.com", now)
+
+			if tt.expectError != (err != nil) {
+				t.Errorf("expected error %v, got %v", tt.expectError, err)
+			}
+			if tt.expectedError != nil && !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestRepository_ReleaseInviteCode(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE signup_invite SET used_email = NULL, used_at = NULL WHERE code_hash = \\?").
+		WithArgs("hash").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	repo := NewRepository(logger, db)
+	if err := repo.ReleaseInviteCode(context.Background(), "hash"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
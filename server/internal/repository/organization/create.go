@@ -0,0 +1,61 @@
+package organization
+
+import (
+	"context"
+	"fmt"
+	"server/internal/domain"
+)
+
+// CreateOrganization stores the organization and makes its creator the
+// first owner.
+func (r *Repository) CreateOrganization(ctx context.Context, organization *domain.Organization) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				r.logger.ErrorContext(ctx, "failed to rollback transaction", "error", rollbackErr)
+			}
+		}
+	}()
+
+	result, err := tx.ExecContext(
+		ctx,
+		"INSERT INTO organization (name, created_by, created_at) VALUES (?, ?, ?)",
+		organization.Name, organization.CreatedBy, organization.CreatedAt,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to create organization", "error", err)
+		return fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	organizationID, err := result.LastInsertId()
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get organization id", "error", err)
+		return fmt.Errorf("failed to get organization id: %w", err)
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		"INSERT INTO organization_member (organization_id, user_id, role, created_at) VALUES (?, ?, ?, ?)",
+		organizationID, organization.CreatedBy, string(domain.OrganizationRoleOwner), organization.CreatedAt,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to add organization owner", "error", err)
+		return fmt.Errorf("failed to add organization owner: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.ErrorContext(ctx, "failed to commit transaction", "error", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	committed = true
+
+	organization.ID = organizationID
+	return nil
+}
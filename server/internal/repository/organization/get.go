@@ -0,0 +1,110 @@
+package organization
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"server/internal/domain"
+)
+
+const selectMembershipColumns = `SELECT o.id, o.name, o.created_by, o.created_at, m.role, m.created_at
+	FROM organization_member m JOIN organization o ON o.id = m.organization_id`
+
+// GetMembership returns the organization as userID is a member of it, or
+// domain.ErrNotOrganizationMember.
+func (r *Repository) GetMembership(ctx context.Context, organizationID, userID int64) (*domain.OrganizationMembership, error) {
+	membership, err := scanMembership(r.db.QueryRowContext(
+		ctx,
+		selectMembershipColumns+" WHERE m.organization_id = ? AND m.user_id = ?",
+		organizationID, userID,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotOrganizationMember
+		}
+		r.logger.ErrorContext(ctx, "failed to get organization membership", "error", err)
+		return nil, fmt.Errorf("failed to get organization membership: %w", err)
+	}
+	return membership, nil
+}
+
+// ListUserOrganizations returns the organizations the user belongs to, by
+// name.
+func (r *Repository) ListUserOrganizations(ctx context.Context, userID int64) ([]*domain.OrganizationMembership, error) {
+	rows, err := r.db.QueryContext(ctx, selectMembershipColumns+" WHERE m.user_id = ? ORDER BY o.name, o.id", userID)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to list organizations", "error", err)
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	defer rows.Close()
+
+	memberships := make([]*domain.OrganizationMembership, 0)
+	for rows.Next() {
+		membership, err := scanMembership(rows)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "failed to scan organization", "error", err)
+			return nil, fmt.Errorf("failed to scan organization: %w", err)
+		}
+		memberships = append(memberships, membership)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.ErrorContext(ctx, "failed to iterate organizations", "error", err)
+		return nil, fmt.Errorf("failed to iterate organizations: %w", err)
+	}
+	return memberships, nil
+}
+
+// ListMembers returns the members of the organization in the order they
+// joined.
+func (r *Repository) ListMembers(ctx context.Context, organizationID int64) ([]*domain.OrganizationMember, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT m.organization_id, m.user_id, u.email, u.full_name, m.role, m.created_at
+		FROM organization_member m JOIN user u ON u.id = m.user_id
+		WHERE m.organization_id = ? ORDER BY m.created_at, m.user_id`,
+		organizationID,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to list organization members", "error", err)
+		return nil, fmt.Errorf("failed to list organization members: %w", err)
+	}
+	defer rows.Close()
+
+	members := make([]*domain.OrganizationMember, 0)
+	for rows.Next() {
+		var member domain.OrganizationMember
+		var fullName sql.NullString
+		var role string
+		err := rows.Scan(&member.OrganizationID, &member.UserID, &member.Email, &fullName, &role, &member.JoinedAt)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "failed to scan organization member", "error", err)
+			return nil, fmt.Errorf("failed to scan organization member: %w", err)
+		}
+		member.FullName = fullName.String
+		member.Role = domain.OrganizationRole(role)
+		members = append(members, &member)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.ErrorContext(ctx, "failed to iterate organization members", "error", err)
+		return nil, fmt.Errorf("failed to iterate organization members: %w", err)
+	}
+	return members, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanMembership(row scanner) (*domain.OrganizationMembership, error) {
+	var membership domain.OrganizationMembership
+	var createdBy sql.NullInt64
+	var role string
+	err := row.Scan(&membership.Organization.ID, &membership.Organization.Name, &createdBy,
+		&membership.Organization.CreatedAt, &role, &membership.JoinedAt)
+	if err != nil {
+		return nil, err
+	}
+	membership.Organization.CreatedBy = createdBy.Int64
+	membership.Role = domain.OrganizationRole(role)
+	return &membership, nil
+}
@@ -0,0 +1,164 @@
+package organization
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"server/internal/domain"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// SaveInvitation stores the invitation, replacing a pending one for the same
+// address so only the newest link works.
+func (r *Repository) SaveInvitation(ctx context.Context, invitation *domain.OrganizationInvitation) error {
+	result, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO organization_invitation (organization_id, email, role, token_hash, invited_by, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id), role = VALUES(role), token_hash = VALUES(token_hash),
+			invited_by = VALUES(invited_by), created_at = VALUES(created_at), expires_at = VALUES(expires_at)`,
+		invitation.OrganizationID, invitation.Email, string(invitation.Role), invitation.TokenHash,
+		invitation.InvitedBy, invitation.CreatedAt, invitation.ExpiresAt,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to save organization invitation", "error", err)
+		return fmt.Errorf("failed to save organization invitation: %w", err)
+	}
+
+	invitation.ID, err = result.LastInsertId()
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get organization invitation id", "error", err)
+		return fmt.Errorf("failed to get organization invitation id: %w", err)
+	}
+	return nil
+}
+
+// ListInvitations returns the invitations of the organization that have not
+// expired by now, newest first.
+func (r *Repository) ListInvitations(ctx context.Context, organizationID int64, now time.Time) ([]*domain.OrganizationInvitation, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		selectInvitationColumns+" WHERE i.organization_id = ? AND i.expires_at > ? ORDER BY i.id DESC",
+		organizationID, now,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to list organization invitations", "error", err)
+		return nil, fmt.Errorf("failed to list organization invitations: %w", err)
+	}
+	defer rows.Close()
+
+	invitations := make([]*domain.OrganizationInvitation, 0)
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "failed to scan organization invitation", "error", err)
+			return nil, fmt.Errorf("failed to scan organization invitation: %w", err)
+		}
+		invitations = append(invitations, invitation)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.ErrorContext(ctx, "failed to iterate organization invitations", "error", err)
+		return nil, fmt.Errorf("failed to iterate organization invitations: %w", err)
+	}
+	return invitations, nil
+}
+
+func (r *Repository) GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*domain.OrganizationInvitation, error) {
+	invitation, err := scanInvitation(r.db.QueryRowContext(ctx, selectInvitationColumns+" WHERE i.token_hash = ?", tokenHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrInvitationNotFound
+		}
+		r.logger.ErrorContext(ctx, "failed to get organization invitation", "error", err)
+		return nil, fmt.Errorf("failed to get organization invitation: %w", err)
+	}
+	return invitation, nil
+}
+
+// AcceptInvitation adds the user with the invited role and uses up the
+// invitation. It fails with domain.ErrInvitationNotFound if the invitation
+// was accepted or replaced in the meantime.
+func (r *Repository) AcceptInvitation(ctx context.Context, invitation *domain.OrganizationInvitation, userID int64, joinedAt time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				r.logger.ErrorContext(ctx, "failed to rollback transaction", "error", rollbackErr)
+			}
+		}
+	}()
+
+	result, err := tx.ExecContext(
+		ctx,
+		"DELETE FROM organization_invitation WHERE id = ? AND token_hash = ?",
+		invitation.ID, invitation.TokenHash,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to delete organization invitation", "error", err)
+		return fmt.Errorf("failed to delete organization invitation: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get rows affected", "error", err)
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrInvitationNotFound
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		"INSERT INTO organization_member (organization_id, user_id, role, created_at) VALUES (?, ?, ?, ?)",
+		invitation.OrganizationID, userID, string(invitation.Role), joinedAt,
+	)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == ErrDuplicateEntry {
+			return domain.ErrAlreadyOrganizationMember
+		}
+		r.logger.ErrorContext(ctx, "failed to add organization member", "error", err)
+		return fmt.Errorf("failed to add organization member: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.ErrorContext(ctx, "failed to commit transaction", "error", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	committed = true
+	return nil
+}
+
+func (r *Repository) DeleteInvitation(ctx context.Context, invitationID int64) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM organization_invitation WHERE id = ?", invitationID)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to delete organization invitation", "error", err)
+		return fmt.Errorf("failed to delete organization invitation: %w", err)
+	}
+	return nil
+}
+
+const selectInvitationColumns = `SELECT i.id, i.organization_id, o.name, i.email, i.role, i.token_hash, i.invited_by,
+	i.created_at, i.expires_at FROM organization_invitation i JOIN organization o ON o.id = i.organization_id`
+
+func scanInvitation(row scanner) (*domain.OrganizationInvitation, error) {
+	var invitation domain.OrganizationInvitation
+	var invitedBy sql.NullInt64
+	var role string
+	err := row.Scan(&invitation.ID, &invitation.OrganizationID, &invitation.OrganizationName, &invitation.Email, &role,
+		&invitation.TokenHash, &invitedBy, &invitation.CreatedAt, &invitation.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	invitation.InvitedBy = invitedBy.Int64
+	invitation.Role = domain.OrganizationRole(role)
+	return &invitation, nil
+}
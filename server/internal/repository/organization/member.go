@@ -0,0 +1,86 @@
+package organization
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"server/internal/domain"
+)
+
+// UpdateMemberRole changes the role of a member. Demoting the last owner
+// fails with domain.ErrLastOrganizationOwner.
+func (r *Repository) UpdateMemberRole(ctx context.Context, organizationID, userID int64, role domain.OrganizationRole) error {
+	return r.changeMember(ctx, organizationID, userID, role != domain.OrganizationRoleOwner,
+		"UPDATE organization_member SET role = ? WHERE organization_id = ? AND user_id = ?",
+		string(role), organizationID, userID)
+}
+
+// RemoveMember takes the user out of the organization. The last owner
+// cannot be removed.
+func (r *Repository) RemoveMember(ctx context.Context, organizationID, userID int64) error {
+	return r.changeMember(ctx, organizationID, userID, true,
+		"DELETE FROM organization_member WHERE organization_id = ? AND user_id = ?",
+		organizationID, userID)
+}
+
+// changeMember runs query against an existing member. dropsOwner tells
+// whether the query takes the owner role away; the owner rows are locked
+// while counting so two owners cannot demote each other at the same time.
+func (r *Repository) changeMember(ctx context.Context, organizationID, userID int64, dropsOwner bool, query string, args ...interface{}) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				r.logger.ErrorContext(ctx, "failed to rollback transaction", "error", rollbackErr)
+			}
+		}
+	}()
+
+	var role string
+	err = tx.QueryRowContext(
+		ctx,
+		"SELECT role FROM organization_member WHERE organization_id = ? AND user_id = ? FOR UPDATE",
+		organizationID, userID,
+	).Scan(&role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.ErrNotOrganizationMember
+		}
+		r.logger.ErrorContext(ctx, "failed to get organization member", "error", err)
+		return fmt.Errorf("failed to get organization member: %w", err)
+	}
+
+	if dropsOwner && domain.OrganizationRole(role) == domain.OrganizationRoleOwner {
+		var owners int
+		err = tx.QueryRowContext(
+			ctx,
+			"SELECT COUNT(*) FROM organization_member WHERE organization_id = ? AND role = ? FOR UPDATE",
+			organizationID, string(domain.OrganizationRoleOwner),
+		).Scan(&owners)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "failed to count organization owners", "error", err)
+			return fmt.Errorf("failed to count organization owners: %w", err)
+		}
+		if owners <= 1 {
+			return domain.ErrLastOrganizationOwner
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		r.logger.ErrorContext(ctx, "failed to update organization member", "error", err)
+		return fmt.Errorf("failed to update organization member: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.ErrorContext(ctx, "failed to commit transaction", "error", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	committed = true
+	return nil
+}
@@ -0,0 +1,467 @@
+package organization
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"os"
+	"server/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+)
+
+func setupTestDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+var membershipColumns = []string{"id", "name", "created_by", "created_at", "role", "created_at"}
+
+var invitationColumns = []string{"id", "organization_id", "name", "email", "role", "token_hash", "invited_by", "created_at", "expires_at"}
+
+func TestRepository_CreateOrganization(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+	createdAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		setupMock   func(sqlmock.Sqlmock)
+		expectError bool
+	}{
+		{
+			name: "organization and owner are created together",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec("INSERT INTO organization \\(name, created_by, created_at\\)").
+					WithArgs("Acme", int64(1), createdAt).
+					WillReturnResult(sqlmock.NewResult(9, 1))
+				m.ExpectExec("INSERT INTO organization_member").
+					WithArgs(int64(9), int64(1), "owner", createdAt).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			},
+		},
+		{
+			name: "failed owner insert rolls back",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec("INSERT INTO organization \\(name, created_by, created_at\\)").
+					WillReturnResult(sqlmock.NewResult(9, 1))
+				m.ExpectExec("INSERT INTO organization_member").
+					WillReturnError(errors.New("db error"))
+				m.ExpectRollback()
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			tt.setupMock(mock)
+
+			repo := NewRepository(logger, db)
+			organization := &domain.Organization{Name: "Acme", CreatedBy: 1, CreatedAt: createdAt}
+			err := repo.CreateOrganization(ctx, organization)
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if organization.ID != 9 {
+				t.Errorf("expected id 9, got %d", organization.ID)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestRepository_GetMembership(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		setupMock     func(sqlmock.Sqlmock)
+		expectedRole  domain.OrganizationRole
+		expectedError error
+	}{
+		{
+			name: "member",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("FROM organization_member m JOIN organization o").
+					WithArgs(int64(9), int64(2)).
+					WillReturnRows(sqlmock.NewRows(membershipColumns).AddRow(9, "Acme", nil, now, "admin", now))
+			},
+			expectedRole: domain.OrganizationRoleAdmin,
+		},
+		{
+			name: "not a member",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("FROM organization_member m JOIN organization o").
+					WithArgs(int64(9), int64(2)).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: domain.ErrNotOrganizationMember,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			tt.setupMock(mock)
+
+			repo := NewRepository(logger, db)
+			membership, err := repo.GetMembership(ctx, 9, 2)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if tt.expectedError == nil && (membership.Organization.Name != "Acme" || membership.Role != tt.expectedRole) {
+				t.Errorf("unexpected membership: %+v", membership)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestRepository_ListMembers(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery("FROM organization_member m JOIN user u").
+		WithArgs(int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"organization_id", "user_id", "email", "full_name", "role", "created_at"}).
+			AddRow(9, 1, "owner@example.com", "Owner", "owner", now).
+			AddRow(9, 2, "member@example.com", nil, "member", now))
+
+	repo := NewRepository(logger, db)
+	members, err := repo.ListMembers(ctx, 9)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(members) != 2 || members[0].Role != domain.OrganizationRoleOwner || members[1].FullName != "" {
+		t.Errorf("unexpected members: %+v %+v", members[0], members[1])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_UpdateMemberRole(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		role          domain.OrganizationRole
+		setupMock     func(sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name: "member is promoted",
+			role: domain.OrganizationRoleAdmin,
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT role FROM organization_member WHERE organization_id = \\? AND user_id = \\? FOR UPDATE").
+					WithArgs(int64(9), int64(2)).
+					WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("member"))
+				m.ExpectExec("UPDATE organization_member SET role = \\?").
+					WithArgs("admin", int64(9), int64(2)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			},
+		},
+		{
+			name: "owner is demoted while another owner remains",
+			role: domain.OrganizationRoleMember,
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT role FROM organization_member").
+					WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("owner"))
+				m.ExpectQuery("SELECT COUNT\\(\\*\\) FROM organization_member WHERE organization_id = \\? AND role = \\? FOR UPDATE").
+					WithArgs(int64(9), "owner").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				m.ExpectExec("UPDATE organization_member SET role = \\?").
+					WithArgs("member", int64(9), int64(2)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			},
+		},
+		{
+			name: "last owner is kept",
+			role: domain.OrganizationRoleAdmin,
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT role FROM organization_member").
+					WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("owner"))
+				m.ExpectQuery("SELECT COUNT\\(\\*\\) FROM organization_member").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				m.ExpectRollback()
+			},
+			expectedError: domain.ErrLastOrganizationOwner,
+		},
+		{
+			name: "not a member",
+			role: domain.OrganizationRoleAdmin,
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT role FROM organization_member").
+					WillReturnError(sql.ErrNoRows)
+				m.ExpectRollback()
+			},
+			expectedError: domain.ErrNotOrganizationMember,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			tt.setupMock(mock)
+
+			repo := NewRepository(logger, db)
+			err := repo.UpdateMemberRole(ctx, 9, 2, tt.role)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestRepository_RemoveMember(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		setupMock     func(sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name: "member is removed",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT role FROM organization_member").
+					WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("member"))
+				m.ExpectExec("DELETE FROM organization_member WHERE organization_id = \\? AND user_id = \\?").
+					WithArgs(int64(9), int64(2)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			},
+		},
+		{
+			name: "last owner cannot leave",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT role FROM organization_member").
+					WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("owner"))
+				m.ExpectQuery("SELECT COUNT\\(\\*\\) FROM organization_member").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				m.ExpectRollback()
+			},
+			expectedError: domain.ErrLastOrganizationOwner,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			tt.setupMock(mock)
+
+			repo := NewRepository(logger, db)
+			err := repo.RemoveMember(ctx, 9, 2)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestRepository_SaveInvitation(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectExec("INSERT INTO organization_invitation .* ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID\\(id\\)").
+		WithArgs(int64(9), "new@example.com", "admin", "hash", int64(1), now, now.Add(time.Hour)).
+		WillReturnResult(sqlmock.NewResult(4, 1))
+
+	repo := NewRepository(logger, db)
+	invitation := &domain.OrganizationInvitation{
+		OrganizationID: 9,
+		Email:          "new@example.com",
+		Role:           domain.OrganizationRoleAdmin,
+		TokenHash:      "hash",
+		InvitedBy:      1,
+		CreatedAt:      now,
+		ExpiresAt:      now.Add(time.Hour),
+	}
+	if err := repo.SaveInvitation(ctx, invitation); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if invitation.ID != 4 {
+		t.Errorf("expected id 4, got %d", invitation.ID)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_GetInvitationByTokenHash(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		setupMock     func(sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name: "found",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("FROM organization_invitation i JOIN organization o .* WHERE i.token_hash = \\?").
+					WithArgs("hash").
+					WillReturnRows(sqlmock.NewRows(invitationColumns).
+						AddRow(4, 9, "Acme", "new@example.com", "member", "hash", nil, now, now.Add(time.Hour)))
+			},
+		},
+		{
+			name: "not found",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("FROM organization_invitation").
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: domain.ErrInvitationNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			tt.setupMock(mock)
+
+			repo := NewRepository(logger, db)
+			invitation, err := repo.GetInvitationByTokenHash(ctx, "hash")
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if tt.expectedError == nil && (invitation.OrganizationName != "Acme" || invitation.Role != domain.OrganizationRoleMember) {
+				t.Errorf("unexpected invitation: %+v", invitation)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestRepository_AcceptInvitation(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+	joinedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		setupMock     func(sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name: "member is added and the invitation used up",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec("DELETE FROM organization_invitation WHERE id = \\? AND token_hash = \\?").
+					WithArgs(int64(4), "hash").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectExec("INSERT INTO organization_member").
+					WithArgs(int64(9), int64(2), "admin", joinedAt).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			},
+		},
+		{
+			name: "invitation already used",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec("DELETE FROM organization_invitation").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				m.ExpectRollback()
+			},
+			expectedError: domain.ErrInvitationNotFound,
+		},
+		{
+			name: "already a member",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec("DELETE FROM organization_invitation").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectExec("INSERT INTO organization_member").
+					WillReturnError(&mysql.MySQLError{Number: ErrDuplicateEntry, Message: "Duplicate entry"})
+				m.ExpectRollback()
+			},
+			expectedError: domain.ErrAlreadyOrganizationMember,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			tt.setupMock(mock)
+
+			repo := NewRepository(logger, db)
+			invitation := &domain.OrganizationInvitation{ID: 4, OrganizationID: 9, Role: domain.OrganizationRoleAdmin, TokenHash: "hash"}
+			err := repo.AcceptInvitation(ctx, invitation, 2, joinedAt)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
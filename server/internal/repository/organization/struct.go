@@ -0,0 +1,18 @@
+package organization
+
+import (
+	"database/sql"
+	"log/slog"
+)
+
+// ErrDuplicateEntry is the MySQL error number for a unique key violation.
+const ErrDuplicateEntry = 1062
+
+type Repository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func NewRepository(logger *slog.Logger, db *sql.DB) *Repository {
+	return &Repository{logger: logger, db: db}
+}
@@ -0,0 +1,56 @@
+package profilefield
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"server/internal/domain"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func (r *Repository) CreateField(ctx context.Context, field *domain.ProfileField) error {
+	rules, err := encodeRules(field.Rules)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to encode profile field rules", "error", err)
+		return fmt.Errorf("failed to encode profile field rules: %w", err)
+	}
+
+	result, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO profile_field (field_key, label, field_type, rules, required, visibility, position)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		field.Key, field.Label, string(field.Type), rules, field.Required, string(field.Visibility), field.Position,
+	)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == ErrDuplicateEntry {
+			return domain.ErrProfileFieldExists
+		}
+		r.logger.ErrorContext(ctx, "failed to create profile field", "error", err)
+		return fmt.Errorf("failed to create profile field: %w", err)
+	}
+
+	field.ID, err = result.LastInsertId()
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get profile field id", "error", err)
+		return fmt.Errorf("failed to get profile field id: %w", err)
+	}
+	return nil
+}
+
+func encodeRules(rules domain.ProfileFieldRules) (string, error) {
+	data, err := json.Marshal(rulesJSON{
+		MinLength: rules.MinLength,
+		MaxLength: rules.MaxLength,
+		Pattern:   rules.Pattern,
+		Min:       rules.Min,
+		Max:       rules.Max,
+		Options:   rules.Options,
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,83 @@
+package profilefield
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"server/internal/domain"
+)
+
+const selectFieldColumns = `SELECT id, field_key, label, field_type, rules, required, visibility,
+	position, created_at, updated_at FROM profile_field`
+
+// ListFields returns every field definition in display order.
+func (r *Repository) ListFields(ctx context.Context) ([]*domain.ProfileField, error) {
+	rows, err := r.db.QueryContext(ctx, selectFieldColumns+" ORDER BY position, id")
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to list profile fields", "error", err)
+		return nil, fmt.Errorf("failed to list profile fields: %w", err)
+	}
+	defer rows.Close()
+
+	fields := make([]*domain.ProfileField, 0)
+	for rows.Next() {
+		field, err := r.scanField(ctx, rows)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.ErrorContext(ctx, "failed to iterate profile fields", "error", err)
+		return nil, fmt.Errorf("failed to iterate profile fields: %w", err)
+	}
+	return fields, nil
+}
+
+func (r *Repository) GetFieldByKey(ctx context.Context, key string) (*domain.ProfileField, error) {
+	row := r.db.QueryRowContext(ctx, selectFieldColumns+" WHERE field_key = ?", key)
+	field, err := r.scanField(ctx, row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrProfileFieldNotFound
+		}
+		return nil, err
+	}
+	return field, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func (r *Repository) scanField(ctx context.Context, row scanner) (*domain.ProfileField, error) {
+	var field domain.ProfileField
+	var fieldType, rules, visibility string
+	err := row.Scan(&field.ID, &field.Key, &field.Label, &fieldType, &rules, &field.Required, &visibility,
+		&field.Position, &field.CreatedAt, &field.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		r.logger.ErrorContext(ctx, "failed to scan profile field", "error", err)
+		return nil, fmt.Errorf("failed to scan profile field: %w", err)
+	}
+	field.Type = domain.ProfileFieldType(fieldType)
+	field.Visibility = domain.ProfileFieldVisibility(visibility)
+
+	var decoded rulesJSON
+	if err := json.Unmarshal([]byte(rules), &decoded); err != nil {
+		r.logger.ErrorContext(ctx, "failed to decode profile field rules", "error", err, "key", field.Key)
+		return nil, fmt.Errorf("failed to decode profile field rules: %w", err)
+	}
+	field.Rules = domain.ProfileFieldRules{
+		MinLength: decoded.MinLength,
+		MaxLength: decoded.MaxLength,
+		Pattern:   decoded.Pattern,
+		Min:       decoded.Min,
+		Max:       decoded.Max,
+		Options:   decoded.Options,
+	}
+	return &field, nil
+}
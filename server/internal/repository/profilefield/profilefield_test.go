@@ -0,0 +1,206 @@
+package profilefield
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"os"
+	"server/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+)
+
+func setupTestDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+var fieldColumns = []string{"id", "field_key", "label", "field_type", "rules", "required", "visibility", "position", "created_at", "updated_at"}
+
+func TestRepository_CreateField(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		setupMock     func(sqlmock.Sqlmock)
+		expectedID    int64
+		expectedError error
+	}{
+		{
+			name: "successful create",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectExec("INSERT INTO profile_field").
+					WithArgs("team", "Team", "select", `{"options":["core","platform"]}`, true, "public", 2).
+					WillReturnResult(sqlmock.NewResult(5, 1))
+			},
+			expectedID: 5,
+		},
+		{
+			name: "duplicate key",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectExec("INSERT INTO profile_field").
+					WillReturnError(&mysql.MySQLError{Number: ErrDuplicateEntry, Message: "Duplicate entry"})
+			},
+			expectedError: domain.ErrProfileFieldExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			tt.setupMock(mock)
+
+			repo := NewRepository(logger, db)
+			field := &domain.ProfileField{
+				Key:        "team",
+				Label:      "Team",
+				Type:       domain.ProfileFieldTypeSelect,
+				Rules:      domain.ProfileFieldRules{Options: []string{"core", "platform"}},
+				Required:   true,
+				Visibility: domain.ProfileFieldVisibilityPublic,
+				Position:   2,
+			}
+			err := repo.CreateField(ctx, field)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if tt.expectedError == nil && field.ID != tt.expectedID {
+				t.Errorf("expected id %d, got %d", tt.expectedID, field.ID)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestRepository_ListFields(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+	now := time.Now()
+
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT (.+) FROM profile_field ORDER BY position, id").
+		WillReturnRows(sqlmock.NewRows(fieldColumns).
+			AddRow(1, "age", "Age", "number", `{"min":18,"max":120}`, false, "private", 0, now, now).
+			AddRow(2, "bio", "Bio", "textarea", `{}`, false, "public", 1, now, now))
+
+	repo := NewRepository(logger, db)
+	fields, err := repo.ListFields(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(fields) != 2 {
+		t.Fatalf("expected 2 fields, got %d", len(fields))
+	}
+	if fields[0].Rules.Min == nil || *fields[0].Rules.Min != 18 || fields[0].Rules.Max == nil || *fields[0].Rules.Max != 120 {
+		t.Errorf("unexpected rules: %+v", fields[0].Rules)
+	}
+	if fields[1].Type != domain.ProfileFieldTypeTextArea || fields[1].Visibility != domain.ProfileFieldVisibilityPublic {
+		t.Errorf("unexpected field: %+v", fields[1])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_GetFieldByKey(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+	now := time.Now()
+
+	tests := []struct {
+		name          string
+		setupMock     func(sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name: "found",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("SELECT (.+) FROM profile_field WHERE field_key = \\?").
+					WithArgs("bio").
+					WillReturnRows(sqlmock.NewRows(fieldColumns).AddRow(2, "bio", "Bio", "textarea", `{"max_length":500}`, false, "public", 1, now, now))
+			},
+		},
+		{
+			name: "not found",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("SELECT (.+) FROM profile_field WHERE field_key = \\?").
+					WithArgs("bio").
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: domain.ErrProfileFieldNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			tt.setupMock(mock)
+
+			repo := NewRepository(logger, db)
+			field, err := repo.GetFieldByKey(ctx, "bio")
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if tt.expectedError == nil && field.Rules.MaxLength != 500 {
+				t.Errorf("expected max length 500, got %d", field.Rules.MaxLength)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestRepository_DeleteField(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		affected      int64
+		expectedError error
+	}{
+		{name: "deleted", affected: 1},
+		{name: "not found", affected: 0, expectedError: domain.ErrProfileFieldNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			mock.ExpectExec("DELETE FROM profile_field WHERE field_key = \\?").
+				WithArgs("bio").
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			repo := NewRepository(logger, db)
+			err := repo.DeleteField(ctx, "bio")
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package profilefield
+
+import (
+	"database/sql"
+	"log/slog"
+)
+
+// ErrDuplicateEntry is the MySQL error number for a unique key violation.
+const ErrDuplicateEntry = 1062
+
+type Repository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func NewRepository(logger *slog.Logger, db *sql.DB) *Repository {
+	return &Repository{logger: logger, db: db}
+}
+
+// rulesJSON is how domain.ProfileFieldRules is stored in profile_field.rules.
+type rulesJSON struct {
+	MinLength int      `json:"min_length,omitempty"`
+	MaxLength int      `json:"max_length,omitempty"`
+	Pattern   string   `json:"pattern,omitempty"`
+	Min       *float64 `json:"min,omitempty"`
+	Max       *float64 `json:"max,omitempty"`
+	Options   []string `json:"options,omitempty"`
+}
@@ -0,0 +1,49 @@
+package profilefield
+
+import (
+	"context"
+	"fmt"
+	"server/internal/domain"
+)
+
+// UpdateField rewrites the definition of the field with the given key; the
+// key and the type stay as they are. MySQL reports unchanged rows as not
+// affected, so callers look the field up first instead of relying on the
+// row count.
+func (r *Repository) UpdateField(ctx context.Context, field *domain.ProfileField) error {
+	rules, err := encodeRules(field.Rules)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to encode profile field rules", "error", err)
+		return fmt.Errorf("failed to encode profile field rules: %w", err)
+	}
+
+	_, err = r.db.ExecContext(
+		ctx,
+		"UPDATE profile_field SET label = ?, rules = ?, required = ?, visibility = ?, position = ? WHERE field_key = ?",
+		field.Label, rules, field.Required, string(field.Visibility), field.Position, field.Key,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to update profile field", "error", err)
+		return fmt.Errorf("failed to update profile field: %w", err)
+	}
+	return nil
+}
+
+// DeleteField removes the definition together with every stored value.
+func (r *Repository) DeleteField(ctx context.Context, key string) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM profile_field WHERE field_key = ?", key)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to delete profile field", "error", err)
+		return fmt.Errorf("failed to delete profile field: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get rows affected", "error", err)
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if affected == 0 {
+		return domain.ErrProfileFieldNotFound
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package refreshtoken
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"server/internal/domain"
+	"time"
+)
+
+const tokenColumns = "t.id, t.family_id, t.user_id, t.token_hash, t.auth_method, t.created_at, t.expires_at, t.used_at, t.revoked_at"
+
+func (r *Repository) CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error {
+	result, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO refresh_token (family_id, user_id, token_hash, auth_method, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		token.FamilyID, token.UserID, token.TokenHash, token.AuthMethod, token.CreatedAt, token.ExpiresAt,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to create refresh token", "error", err)
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	token.ID, err = result.LastInsertId()
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get refresh token id", "error", err)
+		return fmt.Errorf("failed to get refresh token id: %w", err)
+	}
+	return nil
+}
+
+// GetRefreshTokenByHash finds a token by the hash of its value, whether it
+// was used or revoked or not. Tokens of accounts scheduled for deletion are
+// not found, so they stop working together with the account's sessions.
+func (r *Repository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	row := r.db.QueryRowContext(
+		ctx,
+		`SELECT `+tokenColumns+` FROM refresh_token t
+		JOIN user u ON u.id = t.user_id
+		WHERE t.token_hash = ? AND u.deletion_scheduled_at IS NULL`,
+		tokenHash,
+	)
+	token, err := scanToken(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrRefreshTokenNotFound
+	}
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get refresh token", "error", err)
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+	return token, nil
+}
+
+// ListUserRefreshTokens returns every refresh token of userID still on
+// record, newest first.
+func (r *Repository) ListUserRefreshTokens(ctx context.Context, userID int64) ([]*domain.RefreshToken, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT "+tokenColumns+" FROM refresh_token t WHERE t.user_id = ? ORDER BY t.id DESC",
+		userID,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to list refresh tokens", "error", err)
+		return nil, fmt.Errorf("failed to list refresh tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := make([]*domain.RefreshToken, 0)
+	for rows.Next() {
+		token, err := scanToken(rows)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "failed to scan refresh token", "error", err)
+			return nil, fmt.Errorf("failed to scan refresh token: %w", err)
+		}
+		tokens = append(tokens, token)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.ErrorContext(ctx, "failed to iterate refresh tokens", "error", err)
+		return nil, fmt.Errorf("failed to iterate refresh tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// UseRefreshToken marks a token used. It reports false when the token was
+// already used or revoked, which makes two concurrent refreshes with the
+// same token count as reuse.
+func (r *Repository) UseRefreshToken(ctx context.Context, id int64, usedAt time.Time) (bool, error) {
+	result, err := r.db.ExecContext(
+		ctx,
+		"UPDATE refresh_token SET used_at = ? WHERE id = ? AND used_at IS NULL AND revoked_at IS NULL",
+		usedAt, id,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to use refresh token", "error", err)
+		return false, fmt.Errorf("failed to use refresh token: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get rows affected", "error", err)
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *Repository) RevokeRefreshTokenFamily(ctx context.Context, familyID string, revokedAt time.Time) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		"UPDATE refresh_token SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL",
+		revokedAt, familyID,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to revoke refresh token family", "error", err)
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpiredRefreshTokens drops tokens past their expiry. They can no
+// longer be used, so nothing is lost for reuse detection.
+// RevokeUserRefreshTokens revokes every refresh token of userID, logging all
+// of the user's JWT clients out.
+func (r *Repository) RevokeUserRefreshTokens(ctx context.Context, userID int64, revokedAt time.Time) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		"UPDATE refresh_token SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL",
+		revokedAt, userID,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to revoke user refresh tokens", "error", err)
+		return fmt.Errorf("failed to revoke user refresh tokens: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) DeleteExpiredRefreshTokens(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM refresh_token WHERE expires_at <= ?", now)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to delete expired refresh tokens", "error", err)
+		return 0, fmt.Errorf("failed to delete expired refresh tokens: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get rows affected", "error", err)
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return affected, nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanToken(row scanner) (*domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	var usedAt, revokedAt sql.NullTime
+	err := row.Scan(&token.ID, &token.FamilyID, &token.UserID, &token.TokenHash, &token.AuthMethod, &token.CreatedAt, &token.ExpiresAt, &usedAt, &revokedAt)
+	if err != nil {
+		return nil, err
+	}
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	return &token, nil
+}
@@ -0,0 +1,271 @@
+package refreshtoken
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"os"
+	"server/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func setupTestDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	return db, mock
+}
+
+var tokenColumnNames = []string{"id", "family_id", "user_id", "token_hash", "auth_method", "created_at", "expires_at", "used_at", "revoked_at"}
+
+func TestRepository_CreateRefreshToken(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectExec("INSERT INTO refresh_token").
+		WithArgs("family", int64(1), "hash", domain.AuthMethodPassword, now, now.Add(time.Hour)).
+		WillReturnResult(sqlmock.NewResult(7, 1))
+
+	repo := NewRepository(logger, db)
+	token := &domain.RefreshToken{
+		FamilyID:   "family",
+		UserID:     1,
+		TokenHash:  "hash",
+		AuthMethod: domain.AuthMethodPassword,
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(time.Hour),
+	}
+	if err := repo.CreateRefreshToken(context.Background(), token); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token.ID != 7 {
+		t.Errorf("expected id 7, got %d", token.ID)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_GetRefreshTokenByHash(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		setupMock     func(sqlmock.Sqlmock)
+		expectUsed    bool
+		expectedError error
+	}{
+		{
+			name: "unused",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("SELECT (.+) FROM refresh_token t\\s+JOIN user u (.+) AND u.deletion_scheduled_at IS NULL").
+					WithArgs("hash").
+					WillReturnRows(sqlmock.NewRows(tokenColumnNames).
+						AddRow(1, "family", 1, "hash", "password", now, now.Add(time.Hour), nil, nil))
+			},
+		},
+		{
+			name: "used",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("SELECT (.+) FROM refresh_token t").
+					WithArgs("hash").
+					WillReturnRows(sqlmock.NewRows(tokenColumnNames).
+						AddRow(1, "family", 1, "hash", "password", now, now.Add(time.Hour), now, nil))
+			},
+			expectUsed: true,
+		},
+		{
+			name: "unknown or account being deleted",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("SELECT (.+) FROM refresh_token t").
+					WithArgs("hash").
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: domain.ErrRefreshTokenNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			tt.setupMock(mock)
+
+			repo := NewRepository(logger, db)
+			token, err := repo.GetRefreshTokenByHash(context.Background(), "hash")
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if tt.expectedError == nil {
+				if token == nil || token.FamilyID != "family" {
+					t.Fatalf("unexpected token %+v", token)
+				}
+				if (token.UsedAt != nil) != tt.expectUsed {
+					t.Errorf("expected used %v, got used at %v", tt.expectUsed, token.UsedAt)
+				}
+				if token.RevokedAt != nil {
+					t.Error("expected the token not to be revoked")
+				}
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestRepository_ListUserRefreshTokens(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT (.+) FROM refresh_token t WHERE t.user_id = \\? ORDER BY t.id DESC").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows(tokenColumnNames).
+			AddRow(2, "family", 1, "hash-2", "password", now, now.Add(time.Hour), nil, nil).
+			AddRow(1, "family", 1, "hash-1", "password", now, now.Add(time.Hour), now, now))
+
+	repo := NewRepository(logger, db)
+	tokens, err := repo.ListUserRefreshTokens(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tokens) != 2 || tokens[0].ID != 2 || tokens[0].UsedAt != nil || tokens[1].RevokedAt == nil {
+		t.Errorf("unexpected tokens %+v", tokens)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_UseRefreshToken(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		rowsAffected int64
+		expectUsed   bool
+	}{
+		{name: "first use", rowsAffected: 1, expectUsed: true},
+		{name: "already used or revoked", rowsAffected: 0, expectUsed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			mock.ExpectExec("UPDATE refresh_token SET used_at = \\? WHERE id = \\? AND used_at IS NULL AND revoked_at IS NULL").
+				WithArgs(now, int64(3)).
+				WillReturnResult(sqlmock.NewResult(0, tt.rowsAffected))
+
+			repo := NewRepository(logger, db)
+			used, err := repo.UseRefreshToken(context.Background(), 3, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if used != tt.expectUsed {
+				t.Errorf("expected used %v, got %v", tt.expectUsed, used)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestRepository_RevokeRefreshTokenFamily(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE refresh_token SET revoked_at = \\? WHERE family_id = \\? AND revoked_at IS NULL").
+		WithArgs(now, "family").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	repo := NewRepository(logger, db)
+	if err := repo.RevokeRefreshTokenFamily(context.Background(), "family", now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_RevokeUserRefreshTokens(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		execErr       error
+		expectedError bool
+	}{
+		{name: "revoked"},
+		{name: "database error", execErr: errors.New("connection lost"), expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			exec := mock.ExpectExec("UPDATE refresh_token SET revoked_at = \\? WHERE user_id = \\? AND revoked_at IS NULL").
+				WithArgs(now, int64(1))
+			if tt.execErr != nil {
+				exec.WillReturnError(tt.execErr)
+			} else {
+				exec.WillReturnResult(sqlmock.NewResult(0, 2))
+			}
+
+			repo := NewRepository(logger, db)
+			err := repo.RevokeUserRefreshTokens(context.Background(), 1, now)
+			if (err != nil) != tt.expectedError {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestRepository_DeleteExpiredRefreshTokens(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectExec("DELETE FROM refresh_token WHERE expires_at <= \\?").
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+
+	repo := NewRepository(logger, db)
+	deleted, err := repo.DeleteExpiredRefreshTokens(context.Background(), now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deleted != 4 {
+		t.Errorf("expected 4 deleted, got %d", deleted)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
@@ -26,6 +26,22 @@ func NewRepository() *Repository {
 	return r
 }
 
+// Count returns the number of stored sessions, including expired ones that
+// have not been cleaned up yet.
+func (r *Repository) Count() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.sessions)
+}
+
+// Ping reports whether the store can be read. Sessions live in memory, so
+// this only hangs if the store's lock is stuck; the caller bounds the wait.
+func (r *Repository) Ping(ctx context.Context) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return ctx.Err()
+}
+
 func (r *Repository) clearExpiredSessions() {
 	ticker := time.NewTicker(sessionCleanupInterval)
 	defer ticker.Stop()
@@ -88,3 +104,43 @@ func (r *Repository) DeleteSession(_ context.Context, token string) error {
 	delete(r.sessions, token)
 	return nil
 }
+
+func (r *Repository) DeleteUserSessions(_ context.Context, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for token, session := range r.sessions {
+		if session.UserID == userID {
+			delete(r.sessions, token)
+		}
+	}
+	return nil
+}
+
+func (r *Repository) GetUserSessions(_ context.Context, userID int64) ([]*domain.Session, error) {
+	now := time.Now()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	sessions := make([]*domain.Session, 0)
+	for _, session := range r.sessions {
+		if session.UserID == userID && !now.After(session.ExpiresAt) {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+// SetSessionOrganization selects the current organization of the session.
+// The stored session is replaced rather than modified, as callers may hold
+// the previous one.
+func (r *Repository) SetSessionOrganization(_ context.Context, token string, organizationID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[token]
+	if !ok {
+		return domain.ErrSessionNotFound
+	}
+	updated := *session
+	updated.OrganizationID = organizationID
+	r.sessions[token] = &updated
+	return nil
+}
@@ -2,6 +2,7 @@ package session
 
 import (
 	"context"
+	"errors"
 	"server/internal/domain"
 	"testing"
 	"time"
@@ -190,3 +191,121 @@ func TestRepository_ConcurrentAccess(t *testing.T) {
 		t.Errorf("expected UserID 2, got %d", retrieved2.UserID)
 	}
 }
+
+func TestRepository_DeleteUserSessions(t *testing.T) {
+	repo := NewRepository()
+	ctx := context.Background()
+
+	sessions := []*domain.Session{
+		{Token: "user1_a", UserID: 1, ExpiresAt: time.Now().Add(24 * time.Hour)},
+		{Token: "user1_b", UserID: 1, ExpiresAt: time.Now().Add(24 * time.Hour)},
+		{Token: "user2_a", UserID: 2, ExpiresAt: time.Now().Add(24 * time.Hour)},
+	}
+	for _, session := range sessions {
+		if err := repo.StoreSession(ctx, session); err != nil {
+			t.Fatalf("unexpected error storing session: %v", err)
+		}
+	}
+
+	if err := repo.DeleteUserSessions(ctx, 1); err != nil {
+		t.Errorf("unexpected error deleting sessions: %v", err)
+	}
+
+	for _, token := range []string{"user1_a", "user1_b"} {
+		if _, err := repo.GetSessionByToken(ctx, token); err != domain.ErrSessionNotFound {
+			t.Errorf("expected session %s to be deleted, got %v", token, err)
+		}
+	}
+	if _, err := repo.GetSessionByToken(ctx, "user2_a"); err != nil {
+		t.Errorf("expected session of other user to survive, got %v", err)
+	}
+}
+
+func TestRepository_GetUserSessions(t *testing.T) {
+	repo := NewRepository()
+	ctx := context.Background()
+
+	sessions := []*domain.Session{
+		{Token: "user1_a", UserID: 1, ExpiresAt: time.Now().Add(24 * time.Hour)},
+		{Token: "user1_expired", UserID: 1, ExpiresAt: time.Now().Add(-time.Hour)},
+		{Token: "user2_a", UserID: 2, ExpiresAt: time.Now().Add(24 * time.Hour)},
+	}
+	for _, session := range sessions {
+		if err := repo.StoreSession(ctx, session); err != nil {
+			t.Fatalf("unexpected error storing session: %v", err)
+		}
+	}
+
+	got, err := repo.GetUserSessions(ctx, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0].Token != "user1_a" {
+		t.Errorf("expected only the live session of user 1, got %v", got)
+	}
+}
+
+func TestRepository_SetSessionOrganization(t *testing.T) {
+	repo := NewRepository()
+	ctx := context.Background()
+
+	session := &domain.Session{Token: "token", UserID: 1, ExpiresAt: time.Now().Add(24 * time.Hour)}
+	if err := repo.StoreSession(ctx, session); err != nil {
+		t.Fatalf("unexpected error storing session: %v", err)
+	}
+
+	if err := repo.SetSessionOrganization(ctx, "token", 7); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	retrieved, err := repo.GetSessionByToken(ctx, "token")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if retrieved.OrganizationID != 7 {
+		t.Errorf("expected organization 7, got %d", retrieved.OrganizationID)
+	}
+	if session.OrganizationID != 0 {
+		t.Errorf("expected the previously returned session to be left alone, got %d", session.OrganizationID)
+	}
+
+	if err := repo.SetSessionOrganization(ctx, "missing", 7); err != domain.ErrSessionNotFound {
+		t.Errorf("expected ErrSessionNotFound, got %v", err)
+	}
+}
+
+func TestRepository_Count(t *testing.T) {
+	repo := NewRepository()
+	ctx := context.Background()
+
+	if got := repo.Count(); got != 0 {
+		t.Errorf("expected 0 sessions, got %d", got)
+	}
+
+	for _, token := range []string{"first", "second"} {
+		if err := repo.StoreSession(ctx, &domain.Session{Token: token, UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := repo.DeleteSession(ctx, "first"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := repo.Count(); got != 1 {
+		t.Errorf("expected 1 session, got %d", got)
+	}
+}
+
+func TestRepository_Ping(t *testing.T) {
+	repo := NewRepository()
+
+	if err := repo.Ping(context.Background()); err != nil {
+		t.Errorf("expected nil error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := repo.Ping(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"server/internal/domain"
+)
+
+// SetAvatar points the user at a new set of avatar thumbnails and returns the
+// previous avatar ID, empty if there was none, so its blobs can be removed.
+func (r *Repository) SetAvatar(ctx context.Context, userID int64, avatarID string) (string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to begin transaction", "error", err)
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				r.logger.ErrorContext(ctx, "failed to rollback transaction", "error", rollbackErr)
+			}
+		}
+	}()
+
+	var previous sql.NullString
+	err = tx.QueryRowContext(ctx, "SELECT avatar_id FROM user WHERE id = ? FOR UPDATE", userID).Scan(&previous)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", domain.ErrUserNotExists
+		}
+		r.logger.ErrorContext(ctx, "failed to get current avatar", "error", err)
+		return "", fmt.Errorf("failed to get current avatar: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE user SET avatar_id = ?, version = version + 1 WHERE id = ?", avatarID, userID)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to set avatar", "error", err)
+		return "", fmt.Errorf("failed to set avatar: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.ErrorContext(ctx, "failed to commit transaction", "error", err)
+		return "", fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	committed = true
+	return previous.String, nil
+}
@@ -18,7 +18,7 @@ func (r *Repository) CreateUserWithCredentials(ctx context.Context, credentials
 				return domain.ErrUserAlreadyExists
 			}
 		}
-		r.logger.Error("failed to create user with credentials", "error", err)
+		r.logger.ErrorContext(ctx, "failed to create user with credentials", "error", err)
 		return err
 	}
 	return nil
@@ -27,7 +27,7 @@ func (r *Repository) CreateUserWithCredentials(ctx context.Context, credentials
 func (r *Repository) CreateUserWithOAuthInfo(ctx context.Context, oauthInfo *domain.OAuthUserInfo) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		r.logger.Error("failed to begin transaction", "error", err)
+		r.logger.ErrorContext(ctx, "failed to begin transaction", "error", err)
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 
@@ -35,7 +35,7 @@ func (r *Repository) CreateUserWithOAuthInfo(ctx context.Context, oauthInfo *dom
 	defer func() {
 		if !committed {
 			if rollbackErr := tx.Rollback(); rollbackErr != nil {
-				r.logger.Error("failed to rollback transaction", "error", rollbackErr)
+				r.logger.ErrorContext(ctx, "failed to rollback transaction", "error", rollbackErr)
 			}
 		}
 	}()
@@ -52,13 +52,13 @@ func (r *Repository) CreateUserWithOAuthInfo(ctx context.Context, oauthInfo *dom
 				return domain.ErrUserAlreadyExists
 			}
 		}
-		r.logger.Error("failed to create user with oauth info", "error", err)
+		r.logger.ErrorContext(ctx, "failed to create user with oauth info", "error", err)
 		return fmt.Errorf("failed to create user with oauth info: %w", err)
 	}
 
 	userID, err := result.LastInsertId()
 	if err != nil {
-		r.logger.Error("failed to get last insert id", "error", err)
+		r.logger.ErrorContext(ctx, "failed to get last insert id", "error", err)
 		return fmt.Errorf("failed to get last insert id: %w", err)
 	}
 
@@ -68,12 +68,12 @@ func (r *Repository) CreateUserWithOAuthInfo(ctx context.Context, oauthInfo *dom
 		userID, oauthInfo.ProviderName, oauthInfo.Sub,
 	)
 	if err != nil {
-		r.logger.Error("failed to create oauth account", "error", err)
+		r.logger.ErrorContext(ctx, "failed to create oauth account", "error", err)
 		return fmt.Errorf("failed to create oauth account: %w", err)
 	}
 
 	if err = tx.Commit(); err != nil {
-		r.logger.Error("failed to commit transaction", "error", err)
+		r.logger.ErrorContext(ctx, "failed to commit transaction", "error", err)
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 	committed = true
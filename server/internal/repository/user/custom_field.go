@@ -0,0 +1,71 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// GetCustomFieldValues returns the user's custom field values by field key.
+func (r *Repository) GetCustomFieldValues(ctx context.Context, userID int64) (map[string]string, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT f.field_key, v.value
+		FROM profile_field_value v
+		JOIN profile_field f ON f.id = v.field_id
+		WHERE v.user_id = ?`,
+		userID,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get custom field values", "error", err)
+		return nil, fmt.Errorf("failed to get custom field values: %w", err)
+	}
+	defer rows.Close()
+
+	values := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			r.logger.ErrorContext(ctx, "failed to scan custom field value", "error", err)
+			return nil, fmt.Errorf("failed to scan custom field value: %w", err)
+		}
+		values[key] = value
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.ErrorContext(ctx, "failed to iterate custom field values", "error", err)
+		return nil, fmt.Errorf("failed to iterate custom field values: %w", err)
+	}
+	return values, nil
+}
+
+// writeCustomFieldValues stores the values by field key; an empty value
+// removes the stored one. Keys are written in order so concurrent updates
+// lock rows in the same sequence.
+func (r *Repository) writeCustomFieldValues(ctx context.Context, tx *sql.Tx, userID int64, values map[string]string) error {
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		value := values[key]
+		var err error
+		if value == "" {
+			_, err = tx.ExecContext(
+				ctx,
+				"DELETE FROM profile_field_value WHERE user_id = ? AND field_id = (SELECT id FROM profile_field WHERE field_key = ?)",
+				userID, key,
+			)
+		} else {
+			_, err = tx.ExecContext(
+				ctx,
+				`INSERT INTO profile_field_value (user_id, field_id, value)
+				SELECT ?, id, ? FROM profile_field WHERE field_key = ?
+				ON DUPLICATE KEY UPDATE value = VALUES(value)`,
+				userID, value, key,
+			)
+		}
+		if err != nil {
+			r.logger.ErrorContext(ctx, "failed to write custom field value", "key", key, "error", err)
+			return fmt.Errorf("failed to write custom field value: %w", err)
+		}
+	}
+	return nil
+}
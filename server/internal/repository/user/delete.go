@@ -0,0 +1,122 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"server/internal/domain"
+	"strings"
+	"time"
+)
+
+func (r *Repository) ScheduleAccountDeletion(ctx context.Context, userID int64, deleteAt time.Time) error {
+	result, err := r.db.ExecContext(ctx, "UPDATE user SET deletion_scheduled_at = ? WHERE id = ?", deleteAt, userID)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to schedule account deletion", "error", err)
+		return fmt.Errorf("failed to schedule account deletion: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get rows affected", "error", err)
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if affected == 0 {
+		return domain.ErrUserNotExists
+	}
+	return nil
+}
+
+// CancelAccountDeletion clears a pending deletion and reports whether there was one.
+func (r *Repository) CancelAccountDeletion(ctx context.Context, userID int64) (bool, error) {
+	result, err := r.db.ExecContext(
+		ctx,
+		"UPDATE user SET deletion_scheduled_at = NULL WHERE id = ? AND deletion_scheduled_at IS NOT NULL",
+		userID,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to cancel account deletion", "error", err)
+		return false, fmt.Errorf("failed to cancel account deletion: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get rows affected", "error", err)
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// DeleteUsersScheduledBefore hard-deletes accounts whose grace period has
+// ended and returns how many went with the avatar IDs they held, so their
+// blobs can be removed. The rows are locked while they are read, so a login
+// that cancels a deletion either comes first and keeps the account out of
+// both, or waits until it is gone. Linked oauth_account rows go with them
+// through the foreign key.
+func (r *Repository) DeleteUsersScheduledBefore(ctx context.Context, now time.Time) (int64, []string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to begin transaction", "error", err)
+		return 0, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				r.logger.ErrorContext(ctx, "failed to rollback transaction", "error", rollbackErr)
+			}
+		}
+	}()
+
+	rows, err := tx.QueryContext(
+		ctx,
+		"SELECT id, avatar_id FROM user WHERE deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ? FOR UPDATE",
+		now,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get scheduled users", "error", err)
+		return 0, nil, fmt.Errorf("failed to get scheduled users: %w", err)
+	}
+	var userIDs []any
+	var avatarIDs []string
+	for rows.Next() {
+		var userID int64
+		var avatarID sql.NullString
+		if err := rows.Scan(&userID, &avatarID); err != nil {
+			rows.Close()
+			r.logger.ErrorContext(ctx, "failed to scan scheduled user", "error", err)
+			return 0, nil, fmt.Errorf("failed to scan scheduled user: %w", err)
+		}
+		userIDs = append(userIDs, userID)
+		if avatarID.Valid {
+			avatarIDs = append(avatarIDs, avatarID.String)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		r.logger.ErrorContext(ctx, "failed to iterate scheduled users", "error", err)
+		return 0, nil, fmt.Errorf("failed to iterate scheduled users: %w", err)
+	}
+	rows.Close()
+	if len(userIDs) == 0 {
+		return 0, nil, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(userIDs)), ",")
+	result, err := tx.ExecContext(ctx, "DELETE FROM user WHERE id IN ("+placeholders+")", userIDs...)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to delete scheduled users", "error", err)
+		return 0, nil, fmt.Errorf("failed to delete scheduled users: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get rows affected", "error", err)
+		return 0, nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.ErrorContext(ctx, "failed to commit transaction", "error", err)
+		return 0, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	committed = true
+	return affected, avatarIDs, nil
+}
@@ -0,0 +1,162 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"server/internal/domain"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+const selectEmailChangeColumns = `SELECT id, user_id, old_email, new_email, confirm_token_hash, cancel_token_hash, created_at, expires_at
+	FROM email_change_request`
+
+// CreateEmailChangeRequest replaces any pending request of the user, so only
+// the latest confirmation link stays valid.
+func (r *Repository) CreateEmailChangeRequest(ctx context.Context, request *domain.EmailChangeRequest) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				r.logger.ErrorContext(ctx, "failed to rollback transaction", "error", rollbackErr)
+			}
+		}
+	}()
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM email_change_request WHERE user_id = ?", request.UserID)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to delete previous email change request", "error", err)
+		return fmt.Errorf("failed to delete previous email change request: %w", err)
+	}
+
+	result, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO email_change_request (user_id, old_email, new_email, confirm_token_hash, cancel_token_hash, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		request.UserID, request.OldEmail, request.NewEmail, request.ConfirmTokenHash, request.CancelTokenHash,
+		request.CreatedAt, request.ExpiresAt,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to create email change request", "error", err)
+		return fmt.Errorf("failed to create email change request: %w", err)
+	}
+
+	request.ID, err = result.LastInsertId()
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get last insert id", "error", err)
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.ErrorContext(ctx, "failed to commit transaction", "error", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	committed = true
+	return nil
+}
+
+func (r *Repository) GetEmailChangeRequestByUserID(ctx context.Context, userID int64) (*domain.EmailChangeRequest, error) {
+	row := r.db.QueryRowContext(ctx, selectEmailChangeColumns+" WHERE user_id = ?", userID)
+	return r.scanEmailChangeRequest(ctx, row)
+}
+
+func (r *Repository) GetEmailChangeRequestByConfirmToken(ctx context.Context, tokenHash string) (*domain.EmailChangeRequest, error) {
+	row := r.db.QueryRowContext(ctx, selectEmailChangeColumns+" WHERE confirm_token_hash = ?", tokenHash)
+	return r.scanEmailChangeRequest(ctx, row)
+}
+
+func (r *Repository) GetEmailChangeRequestByCancelToken(ctx context.Context, tokenHash string) (*domain.EmailChangeRequest, error) {
+	row := r.db.QueryRowContext(ctx, selectEmailChangeColumns+" WHERE cancel_token_hash = ?", tokenHash)
+	return r.scanEmailChangeRequest(ctx, row)
+}
+
+func (r *Repository) scanEmailChangeRequest(ctx context.Context, row *sql.Row) (*domain.EmailChangeRequest, error) {
+	var request domain.EmailChangeRequest
+	err := row.Scan(
+		&request.ID, &request.UserID, &request.OldEmail, &request.NewEmail,
+		&request.ConfirmTokenHash, &request.CancelTokenHash, &request.CreatedAt, &request.ExpiresAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrEmailChangeNotFound
+		}
+		r.logger.ErrorContext(ctx, "failed to get email change request", "error", err)
+		return nil, fmt.Errorf("failed to get email change request: %w", err)
+	}
+	return &request, nil
+}
+
+// ApplyEmailChange swaps the email and consumes the request in one
+// transaction. The unique key on user.email is the final uniqueness check:
+// an address taken since the request was made yields ErrUserAlreadyExists.
+func (r *Repository) ApplyEmailChange(ctx context.Context, request *domain.EmailChangeRequest) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				r.logger.ErrorContext(ctx, "failed to rollback transaction", "error", rollbackErr)
+			}
+		}
+	}()
+
+	result, err := tx.ExecContext(
+		ctx,
+		"UPDATE user SET email = ?, version = version + 1 WHERE id = ? AND email = ?",
+		request.NewEmail, request.UserID, request.OldEmail,
+	)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) {
+			if mysqlErr.Number == ErrDuplicateEntry {
+				return domain.ErrUserAlreadyExists
+			}
+		}
+		r.logger.ErrorContext(ctx, "failed to update user email", "error", err)
+		return fmt.Errorf("failed to update user email: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get rows affected", "error", err)
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if affected == 0 {
+		return domain.ErrEmailChangeNotFound
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM email_change_request WHERE id = ?", request.ID)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to delete email change request", "error", err)
+		return fmt.Errorf("failed to delete email change request: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.ErrorContext(ctx, "failed to commit transaction", "error", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	committed = true
+	return nil
+}
+
+func (r *Repository) DeleteEmailChangeRequest(ctx context.Context, requestID int64) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM email_change_request WHERE id = ?", requestID)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to delete email change request", "error", err)
+		return fmt.Errorf("failed to delete email change request: %w", err)
+	}
+	return nil
+}
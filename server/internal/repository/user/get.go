@@ -22,7 +22,7 @@ func (r *Repository) GetUserByEmail(ctx context.Context, email string) (*domain.
 		if err == sql.ErrNoRows {
 			return nil, domain.ErrUserNotExists
 		}
-		r.logger.Error("failed to get user by email", "error", err)
+		r.logger.ErrorContext(ctx, "failed to get user by email", "error", err)
 		return nil, fmt.Errorf("failed to get user by email: %w", err)
 	}
 
@@ -46,7 +46,7 @@ func (r *Repository) GetUserByID(ctx context.Context, userID int64) (*domain.Use
 		if err == sql.ErrNoRows {
 			return nil, domain.ErrUserNotExists
 		}
-		r.logger.Error("failed to get user by id", "error", err)
+		r.logger.ErrorContext(ctx, "failed to get user by id", "error", err)
 		return nil, fmt.Errorf("failed to get user by id: %w", err)
 	}
 
@@ -57,17 +57,80 @@ func (r *Repository) GetUserByID(ctx context.Context, userID int64) (*domain.Use
 	return &user, nil
 }
 
+const selectProfileColumns = `SELECT id, email, full_name, phone, phone_verified_at, avatar_id, version,
+	handle, public_profile, full_name_visibility, email_visibility, phone_visibility, avatar_visibility FROM user`
+
 func (r *Repository) GetProfileByUserID(ctx context.Context, userID int64) (*domain.Profile, error) {
-	user, err := r.GetUserByID(ctx, userID)
+	profile, err := scanProfile(r.db.QueryRowContext(ctx, selectProfileColumns+" WHERE id = ?", userID))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user by id: %w", err)
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrUserNotExists
+		}
+		r.logger.ErrorContext(ctx, "failed to get profile by user id", "error", err)
+		return nil, fmt.Errorf("failed to get profile by user id: %w", err)
+	}
+	return profile, nil
+}
+
+// GetActiveProfileByUserID is GetProfileByUserID for what others see of the
+// user: like GetProfileByHandle, it skips accounts scheduled for deletion.
+func (r *Repository) GetActiveProfileByUserID(ctx context.Context, userID int64) (*domain.Profile, error) {
+	profile, err := scanProfile(r.db.QueryRowContext(ctx, selectProfileColumns+" WHERE id = ? AND deletion_scheduled_at IS NULL", userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrUserNotExists
+		}
+		r.logger.ErrorContext(ctx, "failed to get active profile by user id", "error", err)
+		return nil, fmt.Errorf("failed to get active profile by user id: %w", err)
+	}
+	return profile, nil
+}
+
+// GetProfileByHandle looks the profile up by its public handle, which is
+// stored in lower case. Like the directory search, it skips accounts
+// scheduled for deletion.
+func (r *Repository) GetProfileByHandle(ctx context.Context, handle string) (*domain.Profile, error) {
+	profile, err := scanProfile(r.db.QueryRowContext(ctx, selectProfileColumns+" WHERE handle = ? AND deletion_scheduled_at IS NULL", handle))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrUserNotExists
+		}
+		r.logger.ErrorContext(ctx, "failed to get profile by handle", "error", err)
+		return nil, fmt.Errorf("failed to get profile by handle: %w", err)
+	}
+	return profile, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanProfile(row scanner) (*domain.Profile, error) {
+	var profile domain.Profile
+	var fullName, phone, avatarID, handle sql.NullString
+	var phoneVerifiedAt sql.NullTime
+	var fullNameVisibility, emailVisibility, phoneVisibility, avatarVisibility string
+	err := row.Scan(&profile.UserID, &profile.Email, &fullName, &phone, &phoneVerifiedAt, &avatarID, &profile.Version,
+		&handle, &profile.Public, &fullNameVisibility, &emailVisibility, &phoneVisibility, &avatarVisibility)
+	if err != nil {
+		return nil, err
+	}
+
+	profile.FullName = fullName.String
+	profile.Phone = phone.String
+	if phoneVerifiedAt.Valid {
+		profile.PhoneVerifiedAt = &phoneVerifiedAt.Time
+	}
+	profile.AvatarID = avatarID.String
+	profile.Handle = handle.String
+	profile.Privacy = domain.ProfilePrivacy{
+		FullName: domain.Visibility(fullNameVisibility),
+		Email:    domain.Visibility(emailVisibility),
+		Phone:    domain.Visibility(phoneVisibility),
+		Avatar:   domain.Visibility(avatarVisibility),
 	}
-	return &domain.Profile{
-		UserID:   user.ID,
-		Email:    user.Email,
-		FullName: user.FullName,
-		Phone:    user.Phone,
-	}, nil
+
+	return &profile, nil
 }
 
 func (r *Repository) GetUserByOAuthInfo(ctx context.Context, oauthInfo *domain.OAuthUserInfo) (*domain.User, error) {
@@ -86,7 +149,7 @@ func (r *Repository) GetUserByOAuthInfo(ctx context.Context, oauthInfo *domain.O
 		if err == sql.ErrNoRows {
 			return nil, domain.ErrUserNotExists
 		}
-		r.logger.Error("failed to get user by oauth info", "error", err)
+		r.logger.ErrorContext(ctx, "failed to get user by oauth info", "error", err)
 		return nil, fmt.Errorf("failed to get user by oauth info: %w", err)
 	}
 
@@ -95,3 +158,44 @@ func (r *Repository) GetUserByOAuthInfo(ctx context.Context, oauthInfo *domain.O
 
 	return &user, nil
 }
+
+func (r *Repository) IsUserAdmin(ctx context.Context, userID int64) (bool, error) {
+	var isAdmin bool
+	err := r.db.QueryRowContext(ctx, "SELECT is_admin FROM user WHERE id = ?", userID).Scan(&isAdmin)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, domain.ErrUserNotExists
+		}
+		r.logger.ErrorContext(ctx, "failed to check if user is admin", "error", err)
+		return false, fmt.Errorf("failed to check if user is admin: %w", err)
+	}
+	return isAdmin, nil
+}
+
+func (r *Repository) GetOAuthAccountsByUserID(ctx context.Context, userID int64) ([]*domain.OAuthAccount, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT provider_name, sub, created_at FROM oauth_account WHERE user_id = ? ORDER BY id",
+		userID,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get oauth accounts", "error", err)
+		return nil, fmt.Errorf("failed to get oauth accounts: %w", err)
+	}
+	defer rows.Close()
+
+	accounts := make([]*domain.OAuthAccount, 0)
+	for rows.Next() {
+		var account domain.OAuthAccount
+		if err := rows.Scan(&account.ProviderName, &account.Sub, &account.CreatedAt); err != nil {
+			r.logger.ErrorContext(ctx, "failed to scan oauth account", "error", err)
+			return nil, fmt.Errorf("failed to scan oauth account: %w", err)
+		}
+		accounts = append(accounts, &account)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.ErrorContext(ctx, "failed to iterate oauth accounts", "error", err)
+		return nil, fmt.Errorf("failed to iterate oauth accounts: %w", err)
+	}
+	return accounts, nil
+}
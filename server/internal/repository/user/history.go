@@ -0,0 +1,90 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"server/internal/domain"
+	"strings"
+)
+
+const (
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 500
+)
+
+// ListProfileChanges returns the user's profile history, newest first.
+// beforeID pages through older rows; zero starts from the newest.
+func (r *Repository) ListProfileChanges(ctx context.Context, userID, beforeID int64, limit int) ([]*domain.ProfileChange, error) {
+	query := "SELECT id, user_id, field, old_value, new_value, session_id, created_at FROM profile_change WHERE user_id = ?"
+	args := []interface{}{userID}
+	if beforeID > 0 {
+		query += " AND id < ?"
+		args = append(args, beforeID)
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, clampHistoryLimit(limit))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to list profile changes", "error", err)
+		return nil, fmt.Errorf("failed to list profile changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := make([]*domain.ProfileChange, 0)
+	for rows.Next() {
+		var change domain.ProfileChange
+		var oldValue, newValue, sessionID sql.NullString
+		err := rows.Scan(&change.ID, &change.UserID, &change.Field, &oldValue, &newValue, &sessionID, &change.CreatedAt)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "failed to scan profile change", "error", err)
+			return nil, fmt.Errorf("failed to scan profile change: %w", err)
+		}
+		change.OldValue = oldValue.String
+		change.NewValue = newValue.String
+		change.SessionID = sessionID.String
+		changes = append(changes, &change)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.ErrorContext(ctx, "failed to iterate profile changes", "error", err)
+		return nil, fmt.Errorf("failed to iterate profile changes: %w", err)
+	}
+	return changes, nil
+}
+
+// insertProfileChanges appends history rows as part of a profile update.
+func (r *Repository) insertProfileChanges(ctx context.Context, tx *sql.Tx, changes []*domain.ProfileChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, 0, len(changes))
+	args := make([]interface{}, 0, len(changes)*6)
+	for _, change := range changes {
+		placeholders = append(placeholders, "(?, ?, ?, ?, ?, ?)")
+		args = append(args, change.UserID, change.Field, nullIfEmpty(change.OldValue), nullIfEmpty(change.NewValue),
+			nullIfEmpty(change.SessionID), change.CreatedAt)
+	}
+
+	_, err := tx.ExecContext(
+		ctx,
+		"INSERT INTO profile_change (user_id, field, old_value, new_value, session_id, created_at) VALUES "+strings.Join(placeholders, ", "),
+		args...,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to insert profile changes", "error", err)
+		return fmt.Errorf("failed to insert profile changes: %w", err)
+	}
+	return nil
+}
+
+func clampHistoryLimit(limit int) int {
+	if limit <= 0 {
+		return defaultHistoryLimit
+	}
+	if limit > maxHistoryLimit {
+		return maxHistoryLimit
+	}
+	return limit
+}
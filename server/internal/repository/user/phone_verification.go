@@ -0,0 +1,119 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"server/internal/domain"
+	"time"
+)
+
+// SavePhoneVerification stores a fresh code for the user, replacing the
+// previous one and resetting the attempt counter.
+func (r *Repository) SavePhoneVerification(ctx context.Context, verification *domain.PhoneVerification) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO phone_verification (user_id, phone, code_hash, attempts, created_at, expires_at)
+		VALUES (?, ?, ?, 0, ?, ?)
+		ON DUPLICATE KEY UPDATE phone = VALUES(phone), code_hash = VALUES(code_hash), attempts = 0,
+			created_at = VALUES(created_at), expires_at = VALUES(expires_at)`,
+		verification.UserID, verification.Phone, verification.CodeHash, verification.CreatedAt, verification.ExpiresAt,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to save phone verification", "error", err)
+		return fmt.Errorf("failed to save phone verification: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) GetPhoneVerification(ctx context.Context, userID int64) (*domain.PhoneVerification, error) {
+	var verification domain.PhoneVerification
+	row := r.db.QueryRowContext(
+		ctx,
+		"SELECT user_id, phone, code_hash, attempts, created_at, expires_at FROM phone_verification WHERE user_id = ?",
+		userID,
+	)
+	err := row.Scan(
+		&verification.UserID, &verification.Phone, &verification.CodeHash,
+		&verification.Attempts, &verification.CreatedAt, &verification.ExpiresAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrPhoneVerificationNotFound
+		}
+		r.logger.ErrorContext(ctx, "failed to get phone verification", "error", err)
+		return nil, fmt.Errorf("failed to get phone verification: %w", err)
+	}
+	return &verification, nil
+}
+
+// UsePhoneVerificationAttempt counts a guess at the code before it is
+// checked. The limit is part of the update so concurrent guesses cannot all
+// pass it; domain.ErrTooManyPhoneCodeAttempts means no attempts are left.
+func (r *Repository) UsePhoneVerificationAttempt(ctx context.Context, userID int64, maxAttempts int) error {
+	result, err := r.db.ExecContext(
+		ctx,
+		"UPDATE phone_verification SET attempts = attempts + 1 WHERE user_id = ? AND attempts < ?",
+		userID, maxAttempts,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to count phone verification attempt", "error", err)
+		return fmt.Errorf("failed to count phone verification attempt: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get rows affected", "error", err)
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrTooManyPhoneCodeAttempts
+	}
+	return nil
+}
+
+// MarkPhoneVerified sets phone_verified_at only if the user still has the
+// phone the code was sent to, and consumes the code.
+func (r *Repository) MarkPhoneVerified(ctx context.Context, userID int64, phone string, verifiedAt time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				r.logger.ErrorContext(ctx, "failed to rollback transaction", "error", rollbackErr)
+			}
+		}
+	}()
+
+	result, err := tx.ExecContext(ctx, "UPDATE user SET phone_verified_at = ?, version = version + 1 WHERE id = ? AND phone = ?", verifiedAt, userID, phone)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to mark phone verified", "error", err)
+		return fmt.Errorf("failed to mark phone verified: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get rows affected", "error", err)
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if affected == 0 {
+		return domain.ErrPhoneVerificationNotFound
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM phone_verification WHERE user_id = ?", userID)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to delete phone verification", "error", err)
+		return fmt.Errorf("failed to delete phone verification: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.ErrorContext(ctx, "failed to commit transaction", "error", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	committed = true
+	return nil
+}
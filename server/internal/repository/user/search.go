@@ -0,0 +1,77 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"server/internal/domain"
+	"strings"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// SearchProfiles finds public profiles whose name or email contains every
+// term, for a signed-in searcher: a field is only matched when its
+// visibility lets signed-in users see it. Results are ordered by id;
+// afterID continues after the last profile of the previous page.
+func (r *Repository) SearchProfiles(ctx context.Context, terms []string, afterID int64, limit int) ([]*domain.Profile, error) {
+	match := booleanPhrases(terms)
+	rows, err := r.db.QueryContext(
+		ctx,
+		selectProfileColumns+` WHERE public_profile = TRUE AND deletion_scheduled_at IS NULL AND id > ? AND (
+			(full_name_visibility IN (?, ?) AND MATCH (full_name) AGAINST (? IN BOOLEAN MODE))
+			OR (email_visibility IN (?, ?) AND MATCH (email) AGAINST (? IN BOOLEAN MODE)))
+		ORDER BY id LIMIT ?`,
+		afterID,
+		domain.VisibilityPublic, domain.VisibilitySignedIn, match,
+		domain.VisibilityPublic, domain.VisibilitySignedIn, match,
+		clampSearchLimit(limit),
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to search profiles", "error", err)
+		return nil, fmt.Errorf("failed to search profiles: %w", err)
+	}
+	defer rows.Close()
+
+	profiles := make([]*domain.Profile, 0)
+	for rows.Next() {
+		profile, err := scanProfile(rows)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "failed to scan profile", "error", err)
+			return nil, fmt.Errorf("failed to scan profile: %w", err)
+		}
+		profiles = append(profiles, profile)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.ErrorContext(ctx, "failed to iterate profiles", "error", err)
+		return nil, fmt.Errorf("failed to iterate profiles: %w", err)
+	}
+	return profiles, nil
+}
+
+// booleanPhrases requires each term as a quoted phrase, which the ngram
+// parser matches anywhere in the value. Quotes are the only character with
+// a meaning inside a phrase, so they are dropped.
+func booleanPhrases(terms []string) string {
+	phrases := make([]string, 0, len(terms))
+	for _, term := range terms {
+		term = strings.ReplaceAll(term, `"`, "")
+		if term == "" {
+			continue
+		}
+		phrases = append(phrases, `+"`+term+`"`)
+	}
+	return strings.Join(phrases, " ")
+}
+
+func clampSearchLimit(limit int) int {
+	if limit <= 0 {
+		return defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		return maxSearchLimit
+	}
+	return limit
+}
@@ -3,38 +3,129 @@ package user
 import (
 	"context"
 	"errors"
+	"fmt"
 	"server/internal/domain"
+	"strings"
 
 	"github.com/go-sql-driver/mysql"
 )
 
-func (r *Repository) UpdateProfile(ctx context.Context, profile *domain.Profile) error {
-	var fullName, phone interface{}
-	if profile.FullName == "" {
-		fullName = nil
-	} else {
-		fullName = profile.FullName
+// UpdateProfile writes only the columns and custom field values set in the
+// patch, provided the row is still at the given version, and appends the
+// history rows in the same transaction; an empty patch is a no-op.
+func (r *Repository) UpdateProfile(ctx context.Context, userID, version int64, patch *domain.ProfilePatch, history []*domain.ProfileChange) error {
+	if patch.IsEmpty() {
+		return nil
 	}
-	if profile.Phone == "" {
-		phone = nil
-	} else {
-		phone = profile.Phone
+
+	columns := make([]string, 0, 11)
+	args := make([]interface{}, 0, 12)
+	if patch.Email != nil {
+		columns = append(columns, "email = ?")
+		args = append(args, *patch.Email)
+	}
+	if patch.FullName != nil {
+		columns = append(columns, "full_name = ?")
+		args = append(args, nullIfEmpty(*patch.FullName))
+	}
+	if patch.Phone != nil {
+		phone := nullIfEmpty(*patch.Phone)
+		// phone_verified_at is assigned first so it still sees the old phone:
+		// a changed number has to be verified again.
+		columns = append(columns, "phone_verified_at = IF(phone <=> ?, phone_verified_at, NULL)", "phone = ?")
+		args = append(args, phone, phone)
+	}
+	if patch.Handle != nil {
+		columns = append(columns, "handle = ?")
+		args = append(args, nullIfEmpty(*patch.Handle))
 	}
-	
-	_, err := r.db.ExecContext(
+	if patch.Public != nil {
+		columns = append(columns, "public_profile = ?")
+		args = append(args, *patch.Public)
+	}
+	for _, visibility := range []struct {
+		column string
+		value  domain.Visibility
+	}{
+		{"full_name_visibility", patch.Privacy.FullName},
+		{"email_visibility", patch.Privacy.Email},
+		{"phone_visibility", patch.Privacy.Phone},
+		{"avatar_visibility", patch.Privacy.Avatar},
+	} {
+		if visibility.value != "" {
+			columns = append(columns, visibility.column+" = ?")
+			args = append(args, string(visibility.value))
+		}
+	}
+	// The version is bumped even when only custom fields change, which also
+	// makes the row count tell a stale version apart.
+	columns = append(columns, "version = version + 1")
+	args = append(args, userID, version)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				r.logger.ErrorContext(ctx, "failed to rollback transaction", "error", rollbackErr)
+			}
+		}
+	}()
+
+	result, err := tx.ExecContext(
 		ctx,
-		"UPDATE user SET email = ?, full_name = ?, phone = ? WHERE id = ?",
-		profile.Email, fullName, phone, profile.UserID,
+		"UPDATE user SET "+strings.Join(columns, ", ")+" WHERE id = ? AND version = ?",
+		args...,
 	)
 	if err != nil {
 		var mysqlErr *mysql.MySQLError
 		if errors.As(err, &mysqlErr) {
 			if mysqlErr.Number == ErrDuplicateEntry {
+				// The message ends with the violated key, e.g.
+				// "Duplicate entry 'x' for key 'user.handle'".
+				if strings.HasSuffix(mysqlErr.Message, "'handle'") || strings.HasSuffix(mysqlErr.Message, ".handle'") {
+					return domain.ErrHandleTaken
+				}
 				return domain.ErrUserAlreadyExists
 			}
 		}
-		r.logger.Error("failed to update profile", "error", err)
+		r.logger.ErrorContext(ctx, "failed to update profile", "error", err)
 		return err
 	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.ErrorContext(ctx, "failed to get rows affected", "error", err)
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if affected == 0 {
+		return domain.ErrProfileVersionMismatch
+	}
+
+	if err = r.writeCustomFieldValues(ctx, tx, userID, patch.CustomFields); err != nil {
+		return err
+	}
+
+	if err = r.insertProfileChanges(ctx, tx, history); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.ErrorContext(ctx, "failed to commit transaction", "error", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	committed = true
 	return nil
 }
+
+func nullIfEmpty(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
@@ -3,10 +3,13 @@ package user
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"log/slog"
 	"os"
 	"server/internal/domain"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/go-sql-driver/mysql"
@@ -302,6 +305,9 @@ func TestRepository_GetUserByID(t *testing.T) {
 	}
 }
 
+var profileColumns = []string{"id", "email", "full_name", "phone", "phone_verified_at", "avatar_id", "version",
+	"handle", "public_profile", "full_name_visibility", "email_visibility", "phone_visibility", "avatar_visibility"}
+
 func TestRepository_GetProfileByUserID(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	ctx := context.Background()
@@ -317,9 +323,10 @@ func TestRepository_GetProfileByUserID(t *testing.T) {
 			name:   "successful get profile",
 			userID: 1,
 			setupMock: func(m sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "email", "password_hash", "full_name", "phone"}).
-					AddRow(1, "test@example.com", "hashed_password", "Test User", "1234567890")
-				m.ExpectQuery("SELECT id, email, password_hash, full_name, phone FROM user WHERE id").
+				rows := sqlmock.NewRows(profileColumns).
+					AddRow(1, "test@example.com", "Test User", "1234567890", nil, "avatar-1", 3,
+						"test_user", true, "public", "signed_in", "private", "public")
+				m.ExpectQuery("SELECT id, email, full_name, phone, phone_verified_at, avatar_id, version, handle, public_profile, .* FROM user WHERE id").
 					WithArgs(1).
 					WillReturnRows(rows)
 			},
@@ -329,13 +336,23 @@ func TestRepository_GetProfileByUserID(t *testing.T) {
 				Email:    "test@example.com",
 				FullName: "Test User",
 				Phone:    "1234567890",
+				AvatarID: "avatar-1",
+				Version:  3,
+				Handle:   "test_user",
+				Public:   true,
+				Privacy: domain.ProfilePrivacy{
+					FullName: domain.VisibilityPublic,
+					Email:    domain.VisibilitySignedIn,
+					Phone:    domain.VisibilityPrivate,
+					Avatar:   domain.VisibilityPublic,
+				},
 			},
 		},
 		{
 			name:   "user not found",
 			userID: 1,
 			setupMock: func(m sqlmock.Sqlmock) {
-				m.ExpectQuery("SELECT id, email, password_hash, full_name, phone FROM user WHERE id").
+				m.ExpectQuery("SELECT id, email, full_name, phone, phone_verified_at, avatar_id, version, handle, public_profile, .* FROM user WHERE id").
 					WithArgs(1).
 					WillReturnError(sql.ErrNoRows)
 			},
@@ -374,6 +391,18 @@ func TestRepository_GetProfileByUserID(t *testing.T) {
 					if profile.Email != tt.expectedProfile.Email {
 						t.Errorf("expected Email %s, got %s", tt.expectedProfile.Email, profile.Email)
 					}
+					if profile.AvatarID != tt.expectedProfile.AvatarID {
+						t.Errorf("expected AvatarID %s, got %s", tt.expectedProfile.AvatarID, profile.AvatarID)
+					}
+					if profile.Version != tt.expectedProfile.Version {
+						t.Errorf("expected Version %d, got %d", tt.expectedProfile.Version, profile.Version)
+					}
+					if profile.Handle != tt.expectedProfile.Handle || profile.Public != tt.expectedProfile.Public {
+						t.Errorf("expected handle %q public %v, got %q %v", tt.expectedProfile.Handle, tt.expectedProfile.Public, profile.Handle, profile.Public)
+					}
+					if profile.Privacy != tt.expectedProfile.Privacy {
+						t.Errorf("expected Privacy %+v, got %+v", tt.expectedProfile.Privacy, profile.Privacy)
+					}
 				}
 			}
 
@@ -478,58 +507,228 @@ func TestRepository_UpdateProfile(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	ctx := context.Background()
 
+	stringPtr := func(s string) *string { return &s }
+	boolPtr := func(b bool) *bool { return &b }
+	changedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
 	tests := []struct {
 		name          string
-		profile       *domain.Profile
+		userID        int64
+		version       int64
+		patch         *domain.ProfilePatch
+		history       []*domain.ProfileChange
 		setupMock     func(sqlmock.Sqlmock)
 		expectedError error
 	}{
 		{
-			name: "successful update",
-			profile: &domain.Profile{
-				UserID:   1,
-				Email:    "updated@example.com",
-				FullName: "Updated User",
-				Phone:    "9876543210",
+			name:    "successful update",
+			userID:  1,
+			version: 3,
+			patch: &domain.ProfilePatch{
+				Email:    stringPtr("updated@example.com"),
+				FullName: stringPtr("Updated User"),
+				Phone:    stringPtr("+14155550132"),
 			},
 			setupMock: func(m sqlmock.Sqlmock) {
-				m.ExpectExec("UPDATE user SET email = \\?, full_name = \\?, phone = \\? WHERE id = \\?").
-					WithArgs("updated@example.com", "Updated User", "9876543210", int64(1)).
+				m.ExpectBegin()
+				m.ExpectExec("UPDATE user SET email = \\?, full_name = \\?, phone_verified_at = IF\\(phone <=> \\?, phone_verified_at, NULL\\), phone = \\?, version = version \\+ 1 WHERE id = \\? AND version = \\?").
+					WithArgs("updated@example.com", "Updated User", "+14155550132", "+14155550132", int64(1), int64(3)).
 					WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
 			},
 			expectedError: nil,
 		},
 		{
-			name: "update with empty fields",
-			profile: &domain.Profile{
-				UserID:   1,
-				Email:    "updated@example.com",
-				FullName: "",
-				Phone:    "",
+			name:    "only changed columns are written",
+			userID:  1,
+			version: 3,
+			patch: &domain.ProfilePatch{
+				FullName: stringPtr("Updated User"),
 			},
 			setupMock: func(m sqlmock.Sqlmock) {
-				m.ExpectExec("UPDATE user SET email = \\?, full_name = \\?, phone = \\? WHERE id = \\?").
-					WithArgs("updated@example.com", nil, nil, int64(1)).
+				m.ExpectBegin()
+				m.ExpectExec("^UPDATE user SET full_name = \\?, version = version \\+ 1 WHERE id = \\? AND version = \\?$").
+					WithArgs("Updated User", int64(1), int64(3)).
 					WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
 			},
 			expectedError: nil,
 		},
 		{
-			name: "duplicate email",
-			profile: &domain.Profile{
-				UserID:   1,
-				Email:    "existing@example.com",
-				FullName: "Updated User",
-				Phone:    "9876543210",
+			name:    "empty values clear columns",
+			userID:  1,
+			version: 3,
+			patch: &domain.ProfilePatch{
+				FullName: stringPtr(""),
+				Phone:    stringPtr(""),
+			},
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec("UPDATE user SET full_name = \\?, phone_verified_at = IF\\(phone <=> \\?, phone_verified_at, NULL\\), phone = \\?, version = version \\+ 1 WHERE id = \\? AND version = \\?").
+					WithArgs(nil, nil, nil, int64(1), int64(3)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			},
+			expectedError: nil,
+		},
+		{
+			name:    "stale version",
+			userID:  1,
+			version: 2,
+			patch: &domain.ProfilePatch{
+				FullName: stringPtr("Updated User"),
+			},
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec("UPDATE user SET full_name = \\?, version = version \\+ 1 WHERE id = \\? AND version = \\?").
+					WithArgs("Updated User", int64(1), int64(2)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				m.ExpectRollback()
+			},
+			expectedError: domain.ErrProfileVersionMismatch,
+		},
+		{
+			name:    "custom field values are upserted and cleared",
+			userID:  1,
+			version: 3,
+			patch: &domain.ProfilePatch{
+				CustomFields: map[string]string{"department": "Research", "bio": ""},
+			},
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec("^UPDATE user SET version = version \\+ 1 WHERE id = \\? AND version = \\?$").
+					WithArgs(int64(1), int64(3)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectExec("DELETE FROM profile_field_value WHERE user_id = \\? AND field_id = \\(SELECT id FROM profile_field WHERE field_key = \\?\\)").
+					WithArgs(int64(1), "bio").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectExec("INSERT INTO profile_field_value").
+					WithArgs(int64(1), "Research", "department").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			},
+			expectedError: nil,
+		},
+		{
+			name:    "custom field write error",
+			userID:  1,
+			version: 3,
+			patch: &domain.ProfilePatch{
+				CustomFields: map[string]string{"department": "Research"},
+			},
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec("UPDATE user SET version = version \\+ 1").
+					WithArgs(int64(1), int64(3)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectExec("INSERT INTO profile_field_value").
+					WillReturnError(sql.ErrConnDone)
+				m.ExpectRollback()
+			},
+			expectedError: sql.ErrConnDone,
+		},
+		{
+			name:    "history is written in the same transaction",
+			userID:  1,
+			version: 3,
+			patch: &domain.ProfilePatch{
+				Phone: stringPtr("+14155550132"),
+			},
+			history: []*domain.ProfileChange{
+				{UserID: 1, Field: "phone", OldValue: "", NewValue: "+14155550132", SessionID: "0123456789abcdef", CreatedAt: changedAt},
+			},
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec("UPDATE user SET phone_verified_at").
+					WithArgs("+14155550132", "+14155550132", int64(1), int64(3)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectExec("INSERT INTO profile_change \\(user_id, field, old_value, new_value, session_id, created_at\\) VALUES \\(\\?, \\?, \\?, \\?, \\?, \\?\\)").
+					WithArgs(int64(1), "phone", nil, "+14155550132", "0123456789abcdef", changedAt).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				m.ExpectCommit()
+			},
+			expectedError: nil,
+		},
+		{
+			name:    "history write error rolls back",
+			userID:  1,
+			version: 3,
+			patch: &domain.ProfilePatch{
+				FullName: stringPtr("Updated User"),
+			},
+			history: []*domain.ProfileChange{
+				{UserID: 1, Field: "full_name", OldValue: "Test User", NewValue: "Updated User", CreatedAt: changedAt},
+			},
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec("UPDATE user SET full_name = \\?").
+					WithArgs("Updated User", int64(1), int64(3)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectExec("INSERT INTO profile_change").
+					WillReturnError(sql.ErrConnDone)
+				m.ExpectRollback()
+			},
+			expectedError: sql.ErrConnDone,
+		},
+		{
+			name:    "handle, publicity and visibility",
+			userID:  1,
+			version: 3,
+			patch: &domain.ProfilePatch{
+				Handle:  stringPtr("test_user"),
+				Public:  boolPtr(true),
+				Privacy: domain.ProfilePrivacy{Email: domain.VisibilitySignedIn},
+			},
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec("^UPDATE user SET handle = \\?, public_profile = \\?, email_visibility = \\?, version = version \\+ 1 WHERE id = \\? AND version = \\?$").
+					WithArgs("test_user", true, "signed_in", int64(1), int64(3)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			},
+			expectedError: nil,
+		},
+		{
+			name:    "duplicate handle",
+			userID:  1,
+			version: 3,
+			patch: &domain.ProfilePatch{
+				Handle: stringPtr("taken"),
+			},
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec("UPDATE user SET handle = \\?").
+					WithArgs("taken", int64(1), int64(3)).
+					WillReturnError(&mysql.MySQLError{Number: ErrDuplicateEntry, Message: "Duplicate entry 'taken' for key 'user.handle'"})
+				m.ExpectRollback()
+			},
+			expectedError: domain.ErrHandleTaken,
+		},
+		{
+			name:          "empty patch is a no-op",
+			userID:        1,
+			version:       3,
+			patch:         &domain.ProfilePatch{},
+			setupMock:     func(m sqlmock.Sqlmock) {},
+			expectedError: nil,
+		},
+		{
+			name:    "duplicate email",
+			userID:  1,
+			version: 3,
+			patch: &domain.ProfilePatch{
+				Email: stringPtr("existing@example.com"),
 			},
 			setupMock: func(m sqlmock.Sqlmock) {
 				mysqlErr := &mysql.MySQLError{
 					Number:  ErrDuplicateEntry,
 					Message: "Duplicate entry",
 				}
-				m.ExpectExec("UPDATE user SET email = \\?, full_name = \\?, phone = \\? WHERE id = \\?").
-					WithArgs("existing@example.com", "Updated User", "9876543210", int64(1)).
+				m.ExpectBegin()
+				m.ExpectExec("UPDATE user SET email = \\?, version = version \\+ 1 WHERE id = \\? AND version = \\?").
+					WithArgs("existing@example.com", int64(1), int64(3)).
 					WillReturnError(mysqlErr)
+				m.ExpectRollback()
 			},
 			expectedError: domain.ErrUserAlreadyExists,
 		},
@@ -543,12 +742,12 @@ func TestRepository_UpdateProfile(t *testing.T) {
 			tt.setupMock(mock)
 
 			repo := NewRepository(logger, db)
-			err := repo.UpdateProfile(ctx, tt.profile)
+			err := repo.UpdateProfile(ctx, tt.userID, tt.version, tt.patch, tt.history)
 
 			if tt.expectedError != nil {
 				if err == nil {
 					t.Errorf("expected error %v, got nil", tt.expectedError)
-				} else if err != tt.expectedError && !isMySQLError(err, ErrDuplicateEntry) {
+				} else if !errors.Is(err, tt.expectedError) && !isMySQLError(err, ErrDuplicateEntry) {
 					t.Errorf("expected error %v, got %v", tt.expectedError, err)
 				}
 			} else {
@@ -564,9 +763,933 @@ func TestRepository_UpdateProfile(t *testing.T) {
 	}
 }
 
+func TestRepository_IsUserAdmin(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		userID        int64
+		setupMock     func(sqlmock.Sqlmock)
+		expectedError error
+		expectedAdmin bool
+	}{
+		{
+			name:   "admin user",
+			userID: 1,
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("SELECT is_admin FROM user WHERE id").
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"is_admin"}).AddRow(true))
+			},
+			expectedError: nil,
+			expectedAdmin: true,
+		},
+		{
+			name:   "user not found",
+			userID: 1,
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("SELECT is_admin FROM user WHERE id").
+					WithArgs(1).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: domain.ErrUserNotExists,
+			expectedAdmin: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			tt.setupMock(mock)
+
+			repo := NewRepository(logger, db)
+			isAdmin, err := repo.IsUserAdmin(ctx, tt.userID)
+
+			if err != tt.expectedError {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if isAdmin != tt.expectedAdmin {
+				t.Errorf("expected admin %v, got %v", tt.expectedAdmin, isAdmin)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("mock expectations were not met: %v", err)
+			}
+		})
+	}
+}
+
+func TestRepository_ScheduleAccountDeletion(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+	deleteAt := time.Now().Add(30 * 24 * time.Hour)
+
+	tests := []struct {
+		name          string
+		setupMock     func(sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name: "successful schedule",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectExec("UPDATE user SET deletion_scheduled_at = \\? WHERE id = \\?").
+					WithArgs(deleteAt, int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			expectedError: nil,
+		},
+		{
+			name: "user not found",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectExec("UPDATE user SET deletion_scheduled_at = \\? WHERE id = \\?").
+					WithArgs(deleteAt, int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedError: domain.ErrUserNotExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			tt.setupMock(mock)
+
+			repo := NewRepository(logger, db)
+			err := repo.ScheduleAccountDeletion(ctx, 1, deleteAt)
+
+			if err != tt.expectedError {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("mock expectations were not met: %v", err)
+			}
+		})
+	}
+}
+
+func TestRepository_CancelAccountDeletion(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name              string
+		rowsAffected      int64
+		expectedCancelled bool
+	}{
+		{name: "pending deletion cancelled", rowsAffected: 1, expectedCancelled: true},
+		{name: "no pending deletion", rowsAffected: 0, expectedCancelled: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			mock.ExpectExec("UPDATE user SET deletion_scheduled_at = NULL WHERE id = \\? AND deletion_scheduled_at IS NOT NULL").
+				WithArgs(int64(1)).
+				WillReturnResult(sqlmock.NewResult(0, tt.rowsAffected))
+
+			repo := NewRepository(logger, db)
+			cancelled, err := repo.CancelAccountDeletion(ctx, 1)
+
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if cancelled != tt.expectedCancelled {
+				t.Errorf("expected cancelled %v, got %v", tt.expectedCancelled, cancelled)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("mock expectations were not met: %v", err)
+			}
+		})
+	}
+}
+
+func TestRepository_DeleteUsersScheduledBefore(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+	now := time.Now()
+
+	tests := []struct {
+		name            string
+		setupMock       func(sqlmock.Sqlmock)
+		expectedDeleted int64
+		expectedAvatars []string
+		expectError     bool
+	}{
+		{
+			name: "locks and deletes the scheduled users",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT id, avatar_id FROM user WHERE deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= \\? FOR UPDATE").
+					WithArgs(now).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "avatar_id"}).AddRow(1, "avatar-1").AddRow(2, nil).AddRow(3, "avatar-3"))
+				m.ExpectExec("DELETE FROM user WHERE id IN \\(\\?,\\?,\\?\\)").
+					WithArgs(int64(1), int64(2), int64(3)).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				m.ExpectCommit()
+			},
+			expectedDeleted: 3,
+			expectedAvatars: []string{"avatar-1", "avatar-3"},
+		},
+		{
+			name: "nothing scheduled",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT id, avatar_id FROM user").
+					WithArgs(now).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "avatar_id"}))
+				m.ExpectRollback()
+			},
+		},
+		{
+			name: "delete fails",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT id, avatar_id FROM user").
+					WithArgs(now).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "avatar_id"}).AddRow(1, "avatar-1"))
+				m.ExpectExec("DELETE FROM user WHERE id IN").
+					WillReturnError(errors.New("lock wait timeout"))
+				m.ExpectRollback()
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			tt.setupMock(mock)
+
+			repo := NewRepository(logger, db)
+			deleted, avatarIDs, err := repo.DeleteUsersScheduledBefore(ctx, now)
+
+			if (err != nil) != tt.expectError {
+				t.Fatalf("expected error %v, got %v", tt.expectError, err)
+			}
+			if deleted != tt.expectedDeleted {
+				t.Errorf("expected %d deleted users, got %d", tt.expectedDeleted, deleted)
+			}
+			if !tt.expectError && !slices.Equal(avatarIDs, tt.expectedAvatars) {
+				t.Errorf("expected avatars %v, got %v", tt.expectedAvatars, avatarIDs)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("mock expectations were not met: %v", err)
+			}
+		})
+	}
+}
+
 func isMySQLError(err error, number uint16) bool {
 	if err != nil && err.Error() == domain.ErrUserAlreadyExists.Error() {
 		return true
 	}
 	return false
 }
+
+func TestRepository_GetOAuthAccountsByUserID(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+	now := time.Now()
+
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"provider_name", "sub", "created_at"}).
+		AddRow("google", "12345", now)
+	mock.ExpectQuery("SELECT provider_name, sub, created_at FROM oauth_account WHERE user_id = \\?").
+		WithArgs(1).
+		WillReturnRows(rows)
+
+	repo := NewRepository(logger, db)
+	accounts, err := repo.GetOAuthAccountsByUserID(ctx, 1)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(accounts) != 1 || accounts[0].ProviderName != "google" || accounts[0].Sub != "12345" {
+		t.Errorf("unexpected accounts: %v", accounts)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("mock expectations were not met: %v", err)
+	}
+}
+
+func TestRepository_CreateEmailChangeRequest(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+	now := time.Now()
+
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM email_change_request WHERE user_id = \\?").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO email_change_request").
+		WithArgs(1, "old@example.com", "new@example.com", "confirm-hash", "cancel-hash", now, now.Add(time.Hour)).
+		WillReturnResult(sqlmock.NewResult(7, 1))
+	mock.ExpectCommit()
+
+	repo := NewRepository(logger, db)
+	request := &domain.EmailChangeRequest{
+		UserID:           1,
+		OldEmail:         "old@example.com",
+		NewEmail:         "new@example.com",
+		ConfirmTokenHash: "confirm-hash",
+		CancelTokenHash:  "cancel-hash",
+		CreatedAt:        now,
+		ExpiresAt:        now.Add(time.Hour),
+	}
+	if err := repo.CreateEmailChangeRequest(ctx, request); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if request.ID != 7 {
+		t.Errorf("expected request id 7, got %d", request.ID)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("mock expectations were not met: %v", err)
+	}
+}
+
+func TestRepository_GetEmailChangeRequestByConfirmToken(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+	now := time.Now()
+	columns := []string{"id", "user_id", "old_email", "new_email", "confirm_token_hash", "cancel_token_hash", "created_at", "expires_at"}
+
+	tests := []struct {
+		name          string
+		setupMock     func(sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name: "request found",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("FROM email_change_request WHERE confirm_token_hash = \\?").
+					WithArgs("hash").
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(1, 1, "old@example.com", "new@example.com", "hash", "cancel", now, now.Add(time.Hour)))
+			},
+			expectedError: nil,
+		},
+		{
+			name: "request not found",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("FROM email_change_request WHERE confirm_token_hash = \\?").
+					WithArgs("hash").
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: domain.ErrEmailChangeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			tt.setupMock(mock)
+
+			repo := NewRepository(logger, db)
+			request, err := repo.GetEmailChangeRequestByConfirmToken(ctx, "hash")
+
+			if err != tt.expectedError {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if tt.expectedError == nil && request.NewEmail != "new@example.com" {
+				t.Errorf("expected new email new@example.com, got %s", request.NewEmail)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("mock expectations were not met: %v", err)
+			}
+		})
+	}
+}
+
+func TestRepository_ApplyEmailChange(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+	request := &domain.EmailChangeRequest{ID: 3, UserID: 1, OldEmail: "old@example.com", NewEmail: "new@example.com"}
+
+	tests := []struct {
+		name          string
+		setupMock     func(sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name: "successful change",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec("UPDATE user SET email = \\?, version = version \\+ 1 WHERE id = \\? AND email = \\?").
+					WithArgs("new@example.com", 1, "old@example.com").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectExec("DELETE FROM email_change_request WHERE id = \\?").
+					WithArgs(3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			},
+			expectedError: nil,
+		},
+		{
+			name: "new email taken",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec("UPDATE user SET email = \\?, version = version \\+ 1 WHERE id = \\? AND email = \\?").
+					WithArgs("new@example.com", 1, "old@example.com").
+					WillReturnError(&mysql.MySQLError{Number: ErrDuplicateEntry})
+				m.ExpectRollback()
+			},
+			expectedError: domain.ErrUserAlreadyExists,
+		},
+		{
+			name: "email changed meanwhile",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec("UPDATE user SET email = \\?, version = version \\+ 1 WHERE id = \\? AND email = \\?").
+					WithArgs("new@example.com", 1, "old@example.com").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				m.ExpectRollback()
+			},
+			expectedError: domain.ErrEmailChangeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			tt.setupMock(mock)
+
+			repo := NewRepository(logger, db)
+			err := repo.ApplyEmailChange(ctx, request)
+
+			if err != tt.expectedError {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("mock expectations were not met: %v", err)
+			}
+		})
+	}
+}
+
+func TestRepository_SavePhoneVerification(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+	now := time.Now()
+
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectExec("INSERT INTO phone_verification .* ON DUPLICATE KEY UPDATE").
+		WithArgs(int64(1), "+14155550132", "hash", now, now.Add(time.Minute)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	repo := NewRepository(logger, db)
+	err := repo.SavePhoneVerification(ctx, &domain.PhoneVerification{
+		UserID:    1,
+		Phone:     "+14155550132",
+		CodeHash:  "hash",
+		CreatedAt: now,
+		ExpiresAt: now.Add(time.Minute),
+	})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("mock expectations were not met: %v", err)
+	}
+}
+
+func TestRepository_UsePhoneVerificationAttempt(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		rowsAffected  int64
+		expectedError error
+	}{
+		{name: "attempt left", rowsAffected: 1},
+		{name: "attempts used up", rowsAffected: 0, expectedError: domain.ErrTooManyPhoneCodeAttempts},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			mock.ExpectExec("UPDATE phone_verification SET attempts = attempts \\+ 1 WHERE user_id = \\? AND attempts < \\?").
+				WithArgs(1, 3).
+				WillReturnResult(sqlmock.NewResult(0, tt.rowsAffected))
+
+			repo := NewRepository(logger, db)
+			err := repo.UsePhoneVerificationAttempt(ctx, 1, 3)
+
+			if err != tt.expectedError {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("mock expectations were not met: %v", err)
+			}
+		})
+	}
+}
+
+func TestRepository_MarkPhoneVerified(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+	now := time.Now()
+
+	tests := []struct {
+		name          string
+		setupMock     func(sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name: "phone verified",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec("UPDATE user SET phone_verified_at = \\?, version = version \\+ 1 WHERE id = \\? AND phone = \\?").
+					WithArgs(now, 1, "+14155550132").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectExec("DELETE FROM phone_verification WHERE user_id = \\?").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			},
+			expectedError: nil,
+		},
+		{
+			name: "phone changed meanwhile",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec("UPDATE user SET phone_verified_at = \\?, version = version \\+ 1 WHERE id = \\? AND phone = \\?").
+					WithArgs(now, 1, "+14155550132").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				m.ExpectRollback()
+			},
+			expectedError: domain.ErrPhoneVerificationNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			tt.setupMock(mock)
+
+			repo := NewRepository(logger, db)
+			err := repo.MarkPhoneVerified(ctx, 1, "+14155550132", now)
+
+			if err != tt.expectedError {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("mock expectations were not met: %v", err)
+			}
+		})
+	}
+}
+
+func TestRepository_SetAvatar(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name             string
+		setupMock        func(sqlmock.Sqlmock)
+		expectedPrevious string
+		expectedError    error
+	}{
+		{
+			name: "replaces previous avatar",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT avatar_id FROM user WHERE id = \\? FOR UPDATE").
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"avatar_id"}).AddRow("old-avatar"))
+				m.ExpectExec("UPDATE user SET avatar_id = \\?, version = version \\+ 1 WHERE id = \\?").
+					WithArgs("new-avatar", 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			},
+			expectedPrevious: "old-avatar",
+		},
+		{
+			name: "first avatar",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT avatar_id FROM user WHERE id = \\? FOR UPDATE").
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"avatar_id"}).AddRow(nil))
+				m.ExpectExec("UPDATE user SET avatar_id = \\?, version = version \\+ 1 WHERE id = \\?").
+					WithArgs("new-avatar", 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			},
+			expectedPrevious: "",
+		},
+		{
+			name: "user not found",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT avatar_id FROM user WHERE id = \\? FOR UPDATE").
+					WithArgs(1).
+					WillReturnError(sql.ErrNoRows)
+				m.ExpectRollback()
+			},
+			expectedError: domain.ErrUserNotExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			tt.setupMock(mock)
+
+			repo := NewRepository(logger, db)
+			previous, err := repo.SetAvatar(ctx, 1, "new-avatar")
+
+			if err != tt.expectedError {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if previous != tt.expectedPrevious {
+				t.Errorf("expected previous avatar %q, got %q", tt.expectedPrevious, previous)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("mock expectations were not met: %v", err)
+			}
+		})
+	}
+}
+
+func TestRepository_GetCustomFieldValues(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT f.field_key, v.value FROM profile_field_value v JOIN profile_field f").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"field_key", "value"}).
+			AddRow("department", "Research").
+			AddRow("timezone", "Europe/Berlin"))
+
+	repo := NewRepository(logger, db)
+	values, err := repo.GetCustomFieldValues(ctx, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(values) != 2 || values["department"] != "Research" || values["timezone"] != "Europe/Berlin" {
+		t.Errorf("unexpected values: %v", values)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRepository_ListProfileChanges(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+	changedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	columns := []string{"id", "user_id", "field", "old_value", "new_value", "session_id", "created_at"}
+
+	tests := []struct {
+		name          string
+		beforeID      int64
+		limit         int
+		setupMock     func(sqlmock.Sqlmock)
+		expectedCount int
+		expectedError bool
+	}{
+		{
+			name:  "newest first",
+			limit: 10,
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("FROM profile_change WHERE user_id = \\? ORDER BY id DESC LIMIT \\?").
+					WithArgs(int64(1), 10).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(int64(2), int64(1), "phone", "+14155550132", nil, "0123456789abcdef", changedAt).
+						AddRow(int64(1), int64(1), "phone", nil, "+14155550132", nil, changedAt))
+			},
+			expectedCount: 2,
+		},
+		{
+			name:     "paged with default limit",
+			beforeID: 5,
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("WHERE user_id = \\? AND id < \\? ORDER BY id DESC LIMIT \\?").
+					WithArgs(int64(1), int64(5), defaultHistoryLimit).
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+			expectedCount: 0,
+		},
+		{
+			name:  "database error",
+			limit: 10,
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("FROM profile_change").
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			tt.setupMock(mock)
+
+			repo := NewRepository(logger, db)
+			changes, err := repo.ListProfileChanges(ctx, 1, tt.beforeID, tt.limit)
+
+			if tt.expectedError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(changes) != tt.expectedCount {
+					t.Errorf("expected %d changes, got %d", tt.expectedCount, len(changes))
+				}
+				if tt.expectedCount > 0 && (changes[0].OldValue != "+14155550132" || changes[0].NewValue != "" || changes[0].SessionID != "0123456789abcdef") {
+					t.Errorf("unexpected change: %+v", changes[0])
+				}
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestRepository_GetProfileByHandle(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		setupMock     func(sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name: "found",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("FROM user WHERE handle = \\? AND deletion_scheduled_at IS NULL").
+					WithArgs("test_user").
+					WillReturnRows(sqlmock.NewRows(profileColumns).
+						AddRow(1, "test@example.com", "Test User", nil, nil, nil, 3,
+							"test_user", true, "public", "private", "private", "public"))
+			},
+		},
+		{
+			name: "not found",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("FROM user WHERE handle = \\?").
+					WithArgs("test_user").
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: domain.ErrUserNotExists,
+		},
+		{
+			name: "account scheduled for deletion",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("FROM user WHERE handle = \\? AND deletion_scheduled_at IS NULL").
+					WithArgs("test_user").
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: domain.ErrUserNotExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			tt.setupMock(mock)
+
+			repo := NewRepository(logger, db)
+			profile, err := repo.GetProfileByHandle(ctx, "test_user")
+
+			if tt.expectedError != nil {
+				if !errors.Is(err, tt.expectedError) {
+					t.Errorf("expected error %v, got %v", tt.expectedError, err)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if profile.UserID != 1 || profile.Handle != "test_user" || !profile.Public {
+				t.Errorf("unexpected profile: %+v", profile)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("mock expectations were not met: %v", err)
+			}
+		})
+	}
+}
+
+func TestRepository_GetActiveProfileByUserID(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		setupMock     func(sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name: "found",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("FROM user WHERE id = \\? AND deletion_scheduled_at IS NULL").
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(profileColumns).
+						AddRow(1, "test@example.com", "Test User", nil, nil, "avatar", 3,
+							"test_user", true, "public", "private", "private", "public"))
+			},
+		},
+		{
+			name: "unknown or scheduled for deletion",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("FROM user WHERE id = \\? AND deletion_scheduled_at IS NULL").
+					WithArgs(1).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: domain.ErrUserNotExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			tt.setupMock(mock)
+
+			repo := NewRepository(logger, db)
+			profile, err := repo.GetActiveProfileByUserID(ctx, 1)
+
+			if tt.expectedError != nil {
+				if !errors.Is(err, tt.expectedError) {
+					t.Errorf("expected error %v, got %v", tt.expectedError, err)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if profile.UserID != 1 || profile.AvatarID != "avatar" {
+				t.Errorf("unexpected profile: %+v", profile)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("mock expectations were not met: %v", err)
+			}
+		})
+	}
+}
+
+func TestRepository_SearchProfiles(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		terms         []string
+		afterID       int64
+		limit         int
+		setupMock     func(sqlmock.Sqlmock)
+		expectedCount int
+		expectError   bool
+	}{
+		{
+			name:    "matches every term as a phrase",
+			terms:   []string{"ada", `love"lace`},
+			afterID: 7,
+			limit:   2,
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("FROM user WHERE public_profile = TRUE AND deletion_scheduled_at IS NULL AND id > \\?").
+					WithArgs(int64(7), "public", "signed_in", `+"ada" +"lovelace"`, "public", "signed_in", `+"ada" +"lovelace"`, 2).
+					WillReturnRows(sqlmock.NewRows(profileColumns).
+						AddRow(8, "ada@example.com", "Ada Lovelace", nil, nil, nil, 1,
+							"ada", true, "public", "signed_in", "private", "public").
+						AddRow(12, "lovelace@example.com", "Augusta Ada", nil, nil, nil, 1,
+							"augusta", true, "public", "private", "private", "public"))
+			},
+			expectedCount: 2,
+		},
+		{
+			name:  "limit is clamped",
+			terms: []string{"ada"},
+			limit: 1000,
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("FROM user WHERE public_profile").
+					WithArgs(int64(0), "public", "signed_in", `+"ada"`, "public", "signed_in", `+"ada"`, maxSearchLimit).
+					WillReturnRows(sqlmock.NewRows(profileColumns))
+			},
+		},
+		{
+			name:  "database error",
+			terms: []string{"ada"},
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("FROM user WHERE public_profile").
+					WillReturnError(errors.New("db error"))
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			tt.setupMock(mock)
+
+			repo := NewRepository(logger, db)
+			profiles, err := repo.SearchProfiles(ctx, tt.terms, tt.afterID, tt.limit)
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if len(profiles) != tt.expectedCount {
+				t.Errorf("expected %d profiles, got %d", tt.expectedCount, len(profiles))
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("mock expectations were not met: %v", err)
+			}
+		})
+	}
+}
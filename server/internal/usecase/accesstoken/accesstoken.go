@@ -0,0 +1,193 @@
+package accesstoken
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"server/internal/domain"
+	"server/internal/pkg/tracing"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxNameLength    = 100
+	maxTokensPerUser = 50
+	// lastUsedInterval limits how often the last use of a token is written,
+	// so a busy script does not cause a write on every request.
+	lastUsedInterval = time.Minute
+)
+
+// CreateToken creates a personal access token. The token is returned only
+// here; afterwards just its hash is known.
+func (uc *UseCase) CreateToken(ctx context.Context, userID int64, name string, scopes []domain.TokenScope, expiresAt *time.Time) (string, *domain.PersonalAccessToken, error) {
+	ctx, span := tracing.Start(ctx, "accesstoken.CreateToken")
+	defer span.End()
+
+	now := time.Now()
+	token := &domain.PersonalAccessToken{
+		UserID:    userID,
+		Name:      strings.TrimSpace(name),
+		Scopes:    scopes,
+		CreatedAt: now,
+		ExpiresAt: expiresAt,
+	}
+	if err := validateToken(token, now); err != nil {
+		return "", nil, err
+	}
+
+	value, err := newToken()
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "failed to generate access token", "error", err)
+		return "", nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+	token.TokenHash = hashToken(value)
+
+	if err := uc.accessTokenRepo.CreateToken(ctx, token, maxTokensPerUser); err != nil {
+		return "", nil, err
+	}
+
+	scopeNames := make([]string, 0, len(token.Scopes))
+	for _, scope := range token.Scopes {
+		scopeNames = append(scopeNames, string(scope))
+	}
+	uc.record(ctx, userID, domain.AuditEventAccessTokenCreate, map[string]string{
+		"token_id": strconv.FormatInt(token.ID, 10),
+		"name":     token.Name,
+		"scopes":   strings.Join(scopeNames, ","),
+	})
+	return value, token, nil
+}
+
+func (uc *UseCase) ListTokens(ctx context.Context, userID int64) ([]*domain.PersonalAccessToken, error) {
+	ctx, span := tracing.Start(ctx, "accesstoken.ListTokens")
+	defer span.End()
+
+	return uc.accessTokenRepo.ListTokens(ctx, userID)
+}
+
+// DeleteToken revokes one of the user's tokens; it stops working at once.
+func (uc *UseCase) DeleteToken(ctx context.Context, userID, id int64) error {
+	ctx, span := tracing.Start(ctx, "accesstoken.DeleteToken")
+	defer span.End()
+
+	if err := uc.accessTokenRepo.DeleteToken(ctx, userID, id); err != nil {
+		return err
+	}
+
+	uc.record(ctx, userID, domain.AuditEventAccessTokenDelete, map[string]string{
+		"token_id": strconv.FormatInt(id, 10),
+	})
+	return nil
+}
+
+// Authenticate turns a personal access token into a session for the
+// request. Unknown and expired tokens give domain.ErrSessionNotFound, like
+// any other bad credential.
+func (uc *UseCase) Authenticate(ctx context.Context, value string) (*domain.Session, error) {
+	ctx, span := tracing.Start(ctx, "accesstoken.Authenticate")
+	defer span.End()
+
+	token, err := uc.accessTokenRepo.GetTokenByHash(ctx, hashToken(value))
+	if errors.Is(err, domain.ErrAccessTokenNotFound) {
+		return nil, domain.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if token.IsExpired(now) {
+		return nil, domain.ErrSessionNotFound
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= lastUsedInterval {
+		// The repository logs a failed write; a stale last use must not
+		// fail the request.
+		_ = uc.accessTokenRepo.TouchToken(ctx, token.ID, now)
+	}
+
+	session := &domain.Session{
+		Token:      value,
+		UserID:     token.UserID,
+		AuthMethod: domain.AuthMethodAccessToken,
+		CreatedAt:  token.CreatedAt,
+		Bearer:     true,
+		Scopes:     token.Scopes,
+	}
+	if token.ExpiresAt != nil {
+		session.ExpiresAt = *token.ExpiresAt
+	}
+	return session, nil
+}
+
+func (uc *UseCase) record(ctx context.Context, userID int64, eventType domain.AuditEventType, details map[string]string) {
+	uc.auditUC.Record(ctx, domain.AuditEvent{
+		ActorUserID:   &userID,
+		SubjectUserID: &userID,
+		Type:          eventType,
+		Outcome:       domain.AuditOutcomeSuccess,
+		Details:       details,
+	})
+}
+
+// validateToken checks a new token and removes repeated scopes, reporting
+// every problem at once as domain.FieldErrors.
+func validateToken(token *domain.PersonalAccessToken, now time.Time) error {
+	fieldErrors := domain.FieldErrors{}
+
+	if token.Name == "" {
+		fieldErrors["name"] = "must not be empty"
+	} else if utf8.RuneCountInString(token.Name) > maxNameLength {
+		fieldErrors["name"] = fmt.Sprintf("must be at most %d characters", maxNameLength)
+	}
+
+	scopes := make([]domain.TokenScope, 0, len(token.Scopes))
+	for _, scope := range token.Scopes {
+		if !scope.IsValid() {
+			names := make([]string, 0, len(domain.TokenScopes))
+			for _, known := range domain.TokenScopes {
+				names = append(names, string(known))
+			}
+			fieldErrors["scopes"] = "must be some of: " + strings.Join(names, ", ")
+			break
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(token.Scopes) == 0 {
+		fieldErrors["scopes"] = "must not be empty"
+	}
+	token.Scopes = scopes
+
+	if token.ExpiresAt != nil && !token.ExpiresAt.After(now) {
+		fieldErrors["expires_at"] = "must be in the future"
+	}
+
+	if len(fieldErrors) > 0 {
+		return fieldErrors
+	}
+	return nil
+}
+
+// newToken returns 256 random bits behind domain.AccessTokenPrefix.
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return domain.AccessTokenPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
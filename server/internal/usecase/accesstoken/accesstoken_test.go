@@ -0,0 +1,310 @@
+package accesstoken
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"server/internal/domain"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mockAccessTokenRepository struct {
+	createTokenFunc    func(ctx context.Context, token *domain.PersonalAccessToken, maxTokens int) error
+	listTokensFunc     func(ctx context.Context, userID int64) ([]*domain.PersonalAccessToken, error)
+	getTokenByHashFunc func(ctx context.Context, tokenHash string) (*domain.PersonalAccessToken, error)
+	deleteTokenFunc    func(ctx context.Context, userID, id int64) error
+	touchTokenFunc     func(ctx context.Context, id int64, usedAt time.Time) error
+}
+
+func (m *mockAccessTokenRepository) CreateToken(ctx context.Context, token *domain.PersonalAccessToken, maxTokens int) error {
+	if m.createTokenFunc != nil {
+		return m.createTokenFunc(ctx, token, maxTokens)
+	}
+	token.ID = 1
+	return nil
+}
+
+func (m *mockAccessTokenRepository) ListTokens(ctx context.Context, userID int64) ([]*domain.PersonalAccessToken, error) {
+	if m.listTokensFunc != nil {
+		return m.listTokensFunc(ctx, userID)
+	}
+	return nil, nil
+}
+
+func (m *mockAccessTokenRepository) GetTokenByHash(ctx context.Context, tokenHash string) (*domain.PersonalAccessToken, error) {
+	if m.getTokenByHashFunc != nil {
+		return m.getTokenByHashFunc(ctx, tokenHash)
+	}
+	return nil, domain.ErrAccessTokenNotFound
+}
+
+func (m *mockAccessTokenRepository) DeleteToken(ctx context.Context, userID, id int64) error {
+	if m.deleteTokenFunc != nil {
+		return m.deleteTokenFunc(ctx, userID, id)
+	}
+	return nil
+}
+
+func (m *mockAccessTokenRepository) TouchToken(ctx context.Context, id int64, usedAt time.Time) error {
+	if m.touchTokenFunc != nil {
+		return m.touchTokenFunc(ctx, id, usedAt)
+	}
+	return nil
+}
+
+type mockAuditUseCase struct {
+	events []domain.AuditEvent
+}
+
+func (m *mockAuditUseCase) Record(ctx context.Context, event domain.AuditEvent) {
+	m.events = append(m.events, event)
+}
+
+func TestUseCase_CreateToken(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	future := time.Now().Add(24 * time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name          string
+		tokenName     string
+		scopes        []domain.TokenScope
+		expiresAt     *time.Time
+		existing      int
+		expectedError error
+		expectedField string
+	}{
+		{
+			name:      "token with expiry",
+			tokenName: "  deploy  ",
+			scopes:    []domain.TokenScope{domain.ScopeProfileRead, domain.ScopeProfileRead, domain.ScopeProfileWrite},
+			expiresAt: &future,
+		},
+		{
+			name:      "token without expiry",
+			tokenName: "deploy",
+			scopes:    []domain.TokenScope{domain.ScopeOrganizationsRead},
+		},
+		{
+			name:          "empty name",
+			tokenName:     "  ",
+			scopes:        []domain.TokenScope{domain.ScopeProfileRead},
+			expectedField: "name",
+		},
+		{
+			name:          "name too long",
+			tokenName:     strings.Repeat("a", maxNameLength+1),
+			scopes:        []domain.TokenScope{domain.ScopeProfileRead},
+			expectedField: "name",
+		},
+		{
+			name:          "no scopes",
+			tokenName:     "deploy",
+			expectedField: "scopes",
+		},
+		{
+			name:          "unknown scope",
+			tokenName:     "deploy",
+			scopes:        []domain.TokenScope{"admin"},
+			expectedField: "scopes",
+		},
+		{
+			name:          "expiry in the past",
+			tokenName:     "deploy",
+			scopes:        []domain.TokenScope{domain.ScopeProfileRead},
+			expiresAt:     &past,
+			expectedField: "expires_at",
+		},
+		{
+			name:          "too many tokens",
+			tokenName:     "deploy",
+			scopes:        []domain.TokenScope{domain.ScopeProfileRead},
+			existing:      maxTokensPerUser,
+			expectedError: domain.ErrTooManyAccessTokens,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stored *domain.PersonalAccessToken
+			mockRepo := &mockAccessTokenRepository{
+				createTokenFunc: func(ctx context.Context, token *domain.PersonalAccessToken, maxTokens int) error {
+					if tt.existing >= maxTokens {
+						return domain.ErrTooManyAccessTokens
+					}
+					stored = token
+					token.ID = 7
+					return nil
+				},
+			}
+			mockAudit := &mockAuditUseCase{}
+
+			uc := NewUseCase(logger, mockRepo, mockAudit)
+			value, token, err := uc.CreateToken(context.Background(), 1, tt.tokenName, tt.scopes, tt.expiresAt)
+
+			if tt.expectedField != "" {
+				var fieldErrors domain.FieldErrors
+				if !errors.As(err, &fieldErrors) {
+					t.Fatalf("expected field errors, got %v", err)
+				}
+				if _, ok := fieldErrors[tt.expectedField]; !ok {
+					t.Errorf("expected an error for %s, got %v", tt.expectedField, fieldErrors)
+				}
+				if stored != nil {
+					t.Error("expected no token to be stored")
+				}
+				return
+			}
+			if tt.expectedError != nil {
+				if !errors.Is(err, tt.expectedError) {
+					t.Errorf("expected error %v, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !strings.HasPrefix(value, domain.AccessTokenPrefix) {
+				t.Errorf("expected token to start with %s, got %s", domain.AccessTokenPrefix, value)
+			}
+			if token != stored || token.TokenHash != hashToken(value) {
+				t.Error("expected only the hash of the token to be stored")
+			}
+			if token.Name != strings.TrimSpace(tt.tokenName) {
+				t.Errorf("expected trimmed name, got %q", token.Name)
+			}
+			if len(token.Scopes) != len(slices.Compact(slices.Clone(tt.scopes))) {
+				t.Errorf("expected repeated scopes to be removed, got %v", token.Scopes)
+			}
+			if len(mockAudit.events) != 1 || mockAudit.events[0].Type != domain.AuditEventAccessTokenCreate {
+				t.Fatalf("expected a token creation event, got %v", mockAudit.events)
+			}
+			if mockAudit.events[0].Details["token_id"] != "7" {
+				t.Errorf("expected token id in details, got %v", mockAudit.events[0].Details)
+			}
+		})
+	}
+}
+
+func TestUseCase_DeleteToken(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	tests := []struct {
+		name          string
+		repoErr       error
+		expectedError error
+		expectEvents  int
+	}{
+		{name: "deleted", expectEvents: 1},
+		{name: "not found", repoErr: domain.ErrAccessTokenNotFound, expectedError: domain.ErrAccessTokenNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &mockAccessTokenRepository{
+				deleteTokenFunc: func(ctx context.Context, userID, id int64) error {
+					if userID != 1 || id != 3 {
+						t.Errorf("unexpected user %d or token %d", userID, id)
+					}
+					return tt.repoErr
+				},
+			}
+			mockAudit := &mockAuditUseCase{}
+
+			uc := NewUseCase(logger, mockRepo, mockAudit)
+			err := uc.DeleteToken(context.Background(), 1, 3)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if len(mockAudit.events) != tt.expectEvents {
+				t.Errorf("expected %d audit events, got %d", tt.expectEvents, len(mockAudit.events))
+			}
+		})
+	}
+}
+
+func TestUseCase_Authenticate(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	now := time.Now()
+	recently := now.Add(-time.Second)
+	longAgo := now.Add(-time.Hour)
+	expired := now.Add(-time.Minute)
+
+	tests := []struct {
+		name          string
+		token         *domain.PersonalAccessToken
+		expectedError error
+		expectTouch   bool
+	}{
+		{
+			name:        "never used",
+			token:       &domain.PersonalAccessToken{ID: 3, UserID: 1, Scopes: []domain.TokenScope{domain.ScopeProfileRead}},
+			expectTouch: true,
+		},
+		{
+			name:        "used long ago",
+			token:       &domain.PersonalAccessToken{ID: 3, UserID: 1, Scopes: []domain.TokenScope{domain.ScopeProfileRead}, LastUsedAt: &longAgo},
+			expectTouch: true,
+		},
+		{
+			name:  "used recently",
+			token: &domain.PersonalAccessToken{ID: 3, UserID: 1, Scopes: []domain.TokenScope{domain.ScopeProfileRead}, LastUsedAt: &recently},
+		},
+		{
+			name:          "expired",
+			token:         &domain.PersonalAccessToken{ID: 3, UserID: 1, ExpiresAt: &expired},
+			expectedError: domain.ErrSessionNotFound,
+		},
+		{
+			name:          "unknown",
+			expectedError: domain.ErrSessionNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			touched := false
+			mockRepo := &mockAccessTokenRepository{
+				getTokenByHashFunc: func(ctx context.Context, tokenHash string) (*domain.PersonalAccessToken, error) {
+					if tokenHash != hashToken("pat_value") {
+						t.Errorf("expected lookup by hash, got %s", tokenHash)
+					}
+					if tt.token == nil {
+						return nil, domain.ErrAccessTokenNotFound
+					}
+					return tt.token, nil
+				},
+				touchTokenFunc: func(ctx context.Context, id int64, usedAt time.Time) error {
+					touched = true
+					return nil
+				},
+			}
+
+			uc := NewUseCase(logger, mockRepo, &mockAuditUseCase{})
+			session, err := uc.Authenticate(context.Background(), "pat_value")
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if touched != tt.expectTouch {
+				t.Errorf("expected touch %v, got %v", tt.expectTouch, touched)
+			}
+			if tt.expectedError != nil {
+				return
+			}
+
+			if session.UserID != 1 || session.AuthMethod != domain.AuthMethodAccessToken || !session.Bearer {
+				t.Errorf("unexpected session %+v", session)
+			}
+			if !session.HasScope(domain.ScopeProfileRead) || session.HasScope(domain.ScopeProfileWrite) {
+				t.Errorf("expected session limited to the token's scopes, got %v", session.Scopes)
+			}
+		})
+	}
+}
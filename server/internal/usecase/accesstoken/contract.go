@@ -0,0 +1,19 @@
+package accesstoken
+
+import (
+	"context"
+	"server/internal/domain"
+	"time"
+)
+
+type AccessTokenRepository interface {
+	CreateToken(ctx context.Context, token *domain.PersonalAccessToken, maxTokens int) error
+	ListTokens(ctx context.Context, userID int64) ([]*domain.PersonalAccessToken, error)
+	GetTokenByHash(ctx context.Context, tokenHash string) (*domain.PersonalAccessToken, error)
+	DeleteToken(ctx context.Context, userID, id int64) error
+	TouchToken(ctx context.Context, id int64, usedAt time.Time) error
+}
+
+type AuditUseCase interface {
+	Record(ctx context.Context, event domain.AuditEvent)
+}
@@ -0,0 +1,17 @@
+package accesstoken
+
+import "log/slog"
+
+type UseCase struct {
+	logger          *slog.Logger
+	accessTokenRepo AccessTokenRepository
+	auditUC         AuditUseCase
+}
+
+func NewUseCase(logger *slog.Logger, accessTokenRepo AccessTokenRepository, auditUC AuditUseCase) *UseCase {
+	return &UseCase{
+		logger:          logger,
+		accessTokenRepo: accessTokenRepo,
+		auditUC:         auditUC,
+	}
+}
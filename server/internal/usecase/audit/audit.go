@@ -0,0 +1,130 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"server/internal/domain"
+	appcontext "server/internal/pkg/context"
+	"server/internal/pkg/tracing"
+	"time"
+)
+
+const (
+	verifyBatchSize    = 500
+	maxIPLength        = 255
+	maxUserAgentLength = 512
+)
+
+// Record appends an event to the audit log. Failures are logged rather than
+// returned so that an unavailable audit table never blocks logins.
+func (uc *UseCase) Record(ctx context.Context, event domain.AuditEvent) {
+	ctx, span := tracing.Start(ctx, "audit.Record")
+	defer span.End()
+
+	client := appcontext.ClientInfoFromContext(ctx)
+	event.IP = truncate(client.IP, maxIPLength)
+	event.UserAgent = truncate(client.UserAgent, maxUserAgentLength)
+	event.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+
+	if err := uc.auditRepo.AppendEvent(ctx, &event); err != nil {
+		uc.logger.ErrorContext(ctx, "failed to record audit event", "error", err, "type", event.Type, "outcome", event.Outcome)
+	}
+}
+
+// ListUserActivity returns the events userID performed or was the subject
+// of. The IP address and user agent belong to whoever acted, so they are
+// left out of events another user performed, such as an admin removing
+// userID from an organization.
+func (uc *UseCase) ListUserActivity(ctx context.Context, userID int64, beforeID int64, limit int) ([]*domain.AuditEvent, error) {
+	ctx, span := tracing.Start(ctx, "audit.ListUserActivity")
+	defer span.End()
+
+	events, err := uc.auditRepo.ListEvents(ctx, domain.AuditQuery{
+		UserID:   &userID,
+		BeforeID: beforeID,
+		Limit:    limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user activity: %w", err)
+	}
+	for _, event := range events {
+		if event.ActorUserID != nil && *event.ActorUserID != userID {
+			event.IP = ""
+			event.UserAgent = ""
+		}
+	}
+	return events, nil
+}
+
+func (uc *UseCase) QueryEvents(ctx context.Context, query domain.AuditQuery) ([]*domain.AuditEvent, error) {
+	ctx, span := tracing.Start(ctx, "audit.QueryEvents")
+	defer span.End()
+
+	events, err := uc.auditRepo.ListEvents(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit events: %w", err)
+	}
+	return events, nil
+}
+
+// VerifyChain walks the whole log in insertion order and recomputes every
+// hash, reporting the first row whose link or content does not match. The
+// chain head is read first and must turn up among the rows: events appended
+// during the walk come after it, but rows removed from the end take it away.
+func (uc *UseCase) VerifyChain(ctx context.Context) (*domain.AuditChainReport, error) {
+	ctx, span := tracing.Start(ctx, "audit.VerifyChain")
+	defer span.End()
+
+	head, err := uc.auditRepo.GetChainHead(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit chain head: %w", err)
+	}
+	headFound := head == ""
+
+	report := &domain.AuditChainReport{Valid: true}
+	prevHash := ""
+	afterID := int64(0)
+	for {
+		events, err := uc.auditRepo.ListEventsAfterID(ctx, afterID, verifyBatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list audit events: %w", err)
+		}
+		for _, event := range events {
+			report.Checked++
+			hash, err := event.ComputeHash()
+			if err != nil {
+				return nil, fmt.Errorf("failed to compute audit event hash: %w", err)
+			}
+			if event.PrevHash != prevHash || event.Hash != hash {
+				report.Valid = false
+				report.BrokenAtID = event.ID
+				return report, nil
+			}
+			if event.Hash == head {
+				headFound = true
+			}
+			prevHash = event.Hash
+			afterID = event.ID
+		}
+		if len(events) < verifyBatchSize {
+			if !headFound {
+				report.Valid = false
+				report.HeadMissing = true
+			}
+			return report, nil
+		}
+	}
+}
+
+// truncate caps value at maxLength characters, which is what the varchar
+// columns count, without splitting a multi-byte character.
+func truncate(value string, maxLength int) string {
+	count := 0
+	for i := range value {
+		if count == maxLength {
+			return value[:i]
+		}
+		count++
+	}
+	return value
+}
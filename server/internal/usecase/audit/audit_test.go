@@ -0,0 +1,254 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"server/internal/domain"
+	appcontext "server/internal/pkg/context"
+	"testing"
+	"time"
+)
+
+type mockAuditRepository struct {
+	appendEventFunc       func(ctx context.Context, event *domain.AuditEvent) error
+	listEventsFunc        func(ctx context.Context, query domain.AuditQuery) ([]*domain.AuditEvent, error)
+	listEventsAfterIDFunc func(ctx context.Context, afterID int64, limit int) ([]*domain.AuditEvent, error)
+	getChainHeadFunc      func(ctx context.Context) (string, error)
+}
+
+func (m *mockAuditRepository) AppendEvent(ctx context.Context, event *domain.AuditEvent) error {
+	if m.appendEventFunc != nil {
+		return m.appendEventFunc(ctx, event)
+	}
+	return nil
+}
+
+func (m *mockAuditRepository) ListEvents(ctx context.Context, query domain.AuditQuery) ([]*domain.AuditEvent, error) {
+	if m.listEventsFunc != nil {
+		return m.listEventsFunc(ctx, query)
+	}
+	return nil, nil
+}
+
+func (m *mockAuditRepository) ListEventsAfterID(ctx context.Context, afterID int64, limit int) ([]*domain.AuditEvent, error) {
+	if m.listEventsAfterIDFunc != nil {
+		return m.listEventsAfterIDFunc(ctx, afterID, limit)
+	}
+	return nil, nil
+}
+
+func (m *mockAuditRepository) GetChainHead(ctx context.Context) (string, error) {
+	if m.getChainHeadFunc != nil {
+		return m.getChainHeadFunc(ctx)
+	}
+	return "", nil
+}
+
+func buildChain(t *testing.T, n int) []*domain.AuditEvent {
+	events := make([]*domain.AuditEvent, 0, n)
+	prevHash := ""
+	for i := 0; i < n; i++ {
+		userID := int64(i + 1)
+		event := &domain.AuditEvent{
+			ID:            int64(i + 1),
+			SubjectUserID: &userID,
+			Type:          domain.AuditEventLogInEmail,
+			Outcome:       domain.AuditOutcomeSuccess,
+			Details:       map[string]string{"n": "1"},
+			CreatedAt:     time.Unix(int64(1700000000+i), 0),
+			PrevHash:      prevHash,
+		}
+		hash, err := event.ComputeHash()
+		if err != nil {
+			t.Fatalf("failed to compute hash: %v", err)
+		}
+		event.Hash = hash
+		prevHash = hash
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestUseCase_Record(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := appcontext.WithClientInfo(context.Background(), domain.ClientInfo{
+		IP:        "10.0.0.1",
+		UserAgent: "test-agent",
+	})
+
+	var recorded *domain.AuditEvent
+	repo := &mockAuditRepository{
+		appendEventFunc: func(ctx context.Context, event *domain.AuditEvent) error {
+			recorded = event
+			return nil
+		},
+	}
+
+	uc := NewUseCase(logger, repo)
+	uc.Record(ctx, domain.AuditEvent{Type: domain.AuditEventLogOut, Outcome: domain.AuditOutcomeSuccess})
+
+	if recorded == nil {
+		t.Fatal("expected event to be recorded")
+	}
+	if recorded.IP != "10.0.0.1" {
+		t.Errorf("expected IP 10.0.0.1, got %s", recorded.IP)
+	}
+	if recorded.UserAgent != "test-agent" {
+		t.Errorf("expected user agent test-agent, got %s", recorded.UserAgent)
+	}
+	if recorded.CreatedAt.IsZero() {
+		t.Error("expected created at to be set")
+	}
+
+	repo.appendEventFunc = func(ctx context.Context, event *domain.AuditEvent) error {
+		return errors.New("db down")
+	}
+	uc.Record(ctx, domain.AuditEvent{Type: domain.AuditEventLogOut, Outcome: domain.AuditOutcomeSuccess})
+}
+
+func TestUseCase_VerifyChain(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	chain := buildChain(t, 3)
+	head := chain[len(chain)-1].Hash
+
+	tests := []struct {
+		name              string
+		events            func() []*domain.AuditEvent
+		expectedValid     bool
+		expectedID        int64
+		expectHeadMissing bool
+	}{
+		{
+			name: "intact chain",
+			events: func() []*domain.AuditEvent {
+				return buildChain(t, 3)
+			},
+			expectedValid: true,
+		},
+		{
+			name: "tampered details",
+			events: func() []*domain.AuditEvent {
+				events := buildChain(t, 3)
+				events[1].Details["n"] = "2"
+				return events
+			},
+			expectedValid: false,
+			expectedID:    2,
+		},
+		{
+			name: "deleted row",
+			events: func() []*domain.AuditEvent {
+				events := buildChain(t, 3)
+				return append(events[:1], events[2:]...)
+			},
+			expectedValid: false,
+			expectedID:    3,
+		},
+		{
+			name: "deleted rows at the end",
+			events: func() []*domain.AuditEvent {
+				return buildChain(t, 3)[:2]
+			},
+			expectedValid:     false,
+			expectHeadMissing: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := tt.events()
+			repo := &mockAuditRepository{
+				listEventsAfterIDFunc: func(ctx context.Context, afterID int64, limit int) ([]*domain.AuditEvent, error) {
+					if afterID != 0 {
+						return nil, nil
+					}
+					return events, nil
+				},
+				getChainHeadFunc: func(ctx context.Context) (string, error) {
+					return head, nil
+				},
+			}
+
+			uc := NewUseCase(logger, repo)
+			report, err := uc.VerifyChain(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if report.Valid != tt.expectedValid {
+				t.Errorf("expected valid %v, got %v", tt.expectedValid, report.Valid)
+			}
+			if report.BrokenAtID != tt.expectedID {
+				t.Errorf("expected broken at %d, got %d", tt.expectedID, report.BrokenAtID)
+			}
+			if report.HeadMissing != tt.expectHeadMissing {
+				t.Errorf("expected head missing %v, got %v", tt.expectHeadMissing, report.HeadMissing)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		maxLength int
+		expected  string
+	}{
+		{name: "short", value: "abc", maxLength: 5, expected: "abc"},
+		{name: "ascii", value: "abcdef", maxLength: 3, expected: "abc"},
+		{name: "multi-byte", value: "żółw-żółw", maxLength: 4, expected: "żółw"},
+		{name: "exact", value: "żółw", maxLength: 4, expected: "żółw"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncate(tt.value, tt.maxLength); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestUseCase_ListUserActivity(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	repo := &mockAuditRepository{
+		listEventsFunc: func(ctx context.Context, query domain.AuditQuery) ([]*domain.AuditEvent, error) {
+			if query.UserID == nil || *query.UserID != 5 {
+				t.Errorf("expected user filter 5, got %v", query.UserID)
+			}
+			if query.BeforeID != 100 {
+				t.Errorf("expected before id 100, got %d", query.BeforeID)
+			}
+			self, admin := int64(5), int64(6)
+			return []*domain.AuditEvent{
+				{ID: 99, ActorUserID: &self, IP: "192.0.2.1", UserAgent: "own-agent"},
+				{ID: 98, ActorUserID: &admin, SubjectUserID: &self, IP: "192.0.2.2", UserAgent: "admin-agent"},
+				{ID: 97, SubjectUserID: &self, IP: "192.0.2.3", UserAgent: "login-agent"},
+			}, nil
+		},
+	}
+
+	uc := NewUseCase(logger, repo)
+	events, err := uc.ListUserActivity(ctx, 5, 100, 20)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+	if events[0].IP != "192.0.2.1" || events[0].UserAgent != "own-agent" {
+		t.Errorf("expected own event to keep client info, got %q %q", events[0].IP, events[0].UserAgent)
+	}
+	if events[1].IP != "" || events[1].UserAgent != "" {
+		t.Errorf("expected another user's client info to be hidden, got %q %q", events[1].IP, events[1].UserAgent)
+	}
+	if events[2].IP != "192.0.2.3" || events[2].UserAgent != "login-agent" {
+		t.Errorf("expected anonymous event to keep client info, got %q %q", events[2].IP, events[2].UserAgent)
+	}
+}
@@ -0,0 +1,13 @@
+package audit
+
+import (
+	"context"
+	"server/internal/domain"
+)
+
+type AuditRepository interface {
+	AppendEvent(ctx context.Context, event *domain.AuditEvent) error
+	ListEvents(ctx context.Context, query domain.AuditQuery) ([]*domain.AuditEvent, error)
+	ListEventsAfterID(ctx context.Context, afterID int64, limit int) ([]*domain.AuditEvent, error)
+	GetChainHead(ctx context.Context) (string, error)
+}
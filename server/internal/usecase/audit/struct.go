@@ -0,0 +1,15 @@
+package audit
+
+import "log/slog"
+
+type UseCase struct {
+	logger    *slog.Logger
+	auditRepo AuditRepository
+}
+
+func NewUseCase(logger *slog.Logger, auditRepo AuditRepository) *UseCase {
+	return &UseCase{
+		logger:    logger,
+		auditRepo: auditRepo,
+	}
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"server/internal/domain"
+	"server/internal/pkg/tracing"
+)
+
+func auditFailureReason(err error) string {
+	switch {
+	case errors.Is(err, domain.ErrNotValidEmail):
+		return "invalid_email"
+	case errors.Is(err, domain.ErrInvalidPassword):
+		return "invalid_password"
+	case errors.Is(err, domain.ErrUserNotExists):
+		return "user_not_exists"
+	case errors.Is(err, domain.ErrUserAlreadyExists):
+		return "user_already_exists"
+	case errors.Is(err, domain.ErrInvalidGoogleCode):
+		return "invalid_google_code"
+	case errors.Is(err, domain.ErrInviteCodeRequired):
+		return "invite_code_required"
+	case errors.Is(err, domain.ErrInvalidInviteCode):
+		return "invalid_invite_code"
+	case errors.Is(err, domain.ErrEmailDomainNotAllowed):
+		return "email_domain_not_allowed"
+	case errors.Is(err, domain.ErrEmailNotVerified):
+		return "email_not_verified"
+	case errors.Is(err, domain.ErrRefreshTokenReused):
+		return "refresh_token_reused"
+	default:
+		return "internal_error"
+	}
+}
+
+func (uc *UseCase) recordSuccess(ctx context.Context, eventType domain.AuditEventType, userID *int64, details map[string]string) {
+	uc.observe(eventType, domain.AuditOutcomeSuccess, "")
+	uc.auditUC.Record(ctx, domain.AuditEvent{
+		ActorUserID:   userID,
+		SubjectUserID: userID,
+		Type:          eventType,
+		Outcome:       domain.AuditOutcomeSuccess,
+		Details:       details,
+	})
+}
+
+func (uc *UseCase) recordFailure(ctx context.Context, eventType domain.AuditEventType, subjectID *int64, err error, details map[string]string) {
+	if details == nil {
+		details = make(map[string]string, 1)
+	}
+	details["reason"] = auditFailureReason(err)
+	uc.observe(eventType, domain.AuditOutcomeFailure, details["reason"])
+	uc.auditUC.Record(ctx, domain.AuditEvent{
+		SubjectUserID: subjectID,
+		Type:          eventType,
+		Outcome:       domain.AuditOutcomeFailure,
+		Details:       details,
+	})
+}
+
+// observe counts login and sign-up outcomes; other events are only audited.
+func (uc *UseCase) observe(eventType domain.AuditEventType, outcome domain.AuditOutcome, reason string) {
+	switch eventType {
+	case domain.AuditEventLogInEmail:
+		uc.metrics.ObserveLogin(domain.AuthMethodPassword, string(outcome), reason)
+	case domain.AuditEventLogInGoogle:
+		uc.metrics.ObserveLogin(domain.AuthMethodGoogle, string(outcome), reason)
+	case domain.AuditEventSignUpEmail:
+		uc.metrics.ObserveSignUp("email", string(outcome), reason)
+	case domain.AuditEventSignUpGoogle:
+		uc.metrics.ObserveSignUp("google", string(outcome), reason)
+	}
+}
+
+func (uc *UseCase) IsAdmin(ctx context.Context, userID int64) (bool, error) {
+	ctx, span := tracing.Start(ctx, "auth.IsAdmin")
+	defer span.End()
+
+	return uc.userRepo.IsUserAdmin(ctx, userID)
+}
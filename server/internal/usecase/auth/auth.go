@@ -3,22 +3,23 @@ package auth
 import (
 	"context"
 	"fmt"
-	"regexp"
 	"server/internal/domain"
+	"server/internal/pkg/tracing"
+	"server/internal/pkg/validation"
 	"time"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
-var emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+// SignUpWithEmail creates a password account. inviteCode is only needed
+// while sign-up is invite-only.
+func (uc *UseCase) SignUpWithEmail(ctx context.Context, email, password, inviteCode string) error {
+	ctx, span := tracing.Start(ctx, "auth.SignUpWithEmail")
+	defer span.End()
 
-func validateEmail(email string) bool {
-	return emailRegex.MatchString(email)
-}
-
-func (uc *UseCase) SignUpWithEmail(ctx context.Context, email, password string) error {
-	if !validateEmail(email) {
+	if !validation.IsValidEmail(email) {
+		uc.recordFailure(ctx, domain.AuditEventSignUpEmail, nil, domain.ErrNotValidEmail, map[string]string{"email": email})
 		return domain.ErrNotValidEmail
 	}
 
@@ -27,14 +28,23 @@ func (uc *UseCase) SignUpWithEmail(ctx context.Context, email, password string)
 		return fmt.Errorf("failed to generate password hash: %w", err)
 	}
 
+	release, err := uc.admitSignUp(ctx, email, inviteCode)
+	if err != nil {
+		uc.recordFailure(ctx, domain.AuditEventSignUpEmail, nil, err, map[string]string{"email": email})
+		return err
+	}
+
 	err = uc.userRepo.CreateUserWithCredentials(ctx, domain.Credentials{
 		Email:    email,
 		Password: string(hash),
 	})
 	if err != nil {
+		release()
+		uc.recordFailure(ctx, domain.AuditEventSignUpEmail, nil, err, map[string]string{"email": email})
 		return fmt.Errorf("failed to create user with credentials: %w", err)
 	}
 
+	uc.recordSuccess(ctx, domain.AuditEventSignUpEmail, nil, map[string]string{"email": email})
 	return nil
 }
 
@@ -42,80 +52,218 @@ func checkPassword(password, hash string) bool {
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
 }
 
-func (uc *UseCase) createSession(ctx context.Context, userID int64) (*domain.Session, error) {
+// createSession starts a browser session, or with bearer a session for an
+// API client. Both live in the same store, so logging out, deleting the
+// account and every other revocation applies to them alike.
+func (uc *UseCase) createSession(ctx context.Context, userID int64, authMethod string, bearer bool) (*domain.Session, error) {
 	token := uuid.New().String()
+	now := time.Now()
+	ttl := sessionTTL
+	if bearer {
+		ttl = uc.cfg.BearerTokenTTL
+	}
 	session := &domain.Session{
-		UserID:    userID,
-		Token:     token,
-		ExpiresAt: time.Now().Add(24 * time.Hour),
+		UserID:     userID,
+		Token:      token,
+		AuthMethod: authMethod,
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(ttl),
+		Bearer:     bearer,
 	}
 	err := uc.sessionRepo.StoreSession(ctx, session)
 	if err != nil {
 		return nil, fmt.Errorf("failed to store session: %w", err)
 	}
+	uc.cancelPendingDeletion(ctx, userID)
 	return session, nil
 }
 
+// cancelPendingDeletion implements "logging in during the grace period keeps
+// the account". It never fails the login itself.
+func (uc *UseCase) cancelPendingDeletion(ctx context.Context, userID int64) {
+	cancelled, err := uc.userRepo.CancelAccountDeletion(ctx, userID)
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "failed to cancel account deletion", "error", err, "user_id", userID)
+		return
+	}
+	if cancelled {
+		uc.logger.InfoContext(ctx, "account deletion cancelled by login", "user_id", userID)
+		uc.recordSuccess(ctx, domain.AuditEventAccountDeletionCancel, &userID, nil)
+	}
+}
+
 func (uc *UseCase) LogInWithEmail(ctx context.Context, email, password string) (*domain.Session, error) {
+	ctx, span := tracing.Start(ctx, "auth.LogInWithEmail")
+	defer span.End()
+
+	var session *domain.Session
+	if err := uc.logInWithEmail(ctx, email, password, "", uc.issueSession(&session, false)); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (uc *UseCase) LogInWithGoogle(ctx context.Context, code string) (*domain.Session, error) {
+	ctx, span := tracing.Start(ctx, "auth.LogInWithGoogle")
+	defer span.End()
+
+	var session *domain.Session
+	if err := uc.logInWithGoogle(ctx, code, "", uc.issueSession(&session, false)); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// IssueToken logs an API client in and returns a bearer session. The
+// logins are audited and counted like browser logins.
+func (uc *UseCase) IssueToken(ctx context.Context, grant domain.TokenGrant) (*domain.Session, error) {
+	ctx, span := tracing.Start(ctx, "auth.IssueToken")
+	defer span.End()
+
+	var session *domain.Session
+	issue := uc.issueSession(&session, true)
+	var err error
+	switch grant.Type {
+	case domain.GrantTypePassword:
+		err = uc.logInWithEmail(ctx, grant.Email, grant.Password, loginTokenBearer, issue)
+	case domain.GrantTypeGoogle:
+		err = uc.logInWithGoogle(ctx, grant.Code, loginTokenBearer, issue)
+	default:
+		return nil, domain.ErrUnsupportedGrantType
+	}
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// A loginIssuer hands out what a login grants once the user is known: a
+// browser or bearer session, or a JWT pair. Its errors fail the login.
+type loginIssuer func(ctx context.Context, user *domain.User, authMethod string) error
+
+func (uc *UseCase) issueSession(session **domain.Session, bearer bool) loginIssuer {
+	return func(ctx context.Context, user *domain.User, authMethod string) error {
+		issued, err := uc.createSession(ctx, user.ID, authMethod, bearer)
+		if err != nil {
+			return fmt.Errorf("failed to create session: %w", err)
+		}
+		*session = issued
+		return nil
+	}
+}
+
+// What a login hands out besides a browser session, recorded as the token
+// audit detail.
+const (
+	loginTokenBearer = "bearer"
+	loginTokenJWT    = "jwt"
+)
+
+func (uc *UseCase) logInWithEmail(ctx context.Context, email, password, token string, issue loginIssuer) error {
 	user, err := uc.userRepo.GetUserByEmail(ctx, email)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user by email: %w", err)
+		uc.recordFailure(ctx, domain.AuditEventLogInEmail, nil, err, loginDetails(token, map[string]string{"email": email}))
+		return fmt.Errorf("failed to get user by email: %w", err)
 	}
 
 	if !checkPassword(password, user.Password) {
-		return nil, domain.ErrInvalidPassword
+		uc.recordFailure(ctx, domain.AuditEventLogInEmail, &user.ID, domain.ErrInvalidPassword, loginDetails(token, nil))
+		return domain.ErrInvalidPassword
 	}
 
-	session, err := uc.createSession(ctx, user.ID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create session: %w", err)
+	if err := issue(ctx, user, domain.AuthMethodPassword); err != nil {
+		uc.recordFailure(ctx, domain.AuditEventLogInEmail, &user.ID, err, loginDetails(token, nil))
+		return err
 	}
 
-	return session, nil
+	uc.recordSuccess(ctx, domain.AuditEventLogInEmail, &user.ID, loginDetails(token, nil))
+	return nil
 }
 
-func (uc *UseCase) LogInWithGoogle(ctx context.Context, code string) (*domain.Session, error) {
+func (uc *UseCase) logInWithGoogle(ctx context.Context, code, token string, issue loginIssuer) error {
 	if code == "" {
-		return nil, domain.ErrInvalidGoogleCode
+		uc.recordFailure(ctx, domain.AuditEventLogInGoogle, nil, domain.ErrInvalidGoogleCode, loginDetails(token, nil))
+		return domain.ErrInvalidGoogleCode
 	}
 
 	userInfo, err := uc.oauthGateway.GetOAuthUserInfo(ctx, code, "login")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get oauth user info: %w", err)
+		uc.recordFailure(ctx, domain.AuditEventLogInGoogle, nil, err, loginDetails(token, nil))
+		return fmt.Errorf("failed to get oauth user info: %w", err)
 	}
 
 	user, err := uc.userRepo.GetUserByOAuthInfo(ctx, userInfo)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user by email: %w", err)
+		uc.recordFailure(ctx, domain.AuditEventLogInGoogle, nil, err, loginDetails(token, map[string]string{"email": userInfo.Email}))
+		return fmt.Errorf("failed to get user by email: %w", err)
 	}
 
-	session, err := uc.createSession(ctx, user.ID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create session: %w", err)
+	if err := issue(ctx, user, domain.AuthMethodGoogle); err != nil {
+		uc.recordFailure(ctx, domain.AuditEventLogInGoogle, &user.ID, err, loginDetails(token, nil))
+		return err
 	}
 
-	return session, nil
+	uc.recordSuccess(ctx, domain.AuditEventLogInGoogle, &user.ID, loginDetails(token, nil))
+	return nil
+}
+
+// loginDetails marks the audit details of logins that issued a token
+// instead of a browser session.
+func loginDetails(token string, details map[string]string) map[string]string {
+	if token == "" {
+		return details
+	}
+	if details == nil {
+		details = make(map[string]string, 1)
+	}
+	details["token"] = token
+	return details
 }
 
-func (uc *UseCase) SignUpWithGoogle(ctx context.Context, code string) error {
+// SignUpWithGoogle creates an account from a Google authorization code,
+// under the same sign-up policy as SignUpWithEmail. Google accounts may
+// carry an address their owner never proved, so the address must be
+// verified before it can pass the domain rules or become the account's.
+func (uc *UseCase) SignUpWithGoogle(ctx context.Context, code, inviteCode string) error {
+	ctx, span := tracing.Start(ctx, "auth.SignUpWithGoogle")
+	defer span.End()
+
 	if code == "" {
+		uc.recordFailure(ctx, domain.AuditEventSignUpGoogle, nil, domain.ErrInvalidGoogleCode, nil)
 		return domain.ErrInvalidGoogleCode
 	}
 
 	userInfo, err := uc.oauthGateway.GetOAuthUserInfo(ctx, code, "signup")
 	if err != nil {
+		uc.recordFailure(ctx, domain.AuditEventSignUpGoogle, nil, err, nil)
 		return fmt.Errorf("failed to get oauth user info: %w", err)
 	}
+	if !userInfo.EmailVerified {
+		uc.recordFailure(ctx, domain.AuditEventSignUpGoogle, nil, domain.ErrEmailNotVerified, map[string]string{"email": userInfo.Email})
+		return domain.ErrEmailNotVerified
+	}
+
+	release, err := uc.admitSignUp(ctx, userInfo.Email, inviteCode)
+	if err != nil {
+		uc.recordFailure(ctx, domain.AuditEventSignUpGoogle, nil, err, map[string]string{"email": userInfo.Email})
+		return err
+	}
 
 	err = uc.userRepo.CreateUserWithOAuthInfo(ctx, userInfo)
 	if err != nil {
+		release()
+		uc.recordFailure(ctx, domain.AuditEventSignUpGoogle, nil, err, map[string]string{"email": userInfo.Email})
 		return fmt.Errorf("failed to create user with oauth info: %w", err)
 	}
 
+	uc.recordSuccess(ctx, domain.AuditEventSignUpGoogle, nil, map[string]string{"email": userInfo.Email})
 	return nil
 }
 
 func (uc *UseCase) GetGoogleAuthURL(ctx context.Context, purpose string) (string, string, error) {
+	ctx, span := tracing.Start(ctx, "auth.GetGoogleAuthURL")
+	defer span.End()
+
 	state, err := uc.csrfUC.GetCSRFToken(ctx)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to get csrf token: %w", err)
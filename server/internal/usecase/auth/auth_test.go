@@ -15,8 +15,11 @@ import (
 type mockUserRepository struct {
 	createUserWithCredentialsFunc func(ctx context.Context, credentials domain.Credentials) error
 	getUserByEmailFunc            func(ctx context.Context, email string) (*domain.User, error)
+	getUserByIDFunc               func(ctx context.Context, userID int64) (*domain.User, error)
 	getUserByOAuthInfoFunc        func(ctx context.Context, oauthInfo *domain.OAuthUserInfo) (*domain.User, error)
 	createUserWithOAuthInfoFunc   func(ctx context.Context, oauthInfo *domain.OAuthUserInfo) error
+	isUserAdminFunc               func(ctx context.Context, userID int64) (bool, error)
+	cancelAccountDeletionFunc     func(ctx context.Context, userID int64) (bool, error)
 }
 
 func (m *mockUserRepository) CreateUserWithCredentials(ctx context.Context, credentials domain.Credentials) error {
@@ -33,6 +36,13 @@ func (m *mockUserRepository) GetUserByEmail(ctx context.Context, email string) (
 	return nil, nil
 }
 
+func (m *mockUserRepository) GetUserByID(ctx context.Context, userID int64) (*domain.User, error) {
+	if m.getUserByIDFunc != nil {
+		return m.getUserByIDFunc(ctx, userID)
+	}
+	return nil, nil
+}
+
 func (m *mockUserRepository) GetUserByOAuthInfo(ctx context.Context, oauthInfo *domain.OAuthUserInfo) (*domain.User, error) {
 	if m.getUserByOAuthInfoFunc != nil {
 		return m.getUserByOAuthInfoFunc(ctx, oauthInfo)
@@ -47,6 +57,20 @@ func (m *mockUserRepository) CreateUserWithOAuthInfo(ctx context.Context, oauthI
 	return nil
 }
 
+func (m *mockUserRepository) IsUserAdmin(ctx context.Context, userID int64) (bool, error) {
+	if m.isUserAdminFunc != nil {
+		return m.isUserAdminFunc(ctx, userID)
+	}
+	return false, nil
+}
+
+func (m *mockUserRepository) CancelAccountDeletion(ctx context.Context, userID int64) (bool, error) {
+	if m.cancelAccountDeletionFunc != nil {
+		return m.cancelAccountDeletionFunc(ctx, userID)
+	}
+	return false, nil
+}
+
 type mockSessionRepository struct {
 	storeSessionFunc  func(ctx context.Context, session *domain.Session) error
 	getSessionFunc    func(ctx context.Context, token string) (*domain.Session, error)
@@ -74,6 +98,92 @@ func (m *mockSessionRepository) DeleteSession(ctx context.Context, token string)
 	return nil
 }
 
+type mockInviteRepository struct {
+	createInviteCodeFunc  func(ctx context.Context, code *domain.InviteCode) error
+	listInviteCodesFunc   func(ctx context.Context) ([]*domain.InviteCode, error)
+	deleteInviteCodeFunc  func(ctx context.Context, id int64) error
+	redeemInviteCodeFunc  func(ctx context.Context, codeHash, email string, now time.Time) error
+	releaseInviteCodeFunc func(ctx context.Context, codeHash string) error
+}
+
+func (m *mockInviteRepository) CreateInviteCode(ctx context.Context, code *domain.InviteCode) error {
+	if m.createInviteCodeFunc != nil {
+		return m.createInviteCodeFunc(ctx, code)
+	}
+	return nil
+}
+
+func (m *mockInviteRepository) ListInviteCodes(ctx context.Context) ([]*domain.InviteCode, error) {
+	if m.listInviteCodesFunc != nil {
+		return m.listInviteCodesFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (m *mockInviteRepository) DeleteInviteCode(ctx context.Context, id int64) error {
+	if m.deleteInviteCodeFunc != nil {
+		return m.deleteInviteCodeFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *mockInviteRepository) RedeemInviteCode(ctx context.Context, codeHash, email string, now time.Time) error {
+	if m.redeemInviteCodeFunc != nil {
+		return m.redeemInviteCodeFunc(ctx, codeHash, email, now)
+	}
+	return nil
+}
+
+func (m *mockInviteRepository) ReleaseInviteCode(ctx context.Context, codeHash string) error {
+	if m.releaseInviteCodeFunc != nil {
+		return m.releaseInviteCodeFunc(ctx, codeHash)
+	}
+	return nil
+}
+
+type mockRefreshTokenRepository struct {
+	createRefreshTokenFunc         func(ctx context.Context, token *domain.RefreshToken) error
+	getRefreshTokenByHashFunc      func(ctx context.Context, tokenHash string) (*domain.RefreshToken, error)
+	useRefreshTokenFunc            func(ctx context.Context, id int64, usedAt time.Time) (bool, error)
+	revokeRefreshTokenFamilyFunc   func(ctx context.Context, familyID string, revokedAt time.Time) error
+	deleteExpiredRefreshTokensFunc func(ctx context.Context, now time.Time) (int64, error)
+}
+
+func (m *mockRefreshTokenRepository) CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error {
+	if m.createRefreshTokenFunc != nil {
+		return m.createRefreshTokenFunc(ctx, token)
+	}
+	return nil
+}
+
+func (m *mockRefreshTokenRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	if m.getRefreshTokenByHashFunc != nil {
+		return m.getRefreshTokenByHashFunc(ctx, tokenHash)
+	}
+	return nil, domain.ErrRefreshTokenNotFound
+}
+
+func (m *mockRefreshTokenRepository) UseRefreshToken(ctx context.Context, id int64, usedAt time.Time) (bool, error) {
+	if m.useRefreshTokenFunc != nil {
+		return m.useRefreshTokenFunc(ctx, id, usedAt)
+	}
+	return true, nil
+}
+
+func (m *mockRefreshTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string, revokedAt time.Time) error {
+	if m.revokeRefreshTokenFamilyFunc != nil {
+		return m.revokeRefreshTokenFamilyFunc(ctx, familyID, revokedAt)
+	}
+	return nil
+}
+
+func (m *mockRefreshTokenRepository) DeleteExpiredRefreshTokens(ctx context.Context, now time.Time) (int64, error) {
+	if m.deleteExpiredRefreshTokensFunc != nil {
+		return m.deleteExpiredRefreshTokensFunc(ctx, now)
+	}
+	return 0, nil
+}
+
 type mockOAuthGateway struct {
 	getOAuthUserInfoFunc func(ctx context.Context, code, purpose string) (*domain.OAuthUserInfo, error)
 	getGoogleAuthURLFunc func(ctx context.Context, purpose, state string) string
@@ -104,6 +214,27 @@ func (m *mockCSRFTokenGenerator) GetCSRFToken(ctx context.Context) (string, erro
 	return "", nil
 }
 
+type mockAuditRecorder struct {
+	events []domain.AuditEvent
+}
+
+func (m *mockAuditRecorder) Record(ctx context.Context, event domain.AuditEvent) {
+	m.events = append(m.events, event)
+}
+
+type mockAuthMetrics struct {
+	logins  []string
+	signups []string
+}
+
+func (m *mockAuthMetrics) ObserveLogin(method, outcome, reason string) {
+	m.logins = append(m.logins, method+"/"+outcome+"/"+reason)
+}
+
+func (m *mockAuthMetrics) ObserveSignUp(provider, outcome, reason string) {
+	m.signups = append(m.signups, provider+"/"+outcome+"/"+reason)
+}
+
 func hashPassword(password string) (string, error) {
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -164,11 +295,12 @@ func TestUseCase_SignUpWithEmail(t *testing.T) {
 			mockSessionRepo := &mockSessionRepository{}
 			mockOAuthGateway := &mockOAuthGateway{}
 			mockCSRF := &mockCSRFTokenGenerator{}
+			mockAudit := &mockAuditRecorder{}
 
 			tt.setupMocks(mockUserRepo)
 
-			uc := NewUseCase(logger, mockUserRepo, mockSessionRepo, mockOAuthGateway, mockCSRF)
-			err := uc.SignUpWithEmail(ctx, tt.email, tt.password)
+			uc := NewUseCase(logger, mockUserRepo, mockSessionRepo, &mockInviteRepository{}, &mockRefreshTokenRepository{}, mockOAuthGateway, mockCSRF, nil, mockAudit, &mockAuthMetrics{}, Config{})
+			err := uc.SignUpWithEmail(ctx, tt.email, tt.password, "")
 
 			if tt.expectedError != nil {
 				if err == nil {
@@ -257,10 +389,11 @@ func TestUseCase_LogInWithEmail(t *testing.T) {
 			mockSessionRepo := &mockSessionRepository{}
 			mockOAuthGateway := &mockOAuthGateway{}
 			mockCSRF := &mockCSRFTokenGenerator{}
+			mockAudit := &mockAuditRecorder{}
 
 			tt.setupMocks(mockUserRepo, mockSessionRepo)
 
-			uc := NewUseCase(logger, mockUserRepo, mockSessionRepo, mockOAuthGateway, mockCSRF)
+			uc := NewUseCase(logger, mockUserRepo, mockSessionRepo, &mockInviteRepository{}, &mockRefreshTokenRepository{}, mockOAuthGateway, mockCSRF, nil, mockAudit, &mockAuthMetrics{}, Config{})
 			session, err := uc.LogInWithEmail(ctx, tt.email, tt.password)
 
 			if tt.expectedError != nil {
@@ -360,10 +493,11 @@ func TestUseCase_LogInWithGoogle(t *testing.T) {
 			mockSessionRepo := &mockSessionRepository{}
 			mockOAuthGateway := &mockOAuthGateway{}
 			mockCSRF := &mockCSRFTokenGenerator{}
+			mockAudit := &mockAuditRecorder{}
 
 			tt.setupMocks(mockOAuthGateway, mockUserRepo, mockSessionRepo)
 
-			uc := NewUseCase(logger, mockUserRepo, mockSessionRepo, mockOAuthGateway, mockCSRF)
+			uc := NewUseCase(logger, mockUserRepo, mockSessionRepo, &mockInviteRepository{}, &mockRefreshTokenRepository{}, mockOAuthGateway, mockCSRF, nil, mockAudit, &mockAuthMetrics{}, Config{})
 			session, err := uc.LogInWithGoogle(ctx, tt.code)
 
 			if tt.expectedError != nil {
@@ -410,9 +544,10 @@ func TestUseCase_SignUpWithGoogle(t *testing.T) {
 			setupMocks: func(mo *mockOAuthGateway, mu *mockUserRepository) {
 				mo.getOAuthUserInfoFunc = func(ctx context.Context, code, purpose string) (*domain.OAuthUserInfo, error) {
 					return &domain.OAuthUserInfo{
-						Email:        "test@example.com",
-						ProviderName: "google",
-						Sub:          "123456",
+						Email:         "test@example.com",
+						EmailVerified: true,
+						ProviderName:  "google",
+						Sub:           "123456",
 					}, nil
 				}
 				mu.createUserWithOAuthInfoFunc = func(ctx context.Context, oauthInfo *domain.OAuthUserInfo) error {
@@ -434,9 +569,10 @@ func TestUseCase_SignUpWithGoogle(t *testing.T) {
 			setupMocks: func(mo *mockOAuthGateway, mu *mockUserRepository) {
 				mo.getOAuthUserInfoFunc = func(ctx context.Context, code, purpose string) (*domain.OAuthUserInfo, error) {
 					return &domain.OAuthUserInfo{
-						Email:        "test@example.com",
-						ProviderName: "google",
-						Sub:          "123456",
+						Email:         "test@example.com",
+						EmailVerified: true,
+						ProviderName:  "google",
+						Sub:           "123456",
 					}, nil
 				}
 				mu.createUserWithOAuthInfoFunc = func(ctx context.Context, oauthInfo *domain.OAuthUserInfo) error {
@@ -453,11 +589,12 @@ func TestUseCase_SignUpWithGoogle(t *testing.T) {
 			mockSessionRepo := &mockSessionRepository{}
 			mockOAuthGateway := &mockOAuthGateway{}
 			mockCSRF := &mockCSRFTokenGenerator{}
+			mockAudit := &mockAuditRecorder{}
 
 			tt.setupMocks(mockOAuthGateway, mockUserRepo)
 
-			uc := NewUseCase(logger, mockUserRepo, mockSessionRepo, mockOAuthGateway, mockCSRF)
-			err := uc.SignUpWithGoogle(ctx, tt.code)
+			uc := NewUseCase(logger, mockUserRepo, mockSessionRepo, &mockInviteRepository{}, &mockRefreshTokenRepository{}, mockOAuthGateway, mockCSRF, nil, mockAudit, &mockAuthMetrics{}, Config{})
+			err := uc.SignUpWithGoogle(ctx, tt.code, "")
 
 			if tt.expectedError != nil {
 				if err == nil {
@@ -521,10 +658,11 @@ func TestUseCase_GetGoogleAuthURL(t *testing.T) {
 			mockSessionRepo := &mockSessionRepository{}
 			mockOAuthGateway := &mockOAuthGateway{}
 			mockCSRF := &mockCSRFTokenGenerator{}
+			mockAudit := &mockAuditRecorder{}
 
 			tt.setupMocks(mockCSRF, mockOAuthGateway)
 
-			uc := NewUseCase(logger, mockUserRepo, mockSessionRepo, mockOAuthGateway, mockCSRF)
+			uc := NewUseCase(logger, mockUserRepo, mockSessionRepo, &mockInviteRepository{}, &mockRefreshTokenRepository{}, mockOAuthGateway, mockCSRF, nil, mockAudit, &mockAuthMetrics{}, Config{})
 			url, state, err := uc.GetGoogleAuthURL(ctx, tt.purpose)
 
 			if tt.expectedError != nil {
@@ -601,10 +739,11 @@ func TestUseCase_LogOut(t *testing.T) {
 			mockSessionRepo := &mockSessionRepository{}
 			mockOAuthGateway := &mockOAuthGateway{}
 			mockCSRF := &mockCSRFTokenGenerator{}
+			mockAudit := &mockAuditRecorder{}
 
 			tt.setupMocks(mockSessionRepo)
 
-			uc := NewUseCase(logger, mockUserRepo, mockSessionRepo, mockOAuthGateway, mockCSRF)
+			uc := NewUseCase(logger, mockUserRepo, mockSessionRepo, &mockInviteRepository{}, &mockRefreshTokenRepository{}, mockOAuthGateway, mockCSRF, nil, mockAudit, &mockAuthMetrics{}, Config{})
 			err := uc.LogOut(ctx, tt.session)
 
 			if tt.expectedError != nil {
@@ -619,3 +758,110 @@ func TestUseCase_LogOut(t *testing.T) {
 		})
 	}
 }
+
+func TestUseCase_LogInWithEmail_RecordsAuditEvent(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name            string
+		password        string
+		expectedOutcome domain.AuditOutcome
+		expectedReason  string
+	}{
+		{
+			name:            "successful login",
+			password:        "password123",
+			expectedOutcome: domain.AuditOutcomeSuccess,
+			expectedReason:  "",
+		},
+		{
+			name:            "invalid password",
+			password:        "wrongpassword",
+			expectedOutcome: domain.AuditOutcomeFailure,
+			expectedReason:  "invalid_password",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashedPassword, _ := hashPassword("password123")
+			mockUserRepo := &mockUserRepository{
+				getUserByEmailFunc: func(ctx context.Context, email string) (*domain.User, error) {
+					return &domain.User{ID: 1, Email: email, Password: hashedPassword}, nil
+				},
+			}
+			mockAudit := &mockAuditRecorder{}
+			mockMetrics := &mockAuthMetrics{}
+
+			uc := NewUseCase(logger, mockUserRepo, &mockSessionRepository{}, &mockInviteRepository{}, &mockRefreshTokenRepository{}, &mockOAuthGateway{}, &mockCSRFTokenGenerator{}, nil, mockAudit, mockMetrics, Config{})
+			_, _ = uc.LogInWithEmail(ctx, "test@example.com", tt.password)
+
+			expectedLogin := "password/" + string(tt.expectedOutcome) + "/" + tt.expectedReason
+			if len(mockMetrics.logins) != 1 || mockMetrics.logins[0] != expectedLogin {
+				t.Errorf("expected login metric %q, got %q", expectedLogin, mockMetrics.logins)
+			}
+
+			if len(mockAudit.events) != 1 {
+				t.Fatalf("expected 1 audit event, got %d", len(mockAudit.events))
+			}
+			event := mockAudit.events[0]
+			if event.Type != domain.AuditEventLogInEmail {
+				t.Errorf("expected type %s, got %s", domain.AuditEventLogInEmail, event.Type)
+			}
+			if event.Outcome != tt.expectedOutcome {
+				t.Errorf("expected outcome %s, got %s", tt.expectedOutcome, event.Outcome)
+			}
+			if event.SubjectUserID == nil || *event.SubjectUserID != 1 {
+				t.Errorf("expected subject user 1, got %v", event.SubjectUserID)
+			}
+			if event.Details["reason"] != tt.expectedReason {
+				t.Errorf("expected reason %q, got %q", tt.expectedReason, event.Details["reason"])
+			}
+		})
+	}
+}
+
+func TestUseCase_LogInWithEmail_CancelsPendingDeletion(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	hashedPassword, _ := hashPassword("password123")
+	cancelCalled := false
+	mockUserRepo := &mockUserRepository{
+		getUserByEmailFunc: func(ctx context.Context, email string) (*domain.User, error) {
+			return &domain.User{ID: 1, Email: email, Password: hashedPassword}, nil
+		},
+		cancelAccountDeletionFunc: func(ctx context.Context, userID int64) (bool, error) {
+			cancelCalled = true
+			if userID != 1 {
+				t.Errorf("expected userID 1, got %d", userID)
+			}
+			return true, nil
+		},
+	}
+	mockAudit := &mockAuditRecorder{}
+
+	uc := NewUseCase(logger, mockUserRepo, &mockSessionRepository{}, &mockInviteRepository{}, &mockRefreshTokenRepository{}, &mockOAuthGateway{}, &mockCSRFTokenGenerator{}, nil, mockAudit, &mockAuthMetrics{}, Config{})
+	session, err := uc.LogInWithEmail(ctx, "test@example.com", "password123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !cancelCalled {
+		t.Error("expected pending deletion to be cancelled")
+	}
+	if session.AuthMethod != domain.AuthMethodPassword {
+		t.Errorf("expected auth method %s, got %s", domain.AuthMethodPassword, session.AuthMethod)
+	}
+
+	found := false
+	for _, event := range mockAudit.events {
+		if event.Type == domain.AuditEventAccountDeletionCancel {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected deletion cancel audit event")
+	}
+}
@@ -3,13 +3,26 @@ package auth
 import (
 	"context"
 	"server/internal/domain"
+	"server/internal/pkg/jwt"
+	"time"
 )
 
 type UserRepository interface {
 	CreateUserWithCredentials(ctx context.Context, credentials domain.Credentials) error
 	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
+	GetUserByID(ctx context.Context, userID int64) (*domain.User, error)
 	GetUserByOAuthInfo(ctx context.Context, oauthInfo *domain.OAuthUserInfo) (*domain.User, error)
 	CreateUserWithOAuthInfo(ctx context.Context, oauthInfo *domain.OAuthUserInfo) error
+	IsUserAdmin(ctx context.Context, userID int64) (bool, error)
+	CancelAccountDeletion(ctx context.Context, userID int64) (bool, error)
+}
+
+type InviteRepository interface {
+	CreateInviteCode(ctx context.Context, code *domain.InviteCode) error
+	ListInviteCodes(ctx context.Context) ([]*domain.InviteCode, error)
+	DeleteInviteCode(ctx context.Context, id int64) error
+	RedeemInviteCode(ctx context.Context, codeHash, email string, now time.Time) error
+	ReleaseInviteCode(ctx context.Context, codeHash string) error
 }
 
 type SessionRepository interface {
@@ -18,6 +31,14 @@ type SessionRepository interface {
 	DeleteSession(ctx context.Context, token string) error
 }
 
+type RefreshTokenRepository interface {
+	CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error)
+	UseRefreshToken(ctx context.Context, id int64, usedAt time.Time) (bool, error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string, revokedAt time.Time) error
+	DeleteExpiredRefreshTokens(ctx context.Context, now time.Time) (int64, error)
+}
+
 type OAuthGateway interface {
 	GetOAuthUserInfo(ctx context.Context, code, purpose string) (*domain.OAuthUserInfo, error)
 	GetGoogleAuthURL(ctx context.Context, purpose, state string) string
@@ -26,3 +47,19 @@ type OAuthGateway interface {
 type CSRFTokenGenerator interface {
 	GetCSRFToken(ctx context.Context) (string, error)
 }
+
+// TokenSigner signs JWT access tokens and lists the keys to verify them
+// with.
+type TokenSigner interface {
+	Sign(claims map[string]any) (string, error)
+	JWKS() jwt.JWKS
+}
+
+type AuditRecorder interface {
+	Record(ctx context.Context, event domain.AuditEvent)
+}
+
+type AuthMetrics interface {
+	ObserveLogin(method, outcome, reason string)
+	ObserveSignUp(provider, outcome, reason string)
+}
@@ -0,0 +1,239 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"server/internal/domain"
+	"server/internal/pkg/jwt"
+	"server/internal/pkg/tracing"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// registeredClaims are set by the issuer; configured claims of the same
+// name are dropped.
+var registeredClaims = []string{"iss", "sub", "aud", "exp", "nbf", "iat", "jti", "email"}
+
+// IssueJWT logs an API client in, or refreshes its login, and returns a
+// signed access token with a refresh token. Unlike bearer sessions, the
+// access tokens are not stored: services check them against the keys from
+// JWKS and cannot revoke them, so they are short-lived.
+func (uc *UseCase) IssueJWT(ctx context.Context, grant domain.TokenGrant) (*domain.TokenPair, error) {
+	ctx, span := tracing.Start(ctx, "auth.IssueJWT")
+	defer span.End()
+
+	if uc.signer == nil {
+		return nil, domain.ErrJWTDisabled
+	}
+
+	var pair *domain.TokenPair
+	issue := func(ctx context.Context, user *domain.User, authMethod string) error {
+		issued, err := uc.issueTokenPair(ctx, user, authMethod, uuid.New().String())
+		if err != nil {
+			return err
+		}
+		uc.cancelPendingDeletion(ctx, user.ID)
+		pair = issued
+		return nil
+	}
+
+	var err error
+	switch grant.Type {
+	case domain.GrantTypePassword:
+		err = uc.logInWithEmail(ctx, grant.Email, grant.Password, loginTokenJWT, issue)
+	case domain.GrantTypeGoogle:
+		err = uc.logInWithGoogle(ctx, grant.Code, loginTokenJWT, issue)
+	case domain.GrantTypeRefreshToken:
+		return uc.refreshJWT(ctx, grant.RefreshToken)
+	default:
+		return nil, domain.ErrUnsupportedGrantType
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// refreshJWT uses up a refresh token and issues the next pair of its
+// family. A token that was already used revokes the family.
+func (uc *UseCase) refreshJWT(ctx context.Context, value string) (*domain.TokenPair, error) {
+	if value == "" {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+	token, err := uc.refreshTokenRepo.GetRefreshTokenByHash(ctx, hashRefreshToken(value))
+	if errors.Is(err, domain.ErrRefreshTokenNotFound) {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	now := time.Now()
+	if token.RevokedAt != nil || token.IsExpired(now) {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+	if token.UsedAt != nil {
+		return nil, uc.revokeReusedFamily(ctx, token, now)
+	}
+	used, err := uc.refreshTokenRepo.UseRefreshToken(ctx, token.ID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to use refresh token: %w", err)
+	}
+	if !used {
+		return nil, uc.revokeReusedFamily(ctx, token, now)
+	}
+
+	user, err := uc.userRepo.GetUserByID(ctx, token.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by id: %w", err)
+	}
+	return uc.issueTokenPair(ctx, user, token.AuthMethod, token.FamilyID)
+}
+
+// revokeReusedFamily ends a family after one of its used tokens came back.
+// Either the client or someone who stole the token holds a copy and there
+// is no telling which, so both have to log in again.
+func (uc *UseCase) revokeReusedFamily(ctx context.Context, token *domain.RefreshToken, now time.Time) error {
+	if err := uc.refreshTokenRepo.RevokeRefreshTokenFamily(ctx, token.FamilyID, now); err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+	uc.logger.WarnContext(ctx, "refresh token reused, family revoked", "user_id", token.UserID, "family_id", token.FamilyID)
+	uc.recordFailure(ctx, domain.AuditEventRefreshTokenReuse, &token.UserID, domain.ErrRefreshTokenReused, map[string]string{"family_id": token.FamilyID})
+	return domain.ErrRefreshTokenReused
+}
+
+// RevokeRefreshToken logs a JWT client out by revoking the family of its
+// refresh token. Access tokens already issued stay valid until they expire.
+func (uc *UseCase) RevokeRefreshToken(ctx context.Context, value string) error {
+	ctx, span := tracing.Start(ctx, "auth.RevokeRefreshToken")
+	defer span.End()
+
+	if uc.signer == nil {
+		return domain.ErrJWTDisabled
+	}
+	if value == "" {
+		return domain.ErrInvalidRefreshToken
+	}
+	token, err := uc.refreshTokenRepo.GetRefreshTokenByHash(ctx, hashRefreshToken(value))
+	if errors.Is(err, domain.ErrRefreshTokenNotFound) {
+		return domain.ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get refresh token: %w", err)
+	}
+	if token.RevokedAt != nil {
+		return nil
+	}
+
+	if err := uc.refreshTokenRepo.RevokeRefreshTokenFamily(ctx, token.FamilyID, time.Now()); err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+	uc.recordSuccess(ctx, domain.AuditEventRefreshTokenRevoke, &token.UserID, map[string]string{"family_id": token.FamilyID})
+	return nil
+}
+
+// JWKS returns the public keys access tokens are signed with. The set is
+// empty when the issuer is turned off.
+func (uc *UseCase) JWKS() jwt.JWKS {
+	if uc.signer == nil {
+		return jwt.JWKS{Keys: []jwt.JWK{}}
+	}
+	return uc.signer.JWKS()
+}
+
+// PurgeExpiredRefreshTokens drops refresh tokens past their expiry.
+func (uc *UseCase) PurgeExpiredRefreshTokens(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "auth.PurgeExpiredRefreshTokens")
+	defer span.End()
+
+	deleted, err := uc.refreshTokenRepo.DeleteExpiredRefreshTokens(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to delete expired refresh tokens: %w", err)
+	}
+	if deleted > 0 {
+		uc.logger.InfoContext(ctx, "purged expired refresh tokens", "count", deleted)
+	}
+	return nil
+}
+
+func (uc *UseCase) issueTokenPair(ctx context.Context, user *domain.User, authMethod, familyID string) (*domain.TokenPair, error) {
+	now := time.Now()
+	accessExpiresAt := now.Add(uc.cfg.JWT.AccessTokenTTL)
+	accessToken, err := uc.signer.Sign(uc.accessTokenClaims(user, now, accessExpiresAt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign access token: %w", err)
+	}
+
+	value, err := newRefreshToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	refreshToken := &domain.RefreshToken{
+		FamilyID:   familyID,
+		UserID:     user.ID,
+		TokenHash:  hashRefreshToken(value),
+		AuthMethod: authMethod,
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(uc.cfg.JWT.RefreshTokenTTL),
+	}
+	if err := uc.refreshTokenRepo.CreateRefreshToken(ctx, refreshToken); err != nil {
+		return nil, fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	return &domain.TokenPair{
+		UserID:                user.ID,
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessExpiresAt,
+		RefreshToken:          value,
+		RefreshTokenExpiresAt: refreshToken.ExpiresAt,
+	}, nil
+}
+
+func (uc *UseCase) accessTokenClaims(user *domain.User, issuedAt, expiresAt time.Time) map[string]any {
+	claims := make(map[string]any, len(uc.cfg.JWT.Claims)+len(registeredClaims))
+	maps.Copy(claims, uc.cfg.JWT.Claims)
+	for _, name := range registeredClaims {
+		delete(claims, name)
+	}
+
+	if uc.cfg.JWT.Issuer != "" {
+		claims["iss"] = uc.cfg.JWT.Issuer
+	}
+	switch len(uc.cfg.JWT.Audience) {
+	case 0:
+	case 1:
+		claims["aud"] = uc.cfg.JWT.Audience[0]
+	default:
+		claims["aud"] = uc.cfg.JWT.Audience
+	}
+	claims["sub"] = strconv.FormatInt(user.ID, 10)
+	claims["iat"] = issuedAt.Unix()
+	claims["exp"] = expiresAt.Unix()
+	claims["jti"] = uuid.New().String()
+	if uc.cfg.JWT.IncludeEmail {
+		claims["email"] = user.Email
+	}
+	return claims
+}
+
+// newRefreshToken returns 256 random bits.
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,364 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"server/internal/domain"
+	"server/internal/pkg/jwt"
+	"slices"
+	"testing"
+	"time"
+)
+
+type mockTokenSigner struct {
+	claims []map[string]any
+}
+
+func (m *mockTokenSigner) Sign(claims map[string]any) (string, error) {
+	m.claims = append(m.claims, claims)
+	return "signed", nil
+}
+
+func (m *mockTokenSigner) JWKS() jwt.JWKS {
+	return jwt.JWKS{Keys: []jwt.JWK{{KeyID: "k1"}}}
+}
+
+func TestUseCase_IssueJWT(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	hashedPassword, _ := hashPassword("password123")
+
+	tests := []struct {
+		name          string
+		grant         domain.TokenGrant
+		disabled      bool
+		expectedError error
+		expectMethod  string
+	}{
+		{
+			name:         "password grant",
+			grant:        domain.TokenGrant{Type: domain.GrantTypePassword, Email: "test@example.com", Password: "password123"},
+			expectMethod: domain.AuthMethodPassword,
+		},
+		{
+			name:          "password grant with wrong password",
+			grant:         domain.TokenGrant{Type: domain.GrantTypePassword, Email: "test@example.com", Password: "wrongpassword"},
+			expectedError: domain.ErrInvalidPassword,
+		},
+		{
+			name:         "google grant",
+			grant:        domain.TokenGrant{Type: domain.GrantTypeGoogle, Code: "code"},
+			expectMethod: domain.AuthMethodGoogle,
+		},
+		{
+			name:          "unsupported grant type",
+			grant:         domain.TokenGrant{Type: "client_credentials"},
+			expectedError: domain.ErrUnsupportedGrantType,
+		},
+		{
+			name:          "issuer not configured",
+			grant:         domain.TokenGrant{Type: domain.GrantTypePassword, Email: "test@example.com", Password: "password123"},
+			disabled:      true,
+			expectedError: domain.ErrJWTDisabled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stored *domain.RefreshToken
+			mockUserRepo := &mockUserRepository{
+				getUserByEmailFunc: func(ctx context.Context, email string) (*domain.User, error) {
+					return &domain.User{ID: 1, Email: email, Password: hashedPassword}, nil
+				},
+				getUserByOAuthInfoFunc: func(ctx context.Context, oauthInfo *domain.OAuthUserInfo) (*domain.User, error) {
+					return &domain.User{ID: 1, Email: oauthInfo.Email}, nil
+				},
+			}
+			mockRefreshRepo := &mockRefreshTokenRepository{
+				createRefreshTokenFunc: func(ctx context.Context, token *domain.RefreshToken) error {
+					stored = token
+					return nil
+				},
+			}
+			mockOAuth := &mockOAuthGateway{
+				getOAuthUserInfoFunc: func(ctx context.Context, code, purpose string) (*domain.OAuthUserInfo, error) {
+					return &domain.OAuthUserInfo{Email: "test@example.com"}, nil
+				},
+			}
+			mockAudit := &mockAuditRecorder{}
+			var signer TokenSigner = &mockTokenSigner{}
+			if tt.disabled {
+				signer = nil
+			}
+
+			uc := NewUseCase(logger, mockUserRepo, &mockSessionRepository{}, &mockInviteRepository{}, mockRefreshRepo, mockOAuth, &mockCSRFTokenGenerator{}, signer, mockAudit, &mockAuthMetrics{}, Config{
+				JWT: JWTConfig{AccessTokenTTL: time.Minute, RefreshTokenTTL: time.Hour},
+			})
+			pair, err := uc.IssueJWT(ctx, tt.grant)
+
+			if tt.expectedError != nil {
+				if !errors.Is(err, tt.expectedError) {
+					t.Errorf("expected error %v, got %v", tt.expectedError, err)
+				}
+				if pair != nil || stored != nil {
+					t.Error("expected no tokens to be issued")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if pair.AccessToken != "signed" {
+				t.Errorf("expected the signed access token, got %q", pair.AccessToken)
+			}
+			if stored == nil {
+				t.Fatal("expected a refresh token to be stored")
+			}
+			if stored.TokenHash != hashRefreshToken(pair.RefreshToken) {
+				t.Error("expected the hash of the refresh token to be stored")
+			}
+			if stored.FamilyID == "" || stored.AuthMethod != tt.expectMethod {
+				t.Errorf("unexpected refresh token %+v", stored)
+			}
+			if ttl := pair.AccessTokenExpiresAt.Sub(stored.CreatedAt); ttl != time.Minute {
+				t.Errorf("expected access token ttl 1m, got %v", ttl)
+			}
+			if ttl := pair.RefreshTokenExpiresAt.Sub(stored.CreatedAt); ttl != time.Hour {
+				t.Errorf("expected refresh token ttl 1h, got %v", ttl)
+			}
+
+			if len(mockAudit.events) != 1 {
+				t.Fatalf("expected 1 audit event, got %d", len(mockAudit.events))
+			}
+			if mockAudit.events[0].Details["token"] != "jwt" {
+				t.Errorf("expected jwt token detail, got %v", mockAudit.events[0].Details)
+			}
+		})
+	}
+}
+
+func TestUseCase_IssueJWT_RefreshToken(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	now := time.Now()
+	earlier := now.Add(-time.Minute)
+
+	tests := []struct {
+		name          string
+		token         *domain.RefreshToken
+		alreadyUsed   bool
+		expectedError error
+		expectRevoke  bool
+	}{
+		{
+			name:  "rotates the token",
+			token: &domain.RefreshToken{ID: 3, FamilyID: "family", UserID: 1, AuthMethod: domain.AuthMethodPassword, ExpiresAt: now.Add(time.Hour)},
+		},
+		{
+			name:          "unknown token",
+			expectedError: domain.ErrInvalidRefreshToken,
+		},
+		{
+			name:          "expired token",
+			token:         &domain.RefreshToken{ID: 3, FamilyID: "family", UserID: 1, ExpiresAt: earlier},
+			expectedError: domain.ErrInvalidRefreshToken,
+		},
+		{
+			name:          "revoked family",
+			token:         &domain.RefreshToken{ID: 3, FamilyID: "family", UserID: 1, ExpiresAt: now.Add(time.Hour), UsedAt: &earlier, RevokedAt: &earlier},
+			expectedError: domain.ErrInvalidRefreshToken,
+		},
+		{
+			name:          "reused token revokes the family",
+			token:         &domain.RefreshToken{ID: 3, FamilyID: "family", UserID: 1, ExpiresAt: now.Add(time.Hour), UsedAt: &earlier},
+			expectedError: domain.ErrRefreshTokenReused,
+			expectRevoke:  true,
+		},
+		{
+			name:          "concurrent refresh revokes the family",
+			token:         &domain.RefreshToken{ID: 3, FamilyID: "family", UserID: 1, ExpiresAt: now.Add(time.Hour)},
+			alreadyUsed:   true,
+			expectedError: domain.ErrRefreshTokenReused,
+			expectRevoke:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stored *domain.RefreshToken
+			var usedID int64
+			var revoked string
+			mockRefreshRepo := &mockRefreshTokenRepository{
+				getRefreshTokenByHashFunc: func(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+					if tokenHash != hashRefreshToken("refresh") {
+						t.Errorf("expected the hash of the token, got %s", tokenHash)
+					}
+					if tt.token == nil {
+						return nil, domain.ErrRefreshTokenNotFound
+					}
+					return tt.token, nil
+				},
+				useRefreshTokenFunc: func(ctx context.Context, id int64, usedAt time.Time) (bool, error) {
+					usedID = id
+					return !tt.alreadyUsed, nil
+				},
+				revokeRefreshTokenFamilyFunc: func(ctx context.Context, familyID string, revokedAt time.Time) error {
+					revoked = familyID
+					return nil
+				},
+				createRefreshTokenFunc: func(ctx context.Context, token *domain.RefreshToken) error {
+					stored = token
+					return nil
+				},
+			}
+			mockUserRepo := &mockUserRepository{
+				getUserByIDFunc: func(ctx context.Context, userID int64) (*domain.User, error) {
+					return &domain.User{ID: userID, Email: "test@example.com"}, nil
+				},
+			}
+			mockAudit := &mockAuditRecorder{}
+
+			uc := NewUseCase(logger, mockUserRepo, &mockSessionRepository{}, &mockInviteRepository{}, mockRefreshRepo, &mockOAuthGateway{}, &mockCSRFTokenGenerator{}, &mockTokenSigner{}, mockAudit, &mockAuthMetrics{}, Config{})
+			pair, err := uc.IssueJWT(ctx, domain.TokenGrant{Type: domain.GrantTypeRefreshToken, RefreshToken: "refresh"})
+
+			if (revoked == "family") != tt.expectRevoke {
+				t.Errorf("expected family revoked %v, got %q", tt.expectRevoke, revoked)
+			}
+			if tt.expectedError != nil {
+				if !errors.Is(err, tt.expectedError) {
+					t.Errorf("expected error %v, got %v", tt.expectedError, err)
+				}
+				if pair != nil || stored != nil {
+					t.Error("expected no tokens to be issued")
+				}
+				if tt.expectRevoke && (len(mockAudit.events) != 1 || mockAudit.events[0].Type != domain.AuditEventRefreshTokenReuse) {
+					t.Errorf("expected a reuse audit event, got %v", mockAudit.events)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if usedID != 3 {
+				t.Errorf("expected token 3 to be used up, got %d", usedID)
+			}
+			if stored == nil || stored.FamilyID != "family" || stored.AuthMethod != domain.AuthMethodPassword {
+				t.Errorf("expected the next token of the family, got %+v", stored)
+			}
+			if pair.RefreshToken == "refresh" {
+				t.Error("expected a new refresh token")
+			}
+		})
+	}
+}
+
+func TestUseCase_AccessTokenClaims(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	issuedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	user := &domain.User{ID: 42, Email: "test@example.com"}
+
+	tests := []struct {
+		name        string
+		cfg         JWTConfig
+		expectAud   any
+		expectEmail bool
+	}{
+		{
+			name: "without audience or email",
+		},
+		{
+			name:      "single audience",
+			cfg:       JWTConfig{Audience: []string{"billing"}},
+			expectAud: "billing",
+		},
+		{
+			name:        "several audiences and email",
+			cfg:         JWTConfig{Audience: []string{"billing", "reports"}, IncludeEmail: true},
+			expectAud:   []string{"billing", "reports"},
+			expectEmail: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Issuer = "https://auth.example.com"
+			tt.cfg.Claims = map[string]any{"tenant": "acme", "sub": "admin", "email": "admin@example.com"}
+
+			uc := NewUseCase(logger, &mockUserRepository{}, &mockSessionRepository{}, &mockInviteRepository{}, &mockRefreshTokenRepository{}, &mockOAuthGateway{}, &mockCSRFTokenGenerator{}, &mockTokenSigner{}, &mockAuditRecorder{}, &mockAuthMetrics{}, Config{JWT: tt.cfg})
+			claims := uc.accessTokenClaims(user, issuedAt, issuedAt.Add(time.Minute))
+
+			if claims["iss"] != "https://auth.example.com" {
+				t.Errorf("unexpected iss %v", claims["iss"])
+			}
+			if claims["sub"] != "42" {
+				t.Errorf("expected the configured sub to be replaced, got %v", claims["sub"])
+			}
+			if claims["tenant"] != "acme" {
+				t.Errorf("expected the configured tenant claim, got %v", claims["tenant"])
+			}
+			if claims["iat"] != issuedAt.Unix() || claims["exp"] != issuedAt.Add(time.Minute).Unix() {
+				t.Errorf("unexpected iat %v or exp %v", claims["iat"], claims["exp"])
+			}
+			if claims["jti"] == "" {
+				t.Error("expected a jti")
+			}
+
+			switch aud := tt.expectAud.(type) {
+			case nil:
+				if _, ok := claims["aud"]; ok {
+					t.Errorf("expected no aud, got %v", claims["aud"])
+				}
+			case string:
+				if claims["aud"] != aud {
+					t.Errorf("expected aud %s, got %v", aud, claims["aud"])
+				}
+			case []string:
+				got, _ := claims["aud"].([]string)
+				if !slices.Equal(got, aud) {
+					t.Errorf("expected aud %v, got %v", aud, claims["aud"])
+				}
+			}
+
+			email, ok := claims["email"]
+			if ok != tt.expectEmail || (ok && email != user.Email) {
+				t.Errorf("unexpected email claim %v", email)
+			}
+		})
+	}
+}
+
+func TestUseCase_RevokeRefreshToken(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	var revoked string
+	mockRefreshRepo := &mockRefreshTokenRepository{
+		getRefreshTokenByHashFunc: func(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+			return &domain.RefreshToken{ID: 3, FamilyID: "family", UserID: 1}, nil
+		},
+		revokeRefreshTokenFamilyFunc: func(ctx context.Context, familyID string, revokedAt time.Time) error {
+			revoked = familyID
+			return nil
+		},
+	}
+	mockAudit := &mockAuditRecorder{}
+
+	uc := NewUseCase(logger, &mockUserRepository{}, &mockSessionRepository{}, &mockInviteRepository{}, mockRefreshRepo, &mockOAuthGateway{}, &mockCSRFTokenGenerator{}, &mockTokenSigner{}, mockAudit, &mockAuthMetrics{}, Config{})
+	if err := uc.RevokeRefreshToken(ctx, "refresh"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if revoked != "family" {
+		t.Errorf("expected the family to be revoked, got %q", revoked)
+	}
+	if len(mockAudit.events) != 1 || mockAudit.events[0].Type != domain.AuditEventRefreshTokenRevoke {
+		t.Errorf("expected a revoke audit event, got %v", mockAudit.events)
+	}
+}
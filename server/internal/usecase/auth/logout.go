@@ -3,8 +3,17 @@ package auth
 import (
 	"context"
 	"server/internal/domain"
+	"server/internal/pkg/tracing"
 )
 
 func (uc *UseCase) LogOut(ctx context.Context, session *domain.Session) error {
-	return uc.sessionRepo.DeleteSession(ctx, session.Token)
+	ctx, span := tracing.Start(ctx, "auth.LogOut")
+	defer span.End()
+
+	err := uc.sessionRepo.DeleteSession(ctx, session.Token)
+	if err != nil {
+		return err
+	}
+	uc.recordSuccess(ctx, domain.AuditEventLogOut, &session.UserID, nil)
+	return nil
 }
@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"server/internal/domain"
+	"server/internal/pkg/tracing"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const maxInviteNoteLength = 255
+
+// SignUpPolicy returns the configured sign-up mode so clients can ask for
+// an invite code or explain the domain restriction up front.
+func (uc *UseCase) SignUpPolicy() domain.SignUpPolicy {
+	return uc.cfg.SignUpPolicy
+}
+
+// admitSignUp enforces the sign-up policy for email. In invite-only mode it
+// redeems inviteCode and returns a release function that makes the code
+// usable again if creating the account fails afterwards.
+func (uc *UseCase) admitSignUp(ctx context.Context, email, inviteCode string) (func(), error) {
+	noop := func() {}
+
+	switch uc.cfg.SignUpPolicy.Mode {
+	case domain.SignUpModeAllowedDomains:
+		if !slices.Contains(uc.cfg.SignUpPolicy.AllowedDomains, emailDomain(email)) {
+			return nil, domain.ErrEmailDomainNotAllowed
+		}
+		return noop, nil
+	case domain.SignUpModeInviteOnly:
+		inviteCode = strings.TrimSpace(inviteCode)
+		if inviteCode == "" {
+			return nil, domain.ErrInviteCodeRequired
+		}
+		codeHash := hashInviteCode(inviteCode)
+		if err := uc.inviteRepo.RedeemInviteCode(ctx, codeHash, email, time.Now()); err != nil {
+			return nil, fmt.Errorf("failed to redeem invite code: %w", err)
+		}
+		return func() {
+			if err := uc.inviteRepo.ReleaseInviteCode(ctx, codeHash); err != nil {
+				uc.logger.ErrorContext(ctx, "failed to release invite code", "error", err)
+			}
+		}, nil
+	default:
+		return noop, nil
+	}
+}
+
+func emailDomain(email string) string {
+	at := strings.LastIndexByte(email, '@')
+	if at < 0 {
+		return ""
+	}
+	return strings.ToLower(email[at+1:])
+}
+
+// CreateInviteCode creates a single-use invite code. The code is returned
+// only here; afterwards just its hash is known.
+func (uc *UseCase) CreateInviteCode(ctx context.Context, adminID int64, note string) (string, *domain.InviteCode, error) {
+	ctx, span := tracing.Start(ctx, "auth.CreateInviteCode")
+	defer span.End()
+
+	note = strings.TrimSpace(note)
+	if utf8.RuneCountInString(note) > maxInviteNoteLength {
+		return "", nil, domain.FieldErrors{"note": fmt.Sprintf("must be at most %d characters", maxInviteNoteLength)}
+	}
+
+	code, err := newInviteCode()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate invite code: %w", err)
+	}
+
+	now := time.Now()
+	invite := &domain.InviteCode{
+		CodeHash:  hashInviteCode(code),
+		Note:      note,
+		CreatedBy: adminID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(uc.cfg.InviteCodeTTL),
+	}
+	if err := uc.inviteRepo.CreateInviteCode(ctx, invite); err != nil {
+		return "", nil, fmt.Errorf("failed to create invite code: %w", err)
+	}
+
+	uc.recordAdmin(ctx, adminID, domain.AuditEventInviteCodeCreate, invite.ID)
+	return code, invite, nil
+}
+
+func (uc *UseCase) ListInviteCodes(ctx context.Context) ([]*domain.InviteCode, error) {
+	ctx, span := tracing.Start(ctx, "auth.ListInviteCodes")
+	defer span.End()
+
+	codes, err := uc.inviteRepo.ListInviteCodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invite codes: %w", err)
+	}
+	return codes, nil
+}
+
+func (uc *UseCase) DeleteInviteCode(ctx context.Context, adminID, id int64) error {
+	ctx, span := tracing.Start(ctx, "auth.DeleteInviteCode")
+	defer span.End()
+
+	if err := uc.inviteRepo.DeleteInviteCode(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete invite code: %w", err)
+	}
+
+	uc.recordAdmin(ctx, adminID, domain.AuditEventInviteCodeDelete, id)
+	return nil
+}
+
+func (uc *UseCase) recordAdmin(ctx context.Context, adminID int64, eventType domain.AuditEventType, inviteID int64) {
+	uc.auditUC.Record(ctx, domain.AuditEvent{
+		ActorUserID: &adminID,
+		Type:        eventType,
+		Outcome:     domain.AuditOutcomeSuccess,
+		Details:     map[string]string{"invite_id": strconv.FormatInt(inviteID, 10)},
+	})
+}
+
+// newInviteCode returns 80 random bits as 16 base32 characters, short enough
+// to be typed in by hand.
+func newInviteCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.EncodeToString(b), nil
+}
+
+// hashInviteCode ignores case and surrounding space so a code typed in by
+// hand still matches.
+func hashInviteCode(code string) string {
+	sum := sha256.Sum256([]byte(strings.ToUpper(strings.TrimSpace(code))))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,214 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"server/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestUseCase_SignUpWithEmail_Policy(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		policy        domain.SignUpPolicy
+		email         string
+		inviteCode    string
+		redeemErr     error
+		createErr     error
+		expectedError error
+		expectRedeem  bool
+		expectRelease bool
+	}{
+		{
+			name:   "allowed domain",
+			policy: domain.SignUpPolicy{Mode: domain.SignUpModeAllowedDomains, AllowedDomains: []string{"example.com"}},
+			email:  "ada@Example.com",
+		},
+		{
+			name:          "other domain",
+			policy:        domain.SignUpPolicy{Mode: domain.SignUpModeAllowedDomains, AllowedDomains: []string{"example.com"}},
+			email:         "ada@mail.example.com",
+			expectedError: domain.ErrEmailDomainNotAllowed,
+		},
+		{
+			name:          "invite code missing",
+			policy:        domain.SignUpPolicy{Mode: domain.SignUpModeInviteOnly},
+			email:         "ada@example.com",
+			expectedError: domain.ErrInviteCodeRequired,
+		},
+		{
+			name:         "invite code redeemed",
+			policy:       domain.SignUpPolicy{Mode: domain.SignUpModeInviteOnly},
+			email:        "ada@example.com",
+			inviteCode:   " abcd ",
+			expectRedeem: true,
+		},
+		{
+			name:          "invite code already used",
+			policy:        domain.SignUpPolicy{Mode: domain.SignUpModeInviteOnly},
+			email:         "ada@example.com",
+			inviteCode:    "abcd",
+			redeemErr:     domain.ErrInvalidInviteCode,
+			expectedError: domain.ErrInvalidInviteCode,
+			expectRedeem:  true,
+		},
+		{
+			name:          "failed sign-up releases the invite code",
+			policy:        domain.SignUpPolicy{Mode: domain.SignUpModeInviteOnly},
+			email:         "ada@example.com",
+			inviteCode:    "abcd",
+			createErr:     domain.ErrUserAlreadyExists,
+			expectedError: domain.ErrUserAlreadyExists,
+			expectRedeem:  true,
+			expectRelease: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := false
+			redeemed := false
+			released := false
+			mockUserRepo := &mockUserRepository{
+				createUserWithCredentialsFunc: func(ctx context.Context, credentials domain.Credentials) error {
+					created = true
+					return tt.createErr
+				},
+			}
+			mockInviteRepo := &mockInviteRepository{
+				redeemInviteCodeFunc: func(ctx context.Context, codeHash, email string, now time.Time) error {
+					redeemed = true
+					if codeHash != hashInviteCode("ABCD") || email != tt.email {
+						t.Errorf("unexpected redeem of %s by %s", codeHash, email)
+					}
+					return tt.redeemErr
+				},
+				releaseInviteCodeFunc: func(ctx context.Context, codeHash string) error {
+					released = true
+					return nil
+				},
+			}
+			mockAudit := &mockAuditRecorder{}
+
+			uc := NewUseCase(logger, mockUserRepo, &mockSessionRepository{}, mockInviteRepo, &mockRefreshTokenRepository{}, &mockOAuthGateway{}, &mockCSRFTokenGenerator{}, nil, mockAudit, &mockAuthMetrics{}, Config{SignUpPolicy: tt.policy})
+			err := uc.SignUpWithEmail(ctx, tt.email, "password123", tt.inviteCode)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if redeemed != tt.expectRedeem || released != tt.expectRelease {
+				t.Errorf("expected redeem %v and release %v, got %v and %v", tt.expectRedeem, tt.expectRelease, redeemed, released)
+			}
+			if tt.expectedError != nil && tt.createErr == nil && created {
+				t.Error("expected no user to be created")
+			}
+			if len(mockAudit.events) != 1 {
+				t.Fatalf("expected one audit event, got %+v", mockAudit.events)
+			}
+		})
+	}
+}
+
+func TestUseCase_SignUpWithGoogle_AllowedDomains(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	created := false
+	mockUserRepo := &mockUserRepository{
+		createUserWithOAuthInfoFunc: func(ctx context.Context, oauthInfo *domain.OAuthUserInfo) error {
+			created = true
+			return nil
+		},
+	}
+	mockOAuth := &mockOAuthGateway{
+		getOAuthUserInfoFunc: func(ctx context.Context, code, purpose string) (*domain.OAuthUserInfo, error) {
+			return &domain.OAuthUserInfo{Email: "ada@gmail.com", EmailVerified: true, ProviderName: "google", Sub: "1"}, nil
+		},
+	}
+	mockAudit := &mockAuditRecorder{}
+
+	uc := NewUseCase(logger, mockUserRepo, &mockSessionRepository{}, &mockInviteRepository{}, &mockRefreshTokenRepository{}, mockOAuth, &mockCSRFTokenGenerator{}, nil, mockAudit, &mockAuthMetrics{},
+		Config{SignUpPolicy: domain.SignUpPolicy{Mode: domain.SignUpModeAllowedDomains, AllowedDomains: []string{"example.com"}}})
+	err := uc.SignUpWithGoogle(context.Background(), "code", "")
+
+	if !errors.Is(err, domain.ErrEmailDomainNotAllowed) {
+		t.Fatalf("expected ErrEmailDomainNotAllowed, got %v", err)
+	}
+	if created {
+		t.Error("expected no user to be created")
+	}
+	if len(mockAudit.events) != 1 || mockAudit.events[0].Details["reason"] != "email_domain_not_allowed" {
+		t.Errorf("expected a failure event, got %+v", mockAudit.events)
+	}
+}
+
+func TestUseCase_SignUpWithGoogle_UnverifiedEmail(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	created := false
+	mockUserRepo := &mockUserRepository{
+		createUserWithOAuthInfoFunc: func(ctx context.Context, oauthInfo *domain.OAuthUserInfo) error {
+			created = true
+			return nil
+		},
+	}
+	mockOAuth := &mockOAuthGateway{
+		getOAuthUserInfoFunc: func(ctx context.Context, code, purpose string) (*domain.OAuthUserInfo, error) {
+			return &domain.OAuthUserInfo{Email: "ada@example.com", EmailVerified: false, ProviderName: "google", Sub: "1"}, nil
+		},
+	}
+	mockAudit := &mockAuditRecorder{}
+
+	uc := NewUseCase(logger, mockUserRepo, &mockSessionRepository{}, &mockInviteRepository{}, &mockRefreshTokenRepository{}, mockOAuth, &mockCSRFTokenGenerator{}, nil, mockAudit, &mockAuthMetrics{},
+		Config{SignUpPolicy: domain.SignUpPolicy{Mode: domain.SignUpModeAllowedDomains, AllowedDomains: []string{"example.com"}}})
+	err := uc.SignUpWithGoogle(context.Background(), "code", "")
+
+	if !errors.Is(err, domain.ErrEmailNotVerified) {
+		t.Fatalf("expected ErrEmailNotVerified, got %v", err)
+	}
+	if created {
+		t.Error("expected no user to be created")
+	}
+	if len(mockAudit.events) != 1 || mockAudit.events[0].Details["reason"] != "email_not_verified" {
+		t.Errorf("expected a failure event, got %+v", mockAudit.events)
+	}
+}
+
+func TestUseCase_CreateInviteCode(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	var stored *domain.InviteCode
+	mockInviteRepo := &mockInviteRepository{
+		createInviteCodeFunc: func(ctx context.Context, code *domain.InviteCode) error {
+			code.ID = 3
+			stored = code
+			return nil
+		},
+	}
+	mockAudit := &mockAuditRecorder{}
+
+	uc := NewUseCase(logger, &mockUserRepository{}, &mockSessionRepository{}, mockInviteRepo, &mockRefreshTokenRepository{}, &mockOAuthGateway{}, &mockCSRFTokenGenerator{}, nil, mockAudit, &mockAuthMetrics{},
+		Config{InviteCodeTTL: time.Hour})
+	code, invite, err := uc.CreateInviteCode(context.Background(), 1, " for Ada ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(code) != 16 || invite != stored || invite.Note != "for Ada" || invite.CreatedBy != 1 {
+		t.Errorf("unexpected invite code %q: %+v", code, invite)
+	}
+	if invite.CodeHash != hashInviteCode(code) || invite.CodeHash != hashInviteCode(" "+code+" ") {
+		t.Error("expected the stored hash to match the code")
+	}
+	if got := invite.ExpiresAt.Sub(invite.CreatedAt); got != time.Hour {
+		t.Errorf("expected a one hour lifetime, got %v", got)
+	}
+	if len(mockAudit.events) != 1 || mockAudit.events[0].Type != domain.AuditEventInviteCodeCreate {
+		t.Errorf("expected an invite_code.create event, got %+v", mockAudit.events)
+	}
+}
@@ -1,21 +1,84 @@
 package auth
 
-import "log/slog"
+import (
+	"log/slog"
+	"server/internal/domain"
+	"time"
+)
+
+type Config struct {
+	SignUpPolicy domain.SignUpPolicy
+	// InviteCodeTTL is how long a new invite code can be redeemed.
+	InviteCodeTTL time.Duration
+	// BearerTokenTTL is how long a token issued to an API client is valid.
+	BearerTokenTTL time.Duration
+	JWT            JWTConfig
+}
+
+// JWTConfig shapes the access tokens minted by IssueJWT.
+type JWTConfig struct {
+	Issuer string
+	// Audience becomes the aud claim; it is left out when empty.
+	Audience        []string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	// IncludeEmail adds the user's email as the email claim.
+	IncludeEmail bool
+	// Claims are added to every access token. They cannot replace the
+	// claims the issuer sets itself.
+	Claims map[string]any
+}
+
+// sessionTTL is how long a browser session lasts.
+const sessionTTL = 24 * time.Hour
+
+const defaultBearerTokenTTL = 30 * 24 * time.Hour
+
+const (
+	defaultAccessTokenTTL  = 15 * time.Minute
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
 
 type UseCase struct {
-	logger       *slog.Logger
-	userRepo     UserRepository
-	sessionRepo  SessionRepository
-	oauthGateway OAuthGateway
-	csrfUC       CSRFTokenGenerator
+	logger           *slog.Logger
+	userRepo         UserRepository
+	sessionRepo      SessionRepository
+	inviteRepo       InviteRepository
+	refreshTokenRepo RefreshTokenRepository
+	oauthGateway     OAuthGateway
+	csrfUC           CSRFTokenGenerator
+	signer           TokenSigner
+	auditUC          AuditRecorder
+	metrics          AuthMetrics
+	cfg              Config
 }
 
-func NewUseCase(logger *slog.Logger, userRepo UserRepository, sessionRepo SessionRepository, oauthGateway OAuthGateway, csrfUC CSRFTokenGenerator) *UseCase {
+// NewUseCase returns the auth use case. signer may be nil, which turns off
+// the JWT issuer.
+func NewUseCase(logger *slog.Logger, userRepo UserRepository, sessionRepo SessionRepository, inviteRepo InviteRepository, refreshTokenRepo RefreshTokenRepository, oauthGateway OAuthGateway, csrfUC CSRFTokenGenerator, signer TokenSigner, auditUC AuditRecorder, metrics AuthMetrics, cfg Config) *UseCase {
+	if cfg.SignUpPolicy.Mode == "" {
+		cfg.SignUpPolicy.Mode = domain.SignUpModeOpen
+	}
+	if cfg.BearerTokenTTL <= 0 {
+		cfg.BearerTokenTTL = defaultBearerTokenTTL
+	}
+	if cfg.JWT.AccessTokenTTL <= 0 {
+		cfg.JWT.AccessTokenTTL = defaultAccessTokenTTL
+	}
+	if cfg.JWT.RefreshTokenTTL <= 0 {
+		cfg.JWT.RefreshTokenTTL = defaultRefreshTokenTTL
+	}
 	return &UseCase{
-		logger:       logger,
-		userRepo:     userRepo,
-		sessionRepo:  sessionRepo,
-		oauthGateway: oauthGateway,
-		csrfUC:       csrfUC,
+		logger:           logger,
+		userRepo:         userRepo,
+		sessionRepo:      sessionRepo,
+		inviteRepo:       inviteRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		oauthGateway:     oauthGateway,
+		csrfUC:           csrfUC,
+		signer:           signer,
+		auditUC:          auditUC,
+		metrics:          metrics,
+		cfg:              cfg,
 	}
 }
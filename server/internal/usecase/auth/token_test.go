@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"server/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestUseCase_IssueToken(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	hashedPassword, _ := hashPassword("password123")
+
+	tests := []struct {
+		name          string
+		grant         domain.TokenGrant
+		expectedError error
+		expectMethod  string
+	}{
+		{
+			name:         "password grant",
+			grant:        domain.TokenGrant{Type: domain.GrantTypePassword, Email: "test@example.com", Password: "password123"},
+			expectMethod: domain.AuthMethodPassword,
+		},
+		{
+			name:          "password grant with wrong password",
+			grant:         domain.TokenGrant{Type: domain.GrantTypePassword, Email: "test@example.com", Password: "wrongpassword"},
+			expectedError: domain.ErrInvalidPassword,
+		},
+		{
+			name:         "google grant",
+			grant:        domain.TokenGrant{Type: domain.GrantTypeGoogle, Code: "code"},
+			expectMethod: domain.AuthMethodGoogle,
+		},
+		{
+			name:          "google grant without code",
+			grant:         domain.TokenGrant{Type: domain.GrantTypeGoogle},
+			expectedError: domain.ErrInvalidGoogleCode,
+		},
+		{
+			name:          "unsupported grant type",
+			grant:         domain.TokenGrant{Type: "client_credentials"},
+			expectedError: domain.ErrUnsupportedGrantType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stored *domain.Session
+			mockUserRepo := &mockUserRepository{
+				getUserByEmailFunc: func(ctx context.Context, email string) (*domain.User, error) {
+					return &domain.User{ID: 1, Email: email, Password: hashedPassword}, nil
+				},
+				getUserByOAuthInfoFunc: func(ctx context.Context, oauthInfo *domain.OAuthUserInfo) (*domain.User, error) {
+					return &domain.User{ID: 1, Email: oauthInfo.Email}, nil
+				},
+			}
+			mockSessionRepo := &mockSessionRepository{
+				storeSessionFunc: func(ctx context.Context, session *domain.Session) error {
+					stored = session
+					return nil
+				},
+			}
+			mockOAuth := &mockOAuthGateway{
+				getOAuthUserInfoFunc: func(ctx context.Context, code, purpose string) (*domain.OAuthUserInfo, error) {
+					if purpose != "login" {
+						t.Errorf("expected purpose login, got %s", purpose)
+					}
+					return &domain.OAuthUserInfo{Email: "test@example.com"}, nil
+				},
+			}
+			mockAudit := &mockAuditRecorder{}
+
+			uc := NewUseCase(logger, mockUserRepo, mockSessionRepo, &mockInviteRepository{}, &mockRefreshTokenRepository{}, mockOAuth, &mockCSRFTokenGenerator{}, nil, mockAudit, &mockAuthMetrics{}, Config{
+				BearerTokenTTL: time.Hour,
+			})
+			session, err := uc.IssueToken(ctx, tt.grant)
+
+			if tt.expectedError != nil {
+				if !errors.Is(err, tt.expectedError) {
+					t.Errorf("expected error %v, got %v", tt.expectedError, err)
+				}
+				if session != nil || stored != nil {
+					t.Error("expected no session to be issued")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if session != stored {
+				t.Error("expected the issued session to be stored")
+			}
+			if !session.Bearer {
+				t.Error("expected a bearer session")
+			}
+			if session.AuthMethod != tt.expectMethod {
+				t.Errorf("expected auth method %s, got %s", tt.expectMethod, session.AuthMethod)
+			}
+			if ttl := session.ExpiresAt.Sub(session.CreatedAt); ttl != time.Hour {
+				t.Errorf("expected ttl 1h, got %v", ttl)
+			}
+
+			if len(mockAudit.events) != 1 {
+				t.Fatalf("expected 1 audit event, got %d", len(mockAudit.events))
+			}
+			if mockAudit.events[0].Details["token"] != "bearer" {
+				t.Errorf("expected bearer token detail, got %v", mockAudit.events[0].Details)
+			}
+		})
+	}
+}
+
+func TestUseCase_LogInWithEmail_IssuesBrowserSession(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	hashedPassword, _ := hashPassword("password123")
+	mockUserRepo := &mockUserRepository{
+		getUserByEmailFunc: func(ctx context.Context, email string) (*domain.User, error) {
+			return &domain.User{ID: 1, Email: email, Password: hashedPassword}, nil
+		},
+	}
+
+	uc := NewUseCase(logger, mockUserRepo, &mockSessionRepository{}, &mockInviteRepository{}, &mockRefreshTokenRepository{}, &mockOAuthGateway{}, &mockCSRFTokenGenerator{}, nil, &mockAuditRecorder{}, &mockAuthMetrics{}, Config{
+		BearerTokenTTL: time.Hour,
+	})
+	session, err := uc.LogInWithEmail(ctx, "test@example.com", "password123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if session.Bearer {
+		t.Error("expected a browser session")
+	}
+	if ttl := session.ExpiresAt.Sub(session.CreatedAt); ttl != sessionTTL {
+		t.Errorf("expected ttl %v, got %v", sessionTTL, ttl)
+	}
+}
@@ -0,0 +1,37 @@
+package organization
+
+import (
+	"context"
+	"server/internal/domain"
+	"time"
+)
+
+type OrganizationRepository interface {
+	CreateOrganization(ctx context.Context, organization *domain.Organization) error
+	GetMembership(ctx context.Context, organizationID, userID int64) (*domain.OrganizationMembership, error)
+	ListUserOrganizations(ctx context.Context, userID int64) ([]*domain.OrganizationMembership, error)
+	ListMembers(ctx context.Context, organizationID int64) ([]*domain.OrganizationMember, error)
+	UpdateMemberRole(ctx context.Context, organizationID, userID int64, role domain.OrganizationRole) error
+	RemoveMember(ctx context.Context, organizationID, userID int64) error
+	SaveInvitation(ctx context.Context, invitation *domain.OrganizationInvitation) error
+	ListInvitations(ctx context.Context, organizationID int64, now time.Time) ([]*domain.OrganizationInvitation, error)
+	GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*domain.OrganizationInvitation, error)
+	AcceptInvitation(ctx context.Context, invitation *domain.OrganizationInvitation, userID int64, joinedAt time.Time) error
+	DeleteInvitation(ctx context.Context, invitationID int64) error
+}
+
+type UserRepository interface {
+	GetUserByID(ctx context.Context, userID int64) (*domain.User, error)
+}
+
+type SessionRepository interface {
+	SetSessionOrganization(ctx context.Context, token string, organizationID int64) error
+}
+
+type AuditUseCase interface {
+	Record(ctx context.Context, event domain.AuditEvent)
+}
+
+type Mailer interface {
+	Send(ctx context.Context, message domain.EmailMessage) error
+}
@@ -0,0 +1,184 @@
+package organization
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"server/internal/domain"
+	"server/internal/pkg/tracing"
+	"server/internal/pkg/validation"
+	"strings"
+	"time"
+)
+
+const (
+	invitationTokenBytes = 32
+	acceptInvitationPath = "/invitations/accept"
+)
+
+// InviteMember emails an invitation to join with role. Owners and admins
+// may invite; only owners may invite owners.
+func (uc *UseCase) InviteMember(ctx context.Context, actorID, organizationID int64, email string, role domain.OrganizationRole) (*domain.OrganizationInvitation, error) {
+	ctx, span := tracing.Start(ctx, "organization.InviteMember")
+	defer span.End()
+
+	email = strings.TrimSpace(email)
+	if role == "" {
+		role = domain.OrganizationRoleMember
+	}
+	fieldErrors := domain.FieldErrors{}
+	if !validation.IsValidEmail(email) {
+		fieldErrors["email"] = "must be a valid email address"
+	}
+	if !role.IsValid() {
+		fieldErrors["role"] = "must be owner, admin or member"
+	}
+	if len(fieldErrors) > 0 {
+		return nil, fieldErrors
+	}
+
+	actor, err := uc.requireManager(ctx, organizationID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if role == domain.OrganizationRoleOwner && actor.Role != domain.OrganizationRoleOwner {
+		return nil, domain.ErrOrganizationPermissionDenied
+	}
+
+	token, err := generateInvitationToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invitation token: %w", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	invitation := &domain.OrganizationInvitation{
+		OrganizationID:   organizationID,
+		OrganizationName: actor.Organization.Name,
+		Email:            email,
+		Role:             role,
+		TokenHash:        hashInvitationToken(token),
+		InvitedBy:        actorID,
+		CreatedAt:        now,
+		ExpiresAt:        now.Add(uc.cfg.InvitationTTL),
+	}
+	if err := uc.orgRepo.SaveInvitation(ctx, invitation); err != nil {
+		return nil, fmt.Errorf("failed to save invitation: %w", err)
+	}
+
+	err = uc.mailer.Send(ctx, domain.EmailMessage{
+		To:      email,
+		Subject: fmt.Sprintf("You are invited to join %s", invitation.OrganizationName),
+		Body: fmt.Sprintf("You have been invited to join %s as %s. Sign in with this email address and open the link below to accept:\n\n%s\n\nThe link expires on %s.",
+			invitation.OrganizationName, role, uc.invitationLink(token), invitation.ExpiresAt.Format(time.RFC1123)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send invitation email: %w", err)
+	}
+
+	uc.record(ctx, actorID, nil, domain.AuditEventOrganizationInvite, organizationID,
+		map[string]string{"email": email, "role": string(role)})
+	return invitation, nil
+}
+
+// ListInvitations returns the pending invitations to owners and admins.
+func (uc *UseCase) ListInvitations(ctx context.Context, actorID, organizationID int64) ([]*domain.OrganizationInvitation, error) {
+	ctx, span := tracing.Start(ctx, "organization.ListInvitations")
+	defer span.End()
+
+	if _, err := uc.requireManager(ctx, organizationID, actorID); err != nil {
+		return nil, err
+	}
+	invitations, err := uc.orgRepo.ListInvitations(ctx, organizationID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invitations: %w", err)
+	}
+	return invitations, nil
+}
+
+// GetInvitation looks an invitation up by the token from its email, so it
+// can be shown before it is accepted.
+func (uc *UseCase) GetInvitation(ctx context.Context, token string) (*domain.OrganizationInvitation, error) {
+	ctx, span := tracing.Start(ctx, "organization.GetInvitation")
+	defer span.End()
+
+	invitation, err := uc.orgRepo.GetInvitationByTokenHash(ctx, hashInvitationToken(token))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvitationNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	if invitation.IsExpired(time.Now()) {
+		return nil, domain.ErrInvitationExpired
+	}
+	return invitation, nil
+}
+
+// AcceptInvitation adds userID to the organization. The invitation only
+// works for the account with the invited email address.
+func (uc *UseCase) AcceptInvitation(ctx context.Context, userID int64, token string) (*domain.OrganizationMembership, error) {
+	ctx, span := tracing.Start(ctx, "organization.AcceptInvitation")
+	defer span.End()
+
+	invitation, err := uc.orgRepo.GetInvitationByTokenHash(ctx, hashInvitationToken(token))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvitationNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+
+	now := time.Now()
+	if invitation.IsExpired(now) {
+		if err := uc.orgRepo.DeleteInvitation(ctx, invitation.ID); err != nil {
+			return nil, fmt.Errorf("failed to delete invitation: %w", err)
+		}
+		return nil, domain.ErrInvitationExpired
+	}
+
+	user, err := uc.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return nil, domain.ErrInvitationForAnotherEmail
+	}
+
+	if err := uc.orgRepo.AcceptInvitation(ctx, invitation, userID, now.UTC().Truncate(time.Second)); err != nil {
+		if errors.Is(err, domain.ErrInvitationNotFound) || errors.Is(err, domain.ErrAlreadyOrganizationMember) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to accept invitation: %w", err)
+	}
+
+	uc.record(ctx, userID, &userID, domain.AuditEventOrganizationJoin, invitation.OrganizationID,
+		map[string]string{"role": string(invitation.Role)})
+
+	membership, err := uc.orgRepo.GetMembership(ctx, invitation.OrganizationID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get membership: %w", err)
+	}
+	return membership, nil
+}
+
+func (uc *UseCase) invitationLink(token string) string {
+	return strings.TrimRight(uc.cfg.LinkBaseURL, "/") + acceptInvitationPath + "?" + url.Values{"token": {token}}.Encode()
+}
+
+func generateInvitationToken() (string, error) {
+	b := make([]byte, invitationTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,175 @@
+package organization
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/url"
+	"os"
+	"server/internal/domain"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUseCase_InviteMember(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	roles := map[int64]domain.OrganizationRole{
+		1: domain.OrganizationRoleOwner,
+		2: domain.OrganizationRoleAdmin,
+		3: domain.OrganizationRoleMember,
+	}
+
+	tests := []struct {
+		name          string
+		actorID       int64
+		email         string
+		role          domain.OrganizationRole
+		expectedError error
+		expectedField string
+		expectedRole  domain.OrganizationRole
+	}{
+		{name: "admin invites a member", actorID: 2, email: " new@example.com ", expectedRole: domain.OrganizationRoleMember},
+		{name: "owner invites an owner", actorID: 1, email: "new@example.com", role: domain.OrganizationRoleOwner, expectedRole: domain.OrganizationRoleOwner},
+		{name: "admin cannot invite owners", actorID: 2, email: "new@example.com", role: domain.OrganizationRoleOwner, expectedError: domain.ErrOrganizationPermissionDenied},
+		{name: "member cannot invite", actorID: 3, email: "new@example.com", expectedError: domain.ErrOrganizationPermissionDenied},
+		{name: "invalid email", actorID: 1, email: "not-an-email", expectedField: "email"},
+		{name: "invalid role", actorID: 1, email: "new@example.com", role: "guest", expectedField: "role"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved *domain.OrganizationInvitation
+			mockRepo := &mockOrganizationRepository{
+				getMembershipFunc: membershipsOf(roles),
+				saveInvitationFunc: func(ctx context.Context, invitation *domain.OrganizationInvitation) error {
+					saved = invitation
+					return nil
+				},
+			}
+			mockMail := &mockMailer{}
+
+			uc := NewUseCase(logger, mockRepo, &mockUserRepository{}, &mockSessionRepository{}, &mockAuditUseCase{}, mockMail,
+				Config{InvitationTTL: 24 * time.Hour, LinkBaseURL: "https://app.example.com/"})
+			invitation, err := uc.InviteMember(ctx, tt.actorID, 9, tt.email, tt.role)
+
+			if tt.expectedField != "" {
+				var fieldErrs domain.FieldErrors
+				if !errors.As(err, &fieldErrs) || fieldErrs[tt.expectedField] == "" {
+					t.Fatalf("expected a %s field error, got %v", tt.expectedField, err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if tt.expectedError != nil {
+				if saved != nil || len(mockMail.messages) != 0 {
+					t.Error("expected no invitation to be sent")
+				}
+				return
+			}
+
+			if invitation.Email != "new@example.com" || invitation.Role != tt.expectedRole || invitation.OrganizationName != "Acme" {
+				t.Errorf("unexpected invitation: %+v", invitation)
+			}
+			if len(mockMail.messages) != 1 || mockMail.messages[0].To != "new@example.com" {
+				t.Fatalf("expected one email to the invitee, got %+v", mockMail.messages)
+			}
+
+			// The emailed token is the one whose hash was stored.
+			body := mockMail.messages[0].Body
+			start := strings.Index(body, "https://app.example.com/invitations/accept?")
+			if start < 0 {
+				t.Fatalf("expected an accept link in %q", body)
+			}
+			link, err := url.Parse(strings.Fields(body[start:])[0])
+			if err != nil {
+				t.Fatalf("failed to parse link: %v", err)
+			}
+			if hashInvitationToken(link.Query().Get("token")) != saved.TokenHash {
+				t.Error("expected the stored hash to match the emailed token")
+			}
+		})
+	}
+}
+
+func TestUseCase_AcceptInvitation(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		email         string
+		expiresIn     time.Duration
+		acceptErr     error
+		expectedError error
+		expectDelete  bool
+	}{
+		{name: "invited user joins", email: "User@Example.com", expiresIn: time.Hour},
+		{name: "expired invitation is dropped", email: "user@example.com", expiresIn: -time.Hour, expectedError: domain.ErrInvitationExpired, expectDelete: true},
+		{name: "another account", email: "someone@example.com", expiresIn: time.Hour, expectedError: domain.ErrInvitationForAnotherEmail},
+		{name: "already a member", email: "user@example.com", expiresIn: time.Hour, acceptErr: domain.ErrAlreadyOrganizationMember, expectedError: domain.ErrAlreadyOrganizationMember},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deleted := false
+			accepted := false
+			mockRepo := &mockOrganizationRepository{
+				getInvitationByTokenHashFunc: func(ctx context.Context, tokenHash string) (*domain.OrganizationInvitation, error) {
+					if tokenHash != hashInvitationToken("token") {
+						return nil, domain.ErrInvitationNotFound
+					}
+					return &domain.OrganizationInvitation{
+						ID:             4,
+						OrganizationID: 9,
+						Email:          tt.email,
+						Role:           domain.OrganizationRoleAdmin,
+						TokenHash:      tokenHash,
+						ExpiresAt:      time.Now().Add(tt.expiresIn),
+					}, nil
+				},
+				acceptInvitationFunc: func(ctx context.Context, invitation *domain.OrganizationInvitation, userID int64, joinedAt time.Time) error {
+					accepted = true
+					return tt.acceptErr
+				},
+				deleteInvitationFunc: func(ctx context.Context, invitationID int64) error {
+					deleted = true
+					return nil
+				},
+				getMembershipFunc: membershipsOf(map[int64]domain.OrganizationRole{1: domain.OrganizationRoleAdmin}),
+			}
+			mockAudit := &mockAuditUseCase{}
+
+			uc := NewUseCase(logger, mockRepo, &mockUserRepository{}, &mockSessionRepository{}, mockAudit, &mockMailer{}, Config{})
+			membership, err := uc.AcceptInvitation(ctx, 1, "token")
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if deleted != tt.expectDelete {
+				t.Errorf("expected delete %v, got %v", tt.expectDelete, deleted)
+			}
+			if tt.expectedError != nil {
+				if len(mockAudit.events) != 0 {
+					t.Errorf("expected no events, got %+v", mockAudit.events)
+				}
+				return
+			}
+			if !accepted || membership.Role != domain.OrganizationRoleAdmin {
+				t.Errorf("expected to join as admin, got %+v", membership)
+			}
+			if len(mockAudit.events) != 1 || mockAudit.events[0].Type != domain.AuditEventOrganizationJoin {
+				t.Errorf("expected an organization.join event, got %+v", mockAudit.events)
+			}
+		})
+	}
+
+	uc := NewUseCase(logger, &mockOrganizationRepository{}, &mockUserRepository{}, &mockSessionRepository{}, &mockAuditUseCase{}, &mockMailer{}, Config{})
+	if _, err := uc.AcceptInvitation(ctx, 1, "unknown"); !errors.Is(err, domain.ErrInvitationNotFound) {
+		t.Errorf("expected ErrInvitationNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,212 @@
+package organization
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"server/internal/domain"
+	"server/internal/pkg/tracing"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const maxNameLength = 255
+
+// CreateOrganization creates an organization with userID as its owner.
+func (uc *UseCase) CreateOrganization(ctx context.Context, userID int64, name string) (*domain.OrganizationMembership, error) {
+	ctx, span := tracing.Start(ctx, "organization.CreateOrganization")
+	defer span.End()
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, domain.FieldErrors{"name": "must not be empty"}
+	}
+	if utf8.RuneCountInString(name) > maxNameLength {
+		return nil, domain.FieldErrors{"name": fmt.Sprintf("must be at most %d characters", maxNameLength)}
+	}
+
+	organization := &domain.Organization{
+		Name:      name,
+		CreatedBy: userID,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	if err := uc.orgRepo.CreateOrganization(ctx, organization); err != nil {
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	uc.record(ctx, userID, nil, domain.AuditEventOrganizationCreate, organization.ID, map[string]string{"name": name})
+	return &domain.OrganizationMembership{
+		Organization: *organization,
+		Role:         domain.OrganizationRoleOwner,
+		JoinedAt:     organization.CreatedAt,
+	}, nil
+}
+
+func (uc *UseCase) ListOrganizations(ctx context.Context, userID int64) ([]*domain.OrganizationMembership, error) {
+	ctx, span := tracing.Start(ctx, "organization.ListOrganizations")
+	defer span.End()
+
+	memberships, err := uc.orgRepo.ListUserOrganizations(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	return memberships, nil
+}
+
+// ListMembers returns the member list to any member of the organization.
+func (uc *UseCase) ListMembers(ctx context.Context, userID, organizationID int64) ([]*domain.OrganizationMember, error) {
+	ctx, span := tracing.Start(ctx, "organization.ListMembers")
+	defer span.End()
+
+	if _, err := uc.orgRepo.GetMembership(ctx, organizationID, userID); err != nil {
+		return nil, err
+	}
+	members, err := uc.orgRepo.ListMembers(ctx, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+	return members, nil
+}
+
+// ChangeMemberRole lets owners and admins change roles; only owners may
+// make someone an owner or change an owner's role.
+func (uc *UseCase) ChangeMemberRole(ctx context.Context, actorID, organizationID, userID int64, role domain.OrganizationRole) error {
+	ctx, span := tracing.Start(ctx, "organization.ChangeMemberRole")
+	defer span.End()
+
+	if !role.IsValid() {
+		return domain.FieldErrors{"role": "must be owner, admin or member"}
+	}
+
+	actor, err := uc.requireManager(ctx, organizationID, actorID)
+	if err != nil {
+		return err
+	}
+	target, err := uc.orgRepo.GetMembership(ctx, organizationID, userID)
+	if err != nil {
+		return err
+	}
+	if (target.Role == domain.OrganizationRoleOwner || role == domain.OrganizationRoleOwner) && actor.Role != domain.OrganizationRoleOwner {
+		return domain.ErrOrganizationPermissionDenied
+	}
+	if target.Role == role {
+		return nil
+	}
+
+	if err := uc.orgRepo.UpdateMemberRole(ctx, organizationID, userID, role); err != nil {
+		if errors.Is(err, domain.ErrLastOrganizationOwner) || errors.Is(err, domain.ErrNotOrganizationMember) {
+			return err
+		}
+		return fmt.Errorf("failed to update member role: %w", err)
+	}
+
+	uc.record(ctx, actorID, &userID, domain.AuditEventOrganizationRoleChange, organizationID,
+		map[string]string{"old_role": string(target.Role), "new_role": string(role)})
+	return nil
+}
+
+// RemoveMember takes userID out of the organization. Members may always
+// leave; removing someone else takes an owner or admin, and only owners
+// may remove owners.
+func (uc *UseCase) RemoveMember(ctx context.Context, actorID, organizationID, userID int64) error {
+	ctx, span := tracing.Start(ctx, "organization.RemoveMember")
+	defer span.End()
+
+	if actorID != userID {
+		actor, err := uc.requireManager(ctx, organizationID, actorID)
+		if err != nil {
+			return err
+		}
+		target, err := uc.orgRepo.GetMembership(ctx, organizationID, userID)
+		if err != nil {
+			return err
+		}
+		if target.Role == domain.OrganizationRoleOwner && actor.Role != domain.OrganizationRoleOwner {
+			return domain.ErrOrganizationPermissionDenied
+		}
+	}
+
+	if err := uc.orgRepo.RemoveMember(ctx, organizationID, userID); err != nil {
+		if errors.Is(err, domain.ErrLastOrganizationOwner) || errors.Is(err, domain.ErrNotOrganizationMember) {
+			return err
+		}
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+
+	uc.record(ctx, actorID, &userID, domain.AuditEventOrganizationMemberRemove, organizationID, nil)
+	return nil
+}
+
+// SelectOrganization makes organizationID the current organization of the
+// session; zero clears the selection.
+func (uc *UseCase) SelectOrganization(ctx context.Context, session *domain.Session, organizationID int64) (*domain.OrganizationMembership, error) {
+	ctx, span := tracing.Start(ctx, "organization.SelectOrganization")
+	defer span.End()
+
+	var membership *domain.OrganizationMembership
+	if organizationID != 0 {
+		var err error
+		membership, err = uc.orgRepo.GetMembership(ctx, organizationID, session.UserID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := uc.sessionRepo.SetSessionOrganization(ctx, session.Token, organizationID); err != nil {
+		return nil, fmt.Errorf("failed to select organization: %w", err)
+	}
+	return membership, nil
+}
+
+// CurrentOrganization returns the organization selected for the session, or
+// nil when there is none. A selection the user has lost access to since is
+// cleared.
+func (uc *UseCase) CurrentOrganization(ctx context.Context, session *domain.Session) (*domain.OrganizationMembership, error) {
+	ctx, span := tracing.Start(ctx, "organization.CurrentOrganization")
+	defer span.End()
+
+	if session.OrganizationID == 0 {
+		return nil, nil
+	}
+
+	membership, err := uc.orgRepo.GetMembership(ctx, session.OrganizationID, session.UserID)
+	if errors.Is(err, domain.ErrNotOrganizationMember) {
+		if err := uc.sessionRepo.SetSessionOrganization(ctx, session.Token, 0); err != nil && !errors.Is(err, domain.ErrSessionNotFound) {
+			return nil, fmt.Errorf("failed to clear organization: %w", err)
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current organization: %w", err)
+	}
+	return membership, nil
+}
+
+// requireManager returns the actor's membership if the actor may manage
+// the organization's members.
+func (uc *UseCase) requireManager(ctx context.Context, organizationID, actorID int64) (*domain.OrganizationMembership, error) {
+	actor, err := uc.orgRepo.GetMembership(ctx, organizationID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.Role.CanManageMembers() {
+		return nil, domain.ErrOrganizationPermissionDenied
+	}
+	return actor, nil
+}
+
+func (uc *UseCase) record(ctx context.Context, actorID int64, subjectID *int64, eventType domain.AuditEventType, organizationID int64, details map[string]string) {
+	if details == nil {
+		details = make(map[string]string, 1)
+	}
+	details["organization_id"] = strconv.FormatInt(organizationID, 10)
+	uc.auditUC.Record(ctx, domain.AuditEvent{
+		ActorUserID:   &actorID,
+		SubjectUserID: subjectID,
+		Type:          eventType,
+		Outcome:       domain.AuditOutcomeSuccess,
+		Details:       details,
+	})
+}
@@ -0,0 +1,380 @@
+package organization
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"server/internal/domain"
+	"testing"
+	"time"
+)
+
+type mockOrganizationRepository struct {
+	createOrganizationFunc       func(ctx context.Context, organization *domain.Organization) error
+	getMembershipFunc            func(ctx context.Context, organizationID, userID int64) (*domain.OrganizationMembership, error)
+	listUserOrganizationsFunc    func(ctx context.Context, userID int64) ([]*domain.OrganizationMembership, error)
+	listMembersFunc              func(ctx context.Context, organizationID int64) ([]*domain.OrganizationMember, error)
+	updateMemberRoleFunc         func(ctx context.Context, organizationID, userID int64, role domain.OrganizationRole) error
+	removeMemberFunc             func(ctx context.Context, organizationID, userID int64) error
+	saveInvitationFunc           func(ctx context.Context, invitation *domain.OrganizationInvitation) error
+	listInvitationsFunc          func(ctx context.Context, organizationID int64, now time.Time) ([]*domain.OrganizationInvitation, error)
+	getInvitationByTokenHashFunc func(ctx context.Context, tokenHash string) (*domain.OrganizationInvitation, error)
+	acceptInvitationFunc         func(ctx context.Context, invitation *domain.OrganizationInvitation, userID int64, joinedAt time.Time) error
+	deleteInvitationFunc         func(ctx context.Context, invitationID int64) error
+}
+
+func (m *mockOrganizationRepository) CreateOrganization(ctx context.Context, organization *domain.Organization) error {
+	if m.createOrganizationFunc != nil {
+		return m.createOrganizationFunc(ctx, organization)
+	}
+	organization.ID = 1
+	return nil
+}
+
+func (m *mockOrganizationRepository) GetMembership(ctx context.Context, organizationID, userID int64) (*domain.OrganizationMembership, error) {
+	if m.getMembershipFunc != nil {
+		return m.getMembershipFunc(ctx, organizationID, userID)
+	}
+	return nil, domain.ErrNotOrganizationMember
+}
+
+func (m *mockOrganizationRepository) ListUserOrganizations(ctx context.Context, userID int64) ([]*domain.OrganizationMembership, error) {
+	if m.listUserOrganizationsFunc != nil {
+		return m.listUserOrganizationsFunc(ctx, userID)
+	}
+	return []*domain.OrganizationMembership{}, nil
+}
+
+func (m *mockOrganizationRepository) ListMembers(ctx context.Context, organizationID int64) ([]*domain.OrganizationMember, error) {
+	if m.listMembersFunc != nil {
+		return m.listMembersFunc(ctx, organizationID)
+	}
+	return []*domain.OrganizationMember{}, nil
+}
+
+func (m *mockOrganizationRepository) UpdateMemberRole(ctx context.Context, organizationID, userID int64, role domain.OrganizationRole) error {
+	if m.updateMemberRoleFunc != nil {
+		return m.updateMemberRoleFunc(ctx, organizationID, userID, role)
+	}
+	return nil
+}
+
+func (m *mockOrganizationRepository) RemoveMember(ctx context.Context, organizationID, userID int64) error {
+	if m.removeMemberFunc != nil {
+		return m.removeMemberFunc(ctx, organizationID, userID)
+	}
+	return nil
+}
+
+func (m *mockOrganizationRepository) SaveInvitation(ctx context.Context, invitation *domain.OrganizationInvitation) error {
+	if m.saveInvitationFunc != nil {
+		return m.saveInvitationFunc(ctx, invitation)
+	}
+	invitation.ID = 1
+	return nil
+}
+
+func (m *mockOrganizationRepository) ListInvitations(ctx context.Context, organizationID int64, now time.Time) ([]*domain.OrganizationInvitation, error) {
+	if m.listInvitationsFunc != nil {
+		return m.listInvitationsFunc(ctx, organizationID, now)
+	}
+	return []*domain.OrganizationInvitation{}, nil
+}
+
+func (m *mockOrganizationRepository) GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*domain.OrganizationInvitation, error) {
+	if m.getInvitationByTokenHashFunc != nil {
+		return m.getInvitationByTokenHashFunc(ctx, tokenHash)
+	}
+	return nil, domain.ErrInvitationNotFound
+}
+
+func (m *mockOrganizationRepository) AcceptInvitation(ctx context.Context, invitation *domain.OrganizationInvitation, userID int64, joinedAt time.Time) error {
+	if m.acceptInvitationFunc != nil {
+		return m.acceptInvitationFunc(ctx, invitation, userID, joinedAt)
+	}
+	return nil
+}
+
+func (m *mockOrganizationRepository) DeleteInvitation(ctx context.Context, invitationID int64) error {
+	if m.deleteInvitationFunc != nil {
+		return m.deleteInvitationFunc(ctx, invitationID)
+	}
+	return nil
+}
+
+type mockUserRepository struct {
+	getUserByIDFunc func(ctx context.Context, userID int64) (*domain.User, error)
+}
+
+func (m *mockUserRepository) GetUserByID(ctx context.Context, userID int64) (*domain.User, error) {
+	if m.getUserByIDFunc != nil {
+		return m.getUserByIDFunc(ctx, userID)
+	}
+	return &domain.User{ID: userID, Email: "user@example.com"}, nil
+}
+
+type mockSessionRepository struct {
+	selected map[string]int64
+}
+
+func (m *mockSessionRepository) SetSessionOrganization(ctx context.Context, token string, organizationID int64) error {
+	if m.selected == nil {
+		m.selected = make(map[string]int64)
+	}
+	m.selected[token] = organizationID
+	return nil
+}
+
+type mockAuditUseCase struct {
+	events []domain.AuditEvent
+}
+
+func (m *mockAuditUseCase) Record(ctx context.Context, event domain.AuditEvent) {
+	m.events = append(m.events, event)
+}
+
+type mockMailer struct {
+	messages []domain.EmailMessage
+}
+
+func (m *mockMailer) Send(ctx context.Context, message domain.EmailMessage) error {
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+// membershipsOf answers GetMembership from a role per user of organization 9.
+func membershipsOf(roles map[int64]domain.OrganizationRole) func(ctx context.Context, organizationID, userID int64) (*domain.OrganizationMembership, error) {
+	return func(ctx context.Context, organizationID, userID int64) (*domain.OrganizationMembership, error) {
+		role, ok := roles[userID]
+		if organizationID != 9 || !ok {
+			return nil, domain.ErrNotOrganizationMember
+		}
+		return &domain.OrganizationMembership{
+			Organization: domain.Organization{ID: 9, Name: "Acme"},
+			Role:         role,
+		}, nil
+	}
+}
+
+func TestUseCase_CreateOrganization(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		orgName       string
+		expectedField bool
+	}{
+		{name: "creator becomes owner", orgName: " Acme "},
+		{name: "empty name", orgName: "  ", expectedField: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created *domain.Organization
+			mockRepo := &mockOrganizationRepository{
+				createOrganizationFunc: func(ctx context.Context, organization *domain.Organization) error {
+					created = organization
+					organization.ID = 9
+					return nil
+				},
+			}
+			mockAudit := &mockAuditUseCase{}
+
+			uc := NewUseCase(logger, mockRepo, &mockUserRepository{}, &mockSessionRepository{}, mockAudit, &mockMailer{}, Config{})
+			membership, err := uc.CreateOrganization(ctx, 1, tt.orgName)
+
+			if tt.expectedField {
+				var fieldErrs domain.FieldErrors
+				if !errors.As(err, &fieldErrs) || fieldErrs["name"] == "" {
+					t.Fatalf("expected a name field error, got %v", err)
+				}
+				if created != nil {
+					t.Error("expected nothing to be created")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if created.Name != "Acme" || created.CreatedBy != 1 {
+				t.Errorf("unexpected organization: %+v", created)
+			}
+			if membership.Organization.ID != 9 || membership.Role != domain.OrganizationRoleOwner {
+				t.Errorf("unexpected membership: %+v", membership)
+			}
+			if len(mockAudit.events) != 1 || mockAudit.events[0].Type != domain.AuditEventOrganizationCreate {
+				t.Errorf("expected an organization.create event, got %+v", mockAudit.events)
+			}
+		})
+	}
+}
+
+func TestUseCase_ChangeMemberRole(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	// 1 owner, 2 admin, 3 member, 4 second owner
+	roles := map[int64]domain.OrganizationRole{
+		1: domain.OrganizationRoleOwner,
+		2: domain.OrganizationRoleAdmin,
+		3: domain.OrganizationRoleMember,
+		4: domain.OrganizationRoleOwner,
+	}
+
+	tests := []struct {
+		name          string
+		actorID       int64
+		userID        int64
+		role          domain.OrganizationRole
+		repoErr       error
+		expectedError error
+		expectUpdate  bool
+	}{
+		{name: "admin promotes a member to admin", actorID: 2, userID: 3, role: domain.OrganizationRoleAdmin, expectUpdate: true},
+		{name: "admin cannot make owners", actorID: 2, userID: 3, role: domain.OrganizationRoleOwner, expectedError: domain.ErrOrganizationPermissionDenied},
+		{name: "admin cannot demote owners", actorID: 2, userID: 1, role: domain.OrganizationRoleMember, expectedError: domain.ErrOrganizationPermissionDenied},
+		{name: "member cannot change roles", actorID: 3, userID: 2, role: domain.OrganizationRoleMember, expectedError: domain.ErrOrganizationPermissionDenied},
+		{name: "owner demotes another owner", actorID: 1, userID: 4, role: domain.OrganizationRoleAdmin, expectUpdate: true},
+		{name: "last owner is kept", actorID: 1, userID: 1, role: domain.OrganizationRoleAdmin, repoErr: domain.ErrLastOrganizationOwner, expectedError: domain.ErrLastOrganizationOwner, expectUpdate: true},
+		{name: "outsider", actorID: 5, userID: 3, role: domain.OrganizationRoleAdmin, expectedError: domain.ErrNotOrganizationMember},
+		{name: "unknown target", actorID: 1, userID: 5, role: domain.OrganizationRoleAdmin, expectedError: domain.ErrNotOrganizationMember},
+		{name: "unchanged role", actorID: 1, userID: 3, role: domain.OrganizationRoleMember},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := false
+			mockRepo := &mockOrganizationRepository{
+				getMembershipFunc: membershipsOf(roles),
+				updateMemberRoleFunc: func(ctx context.Context, organizationID, userID int64, role domain.OrganizationRole) error {
+					updated = true
+					if userID != tt.userID || role != tt.role {
+						t.Errorf("unexpected update of %d to %s", userID, role)
+					}
+					return tt.repoErr
+				},
+			}
+			mockAudit := &mockAuditUseCase{}
+
+			uc := NewUseCase(logger, mockRepo, &mockUserRepository{}, &mockSessionRepository{}, mockAudit, &mockMailer{}, Config{})
+			err := uc.ChangeMemberRole(ctx, tt.actorID, 9, tt.userID, tt.role)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if updated != tt.expectUpdate {
+				t.Errorf("expected update %v, got %v", tt.expectUpdate, updated)
+			}
+			if expectEvent := tt.expectUpdate && tt.expectedError == nil; expectEvent != (len(mockAudit.events) == 1) {
+				t.Errorf("expected event %v, got %+v", expectEvent, mockAudit.events)
+			}
+		})
+	}
+}
+
+func TestUseCase_RemoveMember(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	roles := map[int64]domain.OrganizationRole{
+		1: domain.OrganizationRoleOwner,
+		2: domain.OrganizationRoleAdmin,
+		3: domain.OrganizationRoleMember,
+	}
+
+	tests := []struct {
+		name          string
+		actorID       int64
+		userID        int64
+		expectedError error
+		expectRemove  bool
+	}{
+		{name: "admin removes a member", actorID: 2, userID: 3, expectRemove: true},
+		{name: "member leaves", actorID: 3, userID: 3, expectRemove: true},
+		{name: "member cannot remove others", actorID: 3, userID: 2, expectedError: domain.ErrOrganizationPermissionDenied},
+		{name: "admin cannot remove owners", actorID: 2, userID: 1, expectedError: domain.ErrOrganizationPermissionDenied},
+		{name: "owner removes an admin", actorID: 1, userID: 2, expectRemove: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			removed := false
+			mockRepo := &mockOrganizationRepository{
+				getMembershipFunc: membershipsOf(roles),
+				removeMemberFunc: func(ctx context.Context, organizationID, userID int64) error {
+					removed = true
+					return nil
+				},
+			}
+
+			uc := NewUseCase(logger, mockRepo, &mockUserRepository{}, &mockSessionRepository{}, &mockAuditUseCase{}, &mockMailer{}, Config{})
+			err := uc.RemoveMember(ctx, tt.actorID, 9, tt.userID)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if removed != tt.expectRemove {
+				t.Errorf("expected remove %v, got %v", tt.expectRemove, removed)
+			}
+		})
+	}
+}
+
+func TestUseCase_SelectOrganization(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	mockRepo := &mockOrganizationRepository{
+		getMembershipFunc: membershipsOf(map[int64]domain.OrganizationRole{1: domain.OrganizationRoleMember}),
+	}
+	mockSessions := &mockSessionRepository{}
+	uc := NewUseCase(logger, mockRepo, &mockUserRepository{}, mockSessions, &mockAuditUseCase{}, &mockMailer{}, Config{})
+
+	session := &domain.Session{Token: "token", UserID: 1}
+	membership, err := uc.SelectOrganization(ctx, session, 9)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if membership.Organization.ID != 9 || mockSessions.selected["token"] != 9 {
+		t.Errorf("expected organization 9 to be selected, got %+v and %v", membership, mockSessions.selected)
+	}
+
+	if _, err := uc.SelectOrganization(ctx, session, 10); !errors.Is(err, domain.ErrNotOrganizationMember) {
+		t.Errorf("expected ErrNotOrganizationMember, got %v", err)
+	}
+	if mockSessions.selected["token"] != 9 {
+		t.Errorf("expected the selection to stay, got %d", mockSessions.selected["token"])
+	}
+
+	membership, err = uc.SelectOrganization(ctx, session, 0)
+	if err != nil || membership != nil || mockSessions.selected["token"] != 0 {
+		t.Errorf("expected the selection to be cleared, got %+v, %v", membership, err)
+	}
+}
+
+func TestUseCase_CurrentOrganization(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	mockRepo := &mockOrganizationRepository{
+		getMembershipFunc: membershipsOf(map[int64]domain.OrganizationRole{1: domain.OrganizationRoleMember}),
+	}
+	mockSessions := &mockSessionRepository{selected: map[string]int64{}}
+	uc := NewUseCase(logger, mockRepo, &mockUserRepository{}, mockSessions, &mockAuditUseCase{}, &mockMailer{}, Config{})
+
+	membership, err := uc.CurrentOrganization(ctx, &domain.Session{Token: "member", UserID: 1, OrganizationID: 9})
+	if err != nil || membership == nil || membership.Role != domain.OrganizationRoleMember {
+		t.Errorf("expected the membership, got %+v, %v", membership, err)
+	}
+
+	mockSessions.selected["removed"] = 9
+	membership, err = uc.CurrentOrganization(ctx, &domain.Session{Token: "removed", UserID: 2, OrganizationID: 9})
+	if err != nil || membership != nil {
+		t.Errorf("expected no organization, got %+v, %v", membership, err)
+	}
+	if mockSessions.selected["removed"] != 0 {
+		t.Errorf("expected the lost selection to be cleared, got %d", mockSessions.selected["removed"])
+	}
+}
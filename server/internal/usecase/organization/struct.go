@@ -0,0 +1,34 @@
+package organization
+
+import (
+	"log/slog"
+	"time"
+)
+
+type Config struct {
+	InvitationTTL time.Duration
+	// LinkBaseURL is the public origin that invitation links point to.
+	LinkBaseURL string
+}
+
+type UseCase struct {
+	logger      *slog.Logger
+	orgRepo     OrganizationRepository
+	userRepo    UserRepository
+	sessionRepo SessionRepository
+	auditUC     AuditUseCase
+	mailer      Mailer
+	cfg         Config
+}
+
+func NewUseCase(logger *slog.Logger, orgRepo OrganizationRepository, userRepo UserRepository, sessionRepo SessionRepository, auditUC AuditUseCase, mailer Mailer, cfg Config) *UseCase {
+	return &UseCase{
+		logger:      logger,
+		orgRepo:     orgRepo,
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		auditUC:     auditUC,
+		mailer:      mailer,
+		cfg:         cfg,
+	}
+}
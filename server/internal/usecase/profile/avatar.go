@@ -0,0 +1,168 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"server/internal/domain"
+	"server/internal/pkg/identicon"
+	"server/internal/pkg/imageproc"
+	"server/internal/pkg/tracing"
+	"slices"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// AvatarSizes lists the square thumbnails, in pixels, generated for every
+// avatar. The first one is served when no size is requested.
+var AvatarSizes = []int{256, 64}
+
+// UploadAvatar decodes the image, renders every thumbnail size and only then
+// switches the user over to the new avatar, so a failed upload leaves the
+// previous one intact.
+func (uc *UseCase) UploadAvatar(ctx context.Context, userID int64, data []byte) (string, error) {
+	ctx, span := tracing.Start(ctx, "profile.UploadAvatar")
+	defer span.End()
+
+	if int64(len(data)) > uc.cfg.AvatarMaxBytes {
+		return "", domain.ErrImageTooLarge
+	}
+
+	img, contentType, err := imageproc.Decode(data)
+	if err != nil {
+		return "", err
+	}
+
+	avatarID := uuid.NewString()
+	for _, size := range AvatarSizes {
+		encoded, err := imageproc.Encode(imageproc.Thumbnail(img, size), contentType)
+		if err != nil {
+			uc.deleteAvatarBlobs(ctx, avatarID)
+			return "", err
+		}
+		err = uc.blobStore.Put(ctx, avatarKey(avatarID, size), encoded)
+		if err != nil {
+			uc.logger.ErrorContext(ctx, "failed to store avatar", "error", err)
+			uc.deleteAvatarBlobs(ctx, avatarID)
+			return "", fmt.Errorf("failed to store avatar: %w", err)
+		}
+	}
+
+	previousID, err := uc.profileRepo.SetAvatar(ctx, userID, avatarID)
+	if err != nil {
+		uc.deleteAvatarBlobs(ctx, avatarID)
+		return "", err
+	}
+	if previousID != "" {
+		uc.deleteAvatarBlobs(ctx, previousID)
+	}
+
+	uc.auditUC.Record(ctx, domain.AuditEvent{
+		ActorUserID:   &userID,
+		SubjectUserID: &userID,
+		Type:          domain.AuditEventAvatarUpdate,
+		Outcome:       domain.AuditOutcomeSuccess,
+	})
+	return avatarID, nil
+}
+
+// GetAvatar returns one thumbnail of the user's avatar; size 0 means the
+// default size. Users without an uploaded picture get a generated one in the
+// requested format, an empty format meaning PNG. viewerID is the signed-in
+// user asking, 0 if anonymous; a picture they may not see is reported as
+// domain.ErrAvatarNotFound.
+func (uc *UseCase) GetAvatar(ctx context.Context, viewerID, userID int64, size int, format string) (*domain.AvatarImage, error) {
+	ctx, span := tracing.Start(ctx, "profile.GetAvatar")
+	defer span.End()
+
+	if size == 0 {
+		size = AvatarSizes[0]
+	}
+	if !slices.Contains(AvatarSizes, size) {
+		return nil, domain.ErrInvalidAvatarSize
+	}
+	if format == "" {
+		format = domain.AvatarFormatPNG
+	}
+	if format != domain.AvatarFormatPNG && format != domain.AvatarFormatSVG {
+		return nil, domain.ErrInvalidAvatarFormat
+	}
+
+	// Accounts pending deletion are gone for everyone, as on the public
+	// profile page.
+	profile, err := uc.profileRepo.GetActiveProfileByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canSee(profile, viewerID, profile.Privacy.Avatar) {
+		return nil, domain.ErrAvatarNotFound
+	}
+	if profile.AvatarID == "" {
+		fullName := ""
+		if canSee(profile, viewerID, profile.Privacy.FullName) {
+			fullName = profile.FullName
+		}
+		return generatedAvatar(profile.UserID, fullName, size, format)
+	}
+
+	data, err := uc.blobStore.Get(ctx, avatarKey(profile.AvatarID, size))
+	if err != nil {
+		if errors.Is(err, domain.ErrBlobNotFound) {
+			return nil, domain.ErrAvatarNotFound
+		}
+		return nil, fmt.Errorf("failed to read avatar: %w", err)
+	}
+
+	contentType, err := imageproc.Sniff(data)
+	if err != nil {
+		return nil, fmt.Errorf("stored avatar is corrupt: %w", err)
+	}
+
+	return &domain.AvatarImage{
+		AvatarID:    profile.AvatarID,
+		Size:        size,
+		ContentType: contentType,
+		Data:        data,
+	}, nil
+}
+
+// generatedAvatar renders the identicon on every request; it is cheap enough
+// and the version in its ID lets clients cache it for good. The initials
+// give the name away, so callers pass an empty name to viewers who may not
+// see it and they get the pattern instead.
+func generatedAvatar(userID int64, fullName string, size int, format string) (*domain.AvatarImage, error) {
+	icon := identicon.New(userID, fullName)
+	avatar := &domain.AvatarImage{
+		AvatarID: icon.Version(),
+		Size:     size,
+	}
+
+	if format == domain.AvatarFormatSVG {
+		avatar.ContentType = identicon.ContentTypeSVG
+		avatar.Data = icon.SVG(size)
+		return avatar, nil
+	}
+
+	data, err := icon.PNG(size)
+	if err != nil {
+		return nil, err
+	}
+	avatar.ContentType = imageproc.ContentTypePNG
+	avatar.Data = data
+	return avatar, nil
+}
+
+// deleteAvatarBlobs is best effort: a leftover thumbnail wastes space but is
+// no longer reachable once the user points at another avatar.
+func (uc *UseCase) deleteAvatarBlobs(ctx context.Context, avatarID string) {
+	for _, size := range AvatarSizes {
+		if err := uc.blobStore.Delete(ctx, avatarKey(avatarID, size)); err != nil {
+			uc.logger.ErrorContext(ctx, "failed to delete avatar", "avatar_id", avatarID, "size", size, "error", err)
+		}
+	}
+}
+
+func avatarKey(avatarID string, size int) string {
+	return "avatars/" + avatarID + "/" + strconv.Itoa(size)
+}
@@ -0,0 +1,329 @@
+package profile
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"log/slog"
+	"os"
+	"server/internal/domain"
+	"server/internal/gateway/sms"
+	"server/internal/pkg/identicon"
+	"testing"
+)
+
+func encodeTestImage(t *testing.T, width, height int, encode func(*bytes.Buffer, image.Image) error) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := encode(&buf, img); err != nil {
+		t.Fatalf("failed to encode test image: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestUseCase_UploadAvatar(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	pngData := encodeTestImage(t, 300, 200, func(buf *bytes.Buffer, img image.Image) error { return png.Encode(buf, img) })
+	jpegData := encodeTestImage(t, 120, 400, func(buf *bytes.Buffer, img image.Image) error { return jpeg.Encode(buf, img, nil) })
+
+	tests := []struct {
+		name                string
+		data                []byte
+		expectedContentType string
+		expectedError       error
+	}{
+		{name: "png", data: pngData, expectedContentType: "image/png"},
+		{name: "jpeg", data: jpegData, expectedContentType: "image/jpeg"},
+		{name: "gif rejected", data: []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00;"), expectedError: domain.ErrUnsupportedImage},
+		{name: "script rejected", data: []byte("<script>alert(1)</script>"), expectedError: domain.ErrUnsupportedImage},
+		{name: "truncated png rejected", data: pngData[:100], expectedError: domain.ErrUnsupportedImage},
+		{name: "over size limit", data: make([]byte, 2<<20), expectedError: domain.ErrImageTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var storedID string
+			mockProfileRepo := &mockProfileRepository{
+				setAvatarFunc: func(ctx context.Context, userID int64, avatarID string) (string, error) {
+					storedID = avatarID
+					return "", nil
+				},
+			}
+			blobStore := newMockBlobStore()
+
+			uc := NewUseCase(logger, mockProfileRepo, &mockSessionRepository{}, &mockRefreshTokenRepository{}, &mockAccessTokenRepository{}, &mockOrganizationRepository{}, &mockExportRepository{}, &mockProfileFieldRepository{}, &mockAuditUseCase{}, &mockMailer{}, sms.NewFakeSender(), blobStore,
+				Config{AvatarMaxBytes: 1 << 20})
+			avatarID, err := uc.UploadAvatar(ctx, 1, tt.data)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if tt.expectedError != nil {
+				if storedID != "" || len(blobStore.blobs) != 0 {
+					t.Error("expected nothing to be stored on failure")
+				}
+				return
+			}
+
+			if avatarID == "" || avatarID != storedID {
+				t.Fatalf("expected returned avatar id %q to be stored, got %q", avatarID, storedID)
+			}
+			for _, size := range AvatarSizes {
+				data, ok := blobStore.blobs[avatarKey(avatarID, size)]
+				if !ok {
+					t.Fatalf("missing %dpx thumbnail", size)
+				}
+				config, format, err := image.DecodeConfig(bytes.NewReader(data))
+				if err != nil {
+					t.Fatalf("thumbnail is not a valid image: %v", err)
+				}
+				if config.Width != size || config.Height != size {
+					t.Errorf("expected %dx%d thumbnail, got %dx%d", size, size, config.Width, config.Height)
+				}
+				if "image/"+format != tt.expectedContentType {
+					t.Errorf("expected %s thumbnail, got %s", tt.expectedContentType, format)
+				}
+			}
+		})
+	}
+}
+
+func TestUseCase_UploadAvatar_ReplacesPrevious(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	blobStore := newMockBlobStore()
+	blobStore.blobs[avatarKey("old", 256)] = []byte("old")
+	blobStore.blobs[avatarKey("old", 64)] = []byte("old")
+	mockProfileRepo := &mockProfileRepository{
+		setAvatarFunc: func(ctx context.Context, userID int64, avatarID string) (string, error) {
+			return "old", nil
+		},
+	}
+	mockAudit := &mockAuditUseCase{}
+
+	uc := NewUseCase(logger, mockProfileRepo, &mockSessionRepository{}, &mockRefreshTokenRepository{}, &mockAccessTokenRepository{}, &mockOrganizationRepository{}, &mockExportRepository{}, &mockProfileFieldRepository{}, mockAudit, &mockMailer{}, sms.NewFakeSender(), blobStore,
+		Config{AvatarMaxBytes: 1 << 20})
+	pngData := encodeTestImage(t, 64, 64, func(buf *bytes.Buffer, img image.Image) error { return png.Encode(buf, img) })
+	if _, err := uc.UploadAvatar(ctx, 1, pngData); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := blobStore.blobs[avatarKey("old", 256)]; ok {
+		t.Error("expected previous avatar to be deleted")
+	}
+	if len(blobStore.blobs) != len(AvatarSizes) {
+		t.Errorf("expected only the new thumbnails to remain, got %d blobs", len(blobStore.blobs))
+	}
+	if len(mockAudit.events) != 1 || mockAudit.events[0].Type != domain.AuditEventAvatarUpdate {
+		t.Errorf("expected avatar update audit event, got %v", mockAudit.events)
+	}
+}
+
+func TestUseCase_UploadAvatar_StoreFailureKeepsPrevious(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	blobStore := newMockBlobStore()
+	blobStore.putErr = errors.New("disk full")
+	mockProfileRepo := &mockProfileRepository{
+		setAvatarFunc: func(ctx context.Context, userID int64, avatarID string) (string, error) {
+			t.Error("avatar must not be switched when storing fails")
+			return "", nil
+		},
+	}
+
+	uc := NewUseCase(logger, mockProfileRepo, &mockSessionRepository{}, &mockRefreshTokenRepository{}, &mockAccessTokenRepository{}, &mockOrganizationRepository{}, &mockExportRepository{}, &mockProfileFieldRepository{}, &mockAuditUseCase{}, &mockMailer{}, sms.NewFakeSender(), blobStore,
+		Config{AvatarMaxBytes: 1 << 20})
+	pngData := encodeTestImage(t, 64, 64, func(buf *bytes.Buffer, img image.Image) error { return png.Encode(buf, img) })
+	if _, err := uc.UploadAvatar(ctx, 1, pngData); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestUseCase_GetAvatar(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	pngData := encodeTestImage(t, 8, 8, func(buf *bytes.Buffer, img image.Image) error { return png.Encode(buf, img) })
+
+	tests := []struct {
+		name                string
+		avatarID            string
+		size                int
+		format              string
+		expectedSize        int
+		expectedContentType string
+		expectedError       error
+	}{
+		{name: "default size", avatarID: "current", size: 0, expectedSize: 256, expectedContentType: "image/png"},
+		{name: "small size", avatarID: "current", size: 64, expectedSize: 64, expectedContentType: "image/png"},
+		{name: "uploaded ignores format", avatarID: "current", size: 0, format: "svg", expectedSize: 256, expectedContentType: "image/png"},
+		{name: "unknown size", avatarID: "current", size: 100, expectedError: domain.ErrInvalidAvatarSize},
+		{name: "unknown format", avatarID: "current", size: 0, format: "gif", expectedError: domain.ErrInvalidAvatarFormat},
+		{name: "missing blob", avatarID: "lost", size: 0, expectedError: domain.ErrAvatarNotFound},
+		{name: "generated png", avatarID: "", size: 64, expectedSize: 64, expectedContentType: "image/png"},
+		{name: "generated svg", avatarID: "", size: 0, format: "svg", expectedSize: 256, expectedContentType: "image/svg+xml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockProfileRepo := &mockProfileRepository{
+				getActiveProfileByUserIDFunc: func(ctx context.Context, userID int64) (*domain.Profile, error) {
+					return &domain.Profile{UserID: userID, FullName: "Ada Lovelace", AvatarID: tt.avatarID}, nil
+				},
+			}
+			blobStore := newMockBlobStore()
+			blobStore.blobs[avatarKey("current", 256)] = pngData
+			blobStore.blobs[avatarKey("current", 64)] = pngData
+
+			uc := NewUseCase(logger, mockProfileRepo, &mockSessionRepository{}, &mockRefreshTokenRepository{}, &mockAccessTokenRepository{}, &mockOrganizationRepository{}, &mockExportRepository{}, &mockProfileFieldRepository{}, &mockAuditUseCase{}, &mockMailer{}, sms.NewFakeSender(), blobStore, Config{})
+			avatar, err := uc.GetAvatar(ctx, 1, 1, tt.size, tt.format)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if tt.expectedError != nil {
+				return
+			}
+			if avatar.Size != tt.expectedSize || avatar.ContentType != tt.expectedContentType {
+				t.Errorf("unexpected avatar: size %d, type %s", avatar.Size, avatar.ContentType)
+			}
+			if tt.avatarID != "" && avatar.AvatarID != tt.avatarID {
+				t.Errorf("expected avatar id %s, got %s", tt.avatarID, avatar.AvatarID)
+			}
+			if tt.expectedContentType == "image/png" {
+				img, err := png.Decode(bytes.NewReader(avatar.Data))
+				if err != nil {
+					t.Fatalf("avatar is not a valid PNG: %v", err)
+				}
+				if img.Bounds().Dx() != tt.expectedSize && tt.avatarID == "" {
+					t.Errorf("expected %dpx generated avatar, got %dpx", tt.expectedSize, img.Bounds().Dx())
+				}
+			}
+		})
+	}
+}
+
+func TestUseCase_GetAvatar_PendingDeletion(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	mockProfileRepo := &mockProfileRepository{
+		getProfileByUserIDFunc: func(ctx context.Context, userID int64) (*domain.Profile, error) {
+			t.Error("avatars must be looked up among active accounts only")
+			return &domain.Profile{UserID: userID, AvatarID: "current"}, nil
+		},
+		getActiveProfileByUserIDFunc: func(ctx context.Context, userID int64) (*domain.Profile, error) {
+			return nil, domain.ErrUserNotExists
+		},
+	}
+
+	uc := NewUseCase(logger, mockProfileRepo, &mockSessionRepository{}, &mockRefreshTokenRepository{}, &mockAccessTokenRepository{}, &mockOrganizationRepository{}, &mockExportRepository{}, &mockProfileFieldRepository{}, &mockAuditUseCase{}, &mockMailer{}, sms.NewFakeSender(), newMockBlobStore(), Config{})
+	if _, err := uc.GetAvatar(context.Background(), 2, 1, 0, ""); !errors.Is(err, domain.ErrUserNotExists) {
+		t.Errorf("expected ErrUserNotExists, got %v", err)
+	}
+}
+
+func TestUseCase_GetAvatar_GeneratedIsDeterministic(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	fullName := "Ada Lovelace"
+	mockProfileRepo := &mockProfileRepository{
+		getActiveProfileByUserIDFunc: func(ctx context.Context, userID int64) (*domain.Profile, error) {
+			return &domain.Profile{UserID: userID, FullName: fullName}, nil
+		},
+	}
+	uc := NewUseCase(logger, mockProfileRepo, &mockSessionRepository{}, &mockRefreshTokenRepository{}, &mockAccessTokenRepository{}, &mockOrganizationRepository{}, &mockExportRepository{}, &mockProfileFieldRepository{}, &mockAuditUseCase{}, &mockMailer{}, sms.NewFakeSender(), newMockBlobStore(), Config{})
+
+	first, err := uc.GetAvatar(ctx, 1, 1, 0, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := uc.GetAvatar(ctx, 1, 1, 0, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.AvatarID != second.AvatarID || !bytes.Equal(first.Data, second.Data) {
+		t.Error("expected the same avatar for the same user")
+	}
+
+	other, err := uc.GetAvatar(ctx, 2, 2, 0, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if other.AvatarID == first.AvatarID {
+		t.Error("expected different users to get different avatars")
+	}
+
+	fullName = "Grace Hopper"
+	renamed, err := uc.GetAvatar(ctx, 1, 1, 0, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if renamed.AvatarID == first.AvatarID {
+		t.Error("expected new initials to change the avatar version")
+	}
+}
+
+func TestUseCase_GetAvatar_Visibility(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		public        bool
+		privacy       domain.ProfilePrivacy
+		viewerID      int64
+		expectedError error
+		// expectedNamed tells whether the generated avatar shows the
+		// initials rather than the nameless pattern.
+		expectedNamed bool
+	}{
+		{name: "owner sees a private profile", public: false, viewerID: 1, expectedNamed: true},
+		{name: "others do not see a private profile", public: false, viewerID: 2, expectedError: domain.ErrAvatarNotFound},
+		{name: "anonymous sees a public avatar", public: true, privacy: domain.DefaultProfilePrivacy, viewerID: 0, expectedNamed: true},
+		{name: "anonymous does not see a signed-in avatar", public: true, privacy: domain.ProfilePrivacy{Avatar: domain.VisibilitySignedIn}, viewerID: 0, expectedError: domain.ErrAvatarNotFound},
+		{name: "signed-in user sees a signed-in avatar", public: true, privacy: domain.ProfilePrivacy{FullName: domain.VisibilitySignedIn, Avatar: domain.VisibilitySignedIn}, viewerID: 2, expectedNamed: true},
+		{name: "hidden name is not drawn", public: true, privacy: domain.ProfilePrivacy{FullName: domain.VisibilityPrivate, Avatar: domain.VisibilityPublic}, viewerID: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockProfileRepo := &mockProfileRepository{
+				getActiveProfileByUserIDFunc: func(ctx context.Context, userID int64) (*domain.Profile, error) {
+					return &domain.Profile{UserID: userID, FullName: "Ada Lovelace", Public: tt.public, Privacy: tt.privacy}, nil
+				},
+			}
+			uc := NewUseCase(logger, mockProfileRepo, &mockSessionRepository{}, &mockRefreshTokenRepository{}, &mockAccessTokenRepository{}, &mockOrganizationRepository{}, &mockExportRepository{}, &mockProfileFieldRepository{}, &mockAuditUseCase{}, &mockMailer{}, sms.NewFakeSender(), newMockBlobStore(), Config{})
+
+			avatar, err := uc.GetAvatar(ctx, tt.viewerID, 1, 0, domain.AvatarFormatSVG)
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if tt.expectedError != nil {
+				return
+			}
+
+			fullName := ""
+			if tt.expectedNamed {
+				fullName = "Ada Lovelace"
+			}
+			if expected := identicon.New(1, fullName).Version(); avatar.AvatarID != expected {
+				t.Errorf("expected avatar version %s, got %s", expected, avatar.AvatarID)
+			}
+		})
+	}
+}
@@ -3,9 +3,83 @@ package profile
 import (
 	"context"
 	"server/internal/domain"
+	"time"
 )
 
 type ProfileRepository interface {
 	GetProfileByUserID(ctx context.Context, userID int64) (*domain.Profile, error)
-	UpdateProfile(ctx context.Context, profile *domain.Profile) error
+	GetActiveProfileByUserID(ctx context.Context, userID int64) (*domain.Profile, error)
+	GetProfileByHandle(ctx context.Context, handle string) (*domain.Profile, error)
+	UpdateProfile(ctx context.Context, userID, version int64, patch *domain.ProfilePatch, history []*domain.ProfileChange) error
+	GetUserByID(ctx context.Context, userID int64) (*domain.User, error)
+	ScheduleAccountDeletion(ctx context.Context, userID int64, deleteAt time.Time) error
+	DeleteUsersScheduledBefore(ctx context.Context, now time.Time) (int64, []string, error)
+	GetOAuthAccountsByUserID(ctx context.Context, userID int64) ([]*domain.OAuthAccount, error)
+	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
+	CreateEmailChangeRequest(ctx context.Context, request *domain.EmailChangeRequest) error
+	GetEmailChangeRequestByUserID(ctx context.Context, userID int64) (*domain.EmailChangeRequest, error)
+	GetEmailChangeRequestByConfirmToken(ctx context.Context, tokenHash string) (*domain.EmailChangeRequest, error)
+	GetEmailChangeRequestByCancelToken(ctx context.Context, tokenHash string) (*domain.EmailChangeRequest, error)
+	ApplyEmailChange(ctx context.Context, request *domain.EmailChangeRequest) error
+	DeleteEmailChangeRequest(ctx context.Context, requestID int64) error
+	SavePhoneVerification(ctx context.Context, verification *domain.PhoneVerification) error
+	GetPhoneVerification(ctx context.Context, userID int64) (*domain.PhoneVerification, error)
+	UsePhoneVerificationAttempt(ctx context.Context, userID int64, maxAttempts int) error
+	MarkPhoneVerified(ctx context.Context, userID int64, phone string, verifiedAt time.Time) error
+	SetAvatar(ctx context.Context, userID int64, avatarID string) (string, error)
+	GetCustomFieldValues(ctx context.Context, userID int64) (map[string]string, error)
+	ListProfileChanges(ctx context.Context, userID, beforeID int64, limit int) ([]*domain.ProfileChange, error)
+	SearchProfiles(ctx context.Context, terms []string, afterID int64, limit int) ([]*domain.Profile, error)
+}
+
+type ProfileFieldRepository interface {
+	ListFields(ctx context.Context) ([]*domain.ProfileField, error)
+}
+
+type SessionRepository interface {
+	DeleteUserSessions(ctx context.Context, userID int64) error
+	GetUserSessions(ctx context.Context, userID int64) ([]*domain.Session, error)
+}
+
+type RefreshTokenRepository interface {
+	ListUserRefreshTokens(ctx context.Context, userID int64) ([]*domain.RefreshToken, error)
+	RevokeUserRefreshTokens(ctx context.Context, userID int64, revokedAt time.Time) error
+}
+
+type AccessTokenRepository interface {
+	ListTokens(ctx context.Context, userID int64) ([]*domain.PersonalAccessToken, error)
+	DeleteUserTokens(ctx context.Context, userID int64) error
+}
+
+type OrganizationRepository interface {
+	ListUserOrganizations(ctx context.Context, userID int64) ([]*domain.OrganizationMembership, error)
+}
+
+type ExportRepository interface {
+	CreateExport(ctx context.Context, export *domain.DataExport) error
+	GetExportByID(ctx context.Context, exportID string) (*domain.DataExport, error)
+	GetLatestExportByUserID(ctx context.Context, userID int64) (*domain.DataExport, error)
+	ListPendingExports(ctx context.Context, limit int) ([]*domain.DataExport, error)
+	CompleteExport(ctx context.Context, exportID string, archive []byte, completedAt, expiresAt time.Time) error
+	FailExport(ctx context.Context, exportID string, completedAt, expiresAt time.Time) error
+	DeleteExpiredExports(ctx context.Context, now time.Time) (int64, error)
+}
+
+type AuditUseCase interface {
+	Record(ctx context.Context, event domain.AuditEvent)
+	ListUserActivity(ctx context.Context, userID int64, beforeID int64, limit int) ([]*domain.AuditEvent, error)
+}
+
+type Mailer interface {
+	Send(ctx context.Context, message domain.EmailMessage) error
+}
+
+type SMSSender interface {
+	Send(ctx context.Context, phone, message string) error
+}
+
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
 }
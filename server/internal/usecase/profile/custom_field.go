@@ -0,0 +1,153 @@
+package profile
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"server/internal/domain"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	// Time zone values are checked against the embedded database so the
+	// result does not depend on the zoneinfo installed on the host.
+	_ "time/tzdata"
+	"unicode/utf8"
+
+	"golang.org/x/text/language"
+)
+
+// Default length limits for text fields that do not set MaxLength.
+const (
+	defaultTextMaxLength     = 255
+	defaultTextAreaMaxLength = 2000
+)
+
+// customFieldPath names a custom field in field errors and audit details.
+func customFieldPath(key string) string {
+	return "custom_fields." + key
+}
+
+// validateCustomFields checks and normalizes the submitted values against
+// the field definitions, collecting problems into fieldErrors.
+func validateCustomFields(fields []*domain.ProfileField, values map[string]string, fieldErrors domain.FieldErrors) map[string]string {
+	byKey := make(map[string]*domain.ProfileField, len(fields))
+	for _, field := range fields {
+		byKey[field.Key] = field
+	}
+
+	normalized := make(map[string]string, len(values))
+	for key, value := range values {
+		field, ok := byKey[key]
+		if !ok {
+			fieldErrors[customFieldPath(key)] = "unknown field"
+			continue
+		}
+
+		value, message := validateCustomFieldValue(field, strings.TrimSpace(value))
+		if message != "" {
+			fieldErrors[customFieldPath(key)] = message
+			continue
+		}
+		normalized[key] = value
+	}
+	return normalized
+}
+
+// validateCustomFieldValue returns the value in canonical form, or a message
+// describing why it is not acceptable. An empty value clears the field.
+func validateCustomFieldValue(field *domain.ProfileField, value string) (string, string) {
+	if value == "" {
+		if field.Required {
+			return "", "is required"
+		}
+		return "", ""
+	}
+
+	rules := field.Rules
+	switch field.Type {
+	case domain.ProfileFieldTypeText, domain.ProfileFieldTypeTextArea:
+		maxLength := rules.MaxLength
+		if maxLength == 0 {
+			maxLength = defaultTextMaxLength
+			if field.Type == domain.ProfileFieldTypeTextArea {
+				maxLength = defaultTextAreaMaxLength
+			}
+		}
+		length := utf8.RuneCountInString(value)
+		if length < rules.MinLength {
+			return "", fmt.Sprintf("must be at least %d characters", rules.MinLength)
+		}
+		if length > maxLength {
+			return "", fmt.Sprintf("must be at most %d characters", maxLength)
+		}
+		if rules.Pattern != "" {
+			pattern, err := regexp.Compile("^(?:" + rules.Pattern + ")$")
+			if err != nil || !pattern.MatchString(value) {
+				return "", "has an invalid format"
+			}
+		}
+		return value, ""
+
+	case domain.ProfileFieldTypeNumber:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+			return "", "must be a number"
+		}
+		if rules.Min != nil && number < *rules.Min {
+			return "", "must be at least " + formatNumber(*rules.Min)
+		}
+		if rules.Max != nil && number > *rules.Max {
+			return "", "must be at most " + formatNumber(*rules.Max)
+		}
+		return formatNumber(number), ""
+
+	case domain.ProfileFieldTypeBoolean:
+		flag, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", "must be true or false"
+		}
+		return strconv.FormatBool(flag), ""
+
+	case domain.ProfileFieldTypeSelect:
+		if !slices.Contains(rules.Options, value) {
+			return "", "must be one of: " + strings.Join(rules.Options, ", ")
+		}
+		return value, ""
+
+	case domain.ProfileFieldTypeURL:
+		parsed, err := url.Parse(value)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return "", "must be an http or https URL"
+		}
+		if utf8.RuneCountInString(value) > defaultTextAreaMaxLength {
+			return "", fmt.Sprintf("must be at most %d characters", defaultTextAreaMaxLength)
+		}
+		return parsed.String(), ""
+
+	case domain.ProfileFieldTypeTimezone:
+		if value == "Local" {
+			return "", "must be an IANA time zone, e.g. Europe/Berlin"
+		}
+		location, err := time.LoadLocation(value)
+		if err != nil {
+			return "", "must be an IANA time zone, e.g. Europe/Berlin"
+		}
+		return location.String(), ""
+
+	case domain.ProfileFieldTypeLocale:
+		tag, err := language.Parse(value)
+		if err != nil {
+			return "", "must be a language tag, e.g. en-US"
+		}
+		return tag.String(), ""
+
+	default:
+		return "", "has an unsupported type"
+	}
+}
+
+func formatNumber(number float64) string {
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
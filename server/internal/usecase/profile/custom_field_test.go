@@ -0,0 +1,224 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"os"
+	"server/internal/domain"
+	"server/internal/gateway/sms"
+	"strings"
+	"testing"
+)
+
+func testProfileFields() []*domain.ProfileField {
+	minAge, maxAge := 18.0, 120.0
+	return []*domain.ProfileField{
+		{Key: "department", Type: domain.ProfileFieldTypeText, Required: true},
+		{Key: "bio", Type: domain.ProfileFieldTypeTextArea},
+		{Key: "age", Type: domain.ProfileFieldTypeNumber, Rules: domain.ProfileFieldRules{Min: &minAge, Max: &maxAge}},
+		{Key: "team", Type: domain.ProfileFieldTypeSelect, Rules: domain.ProfileFieldRules{Options: []string{"core", "platform"}}},
+		{Key: "timezone", Type: domain.ProfileFieldTypeTimezone},
+	}
+}
+
+func TestUseCase_PatchProfile_CustomFields(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name                string
+		customFields        map[string]string
+		expectedChanges     map[string]string
+		expectedFieldErrors []string
+	}{
+		{
+			name:            "changed values are written normalized",
+			customFields:    map[string]string{"department": "Research", "age": " 042 ", "team": "core"},
+			expectedChanges: map[string]string{"age": "42", "team": "core"},
+		},
+		{
+			name:            "empty value clears an optional field",
+			customFields:    map[string]string{"bio": ""},
+			expectedChanges: map[string]string{"bio": ""},
+		},
+		{
+			name:            "unchanged values are not written",
+			customFields:    map[string]string{"department": "Research", "bio": "Hello"},
+			expectedChanges: nil,
+		},
+		{
+			name:                "invalid values are reported per field",
+			customFields:        map[string]string{"department": "", "age": "7", "team": "sales", "timezone": "Mars/Olympus", "shoe_size": "44"},
+			expectedFieldErrors: []string{"custom_fields.department", "custom_fields.age", "custom_fields.team", "custom_fields.timezone", "custom_fields.shoe_size"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var written *domain.ProfilePatch
+			mockProfileRepo := &mockProfileRepository{
+				updateProfileFunc: func(ctx context.Context, userID, version int64, patch *domain.ProfilePatch, history []*domain.ProfileChange) error {
+					written = patch
+					return nil
+				},
+				getCustomFieldValuesFunc: func(ctx context.Context, userID int64) (map[string]string, error) {
+					return map[string]string{"department": "Research", "bio": "Hello"}, nil
+				},
+			}
+			mockFieldRepo := &mockProfileFieldRepository{
+				listFieldsFunc: func(ctx context.Context) ([]*domain.ProfileField, error) {
+					return testProfileFields(), nil
+				},
+			}
+
+			uc := NewUseCase(logger, mockProfileRepo, &mockSessionRepository{}, &mockRefreshTokenRepository{}, &mockAccessTokenRepository{}, &mockOrganizationRepository{}, &mockExportRepository{}, mockFieldRepo, &mockAuditUseCase{}, &mockMailer{}, sms.NewFakeSender(), newMockBlobStore(), Config{})
+			_, err := uc.PatchProfile(ctx, 1, 1, &domain.ProfilePatch{CustomFields: tt.customFields})
+
+			if tt.expectedFieldErrors != nil {
+				var fieldErrors domain.FieldErrors
+				if !errors.As(err, &fieldErrors) {
+					t.Fatalf("expected field errors, got %v", err)
+				}
+				for _, key := range tt.expectedFieldErrors {
+					if fieldErrors[key] == "" {
+						t.Errorf("expected an error for %s, got %v", key, fieldErrors)
+					}
+				}
+				if len(fieldErrors) != len(tt.expectedFieldErrors) {
+					t.Errorf("expected %d field errors, got %v", len(tt.expectedFieldErrors), fieldErrors)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.expectedChanges == nil {
+				if written != nil {
+					t.Errorf("expected no update, got %+v", written)
+				}
+				return
+			}
+			if written == nil {
+				t.Fatal("expected an update")
+			}
+			if !maps.Equal(written.CustomFields, tt.expectedChanges) {
+				t.Errorf("expected custom field changes %v, got %v", tt.expectedChanges, written.CustomFields)
+			}
+		})
+	}
+}
+
+func TestUseCase_UpdateProfile_CustomFields(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name            string
+		customFields    map[string]string
+		expectedChanges map[string]string
+		expectedError   bool
+	}{
+		{
+			name:            "nil leaves custom fields alone",
+			customFields:    nil,
+			expectedChanges: nil,
+		},
+		{
+			name:            "omitted fields are cleared",
+			customFields:    map[string]string{"department": "Research"},
+			expectedChanges: map[string]string{"bio": ""},
+		},
+		{
+			name:          "omitted required field is rejected",
+			customFields:  map[string]string{"bio": "Hello"},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var written *domain.ProfilePatch
+			mockProfileRepo := &mockProfileRepository{
+				getProfileByUserIDFunc: func(ctx context.Context, userID int64) (*domain.Profile, error) {
+					return &domain.Profile{UserID: userID, FullName: "Test User", Version: 1}, nil
+				},
+				updateProfileFunc: func(ctx context.Context, userID, version int64, patch *domain.ProfilePatch, history []*domain.ProfileChange) error {
+					written = patch
+					return nil
+				},
+				getCustomFieldValuesFunc: func(ctx context.Context, userID int64) (map[string]string, error) {
+					return map[string]string{"department": "Research", "bio": "Hello"}, nil
+				},
+			}
+			mockFieldRepo := &mockProfileFieldRepository{
+				listFieldsFunc: func(ctx context.Context) ([]*domain.ProfileField, error) {
+					return testProfileFields(), nil
+				},
+			}
+
+			uc := NewUseCase(logger, mockProfileRepo, &mockSessionRepository{}, &mockRefreshTokenRepository{}, &mockAccessTokenRepository{}, &mockOrganizationRepository{}, &mockExportRepository{}, mockFieldRepo, &mockAuditUseCase{}, &mockMailer{}, sms.NewFakeSender(), newMockBlobStore(), Config{})
+			_, err := uc.UpdateProfile(ctx, 1, 1, &domain.Profile{FullName: "Test User", CustomFields: tt.customFields})
+
+			if tt.expectedError {
+				var fieldErrors domain.FieldErrors
+				if !errors.As(err, &fieldErrors) || fieldErrors["custom_fields.department"] == "" {
+					t.Fatalf("expected a department field error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.expectedChanges == nil {
+				if written != nil {
+					t.Errorf("expected no update, got %+v", written)
+				}
+				return
+			}
+			if written == nil || !maps.Equal(written.CustomFields, tt.expectedChanges) {
+				t.Errorf("expected custom field changes %v, got %+v", tt.expectedChanges, written)
+			}
+		})
+	}
+}
+
+func TestValidateCustomFieldValue(t *testing.T) {
+	pattern := &domain.ProfileField{Type: domain.ProfileFieldTypeText, Rules: domain.ProfileFieldRules{Pattern: `[A-Z]{3}-\d+`, MinLength: 5}}
+
+	tests := []struct {
+		name          string
+		field         *domain.ProfileField
+		value         string
+		expectedValue string
+		expectedValid bool
+	}{
+		{name: "text within default limit", field: &domain.ProfileField{Type: domain.ProfileFieldTypeText}, value: "Research", expectedValue: "Research", expectedValid: true},
+		{name: "text over default limit", field: &domain.ProfileField{Type: domain.ProfileFieldTypeText}, value: strings.Repeat("a", 256), expectedValid: false},
+		{name: "pattern matches whole value", field: pattern, value: "ENG-42", expectedValue: "ENG-42", expectedValid: true},
+		{name: "pattern must match whole value", field: pattern, value: "xENG-42", expectedValid: false},
+		{name: "too short", field: pattern, value: "EN-4", expectedValid: false},
+		{name: "boolean normalized", field: &domain.ProfileField{Type: domain.ProfileFieldTypeBoolean}, value: "1", expectedValue: "true", expectedValid: true},
+		{name: "not a number", field: &domain.ProfileField{Type: domain.ProfileFieldTypeNumber}, value: "NaN", expectedValid: false},
+		{name: "url needs http scheme", field: &domain.ProfileField{Type: domain.ProfileFieldTypeURL}, value: "javascript:alert(1)", expectedValid: false},
+		{name: "url", field: &domain.ProfileField{Type: domain.ProfileFieldTypeURL}, value: "https://example.com/me", expectedValue: "https://example.com/me", expectedValid: true},
+		{name: "timezone", field: &domain.ProfileField{Type: domain.ProfileFieldTypeTimezone}, value: "Europe/Berlin", expectedValue: "Europe/Berlin", expectedValid: true},
+		{name: "host local timezone rejected", field: &domain.ProfileField{Type: domain.ProfileFieldTypeTimezone}, value: "Local", expectedValid: false},
+		{name: "locale canonicalized", field: &domain.ProfileField{Type: domain.ProfileFieldTypeLocale}, value: "en-us", expectedValue: "en-US", expectedValid: true},
+		{name: "invalid locale", field: &domain.ProfileField{Type: domain.ProfileFieldTypeLocale}, value: "not a locale", expectedValid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, message := validateCustomFieldValue(tt.field, tt.value)
+			if tt.expectedValid != (message == "") {
+				t.Fatalf("expected valid=%v, got message %q", tt.expectedValid, message)
+			}
+			if tt.expectedValid && value != tt.expectedValue {
+				t.Errorf("expected value %q, got %q", tt.expectedValue, value)
+			}
+		})
+	}
+}
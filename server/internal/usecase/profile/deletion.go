@@ -0,0 +1,117 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"server/internal/domain"
+	"server/internal/pkg/tracing"
+	"strconv"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// recentLoginWindow is how fresh an OAuth login must be to count as
+// re-authentication for accounts that have no password.
+const recentLoginWindow = 10 * time.Minute
+
+// revokeCredentials ends every way of acting as the user: browser and
+// bearer sessions, JWT refresh token families and personal access tokens.
+// Any of them could have been minted by whoever holds a hijacked session.
+func (uc *UseCase) revokeCredentials(ctx context.Context, userID int64) error {
+	if err := uc.sessionRepo.DeleteUserSessions(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	if err := uc.refreshTokenRepo.RevokeUserRefreshTokens(ctx, userID, time.Now()); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	if err := uc.accessTokenRepo.DeleteUserTokens(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke access tokens: %w", err)
+	}
+	return nil
+}
+
+// RequestAccountDeletion schedules the account for removal after the grace
+// period and revokes every session, JWT refresh token and personal access
+// token so the account is unusable right away. Cancelling the deletion by
+// logging in does not bring them back.
+func (uc *UseCase) RequestAccountDeletion(ctx context.Context, session *domain.Session, password string) (time.Time, error) {
+	ctx, span := tracing.Start(ctx, "profile.RequestAccountDeletion")
+	defer span.End()
+
+	user, err := uc.profileRepo.GetUserByID(ctx, session.UserID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get user by id: %w", err)
+	}
+
+	if err := reauthenticate(user, session, password); err != nil {
+		uc.auditUC.Record(ctx, domain.AuditEvent{
+			ActorUserID:   &session.UserID,
+			SubjectUserID: &session.UserID,
+			Type:          domain.AuditEventAccountDeletionRequest,
+			Outcome:       domain.AuditOutcomeFailure,
+			Details:       map[string]string{"reason": err.Error()},
+		})
+		return time.Time{}, err
+	}
+
+	deleteAt := time.Now().Add(uc.cfg.DeletionGracePeriod)
+	if err := uc.profileRepo.ScheduleAccountDeletion(ctx, session.UserID, deleteAt); err != nil {
+		return time.Time{}, fmt.Errorf("failed to schedule account deletion: %w", err)
+	}
+
+	if err := uc.revokeCredentials(ctx, session.UserID); err != nil {
+		return time.Time{}, err
+	}
+
+	uc.auditUC.Record(ctx, domain.AuditEvent{
+		ActorUserID:   &session.UserID,
+		SubjectUserID: &session.UserID,
+		Type:          domain.AuditEventAccountDeletionRequest,
+		Outcome:       domain.AuditOutcomeSuccess,
+		Details:       map[string]string{"delete_at": deleteAt.UTC().Format(time.RFC3339)},
+	})
+	return deleteAt, nil
+}
+
+func reauthenticate(user *domain.User, session *domain.Session, password string) error {
+	if user.Password != "" {
+		if password == "" {
+			return domain.ErrReauthenticationRequired
+		}
+		if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+			return domain.ErrInvalidPassword
+		}
+		return nil
+	}
+
+	if session.AuthMethod != domain.AuthMethodGoogle || time.Since(session.CreatedAt) > recentLoginWindow {
+		return domain.ErrReauthenticationRequired
+	}
+	return nil
+}
+
+// PurgeScheduledDeletions hard-deletes every account whose grace period is over.
+func (uc *UseCase) PurgeScheduledDeletions(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "profile.PurgeScheduledDeletions")
+	defer span.End()
+
+	deleted, avatarIDs, err := uc.profileRepo.DeleteUsersScheduledBefore(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to delete scheduled users: %w", err)
+	}
+	for _, avatarID := range avatarIDs {
+		uc.deleteAvatarBlobs(ctx, avatarID)
+	}
+	if deleted == 0 {
+		return nil
+	}
+
+	uc.logger.InfoContext(ctx, "purged deleted accounts", "count", deleted)
+	uc.auditUC.Record(ctx, domain.AuditEvent{
+		Type:    domain.AuditEventAccountDelete,
+		Outcome: domain.AuditOutcomeSuccess,
+		Details: map[string]string{"count": strconv.FormatInt(deleted, 10)},
+	})
+	return nil
+}
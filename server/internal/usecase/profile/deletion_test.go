@@ -0,0 +1,156 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"server/internal/domain"
+	"server/internal/gateway/sms"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestUseCase_RequestAccountDeletion(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+	passwordHash, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+
+	tests := []struct {
+		name            string
+		userPassword    string
+		session         *domain.Session
+		password        string
+		expectedError   error
+		expectScheduled bool
+	}{
+		{
+			name:            "correct password",
+			userPassword:    string(passwordHash),
+			session:         &domain.Session{UserID: 1, AuthMethod: domain.AuthMethodPassword, CreatedAt: time.Now().Add(-time.Hour)},
+			password:        "password123",
+			expectedError:   nil,
+			expectScheduled: true,
+		},
+		{
+			name:            "wrong password",
+			userPassword:    string(passwordHash),
+			session:         &domain.Session{UserID: 1, AuthMethod: domain.AuthMethodPassword, CreatedAt: time.Now()},
+			password:        "wrong",
+			expectedError:   domain.ErrInvalidPassword,
+			expectScheduled: false,
+		},
+		{
+			name:            "missing password",
+			userPassword:    string(passwordHash),
+			session:         &domain.Session{UserID: 1, AuthMethod: domain.AuthMethodGoogle, CreatedAt: time.Now()},
+			password:        "",
+			expectedError:   domain.ErrReauthenticationRequired,
+			expectScheduled: false,
+		},
+		{
+			name:            "recent google login",
+			userPassword:    "",
+			session:         &domain.Session{UserID: 1, AuthMethod: domain.AuthMethodGoogle, CreatedAt: time.Now().Add(-time.Minute)},
+			password:        "",
+			expectedError:   nil,
+			expectScheduled: true,
+		},
+		{
+			name:            "stale google login",
+			userPassword:    "",
+			session:         &domain.Session{UserID: 1, AuthMethod: domain.AuthMethodGoogle, CreatedAt: time.Now().Add(-time.Hour)},
+			password:        "",
+			expectedError:   domain.ErrReauthenticationRequired,
+			expectScheduled: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduled := false
+			revoked := false
+			refreshRevoked := false
+			accessTokensRevoked := false
+			mockProfileRepo := &mockProfileRepository{
+				getUserByIDFunc: func(ctx context.Context, userID int64) (*domain.User, error) {
+					return &domain.User{ID: userID, Password: tt.userPassword}, nil
+				},
+				scheduleAccountDeletionFunc: func(ctx context.Context, userID int64, deleteAt time.Time) error {
+					scheduled = true
+					if time.Until(deleteAt) < 23*time.Hour {
+						t.Errorf("expected deletion after grace period, got %v", deleteAt)
+					}
+					return nil
+				},
+			}
+			mockSessionRepo := &mockSessionRepository{
+				deleteUserSessionsFunc: func(ctx context.Context, userID int64) error {
+					revoked = true
+					return nil
+				},
+			}
+			mockRefreshTokenRepo := &mockRefreshTokenRepository{
+				revokeUserRefreshTokensFunc: func(ctx context.Context, userID int64, revokedAt time.Time) error {
+					refreshRevoked = true
+					return nil
+				},
+			}
+			mockAccessTokenRepo := &mockAccessTokenRepository{
+				deleteUserTokensFunc: func(ctx context.Context, userID int64) error {
+					accessTokensRevoked = true
+					return nil
+				},
+			}
+
+			uc := NewUseCase(logger, mockProfileRepo, mockSessionRepo, mockRefreshTokenRepo, mockAccessTokenRepo, &mockOrganizationRepository{}, &mockExportRepository{}, &mockProfileFieldRepository{}, &mockAuditUseCase{}, &mockMailer{}, sms.NewFakeSender(), newMockBlobStore(), Config{DeletionGracePeriod: 24 * time.Hour})
+			_, err := uc.RequestAccountDeletion(ctx, tt.session, tt.password)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if scheduled != tt.expectScheduled {
+				t.Errorf("expected scheduled %v, got %v", tt.expectScheduled, scheduled)
+			}
+			if revoked != tt.expectScheduled {
+				t.Errorf("expected sessions revoked %v, got %v", tt.expectScheduled, revoked)
+			}
+			if refreshRevoked != tt.expectScheduled {
+				t.Errorf("expected refresh tokens revoked %v, got %v", tt.expectScheduled, refreshRevoked)
+			}
+			if accessTokensRevoked != tt.expectScheduled {
+				t.Errorf("expected access tokens revoked %v, got %v", tt.expectScheduled, accessTokensRevoked)
+			}
+		})
+	}
+}
+
+func TestUseCase_PurgeScheduledDeletions(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	mockProfileRepo := &mockProfileRepository{
+		deleteUsersScheduledBeforeFunc: func(ctx context.Context, now time.Time) (int64, []string, error) {
+			return 2, []string{"avatar-1"}, nil
+		},
+	}
+	mockAudit := &mockAuditUseCase{}
+	blobStore := newMockBlobStore()
+	blobStore.blobs["avatars/avatar-1/256"] = []byte("large")
+	blobStore.blobs["avatars/avatar-1/64"] = []byte("small")
+	blobStore.blobs["avatars/avatar-2/256"] = []byte("someone else")
+
+	uc := NewUseCase(logger, mockProfileRepo, &mockSessionRepository{}, &mockRefreshTokenRepository{}, &mockAccessTokenRepository{}, &mockOrganizationRepository{}, &mockExportRepository{}, &mockProfileFieldRepository{}, mockAudit, &mockMailer{}, sms.NewFakeSender(), blobStore, Config{})
+	if err := uc.PurgeScheduledDeletions(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(mockAudit.events) != 1 || mockAudit.events[0].Details["count"] != "2" {
+		t.Errorf("expected one purge audit event with count 2, got %v", mockAudit.events)
+	}
+	if len(blobStore.blobs) != 1 {
+		t.Errorf("expected only the avatar of the remaining user to be kept, got %v", blobStore.blobs)
+	}
+}
@@ -0,0 +1,70 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"server/internal/domain"
+	"server/internal/pkg/tracing"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxSearchQueryLength = 100
+	minSearchTermLength  = 2
+	maxSearchTerms       = 5
+)
+
+// SearchDirectory looks signed-in users up by name or email. Only public
+// profiles are found, and only by the fields viewerID may see. Searches are
+// rate limited per user to make scraping the directory slow.
+func (uc *UseCase) SearchDirectory(ctx context.Context, viewerID int64, query string, afterID int64, limit int) ([]*domain.PublicProfile, error) {
+	ctx, span := tracing.Start(ctx, "profile.SearchDirectory")
+	defer span.End()
+
+	// Keyed by user, not session: signing in again must not reset the limit.
+	if !uc.directoryLimiter.Allow(strconv.FormatInt(viewerID, 10)) {
+		return nil, domain.ErrRateLimited
+	}
+
+	terms, message := searchTerms(query)
+	if message != "" {
+		return nil, domain.FieldErrors{"q": message}
+	}
+
+	profiles, err := uc.profileRepo.SearchProfiles(ctx, terms, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search profiles: %w", err)
+	}
+
+	results := make([]*domain.PublicProfile, 0, len(profiles))
+	for _, profile := range profiles {
+		results = append(results, visibleProfile(profile, viewerID))
+	}
+	return results, nil
+}
+
+// searchTerms splits the query into words, dropping those too short to be
+// matched, and returns a message if nothing searchable is left.
+func searchTerms(query string) ([]string, string) {
+	query = strings.TrimSpace(query)
+	if utf8.RuneCountInString(query) > maxSearchQueryLength {
+		return nil, fmt.Sprintf("must be at most %d characters", maxSearchQueryLength)
+	}
+
+	var terms []string
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		if utf8.RuneCountInString(word) < minSearchTermLength {
+			continue
+		}
+		terms = append(terms, word)
+		if len(terms) == maxSearchTerms {
+			break
+		}
+	}
+	if len(terms) == 0 {
+		return nil, fmt.Sprintf("must contain a word of at least %d characters", minSearchTermLength)
+	}
+	return terms, ""
+}
@@ -0,0 +1,126 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"server/internal/domain"
+	"server/internal/gateway/sms"
+	appcontext "server/internal/pkg/context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUseCase_SearchDirectory(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		query         string
+		expectedTerms []string
+		expectedField bool
+	}{
+		{
+			name:          "words are lower-cased and short ones dropped",
+			query:         "  Ada L Lovelace ",
+			expectedTerms: []string{"ada", "lovelace"},
+		},
+		{
+			name:          "email search",
+			query:         "ada@example",
+			expectedTerms: []string{"ada@example"},
+		},
+		{
+			name:          "nothing searchable",
+			query:         "a b",
+			expectedField: true,
+		},
+		{
+			name:          "too long",
+			query:         strings.Repeat("a", maxSearchQueryLength+1),
+			expectedField: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var searchedTerms []string
+			mockProfileRepo := &mockProfileRepository{
+				searchProfilesFunc: func(ctx context.Context, terms []string, afterID int64, limit int) ([]*domain.Profile, error) {
+					searchedTerms = terms
+					if afterID != 5 || limit != 10 {
+						t.Errorf("expected paging 5/10, got %d/%d", afterID, limit)
+					}
+					return []*domain.Profile{{
+						UserID:   1,
+						Handle:   "ada",
+						Email:    "ada@example.com",
+						FullName: "Ada Lovelace",
+						Phone:    "+14155550132",
+						Public:   true,
+						Privacy: domain.ProfilePrivacy{
+							FullName: domain.VisibilityPublic,
+							Email:    domain.VisibilitySignedIn,
+							Phone:    domain.VisibilityPrivate,
+							Avatar:   domain.VisibilityPrivate,
+						},
+					}}, nil
+				},
+			}
+
+			uc := NewUseCase(logger, mockProfileRepo, &mockSessionRepository{}, &mockRefreshTokenRepository{}, &mockAccessTokenRepository{}, &mockOrganizationRepository{}, &mockExportRepository{}, &mockProfileFieldRepository{}, &mockAuditUseCase{}, &mockMailer{}, sms.NewFakeSender(), newMockBlobStore(), Config{})
+			results, err := uc.SearchDirectory(ctx, 2, tt.query, 5, 10)
+
+			if tt.expectedField {
+				var fieldErrs domain.FieldErrors
+				if !errors.As(err, &fieldErrs) || fieldErrs["q"] == "" {
+					t.Fatalf("expected a q field error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(searchedTerms, tt.expectedTerms) {
+				t.Errorf("expected terms %q, got %q", tt.expectedTerms, searchedTerms)
+			}
+			if len(results) != 1 {
+				t.Fatalf("expected 1 result, got %d", len(results))
+			}
+			result := results[0]
+			if result.FullName != "Ada Lovelace" || result.Email != "ada@example.com" || result.Phone != "" || result.AvatarVisible {
+				t.Errorf("expected only the name and email to be visible, got %+v", *result)
+			}
+		})
+	}
+}
+
+func TestUseCase_SearchDirectory_RateLimited(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	uc := NewUseCase(logger, &mockProfileRepository{}, &mockSessionRepository{}, &mockRefreshTokenRepository{}, &mockAccessTokenRepository{}, &mockOrganizationRepository{}, &mockExportRepository{}, &mockProfileFieldRepository{}, &mockAuditUseCase{}, &mockMailer{}, sms.NewFakeSender(), newMockBlobStore(),
+		Config{DirectorySearchLimit: 2, DirectorySearchPeriod: time.Hour})
+
+	first := appcontext.WithSession(context.Background(), &domain.Session{Token: "first", UserID: 2})
+	second := appcontext.WithSession(context.Background(), &domain.Session{Token: "second", UserID: 2})
+	other := appcontext.WithSession(context.Background(), &domain.Session{Token: "other", UserID: 3})
+
+	for i := 0; i < 2; i++ {
+		if _, err := uc.SearchDirectory(first, 2, "ada", 0, 10); err != nil {
+			t.Fatalf("search %d: unexpected error: %v", i+1, err)
+		}
+	}
+	if _, err := uc.SearchDirectory(first, 2, "ada", 0, 10); !errors.Is(err, domain.ErrRateLimited) {
+		t.Errorf("expected ErrRateLimited, got %v", err)
+	}
+	if _, err := uc.SearchDirectory(second, 2, "ada", 0, 10); !errors.Is(err, domain.ErrRateLimited) {
+		t.Errorf("expected a new session of the same user to share the limit, got %v", err)
+	}
+	if _, err := uc.SearchDirectory(other, 3, "ada", 0, 10); err != nil {
+		t.Errorf("expected another user to have their own limit, got %v", err)
+	}
+}
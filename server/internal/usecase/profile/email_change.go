@@ -0,0 +1,213 @@
+package profile
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"server/internal/domain"
+	"server/internal/pkg/tracing"
+	"server/internal/pkg/validation"
+	"strings"
+	"time"
+)
+
+const (
+	emailChangeTokenBytes = 32
+	confirmEmailPath      = "/api/profile/email/confirm"
+	cancelEmailPath       = "/api/profile/email/cancel"
+)
+
+// RequestEmailChange starts an email change. Nothing is written to the user
+// row until the new address is confirmed; the old address gets a link to
+// cancel the change in case the session was hijacked.
+func (uc *UseCase) RequestEmailChange(ctx context.Context, userID int64, newEmail string) (*domain.EmailChangeRequest, error) {
+	ctx, span := tracing.Start(ctx, "profile.RequestEmailChange")
+	defer span.End()
+
+	newEmail = strings.TrimSpace(newEmail)
+	if !validation.IsValidEmail(newEmail) {
+		return nil, domain.ErrNotValidEmail
+	}
+
+	current, err := uc.profileRepo.GetProfileByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current profile: %w", err)
+	}
+	if strings.EqualFold(current.Email, newEmail) {
+		return nil, domain.ErrEmailUnchanged
+	}
+
+	_, err = uc.profileRepo.GetUserByEmail(ctx, newEmail)
+	if err == nil {
+		return nil, domain.ErrUserAlreadyExists
+	}
+	if !errors.Is(err, domain.ErrUserNotExists) {
+		return nil, fmt.Errorf("failed to check email availability: %w", err)
+	}
+
+	confirmToken, err := generateEmailChangeToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate confirm token: %w", err)
+	}
+	cancelToken, err := generateEmailChangeToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate cancel token: %w", err)
+	}
+
+	now := time.Now()
+	request := &domain.EmailChangeRequest{
+		UserID:           userID,
+		OldEmail:         current.Email,
+		NewEmail:         newEmail,
+		ConfirmTokenHash: hashEmailChangeToken(confirmToken),
+		CancelTokenHash:  hashEmailChangeToken(cancelToken),
+		CreatedAt:        now,
+		ExpiresAt:        now.Add(uc.cfg.EmailChangeTTL),
+	}
+	if err := uc.profileRepo.CreateEmailChangeRequest(ctx, request); err != nil {
+		return nil, fmt.Errorf("failed to create email change request: %w", err)
+	}
+
+	err = uc.mailer.Send(ctx, domain.EmailMessage{
+		To:      request.NewEmail,
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf("Open the link below to use this address for your account:\n\n%s\n\nThe link expires on %s.",
+			uc.emailChangeLink(confirmEmailPath, confirmToken), request.ExpiresAt.UTC().Format(time.RFC1123)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send confirmation email: %w", err)
+	}
+
+	if request.OldEmail != "" {
+		err = uc.mailer.Send(ctx, domain.EmailMessage{
+			To:      request.OldEmail,
+			Subject: "Your email address is being changed",
+			Body: fmt.Sprintf("A change of your account email to %s was requested. If this was not you, cancel it and sign out all sessions:\n\n%s",
+				request.NewEmail, uc.emailChangeLink(cancelEmailPath, cancelToken)),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to send notification email: %w", err)
+		}
+	}
+
+	uc.auditUC.Record(ctx, domain.AuditEvent{
+		ActorUserID:   &userID,
+		SubjectUserID: &userID,
+		Type:          domain.AuditEventEmailChangeRequest,
+		Outcome:       domain.AuditOutcomeSuccess,
+		Details:       map[string]string{"old_email": request.OldEmail, "new_email": request.NewEmail},
+	})
+	return request, nil
+}
+
+// ConfirmEmailChange applies the pending change. Uniqueness is checked again
+// here because the address may have been registered since the request.
+func (uc *UseCase) ConfirmEmailChange(ctx context.Context, token string) error {
+	ctx, span := tracing.Start(ctx, "profile.ConfirmEmailChange")
+	defer span.End()
+
+	request, err := uc.profileRepo.GetEmailChangeRequestByConfirmToken(ctx, hashEmailChangeToken(token))
+	if err != nil {
+		if errors.Is(err, domain.ErrEmailChangeNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to get email change request: %w", err)
+	}
+
+	if request.IsExpired(time.Now()) {
+		if err := uc.profileRepo.DeleteEmailChangeRequest(ctx, request.ID); err != nil {
+			return fmt.Errorf("failed to delete email change request: %w", err)
+		}
+		return domain.ErrEmailChangeExpired
+	}
+
+	if err := uc.profileRepo.ApplyEmailChange(ctx, request); err != nil {
+		if errors.Is(err, domain.ErrUserAlreadyExists) || errors.Is(err, domain.ErrEmailChangeNotFound) {
+			uc.auditUC.Record(ctx, domain.AuditEvent{
+				ActorUserID:   &request.UserID,
+				SubjectUserID: &request.UserID,
+				Type:          domain.AuditEventEmailChange,
+				Outcome:       domain.AuditOutcomeFailure,
+				Details:       map[string]string{"new_email": request.NewEmail, "reason": err.Error()},
+			})
+			return err
+		}
+		return fmt.Errorf("failed to apply email change: %w", err)
+	}
+
+	uc.auditUC.Record(ctx, domain.AuditEvent{
+		ActorUserID:   &request.UserID,
+		SubjectUserID: &request.UserID,
+		Type:          domain.AuditEventEmailChange,
+		Outcome:       domain.AuditOutcomeSuccess,
+		Details:       map[string]string{"old_email": request.OldEmail, "new_email": request.NewEmail},
+	})
+	return nil
+}
+
+// CancelEmailChange drops the pending change from the link sent to the old
+// address. Every session and token is revoked since the request may come
+// from an attacker holding one of them.
+func (uc *UseCase) CancelEmailChange(ctx context.Context, token string) error {
+	ctx, span := tracing.Start(ctx, "profile.CancelEmailChange")
+	defer span.End()
+
+	request, err := uc.profileRepo.GetEmailChangeRequestByCancelToken(ctx, hashEmailChangeToken(token))
+	if err != nil {
+		if errors.Is(err, domain.ErrEmailChangeNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to get email change request: %w", err)
+	}
+
+	if err := uc.profileRepo.DeleteEmailChangeRequest(ctx, request.ID); err != nil {
+		return fmt.Errorf("failed to delete email change request: %w", err)
+	}
+	if err := uc.revokeCredentials(ctx, request.UserID); err != nil {
+		return err
+	}
+
+	uc.auditUC.Record(ctx, domain.AuditEvent{
+		SubjectUserID: &request.UserID,
+		Type:          domain.AuditEventEmailChangeCancel,
+		Outcome:       domain.AuditOutcomeSuccess,
+		Details:       map[string]string{"new_email": request.NewEmail},
+	})
+	return nil
+}
+
+func (uc *UseCase) getPendingEmail(ctx context.Context, userID int64) (string, error) {
+	request, err := uc.profileRepo.GetEmailChangeRequestByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrEmailChangeNotFound) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get email change request: %w", err)
+	}
+	if request.IsExpired(time.Now()) {
+		return "", nil
+	}
+	return request.NewEmail, nil
+}
+
+func (uc *UseCase) emailChangeLink(path, token string) string {
+	return strings.TrimRight(uc.cfg.LinkBaseURL, "/") + path + "?" + url.Values{"token": {token}}.Encode()
+}
+
+func generateEmailChangeToken() (string, error) {
+	b := make([]byte, emailChangeTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashEmailChangeToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
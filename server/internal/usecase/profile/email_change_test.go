@@ -0,0 +1,299 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"server/internal/domain"
+	"server/internal/gateway/sms"
+	"server/internal/usecase/accesstoken"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUseCase_RequestEmailChange(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		newEmail      string
+		emailTaken    bool
+		expectedError error
+	}{
+		{
+			name:          "successful request",
+			newEmail:      "new@example.com",
+			expectedError: nil,
+		},
+		{
+			name:          "invalid email",
+			newEmail:      "not-an-email",
+			expectedError: domain.ErrNotValidEmail,
+		},
+		{
+			name:          "same email",
+			newEmail:      "OLD@example.com",
+			expectedError: domain.ErrEmailUnchanged,
+		},
+		{
+			name:          "email taken",
+			newEmail:      "taken@example.com",
+			emailTaken:    true,
+			expectedError: domain.ErrUserAlreadyExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created *domain.EmailChangeRequest
+			mockProfileRepo := &mockProfileRepository{
+				getProfileByUserIDFunc: func(ctx context.Context, userID int64) (*domain.Profile, error) {
+					return &domain.Profile{UserID: userID, Email: "old@example.com"}, nil
+				},
+				getUserByEmailFunc: func(ctx context.Context, email string) (*domain.User, error) {
+					if tt.emailTaken {
+						return &domain.User{ID: 2, Email: email}, nil
+					}
+					return nil, domain.ErrUserNotExists
+				},
+				createEmailChangeRequestFunc: func(ctx context.Context, request *domain.EmailChangeRequest) error {
+					created = request
+					return nil
+				},
+				updateProfileFunc: func(ctx context.Context, userID, version int64, patch *domain.ProfilePatch, history []*domain.ProfileChange) error {
+					t.Error("profile must not be updated before confirmation")
+					return nil
+				},
+			}
+			mailer := &mockMailer{}
+
+			uc := NewUseCase(logger, mockProfileRepo, &mockSessionRepository{}, &mockRefreshTokenRepository{}, &mockAccessTokenRepository{}, &mockOrganizationRepository{}, &mockExportRepository{}, &mockProfileFieldRepository{}, &mockAuditUseCase{}, mailer, sms.NewFakeSender(), newMockBlobStore(),
+				Config{EmailChangeTTL: time.Hour, LinkBaseURL: "http://localhost:8080/"})
+			_, err := uc.RequestEmailChange(ctx, 1, tt.newEmail)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if tt.expectedError != nil {
+				if created != nil || len(mailer.messages) != 0 {
+					t.Error("expected no request and no mail on failure")
+				}
+				return
+			}
+
+			if created.OldEmail != "old@example.com" || created.NewEmail != "new@example.com" {
+				t.Errorf("unexpected request: %+v", created)
+			}
+			if len(mailer.messages) != 2 {
+				t.Fatalf("expected 2 emails, got %d", len(mailer.messages))
+			}
+			if mailer.messages[0].To != "new@example.com" || !strings.Contains(mailer.messages[0].Body, "http://localhost:8080/api/profile/email/confirm?token=") {
+				t.Errorf("unexpected confirmation email: %+v", mailer.messages[0])
+			}
+			if mailer.messages[1].To != "old@example.com" || !strings.Contains(mailer.messages[1].Body, "/api/profile/email/cancel?token=") {
+				t.Errorf("unexpected notification email: %+v", mailer.messages[1])
+			}
+			if strings.Contains(mailer.messages[0].Body, created.ConfirmTokenHash) {
+				t.Error("email must carry the token, not its stored hash")
+			}
+		})
+	}
+}
+
+func TestUseCase_ConfirmEmailChange(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		expiresAt     time.Time
+		applyErr      error
+		expectedError error
+		expectApplied bool
+	}{
+		{
+			name:          "successful confirmation",
+			expiresAt:     time.Now().Add(time.Hour),
+			expectedError: nil,
+			expectApplied: true,
+		},
+		{
+			name:          "expired request",
+			expiresAt:     time.Now().Add(-time.Minute),
+			expectedError: domain.ErrEmailChangeExpired,
+			expectApplied: false,
+		},
+		{
+			name:          "email taken meanwhile",
+			expiresAt:     time.Now().Add(time.Hour),
+			applyErr:      domain.ErrUserAlreadyExists,
+			expectedError: domain.ErrUserAlreadyExists,
+			expectApplied: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			applied := false
+			mockProfileRepo := &mockProfileRepository{
+				getEmailChangeByConfirmFunc: func(ctx context.Context, tokenHash string) (*domain.EmailChangeRequest, error) {
+					if tokenHash != hashEmailChangeToken("token") {
+						t.Errorf("expected lookup by token hash, got %s", tokenHash)
+					}
+					return &domain.EmailChangeRequest{ID: 1, UserID: 1, OldEmail: "old@example.com", NewEmail: "new@example.com", ExpiresAt: tt.expiresAt}, nil
+				},
+				applyEmailChangeFunc: func(ctx context.Context, request *domain.EmailChangeRequest) error {
+					applied = true
+					return tt.applyErr
+				},
+			}
+			mockAudit := &mockAuditUseCase{}
+
+			uc := NewUseCase(logger, mockProfileRepo, &mockSessionRepository{}, &mockRefreshTokenRepository{}, &mockAccessTokenRepository{}, &mockOrganizationRepository{}, &mockExportRepository{}, &mockProfileFieldRepository{}, mockAudit, &mockMailer{}, sms.NewFakeSender(), newMockBlobStore(), Config{})
+			err := uc.ConfirmEmailChange(ctx, "token")
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if applied != tt.expectApplied {
+				t.Errorf("expected applied %v, got %v", tt.expectApplied, applied)
+			}
+			if tt.expectedError == nil && (len(mockAudit.events) != 1 || mockAudit.events[0].Type != domain.AuditEventEmailChange) {
+				t.Errorf("expected an email change audit event, got %v", mockAudit.events)
+			}
+		})
+	}
+}
+
+func TestUseCase_CancelEmailChange(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	deleted := false
+	revoked := false
+	mockProfileRepo := &mockProfileRepository{
+		getEmailChangeByCancelFunc: func(ctx context.Context, tokenHash string) (*domain.EmailChangeRequest, error) {
+			return &domain.EmailChangeRequest{ID: 5, UserID: 1, NewEmail: "new@example.com"}, nil
+		},
+		deleteEmailChangeRequestFunc: func(ctx context.Context, requestID int64) error {
+			deleted = requestID == 5
+			return nil
+		},
+	}
+	mockSessionRepo := &mockSessionRepository{
+		deleteUserSessionsFunc: func(ctx context.Context, userID int64) error {
+			revoked = userID == 1
+			return nil
+		},
+	}
+
+	uc := NewUseCase(logger, mockProfileRepo, mockSessionRepo, &mockRefreshTokenRepository{}, &mockAccessTokenRepository{}, &mockOrganizationRepository{}, &mockExportRepository{}, &mockProfileFieldRepository{}, &mockAuditUseCase{}, &mockMailer{}, sms.NewFakeSender(), newMockBlobStore(), Config{})
+	if err := uc.CancelEmailChange(ctx, "token"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !deleted {
+		t.Error("expected request to be deleted")
+	}
+	if !revoked {
+		t.Error("expected sessions to be revoked")
+	}
+}
+
+// memoryAccessTokenRepository keeps tokens in memory so the profile and
+// access token use cases can share them within a test.
+type memoryAccessTokenRepository struct {
+	tokens map[string]*domain.PersonalAccessToken
+}
+
+func (m *memoryAccessTokenRepository) CreateToken(ctx context.Context, token *domain.PersonalAccessToken, maxTokens int) error {
+	token.ID = int64(len(m.tokens) + 1)
+	m.tokens[token.TokenHash] = token
+	return nil
+}
+
+func (m *memoryAccessTokenRepository) ListTokens(ctx context.Context, userID int64) ([]*domain.PersonalAccessToken, error) {
+	tokens := []*domain.PersonalAccessToken{}
+	for _, token := range m.tokens {
+		if token.UserID == userID {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+func (m *memoryAccessTokenRepository) GetTokenByHash(ctx context.Context, tokenHash string) (*domain.PersonalAccessToken, error) {
+	token, ok := m.tokens[tokenHash]
+	if !ok {
+		return nil, domain.ErrAccessTokenNotFound
+	}
+	return token, nil
+}
+
+func (m *memoryAccessTokenRepository) DeleteToken(ctx context.Context, userID, id int64) error {
+	for hash, token := range m.tokens {
+		if token.UserID == userID && token.ID == id {
+			delete(m.tokens, hash)
+			return nil
+		}
+	}
+	return domain.ErrAccessTokenNotFound
+}
+
+func (m *memoryAccessTokenRepository) DeleteUserTokens(ctx context.Context, userID int64) error {
+	for hash, token := range m.tokens {
+		if token.UserID == userID {
+			delete(m.tokens, hash)
+		}
+	}
+	return nil
+}
+
+func (m *memoryAccessTokenRepository) TouchToken(ctx context.Context, id int64, usedAt time.Time) error {
+	return nil
+}
+
+// TestUseCase_CancelEmailChangeRevokesTokens covers a takeover where the
+// attacker used a hijacked session to mint long-lived credentials before
+// the victim cancelled the change.
+func TestUseCase_CancelEmailChangeRevokesTokens(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	tokens := &memoryAccessTokenRepository{tokens: map[string]*domain.PersonalAccessToken{}}
+	accessTokenUC := accesstoken.NewUseCase(logger, tokens, &mockAuditUseCase{})
+	value, _, err := accessTokenUC.CreateToken(ctx, 1, "attacker", []domain.TokenScope{domain.ScopeProfileWrite}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := accessTokenUC.Authenticate(ctx, value); err != nil {
+		t.Fatalf("expected the token to work before the cancel, got %v", err)
+	}
+
+	refreshRevoked := false
+	mockProfileRepo := &mockProfileRepository{
+		getEmailChangeByCancelFunc: func(ctx context.Context, tokenHash string) (*domain.EmailChangeRequest, error) {
+			return &domain.EmailChangeRequest{ID: 5, UserID: 1, NewEmail: "attacker@example.com"}, nil
+		},
+	}
+	mockRefreshTokenRepo := &mockRefreshTokenRepository{
+		revokeUserRefreshTokensFunc: func(ctx context.Context, userID int64, revokedAt time.Time) error {
+			refreshRevoked = userID == 1
+			return nil
+		},
+	}
+
+	uc := NewUseCase(logger, mockProfileRepo, &mockSessionRepository{}, mockRefreshTokenRepo, tokens, &mockOrganizationRepository{}, &mockExportRepository{}, &mockProfileFieldRepository{}, &mockAuditUseCase{}, &mockMailer{}, sms.NewFakeSender(), newMockBlobStore(), Config{})
+	if err := uc.CancelEmailChange(ctx, "token"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := accessTokenUC.Authenticate(ctx, value); !errors.Is(err, domain.ErrSessionNotFound) {
+		t.Errorf("expected the access token to stop working, got %v", err)
+	}
+	if !refreshRevoked {
+		t.Error("expected refresh tokens to be revoked")
+	}
+}
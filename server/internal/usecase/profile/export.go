@@ -0,0 +1,439 @@
+package profile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"server/internal/domain"
+	"server/internal/pkg/tracing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	exportBatchSize = 10
+	exportPageLimit = 500
+)
+
+// userDataArchive is everything we hold about a user, as handed out by the
+// self-service data export. Every table that holds rows of a user has to
+// show up here; export_test.go checks that against db/create_db.sql.
+type userDataArchive struct {
+	GeneratedAt        time.Time                          `json:"generated_at"`
+	User               userDataArchiveUser                `json:"user"`
+	PendingEmailChange *userDataArchiveEmailChange        `json:"pending_email_change"`
+	PendingPhone       *userDataArchivePhoneVerification  `json:"pending_phone_verification"`
+	ProfileHistory     []userDataArchiveProfileChange     `json:"profile_history"`
+	OAuthAccounts      []userDataArchiveOAuthAccount      `json:"oauth_accounts"`
+	Sessions           []userDataArchiveSession           `json:"sessions"`
+	RefreshTokens      []userDataArchiveRefreshToken      `json:"refresh_tokens"`
+	AccessTokens       []userDataArchiveAccessToken       `json:"access_tokens"`
+	Organizations      []userDataArchiveOrganizationEntry `json:"organizations"`
+	AuditEvents        []userDataArchiveAuditEvent        `json:"audit_events"`
+}
+
+type userDataArchiveUser struct {
+	ID              int64                  `json:"id"`
+	Email           string                 `json:"email"`
+	FullName        string                 `json:"full_name"`
+	Phone           string                 `json:"phone"`
+	PhoneVerifiedAt *time.Time             `json:"phone_verified_at"`
+	Handle          string                 `json:"handle"`
+	PublicProfile   bool                   `json:"public_profile"`
+	Privacy         userDataArchivePrivacy `json:"privacy"`
+	AvatarID        string                 `json:"avatar_id"`
+	CustomFields    map[string]string      `json:"custom_fields"`
+}
+
+type userDataArchivePrivacy struct {
+	FullName string `json:"full_name"`
+	Email    string `json:"email"`
+	Phone    string `json:"phone"`
+	Avatar   string `json:"avatar"`
+}
+
+type userDataArchiveEmailChange struct {
+	NewEmail  string    `json:"new_email"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type userDataArchivePhoneVerification struct {
+	Phone     string    `json:"phone"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type userDataArchiveProfileChange struct {
+	Field     string    `json:"field"`
+	OldValue  string    `json:"old_value"`
+	NewValue  string    `json:"new_value"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type userDataArchiveOAuthAccount struct {
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type userDataArchiveSession struct {
+	AuthMethod string    `json:"auth_method"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+type userDataArchiveRefreshToken struct {
+	AuthMethod string     `json:"auth_method"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	UsedAt     *time.Time `json:"used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+type userDataArchiveAccessToken struct {
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+type userDataArchiveOrganizationEntry struct {
+	ID       int64     `json:"id"`
+	Name     string    `json:"name"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+type userDataArchiveAuditEvent struct {
+	Type      string            `json:"type"`
+	Outcome   string            `json:"outcome"`
+	IP        string            `json:"ip"`
+	UserAgent string            `json:"user_agent"`
+	Details   map[string]string `json:"details"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// RequestDataExport queues a new export for the user. An export that is still
+// waiting to be built is returned as is instead of queueing a duplicate.
+func (uc *UseCase) RequestDataExport(ctx context.Context, userID int64) (*domain.DataExport, error) {
+	ctx, span := tracing.Start(ctx, "profile.RequestDataExport")
+	defer span.End()
+
+	latest, err := uc.exportRepo.GetLatestExportByUserID(ctx, userID)
+	if err != nil && !errors.Is(err, domain.ErrExportNotFound) {
+		return nil, fmt.Errorf("failed to get latest data export: %w", err)
+	}
+	if latest != nil && latest.Status == domain.DataExportStatusPending {
+		return latest, nil
+	}
+
+	export := &domain.DataExport{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Status:    domain.DataExportStatusPending,
+		CreatedAt: time.Now(),
+	}
+	if err := uc.exportRepo.CreateExport(ctx, export); err != nil {
+		return nil, fmt.Errorf("failed to create data export: %w", err)
+	}
+
+	uc.auditUC.Record(ctx, domain.AuditEvent{
+		ActorUserID:   &userID,
+		SubjectUserID: &userID,
+		Type:          domain.AuditEventDataExportRequest,
+		Outcome:       domain.AuditOutcomeSuccess,
+		Details:       map[string]string{"export_id": export.ID},
+	})
+	return export, nil
+}
+
+func (uc *UseCase) GetLatestDataExport(ctx context.Context, userID int64) (*domain.DataExport, error) {
+	ctx, span := tracing.Start(ctx, "profile.GetLatestDataExport")
+	defer span.End()
+
+	export, err := uc.exportRepo.GetLatestExportByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrExportNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get latest data export: %w", err)
+	}
+	return export, nil
+}
+
+// GetDataExport returns a ready archive. Exports of other users are reported
+// as missing so that export IDs cannot be probed.
+func (uc *UseCase) GetDataExport(ctx context.Context, userID int64, exportID string) (*domain.DataExport, error) {
+	ctx, span := tracing.Start(ctx, "profile.GetDataExport")
+	defer span.End()
+
+	export, err := uc.exportRepo.GetExportByID(ctx, exportID)
+	if err != nil {
+		if errors.Is(err, domain.ErrExportNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get data export: %w", err)
+	}
+	if export.UserID != userID {
+		return nil, domain.ErrExportNotFound
+	}
+	if export.IsExpired(time.Now()) {
+		return nil, domain.ErrExportExpired
+	}
+	if export.Status != domain.DataExportStatusReady {
+		return nil, domain.ErrExportNotReady
+	}
+
+	uc.auditUC.Record(ctx, domain.AuditEvent{
+		ActorUserID:   &userID,
+		SubjectUserID: &userID,
+		Type:          domain.AuditEventDataExportDownload,
+		Outcome:       domain.AuditOutcomeSuccess,
+		Details:       map[string]string{"export_id": export.ID},
+	})
+	return export, nil
+}
+
+// ProcessPendingExports builds the archives of queued exports and drops the
+// ones whose download window is over.
+func (uc *UseCase) ProcessPendingExports(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "profile.ProcessPendingExports")
+	defer span.End()
+
+	exports, err := uc.exportRepo.ListPendingExports(ctx, exportBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to list pending data exports: %w", err)
+	}
+
+	for _, export := range exports {
+		now := time.Now()
+		archive, err := uc.buildUserDataArchive(ctx, export.UserID, now)
+		if err != nil {
+			uc.logger.ErrorContext(ctx, "failed to build data export", "error", err, "export_id", export.ID)
+			if err := uc.exportRepo.FailExport(ctx, export.ID, now, now.Add(uc.cfg.ExportTTL)); err != nil {
+				return fmt.Errorf("failed to mark data export as failed: %w", err)
+			}
+			continue
+		}
+		if err := uc.exportRepo.CompleteExport(ctx, export.ID, archive, now, now.Add(uc.cfg.ExportTTL)); err != nil {
+			return fmt.Errorf("failed to complete data export: %w", err)
+		}
+	}
+
+	deleted, err := uc.exportRepo.DeleteExpiredExports(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to delete expired data exports: %w", err)
+	}
+	if deleted > 0 {
+		uc.logger.InfoContext(ctx, "deleted expired data exports", "count", deleted)
+	}
+	return nil
+}
+
+func (uc *UseCase) buildUserDataArchive(ctx context.Context, userID int64, now time.Time) ([]byte, error) {
+	user, err := uc.profileRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by id: %w", err)
+	}
+
+	profile, err := uc.profileRepo.GetProfileByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+
+	customFields, err := uc.profileRepo.GetCustomFieldValues(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get custom fields: %w", err)
+	}
+
+	archive := userDataArchive{
+		GeneratedAt: now.UTC(),
+		User: userDataArchiveUser{
+			ID:              user.ID,
+			Email:           user.Email,
+			FullName:        user.FullName,
+			Phone:           user.Phone,
+			PhoneVerifiedAt: profile.PhoneVerifiedAt,
+			Handle:          profile.Handle,
+			PublicProfile:   profile.Public,
+			Privacy: userDataArchivePrivacy{
+				FullName: string(profile.Privacy.FullName),
+				Email:    string(profile.Privacy.Email),
+				Phone:    string(profile.Privacy.Phone),
+				Avatar:   string(profile.Privacy.Avatar),
+			},
+			AvatarID:     profile.AvatarID,
+			CustomFields: customFields,
+		},
+	}
+
+	emailChange, err := uc.profileRepo.GetEmailChangeRequestByUserID(ctx, userID)
+	if err != nil && !errors.Is(err, domain.ErrEmailChangeNotFound) {
+		return nil, fmt.Errorf("failed to get email change request: %w", err)
+	}
+	if emailChange != nil {
+		archive.PendingEmailChange = &userDataArchiveEmailChange{
+			NewEmail:  emailChange.NewEmail,
+			CreatedAt: emailChange.CreatedAt,
+			ExpiresAt: emailChange.ExpiresAt,
+		}
+	}
+
+	verification, err := uc.profileRepo.GetPhoneVerification(ctx, userID)
+	if err != nil && !errors.Is(err, domain.ErrPhoneVerificationNotFound) {
+		return nil, fmt.Errorf("failed to get phone verification: %w", err)
+	}
+	if verification != nil {
+		archive.PendingPhone = &userDataArchivePhoneVerification{
+			Phone:     verification.Phone,
+			CreatedAt: verification.CreatedAt,
+			ExpiresAt: verification.ExpiresAt,
+		}
+	}
+
+	changes, err := uc.listAllProfileChanges(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	archive.ProfileHistory = make([]userDataArchiveProfileChange, 0, len(changes))
+	for _, change := range changes {
+		archive.ProfileHistory = append(archive.ProfileHistory, userDataArchiveProfileChange{
+			Field:     change.Field,
+			OldValue:  change.OldValue,
+			NewValue:  change.NewValue,
+			CreatedAt: change.CreatedAt,
+		})
+	}
+
+	accounts, err := uc.profileRepo.GetOAuthAccountsByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get oauth accounts: %w", err)
+	}
+	archive.OAuthAccounts = make([]userDataArchiveOAuthAccount, 0, len(accounts))
+	for _, account := range accounts {
+		archive.OAuthAccounts = append(archive.OAuthAccounts, userDataArchiveOAuthAccount{
+			Provider:  account.ProviderName,
+			Subject:   account.Sub,
+			CreatedAt: account.CreatedAt,
+		})
+	}
+
+	sessions, err := uc.sessionRepo.GetUserSessions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user sessions: %w", err)
+	}
+	archive.Sessions = make([]userDataArchiveSession, 0, len(sessions))
+	for _, session := range sessions {
+		archive.Sessions = append(archive.Sessions, userDataArchiveSession{
+			AuthMethod: session.AuthMethod,
+			CreatedAt:  session.CreatedAt,
+			ExpiresAt:  session.ExpiresAt,
+		})
+	}
+
+	refreshTokens, err := uc.refreshTokenRepo.ListUserRefreshTokens(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refresh tokens: %w", err)
+	}
+	archive.RefreshTokens = make([]userDataArchiveRefreshToken, 0, len(refreshTokens))
+	for _, token := range refreshTokens {
+		archive.RefreshTokens = append(archive.RefreshTokens, userDataArchiveRefreshToken{
+			AuthMethod: token.AuthMethod,
+			CreatedAt:  token.CreatedAt,
+			ExpiresAt:  token.ExpiresAt,
+			UsedAt:     token.UsedAt,
+			RevokedAt:  token.RevokedAt,
+		})
+	}
+
+	accessTokens, err := uc.accessTokenRepo.ListTokens(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list access tokens: %w", err)
+	}
+	archive.AccessTokens = make([]userDataArchiveAccessToken, 0, len(accessTokens))
+	for _, token := range accessTokens {
+		scopes := make([]string, 0, len(token.Scopes))
+		for _, scope := range token.Scopes {
+			scopes = append(scopes, string(scope))
+		}
+		archive.AccessTokens = append(archive.AccessTokens, userDataArchiveAccessToken{
+			Name:       token.Name,
+			Scopes:     scopes,
+			CreatedAt:  token.CreatedAt,
+			ExpiresAt:  token.ExpiresAt,
+			LastUsedAt: token.LastUsedAt,
+		})
+	}
+
+	memberships, err := uc.orgRepo.ListUserOrganizations(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	archive.Organizations = make([]userDataArchiveOrganizationEntry, 0, len(memberships))
+	for _, membership := range memberships {
+		archive.Organizations = append(archive.Organizations, userDataArchiveOrganizationEntry{
+			ID:       membership.Organization.ID,
+			Name:     membership.Organization.Name,
+			Role:     string(membership.Role),
+			JoinedAt: membership.JoinedAt,
+		})
+	}
+
+	events, err := uc.listAllUserActivity(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	archive.AuditEvents = make([]userDataArchiveAuditEvent, 0, len(events))
+	for _, event := range events {
+		archive.AuditEvents = append(archive.AuditEvents, userDataArchiveAuditEvent{
+			Type:      string(event.Type),
+			Outcome:   string(event.Outcome),
+			IP:        event.IP,
+			UserAgent: event.UserAgent,
+			Details:   event.Details,
+			CreatedAt: event.CreatedAt,
+		})
+	}
+
+	data, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal data export: %w", err)
+	}
+	return data, nil
+}
+
+func (uc *UseCase) listAllProfileChanges(ctx context.Context, userID int64) ([]*domain.ProfileChange, error) {
+	all := make([]*domain.ProfileChange, 0)
+	beforeID := int64(0)
+	for {
+		changes, err := uc.profileRepo.ListProfileChanges(ctx, userID, beforeID, exportPageLimit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list profile changes: %w", err)
+		}
+		all = append(all, changes...)
+		if len(changes) < exportPageLimit {
+			return all, nil
+		}
+		beforeID = changes[len(changes)-1].ID
+	}
+}
+
+func (uc *UseCase) listAllUserActivity(ctx context.Context, userID int64) ([]*domain.AuditEvent, error) {
+	all := make([]*domain.AuditEvent, 0)
+	beforeID := int64(0)
+	for {
+		events, err := uc.auditUC.ListUserActivity(ctx, userID, beforeID, exportPageLimit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list user activity: %w", err)
+		}
+		all = append(all, events...)
+		if len(events) < exportPageLimit {
+			return all, nil
+		}
+		beforeID = events[len(events)-1].ID
+	}
+}
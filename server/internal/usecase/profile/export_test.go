@@ -0,0 +1,317 @@
+package profile
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"regexp"
+	"server/internal/domain"
+	"server/internal/gateway/sms"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUseCase_RequestDataExport(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		latest        *domain.DataExport
+		expectCreated bool
+		expectedID    string
+	}{
+		{
+			name:          "no previous export",
+			latest:        nil,
+			expectCreated: true,
+		},
+		{
+			name:          "pending export is reused",
+			latest:        &domain.DataExport{ID: "pending-1", UserID: 1, Status: domain.DataExportStatusPending},
+			expectCreated: false,
+			expectedID:    "pending-1",
+		},
+		{
+			name:          "ready export is not reused",
+			latest:        &domain.DataExport{ID: "ready-1", UserID: 1, Status: domain.DataExportStatusReady},
+			expectCreated: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := false
+			mockExportRepo := &mockExportRepository{
+				getLatestExportByUserIDFunc: func(ctx context.Context, userID int64) (*domain.DataExport, error) {
+					if tt.latest == nil {
+						return nil, domain.ErrExportNotFound
+					}
+					return tt.latest, nil
+				},
+				createExportFunc: func(ctx context.Context, export *domain.DataExport) error {
+					created = true
+					if export.UserID != 1 || export.Status != domain.DataExportStatusPending {
+						t.Errorf("unexpected export created: %+v", export)
+					}
+					return nil
+				},
+			}
+
+			uc := NewUseCase(logger, &mockProfileRepository{}, &mockSessionRepository{}, &mockRefreshTokenRepository{}, &mockAccessTokenRepository{}, &mockOrganizationRepository{}, mockExportRepo, &mockProfileFieldRepository{}, &mockAuditUseCase{}, &mockMailer{}, sms.NewFakeSender(), newMockBlobStore(), Config{})
+			export, err := uc.RequestDataExport(ctx, 1)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if created != tt.expectCreated {
+				t.Errorf("expected created %v, got %v", tt.expectCreated, created)
+			}
+			if tt.expectedID != "" && export.ID != tt.expectedID {
+				t.Errorf("expected export id %s, got %s", tt.expectedID, export.ID)
+			}
+		})
+	}
+}
+
+func TestUseCase_GetDataExport(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name          string
+		export        *domain.DataExport
+		expectedError error
+	}{
+		{
+			name:          "ready export of owner",
+			export:        &domain.DataExport{ID: "e1", UserID: 1, Status: domain.DataExportStatusReady, ExpiresAt: &future},
+			expectedError: nil,
+		},
+		{
+			name:          "export of another user",
+			export:        &domain.DataExport{ID: "e1", UserID: 2, Status: domain.DataExportStatusReady, ExpiresAt: &future},
+			expectedError: domain.ErrExportNotFound,
+		},
+		{
+			name:          "pending export",
+			export:        &domain.DataExport{ID: "e1", UserID: 1, Status: domain.DataExportStatusPending},
+			expectedError: domain.ErrExportNotReady,
+		},
+		{
+			name:          "expired export",
+			export:        &domain.DataExport{ID: "e1", UserID: 1, Status: domain.DataExportStatusReady, ExpiresAt: &past},
+			expectedError: domain.ErrExportExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExportRepo := &mockExportRepository{
+				getExportByIDFunc: func(ctx context.Context, exportID string) (*domain.DataExport, error) {
+					return tt.export, nil
+				},
+			}
+			mockAudit := &mockAuditUseCase{}
+
+			uc := NewUseCase(logger, &mockProfileRepository{}, &mockSessionRepository{}, &mockRefreshTokenRepository{}, &mockAccessTokenRepository{}, &mockOrganizationRepository{}, mockExportRepo, &mockProfileFieldRepository{}, mockAudit, &mockMailer{}, sms.NewFakeSender(), newMockBlobStore(), Config{})
+			_, err := uc.GetDataExport(ctx, 1, "e1")
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if tt.expectedError == nil && len(mockAudit.events) != 1 {
+				t.Errorf("expected a download audit event, got %v", mockAudit.events)
+			}
+		})
+	}
+}
+
+func TestUseCase_ProcessPendingExports(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	var archive []byte
+	var expiresAt time.Time
+	cleaned := false
+	mockExportRepo := &mockExportRepository{
+		listPendingExportsFunc: func(ctx context.Context, limit int) ([]*domain.DataExport, error) {
+			return []*domain.DataExport{{ID: "e1", UserID: 1, Status: domain.DataExportStatusPending}}, nil
+		},
+		completeExportFunc: func(ctx context.Context, exportID string, data []byte, completedAt, expires time.Time) error {
+			archive = data
+			expiresAt = expires
+			return nil
+		},
+		deleteExpiredExportsFunc: func(ctx context.Context, now time.Time) (int64, error) {
+			cleaned = true
+			return 0, nil
+		},
+	}
+	mockProfileRepo := &mockProfileRepository{
+		getUserByIDFunc: func(ctx context.Context, userID int64) (*domain.User, error) {
+			return &domain.User{ID: userID, Email: "test@example.com", Password: "secret-hash"}, nil
+		},
+		getOAuthAccountsByUserIDFunc: func(ctx context.Context, userID int64) ([]*domain.OAuthAccount, error) {
+			return []*domain.OAuthAccount{{ProviderName: "google", Sub: "123"}}, nil
+		},
+		getCustomFieldValuesFunc: func(ctx context.Context, userID int64) (map[string]string, error) {
+			return map[string]string{"department": "Research"}, nil
+		},
+	}
+	mockSessionRepo := &mockSessionRepository{
+		getUserSessionsFunc: func(ctx context.Context, userID int64) ([]*domain.Session, error) {
+			return []*domain.Session{{Token: "secret-token", UserID: userID, AuthMethod: domain.AuthMethodPassword}}, nil
+		},
+	}
+	mockAudit := &mockAuditUseCase{
+		listUserActivityFunc: func(ctx context.Context, userID int64, beforeID int64, limit int) ([]*domain.AuditEvent, error) {
+			return []*domain.AuditEvent{{ID: 1, Type: domain.AuditEventLogInEmail, Outcome: domain.AuditOutcomeSuccess}}, nil
+		},
+	}
+
+	uc := NewUseCase(logger, mockProfileRepo, mockSessionRepo, &mockRefreshTokenRepository{}, &mockAccessTokenRepository{}, &mockOrganizationRepository{}, mockExportRepo, &mockProfileFieldRepository{}, mockAudit, &mockMailer{}, sms.NewFakeSender(), newMockBlobStore(), Config{ExportTTL: 24 * time.Hour})
+	if err := uc.ProcessPendingExports(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var decoded userDataArchive
+	if err := json.Unmarshal(archive, &decoded); err != nil {
+		t.Fatalf("archive is not valid json: %v", err)
+	}
+	if decoded.User.Email != "test@example.com" || decoded.User.CustomFields["department"] != "Research" || len(decoded.OAuthAccounts) != 1 || len(decoded.Sessions) != 1 || len(decoded.AuditEvents) != 1 {
+		t.Errorf("unexpected archive contents: %s", archive)
+	}
+	for _, secret := range []string{"secret-hash", "secret-token"} {
+		if bytes.Contains(archive, []byte(secret)) {
+			t.Errorf("archive must not contain %q", secret)
+		}
+	}
+	if time.Until(expiresAt) < 23*time.Hour {
+		t.Errorf("expected export to expire after ttl, got %v", expiresAt)
+	}
+	if !cleaned {
+		t.Error("expected expired exports to be deleted")
+	}
+}
+
+// userOwnedTable matches a table in the schema whose rows belong to a user.
+var userOwnedTable = regexp.MustCompile(`(?s)create table (\w+) \((?:[^;]*?)foreign key \(user_id\) references user\(id\)`)
+
+// TestUseCase_ExportCoversUserOwnedTables fails when a table keyed by
+// user_id is added to db/create_db.sql without being exported. Map the new
+// table to the archive key that carries it, or to "" with a reason when it
+// is deliberately left out.
+func TestUseCase_ExportCoversUserOwnedTables(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	archiveKeys := map[string]string{
+		"oauth_account":         "oauth_accounts",
+		"profile_field_value":   "user.custom_fields",
+		"profile_change":        "profile_history",
+		"phone_verification":    "pending_phone_verification",
+		"email_change_request":  "pending_email_change",
+		"organization_member":   "organizations",
+		"personal_access_token": "access_tokens",
+		"refresh_token":         "refresh_tokens",
+		// The export records themselves; an archive does not contain itself.
+		"data_export": "",
+	}
+
+	schema, err := os.ReadFile("../../../db/create_db.sql")
+	if err != nil {
+		t.Fatalf("failed to read schema: %v", err)
+	}
+	matches := userOwnedTable.FindAllSubmatch(schema, -1)
+	if len(matches) == 0 {
+		t.Fatal("expected user-owned tables in the schema")
+	}
+
+	now := time.Now()
+	mockProfileRepo := &mockProfileRepository{
+		getUserByIDFunc: func(ctx context.Context, userID int64) (*domain.User, error) {
+			return &domain.User{ID: userID, Email: "test@example.com"}, nil
+		},
+		getCustomFieldValuesFunc: func(ctx context.Context, userID int64) (map[string]string, error) {
+			return map[string]string{"department": "Research"}, nil
+		},
+		getOAuthAccountsByUserIDFunc: func(ctx context.Context, userID int64) ([]*domain.OAuthAccount, error) {
+			return []*domain.OAuthAccount{{ProviderName: "google", Sub: "123"}}, nil
+		},
+		listProfileChangesFunc: func(ctx context.Context, userID, beforeID int64, limit int) ([]*domain.ProfileChange, error) {
+			return []*domain.ProfileChange{{ID: 1, UserID: userID, Field: "full_name", NewValue: "Ada"}}, nil
+		},
+		getPhoneVerificationFunc: func(ctx context.Context, userID int64) (*domain.PhoneVerification, error) {
+			return &domain.PhoneVerification{UserID: userID, Phone: "+14155550100", CodeHash: "secret-code", ExpiresAt: now}, nil
+		},
+		getEmailChangeByUserIDFunc: func(ctx context.Context, userID int64) (*domain.EmailChangeRequest, error) {
+			return &domain.EmailChangeRequest{UserID: userID, NewEmail: "new@example.com", ConfirmTokenHash: "secret-confirm", CancelTokenHash: "secret-cancel"}, nil
+		},
+	}
+	mockRefreshTokenRepo := &mockRefreshTokenRepository{
+		listUserRefreshTokensFunc: func(ctx context.Context, userID int64) ([]*domain.RefreshToken, error) {
+			return []*domain.RefreshToken{{ID: 1, FamilyID: "secret-family", UserID: userID, TokenHash: "secret-refresh", AuthMethod: domain.AuthMethodPassword}}, nil
+		},
+	}
+	mockAccessTokenRepo := &mockAccessTokenRepository{
+		listTokensFunc: func(ctx context.Context, userID int64) ([]*domain.PersonalAccessToken, error) {
+			return []*domain.PersonalAccessToken{{ID: 1, UserID: userID, Name: "ci", TokenHash: "secret-pat"}}, nil
+		},
+	}
+	mockOrgRepo := &mockOrganizationRepository{
+		listUserOrganizationsFunc: func(ctx context.Context, userID int64) ([]*domain.OrganizationMembership, error) {
+			return []*domain.OrganizationMembership{{Organization: domain.Organization{ID: 7, Name: "Acme"}, Role: domain.OrganizationRoleMember}}, nil
+		},
+	}
+
+	uc := NewUseCase(logger, mockProfileRepo, &mockSessionRepository{}, mockRefreshTokenRepo, mockAccessTokenRepo, mockOrgRepo, &mockExportRepository{}, &mockProfileFieldRepository{}, &mockAuditUseCase{}, &mockMailer{}, sms.NewFakeSender(), newMockBlobStore(), Config{})
+	archive, err := uc.buildUserDataArchive(ctx, 1, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(archive, &decoded); err != nil {
+		t.Fatalf("archive is not valid json: %v", err)
+	}
+
+	for _, m := range matches {
+		table := string(m[1])
+		key, ok := archiveKeys[table]
+		if !ok {
+			t.Errorf("table %s holds user data but is not part of the data export", table)
+			continue
+		}
+		if key == "" {
+			continue
+		}
+		var value any = decoded
+		for _, part := range strings.Split(key, ".") {
+			object, _ := value.(map[string]any)
+			value = object[part]
+		}
+		switch v := value.(type) {
+		case map[string]any:
+			if len(v) == 0 {
+				t.Errorf("table %s: archive key %s is empty", table, key)
+			}
+		case []any:
+			if len(v) == 0 {
+				t.Errorf("table %s: archive key %s is empty", table, key)
+			}
+		default:
+			t.Errorf("table %s: archive key %s is missing", table, key)
+		}
+	}
+
+	for _, secret := range []string{"secret-code", "secret-confirm", "secret-cancel", "secret-family", "secret-refresh", "secret-pat"} {
+		if bytes.Contains(archive, []byte(secret)) {
+			t.Errorf("archive must not contain %q", secret)
+		}
+	}
+}
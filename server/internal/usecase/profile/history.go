@@ -0,0 +1,72 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"server/internal/domain"
+	appcontext "server/internal/pkg/context"
+	"server/internal/pkg/tracing"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// ListProfileHistory returns the recorded profile changes of the user, newest
+// first.
+func (uc *UseCase) ListProfileHistory(ctx context.Context, userID, beforeID int64, limit int) ([]*domain.ProfileChange, error) {
+	ctx, span := tracing.Start(ctx, "profile.ListProfileHistory")
+	defer span.End()
+
+	changes, err := uc.profileRepo.ListProfileChanges(ctx, userID, beforeID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list profile history: %w", err)
+	}
+	return changes, nil
+}
+
+// profileHistory describes the changes about to be written, one entry per
+// field, attributed to the session in ctx.
+func profileHistory(ctx context.Context, userID int64, current *domain.Profile, currentValues map[string]string, changes *domain.ProfilePatch) []*domain.ProfileChange {
+	var sessionID string
+	if session, ok := appcontext.SessionFromContext(ctx); ok {
+		sessionID = session.ID()
+	}
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	history := make([]*domain.ProfileChange, 0, 4+len(changes.CustomFields))
+	add := func(field, oldValue, newValue string) {
+		history = append(history, &domain.ProfileChange{
+			UserID:    userID,
+			Field:     field,
+			OldValue:  oldValue,
+			NewValue:  newValue,
+			SessionID: sessionID,
+			CreatedAt: now,
+		})
+	}
+
+	if changes.FullName != nil {
+		add("full_name", current.FullName, *changes.FullName)
+	}
+	if changes.Phone != nil {
+		add("phone", current.Phone, *changes.Phone)
+	}
+	if changes.Handle != nil {
+		add("handle", current.Handle, *changes.Handle)
+	}
+	if changes.Public != nil {
+		add("public", strconv.FormatBool(current.Public), strconv.FormatBool(*changes.Public))
+	}
+	currentVisibilities := privacyFields(current.Privacy)
+	changedVisibilities := privacyFields(changes.Privacy)
+	for _, path := range slices.Sorted(maps.Keys(changedVisibilities)) {
+		if changedVisibilities[path] != "" {
+			add(path, string(currentVisibilities[path]), string(changedVisibilities[path]))
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(changes.CustomFields)) {
+		add(customFieldPath(key), currentValues[key], changes.CustomFields[key])
+	}
+	return history
+}
@@ -0,0 +1,117 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"server/internal/domain"
+	"server/internal/gateway/sms"
+	appcontext "server/internal/pkg/context"
+	"testing"
+)
+
+func TestUseCase_PatchProfile_RecordsHistory(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	session := &domain.Session{Token: "session-token", UserID: 1}
+	ctx := appcontext.WithSession(context.Background(), session)
+
+	var history []*domain.ProfileChange
+	mockProfileRepo := &mockProfileRepository{
+		getProfileByUserIDFunc: func(ctx context.Context, userID int64) (*domain.Profile, error) {
+			return &domain.Profile{UserID: userID, FullName: "Test User", Phone: "+14155550132", Version: 1}, nil
+		},
+		updateProfileFunc: func(ctx context.Context, userID, version int64, patch *domain.ProfilePatch, h []*domain.ProfileChange) error {
+			history = h
+			return nil
+		},
+		getCustomFieldValuesFunc: func(ctx context.Context, userID int64) (map[string]string, error) {
+			return map[string]string{"department": "Research"}, nil
+		},
+	}
+	mockFieldRepo := &mockProfileFieldRepository{
+		listFieldsFunc: func(ctx context.Context) ([]*domain.ProfileField, error) {
+			return testProfileFields(), nil
+		},
+	}
+
+	uc := NewUseCase(logger, mockProfileRepo, &mockSessionRepository{}, &mockRefreshTokenRepository{}, &mockAccessTokenRepository{}, &mockOrganizationRepository{}, &mockExportRepository{}, mockFieldRepo, &mockAuditUseCase{}, &mockMailer{}, sms.NewFakeSender(), newMockBlobStore(), Config{})
+
+	fullName, phone := "Test User", ""
+	_, err := uc.PatchProfile(ctx, 1, 1, &domain.ProfilePatch{
+		FullName:     &fullName,
+		Phone:        &phone,
+		CustomFields: map[string]string{"department": "Platform", "team": "core"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []domain.ProfileChange{
+		{UserID: 1, Field: "phone", OldValue: "+14155550132", NewValue: ""},
+		{UserID: 1, Field: "custom_fields.department", OldValue: "Research", NewValue: "Platform"},
+		{UserID: 1, Field: "custom_fields.team", OldValue: "", NewValue: "core"},
+	}
+	if len(history) != len(expected) {
+		t.Fatalf("expected %d history entries, got %d", len(expected), len(history))
+	}
+	for i, want := range expected {
+		got := history[i]
+		if got.UserID != want.UserID || got.Field != want.Field || got.OldValue != want.OldValue || got.NewValue != want.NewValue {
+			t.Errorf("entry %d: expected %+v, got %+v", i, want, *got)
+		}
+		if got.SessionID != session.ID() || got.SessionID == session.Token {
+			t.Errorf("entry %d: expected session id %q, got %q", i, session.ID(), got.SessionID)
+		}
+		if got.CreatedAt.IsZero() || !got.CreatedAt.Equal(history[0].CreatedAt) {
+			t.Errorf("entry %d: expected a shared timestamp, got %v", i, got.CreatedAt)
+		}
+	}
+}
+
+func TestUseCase_ListProfileHistory(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		repoErr       error
+		expectedCount int
+		expectedError bool
+	}{
+		{name: "success", expectedCount: 1},
+		{name: "repository error", repoErr: errors.New("db error"), expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockProfileRepo := &mockProfileRepository{
+				listProfileChangesFunc: func(ctx context.Context, userID, beforeID int64, limit int) ([]*domain.ProfileChange, error) {
+					if userID != 7 || beforeID != 10 || limit != 20 {
+						t.Errorf("unexpected arguments: %d, %d, %d", userID, beforeID, limit)
+					}
+					if tt.repoErr != nil {
+						return nil, tt.repoErr
+					}
+					return []*domain.ProfileChange{{ID: 9, UserID: 7, Field: "phone"}}, nil
+				},
+			}
+
+			uc := NewUseCase(logger, mockProfileRepo, &mockSessionRepository{}, &mockRefreshTokenRepository{}, &mockAccessTokenRepository{}, &mockOrganizationRepository{}, &mockExportRepository{}, &mockProfileFieldRepository{}, &mockAuditUseCase{}, &mockMailer{}, sms.NewFakeSender(), newMockBlobStore(), Config{})
+			changes, err := uc.ListProfileHistory(ctx, 7, 10, 20)
+
+			if tt.expectedError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(changes) != tt.expectedCount {
+				t.Errorf("expected %d changes, got %d", tt.expectedCount, len(changes))
+			}
+		})
+	}
+}
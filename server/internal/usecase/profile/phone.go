@@ -0,0 +1,156 @@
+package profile
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"server/internal/domain"
+	"server/internal/pkg/tracing"
+	"strconv"
+	"time"
+)
+
+const phoneCodeDigits = 6
+
+// RequestPhoneVerification sends a one-time code to the phone currently on
+// the profile. Requesting again replaces the previous code.
+func (uc *UseCase) RequestPhoneVerification(ctx context.Context, userID int64) (time.Time, error) {
+	ctx, span := tracing.Start(ctx, "profile.RequestPhoneVerification")
+	defer span.End()
+
+	profile, err := uc.profileRepo.GetProfileByUserID(ctx, userID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get profile: %w", err)
+	}
+	if profile.Phone == "" {
+		return time.Time{}, domain.ErrPhoneNotSet
+	}
+	if profile.PhoneVerifiedAt != nil {
+		return time.Time{}, domain.ErrPhoneAlreadyVerified
+	}
+
+	now := time.Now()
+	previous, err := uc.profileRepo.GetPhoneVerification(ctx, userID)
+	if err != nil && !errors.Is(err, domain.ErrPhoneVerificationNotFound) {
+		return time.Time{}, fmt.Errorf("failed to get phone verification: %w", err)
+	}
+	if previous != nil && previous.Phone == profile.Phone && now.Sub(previous.CreatedAt) < uc.cfg.PhoneCodeResendInterval {
+		return time.Time{}, domain.ErrPhoneVerificationTooSoon
+	}
+
+	code, err := generatePhoneCode()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to generate phone code: %w", err)
+	}
+
+	verification := &domain.PhoneVerification{
+		UserID:    userID,
+		Phone:     profile.Phone,
+		CodeHash:  hashPhoneCode(userID, code),
+		CreatedAt: now,
+		ExpiresAt: now.Add(uc.cfg.PhoneCodeTTL),
+	}
+	if err := uc.profileRepo.SavePhoneVerification(ctx, verification); err != nil {
+		return time.Time{}, fmt.Errorf("failed to save phone verification: %w", err)
+	}
+
+	message := fmt.Sprintf("Your verification code is %s. It expires in %d minutes.", code, int(uc.cfg.PhoneCodeTTL.Minutes()))
+	if err := uc.smsSender.Send(ctx, profile.Phone, message); err != nil {
+		return time.Time{}, fmt.Errorf("failed to send verification code: %w", err)
+	}
+
+	uc.auditUC.Record(ctx, domain.AuditEvent{
+		ActorUserID:   &userID,
+		SubjectUserID: &userID,
+		Type:          domain.AuditEventPhoneVerificationRequest,
+		Outcome:       domain.AuditOutcomeSuccess,
+		Details:       map[string]string{"phone": profile.Phone},
+	})
+	return verification.ExpiresAt, nil
+}
+
+// ConfirmPhoneVerification checks the code against the last one sent. Every
+// wrong guess counts towards PhoneCodeMaxAttempts, after which a new code
+// has to be requested.
+func (uc *UseCase) ConfirmPhoneVerification(ctx context.Context, userID int64, code string) error {
+	ctx, span := tracing.Start(ctx, "profile.ConfirmPhoneVerification")
+	defer span.End()
+
+	verification, err := uc.profileRepo.GetPhoneVerification(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrPhoneVerificationNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to get phone verification: %w", err)
+	}
+
+	profile, err := uc.profileRepo.GetProfileByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get profile: %w", err)
+	}
+	if verification.Phone != profile.Phone {
+		return domain.ErrPhoneVerificationNotFound
+	}
+	if verification.IsExpired(time.Now()) {
+		return domain.ErrPhoneVerificationExpired
+	}
+	// The attempt is counted before the code is compared, so parallel
+	// guesses cannot get past the limit.
+	if err := uc.profileRepo.UsePhoneVerificationAttempt(ctx, userID, uc.cfg.PhoneCodeMaxAttempts); err != nil {
+		if errors.Is(err, domain.ErrTooManyPhoneCodeAttempts) {
+			return err
+		}
+		return fmt.Errorf("failed to count phone verification attempt: %w", err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashPhoneCode(userID, code)), []byte(verification.CodeHash)) != 1 {
+		uc.auditUC.Record(ctx, domain.AuditEvent{
+			ActorUserID:   &userID,
+			SubjectUserID: &userID,
+			Type:          domain.AuditEventPhoneVerify,
+			Outcome:       domain.AuditOutcomeFailure,
+			Details:       map[string]string{"phone": verification.Phone, "reason": domain.ErrInvalidPhoneCode.Error()},
+		})
+		return domain.ErrInvalidPhoneCode
+	}
+
+	if err := uc.profileRepo.MarkPhoneVerified(ctx, userID, verification.Phone, time.Now()); err != nil {
+		if errors.Is(err, domain.ErrPhoneVerificationNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to mark phone verified: %w", err)
+	}
+
+	uc.auditUC.Record(ctx, domain.AuditEvent{
+		ActorUserID:   &userID,
+		SubjectUserID: &userID,
+		Type:          domain.AuditEventPhoneVerify,
+		Outcome:       domain.AuditOutcomeSuccess,
+		Details:       map[string]string{"phone": verification.Phone},
+	})
+	return nil
+}
+
+func generatePhoneCode() (string, error) {
+	limit := big.NewInt(1)
+	for i := 0; i < phoneCodeDigits; i++ {
+		limit.Mul(limit, big.NewInt(10))
+	}
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", phoneCodeDigits, n.Int64()), nil
+}
+
+// hashPhoneCode binds the code to the user so a stored hash cannot be reused
+// for another account.
+func hashPhoneCode(userID int64, code string) string {
+	sum := sha256.Sum256([]byte(strconv.FormatInt(userID, 10) + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
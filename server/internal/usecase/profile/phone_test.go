@@ -0,0 +1,270 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"server/internal/domain"
+	"server/internal/gateway/sms"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestUseCase_UpdateProfile_NormalizesPhone(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		input         string
+		defaultRegion string
+		expectedPhone string
+		expectedError string
+	}{
+		{name: "us international", input: "+1 (415) 555-0132", expectedPhone: "+14155550132"},
+		{name: "double zero prefix", input: "0044 20 7946 0018", expectedPhone: "+442079460018"},
+		{name: "russian trunk prefix", input: "8 912 345-67-89", defaultRegion: "RU", expectedPhone: "+79123456789"},
+		{name: "uk national", input: "07911 123456", defaultRegion: "GB", expectedPhone: "+447911123456"},
+		{name: "three digit country code", input: "+380 50 123 4567", expectedPhone: "+380501234567"},
+		{name: "missing country code", input: "912 345 67 89", expectedError: "include the country code"},
+		{name: "country code not in a partial table", input: "+41 44 668 18 00", expectedPhone: "+41446681800"},
+		{name: "too short", input: "+7 912", expectedError: "shorter than 7 digits"},
+		{name: "country code starting with 0", input: "+0 15 555 0132", expectedError: "do not start with 0"},
+		{name: "letters", input: "+1 415 CALL NOW", expectedError: "unexpected character 'C'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stored string
+			mockProfileRepo := &mockProfileRepository{
+				updateProfileFunc: func(ctx context.Context, userID, version int64, patch *domain.ProfilePatch, history []*domain.ProfileChange) error {
+					stored = *patch.Phone
+					return nil
+				},
+			}
+
+			uc := NewUseCase(logger, mockProfileRepo, &mockSessionRepository{}, &mockRefreshTokenRepository{}, &mockAccessTokenRepository{}, &mockOrganizationRepository{}, &mockExportRepository{}, &mockProfileFieldRepository{}, &mockAuditUseCase{}, &mockMailer{}, sms.NewFakeSender(), newMockBlobStore(),
+				Config{DefaultPhoneRegion: tt.defaultRegion})
+			_, err := uc.UpdateProfile(ctx, 1, 1, &domain.Profile{FullName: "Test User", Phone: tt.input})
+
+			if tt.expectedError != "" {
+				var fieldErrors domain.FieldErrors
+				if !errors.As(err, &fieldErrors) || !strings.Contains(fieldErrors["phone"], tt.expectedError) {
+					t.Errorf("expected phone field error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if stored != tt.expectedPhone {
+				t.Errorf("expected phone %s, got %s", tt.expectedPhone, stored)
+			}
+		})
+	}
+}
+
+func TestUseCase_PhoneVerification(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+	cfg := Config{PhoneCodeTTL: 10 * time.Minute, PhoneCodeResendInterval: time.Minute, PhoneCodeMaxAttempts: 3}
+
+	var saved *domain.PhoneVerification
+	verifiedPhone := ""
+	mockProfileRepo := &mockProfileRepository{
+		getProfileByUserIDFunc: func(ctx context.Context, userID int64) (*domain.Profile, error) {
+			return &domain.Profile{UserID: userID, Phone: "+14155550132"}, nil
+		},
+		savePhoneVerificationFunc: func(ctx context.Context, verification *domain.PhoneVerification) error {
+			saved = verification
+			return nil
+		},
+		getPhoneVerificationFunc: func(ctx context.Context, userID int64) (*domain.PhoneVerification, error) {
+			if saved == nil {
+				return nil, domain.ErrPhoneVerificationNotFound
+			}
+			return saved, nil
+		},
+		usePhoneAttemptFunc: func(ctx context.Context, userID int64, maxAttempts int) error {
+			if saved.Attempts >= maxAttempts {
+				return domain.ErrTooManyPhoneCodeAttempts
+			}
+			saved.Attempts++
+			return nil
+		},
+		markPhoneVerifiedFunc: func(ctx context.Context, userID int64, phone string, verifiedAt time.Time) error {
+			verifiedPhone = phone
+			return nil
+		},
+	}
+	sender := sms.NewFakeSender()
+
+	uc := NewUseCase(logger, mockProfileRepo, &mockSessionRepository{}, &mockRefreshTokenRepository{}, &mockAccessTokenRepository{}, &mockOrganizationRepository{}, &mockExportRepository{}, &mockProfileFieldRepository{}, &mockAuditUseCase{}, &mockMailer{}, sender, newMockBlobStore(), cfg)
+
+	if _, err := uc.RequestPhoneVerification(ctx, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	messages := sender.Messages()
+	if len(messages) != 1 || messages[0].Phone != "+14155550132" {
+		t.Fatalf("expected one sms to +14155550132, got %v", messages)
+	}
+	code := strings.TrimSuffix(strings.Fields(messages[0].Text)[4], ".")
+	if len(code) != phoneCodeDigits || strings.Contains(saved.CodeHash, code) {
+		t.Errorf("expected a %d digit code stored only as a hash, got %q", phoneCodeDigits, code)
+	}
+
+	if _, err := uc.RequestPhoneVerification(ctx, 1); !errors.Is(err, domain.ErrPhoneVerificationTooSoon) {
+		t.Errorf("expected resend to be throttled, got %v", err)
+	}
+
+	if err := uc.ConfirmPhoneVerification(ctx, 1, "abcdef"); !errors.Is(err, domain.ErrInvalidPhoneCode) {
+		t.Errorf("expected invalid code error, got %v", err)
+	}
+	if saved.Attempts != 1 {
+		t.Errorf("expected wrong guess to be counted, got %d attempts", saved.Attempts)
+	}
+
+	if err := uc.ConfirmPhoneVerification(ctx, 1, code); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if verifiedPhone != "+14155550132" {
+		t.Errorf("expected +14155550132 to be marked verified, got %q", verifiedPhone)
+	}
+}
+
+func TestUseCase_ConfirmPhoneVerification_Errors(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+	cfg := Config{PhoneCodeMaxAttempts: 3}
+
+	tests := []struct {
+		name          string
+		verification  *domain.PhoneVerification
+		expectedError error
+	}{
+		{
+			name:          "no code requested",
+			verification:  nil,
+			expectedError: domain.ErrPhoneVerificationNotFound,
+		},
+		{
+			name:          "phone changed since code was sent",
+			verification:  &domain.PhoneVerification{UserID: 1, Phone: "+14155550199", ExpiresAt: time.Now().Add(time.Minute)},
+			expectedError: domain.ErrPhoneVerificationNotFound,
+		},
+		{
+			name:          "expired code",
+			verification:  &domain.PhoneVerification{UserID: 1, Phone: "+14155550132", ExpiresAt: time.Now().Add(-time.Minute)},
+			expectedError: domain.ErrPhoneVerificationExpired,
+		},
+		{
+			name:          "too many attempts",
+			verification:  &domain.PhoneVerification{UserID: 1, Phone: "+14155550132", Attempts: 3, ExpiresAt: time.Now().Add(time.Minute)},
+			expectedError: domain.ErrTooManyPhoneCodeAttempts,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockProfileRepo := &mockProfileRepository{
+				getProfileByUserIDFunc: func(ctx context.Context, userID int64) (*domain.Profile, error) {
+					return &domain.Profile{UserID: userID, Phone: "+14155550132"}, nil
+				},
+				getPhoneVerificationFunc: func(ctx context.Context, userID int64) (*domain.PhoneVerification, error) {
+					if tt.verification == nil {
+						return nil, domain.ErrPhoneVerificationNotFound
+					}
+					return tt.verification, nil
+				},
+				usePhoneAttemptFunc: func(ctx context.Context, userID int64, maxAttempts int) error {
+					if tt.verification.Attempts >= maxAttempts {
+						return domain.ErrTooManyPhoneCodeAttempts
+					}
+					return nil
+				},
+				markPhoneVerifiedFunc: func(ctx context.Context, userID int64, phone string, verifiedAt time.Time) error {
+					t.Error("phone must not be marked verified")
+					return nil
+				},
+			}
+
+			uc := NewUseCase(logger, mockProfileRepo, &mockSessionRepository{}, &mockRefreshTokenRepository{}, &mockAccessTokenRepository{}, &mockOrganizationRepository{}, &mockExportRepository{}, &mockProfileFieldRepository{}, &mockAuditUseCase{}, &mockMailer{}, sms.NewFakeSender(), newMockBlobStore(), cfg)
+			err := uc.ConfirmPhoneVerification(ctx, 1, "123456")
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+// TestUseCase_ConfirmPhoneVerification_ConcurrentGuesses checks that wrong
+// guesses sent at the same time cannot get past the attempt limit. The mock
+// does what the conditional update does in the database.
+func TestUseCase_ConfirmPhoneVerification_ConcurrentGuesses(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+	cfg := Config{PhoneCodeMaxAttempts: 3}
+
+	var mu sync.Mutex
+	attempts := 0
+	verification := &domain.PhoneVerification{UserID: 1, Phone: "+14155550132", CodeHash: hashPhoneCode(1, "123456"), ExpiresAt: time.Now().Add(time.Minute)}
+	mockProfileRepo := &mockProfileRepository{
+		getProfileByUserIDFunc: func(ctx context.Context, userID int64) (*domain.Profile, error) {
+			return &domain.Profile{UserID: userID, Phone: "+14155550132"}, nil
+		},
+		getPhoneVerificationFunc: func(ctx context.Context, userID int64) (*domain.PhoneVerification, error) {
+			return verification, nil
+		},
+		usePhoneAttemptFunc: func(ctx context.Context, userID int64, maxAttempts int) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if attempts >= maxAttempts {
+				return domain.ErrTooManyPhoneCodeAttempts
+			}
+			attempts++
+			return nil
+		},
+		markPhoneVerifiedFunc: func(ctx context.Context, userID int64, phone string, verifiedAt time.Time) error {
+			t.Error("phone must not be marked verified")
+			return nil
+		},
+	}
+
+	uc := NewUseCase(logger, mockProfileRepo, &mockSessionRepository{}, &mockRefreshTokenRepository{}, &mockAccessTokenRepository{}, &mockOrganizationRepository{}, &mockExportRepository{}, &mockProfileFieldRepository{}, &mockAuditUseCase{}, &mockMailer{}, sms.NewFakeSender(), newMockBlobStore(), cfg)
+
+	const guesses = 20
+	errs := make(chan error, guesses)
+	var wg sync.WaitGroup
+	for i := range guesses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- uc.ConfirmPhoneVerification(ctx, 1, fmt.Sprintf("%06d", 200000+i))
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	checked := 0
+	for err := range errs {
+		switch {
+		case errors.Is(err, domain.ErrInvalidPhoneCode):
+			checked++
+		case errors.Is(err, domain.ErrTooManyPhoneCodeAttempts):
+		default:
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if checked != cfg.PhoneCodeMaxAttempts {
+		t.Errorf("expected %d guesses to be checked, got %d", cfg.PhoneCodeMaxAttempts, checked)
+	}
+
+	if err := uc.ConfirmPhoneVerification(ctx, 1, "123456"); !errors.Is(err, domain.ErrTooManyPhoneCodeAttempts) {
+		t.Errorf("expected the right code to be refused once attempts are used up, got %v", err)
+	}
+}
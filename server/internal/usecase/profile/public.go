@@ -0,0 +1,123 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"server/internal/domain"
+	"server/internal/pkg/tracing"
+	"slices"
+	"strings"
+)
+
+// handlePattern keeps handles safe to use in URLs as they are: lower case
+// letters, digits and underscores, starting with a letter.
+var handlePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{2,31}$`)
+
+// reservedHandles would be confusing as profile names or clash with routes.
+var reservedHandles = []string{"admin", "administrator", "api", "me", "root", "settings", "support", "system"}
+
+// normalizeHandle lower-cases the handle and returns a message if it is not
+// acceptable; an empty handle is allowed and removes it.
+func normalizeHandle(handle string) (string, string) {
+	handle = strings.ToLower(strings.TrimSpace(handle))
+	if handle == "" {
+		return "", ""
+	}
+	if !handlePattern.MatchString(handle) {
+		return handle, "must be 3 to 32 letters, digits or underscores, starting with a letter"
+	}
+	if slices.Contains(reservedHandles, handle) {
+		return handle, "is reserved"
+	}
+	return handle, ""
+}
+
+// GetPublicProfile returns the profile published under handle as viewerID
+// may see it; viewerID is 0 for anonymous visitors. Profiles that are not
+// public are reported as domain.ErrUserNotExists to everyone but the owner,
+// who gets a preview.
+func (uc *UseCase) GetPublicProfile(ctx context.Context, handle string, viewerID int64) (*domain.PublicProfile, error) {
+	ctx, span := tracing.Start(ctx, "profile.GetPublicProfile")
+	defer span.End()
+
+	handle = strings.ToLower(strings.TrimSpace(handle))
+	if handle == "" {
+		return nil, domain.ErrUserNotExists
+	}
+
+	profile, err := uc.profileRepo.GetProfileByHandle(ctx, handle)
+	if err != nil {
+		return nil, err
+	}
+	if !profile.Public && profile.UserID != viewerID {
+		return nil, domain.ErrUserNotExists
+	}
+
+	public := visibleProfile(profile, viewerID)
+	public.CustomFields, err = uc.publicCustomFields(ctx, profile.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return public, nil
+}
+
+// publicCustomFields lists the filled-in custom fields administrators have
+// marked public.
+func (uc *UseCase) publicCustomFields(ctx context.Context, userID int64) ([]domain.PublicProfileField, error) {
+	fields, err := uc.profileFieldRepo.ListFields(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list profile fields: %w", err)
+	}
+	values, err := uc.profileRepo.GetCustomFieldValues(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get custom fields: %w", err)
+	}
+
+	public := make([]domain.PublicProfileField, 0)
+	for _, field := range fields {
+		value := values[field.Key]
+		if field.Visibility != domain.ProfileFieldVisibilityPublic || value == "" {
+			continue
+		}
+		public = append(public, domain.PublicProfileField{
+			Key:   field.Key,
+			Label: field.Label,
+			Type:  field.Type,
+			Value: value,
+		})
+	}
+	return public, nil
+}
+
+// visibleProfile copies what viewerID may see of the profile, without the
+// custom fields.
+func visibleProfile(profile *domain.Profile, viewerID int64) *domain.PublicProfile {
+	public := &domain.PublicProfile{
+		UserID:        profile.UserID,
+		Handle:        profile.Handle,
+		AvatarVisible: canSee(profile, viewerID, profile.Privacy.Avatar),
+	}
+	if canSee(profile, viewerID, profile.Privacy.FullName) {
+		public.FullName = profile.FullName
+	}
+	if canSee(profile, viewerID, profile.Privacy.Email) {
+		public.Email = profile.Email
+	}
+	if canSee(profile, viewerID, profile.Privacy.Phone) {
+		public.Phone = profile.Phone
+	}
+	if public.AvatarVisible {
+		public.AvatarID = profile.AvatarID
+	}
+	return public
+}
+
+// canSee applies a visibility setting to a viewer. Nothing but the owner's
+// own view gets past a profile that is not public.
+func canSee(profile *domain.Profile, viewerID int64, visibility domain.Visibility) bool {
+	if viewerID != 0 && viewerID == profile.UserID {
+		return true
+	}
+	return profile.Public && visibility.AllowsViewer(viewerID != 0)
+}
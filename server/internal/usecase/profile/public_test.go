@@ -0,0 +1,205 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"server/internal/domain"
+	"server/internal/gateway/sms"
+	"testing"
+)
+
+func TestUseCase_GetPublicProfile(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	privacy := domain.ProfilePrivacy{
+		FullName: domain.VisibilityPublic,
+		Email:    domain.VisibilitySignedIn,
+		Phone:    domain.VisibilityPrivate,
+		Avatar:   domain.VisibilityPublic,
+	}
+
+	tests := []struct {
+		name          string
+		public        bool
+		viewerID      int64
+		expectedError error
+		expected      domain.PublicProfile
+	}{
+		{
+			name:     "anonymous visitor sees public fields",
+			public:   true,
+			viewerID: 0,
+			expected: domain.PublicProfile{FullName: "Ada Lovelace", AvatarVisible: true, AvatarID: "avatar-1"},
+		},
+		{
+			name:     "signed-in user also sees signed-in fields",
+			public:   true,
+			viewerID: 2,
+			expected: domain.PublicProfile{FullName: "Ada Lovelace", Email: "ada@example.com", AvatarVisible: true, AvatarID: "avatar-1"},
+		},
+		{
+			name:     "owner sees everything",
+			public:   true,
+			viewerID: 1,
+			expected: domain.PublicProfile{FullName: "Ada Lovelace", Email: "ada@example.com", Phone: "+14155550132", AvatarVisible: true, AvatarID: "avatar-1"},
+		},
+		{
+			name:          "profile that is not public is hidden",
+			public:        false,
+			viewerID:      2,
+			expectedError: domain.ErrUserNotExists,
+		},
+		{
+			name:     "owner previews a profile that is not public",
+			public:   false,
+			viewerID: 1,
+			expected: domain.PublicProfile{FullName: "Ada Lovelace", Email: "ada@example.com", Phone: "+14155550132", AvatarVisible: true, AvatarID: "avatar-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockProfileRepo := &mockProfileRepository{
+				getProfileByHandleFunc: func(ctx context.Context, handle string) (*domain.Profile, error) {
+					if handle != "ada" {
+						t.Errorf("expected the handle in lower case, got %q", handle)
+					}
+					return &domain.Profile{
+						UserID:   1,
+						Handle:   "ada",
+						Email:    "ada@example.com",
+						FullName: "Ada Lovelace",
+						Phone:    "+14155550132",
+						AvatarID: "avatar-1",
+						Public:   tt.public,
+						Privacy:  privacy,
+					}, nil
+				},
+				getCustomFieldValuesFunc: func(ctx context.Context, userID int64) (map[string]string, error) {
+					return map[string]string{"department": "Research", "bio": "Hello"}, nil
+				},
+			}
+			mockFieldRepo := &mockProfileFieldRepository{
+				listFieldsFunc: func(ctx context.Context) ([]*domain.ProfileField, error) {
+					return []*domain.ProfileField{
+						{Key: "department", Label: "Department", Type: domain.ProfileFieldTypeText, Visibility: domain.ProfileFieldVisibilityPublic},
+						{Key: "bio", Label: "Bio", Type: domain.ProfileFieldTypeTextArea, Visibility: domain.ProfileFieldVisibilityPrivate},
+						{Key: "team", Label: "Team", Type: domain.ProfileFieldTypeText, Visibility: domain.ProfileFieldVisibilityPublic},
+					}, nil
+				},
+			}
+
+			uc := NewUseCase(logger, mockProfileRepo, &mockSessionRepository{}, &mockRefreshTokenRepository{}, &mockAccessTokenRepository{}, &mockOrganizationRepository{}, &mockExportRepository{}, mockFieldRepo, &mockAuditUseCase{}, &mockMailer{}, sms.NewFakeSender(), newMockBlobStore(), Config{})
+			profile, err := uc.GetPublicProfile(ctx, " Ada ", tt.viewerID)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if tt.expectedError != nil {
+				return
+			}
+			if profile.FullName != tt.expected.FullName || profile.Email != tt.expected.Email || profile.Phone != tt.expected.Phone {
+				t.Errorf("expected %+v, got %+v", tt.expected, *profile)
+			}
+			if profile.AvatarVisible != tt.expected.AvatarVisible || profile.AvatarID != tt.expected.AvatarID {
+				t.Errorf("expected avatar %v %q, got %v %q", tt.expected.AvatarVisible, tt.expected.AvatarID, profile.AvatarVisible, profile.AvatarID)
+			}
+			if len(profile.CustomFields) != 1 || profile.CustomFields[0].Key != "department" || profile.CustomFields[0].Label != "Department" {
+				t.Errorf("expected only the public department field, got %+v", profile.CustomFields)
+			}
+		})
+	}
+}
+
+func TestUseCase_PatchProfile_Handle(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	stringPtr := func(s string) *string { return &s }
+	boolPtr := func(b bool) *bool { return &b }
+
+	tests := []struct {
+		name           string
+		current        domain.Profile
+		patch          *domain.ProfilePatch
+		repoErr        error
+		expectedField  string
+		expectedHandle string
+	}{
+		{
+			name:           "handle is stored in lower case",
+			patch:          &domain.ProfilePatch{Handle: stringPtr(" Ada_L ")},
+			expectedHandle: "ada_l",
+		},
+		{
+			name:          "invalid characters",
+			patch:         &domain.ProfilePatch{Handle: stringPtr("ada lovelace")},
+			expectedField: "handle",
+		},
+		{
+			name:          "reserved handle",
+			patch:         &domain.ProfilePatch{Handle: stringPtr("admin")},
+			expectedField: "handle",
+		},
+		{
+			name:          "public profile needs a handle",
+			patch:         &domain.ProfilePatch{Public: boolPtr(true)},
+			expectedField: "handle",
+		},
+		{
+			name:          "handle cannot be removed from a public profile",
+			current:       domain.Profile{Handle: "ada", Public: true},
+			patch:         &domain.ProfilePatch{Handle: stringPtr("")},
+			expectedField: "handle",
+		},
+		{
+			name:          "taken handle",
+			patch:         &domain.ProfilePatch{Handle: stringPtr("grace")},
+			repoErr:       domain.ErrHandleTaken,
+			expectedField: "handle",
+		},
+		{
+			name:          "invalid visibility",
+			patch:         &domain.ProfilePatch{Privacy: domain.ProfilePrivacy{Phone: "friends"}},
+			expectedField: "visibility.phone",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var written *domain.ProfilePatch
+			mockProfileRepo := &mockProfileRepository{
+				getProfileByUserIDFunc: func(ctx context.Context, userID int64) (*domain.Profile, error) {
+					current := tt.current
+					current.UserID = userID
+					current.Version = 1
+					return &current, nil
+				},
+				updateProfileFunc: func(ctx context.Context, userID, version int64, patch *domain.ProfilePatch, history []*domain.ProfileChange) error {
+					written = patch
+					return tt.repoErr
+				},
+			}
+
+			uc := NewUseCase(logger, mockProfileRepo, &mockSessionRepository{}, &mockRefreshTokenRepository{}, &mockAccessTokenRepository{}, &mockOrganizationRepository{}, &mockExportRepository{}, &mockProfileFieldRepository{}, &mockAuditUseCase{}, &mockMailer{}, sms.NewFakeSender(), newMockBlobStore(), Config{})
+			_, err := uc.PatchProfile(ctx, 1, 1, tt.patch)
+
+			if tt.expectedField != "" {
+				var fieldErrors domain.FieldErrors
+				if !errors.As(err, &fieldErrors) || fieldErrors[tt.expectedField] == "" {
+					t.Fatalf("expected a %s field error, got %v", tt.expectedField, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if written == nil || written.Handle == nil || *written.Handle != tt.expectedHandle {
+				t.Errorf("expected handle %q to be written, got %+v", tt.expectedHandle, written)
+			}
+		})
+	}
+}
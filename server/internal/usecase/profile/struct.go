@@ -1,15 +1,63 @@
 package profile
 
-import "log/slog"
+import (
+	"log/slog"
+	"server/internal/pkg/ratelimit"
+	"time"
+)
+
+type Config struct {
+	DeletionGracePeriod time.Duration
+	ExportTTL           time.Duration
+	EmailChangeTTL      time.Duration
+	// LinkBaseURL is the public origin that links in outgoing emails point to.
+	LinkBaseURL string
+	// DefaultPhoneRegion is assumed for phone numbers entered without a
+	// country code; empty means the code is required.
+	DefaultPhoneRegion      string
+	PhoneCodeTTL            time.Duration
+	PhoneCodeResendInterval time.Duration
+	PhoneCodeMaxAttempts    int
+	// AvatarMaxBytes caps the size of an uploaded avatar file.
+	AvatarMaxBytes int64
+	// DirectorySearchLimit caps directory searches per user and
+	// DirectorySearchPeriod; zero leaves them unlimited.
+	DirectorySearchLimit  int
+	DirectorySearchPeriod time.Duration
+}
 
 type UseCase struct {
-	logger      *slog.Logger
-	profileRepo ProfileRepository
+	logger           *slog.Logger
+	profileRepo      ProfileRepository
+	sessionRepo      SessionRepository
+	refreshTokenRepo RefreshTokenRepository
+	accessTokenRepo  AccessTokenRepository
+	orgRepo          OrganizationRepository
+	exportRepo       ExportRepository
+	profileFieldRepo ProfileFieldRepository
+	auditUC          AuditUseCase
+	mailer           Mailer
+	smsSender        SMSSender
+	blobStore        BlobStore
+	cfg              Config
+	directoryLimiter *ratelimit.Limiter
 }
 
-func NewUseCase(logger *slog.Logger, profileRepo ProfileRepository) *UseCase {
+func NewUseCase(logger *slog.Logger, profileRepo ProfileRepository, sessionRepo SessionRepository, refreshTokenRepo RefreshTokenRepository, accessTokenRepo AccessTokenRepository, orgRepo OrganizationRepository, exportRepo ExportRepository, profileFieldRepo ProfileFieldRepository, auditUC AuditUseCase, mailer Mailer, smsSender SMSSender, blobStore BlobStore, cfg Config) *UseCase {
 	return &UseCase{
-		logger:      logger,
-		profileRepo: profileRepo,
+		logger:           logger,
+		profileRepo:      profileRepo,
+		sessionRepo:      sessionRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		accessTokenRepo:  accessTokenRepo,
+		orgRepo:          orgRepo,
+		exportRepo:       exportRepo,
+		profileFieldRepo: profileFieldRepo,
+		auditUC:          auditUC,
+		mailer:           mailer,
+		smsSender:        smsSender,
+		blobStore:        blobStore,
+		cfg:              cfg,
+		directoryLimiter: ratelimit.New(cfg.DirectorySearchLimit, cfg.DirectorySearchPeriod),
 	}
 }
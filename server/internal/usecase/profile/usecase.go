@@ -2,22 +2,293 @@ package profile
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"maps"
 	"server/internal/domain"
+	"server/internal/pkg/phone"
+	"server/internal/pkg/tracing"
+	"server/internal/pkg/validation"
+	"slices"
+	"strings"
+	"unicode/utf8"
 )
 
+// maxFullNameLength matches the width of the user.full_name column.
+const maxFullNameLength = 255
+
 func (uc *UseCase) GetProfile(ctx context.Context, userID int64) (*domain.Profile, error) {
+	ctx, span := tracing.Start(ctx, "profile.GetProfile")
+	defer span.End()
+
 	profile, err := uc.profileRepo.GetProfileByUserID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
+
+	profile.PendingEmail, err = uc.getPendingEmail(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile.CustomFields, err = uc.profileRepo.GetCustomFieldValues(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
 	return profile, nil
 }
 
-func (uc *UseCase) UpdateProfile(ctx context.Context, userID int64, profile *domain.Profile) error {
-	return uc.profileRepo.UpdateProfile(ctx, &domain.Profile{
-		UserID:   userID,
-		FullName: profile.FullName,
-		Phone:    profile.Phone,
-		Email:    profile.Email,
+// UpdateProfile replaces the editable fields of the profile. An empty email
+// means "keep the current one"; changing it has to go through
+// RequestEmailChange. Nil CustomFields leave custom fields alone, otherwise
+// every defined field missing from the map is cleared. Empty visibilities
+// are kept as they are.
+func (uc *UseCase) UpdateProfile(ctx context.Context, userID, version int64, profile *domain.Profile) (*domain.Profile, error) {
+	ctx, span := tracing.Start(ctx, "profile.UpdateProfile")
+	defer span.End()
+
+	patch := &domain.ProfilePatch{
+		FullName: &profile.FullName,
+		Phone:    &profile.Phone,
+		Handle:   &profile.Handle,
+		Public:   &profile.Public,
+		Privacy:  profile.Privacy,
+	}
+	if profile.Email != "" {
+		patch.Email = &profile.Email
+	}
+	if profile.CustomFields != nil {
+		fields, err := uc.profileFieldRepo.ListFields(ctx)
+		if err != nil {
+			return nil, err
+		}
+		patch.CustomFields = make(map[string]string, len(fields))
+		for _, field := range fields {
+			patch.CustomFields[field.Key] = ""
+		}
+		maps.Copy(patch.CustomFields, profile.CustomFields)
+	}
+
+	return uc.PatchProfile(ctx, userID, version, patch)
+}
+
+// PatchProfile validates every field present in the patch, reporting all
+// problems at once as domain.FieldErrors, and writes only the fields that
+// actually change. version is the profile version the client last saw; if
+// the profile has changed since, domain.ErrProfileVersionMismatch is returned.
+func (uc *UseCase) PatchProfile(ctx context.Context, userID, version int64, patch *domain.ProfilePatch) (*domain.Profile, error) {
+	ctx, span := tracing.Start(ctx, "profile.PatchProfile")
+	defer span.End()
+
+	var fields []*domain.ProfileField
+	if len(patch.CustomFields) > 0 {
+		var err error
+		fields, err = uc.profileFieldRepo.ListFields(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	normalized, err := uc.validateProfilePatch(patch, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := uc.profileRepo.GetProfileByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current profile: %w", err)
+	}
+	if current.Version != version {
+		return nil, domain.ErrProfileVersionMismatch
+	}
+
+	if normalized.Email != nil && !strings.EqualFold(*normalized.Email, current.Email) {
+		return nil, domain.ErrEmailChangeRequiresConfirmation
+	}
+
+	changes := &domain.ProfilePatch{}
+	if normalized.FullName != nil && *normalized.FullName != current.FullName {
+		changes.FullName = normalized.FullName
+	}
+	if normalized.Phone != nil && *normalized.Phone != current.Phone {
+		changes.Phone = normalized.Phone
+	}
+	var currentValues map[string]string
+	if len(normalized.CustomFields) > 0 {
+		currentValues, err = uc.profileRepo.GetCustomFieldValues(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current custom fields: %w", err)
+		}
+		for key, value := range normalized.CustomFields {
+			if value != currentValues[key] {
+				if changes.CustomFields == nil {
+					changes.CustomFields = make(map[string]string)
+				}
+				changes.CustomFields[key] = value
+			}
+		}
+	}
+	if normalized.Handle != nil && *normalized.Handle != current.Handle {
+		changes.Handle = normalized.Handle
+	}
+	if normalized.Public != nil && *normalized.Public != current.Public {
+		changes.Public = normalized.Public
+	}
+	changes.Privacy = privacyChanges(current.Privacy, normalized.Privacy)
+
+	public, handle := current.Public, current.Handle
+	if changes.Public != nil {
+		public = *changes.Public
+	}
+	if changes.Handle != nil {
+		handle = *changes.Handle
+	}
+	if public && handle == "" {
+		return nil, domain.FieldErrors{"handle": "is required for a public profile"}
+	}
+
+	if changes.IsEmpty() {
+		return uc.GetProfile(ctx, userID)
+	}
+
+	history := profileHistory(ctx, userID, current, currentValues, changes)
+	err = uc.profileRepo.UpdateProfile(ctx, userID, version, changes, history)
+	if err != nil {
+		uc.auditUC.Record(ctx, domain.AuditEvent{
+			ActorUserID:   &userID,
+			SubjectUserID: &userID,
+			Type:          domain.AuditEventProfileEdit,
+			Outcome:       domain.AuditOutcomeFailure,
+		})
+		if errors.Is(err, domain.ErrHandleTaken) {
+			return nil, domain.FieldErrors{"handle": "is already taken"}
+		}
+		return nil, err
+	}
+
+	uc.recordProfileChanges(ctx, userID, changes)
+	return uc.GetProfile(ctx, userID)
+}
+
+// validateProfilePatch returns a copy of the patch with values trimmed, the
+// phone normalized to E.164 and custom field values checked against their
+// definitions.
+func (uc *UseCase) validateProfilePatch(patch *domain.ProfilePatch, fields []*domain.ProfileField) (*domain.ProfilePatch, error) {
+	normalized := &domain.ProfilePatch{}
+	fieldErrors := domain.FieldErrors{}
+
+	if patch.FullName != nil {
+		fullName := strings.TrimSpace(*patch.FullName)
+		if utf8.RuneCountInString(fullName) > maxFullNameLength {
+			fieldErrors["full_name"] = fmt.Sprintf("must be at most %d characters", maxFullNameLength)
+		}
+		normalized.FullName = &fullName
+	}
+
+	if patch.Email != nil {
+		email := strings.TrimSpace(*patch.Email)
+		if email == "" {
+			fieldErrors["email"] = "must not be empty"
+		} else if !validation.IsValidEmail(email) {
+			fieldErrors["email"] = "must be a valid email address"
+		}
+		normalized.Email = &email
+	}
+
+	if patch.Phone != nil {
+		phoneNumber := strings.TrimSpace(*patch.Phone)
+		if phoneNumber != "" {
+			var err error
+			phoneNumber, err = phone.Normalize(phoneNumber, uc.cfg.DefaultPhoneRegion)
+			if err != nil {
+				fieldErrors["phone"] = strings.TrimPrefix(err.Error(), domain.ErrInvalidPhone.Error()+": ")
+			}
+		}
+		normalized.Phone = &phoneNumber
+	}
+
+	if patch.Handle != nil {
+		handle, message := normalizeHandle(*patch.Handle)
+		if message != "" {
+			fieldErrors["handle"] = message
+		}
+		normalized.Handle = &handle
+	}
+
+	normalized.Public = patch.Public
+	normalized.Privacy = patch.Privacy
+	for path, visibility := range privacyFields(patch.Privacy) {
+		if visibility != "" && !visibility.IsValid() {
+			fieldErrors[path] = "must be public, signed_in or private"
+		}
+	}
+
+	if len(patch.CustomFields) > 0 {
+		normalized.CustomFields = validateCustomFields(fields, patch.CustomFields, fieldErrors)
+	}
+
+	if len(fieldErrors) > 0 {
+		return nil, fieldErrors
+	}
+	return normalized, nil
+}
+
+func (uc *UseCase) recordProfileChanges(ctx context.Context, userID int64, changes *domain.ProfilePatch) {
+	changed := make([]string, 0, 2)
+	if changes.FullName != nil {
+		changed = append(changed, "full_name")
+	}
+	if changes.Phone != nil {
+		changed = append(changed, "phone")
+	}
+	if changes.Handle != nil {
+		changed = append(changed, "handle")
+	}
+	if changes.Public != nil {
+		changed = append(changed, "public")
+	}
+	visibilities := privacyFields(changes.Privacy)
+	for _, path := range slices.Sorted(maps.Keys(visibilities)) {
+		if visibilities[path] != "" {
+			changed = append(changed, path)
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(changes.CustomFields)) {
+		changed = append(changed, customFieldPath(key))
+	}
+
+	uc.auditUC.Record(ctx, domain.AuditEvent{
+		ActorUserID:   &userID,
+		SubjectUserID: &userID,
+		Type:          domain.AuditEventProfileEdit,
+		Outcome:       domain.AuditOutcomeSuccess,
+		Details:       map[string]string{"fields": strings.Join(changed, ",")},
 	})
 }
+
+// privacyFields names the visibilities by their API path.
+func privacyFields(privacy domain.ProfilePrivacy) map[string]domain.Visibility {
+	return map[string]domain.Visibility{
+		"visibility.full_name": privacy.FullName,
+		"visibility.email":     privacy.Email,
+		"visibility.phone":     privacy.Phone,
+		"visibility.avatar":    privacy.Avatar,
+	}
+}
+
+// privacyChanges keeps the requested visibilities that differ from the
+// current ones.
+func privacyChanges(current, requested domain.ProfilePrivacy) domain.ProfilePrivacy {
+	changed := func(current, requested domain.Visibility) domain.Visibility {
+		if requested == current {
+			return ""
+		}
+		return requested
+	}
+	return domain.ProfilePrivacy{
+		FullName: changed(current.FullName, requested.FullName),
+		Email:    changed(current.Email, requested.Email),
+		Phone:    changed(current.Phone, requested.Phone),
+		Avatar:   changed(current.Avatar, requested.Avatar),
+	}
+}
@@ -6,25 +6,393 @@ import (
 	"log/slog"
 	"os"
 	"server/internal/domain"
+	"server/internal/gateway/sms"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 type mockProfileRepository struct {
-	getProfileByUserIDFunc func(ctx context.Context, userID int64) (*domain.Profile, error)
-	updateProfileFunc      func(ctx context.Context, profile *domain.Profile) error
+	getProfileByUserIDFunc         func(ctx context.Context, userID int64) (*domain.Profile, error)
+	updateProfileFunc              func(ctx context.Context, userID, version int64, patch *domain.ProfilePatch, history []*domain.ProfileChange) error
+	getUserByIDFunc                func(ctx context.Context, userID int64) (*domain.User, error)
+	scheduleAccountDeletionFunc    func(ctx context.Context, userID int64, deleteAt time.Time) error
+	deleteUsersScheduledBeforeFunc func(ctx context.Context, now time.Time) (int64, []string, error)
+	getOAuthAccountsByUserIDFunc   func(ctx context.Context, userID int64) ([]*domain.OAuthAccount, error)
+	getUserByEmailFunc             func(ctx context.Context, email string) (*domain.User, error)
+	createEmailChangeRequestFunc   func(ctx context.Context, request *domain.EmailChangeRequest) error
+	getEmailChangeByUserIDFunc     func(ctx context.Context, userID int64) (*domain.EmailChangeRequest, error)
+	getEmailChangeByConfirmFunc    func(ctx context.Context, tokenHash string) (*domain.EmailChangeRequest, error)
+	getEmailChangeByCancelFunc     func(ctx context.Context, tokenHash string) (*domain.EmailChangeRequest, error)
+	applyEmailChangeFunc           func(ctx context.Context, request *domain.EmailChangeRequest) error
+	deleteEmailChangeRequestFunc   func(ctx context.Context, requestID int64) error
+	savePhoneVerificationFunc      func(ctx context.Context, verification *domain.PhoneVerification) error
+	getPhoneVerificationFunc       func(ctx context.Context, userID int64) (*domain.PhoneVerification, error)
+	usePhoneAttemptFunc            func(ctx context.Context, userID int64, maxAttempts int) error
+	markPhoneVerifiedFunc          func(ctx context.Context, userID int64, phone string, verifiedAt time.Time) error
+	setAvatarFunc                  func(ctx context.Context, userID int64, avatarID string) (string, error)
+	getCustomFieldValuesFunc       func(ctx context.Context, userID int64) (map[string]string, error)
+	getProfileByHandleFunc         func(ctx context.Context, handle string) (*domain.Profile, error)
+	getActiveProfileByUserIDFunc   func(ctx context.Context, userID int64) (*domain.Profile, error)
+	listProfileChangesFunc         func(ctx context.Context, userID, beforeID int64, limit int) ([]*domain.ProfileChange, error)
+	searchProfilesFunc             func(ctx context.Context, terms []string, afterID int64, limit int) ([]*domain.Profile, error)
 }
 
 func (m *mockProfileRepository) GetProfileByUserID(ctx context.Context, userID int64) (*domain.Profile, error) {
 	if m.getProfileByUserIDFunc != nil {
 		return m.getProfileByUserIDFunc(ctx, userID)
 	}
-	return nil, nil
+	return &domain.Profile{UserID: userID, Version: 1}, nil
 }
 
-func (m *mockProfileRepository) UpdateProfile(ctx context.Context, profile *domain.Profile) error {
+func (m *mockProfileRepository) GetActiveProfileByUserID(ctx context.Context, userID int64) (*domain.Profile, error) {
+	if m.getActiveProfileByUserIDFunc != nil {
+		return m.getActiveProfileByUserIDFunc(ctx, userID)
+	}
+	return &domain.Profile{UserID: userID, Version: 1}, nil
+}
+
+func (m *mockProfileRepository) UpdateProfile(ctx context.Context, userID, version int64, patch *domain.ProfilePatch, history []*domain.ProfileChange) error {
 	if m.updateProfileFunc != nil {
-		return m.updateProfileFunc(ctx, profile)
+		return m.updateProfileFunc(ctx, userID, version, patch, history)
+	}
+	return nil
+}
+
+func (m *mockProfileRepository) GetUserByID(ctx context.Context, userID int64) (*domain.User, error) {
+	if m.getUserByIDFunc != nil {
+		return m.getUserByIDFunc(ctx, userID)
+	}
+	return &domain.User{ID: userID}, nil
+}
+
+func (m *mockProfileRepository) ScheduleAccountDeletion(ctx context.Context, userID int64, deleteAt time.Time) error {
+	if m.scheduleAccountDeletionFunc != nil {
+		return m.scheduleAccountDeletionFunc(ctx, userID, deleteAt)
+	}
+	return nil
+}
+
+func (m *mockProfileRepository) DeleteUsersScheduledBefore(ctx context.Context, now time.Time) (int64, []string, error) {
+	if m.deleteUsersScheduledBeforeFunc != nil {
+		return m.deleteUsersScheduledBeforeFunc(ctx, now)
+	}
+	return 0, nil, nil
+}
+
+func (m *mockProfileRepository) GetOAuthAccountsByUserID(ctx context.Context, userID int64) ([]*domain.OAuthAccount, error) {
+	if m.getOAuthAccountsByUserIDFunc != nil {
+		return m.getOAuthAccountsByUserIDFunc(ctx, userID)
+	}
+	return nil, nil
+}
+
+func (m *mockProfileRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	if m.getUserByEmailFunc != nil {
+		return m.getUserByEmailFunc(ctx, email)
+	}
+	return nil, domain.ErrUserNotExists
+}
+
+func (m *mockProfileRepository) CreateEmailChangeRequest(ctx context.Context, request *domain.EmailChangeRequest) error {
+	if m.createEmailChangeRequestFunc != nil {
+		return m.createEmailChangeRequestFunc(ctx, request)
+	}
+	return nil
+}
+
+func (m *mockProfileRepository) GetEmailChangeRequestByUserID(ctx context.Context, userID int64) (*domain.EmailChangeRequest, error) {
+	if m.getEmailChangeByUserIDFunc != nil {
+		return m.getEmailChangeByUserIDFunc(ctx, userID)
+	}
+	return nil, domain.ErrEmailChangeNotFound
+}
+
+func (m *mockProfileRepository) GetEmailChangeRequestByConfirmToken(ctx context.Context, tokenHash string) (*domain.EmailChangeRequest, error) {
+	if m.getEmailChangeByConfirmFunc != nil {
+		return m.getEmailChangeByConfirmFunc(ctx, tokenHash)
+	}
+	return nil, domain.ErrEmailChangeNotFound
+}
+
+func (m *mockProfileRepository) GetEmailChangeRequestByCancelToken(ctx context.Context, tokenHash string) (*domain.EmailChangeRequest, error) {
+	if m.getEmailChangeByCancelFunc != nil {
+		return m.getEmailChangeByCancelFunc(ctx, tokenHash)
+	}
+	return nil, domain.ErrEmailChangeNotFound
+}
+
+func (m *mockProfileRepository) ApplyEmailChange(ctx context.Context, request *domain.EmailChangeRequest) error {
+	if m.applyEmailChangeFunc != nil {
+		return m.applyEmailChangeFunc(ctx, request)
+	}
+	return nil
+}
+
+func (m *mockProfileRepository) DeleteEmailChangeRequest(ctx context.Context, requestID int64) error {
+	if m.deleteEmailChangeRequestFunc != nil {
+		return m.deleteEmailChangeRequestFunc(ctx, requestID)
+	}
+	return nil
+}
+
+func (m *mockProfileRepository) SavePhoneVerification(ctx context.Context, verification *domain.PhoneVerification) error {
+	if m.savePhoneVerificationFunc != nil {
+		return m.savePhoneVerificationFunc(ctx, verification)
+	}
+	return nil
+}
+
+func (m *mockProfileRepository) GetPhoneVerification(ctx context.Context, userID int64) (*domain.PhoneVerification, error) {
+	if m.getPhoneVerificationFunc != nil {
+		return m.getPhoneVerificationFunc(ctx, userID)
+	}
+	return nil, domain.ErrPhoneVerificationNotFound
+}
+
+func (m *mockProfileRepository) UsePhoneVerificationAttempt(ctx context.Context, userID int64, maxAttempts int) error {
+	if m.usePhoneAttemptFunc != nil {
+		return m.usePhoneAttemptFunc(ctx, userID, maxAttempts)
+	}
+	return nil
+}
+
+func (m *mockProfileRepository) MarkPhoneVerified(ctx context.Context, userID int64, phone string, verifiedAt time.Time) error {
+	if m.markPhoneVerifiedFunc != nil {
+		return m.markPhoneVerifiedFunc(ctx, userID, phone, verifiedAt)
+	}
+	return nil
+}
+
+func (m *mockProfileRepository) SetAvatar(ctx context.Context, userID int64, avatarID string) (string, error) {
+	if m.setAvatarFunc != nil {
+		return m.setAvatarFunc(ctx, userID, avatarID)
+	}
+	return "", nil
+}
+
+func (m *mockProfileRepository) GetCustomFieldValues(ctx context.Context, userID int64) (map[string]string, error) {
+	if m.getCustomFieldValuesFunc != nil {
+		return m.getCustomFieldValuesFunc(ctx, userID)
+	}
+	return map[string]string{}, nil
+}
+
+func (m *mockProfileRepository) GetProfileByHandle(ctx context.Context, handle string) (*domain.Profile, error) {
+	if m.getProfileByHandleFunc != nil {
+		return m.getProfileByHandleFunc(ctx, handle)
+	}
+	return nil, domain.ErrUserNotExists
+}
+
+func (m *mockProfileRepository) ListProfileChanges(ctx context.Context, userID, beforeID int64, limit int) ([]*domain.ProfileChange, error) {
+	if m.listProfileChangesFunc != nil {
+		return m.listProfileChangesFunc(ctx, userID, beforeID, limit)
+	}
+	return []*domain.ProfileChange{}, nil
+}
+
+func (m *mockProfileRepository) SearchProfiles(ctx context.Context, terms []string, afterID int64, limit int) ([]*domain.Profile, error) {
+	if m.searchProfilesFunc != nil {
+		return m.searchProfilesFunc(ctx, terms, afterID, limit)
+	}
+	return []*domain.Profile{}, nil
+}
+
+type mockProfileFieldRepository struct {
+	listFieldsFunc func(ctx context.Context) ([]*domain.ProfileField, error)
+}
+
+func (m *mockProfileFieldRepository) ListFields(ctx context.Context) ([]*domain.ProfileField, error) {
+	if m.listFieldsFunc != nil {
+		return m.listFieldsFunc(ctx)
+	}
+	return nil, nil
+}
+
+type mockRefreshTokenRepository struct {
+	listUserRefreshTokensFunc   func(ctx context.Context, userID int64) ([]*domain.RefreshToken, error)
+	revokeUserRefreshTokensFunc func(ctx context.Context, userID int64, revokedAt time.Time) error
+}
+
+func (m *mockRefreshTokenRepository) ListUserRefreshTokens(ctx context.Context, userID int64) ([]*domain.RefreshToken, error) {
+	if m.listUserRefreshTokensFunc != nil {
+		return m.listUserRefreshTokensFunc(ctx, userID)
+	}
+	return nil, nil
+}
+
+func (m *mockRefreshTokenRepository) RevokeUserRefreshTokens(ctx context.Context, userID int64, revokedAt time.Time) error {
+	if m.revokeUserRefreshTokensFunc != nil {
+		return m.revokeUserRefreshTokensFunc(ctx, userID, revokedAt)
+	}
+	return nil
+}
+
+type mockAccessTokenRepository struct {
+	listTokensFunc       func(ctx context.Context, userID int64) ([]*domain.PersonalAccessToken, error)
+	deleteUserTokensFunc func(ctx context.Context, userID int64) error
+}
+
+func (m *mockAccessTokenRepository) ListTokens(ctx context.Context, userID int64) ([]*domain.PersonalAccessToken, error) {
+	if m.listTokensFunc != nil {
+		return m.listTokensFunc(ctx, userID)
+	}
+	return nil, nil
+}
+
+func (m *mockAccessTokenRepository) DeleteUserTokens(ctx context.Context, userID int64) error {
+	if m.deleteUserTokensFunc != nil {
+		return m.deleteUserTokensFunc(ctx, userID)
+	}
+	return nil
+}
+
+type mockOrganizationRepository struct {
+	listUserOrganizationsFunc func(ctx context.Context, userID int64) ([]*domain.OrganizationMembership, error)
+}
+
+func (m *mockOrganizationRepository) ListUserOrganizations(ctx context.Context, userID int64) ([]*domain.OrganizationMembership, error) {
+	if m.listUserOrganizationsFunc != nil {
+		return m.listUserOrganizationsFunc(ctx, userID)
+	}
+	return nil, nil
+}
+
+type mockSessionRepository struct {
+	deleteUserSessionsFunc func(ctx context.Context, userID int64) error
+	getUserSessionsFunc    func(ctx context.Context, userID int64) ([]*domain.Session, error)
+}
+
+func (m *mockSessionRepository) DeleteUserSessions(ctx context.Context, userID int64) error {
+	if m.deleteUserSessionsFunc != nil {
+		return m.deleteUserSessionsFunc(ctx, userID)
+	}
+	return nil
+}
+
+func (m *mockSessionRepository) GetUserSessions(ctx context.Context, userID int64) ([]*domain.Session, error) {
+	if m.getUserSessionsFunc != nil {
+		return m.getUserSessionsFunc(ctx, userID)
+	}
+	return nil, nil
+}
+
+type mockExportRepository struct {
+	createExportFunc            func(ctx context.Context, export *domain.DataExport) error
+	getExportByIDFunc           func(ctx context.Context, exportID string) (*domain.DataExport, error)
+	getLatestExportByUserIDFunc func(ctx context.Context, userID int64) (*domain.DataExport, error)
+	listPendingExportsFunc      func(ctx context.Context, limit int) ([]*domain.DataExport, error)
+	completeExportFunc          func(ctx context.Context, exportID string, archive []byte, completedAt, expiresAt time.Time) error
+	failExportFunc              func(ctx context.Context, exportID string, completedAt, expiresAt time.Time) error
+	deleteExpiredExportsFunc    func(ctx context.Context, now time.Time) (int64, error)
+}
+
+func (m *mockExportRepository) CreateExport(ctx context.Context, export *domain.DataExport) error {
+	if m.createExportFunc != nil {
+		return m.createExportFunc(ctx, export)
+	}
+	return nil
+}
+
+func (m *mockExportRepository) GetExportByID(ctx context.Context, exportID string) (*domain.DataExport, error) {
+	if m.getExportByIDFunc != nil {
+		return m.getExportByIDFunc(ctx, exportID)
+	}
+	return nil, domain.ErrExportNotFound
+}
+
+func (m *mockExportRepository) GetLatestExportByUserID(ctx context.Context, userID int64) (*domain.DataExport, error) {
+	if m.getLatestExportByUserIDFunc != nil {
+		return m.getLatestExportByUserIDFunc(ctx, userID)
+	}
+	return nil, domain.ErrExportNotFound
+}
+
+func (m *mockExportRepository) ListPendingExports(ctx context.Context, limit int) ([]*domain.DataExport, error) {
+	if m.listPendingExportsFunc != nil {
+		return m.listPendingExportsFunc(ctx, limit)
+	}
+	return nil, nil
+}
+
+func (m *mockExportRepository) CompleteExport(ctx context.Context, exportID string, archive []byte, completedAt, expiresAt time.Time) error {
+	if m.completeExportFunc != nil {
+		return m.completeExportFunc(ctx, exportID, archive, completedAt, expiresAt)
+	}
+	return nil
+}
+
+func (m *mockExportRepository) FailExport(ctx context.Context, exportID string, completedAt, expiresAt time.Time) error {
+	if m.failExportFunc != nil {
+		return m.failExportFunc(ctx, exportID, completedAt, expiresAt)
+	}
+	return nil
+}
+
+func (m *mockExportRepository) DeleteExpiredExports(ctx context.Context, now time.Time) (int64, error) {
+	if m.deleteExpiredExportsFunc != nil {
+		return m.deleteExpiredExportsFunc(ctx, now)
+	}
+	return 0, nil
+}
+
+type mockAuditUseCase struct {
+	mu                   sync.Mutex
+	events               []domain.AuditEvent
+	listUserActivityFunc func(ctx context.Context, userID int64, beforeID int64, limit int) ([]*domain.AuditEvent, error)
+}
+
+func (m *mockAuditUseCase) Record(ctx context.Context, event domain.AuditEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, event)
+}
+
+func (m *mockAuditUseCase) ListUserActivity(ctx context.Context, userID int64, beforeID int64, limit int) ([]*domain.AuditEvent, error) {
+	if m.listUserActivityFunc != nil {
+		return m.listUserActivityFunc(ctx, userID, beforeID, limit)
+	}
+	return nil, nil
+}
+
+type mockMailer struct {
+	messages []domain.EmailMessage
+}
+
+func (m *mockMailer) Send(ctx context.Context, message domain.EmailMessage) error {
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+type mockBlobStore struct {
+	blobs  map[string][]byte
+	putErr error
+}
+
+func newMockBlobStore() *mockBlobStore {
+	return &mockBlobStore{blobs: map[string][]byte{}}
+}
+
+func (m *mockBlobStore) Put(ctx context.Context, key string, data []byte) error {
+	if m.putErr != nil {
+		return m.putErr
+	}
+	m.blobs[key] = data
+	return nil
+}
+
+func (m *mockBlobStore) Get(ctx context.Context, key string) ([]byte, error) {
+	data, ok := m.blobs[key]
+	if !ok {
+		return nil, domain.ErrBlobNotFound
 	}
+	return data, nil
+}
+
+func (m *mockBlobStore) Delete(ctx context.Context, key string) error {
+	delete(m.blobs, key)
 	return nil
 }
 
@@ -76,7 +444,7 @@ func TestUseCase_GetProfile(t *testing.T) {
 			mockProfileRepo := &mockProfileRepository{}
 			tt.setupMocks(mockProfileRepo)
 
-			uc := NewUseCase(logger, mockProfileRepo)
+			uc := NewUseCase(logger, mockProfileRepo, &mockSessionRepository{}, &mockRefreshTokenRepository{}, &mockAccessTokenRepository{}, &mockOrganizationRepository{}, &mockExportRepository{}, &mockProfileFieldRepository{}, &mockAuditUseCase{}, &mockMailer{}, sms.NewFakeSender(), newMockBlobStore(), Config{})
 			profile, err := uc.GetProfile(ctx, tt.userID)
 
 			if tt.expectedError != nil {
@@ -120,17 +488,22 @@ func TestUseCase_UpdateProfile(t *testing.T) {
 			name:   "successful update",
 			userID: 1,
 			profile: &domain.Profile{
-				Email:    "updated@example.com",
 				FullName: "Updated User",
-				Phone:    "9876543210",
+				Phone:    "+1 (415) 555-0132",
 			},
 			setupMocks: func(m *mockProfileRepository) {
-				m.updateProfileFunc = func(ctx context.Context, profile *domain.Profile) error {
-					if profile.UserID != 1 {
-						t.Errorf("expected userID 1, got %d", profile.UserID)
+				m.getProfileByUserIDFunc = func(ctx context.Context, userID int64) (*domain.Profile, error) {
+					return &domain.Profile{UserID: userID, Email: "current@example.com", Version: 1}, nil
+				}
+				m.updateProfileFunc = func(ctx context.Context, userID, version int64, patch *domain.ProfilePatch, history []*domain.ProfileChange) error {
+					if userID != 1 {
+						t.Errorf("expected userID 1, got %d", userID)
+					}
+					if patch.Email != nil {
+						t.Errorf("expected email to be left unchanged, got %s", *patch.Email)
 					}
-					if profile.Email != "updated@example.com" {
-						t.Errorf("expected email updated@example.com, got %s", profile.Email)
+					if patch.Phone == nil || *patch.Phone != "+14155550132" {
+						t.Errorf("expected normalized phone +14155550132, got %v", patch.Phone)
 					}
 					return nil
 				}
@@ -138,15 +511,47 @@ func TestUseCase_UpdateProfile(t *testing.T) {
 			expectedError: nil,
 		},
 		{
-			name:   "update error",
+			name:   "invalid phone rejected",
+			userID: 1,
+			profile: &domain.Profile{
+				FullName: "Updated User",
+				Phone:    "+1 415",
+			},
+			setupMocks: func(m *mockProfileRepository) {
+				m.updateProfileFunc = func(ctx context.Context, userID, version int64, patch *domain.ProfilePatch, history []*domain.ProfileChange) error {
+					t.Error("profile must not be updated")
+					return nil
+				}
+			},
+			expectedError: domain.FieldErrors{},
+		},
+		{
+			name:   "direct email change rejected",
 			userID: 1,
 			profile: &domain.Profile{
 				Email:    "updated@example.com",
 				FullName: "Updated User",
-				Phone:    "9876543210",
 			},
 			setupMocks: func(m *mockProfileRepository) {
-				m.updateProfileFunc = func(ctx context.Context, profile *domain.Profile) error {
+				m.getProfileByUserIDFunc = func(ctx context.Context, userID int64) (*domain.Profile, error) {
+					return &domain.Profile{UserID: userID, Email: "current@example.com", Version: 1}, nil
+				}
+				m.updateProfileFunc = func(ctx context.Context, userID, version int64, patch *domain.ProfilePatch, history []*domain.ProfileChange) error {
+					t.Error("profile must not be updated")
+					return nil
+				}
+			},
+			expectedError: domain.ErrEmailChangeRequiresConfirmation,
+		},
+		{
+			name:   "update error",
+			userID: 1,
+			profile: &domain.Profile{
+				FullName: "Updated User",
+				Phone:    "+14155550132",
+			},
+			setupMocks: func(m *mockProfileRepository) {
+				m.updateProfileFunc = func(ctx context.Context, userID, version int64, patch *domain.ProfilePatch, history []*domain.ProfileChange) error {
 					return errors.New("update error")
 				}
 			},
@@ -156,17 +561,19 @@ func TestUseCase_UpdateProfile(t *testing.T) {
 			name:   "update with empty fields",
 			userID: 1,
 			profile: &domain.Profile{
-				Email:    "updated@example.com",
 				FullName: "",
 				Phone:    "",
 			},
 			setupMocks: func(m *mockProfileRepository) {
-				m.updateProfileFunc = func(ctx context.Context, profile *domain.Profile) error {
-					if profile.FullName != "" {
-						t.Errorf("expected empty FullName, got %s", profile.FullName)
+				m.getProfileByUserIDFunc = func(ctx context.Context, userID int64) (*domain.Profile, error) {
+					return &domain.Profile{UserID: userID, FullName: "Test User", Phone: "+14155550132", Version: 1}, nil
+				}
+				m.updateProfileFunc = func(ctx context.Context, userID, version int64, patch *domain.ProfilePatch, history []*domain.ProfileChange) error {
+					if patch.FullName == nil || *patch.FullName != "" {
+						t.Errorf("expected FullName to be cleared, got %v", patch.FullName)
 					}
-					if profile.Phone != "" {
-						t.Errorf("expected empty Phone, got %s", profile.Phone)
+					if patch.Phone == nil || *patch.Phone != "" {
+						t.Errorf("expected Phone to be cleared, got %v", patch.Phone)
 					}
 					return nil
 				}
@@ -180,8 +587,8 @@ func TestUseCase_UpdateProfile(t *testing.T) {
 			mockProfileRepo := &mockProfileRepository{}
 			tt.setupMocks(mockProfileRepo)
 
-			uc := NewUseCase(logger, mockProfileRepo)
-			err := uc.UpdateProfile(ctx, tt.userID, tt.profile)
+			uc := NewUseCase(logger, mockProfileRepo, &mockSessionRepository{}, &mockRefreshTokenRepository{}, &mockAccessTokenRepository{}, &mockOrganizationRepository{}, &mockExportRepository{}, &mockProfileFieldRepository{}, &mockAuditUseCase{}, &mockMailer{}, sms.NewFakeSender(), newMockBlobStore(), Config{})
+			_, err := uc.UpdateProfile(ctx, tt.userID, 1, tt.profile)
 
 			if tt.expectedError != nil {
 				if err == nil {
@@ -195,3 +602,174 @@ func TestUseCase_UpdateProfile(t *testing.T) {
 		})
 	}
 }
+
+func TestUseCase_PatchProfile(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	stringPtr := func(s string) *string { return &s }
+	current := domain.Profile{
+		UserID:   1,
+		Email:    "current@example.com",
+		FullName: "Test User",
+		Phone:    "+14155550132",
+		Version:  4,
+	}
+
+	tests := []struct {
+		name                string
+		version             int64
+		patch               *domain.ProfilePatch
+		expectedChanges     *domain.ProfilePatch
+		expectedFieldErrors domain.FieldErrors
+		expectedError       error
+	}{
+		{
+			name:            "only full name sent",
+			version:         4,
+			patch:           &domain.ProfilePatch{FullName: stringPtr("  New Name ")},
+			expectedChanges: &domain.ProfilePatch{FullName: stringPtr("New Name")},
+		},
+		{
+			name:            "unchanged fields are not written",
+			version:         4,
+			patch:           &domain.ProfilePatch{FullName: stringPtr("Test User"), Phone: stringPtr("+1 415 555 0132"), Email: stringPtr("Current@Example.com")},
+			expectedChanges: nil,
+		},
+		{
+			name:            "null phone clears it",
+			version:         4,
+			patch:           &domain.ProfilePatch{Phone: stringPtr("")},
+			expectedChanges: &domain.ProfilePatch{Phone: stringPtr("")},
+		},
+		{
+			name:    "every invalid field is reported",
+			version: 4,
+			patch:   &domain.ProfilePatch{FullName: stringPtr(strings.Repeat("a", 256)), Phone: stringPtr("12345"), Email: stringPtr("not-an-email")},
+			expectedFieldErrors: domain.FieldErrors{
+				"full_name": "must be at most 255 characters",
+				"phone":     "include the country code",
+				"email":     "must be a valid email address",
+			},
+		},
+		{
+			name:                "email cannot be removed",
+			version:             4,
+			patch:               &domain.ProfilePatch{Email: stringPtr("")},
+			expectedFieldErrors: domain.FieldErrors{"email": "must not be empty"},
+		},
+		{
+			name:          "email change needs confirmation",
+			version:       4,
+			patch:         &domain.ProfilePatch{Email: stringPtr("new@example.com")},
+			expectedError: domain.ErrEmailChangeRequiresConfirmation,
+		},
+		{
+			name:          "stale version rejected",
+			version:       3,
+			patch:         &domain.ProfilePatch{FullName: stringPtr("New Name")},
+			expectedError: domain.ErrProfileVersionMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var written *domain.ProfilePatch
+			mockProfileRepo := &mockProfileRepository{
+				getProfileByUserIDFunc: func(ctx context.Context, userID int64) (*domain.Profile, error) {
+					profile := current
+					return &profile, nil
+				},
+				updateProfileFunc: func(ctx context.Context, userID, version int64, patch *domain.ProfilePatch, history []*domain.ProfileChange) error {
+					written = patch
+					return nil
+				},
+			}
+
+			uc := NewUseCase(logger, mockProfileRepo, &mockSessionRepository{}, &mockRefreshTokenRepository{}, &mockAccessTokenRepository{}, &mockOrganizationRepository{}, &mockExportRepository{}, &mockProfileFieldRepository{}, &mockAuditUseCase{}, &mockMailer{}, sms.NewFakeSender(), newMockBlobStore(), Config{})
+			_, err := uc.PatchProfile(ctx, 1, tt.version, tt.patch)
+
+			if tt.expectedFieldErrors != nil {
+				var fieldErrors domain.FieldErrors
+				if !errors.As(err, &fieldErrors) {
+					t.Fatalf("expected field errors, got %v", err)
+				}
+				for field, message := range tt.expectedFieldErrors {
+					if !strings.Contains(fieldErrors[field], message) {
+						t.Errorf("expected %s error %q, got %q", field, message, fieldErrors[field])
+					}
+				}
+				if len(fieldErrors) != len(tt.expectedFieldErrors) {
+					t.Errorf("expected %d field errors, got %v", len(tt.expectedFieldErrors), fieldErrors)
+				}
+				return
+			}
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if tt.expectedError != nil {
+				return
+			}
+
+			if tt.expectedChanges == nil {
+				if written != nil {
+					t.Errorf("expected no update, got %+v", written)
+				}
+				return
+			}
+			if written == nil {
+				t.Fatal("expected an update")
+			}
+			if !equalStringPtr(written.FullName, tt.expectedChanges.FullName) ||
+				!equalStringPtr(written.Phone, tt.expectedChanges.Phone) ||
+				!equalStringPtr(written.Email, tt.expectedChanges.Email) {
+				t.Errorf("unexpected changes written: %+v", written)
+			}
+		})
+	}
+}
+
+func equalStringPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func TestUseCase_UpdateProfile_RecordsAuditEvents(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	mockProfileRepo := &mockProfileRepository{
+		getProfileByUserIDFunc: func(ctx context.Context, userID int64) (*domain.Profile, error) {
+			return &domain.Profile{
+				UserID:   userID,
+				Email:    "old@example.com",
+				FullName: "Test User",
+				Phone:    "+14155550132",
+				Version:  1,
+			}, nil
+		},
+	}
+	mockAudit := &mockAuditUseCase{}
+
+	uc := NewUseCase(logger, mockProfileRepo, &mockSessionRepository{}, &mockRefreshTokenRepository{}, &mockAccessTokenRepository{}, &mockOrganizationRepository{}, &mockExportRepository{}, &mockProfileFieldRepository{}, mockAudit, &mockMailer{}, sms.NewFakeSender(), newMockBlobStore(), Config{})
+	_, err := uc.UpdateProfile(ctx, 1, 1, &domain.Profile{
+		Email:    "old@example.com",
+		FullName: "Test User",
+		Phone:    "+1 415 555 0199",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(mockAudit.events) != 1 {
+		t.Fatalf("expected 1 audit event, got %d", len(mockAudit.events))
+	}
+	if mockAudit.events[0].Type != domain.AuditEventProfileEdit {
+		t.Errorf("expected type %s, got %s", domain.AuditEventProfileEdit, mockAudit.events[0].Type)
+	}
+	if mockAudit.events[0].Details["fields"] != "phone" {
+		t.Errorf("expected changed fields phone, got %s", mockAudit.events[0].Details["fields"])
+	}
+}
@@ -0,0 +1,18 @@
+package profilefield
+
+import (
+	"context"
+	"server/internal/domain"
+)
+
+type ProfileFieldRepository interface {
+	ListFields(ctx context.Context) ([]*domain.ProfileField, error)
+	GetFieldByKey(ctx context.Context, key string) (*domain.ProfileField, error)
+	CreateField(ctx context.Context, field *domain.ProfileField) error
+	UpdateField(ctx context.Context, field *domain.ProfileField) error
+	DeleteField(ctx context.Context, key string) error
+}
+
+type AuditUseCase interface {
+	Record(ctx context.Context, event domain.AuditEvent)
+}
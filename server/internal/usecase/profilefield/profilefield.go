@@ -0,0 +1,185 @@
+package profilefield
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"server/internal/domain"
+	"server/internal/pkg/tracing"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxLabelLength = 255
+	maxOptions     = 100
+	// maxValueLength matches the widest limit a text field can be given.
+	maxValueLength = 10000
+)
+
+// keyPattern keeps keys usable as JSON member names, form field names and
+// query parameters without escaping.
+var keyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+func (uc *UseCase) ListFields(ctx context.Context) ([]*domain.ProfileField, error) {
+	ctx, span := tracing.Start(ctx, "profilefield.ListFields")
+	defer span.End()
+
+	return uc.profileFieldRepo.ListFields(ctx)
+}
+
+func (uc *UseCase) CreateField(ctx context.Context, actorID int64, field *domain.ProfileField) (*domain.ProfileField, error) {
+	ctx, span := tracing.Start(ctx, "profilefield.CreateField")
+	defer span.End()
+
+	if err := validateField(field, true); err != nil {
+		return nil, err
+	}
+
+	if err := uc.profileFieldRepo.CreateField(ctx, field); err != nil {
+		return nil, err
+	}
+
+	uc.record(ctx, actorID, domain.AuditEventProfileFieldCreate, field.Key)
+	return uc.profileFieldRepo.GetFieldByKey(ctx, field.Key)
+}
+
+// UpdateField replaces the definition of an existing field. The type cannot
+// change because stored values would no longer match it.
+func (uc *UseCase) UpdateField(ctx context.Context, actorID int64, field *domain.ProfileField) (*domain.ProfileField, error) {
+	ctx, span := tracing.Start(ctx, "profilefield.UpdateField")
+	defer span.End()
+
+	current, err := uc.profileFieldRepo.GetFieldByKey(ctx, field.Key)
+	if err != nil {
+		return nil, err
+	}
+	if field.Type == "" {
+		field.Type = current.Type
+	}
+	if field.Type != current.Type {
+		return nil, domain.ErrProfileFieldTypeChanged
+	}
+
+	if err := validateField(field, false); err != nil {
+		return nil, err
+	}
+
+	if err := uc.profileFieldRepo.UpdateField(ctx, field); err != nil {
+		return nil, err
+	}
+
+	uc.record(ctx, actorID, domain.AuditEventProfileFieldUpdate, field.Key)
+	return uc.profileFieldRepo.GetFieldByKey(ctx, field.Key)
+}
+
+// DeleteField removes the field and every value users have stored in it.
+func (uc *UseCase) DeleteField(ctx context.Context, actorID int64, key string) error {
+	ctx, span := tracing.Start(ctx, "profilefield.DeleteField")
+	defer span.End()
+
+	if err := uc.profileFieldRepo.DeleteField(ctx, key); err != nil {
+		return err
+	}
+
+	uc.record(ctx, actorID, domain.AuditEventProfileFieldDelete, key)
+	return nil
+}
+
+func (uc *UseCase) record(ctx context.Context, actorID int64, eventType domain.AuditEventType, key string) {
+	uc.auditUC.Record(ctx, domain.AuditEvent{
+		ActorUserID: &actorID,
+		Type:        eventType,
+		Outcome:     domain.AuditOutcomeSuccess,
+		Details:     map[string]string{"key": key},
+	})
+}
+
+// validateField trims the definition in place and reports every problem at
+// once as domain.FieldErrors.
+func validateField(field *domain.ProfileField, checkKey bool) error {
+	fieldErrors := domain.FieldErrors{}
+
+	if checkKey && !keyPattern.MatchString(field.Key) {
+		fieldErrors["key"] = "must start with a lowercase letter and contain only lowercase letters, digits and underscores, up to 64 characters"
+	}
+
+	field.Label = strings.TrimSpace(field.Label)
+	if field.Label == "" {
+		fieldErrors["label"] = "must not be empty"
+	} else if utf8.RuneCountInString(field.Label) > maxLabelLength {
+		fieldErrors["label"] = fmt.Sprintf("must be at most %d characters", maxLabelLength)
+	}
+
+	if !slices.Contains(domain.ProfileFieldTypes, field.Type) {
+		names := make([]string, 0, len(domain.ProfileFieldTypes))
+		for _, fieldType := range domain.ProfileFieldTypes {
+			names = append(names, string(fieldType))
+		}
+		fieldErrors["type"] = "must be one of: " + strings.Join(names, ", ")
+	}
+
+	if field.Visibility == "" {
+		field.Visibility = domain.ProfileFieldVisibilityPrivate
+	}
+	if field.Visibility != domain.ProfileFieldVisibilityPrivate && field.Visibility != domain.ProfileFieldVisibilityPublic {
+		fieldErrors["visibility"] = "must be private or public"
+	}
+
+	validateRules(field, fieldErrors)
+
+	if len(fieldErrors) > 0 {
+		return fieldErrors
+	}
+	return nil
+}
+
+// validateRules rejects rules that do not apply to the field type or could
+// never be satisfied, so a definition cannot lock users out of saving.
+func validateRules(field *domain.ProfileField, fieldErrors domain.FieldErrors) {
+	rules := &field.Rules
+	isText := field.Type == domain.ProfileFieldTypeText || field.Type == domain.ProfileFieldTypeTextArea
+
+	if rules.MinLength != 0 || rules.MaxLength != 0 || rules.Pattern != "" {
+		if !isText {
+			fieldErrors["rules"] = "min_length, max_length and pattern apply to text fields only"
+		}
+		if rules.MinLength < 0 || rules.MaxLength < 0 || rules.MaxLength > maxValueLength {
+			fieldErrors["rules.max_length"] = fmt.Sprintf("lengths must be between 0 and %d", maxValueLength)
+		} else if rules.MaxLength != 0 && rules.MinLength > rules.MaxLength {
+			fieldErrors["rules.min_length"] = "must not exceed max_length"
+		}
+		if rules.Pattern != "" {
+			if _, err := regexp.Compile(rules.Pattern); err != nil {
+				fieldErrors["rules.pattern"] = "must be a valid regular expression"
+			}
+		}
+	}
+
+	if rules.Min != nil || rules.Max != nil {
+		if field.Type != domain.ProfileFieldTypeNumber {
+			fieldErrors["rules"] = "min and max apply to number fields only"
+		} else if rules.Min != nil && rules.Max != nil && *rules.Min > *rules.Max {
+			fieldErrors["rules.min"] = "must not exceed max"
+		}
+	}
+
+	if field.Type == domain.ProfileFieldTypeSelect {
+		options := make([]string, 0, len(rules.Options))
+		for _, option := range rules.Options {
+			option = strings.TrimSpace(option)
+			if option != "" && !slices.Contains(options, option) {
+				options = append(options, option)
+			}
+		}
+		rules.Options = options
+		if len(options) == 0 {
+			fieldErrors["rules.options"] = "select fields need at least one option"
+		} else if len(options) > maxOptions {
+			fieldErrors["rules.options"] = fmt.Sprintf("must have at most %d options", maxOptions)
+		}
+	} else if len(rules.Options) > 0 {
+		fieldErrors["rules"] = "options apply to select fields only"
+	}
+}
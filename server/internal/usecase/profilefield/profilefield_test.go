@@ -0,0 +1,263 @@
+package profilefield
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"server/internal/domain"
+	"testing"
+)
+
+type mockProfileFieldRepository struct {
+	listFieldsFunc    func(ctx context.Context) ([]*domain.ProfileField, error)
+	getFieldByKeyFunc func(ctx context.Context, key string) (*domain.ProfileField, error)
+	createFieldFunc   func(ctx context.Context, field *domain.ProfileField) error
+	updateFieldFunc   func(ctx context.Context, field *domain.ProfileField) error
+	deleteFieldFunc   func(ctx context.Context, key string) error
+}
+
+func (m *mockProfileFieldRepository) ListFields(ctx context.Context) ([]*domain.ProfileField, error) {
+	if m.listFieldsFunc != nil {
+		return m.listFieldsFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (m *mockProfileFieldRepository) GetFieldByKey(ctx context.Context, key string) (*domain.ProfileField, error) {
+	if m.getFieldByKeyFunc != nil {
+		return m.getFieldByKeyFunc(ctx, key)
+	}
+	return &domain.ProfileField{Key: key, Type: domain.ProfileFieldTypeText}, nil
+}
+
+func (m *mockProfileFieldRepository) CreateField(ctx context.Context, field *domain.ProfileField) error {
+	if m.createFieldFunc != nil {
+		return m.createFieldFunc(ctx, field)
+	}
+	return nil
+}
+
+func (m *mockProfileFieldRepository) UpdateField(ctx context.Context, field *domain.ProfileField) error {
+	if m.updateFieldFunc != nil {
+		return m.updateFieldFunc(ctx, field)
+	}
+	return nil
+}
+
+func (m *mockProfileFieldRepository) DeleteField(ctx context.Context, key string) error {
+	if m.deleteFieldFunc != nil {
+		return m.deleteFieldFunc(ctx, key)
+	}
+	return nil
+}
+
+type mockAuditUseCase struct {
+	events []domain.AuditEvent
+}
+
+func (m *mockAuditUseCase) Record(ctx context.Context, event domain.AuditEvent) {
+	m.events = append(m.events, event)
+}
+
+func TestUseCase_CreateField(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	minValue, maxValue := 10.0, 1.0
+	tests := []struct {
+		name                string
+		field               *domain.ProfileField
+		createErr           error
+		expectedFieldErrors []string
+		expectedError       error
+	}{
+		{
+			name:  "valid text field",
+			field: &domain.ProfileField{Key: "job_title", Label: " Job title ", Type: domain.ProfileFieldTypeText, Rules: domain.ProfileFieldRules{MaxLength: 100}},
+		},
+		{
+			name:  "valid select field",
+			field: &domain.ProfileField{Key: "team", Label: "Team", Type: domain.ProfileFieldTypeSelect, Rules: domain.ProfileFieldRules{Options: []string{"core", " core ", "platform"}}},
+		},
+		{
+			name:                "invalid key, label, type and visibility",
+			field:               &domain.ProfileField{Key: "Job Title", Label: " ", Type: "color", Visibility: "friends"},
+			expectedFieldErrors: []string{"key", "label", "type", "visibility"},
+		},
+		{
+			name:                "select without options",
+			field:               &domain.ProfileField{Key: "team", Label: "Team", Type: domain.ProfileFieldTypeSelect},
+			expectedFieldErrors: []string{"rules.options"},
+		},
+		{
+			name:                "bad pattern",
+			field:               &domain.ProfileField{Key: "code", Label: "Code", Type: domain.ProfileFieldTypeText, Rules: domain.ProfileFieldRules{Pattern: "("}},
+			expectedFieldErrors: []string{"rules.pattern"},
+		},
+		{
+			name:                "min above max",
+			field:               &domain.ProfileField{Key: "age", Label: "Age", Type: domain.ProfileFieldTypeNumber, Rules: domain.ProfileFieldRules{Min: &minValue, Max: &maxValue}},
+			expectedFieldErrors: []string{"rules.min"},
+		},
+		{
+			name:                "rules for another type",
+			field:               &domain.ProfileField{Key: "age", Label: "Age", Type: domain.ProfileFieldTypeNumber, Rules: domain.ProfileFieldRules{MaxLength: 3}},
+			expectedFieldErrors: []string{"rules"},
+		},
+		{
+			name:          "duplicate key",
+			field:         &domain.ProfileField{Key: "team", Label: "Team", Type: domain.ProfileFieldTypeText},
+			createErr:     domain.ErrProfileFieldExists,
+			expectedError: domain.ErrProfileFieldExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created *domain.ProfileField
+			mockRepo := &mockProfileFieldRepository{
+				createFieldFunc: func(ctx context.Context, field *domain.ProfileField) error {
+					created = field
+					return tt.createErr
+				},
+				getFieldByKeyFunc: func(ctx context.Context, key string) (*domain.ProfileField, error) {
+					return created, nil
+				},
+			}
+			mockAudit := &mockAuditUseCase{}
+
+			uc := NewUseCase(logger, mockRepo, mockAudit)
+			field, err := uc.CreateField(ctx, 1, tt.field)
+
+			if tt.expectedFieldErrors != nil {
+				var fieldErrors domain.FieldErrors
+				if !errors.As(err, &fieldErrors) {
+					t.Fatalf("expected field errors, got %v", err)
+				}
+				for _, key := range tt.expectedFieldErrors {
+					if fieldErrors[key] == "" {
+						t.Errorf("expected an error for %s, got %v", key, fieldErrors)
+					}
+				}
+				if created != nil {
+					t.Error("invalid field must not be stored")
+				}
+				return
+			}
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if tt.expectedError != nil {
+				if len(mockAudit.events) != 0 {
+					t.Error("expected no audit event for a failed create")
+				}
+				return
+			}
+
+			if field.Visibility != domain.ProfileFieldVisibilityPrivate {
+				t.Errorf("expected private visibility by default, got %q", field.Visibility)
+			}
+			if field.Label != "Job title" && field.Key == "job_title" {
+				t.Errorf("expected label to be trimmed, got %q", field.Label)
+			}
+			if field.Type == domain.ProfileFieldTypeSelect && len(field.Rules.Options) != 2 {
+				t.Errorf("expected options to be trimmed and deduplicated, got %v", field.Rules.Options)
+			}
+			if len(mockAudit.events) != 1 || mockAudit.events[0].Type != domain.AuditEventProfileFieldCreate {
+				t.Errorf("expected a create audit event, got %+v", mockAudit.events)
+			}
+		})
+	}
+}
+
+func TestUseCase_UpdateField(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		field         *domain.ProfileField
+		expectedType  domain.ProfileFieldType
+		expectedError error
+	}{
+		{
+			name:         "type may be left out",
+			field:        &domain.ProfileField{Key: "department", Label: "Department", Required: true},
+			expectedType: domain.ProfileFieldTypeText,
+		},
+		{
+			name:          "type cannot change",
+			field:         &domain.ProfileField{Key: "department", Label: "Department", Type: domain.ProfileFieldTypeNumber},
+			expectedError: domain.ErrProfileFieldTypeChanged,
+		},
+		{
+			name:          "unknown field",
+			field:         &domain.ProfileField{Key: "missing", Label: "Missing"},
+			expectedError: domain.ErrProfileFieldNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var updated *domain.ProfileField
+			mockRepo := &mockProfileFieldRepository{
+				getFieldByKeyFunc: func(ctx context.Context, key string) (*domain.ProfileField, error) {
+					if key == "missing" {
+						return nil, domain.ErrProfileFieldNotFound
+					}
+					if updated != nil {
+						return updated, nil
+					}
+					return &domain.ProfileField{Key: key, Label: "Dept", Type: domain.ProfileFieldTypeText}, nil
+				},
+				updateFieldFunc: func(ctx context.Context, field *domain.ProfileField) error {
+					updated = field
+					return nil
+				},
+			}
+
+			uc := NewUseCase(logger, mockRepo, &mockAuditUseCase{})
+			field, err := uc.UpdateField(ctx, 1, tt.field)
+
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if tt.expectedError != nil {
+				if updated != nil {
+					t.Error("expected no update")
+				}
+				return
+			}
+			if field.Type != tt.expectedType || !field.Required {
+				t.Errorf("unexpected field after update: %+v", field)
+			}
+		})
+	}
+}
+
+func TestUseCase_DeleteField(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	mockRepo := &mockProfileFieldRepository{
+		deleteFieldFunc: func(ctx context.Context, key string) error {
+			if key != "department" {
+				return domain.ErrProfileFieldNotFound
+			}
+			return nil
+		},
+	}
+	mockAudit := &mockAuditUseCase{}
+	uc := NewUseCase(logger, mockRepo, mockAudit)
+
+	if err := uc.DeleteField(ctx, 1, "department"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := uc.DeleteField(ctx, 1, "missing"); !errors.Is(err, domain.ErrProfileFieldNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	if len(mockAudit.events) != 1 || mockAudit.events[0].Details["key"] != "department" {
+		t.Errorf("expected one delete audit event, got %+v", mockAudit.events)
+	}
+}
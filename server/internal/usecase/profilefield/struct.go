@@ -0,0 +1,17 @@
+package profilefield
+
+import "log/slog"
+
+type UseCase struct {
+	logger           *slog.Logger
+	profileFieldRepo ProfileFieldRepository
+	auditUC          AuditUseCase
+}
+
+func NewUseCase(logger *slog.Logger, profileFieldRepo ProfileFieldRepository, auditUC AuditUseCase) *UseCase {
+	return &UseCase{
+		logger:           logger,
+		profileFieldRepo: profileFieldRepo,
+		auditUC:          auditUC,
+	}
+}